@@ -858,6 +858,8 @@ func (hs *HTTPServer) GetDashboardVersions(c *contextmodel.ReqContext) response.
 			Created:       version.Created,
 			Message:       msg,
 			CreatedBy:     creator,
+			Label:         version.Label,
+			Pinned:        version.Pinned,
 		})
 	}
 
@@ -950,6 +952,8 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 		Created:       res.Created,
 		Message:       res.Message,
 		CreatedBy:     creator,
+		Label:         res.Label,
+		Pinned:        res.Pinned,
 	}
 
 	return response.JSON(http.StatusOK, dashVersionMeta)
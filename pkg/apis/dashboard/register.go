@@ -42,6 +42,31 @@ var DashboardResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 	},
 )
 
+// AnnoKeyTitle is the annotation with the title of a dashboard, set in the partial metadata of the dashboards
+// listed without their spec.
+const AnnoKeyTitle = GROUP + "/title"
+
+// TableRow returns the cells of a dashboard in the tables of the dashboards: the name, the title, the folder, and
+// the update and creation times in UTC. The dashboards that were never updated have their creation time as update time.
+func TableRow(obj metav1.Object, title string) ([]interface{}, error) {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	created := obj.GetCreationTimestamp().UTC()
+	updated, err := meta.GetUpdatedTimestamp()
+	if err != nil || updated == nil {
+		updated = &created
+	}
+	return []interface{}{
+		obj.GetName(),
+		title,
+		meta.GetFolder(),
+		updated.UTC().Format(time.RFC3339),
+		created.Format(time.RFC3339),
+	}, nil
+}
+
 var LibraryPanelResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 	"librarypanels", "librarypanel", "LibraryPanel",
 	func() runtime.Object { return &LibraryPanel{} },
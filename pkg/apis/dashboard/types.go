@@ -52,6 +52,12 @@ type DashboardVersionInfo struct {
 
 	// Message passed while saving the version
 	Message string `json:"message,omitempty"`
+
+	// The label naming this version, e.g. "last known good"
+	Label string `json:"label,omitempty"`
+
+	// Pinned versions are never deleted by the versions clean up
+	Pinned bool `json:"pinned,omitempty"`
 }
 
 // +k8s:conversion-gen:explicit-from=net/url.Values
@@ -65,6 +71,21 @@ type VersionsQueryOptions struct {
 
 	// +optional
 	Version int64 `json:"version,omitempty"`
+	// Only the versions saved by this identity, e.g. user:1
+	// +optional
+	CreatedBy string `json:"createdBy,omitempty"`
+
+	// Only the versions saved at or after this time, in milliseconds since the epoch
+	// +optional
+	From int64 `json:"from,omitempty"`
+
+	// Only the versions saved at or before this time, in milliseconds since the epoch
+	// +optional
+	To int64 `json:"to,omitempty"`
+
+	// Only the versions whose message contains this text
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -140,12 +161,18 @@ type DashboardAccess struct {
 	Slug string `json:"slug,omitempty"`
 	Url  string `json:"url,omitempty"`
 
+	// The folder of the dashboard, dashboards in the root are in the General folder
+	FolderUid   string `json:"folderUid,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+	FolderUrl   string `json:"folderUrl,omitempty"`
+
 	// The permissions part
 	CanSave                bool                  `json:"canSave"`
 	CanEdit                bool                  `json:"canEdit"`
 	CanAdmin               bool                  `json:"canAdmin"`
 	CanStar                bool                  `json:"canStar"`
 	CanDelete              bool                  `json:"canDelete"`
+	CanShare               bool                  `json:"canShare"`
 	AnnotationsPermissions *AnnotationPermission `json:"annotationsPermissions"`
 }
 
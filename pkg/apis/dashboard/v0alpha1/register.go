@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -24,17 +25,21 @@ var DashboardResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 		Definition: []metav1.TableColumnDefinition{
 			{Name: "Name", Type: "string", Format: "name"},
 			{Name: "Title", Type: "string", Format: "string", Description: "The dashboard name"},
+			{Name: "Folder", Type: "string", Description: "The folder of the dashboard, empty in the root folder"},
+			{Name: "Updated At", Type: "date"},
 			{Name: "Created At", Type: "date"},
 		},
+		// The listings of tables only read the metadata of the dashboards, the rows hold their partial metadata with
+		// the title in the dashboard.AnnoKeyTitle annotation
 		Reader: func(obj any) ([]interface{}, error) {
-			dash, ok := obj.(*Dashboard)
-			if ok {
+			switch dash := obj.(type) {
+			case *Dashboard:
 				if dash != nil {
-					return []interface{}{
-						dash.Name,
-						dash.Spec.GetNestedString("title"),
-						dash.CreationTimestamp.UTC().Format(time.RFC3339),
-					}, nil
+					return dashboard.TableRow(dash, dash.Spec.GetNestedString("title"))
+				}
+			case *metav1.PartialObjectMetadata:
+				if dash != nil {
+					return dashboard.TableRow(dash, dash.Annotations[dashboard.AnnoKeyTitle])
 				}
 			}
 			return nil, fmt.Errorf("expected dashboard")
@@ -70,6 +75,62 @@ var LibraryPanelResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 	},
 )
 
+var DashboardSnapshotResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
+	"snapshots", "snapshot", "DashboardSnapshot",
+	func() runtime.Object { return &DashboardSnapshot{} },
+	func() runtime.Object { return &DashboardSnapshotList{} },
+	utils.TableColumns{
+		Definition: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Title", Type: "string", Description: "The snapshot title"},
+			{Name: "External", Type: "boolean", Description: "Published on the external snapshot server"},
+			{Name: "Created At", Type: "date"},
+		},
+		Reader: func(obj any) ([]interface{}, error) {
+			snap, ok := obj.(*DashboardSnapshot)
+			if ok {
+				if snap != nil {
+					return []interface{}{
+						snap.Name,
+						snap.Spec.Title,
+						snap.Spec.External,
+						snap.CreationTimestamp.UTC().Format(time.RFC3339),
+					}, nil
+				}
+			}
+			return nil, fmt.Errorf("expected dashboard snapshot")
+		},
+	},
+)
+
+var DashboardTemplateResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
+	"dashboardtemplates", "dashboardtemplate", "DashboardTemplate",
+	func() runtime.Object { return &DashboardTemplate{} },
+	func() runtime.Object { return &DashboardTemplateList{} },
+	utils.TableColumns{
+		Definition: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Title", Type: "string", Description: "The template title"},
+			{Name: "Variables", Type: "integer", Description: "The number of variables of the template"},
+			{Name: "Created At", Type: "date"},
+		},
+		Reader: func(obj any) ([]interface{}, error) {
+			t, ok := obj.(*DashboardTemplate)
+			if ok {
+				if t != nil {
+					return []interface{}{
+						t.Name,
+						t.Spec.Title,
+						len(t.Spec.Variables),
+						t.CreationTimestamp.UTC().Format(time.RFC3339),
+					}, nil
+				}
+			}
+			return nil, fmt.Errorf("expected dashboard template")
+		},
+	},
+)
+
 var (
 	SchemeBuilder      runtime.SchemeBuilder
 	localSchemeBuilder = &SchemeBuilder
@@ -89,8 +150,20 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DashboardWithAccessInfo{},
 		&DashboardVersionList{},
 		&VersionsQueryOptions{},
+		&DashboardAnnotationList{},
+		&DashboardPermissionList{},
+		&DashboardProvisioningStatus{},
+		&DashboardPublicConfig{},
+		&DashboardResolvedVariables{},
+		&DashboardLintReport{},
+		&DashboardDependencies{},
+		&DashboardPanel{},
 		&LibraryPanel{},
 		&LibraryPanelList{},
+		&DashboardSnapshot{},
+		&DashboardSnapshotList{},
+		&DashboardTemplate{},
+		&DashboardTemplateList{},
 		&metav1.PartialObjectMetadata{},
 		&metav1.PartialObjectMetadataList{},
 	)
@@ -52,6 +52,12 @@ type DashboardVersionInfo struct {
 
 	// Message passed while saving the version
 	Message string `json:"message,omitempty"`
+
+	// The label naming this version, e.g. "last known good"
+	Label string `json:"label,omitempty"`
+
+	// Pinned versions are never deleted by the versions clean up
+	Pinned bool `json:"pinned,omitempty"`
 }
 
 // +k8s:conversion-gen:explicit-from=net/url.Values
@@ -65,6 +71,21 @@ type VersionsQueryOptions struct {
 
 	// +optional
 	Version int64 `json:"version,omitempty"`
+	// Only the versions saved by this identity, e.g. user:1
+	// +optional
+	CreatedBy string `json:"createdBy,omitempty"`
+
+	// Only the versions saved at or after this time, in milliseconds since the epoch
+	// +optional
+	From int64 `json:"from,omitempty"`
+
+	// Only the versions saved at or before this time, in milliseconds since the epoch
+	// +optional
+	To int64 `json:"to,omitempty"`
+
+	// Only the versions whose message contains this text
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -140,12 +161,18 @@ type DashboardAccess struct {
 	Slug string `json:"slug,omitempty"`
 	Url  string `json:"url,omitempty"`
 
+	// The folder of the dashboard, dashboards in the root are in the General folder
+	FolderUid   string `json:"folderUid,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+	FolderUrl   string `json:"folderUrl,omitempty"`
+
 	// The permissions part
 	CanSave                bool                  `json:"canSave"`
 	CanEdit                bool                  `json:"canEdit"`
 	CanAdmin               bool                  `json:"canAdmin"`
 	CanStar                bool                  `json:"canStar"`
 	CanDelete              bool                  `json:"canDelete"`
+	CanShare               bool                  `json:"canShare"`
 	AnnotationsPermissions *AnnotationPermission `json:"annotationsPermissions"`
 }
 
@@ -159,3 +186,298 @@ type AnnotationActions struct {
 	CanEdit   bool `json:"canEdit"`
 	CanDelete bool `json:"canDelete"`
 }
+
+// A dashboard snapshot is a copy of a dashboard including the data shown in its panels
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardSnapshot struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The snapshot content
+	Spec DashboardSnapshotSpec `json:"spec"`
+
+	// Where the snapshot can be viewed and when it expires
+	Status *DashboardSnapshotStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DashboardSnapshot `json:"items,omitempty"`
+}
+
+type DashboardSnapshotSpec struct {
+	// The snapshot title
+	Title string `json:"title,omitempty"`
+
+	// The dashboard body including the panel data (unstructured for now)
+	// It is empty when listing snapshots and for snapshots published externally
+	Dashboard common.Unstructured `json:"dashboard"`
+
+	// Time to live in seconds, the snapshot is removed once it expires.  Zero never expires
+	// +optional
+	Expires int64 `json:"expires,omitempty"`
+
+	// Publish the snapshot on the external snapshot server rather than storing it locally
+	// +optional
+	External bool `json:"external,omitempty"`
+}
+
+type DashboardSnapshotStatus struct {
+	// Absolute URL to view the snapshot
+	URL string `json:"url,omitempty"`
+
+	// The URL of the dashboard the snapshot was taken from
+	OriginalURL string `json:"originalUrl,omitempty"`
+
+	// The URL on the external snapshot server
+	ExternalURL string `json:"externalUrl,omitempty"`
+
+	// When the snapshot expires (unix milliseconds)
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// A dashboard template is a starter dashboard published in a namespace, new dashboards are created from it
+// with the instantiate subresource
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The template content
+	Spec DashboardTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DashboardTemplate `json:"items,omitempty"`
+}
+
+type DashboardTemplateSpec struct {
+	// The template title
+	Title string `json:"title"`
+
+	// What the dashboards created from the template show
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// +optional
+	// +listType=set
+	Tags []string `json:"tags,omitempty"`
+
+	// The variables replaced in the dashboard when the template is instantiated
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Variables []DashboardTemplateVariable `json:"variables,omitempty"`
+
+	// The dashboard body (unstructured for now)
+	// {{name}} in its string values is replaced with the value of the variable name
+	Dashboard common.Unstructured `json:"dashboard"`
+}
+
+type DashboardTemplateVariable struct {
+	// The name of the variable, {{name}} is replaced with its value
+	Name string `json:"name"`
+
+	// The label shown in the create flow
+	// +optional
+	Label string `json:"label,omitempty"`
+
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// The value used when none is given, variables without default value are required
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// DashboardAnnotationList is the response of the annotations subresource
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardAnnotationList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []DashboardAnnotation `json:"items"`
+}
+
+// DashboardAnnotation is an annotation of a dashboard
+type DashboardAnnotation struct {
+	ID      int64                `json:"id"`
+	PanelID int64                `json:"panelId,omitempty"`
+	Time    int64                `json:"time"`
+	TimeEnd int64                `json:"timeEnd,omitempty"`
+	Text    string               `json:"text"`
+	Tags    []string             `json:"tags,omitempty"`
+	Data    *common.Unstructured `json:"data,omitempty"`
+	Login   string               `json:"login,omitempty"`
+	Created int64                `json:"created"`
+	Updated int64                `json:"updated"`
+}
+
+// DashboardPermissionList is the response of the permissions subresource
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []DashboardPermission `json:"items"`
+}
+
+// DashboardPermission is a single entry of the permission list of a dashboard. Exactly one of user, team or role is set.
+type DashboardPermission struct {
+	UserID           int64  `json:"userId,omitempty"`
+	UserUID          string `json:"userUid,omitempty"`
+	UserLogin        string `json:"userLogin,omitempty"`
+	IsServiceAccount bool   `json:"isServiceAccount,omitempty"`
+	TeamID           int64  `json:"teamId,omitempty"`
+	TeamUID          string `json:"teamUid,omitempty"`
+	Team             string `json:"team,omitempty"`
+	Role             string `json:"role,omitempty"`
+
+	// One of View, Edit or Admin
+	Permission string `json:"permission"`
+
+	// Inherited permissions come from the parent folders and can not be changed on the dashboard
+	Inherited bool `json:"inherited,omitempty"`
+}
+
+// DashboardProvisioningStatus tells if a dashboard is managed by file provisioning, and where it is provisioned from
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardProvisioningStatus struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Provisioned bool `json:"provisioned"`
+
+	// ReadOnly is false when the provisioner allows changes from the UI, they are overwritten on the next provisioning run
+	ReadOnly    bool         `json:"readOnly"`
+	Provisioner string       `json:"provisioner,omitempty"`
+	Path        string       `json:"path,omitempty"`
+	Checksum    string       `json:"checksum,omitempty"`
+	Timestamp   *metav1.Time `json:"timestamp,omitempty"`
+}
+
+// DashboardPublicConfig is the public sharing configuration of a dashboard
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardPublicConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	UID                  string `json:"uid"`
+	AccessToken          string `json:"accessToken"`
+	IsEnabled            bool   `json:"isEnabled"`
+	TimeSelectionEnabled bool   `json:"timeSelectionEnabled"`
+	AnnotationsEnabled   bool   `json:"annotationsEnabled"`
+	Share                string `json:"share"`
+	Created              int64  `json:"created"`
+	Updated              int64  `json:"updated"`
+}
+
+// DashboardResolvedVariables is the dashboard spec with its template variables interpolated
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardResolvedVariables struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Spec      common.Unstructured         `json:"spec"`
+	Variables []DashboardResolvedVariable `json:"variables"`
+}
+
+// DashboardResolvedVariable is the value of a template variable after it was evaluated
+type DashboardResolvedVariable struct {
+	Name    string                    `json:"name"`
+	Type    string                    `json:"type"`
+	Text    []string                  `json:"text"`
+	Value   []string                  `json:"value"`
+	Options []DashboardVariableOption `json:"options,omitempty"`
+
+	// Warning is set when the options of the variable could not be evaluated and its current value was kept
+	Warning string `json:"warning,omitempty"`
+}
+
+// DashboardVariableOption is an option of a template variable
+type DashboardVariableOption struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// DashboardLintReport lists the problems found in a dashboard spec, most severe first
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardLintReport struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Findings []DashboardLintFinding `json:"findings"`
+}
+
+// DashboardLintFinding is a problem found in a dashboard spec
+type DashboardLintFinding struct {
+	// The rule reporting the problem, for example duplicate-panel-id
+	Rule string `json:"rule"`
+
+	// One of error, warning or info
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+
+	// The panel with the problem, unset for problems of the whole dashboard
+	PanelID int64 `json:"panelId,omitempty"`
+
+	// The query of the panel with the problem
+	RefID string `json:"refId,omitempty"`
+}
+
+// DashboardDependencies lists the data sources, library panels and panel plugins a dashboard depends on
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardDependencies struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Datasources   []DashboardDatasourceDependency   `json:"datasources"`
+	LibraryPanels []DashboardLibraryPanelDependency `json:"libraryPanels"`
+	Plugins       []DashboardPluginDependency       `json:"plugins"`
+
+	// True when one of the dependencies does not exist
+	Broken bool `json:"broken"`
+}
+
+// DashboardDatasourceDependency is a data source referenced by a dashboard, by name for old dashboards
+type DashboardDatasourceDependency struct {
+	UID    string `json:"uid,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Exists bool   `json:"exists"`
+}
+
+// DashboardLibraryPanelDependency is a library panel used by a dashboard
+type DashboardLibraryPanelDependency struct {
+	UID    string `json:"uid"`
+	Name   string `json:"name,omitempty"`
+	Exists bool   `json:"exists"`
+}
+
+// DashboardPluginDependency is a panel plugin used by a dashboard
+type DashboardPluginDependency struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Exists  bool   `json:"exists"`
+}
+
+// DashboardPanel is a single panel of a dashboard with the template variables it uses
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DashboardPanel struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// The panel, a library panel is replaced by its model
+	Panel common.Unstructured `json:"panel"`
+
+	// The template variables used by the panel, in the order of the dashboard
+	Variables []common.Unstructured `json:"variables"`
+}
@@ -0,0 +1,54 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+)
+
+func TestDashboardTable(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	// the update time is in UTC in the table
+	updated := created.Add(time.Hour).In(time.FixedZone("UTC+2", 2*60*60))
+
+	inFolder := Dashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", CreationTimestamp: metav1.NewTime(created)},
+		Spec:       DashboardSpec{Unstructured: common.Unstructured{Object: map[string]any{"title": "A", "panels": []any{}}}},
+	}
+	meta, err := utils.MetaAccessor(&inFolder)
+	require.NoError(t, err)
+	meta.SetFolder("folder-1")
+	meta.SetUpdatedTimestamp(&updated)
+
+	root := Dashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "b", CreationTimestamp: metav1.NewTime(created)},
+		Spec:       DashboardSpec{Unstructured: common.Unstructured{Object: map[string]any{"title": "B"}}},
+	}
+
+	table, err := DashboardResourceInfo.TableConverter().ConvertToTable(context.Background(),
+		&DashboardList{Items: []Dashboard{inFolder, root}}, nil)
+	require.NoError(t, err)
+	require.Len(t, table.ColumnDefinitions, 5)
+	require.Len(t, table.Rows, 2)
+	require.Equal(t, []any{"a", "A", "folder-1", "2024-01-02T04:04:05Z", "2024-01-02T03:04:05Z"}, table.Rows[0].Cells)
+	require.Equal(t, []any{"b", "B", "", "2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z"}, table.Rows[1].Cells,
+		"dashboards that were never updated use the creation time")
+
+	t.Run("partial metadata", func(t *testing.T) {
+		partial := &metav1.PartialObjectMetadata{ObjectMeta: *inFolder.ObjectMeta.DeepCopy()}
+		partial.Annotations[dashboard.AnnoKeyTitle] = "A"
+
+		table, err := DashboardResourceInfo.TableConverter().ConvertToTable(context.Background(),
+			&metav1.PartialObjectMetadataList{Items: []metav1.PartialObjectMetadata{*partial}}, nil)
+		require.NoError(t, err)
+		require.Len(t, table.Rows, 1)
+		require.Equal(t, []any{"a", "A", "folder-1", "2024-01-02T04:04:05Z", "2024-01-02T03:04:05Z"}, table.Rows[0].Cells)
+	})
+}
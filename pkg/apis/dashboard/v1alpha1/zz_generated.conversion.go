@@ -256,11 +256,15 @@ func Convert_dashboard_Dashboard_To_v1alpha1_Dashboard(in *dashboard.Dashboard,
 func autoConvert_v1alpha1_DashboardAccess_To_dashboard_DashboardAccess(in *DashboardAccess, out *dashboard.DashboardAccess, s conversion.Scope) error {
 	out.Slug = in.Slug
 	out.Url = in.Url
+	out.FolderUid = in.FolderUid
+	out.FolderTitle = in.FolderTitle
+	out.FolderUrl = in.FolderUrl
 	out.CanSave = in.CanSave
 	out.CanEdit = in.CanEdit
 	out.CanAdmin = in.CanAdmin
 	out.CanStar = in.CanStar
 	out.CanDelete = in.CanDelete
+	out.CanShare = in.CanShare
 	out.AnnotationsPermissions = (*dashboard.AnnotationPermission)(unsafe.Pointer(in.AnnotationsPermissions))
 	return nil
 }
@@ -273,11 +277,15 @@ func Convert_v1alpha1_DashboardAccess_To_dashboard_DashboardAccess(in *Dashboard
 func autoConvert_dashboard_DashboardAccess_To_v1alpha1_DashboardAccess(in *dashboard.DashboardAccess, out *DashboardAccess, s conversion.Scope) error {
 	out.Slug = in.Slug
 	out.Url = in.Url
+	out.FolderUid = in.FolderUid
+	out.FolderTitle = in.FolderTitle
+	out.FolderUrl = in.FolderUrl
 	out.CanSave = in.CanSave
 	out.CanEdit = in.CanEdit
 	out.CanAdmin = in.CanAdmin
 	out.CanStar = in.CanStar
 	out.CanDelete = in.CanDelete
+	out.CanShare = in.CanShare
 	out.AnnotationsPermissions = (*AnnotationPermission)(unsafe.Pointer(in.AnnotationsPermissions))
 	return nil
 }
@@ -335,6 +343,8 @@ func autoConvert_v1alpha1_DashboardVersionInfo_To_dashboard_DashboardVersionInfo
 	out.Created = in.Created
 	out.CreatedBy = in.CreatedBy
 	out.Message = in.Message
+	out.Label = in.Label
+	out.Pinned = in.Pinned
 	return nil
 }
 
@@ -349,6 +359,8 @@ func autoConvert_dashboard_DashboardVersionInfo_To_v1alpha1_DashboardVersionInfo
 	out.Created = in.Created
 	out.CreatedBy = in.CreatedBy
 	out.Message = in.Message
+	out.Label = in.Label
+	out.Pinned = in.Pinned
 	return nil
 }
 
@@ -518,6 +530,10 @@ func Convert_dashboard_LibraryPanelStatus_To_v1alpha1_LibraryPanelStatus(in *das
 func autoConvert_v1alpha1_VersionsQueryOptions_To_dashboard_VersionsQueryOptions(in *VersionsQueryOptions, out *dashboard.VersionsQueryOptions, s conversion.Scope) error {
 	out.Path = in.Path
 	out.Version = in.Version
+	out.CreatedBy = in.CreatedBy
+	out.From = in.From
+	out.To = in.To
+	out.Message = in.Message
 	return nil
 }
 
@@ -529,6 +545,10 @@ func Convert_v1alpha1_VersionsQueryOptions_To_dashboard_VersionsQueryOptions(in
 func autoConvert_dashboard_VersionsQueryOptions_To_v1alpha1_VersionsQueryOptions(in *dashboard.VersionsQueryOptions, out *VersionsQueryOptions, s conversion.Scope) error {
 	out.Path = in.Path
 	out.Version = in.Version
+	out.CreatedBy = in.CreatedBy
+	out.From = in.From
+	out.To = in.To
+	out.Message = in.Message
 	return nil
 }
 
@@ -554,6 +574,34 @@ func autoConvert_url_Values_To_v1alpha1_VersionsQueryOptions(in *url.Values, out
 	} else {
 		out.Version = 0
 	}
+	if values, ok := map[string][]string(*in)["createdBy"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.CreatedBy, s); err != nil {
+			return err
+		}
+	} else {
+		out.CreatedBy = ""
+	}
+	if values, ok := map[string][]string(*in)["from"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_int64(&values, &out.From, s); err != nil {
+			return err
+		}
+	} else {
+		out.From = 0
+	}
+	if values, ok := map[string][]string(*in)["to"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_int64(&values, &out.To, s); err != nil {
+			return err
+		}
+	} else {
+		out.To = 0
+	}
+	if values, ok := map[string][]string(*in)["message"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.Message, s); err != nil {
+			return err
+		}
+	} else {
+		out.Message = ""
+	}
 	return nil
 }
 
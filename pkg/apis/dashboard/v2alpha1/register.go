@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -24,17 +25,21 @@ var DashboardResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 		Definition: []metav1.TableColumnDefinition{
 			{Name: "Name", Type: "string", Format: "name"},
 			{Name: "Title", Type: "string", Format: "string", Description: "The dashboard name"},
+			{Name: "Folder", Type: "string", Description: "The folder of the dashboard, empty in the root folder"},
+			{Name: "Updated At", Type: "date"},
 			{Name: "Created At", Type: "date"},
 		},
+		// The listings of tables only read the metadata of the dashboards, the rows hold their partial metadata with
+		// the title in the dashboard.AnnoKeyTitle annotation
 		Reader: func(obj any) ([]interface{}, error) {
-			dash, ok := obj.(*Dashboard)
-			if ok {
+			switch dash := obj.(type) {
+			case *Dashboard:
 				if dash != nil {
-					return []interface{}{
-						dash.Name,
-						dash.Spec.GetNestedString("title"),
-						dash.CreationTimestamp.UTC().Format(time.RFC3339),
-					}, nil
+					return dashboard.TableRow(dash, dash.Spec.GetNestedString("title"))
+				}
+			case *metav1.PartialObjectMetadata:
+				if dash != nil {
+					return dashboard.TableRow(dash, dash.Annotations[dashboard.AnnoKeyTitle])
 				}
 			}
 			return nil, fmt.Errorf("expected dashboard")
@@ -89,6 +94,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DashboardWithAccessInfo{},
 		&DashboardVersionList{},
 		&VersionsQueryOptions{},
+		&DashboardAnnotationList{},
+		&DashboardPermissionList{},
+		&DashboardProvisioningStatus{},
+		&DashboardPublicConfig{},
+		&DashboardResolvedVariables{},
+		&DashboardLintReport{},
+		&DashboardDependencies{},
+		&DashboardPanel{},
 		&LibraryPanel{},
 		&LibraryPanelList{},
 		&metav1.PartialObjectMetadata{},
@@ -9,6 +9,7 @@ package v2alpha1
 
 import (
 	v0alpha1 "github.com/grafana/grafana-plugin-sdk-go/experimental/apis/data/v0alpha1"
+	commonv0alpha1 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -94,6 +95,181 @@ func (in *DashboardAccess) DeepCopy() *DashboardAccess {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardAnnotation) DeepCopyInto(out *DashboardAnnotation) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardAnnotation.
+func (in *DashboardAnnotation) DeepCopy() *DashboardAnnotation {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardAnnotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardAnnotationList) DeepCopyInto(out *DashboardAnnotationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DashboardAnnotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardAnnotationList.
+func (in *DashboardAnnotationList) DeepCopy() *DashboardAnnotationList {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardAnnotationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardAnnotationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardDatasourceDependency) DeepCopyInto(out *DashboardDatasourceDependency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardDatasourceDependency.
+func (in *DashboardDatasourceDependency) DeepCopy() *DashboardDatasourceDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardDatasourceDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardDependencies) DeepCopyInto(out *DashboardDependencies) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Datasources != nil {
+		in, out := &in.Datasources, &out.Datasources
+		*out = make([]DashboardDatasourceDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.LibraryPanels != nil {
+		in, out := &in.LibraryPanels, &out.LibraryPanels
+		*out = make([]DashboardLibraryPanelDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]DashboardPluginDependency, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardDependencies.
+func (in *DashboardDependencies) DeepCopy() *DashboardDependencies {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardDependencies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardDependencies) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardLibraryPanelDependency) DeepCopyInto(out *DashboardLibraryPanelDependency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardLibraryPanelDependency.
+func (in *DashboardLibraryPanelDependency) DeepCopy() *DashboardLibraryPanelDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardLibraryPanelDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardLintFinding) DeepCopyInto(out *DashboardLintFinding) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardLintFinding.
+func (in *DashboardLintFinding) DeepCopy() *DashboardLintFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardLintFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardLintReport) DeepCopyInto(out *DashboardLintReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]DashboardLintFinding, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardLintReport.
+func (in *DashboardLintReport) DeepCopy() *DashboardLintReport {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardLintReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardLintReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardList) DeepCopyInto(out *DashboardList) {
 	*out = *in
@@ -127,6 +303,219 @@ func (in *DashboardList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardPanel) DeepCopyInto(out *DashboardPanel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.Panel.DeepCopyInto(&out.Panel)
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]commonv0alpha1.Unstructured, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardPanel.
+func (in *DashboardPanel) DeepCopy() *DashboardPanel {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardPanel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardPanel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardPermission) DeepCopyInto(out *DashboardPermission) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardPermission.
+func (in *DashboardPermission) DeepCopy() *DashboardPermission {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardPermission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardPermissionList) DeepCopyInto(out *DashboardPermissionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DashboardPermission, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardPermissionList.
+func (in *DashboardPermissionList) DeepCopy() *DashboardPermissionList {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardPermissionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardPermissionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardPluginDependency) DeepCopyInto(out *DashboardPluginDependency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardPluginDependency.
+func (in *DashboardPluginDependency) DeepCopy() *DashboardPluginDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardPluginDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardProvisioningStatus) DeepCopyInto(out *DashboardProvisioningStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardProvisioningStatus.
+func (in *DashboardProvisioningStatus) DeepCopy() *DashboardProvisioningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardProvisioningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardProvisioningStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardPublicConfig) DeepCopyInto(out *DashboardPublicConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardPublicConfig.
+func (in *DashboardPublicConfig) DeepCopy() *DashboardPublicConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardPublicConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardPublicConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardResolvedVariable) DeepCopyInto(out *DashboardResolvedVariable) {
+	*out = *in
+	if in.Text != nil {
+		in, out := &in.Text, &out.Text
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]DashboardVariableOption, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardResolvedVariable.
+func (in *DashboardResolvedVariable) DeepCopy() *DashboardResolvedVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardResolvedVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardResolvedVariables) DeepCopyInto(out *DashboardResolvedVariables) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]DashboardResolvedVariable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardResolvedVariables.
+func (in *DashboardResolvedVariables) DeepCopy() *DashboardResolvedVariables {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardResolvedVariables)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardResolvedVariables) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
 	*out = *in
@@ -144,6 +533,22 @@ func (in *DashboardSpec) DeepCopy() *DashboardSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardVariableOption) DeepCopyInto(out *DashboardVariableOption) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardVariableOption.
+func (in *DashboardVariableOption) DeepCopy() *DashboardVariableOption {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardVariableOption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardVersionInfo) DeepCopyInto(out *DashboardVersionInfo) {
 	*out = *in
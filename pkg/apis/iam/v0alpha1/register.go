@@ -144,6 +144,32 @@ var TeamBindingResourceInfo = utils.NewResourceInfo(
 	},
 )
 
+var TeamRoleResourceInfo = utils.NewResourceInfo(
+	GROUP, VERSION, "teamroles", "teamrole", "TeamRole",
+	func() runtime.Object { return &TeamRole{} },
+	func() runtime.Object { return &TeamRoleList{} },
+	utils.TableColumns{
+		Definition: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Team", Type: "string"},
+			{Name: "Role", Type: "string"},
+			{Name: "Created At", Type: "string", Format: "date"},
+		},
+		Reader: func(obj any) ([]interface{}, error) {
+			m, ok := obj.(*TeamRole)
+			if !ok {
+				return nil, fmt.Errorf("expected team role")
+			}
+			return []interface{}{
+				m.Name,
+				m.Spec.Team.Name,
+				m.Spec.Role.Name,
+				m.CreationTimestamp.UTC().Format(time.RFC3339),
+			}, nil
+		},
+	},
+)
+
 var (
 	// SchemeGroupVersion is group version used to register these objects
 	SchemeGroupVersion = schema.GroupVersion{Group: GROUP, Version: VERSION}
@@ -172,6 +198,11 @@ func AddKnownTypes(scheme *runtime.Scheme, version string) {
 		&TeamBinding{},
 		&TeamBindingList{},
 		&TeamMemberList{},
+		&TeamRole{},
+		&TeamRoleList{},
+		&TeamRoleSyncResult{},
+		&RoleTeamList{},
+		&TeamRoleHistoryList{},
 	)
 }
 
@@ -0,0 +1,97 @@
+package v0alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TeamRole assigns a role to a team. The team and role of a binding can not be changed,
+// the name is the team uid and role uid joined with TeamRoleNameSeparator.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TeamRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TeamRoleSpec `json:"spec,omitempty"`
+}
+
+type TeamRoleSpec struct {
+	Team TeamRef `json:"team"`
+	Role RoleRef `json:"role"`
+}
+
+type RoleRef struct {
+	// Name is the uid of a fixed or custom role.
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TeamRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TeamRole `json:"items,omitempty"`
+}
+
+// RoleTeamList lists the teams a role is assigned to
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RoleTeamList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []RoleTeam `json:"items,omitempty"`
+}
+
+// RoleTeam is a team a role is assigned to
+type RoleTeam struct {
+	Title   string  `json:"title,omitempty"`
+	TeamRef TeamRef `json:"teamRef,omitempty"`
+}
+
+// TeamRoleHistoryList lists the roles assigned to and removed from a team, or the teams a role was assigned to
+// and removed from, oldest first
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TeamRoleHistoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TeamRoleHistoryEntry `json:"items,omitempty"`
+}
+
+// TeamRoleHistoryEntry records a role assigned to or removed from a team
+type TeamRoleHistoryEntry struct {
+	// Action is create when the role was assigned to the team and delete when it was removed
+	Action string  `json:"action"`
+	Team   TeamRef `json:"team"`
+	Role   RoleRef `json:"role"`
+	// Actor is the identity that made the change, e.g. user:u000000001
+	Actor     string      `json:"actor,omitempty"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// TeamRoleNameSeparator can not be part of a team or role uid
+const TeamRoleNameSeparator = "."
+
+// TeamRoleName returns the name of the binding of a role to a team
+func TeamRoleName(teamUID string, roleUID string) string {
+	return teamUID + TeamRoleNameSeparator + roleUID
+}
+
+// TeamRoleSyncRequest is the desired set of roles of a team, for example derived from an identity provider group
+type TeamRoleSyncRequest struct {
+	// Roles are the uids of all roles the team should have, roles that are not listed are removed from the team.
+	// +listType=set
+	Roles []string `json:"roles"`
+}
+
+// TeamRoleSyncResult reports the changes made to reconcile the roles of a team
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TeamRoleSyncResult struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +listType=set
+	Added []string `json:"added"`
+	// +listType=set
+	Removed []string `json:"removed"`
+	// +listType=set
+	Unchanged []string `json:"unchanged"`
+}
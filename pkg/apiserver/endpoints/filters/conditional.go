@@ -0,0 +1,73 @@
+package filters
+
+import (
+	"net/http"
+
+	"k8s.io/apiserver/pkg/endpoints/responsewriter"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+// WithConditionalGet adds the If-None-Match header of GET requests to the request context. Storages that support
+// conditional requests set the ETag of the object, and return a 304 Not Modified status error when it matches,
+// the body of the error is dropped so the object is never serialized.
+func WithConditionalGet(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		c := &request.ConditionalGet{IfNoneMatch: req.Header.Get("If-None-Match")}
+		ctx := request.WithConditionalGet(req.Context(), c)
+		rw := responsewriter.WrapForHTTP1Or2(&conditionalGetResponseWriter{ResponseWriter: w, cond: c})
+		handler.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+type conditionalGetResponseWriter struct {
+	http.ResponseWriter
+	cond        *request.ConditionalGet
+	wroteHeader bool
+	notModified bool
+}
+
+var _ responsewriter.UserProvidedDecorator = &conditionalGetResponseWriter{}
+
+func (w *conditionalGetResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *conditionalGetResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if w.cond.ETag != "" && (code == http.StatusOK || code == http.StatusNotModified) {
+		h.Set("ETag", w.cond.ETag)
+		// the object is encoded according to the Accept header, caches must not mix up the encodings
+		h.Add("Vary", "Accept")
+		if w.cond.CacheControl != "" {
+			h.Set("Cache-Control", w.cond.CacheControl)
+		}
+	}
+	if code == http.StatusNotModified {
+		w.notModified = true
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *conditionalGetResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.notModified {
+		// a 304 response has no body
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,64 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+func TestWithConditionalGet(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, ok := request.ConditionalGetFrom(req.Context())
+		if !ok {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		c.ETag = `W/"1"`
+		c.CacheControl = "private, no-cache"
+		w.Header().Set("Content-Type", "application/json")
+		if c.Matches(c.ETag) {
+			w.WriteHeader(http.StatusNotModified)
+			_, _ = w.Write([]byte(`{"kind":"Status","code":304}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"kind":"Dashboard"}`))
+	})
+
+	t.Run("should set the ETag of the object", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rr := httptest.NewRecorder()
+		WithConditionalGet(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `W/"1"`, rr.Header().Get("ETag"))
+		require.Equal(t, "private, no-cache", rr.Header().Get("Cache-Control"))
+		require.Equal(t, `{"kind":"Dashboard"}`, rr.Body.String())
+	})
+
+	t.Run("should drop the body of not modified responses", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-None-Match", `W/"1"`)
+		rr := httptest.NewRecorder()
+		WithConditionalGet(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		require.Equal(t, `W/"1"`, rr.Header().Get("ETag"))
+		require.Empty(t, rr.Header().Get("Content-Type"))
+		require.Empty(t, rr.Body.String())
+	})
+
+	t.Run("should ignore other methods", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		req.Header.Set("If-None-Match", `W/"1"`)
+		rr := httptest.NewRecorder()
+		WithConditionalGet(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get("ETag"))
+	})
+}
@@ -0,0 +1,48 @@
+package filters
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithContentEncoding decompresses gzip encoded request bodies. The body is decompressed while it is read, so large
+// bodies are never held in memory compressed and decompressed, and the request body size limit of the apiserver
+// applies to the decompressed body.
+func WithContentEncoding(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding"))) {
+		case "", "identity":
+			handler.ServeHTTP(w, req)
+			return
+		case "gzip", "x-gzip":
+		default:
+			http.Error(w, "unsupported content encoding, only gzip is supported", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		zr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, "invalid gzip request body", http.StatusBadRequest)
+			return
+		}
+
+		decoded := req.Clone(req.Context())
+		decoded.Body = &gzipBody{Reader: zr, body: req.Body}
+		decoded.Header.Del("Content-Encoding")
+		decoded.Header.Del("Content-Length")
+		decoded.ContentLength = -1
+		handler.ServeHTTP(w, decoded)
+	})
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,68 @@
+package filters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithContentEncoding(t *testing.T) {
+	var body string
+	var encoding string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		body = string(b)
+		encoding = req.Header.Get("Content-Encoding")
+	})
+
+	t.Run("should pass through plain bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"spec":{}}`))
+		rr := httptest.NewRecorder()
+		WithContentEncoding(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `{"spec":{}}`, body)
+	})
+
+	t.Run("should decompress gzip bodies", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(`{"spec":{"title":"large"}}`))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		req := httptest.NewRequest(http.MethodPut, "/", &buf)
+		req.Header.Set("Content-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		WithContentEncoding(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `{"spec":{"title":"large"}}`, body)
+		require.Empty(t, encoding)
+	})
+
+	t.Run("should reject invalid gzip bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		WithContentEncoding(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should reject unsupported encodings", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("data"))
+		req.Header.Set("Content-Encoding", "br")
+		rr := httptest.NewRecorder()
+		WithContentEncoding(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	})
+}
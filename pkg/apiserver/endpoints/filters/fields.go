@@ -0,0 +1,29 @@
+package filters
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+// WithFields adds the fields of the fields query parameter of GET requests to the request context.
+// The fields are comma separated, and the parameter can be repeated.
+func WithFields(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		var fields []string
+		for _, v := range req.URL.Query()["fields"] {
+			for _, f := range strings.Split(v, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					fields = append(fields, f)
+				}
+			}
+		}
+		ctx := request.WithFields(req.Context(), fields)
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
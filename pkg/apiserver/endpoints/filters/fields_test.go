@@ -0,0 +1,46 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFields(t *testing.T) {
+	t.Run("should not set fields in context without the parameter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		rr := httptest.NewRecorder()
+		handler := &fakeHandler{}
+		WithFields(handler).ServeHTTP(rr, req)
+
+		_, ok := request.FieldsFrom(handler.ctx)
+		require.False(t, ok)
+	})
+
+	t.Run("should set the comma separated fields in context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?fields=spec.panels[*].title,%20spec.templating&fields=spec.title", nil)
+
+		rr := httptest.NewRecorder()
+		handler := &fakeHandler{}
+		WithFields(handler).ServeHTTP(rr, req)
+
+		fields, ok := request.FieldsFrom(handler.ctx)
+		require.True(t, ok)
+		require.Equal(t, []string{"spec.panels[*].title", "spec.templating", "spec.title"}, fields)
+	})
+
+	t.Run("should ignore the fields of other requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/?fields=spec.title", nil)
+
+		rr := httptest.NewRecorder()
+		handler := &fakeHandler{}
+		WithFields(handler).ServeHTTP(rr, req)
+
+		_, ok := request.FieldsFrom(handler.ctx)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,23 @@
+package filters
+
+import (
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+// WithIncludeObject adds the includeObject query parameter of GET requests to the request context, so the storages
+// know whether the rows of a requested table hold the objects before they list them.
+func WithIncludeObject(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		policy := metav1.IncludeObjectPolicy(req.URL.Query().Get("includeObject"))
+		ctx := request.WithIncludeObject(req.Context(), policy)
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
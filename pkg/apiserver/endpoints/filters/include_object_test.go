@@ -0,0 +1,48 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+func TestWithIncludeObject(t *testing.T) {
+	t.Run("should not set the policy in context without the parameter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		rr := httptest.NewRecorder()
+		handler := &fakeHandler{}
+		WithIncludeObject(handler).ServeHTTP(rr, req)
+
+		_, ok := request.IncludeObjectFrom(handler.ctx)
+		require.False(t, ok)
+	})
+
+	t.Run("should set the policy in context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?includeObject=Object", nil)
+
+		rr := httptest.NewRecorder()
+		handler := &fakeHandler{}
+		WithIncludeObject(handler).ServeHTTP(rr, req)
+
+		policy, ok := request.IncludeObjectFrom(handler.ctx)
+		require.True(t, ok)
+		require.Equal(t, metav1.IncludeObject, policy)
+	})
+
+	t.Run("should ignore the policy of other requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/?includeObject=Object", nil)
+
+		rr := httptest.NewRecorder()
+		handler := &fakeHandler{}
+		WithIncludeObject(handler).ServeHTTP(rr, req)
+
+		_, ok := request.IncludeObjectFrom(handler.ctx)
+		require.False(t, ok)
+	})
+}
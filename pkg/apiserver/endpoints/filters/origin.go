@@ -0,0 +1,21 @@
+package filters
+
+import (
+	"net/http"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+// WithOrigin adds the source IP and user agent of the request to the request context.
+func WithOrigin(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := request.Origin{UserAgent: req.UserAgent()}
+		if ip := utilnet.GetClientIP(req); ip != nil {
+			origin.SourceIP = ip.String()
+		}
+		ctx := request.WithOrigin(req.Context(), origin)
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
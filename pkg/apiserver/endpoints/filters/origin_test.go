@@ -0,0 +1,38 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+)
+
+func TestWithOrigin(t *testing.T) {
+	t.Run("should set the remote address and user agent in context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("User-Agent", "terraform")
+
+		handler := &fakeHandler{}
+		WithOrigin(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		origin, ok := request.OriginFrom(handler.ctx)
+		require.True(t, ok)
+		require.Equal(t, request.Origin{SourceIP: "10.0.0.1", UserAgent: "terraform"}, origin)
+	})
+
+	t.Run("should prefer the forwarded address", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Forwarded-For", "192.168.1.2, 10.0.0.1")
+
+		handler := &fakeHandler{}
+		WithOrigin(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		origin, ok := request.OriginFrom(handler.ctx)
+		require.True(t, ok)
+		require.Equal(t, "192.168.1.2", origin.SourceIP)
+	})
+}
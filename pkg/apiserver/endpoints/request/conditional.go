@@ -0,0 +1,45 @@
+package request
+
+import (
+	"context"
+	"strings"
+)
+
+type conditionalGetKey struct{}
+
+// ConditionalGet holds the If-None-Match header of a GET request, and the ETag and Cache-Control headers a storage
+// sets for the response.
+type ConditionalGet struct {
+	IfNoneMatch  string
+	ETag         string
+	CacheControl string
+}
+
+// WithConditionalGet adds the conditional GET state to the supplied context.
+func WithConditionalGet(ctx context.Context, c *ConditionalGet) context.Context {
+	return context.WithValue(ctx, conditionalGetKey{}, c)
+}
+
+// ConditionalGetFrom returns the conditional GET state from the supplied context and a boolean indicating if the value was present.
+func ConditionalGetFrom(ctx context.Context) (*ConditionalGet, bool) {
+	c, ok := ctx.Value(conditionalGetKey{}).(*ConditionalGet)
+	return c, ok && c != nil
+}
+
+// Matches reports whether the ETag matches one of the entity tags of the If-None-Match header.
+// Entity tags are compared with the weak comparison, as required for If-None-Match.
+func (c *ConditionalGet) Matches(etag string) bool {
+	if c.IfNoneMatch == "" || etag == "" {
+		return false
+	}
+	if strings.TrimSpace(c.IfNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(c.IfNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalGet(t *testing.T) {
+	t.Run("should not be present in an empty ctx", func(t *testing.T) {
+		c, ok := ConditionalGetFrom(context.Background())
+		require.False(t, ok)
+		require.Nil(t, c)
+	})
+
+	t.Run("should add the state to ctx", func(t *testing.T) {
+		in := &ConditionalGet{IfNoneMatch: `"1"`}
+		out, ok := ConditionalGetFrom(WithConditionalGet(context.Background(), in))
+		require.True(t, ok)
+		require.Same(t, in, out)
+	})
+
+	t.Run("should match the entity tags", func(t *testing.T) {
+		tests := []struct {
+			ifNoneMatch string
+			etag        string
+			matches     bool
+		}{
+			{ifNoneMatch: "", etag: `"1"`, matches: false},
+			{ifNoneMatch: `"1"`, etag: "", matches: false},
+			{ifNoneMatch: `"1"`, etag: `"1"`, matches: true},
+			{ifNoneMatch: `"1"`, etag: `"2"`, matches: false},
+			{ifNoneMatch: `W/"1"`, etag: `"1"`, matches: true},
+			{ifNoneMatch: `"1"`, etag: `W/"1"`, matches: true},
+			{ifNoneMatch: `"0", W/"1"`, etag: `W/"1"`, matches: true},
+			{ifNoneMatch: "*", etag: `"1"`, matches: true},
+		}
+		for _, tt := range tests {
+			c := &ConditionalGet{IfNoneMatch: tt.ifNoneMatch}
+			require.Equal(t, tt.matches, c.Matches(tt.etag), "If-None-Match: %s, ETag: %s", tt.ifNoneMatch, tt.etag)
+		}
+	})
+}
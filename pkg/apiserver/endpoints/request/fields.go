@@ -0,0 +1,22 @@
+package request
+
+import (
+	"context"
+)
+
+type fieldsKey struct{}
+
+// WithFields adds the fields requested with the fields query parameter to the supplied context.
+func WithFields(ctx context.Context, fields []string) context.Context {
+	// only add the fields to ctx if some were requested
+	if len(fields) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// FieldsFrom returns the requested fields from the supplied context and a boolean indicating if the value was present.
+func FieldsFrom(ctx context.Context) ([]string, bool) {
+	fields, ok := ctx.Value(fieldsKey{}).([]string)
+	return fields, ok
+}
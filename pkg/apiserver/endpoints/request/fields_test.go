@@ -0,0 +1,26 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should not set ctx without fields", func(t *testing.T) {
+		out := WithFields(ctx, nil)
+		fields, ok := FieldsFrom(out)
+		require.False(t, ok)
+		require.Empty(t, fields)
+	})
+
+	t.Run("should add fields to ctx", func(t *testing.T) {
+		out := WithFields(ctx, []string{"spec.title"})
+		fields, ok := FieldsFrom(out)
+		require.True(t, ok)
+		require.Equal(t, []string{"spec.title"}, fields)
+	})
+}
@@ -0,0 +1,25 @@
+package request
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type includeObjectKey struct{}
+
+// WithIncludeObject adds the includeObject policy requested for the rows of a table to the supplied context.
+func WithIncludeObject(ctx context.Context, policy metav1.IncludeObjectPolicy) context.Context {
+	// only add the policy to ctx if one was requested
+	if policy == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, includeObjectKey{}, policy)
+}
+
+// IncludeObjectFrom returns the requested includeObject policy from the supplied context and a boolean indicating if
+// the value was present.
+func IncludeObjectFrom(ctx context.Context) (metav1.IncludeObjectPolicy, bool) {
+	policy, ok := ctx.Value(includeObjectKey{}).(metav1.IncludeObjectPolicy)
+	return policy, ok
+}
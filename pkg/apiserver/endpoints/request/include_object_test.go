@@ -0,0 +1,27 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIncludeObject(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should not set ctx without a policy", func(t *testing.T) {
+		out := WithIncludeObject(ctx, "")
+		policy, ok := IncludeObjectFrom(out)
+		require.False(t, ok)
+		require.Empty(t, policy)
+	})
+
+	t.Run("should add the policy to ctx", func(t *testing.T) {
+		out := WithIncludeObject(ctx, metav1.IncludeObject)
+		policy, ok := IncludeObjectFrom(out)
+		require.True(t, ok)
+		require.Equal(t, metav1.IncludeObject, policy)
+	})
+}
@@ -0,0 +1,25 @@
+package request
+
+import (
+	"context"
+)
+
+type originKey struct{}
+
+// Origin describes where a request comes from.
+type Origin struct {
+	// SourceIP is the first address of X-Forwarded-For or X-Real-Ip, or the remote address of the request
+	SourceIP  string
+	UserAgent string
+}
+
+// WithOrigin adds the origin of the request to the supplied context.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom returns the origin of the request from the supplied context and a boolean indicating if the value was present.
+func OriginFrom(ctx context.Context) (Origin, bool) {
+	origin, ok := ctx.Value(originKey{}).(Origin)
+	return origin, ok
+}
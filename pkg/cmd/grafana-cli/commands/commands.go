@@ -167,6 +167,77 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "import-alert-state-history",
+		Usage:  "Imports alert state history from an external Loki instance or a Loki export into the state history of a running Grafana server",
+		Action: runPluginCommand(importStateHistoryCommand),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "The URL of the Grafana server",
+				Value: "http://localhost:3000",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "A service account token of a server admin",
+				EnvVars: []string{"GF_IMPORT_TOKEN"},
+			},
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "The login of a server admin, if no token is set",
+			},
+			&cli.StringFlag{
+				Name:    "password",
+				Usage:   "The password of the server admin",
+				EnvVars: []string{"GF_IMPORT_PASSWORD"},
+			},
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "The organization to import the state history into, defaults to the current organization of the user",
+			},
+			&cli.StringFlag{
+				Name:  "file",
+				Usage: "A file with state history exported from the Loki query_range API",
+			},
+			&cli.StringFlag{
+				Name:  "loki-url",
+				Usage: "The URL of the Loki instance to import the state history from",
+			},
+			&cli.StringFlag{
+				Name:  "loki-tenant-id",
+				Usage: "The tenant of the Loki instance",
+			},
+			&cli.StringFlag{
+				Name:  "loki-user",
+				Usage: "The basic auth user of the Loki instance",
+			},
+			&cli.StringFlag{
+				Name:    "loki-password",
+				Usage:   "The basic auth password of the Loki instance",
+				EnvVars: []string{"GF_IMPORT_LOKI_PASSWORD"},
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "The start of the time range to import, in seconds or RFC 3339. Required with --loki-url",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "The end of the time range to import, in seconds or RFC 3339. Defaults to now",
+			},
+			&cli.IntFlag{
+				Name:  "source-org-id",
+				Usage: "The organization the state history was recorded for, defaults to the organization imported into",
+			},
+			&cli.StringSliceFlag{
+				Name:  "map-rule",
+				Usage: "Maps the UID of a rule in the source to the UID of a rule in Grafana, <source rule UID>=<rule UID>",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Read the state history without importing it",
+			},
+		},
+	},
 }
 
 var Commands = []*cli.Command{
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const importStateHistoryPath = "/api/v1/rules/history/_import"
+
+// importStateHistoryCommand imports alert state history into the state history backend of a running Grafana server.
+// The import runs on the server because the configured backend is only available there.
+func importStateHistoryCommand(c utils.CommandLine) error {
+	body, err := stateHistoryImportFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.String("url"), "/")+importStateHistoryPath, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid Grafana URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := c.String("token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user := c.String("user"); user != "" {
+		req.SetBasicAuth(user, c.String("password"))
+	}
+	if orgID := c.Int("org-id"); orgID > 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.Itoa(orgID))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to import state history: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to import state history: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var res apimodels.StateHistoryImportResult
+	if err := json.Unmarshal(respBody, &res); err != nil {
+		return fmt.Errorf("failed to read the result of the import: %w", err)
+	}
+
+	logger.Infof("\n")
+	if res.DryRun {
+		logger.Infof("%d state transitions would be imported, %d skipped %s\n", res.Imported, res.Skipped, color.GreenString("✔"))
+	} else {
+		logger.Infof("Imported %d state transitions, %d skipped %s\n", res.Imported, res.Skipped, color.GreenString("✔"))
+	}
+	if len(res.MissingRules) > 0 {
+		logger.Infof("State history of rules that do not exist was skipped, map them with --map-rule: %s\n", strings.Join(res.MissingRules, ", "))
+	}
+	return nil
+}
+
+func stateHistoryImportFromFlags(c utils.CommandLine) (apimodels.StateHistoryImport, error) {
+	body := apimodels.StateHistoryImport{
+		SourceOrgID: int64(c.Int("source-org-id")),
+		DryRun:      c.Bool("dry-run"),
+	}
+
+	var err error
+	if body.From, err = parseImportTime(c.String("from")); err != nil {
+		return body, fmt.Errorf("invalid --from: %w", err)
+	}
+	if body.To, err = parseImportTime(c.String("to")); err != nil {
+		return body, fmt.Errorf("invalid --to: %w", err)
+	}
+
+	for _, m := range c.StringSlice("map-rule") {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok || from == "" || to == "" {
+			return body, fmt.Errorf("invalid --map-rule %q, expected <source rule UID>=<rule UID>", m)
+		}
+		if body.RuleUIDs == nil {
+			body.RuleUIDs = map[string]string{}
+		}
+		body.RuleUIDs[from] = to
+	}
+
+	file, lokiURL := c.String("file"), c.String("loki-url")
+	switch {
+	case file != "" && lokiURL != "":
+		return body, errors.New("either --file or --loki-url must be set, not both")
+	case file != "":
+		body.Streams, err = readStateHistoryExport(file)
+		if err != nil {
+			return body, err
+		}
+	case lokiURL != "":
+		body.Loki = &apimodels.StateHistoryImportLoki{
+			URL:               lokiURL,
+			TenantID:          c.String("loki-tenant-id"),
+			BasicAuthUser:     c.String("loki-user"),
+			BasicAuthPassword: c.String("loki-password"),
+		}
+	default:
+		return body, errors.New("either --file or --loki-url must be set")
+	}
+	return body, nil
+}
+
+// readStateHistoryExport reads state history exported from Loki, either a complete query_range response or its result array.
+func readStateHistoryExport(path string) ([]apimodels.StateHistoryImportStream, error) {
+	// nolint:gosec
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var streams []apimodels.StateHistoryImportStream
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &streams)
+	} else {
+		var res struct {
+			Data struct {
+				Result []apimodels.StateHistoryImportStream `json:"result"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(b, &res)
+		streams = res.Data.Result
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("%s does not contain any state history", path)
+	}
+	return streams, nil
+}
+
+// parseImportTime parses a timestamp in seconds or in RFC 3339 format.
+func parseImportTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
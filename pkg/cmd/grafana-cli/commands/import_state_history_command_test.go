@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestStateHistoryImportFromFlags(t *testing.T) {
+	dir := t.TempDir()
+	response := filepath.Join(dir, "response.json")
+	require.NoError(t, os.WriteFile(response, []byte(`{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"orgID":"1","from":"state-history"},"values":[["1700000000000000000","{\"ruleUID\":\"a\"}"]]}
+	]}}`), 0600))
+	result := filepath.Join(dir, "result.json")
+	require.NoError(t, os.WriteFile(result, []byte(`[{"stream":{"orgID":"1"},"values":[["1700000000000000000","{}"]]}]`), 0600))
+
+	t.Run("reads a query_range response", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{"file": response, "from": "2023-11-14T22:13:20Z", "source-org-id": "2", "dry-run": "true"})
+		require.NoError(t, err)
+
+		body, err := stateHistoryImportFromFlags(c)
+		require.NoError(t, err)
+		require.Equal(t, apimodels.StateHistoryImport{
+			Streams: []apimodels.StateHistoryImportStream{{
+				Stream: map[string]string{"orgID": "1", "from": "state-history"},
+				Values: [][2]string{{"1700000000000000000", `{"ruleUID":"a"}`}},
+			}},
+			From:        1700000000,
+			SourceOrgID: 2,
+			DryRun:      true,
+		}, body)
+	})
+
+	t.Run("reads a result array", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{"file": result, "to": "1700000000"})
+		require.NoError(t, err)
+
+		body, err := stateHistoryImportFromFlags(c)
+		require.NoError(t, err)
+		require.Len(t, body.Streams, 1)
+		require.Equal(t, int64(1700000000), body.To)
+	})
+
+	t.Run("reads from Loki", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{"loki-url": "http://loki:3100", "loki-tenant-id": "tenant"})
+		require.NoError(t, err)
+
+		body, err := stateHistoryImportFromFlags(c)
+		require.NoError(t, err)
+		require.Equal(t, &apimodels.StateHistoryImportLoki{URL: "http://loki:3100", TenantID: "tenant"}, body.Loki)
+	})
+
+	t.Run("requires exactly one source", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{})
+		require.NoError(t, err)
+		_, err = stateHistoryImportFromFlags(c)
+		require.Error(t, err)
+
+		c, err = commandstest.NewCliContext(map[string]string{"file": result, "loki-url": "http://loki:3100"})
+		require.NoError(t, err)
+		_, err = stateHistoryImportFromFlags(c)
+		require.Error(t, err)
+	})
+
+	t.Run("rejects invalid timestamps", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{"file": result, "from": "yesterday"})
+		require.NoError(t, err)
+		_, err = stateHistoryImportFromFlags(c)
+		require.Error(t, err)
+	})
+}
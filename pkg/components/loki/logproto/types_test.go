@@ -80,6 +80,29 @@ func TestCompatibility(t *testing.T) {
 	require.Equal(t, stream, new)
 }
 
+func TestStructuredMetadata(t *testing.T) {
+	withMetadata := Stream{
+		Labels: `{job="foobar"}`,
+		Entries: []Entry{
+			{Timestamp: now, Line: line, StructuredMetadata: []LabelAdapter{{Name: "trace_id", Value: "abc"}, {Name: "user", Value: ""}}},
+			{Timestamp: now.Add(1 * time.Second), Line: line},
+		},
+	}
+	b, err := withMetadata.Marshal()
+	require.NoError(t, err)
+
+	var new Stream
+	require.NoError(t, new.Unmarshal(b))
+	require.Equal(t, withMetadata, new)
+	require.True(t, withMetadata.Equal(new))
+
+	// readers without structured metadata skip it
+	var adapter StreamAdapter
+	require.NoError(t, adapter.Unmarshal(b))
+	require.Equal(t, line, adapter.Entries[0].Line)
+	require.True(t, now.Equal(adapter.Entries[0].Timestamp))
+}
+
 func BenchmarkStream(b *testing.B) {
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
@@ -81,3 +81,19 @@ type FolderFullPathUpdated struct {
 	UIDs      []string  `json:"uids"`
 	OrgID     int64     `json:"org_id"`
 }
+
+// DashboardChanged is emitted when a dashboard is created, updated or deleted through the dashboards API.
+// The versions are the generations of the dashboard before and after the change, 0 when it did not exist.
+type DashboardChanged struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Namespace  string    `json:"namespace"`
+	UID        string    `json:"uid"`
+	Verb       string    `json:"verb"`
+	APIVersion string    `json:"api_version"`
+	ActorUID   string    `json:"actor_uid"`
+	ActorLogin string    `json:"actor_login"`
+	OldVersion int64     `json:"old_version"`
+	NewVersion int64     `json:"new_version"`
+	SourceIP   string    `json:"source_ip"`
+	UserAgent  string    `json:"user_agent"`
+}
@@ -172,7 +172,11 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	span.SetAttributes(attribute.String("reducer", string(gr.Reducer)))
 
 	newRes := mathexp.Results{}
-	for i, val := range vars[gr.VarToReduce].Values {
+	vals, err := timeSeriesValues(vars[gr.VarToReduce].Values)
+	if err != nil {
+		return newRes, err
+	}
+	for i, val := range vals {
 		switch v := val.(type) {
 		case mathexp.Series:
 			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
@@ -208,6 +208,35 @@ func TestReduceExecute(t *testing.T) {
 		assert.NotEqual(t, v, noData[0])
 		assert.Equal(t, "no data", noData[0].AsDataFrame().Name)
 	})
+
+	t.Run("should reduce the tables marked as time series", func(t *testing.T) {
+		t0, t1 := time.Unix(0, 0).UTC(), time.Unix(60, 0).UTC()
+		long := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{t0, t0, t1, t1}),
+			data.NewField("avg", nil, []int64{1, 2, 3, 4}),
+			data.NewField("host", nil, []string{"a", "b", "a", "b"}),
+		)
+		long.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesLong}
+		table := data.NewFrame("", data.NewField("avg", nil, []float64{1}))
+		vars := map[string]mathexp.Results{
+			varToReduce: {Values: mathexp.Values{mathexp.TableData{Frame: long}}},
+		}
+
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), mathexp.ReducerID("last"), varToReduce, nil)
+		require.NoError(t, err)
+		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, results.Values, 2)
+		require.Equal(t, data.Labels{"host": "a"}, results.Values[0].GetLabels())
+		require.Equal(t, util.Pointer(3.0), results.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, data.Labels{"host": "b"}, results.Values[1].GetLabels())
+		require.Equal(t, util.Pointer(4.0), results.Values[1].(mathexp.Number).GetFloat64Value())
+		require.Len(t, long.Fields, 3, "the table is not changed")
+
+		vars[varToReduce] = mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: table}}}
+		_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "can only reduce type series")
+	})
 }
 
 func randomReduceFunc() mathexp.ReducerID {
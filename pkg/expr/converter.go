@@ -294,6 +294,42 @@ func WideToMany(frame *data.Frame, fixSeries func(series mathexp.Series, valueFi
 	return series, nil
 }
 
+// timeSeriesValues replaces the tables marked as time series, like the time series results of SQL expressions, by their
+// series so the expressions reading series accept them. The other values are returned unchanged.
+func timeSeriesValues(vals mathexp.Values) (mathexp.Values, error) {
+	out := make(mathexp.Values, 0, len(vals))
+	for _, val := range vals {
+		table, ok := val.(mathexp.TableData)
+		if !ok || table.Frame == nil || table.Frame.Meta == nil {
+			out = append(out, val)
+			continue
+		}
+		frame := table.Frame
+		switch frame.Meta.Type {
+		case data.FrameTypeTimeSeriesWide:
+			// the fields are replaced when the values are converted, the table is not changed
+			frame = &data.Frame{Name: frame.Name, RefID: frame.RefID, Fields: slices.Clone(frame.Fields)}
+		case data.FrameTypeTimeSeriesLong:
+			wide, err := data.LongToWide(frame, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert the long time series %s to series: %w", frame.RefID, err)
+			}
+			frame = wide
+		default:
+			out = append(out, val)
+			continue
+		}
+		series, err := WideToMany(frame, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
 // checkIfSeriesNeedToBeFixed scans all value fields of all provided frames and determines whether the resulting mathexp.Series
 // needs to be updated so each series could be identifiable by labels.
 // NOTE: applicable only to some datas ources (datasources.DS_GRAPHITE, datasources.DS_TESTDATA, etc.); a more general solution should be investigated
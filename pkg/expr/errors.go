@@ -3,6 +3,8 @@ package expr
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 )
@@ -75,6 +77,35 @@ func MakeDependencyError(refID, depRefID string) error {
 	return DependencyError.Build(data)
 }
 
+var dependencyCycleErrStr = "expressions depend on each other: {{ .Public.cycles }}"
+
+var DependencyCycleError = errutil.NewBase(
+	errutil.StatusBadRequest, "sse.dependencyCycle").MustTemplate(
+	dependencyCycleErrStr,
+	errutil.WithPublic(dependencyCycleErrStr))
+
+// makeDependencyCycleError returns the error of expressions that depend on each other, each cycle lists the RefIDs
+// from an input to the expression reading it, back to the first one, e.g. A, B, A
+func makeDependencyCycleError(cycles [][]string) error {
+	formatted := make([]string, 0, len(cycles))
+	refIDs := []string{}
+	for _, c := range cycles {
+		formatted = append(formatted, strings.Join(c, " -> "))
+		refIDs = append(refIDs, c[:len(c)-1]...)
+	}
+	slices.Sort(refIDs)
+	refIDs = slices.Compact(refIDs)
+	data := errutil.TemplateData{
+		Public: map[string]interface{}{
+			"cycles": strings.Join(formatted, ", "),
+			"refIds": refIDs,
+		},
+		Error: fmt.Errorf("dependency cycle between expressions %v", strings.Join(refIDs, ", ")),
+	}
+
+	return DependencyCycleError.Build(data)
+}
+
 var unexpectedNodeTypeErrString = "expected executable node type but got node type [{{ .Public.nodeType }} for refid [{{ .Public.refId}}]"
 
 var UnexpectedNodeTypeError = errutil.NewBase(
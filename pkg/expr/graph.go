@@ -3,12 +3,15 @@ package expr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/maps"
+	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
 
@@ -62,6 +65,8 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	// the tables persisted by SQL expressions only live for this evaluation
+	c = withSQLTables(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -203,6 +208,9 @@ func (s *Service) buildDependencyGraph(req *Request) (*simple.DirectedGraph, err
 	}
 
 	registry := buildNodeRegistry(graph)
+	if err := registerSQLTables(graph, registry); err != nil {
+		return nil, err
+	}
 
 	if err := buildGraphEdges(graph, registry); err != nil {
 		return nil, err
@@ -216,6 +224,10 @@ func (s *Service) buildDependencyGraph(req *Request) (*simple.DirectedGraph, err
 // be grouped into one request and executed first as phase after this call.
 func buildExecutionOrder(graph *simple.DirectedGraph) ([]Node, error) {
 	sortedNodes, err := topo.SortStabilized(graph, nil)
+	var unorderable topo.Unorderable
+	if errors.As(err, &unorderable) {
+		return nil, makeDependencyCycleError(dependencyCycles(graph, unorderable))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +240,64 @@ func buildExecutionOrder(graph *simple.DirectedGraph) ([]Node, error) {
 	return nodes, nil
 }
 
+// dependencyCycles returns a cycle of RefIDs for each set of nodes that depend on each other, from an input to the
+// node reading it, e.g. A, B, C, A when B reads A, C reads B and A reads C. The cycle starts with the smallest RefID
+// of the set and is the shortest one back to it.
+func dependencyCycles(g *simple.DirectedGraph, components topo.Unorderable) [][]string {
+	cycles := make([][]string, 0, len(components))
+	for _, component := range components {
+		if len(component) == 0 {
+			continue
+		}
+		inComponent := make(map[int64]bool, len(component))
+		for _, n := range component {
+			inComponent[n.ID()] = true
+		}
+		byRefID := func(a, b Node) int { return strings.Compare(a.RefID(), b.RefID()) }
+		start := slices.MinFunc(toNodes(component), byRefID)
+
+		// breadth first search of the shortest path from the start back to it
+		parents := map[int64]Node{}
+		queue := []Node{start}
+		var last Node
+		for len(queue) > 0 && last == nil {
+			current := queue[0]
+			queue = queue[1:]
+			next := toNodes(graph.NodesOf(g.From(current.ID())))
+			slices.SortFunc(next, byRefID)
+			for _, n := range next {
+				if n.ID() == start.ID() {
+					last = current
+					break
+				}
+				if _, seen := parents[n.ID()]; seen || !inComponent[n.ID()] {
+					continue
+				}
+				parents[n.ID()] = current
+				queue = append(queue, n)
+			}
+		}
+
+		cycle := []string{start.RefID()}
+		for n := last; n != nil && n.ID() != start.ID(); n = parents[n.ID()] {
+			cycle = append(cycle, n.RefID())
+		}
+		slices.Reverse(cycle[1:])
+		cycles = append(cycles, append(cycle, start.RefID()))
+	}
+	slices.SortFunc(cycles, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return cycles
+}
+
+// toNodes returns the pipeline nodes of a set of graph nodes
+func toNodes(nodes []graph.Node) []Node {
+	res := make([]Node, len(nodes))
+	for i, n := range nodes {
+		res[i] = n.(Node)
+	}
+	return res
+}
+
 // buildNodeRegistry returns a lookup table for reference IDs to respective node.
 func buildNodeRegistry(g *simple.DirectedGraph) map[string]Node {
 	res := make(map[string]Node)
@@ -243,6 +313,28 @@ func buildNodeRegistry(g *simple.DirectedGraph) map[string]Node {
 	return res
 }
 
+// registerSQLTables adds the temporary tables persisted by SQL expressions to the registry, so the SQL
+// expressions reading a table are evaluated after the one persisting it.
+func registerSQLTables(g *simple.DirectedGraph, registry map[string]Node) error {
+	nodeIt := g.Nodes()
+	for nodeIt.Next() {
+		cmdNode, ok := nodeIt.Node().(*CMDNode)
+		if !ok {
+			continue
+		}
+		sqlCmd, ok := cmdNode.Command.(*SQLCommand)
+		if !ok || sqlCmd.TemporaryTable() == "" {
+			continue
+		}
+		table := sqlCmd.TemporaryTable()
+		if other, ok := registry[table]; ok {
+			return fmt.Errorf("the temporary table '%v' of expression '%v' conflicts with '%v'", table, cmdNode.RefID(), other.RefID())
+		}
+		registry[table] = cmdNode
+	}
+	return nil
+}
+
 // buildGraph creates a new graph populated with nodes for every query.
 func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 	dp := simple.NewDirectedGraph()
@@ -279,7 +371,12 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 		case TypeDatasourceNode:
 			node, err = s.buildDSNode(dp, rn, req)
 		case TypeCMDNode:
-			node, err = buildCMDNode(rn, s.features)
+			var cmdNode *CMDNode
+			cmdNode, err = buildCMDNode(rn, s.features)
+			if err == nil {
+				err = s.configureSQLCommand(cmdNode, req.OrgId)
+			}
+			node = cmdNode
 		case TypeMLNode:
 			if s.features.IsEnabledGlobally(featuremgmt.FlagMlExpressions) {
 				node, err = s.buildMLNode(dp, rn, req)
@@ -316,6 +413,9 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 		}
 
 		cmdNode := node.(*CMDNode)
+		if sqlCmd, ok := cmdNode.Command.(*SQLCommand); ok {
+			sqlCmd.resolveTables(registry)
+		}
 
 		for _, neededVar := range cmdNode.Command.NeedsVars() {
 			neededNode, ok := registry[neededVar]
@@ -327,6 +427,13 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 				return fmt.Errorf("unable to find dependent node '%v'", neededVar)
 			}
 
+			if neededNode.RefID() != neededVar {
+				// a temporary table, only SQL expressions can read them
+				if _, ok := cmdNode.Command.(*SQLCommand); !ok {
+					return fmt.Errorf("unable to find dependent node '%v'", neededVar)
+				}
+			}
+
 			if neededNode.ID() == cmdNode.ID() {
 				return fmt.Errorf("expression '%v' cannot reference itself. Must be query or another expression", neededVar)
 			}
@@ -347,6 +454,16 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 
 			dp.SetEdge(edge)
 		}
+
+		// the SQL expressions reading the columns of all the inputs run after the data source queries
+		if sqlCmd, ok := cmdNode.Command.(*SQLCommand); ok && sqlCmd.ReadsSchemas() {
+			dsIt := dp.Nodes()
+			for dsIt.Next() {
+				if dsNode := dsIt.Node().(Node); dsNode.NodeType() == TypeDatasourceNode {
+					dp.SetEdge(dp.NewEdge(dsNode, cmdNode))
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/graph/simple"
 
+	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
@@ -63,7 +65,7 @@ func TestServicebuildPipeLine(t *testing.T) {
 					},
 				},
 			},
-			expectErrContains: "cyclic components",
+			expectErrContains: "expressions depend on each other: A -> B -> A",
 		},
 		{
 			name: "self reference will error",
@@ -291,3 +293,95 @@ func getRefIDOrder(nodes []Node) []string {
 	}
 	return ids
 }
+
+func TestRegisterSQLTables(t *testing.T) {
+	newGraph := func(nodes ...*CMDNode) (*simple.DirectedGraph, map[string]Node) {
+		g := simple.NewDirectedGraph()
+		for i, n := range nodes {
+			n.id = int64(i)
+			g.AddNode(n)
+		}
+		return g, buildNodeRegistry(g)
+	}
+	sqlNode := func(refID string, table string, tables ...string) *CMDNode {
+		cmd := &SQLCommand{refID: refID, varsToQuery: tables}
+		if table != "" {
+			cmd.table = &sql.TemporaryTable{Name: table}
+		}
+		return &CMDNode{baseNode: baseNode{refID: refID}, CMDType: TypeSQL, Command: cmd}
+	}
+
+	t.Run("orders the expressions reading a table after the one persisting it", func(t *testing.T) {
+		g, registry := newGraph(
+			sqlNode("C", "", "joined"),
+			sqlNode("D", "", "C", "joined"),
+			sqlNode("B", "joined"),
+		)
+		require.NoError(t, registerSQLTables(g, registry))
+		require.NoError(t, buildGraphEdges(g, registry))
+		nodes, err := buildExecutionOrder(g)
+		require.NoError(t, err)
+		require.Equal(t, []string{"B", "C", "D"}, getRefIDOrder(nodes))
+	})
+
+	t.Run("tables conflict with other tables and expressions", func(t *testing.T) {
+		g, registry := newGraph(sqlNode("B", "joined"), sqlNode("C", "joined"))
+		require.ErrorContains(t, registerSQLTables(g, registry), "conflicts")
+
+		g, registry = newGraph(sqlNode("B", "C"), sqlNode("C", ""))
+		require.ErrorContains(t, registerSQLTables(g, registry), "conflicts")
+	})
+
+	t.Run("tables name the expressions in any case", func(t *testing.T) {
+		g, registry := newGraph(sqlNode("C", "", "b", "cte"), sqlNode("B", "", "A"), sqlNode("A", ""))
+		require.NoError(t, registerSQLTables(g, registry))
+		require.NoError(t, buildGraphEdges(g, registry))
+		nodes, err := buildExecutionOrder(g)
+		require.NoError(t, err)
+		require.Equal(t, []string{"A", "B", "C"}, getRefIDOrder(nodes))
+		require.Equal(t, []string{"B", "cte"}, nodes[2].(*CMDNode).Command.NeedsVars())
+	})
+
+	t.Run("cycles name the expressions", func(t *testing.T) {
+		g, registry := newGraph(
+			sqlNode("D", "", "B"),
+			sqlNode("C", "", "joined"),
+			sqlNode("B", "joined", "C"),
+			sqlNode("F", "", "E"),
+			sqlNode("E", "", "G"),
+			sqlNode("G", "", "f"),
+			sqlNode("H", "", "D"),
+		)
+		require.NoError(t, registerSQLTables(g, registry))
+		require.NoError(t, buildGraphEdges(g, registry))
+		_, err := buildExecutionOrder(g)
+		require.ErrorIs(t, err, DependencyCycleError)
+		require.EqualError(t, err, "[sse.dependencyCycle] expressions depend on each other: B -> C -> B, E -> F -> G -> E")
+	})
+
+	t.Run("only SQL expressions read tables", func(t *testing.T) {
+		math, err := NewMathCommand("C", "$joined")
+		require.NoError(t, err)
+		g, registry := newGraph(sqlNode("B", "joined"), &CMDNode{baseNode: baseNode{refID: "C"}, CMDType: TypeMath, Command: math})
+		require.NoError(t, registerSQLTables(g, registry))
+		require.ErrorContains(t, buildGraphEdges(g, registry), "unable to find dependent node 'joined'")
+	})
+}
+
+func TestSQLSchemasOrder(t *testing.T) {
+	g := simple.NewDirectedGraph()
+	nodes := []Node{
+		&CMDNode{baseNode: baseNode{id: 0, refID: "C"}, CMDType: TypeSQL, Command: &SQLCommand{refID: "C", schemas: true}},
+		&DSNode{baseNode: baseNode{id: 1, refID: "A"}},
+		&DSNode{baseNode: baseNode{id: 2, refID: "B"}},
+	}
+	for _, n := range nodes {
+		g.AddNode(n)
+	}
+	registry := buildNodeRegistry(g)
+	require.NoError(t, buildGraphEdges(g, registry))
+
+	order, err := buildExecutionOrder(g)
+	require.NoError(t, err)
+	require.Equal(t, "C", order[2].RefID(), "the data source queries are evaluated first")
+}
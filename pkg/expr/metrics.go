@@ -12,6 +12,10 @@ const (
 type metrics struct {
 	dsRequests *prometheus.CounterVec
 
+	sqlCommandDuration   *prometheus.HistogramVec
+	sqlCommandInputRows  prometheus.Histogram
+	sqlCommandOutputRows prometheus.Histogram
+
 	// older metric
 	expressionsQuerySummary *prometheus.SummaryVec
 }
@@ -25,6 +29,30 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Help:      "Number of datasource queries made via server side expression requests",
 		}, []string{"error", "dataplane", "datasource_type"}),
 
+		sqlCommandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "sql_command_duration_milliseconds",
+			Help:      "Duration of SQL expression executions",
+			Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000, 10000, 30000},
+		}, []string{"status"}),
+
+		sqlCommandInputRows: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "sql_command_input_rows",
+			Help:      "Number of rows in the frames SQL expressions read",
+			Buckets:   prometheus.ExponentialBuckets(10, 10, 6),
+		}),
+
+		sqlCommandOutputRows: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "sql_command_output_rows",
+			Help:      "Number of rows SQL expressions return",
+			Buckets:   prometheus.ExponentialBuckets(10, 10, 6),
+		}),
+
 		// older (No Namespace or Subsystem)
 		expressionsQuerySummary: prometheus.NewSummaryVec(
 			prometheus.SummaryOpts{
@@ -39,6 +67,9 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 	if reg != nil {
 		reg.MustRegister(
 			m.dsRequests,
+			m.sqlCommandDuration,
+			m.sqlCommandInputRows,
+			m.sqlCommandOutputRows,
 			m.expressionsQuerySummary,
 		)
 	}
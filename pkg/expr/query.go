@@ -77,6 +77,9 @@ type ClassicQuery struct {
 // SQLQuery requires the sqlExpression feature flag
 type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
+
+	// Return the query plan as an extra frame, to debug slow or unexpected joins
+	Explain bool `json:"explain,omitempty"`
 }
 
 //-------------------------------
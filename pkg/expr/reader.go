@@ -3,6 +3,7 @@ package expr
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/data/utils/jsoniter"
@@ -133,8 +134,25 @@ func (h *ExpressionQueryReader) ReadQuery(
 		q := &SQLExpression{}
 		err = iter.ReadVal(q)
 		if err == nil {
+			var tr TimeRange
+			if common.TimeRange != nil {
+				gtr := gtime.NewTimeRange(common.TimeRange.From, common.TimeRange.To)
+				tr = AbsoluteTimeRange{
+					From: gtr.GetFromAsTimeUTC(),
+					To:   gtr.GetToAsTimeUTC(),
+				}
+			}
+			interval := time.Duration(defaultIntervalMS) * time.Millisecond
+			if common.IntervalMS > 0 {
+				interval = time.Duration(common.IntervalMS * float64(time.Millisecond))
+			}
 			eq.Properties = q
-			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
+			var cmd *SQLCommand
+			cmd, err = NewSQLCommand(common.RefID, q.Expression, tr, interval)
+			if err == nil {
+				cmd.Explain(q.Explain)
+				eq.Command = cmd
+			}
 		}
 
 	case QueryTypeThreshold:
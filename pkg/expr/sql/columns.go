@@ -0,0 +1,204 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// fromClauseEnd are the keywords ending the tables of a FROM clause
+var fromClauseEnd = map[string]bool{
+	"WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true, "LIMIT": true, "UNION": true, "WINDOW": true,
+}
+
+// notAlias are the keywords following a table of a FROM clause that are not its alias
+var notAlias = map[string]bool{
+	"ON": true, "USING": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "CROSS": true, "NATURAL": true,
+	"FULL": true, "OUTER": true, "STRAIGHT_JOIN": true,
+}
+
+// UniqueColumns renames the columns of a result that have the same name, e.g. the value columns of a join of two
+// frames, so the fields of the result can be told apart. Columns read from a table are prefixed with the RefID of
+// the table, A_value and B_value, the others are numbered, value and value_2. A notice reports the new names,
+// naming the columns with AS in the query avoids the conflict.
+func UniqueColumns(query string, inputs []*data.Frame, result *data.Frame) []data.Notice {
+	byName := map[string][]int{}
+	names := []string{}
+	taken := map[string]bool{}
+	for i, f := range result.Fields {
+		if _, ok := byName[f.Name]; !ok {
+			names = append(names, f.Name)
+		}
+		byName[f.Name] = append(byName[f.Name], i)
+		taken[f.Name] = true
+	}
+
+	var sources []string
+	notices := []data.Notice{}
+	for _, name := range names {
+		fields := byName[name]
+		if len(fields) < 2 {
+			continue
+		}
+		if sources == nil {
+			sources = columnSources(query, inputs, len(result.Fields))
+		}
+
+		prefixed := map[string]bool{}
+		for _, i := range fields {
+			if sources[i] == "" || prefixed[sources[i]] {
+				prefixed = nil
+				break
+			}
+			prefixed[sources[i]] = true
+		}
+		renamed := make([]string, 0, len(fields))
+		for n, i := range fields {
+			switch {
+			case prefixed != nil:
+				result.Fields[i].Name = uniqueColumnName(taken, sources[i]+"_"+name, 1)
+			case n > 0:
+				result.Fields[i].Name = uniqueColumnName(taken, name, n+1)
+			}
+			renamed = append(renamed, result.Fields[i].Name)
+		}
+
+		table := "A"
+		if sources[fields[0]] != "" {
+			table = sources[fields[0]]
+		}
+		notices = append(notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text: fmt.Sprintf("The result has %d columns named %s, they are returned as %s. Name the columns with AS to choose their names, e.g. SELECT %s.%s AS %s_%s.",
+				len(fields), name, strings.Join(renamed, ", "), table, name, strings.ToLower(table), name),
+		})
+	}
+	if len(notices) == 0 {
+		return nil
+	}
+	return notices
+}
+
+// uniqueColumnName returns the name numbered n, name_n, or with the next number not taken by a column, and marks
+// it as taken. The first name is not numbered.
+func uniqueColumnName(taken map[string]bool, name string, n int) string {
+	unique := name
+	if n > 1 {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	for n = max(n, 1); taken[unique]; {
+		n++
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// IsAmbiguousColumnError returns true when the query failed because a column it reads without its table is in
+// several of the joined frames
+func IsAmbiguousColumnError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "ambiguous column")
+}
+
+// columnSources returns the RefID of the table every column of the result is read from, in the order of the
+// columns of the result. The RefID is empty for the columns computed or named with AS, and for all the columns
+// when the select list can not be matched with the count of columns of the result.
+func columnSources(query string, inputs []*data.Frame, count int) []string {
+	unknown := make([]string, count)
+	tokens, err := scanTokens(query)
+	if err != nil {
+		return unknown
+	}
+
+	columns := map[string][]string{}
+	for _, f := range inputs {
+		if f == nil {
+			continue
+		}
+		if _, ok := columns[f.RefID]; ok {
+			continue
+		}
+		for _, field := range f.Fields {
+			columns[f.RefID] = append(columns[f.RefID], field.Name)
+		}
+	}
+
+	// the tables of the FROM clause in order, and the RefIDs of the tables by name and alias
+	tables := []string{}
+	refIDs := map[string]string{}
+	addTable := func(i int) {
+		table := tokens.name(i)
+		if _, ok := columns[table]; !ok {
+			return
+		}
+		tables = append(tables, table)
+		refIDs[table] = table
+		if tokens.word(i+1) == "AS" {
+			i++
+		}
+		if alias := tokens.name(i + 1); alias != "" && !notAlias[strings.ToUpper(alias)] && !fromClauseEnd[strings.ToUpper(alias)] {
+			refIDs[alias] = table
+		}
+	}
+	selectStart, selectEnd := -1, -1
+	inFrom := false
+	for i, t := range tokens {
+		if t.depth != 0 {
+			continue
+		}
+		switch word := t.word(); {
+		case word == "SELECT" && selectStart < 0:
+			selectStart = i + 1
+		case word == "FROM" && selectEnd < 0:
+			selectEnd, inFrom = i, true
+			addTable(i + 1)
+		case fromClauseEnd[word]:
+			inFrom = false
+		case inFrom && (word == "JOIN" || t.text == ","):
+			addTable(i + 1)
+		}
+	}
+	if selectStart < 0 || selectEnd < selectStart {
+		return unknown
+	}
+
+	sources := []string{}
+	item := tokenList{}
+	addItem := func() {
+		switch {
+		case len(item) == 1 && item[0].text == "*":
+			for _, table := range tables {
+				for range columns[table] {
+					sources = append(sources, table)
+				}
+			}
+		case len(item) == 3 && item[1].text == "." && item[2].text == "*":
+			table := refIDs[item.name(0)]
+			for range columns[table] {
+				sources = append(sources, table)
+			}
+		case len(item) == 3 && item[1].text == ".":
+			sources = append(sources, refIDs[item.name(0)])
+		default:
+			sources = append(sources, "")
+		}
+		item = tokenList{}
+	}
+	for _, t := range tokens[selectStart:selectEnd] {
+		if t.depth == 0 && t.text == "," {
+			addItem()
+			continue
+		}
+		if len(item) == 0 && t.depth == 0 && (t.word() == "DISTINCT" || t.word() == "ALL") {
+			continue
+		}
+		item = append(item, t)
+	}
+	addItem()
+
+	if len(sources) != count {
+		return unknown
+	}
+	return sources
+}
@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueColumns(t *testing.T) {
+	a := data.NewFrame("", data.NewField("time", nil, []float64{1}), data.NewField("value", nil, []float64{1}))
+	a.RefID = "A"
+	b := data.NewFrame("", data.NewField("time", nil, []float64{1}), data.NewField("value", nil, []float64{2}))
+	b.RefID = "B"
+	inputs := []*data.Frame{a, b}
+
+	result := func(names ...string) *data.Frame {
+		f := data.NewFrame("")
+		for _, name := range names {
+			f.Fields = append(f.Fields, data.NewField(name, nil, []float64{1}))
+		}
+		return f
+	}
+	names := func(f *data.Frame) []string {
+		out := []string{}
+		for _, field := range f.Fields {
+			out = append(out, field.Name)
+		}
+		return out
+	}
+
+	t.Run("prefixes the columns of a join with their table", func(t *testing.T) {
+		f := result("time", "value", "time", "value")
+		notices := UniqueColumns("SELECT * FROM B JOIN A ON A.time = B.time", inputs, f)
+		require.Equal(t, []string{"B_time", "B_value", "A_time", "A_value"}, names(f))
+		require.Equal(t, []data.Notice{
+			{Severity: data.NoticeSeverityWarning, Text: "The result has 2 columns named time, they are returned as B_time, A_time. Name the columns with AS to choose their names, e.g. SELECT B.time AS b_time."},
+			{Severity: data.NoticeSeverityWarning, Text: "The result has 2 columns named value, they are returned as B_value, A_value. Name the columns with AS to choose their names, e.g. SELECT B.value AS b_value."},
+		}, notices)
+	})
+
+	t.Run("resolves the aliases of the tables", func(t *testing.T) {
+		f := result("time", "value", "value")
+		UniqueColumns("SELECT x.time, y.value, x.value FROM A AS x, B y WHERE x.time = y.time", inputs, f)
+		require.Equal(t, []string{"time", "B_value", "A_value"}, names(f))
+
+		f = result("value", "time", "value")
+		UniqueColumns("SELECT A.value, B.* FROM A JOIN B ON A.time = B.time", inputs, f)
+		require.Equal(t, []string{"A_value", "time", "B_value"}, names(f))
+	})
+
+	t.Run("numbers the columns without a table", func(t *testing.T) {
+		f := result("value", "value", "value_2")
+		notices := UniqueColumns("SELECT A.value, B.value + 1 AS value, 3 AS value_2 FROM A JOIN B ON A.time = B.time", inputs, f)
+		require.Equal(t, []string{"value", "value_3", "value_2"}, names(f))
+		require.Len(t, notices, 1)
+	})
+
+	t.Run("keeps unique columns", func(t *testing.T) {
+		f := result("time", "value")
+		require.Nil(t, UniqueColumns("SELECT * FROM A", inputs, f))
+		require.Equal(t, []string{"time", "value"}, names(f))
+	})
+}
+
+func TestIsAmbiguousColumnError(t *testing.T) {
+	require.True(t, IsAmbiguousColumnError(errors.New(`ambiguous column name "value", it's present in all these tables: [A B]`)))
+	require.False(t, IsAmbiguousColumnError(errors.New("table not found: C")))
+	require.False(t, IsAmbiguousColumnError(nil))
+}
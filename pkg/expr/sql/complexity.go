@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Limits are the maximum complexity of the statements of SQL expressions, zero values are unlimited.
+type Limits struct {
+	// MaxJoins is the maximum number of JOIN clauses of a statement, in all its subqueries.
+	MaxJoins int
+	// MaxSubqueryDepth is the maximum number of subqueries nested in each other.
+	MaxSubqueryDepth int
+}
+
+// ValidateComplexity returns an error when rawSQL has more joins or deeper subqueries than the limits allow,
+// so expensive statements are rejected before they run. Strings and comments are ignored.
+func ValidateComplexity(rawSQL string, limits Limits) error {
+	if limits.MaxJoins == 0 && limits.MaxSubqueryDepth == 0 {
+		return nil
+	}
+	statements, err := splitStatements(rawSQL)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		joins, depth := statementComplexity(stmt)
+		if limits.MaxJoins > 0 && joins > limits.MaxJoins {
+			return fmt.Errorf("SQL expressions can have at most %d joins, found %d", limits.MaxJoins, joins)
+		}
+		if limits.MaxSubqueryDepth > 0 && depth > limits.MaxSubqueryDepth {
+			return fmt.Errorf("SQL expressions can nest subqueries at most %d levels deep, found %d", limits.MaxSubqueryDepth, depth)
+		}
+	}
+	return nil
+}
+
+// statementComplexity returns the number of joins of stmt and the maximum depth of its subqueries.
+// A subquery is a parenthesis whose first word is SELECT or WITH.
+func statementComplexity(stmt string) (joins int, maxDepth int) {
+	type paren struct {
+		// first is true until the first word in the parenthesis is read
+		first    bool
+		subquery bool
+	}
+	parens := []paren{}
+	depth := 0
+	word := strings.Builder{}
+	endWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := strings.ToUpper(word.String())
+		word.Reset()
+		if w == "JOIN" {
+			joins++
+		}
+		if n := len(parens); n > 0 && parens[n-1].first {
+			parens[n-1].first = false
+			if w == "SELECT" || w == "WITH" {
+				parens[n-1].subquery = true
+				depth++
+				maxDepth = max(maxDepth, depth)
+			}
+		}
+	}
+	for _, r := range stmt {
+		switch {
+		case r == '(':
+			endWord()
+			parens = append(parens, paren{first: true})
+		case r == ')':
+			endWord()
+			if n := len(parens); n > 0 {
+				if parens[n-1].subquery {
+					depth--
+				}
+				parens = parens[:n-1]
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word.WriteRune(r)
+		default:
+			endWord()
+		}
+	}
+	endWord()
+	return joins, maxDepth
+}
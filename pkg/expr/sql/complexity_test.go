@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateComplexity(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		limits Limits
+		err    string
+	}{
+		{
+			name: "unlimited",
+			sql:  "SELECT * FROM A JOIN B ON A.x = B.x JOIN C ON B.x = C.x",
+		},
+		{
+			name:   "joins within the limit",
+			sql:    "SELECT * FROM A LEFT JOIN B ON A.x = B.x",
+			limits: Limits{MaxJoins: 1},
+		},
+		{
+			name:   "too many joins",
+			sql:    "SELECT * FROM A JOIN B ON A.x = B.x WHERE A.x IN (SELECT x FROM C join D ON C.x = D.x)",
+			limits: Limits{MaxJoins: 1},
+			err:    "at most 1 joins, found 2",
+		},
+		{
+			name:   "joins in strings and comments are ignored",
+			sql:    "SELECT 'JOIN' AS j FROM A -- JOIN B\n/* JOIN C */",
+			limits: Limits{MaxJoins: 0, MaxSubqueryDepth: 1},
+		},
+		{
+			name:   "subqueries within the limit",
+			sql:    "SELECT * FROM (SELECT x FROM A) a WHERE a.x IN (SELECT x FROM B) AND a.x > (1 + 2)",
+			limits: Limits{MaxSubqueryDepth: 1},
+		},
+		{
+			name:   "nested subqueries",
+			sql:    "SELECT * FROM (SELECT * FROM (with b AS (SELECT 1) SELECT * FROM b) c) d",
+			limits: Limits{MaxSubqueryDepth: 2},
+			err:    "at most 2 levels deep, found 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateComplexity(tt.sql, tt.limits)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
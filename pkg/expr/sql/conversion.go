@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ConversionErrors collects the values that could not be converted when the frames are loaded into tables and the
+// results are converted back to frames. Passed to the conversions, it makes them tolerant: the values that can not be
+// converted are NULL instead of failing the whole conversion, so a rare invalid value returned by a data source does
+// not fail the expression. A nil *ConversionErrors keeps the conversions strict.
+type ConversionErrors struct {
+	mu      sync.Mutex
+	columns []string
+	counts  map[string]int
+	first   map[string]error
+}
+
+// NewConversionErrors returns an empty collection, the conversions it is passed to are tolerant.
+func NewConversionErrors() *ConversionErrors {
+	return &ConversionErrors{counts: map[string]int{}, first: map[string]error{}}
+}
+
+// add records a value of the column that could not be converted. It returns err when e is nil, the conversion
+// is then strict and fails.
+func (e *ConversionErrors) add(column string, err error) error {
+	if e == nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.counts[column]; !ok {
+		e.columns = append(e.columns, column)
+		e.first[column] = err
+	}
+	e.counts[column]++
+	return nil
+}
+
+// Count returns the number of values of the column that could not be converted.
+func (e *ConversionErrors) Count(column string) int {
+	if e == nil {
+		return 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.counts[column]
+}
+
+// Notices returns a warning per column with values that could not be converted, with their count and the error of
+// the first one, in the order the columns were first found.
+func (e *ConversionErrors) Notices() []data.Notice {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	notices := make([]data.Notice, 0, len(e.columns))
+	for _, column := range e.columns {
+		text := fmt.Sprintf("%d values of column %s could not be converted and are NULL", e.counts[column], column)
+		if e.counts[column] == 1 {
+			text = fmt.Sprintf("1 value of column %s could not be converted and is NULL", column)
+		}
+		notices = append(notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("%s, the first error: %s", text, e.first[column]),
+		})
+	}
+	return notices
+}
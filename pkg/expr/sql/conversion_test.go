@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversionErrors(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("doc", nil, []json.RawMessage{json.RawMessage(`{"a":1}`), json.RawMessage(`{`), json.RawMessage(`[`)}),
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+	)
+	frame.RefID = "A"
+
+	t.Run("the conversions are strict without conversion errors", func(t *testing.T) {
+		_, err := TableRows(frame, nil)
+		require.ErrorContains(t, err, "invalid JSON in field doc at row 1")
+	})
+
+	t.Run("the values that can not be converted are nil", func(t *testing.T) {
+		errs := NewConversionErrors()
+		rows, err := TableRows(frame, errs)
+		require.NoError(t, err)
+		require.Equal(t, [][]any{
+			{map[string]any{"a": float64(1)}, "a"},
+			{nil, "b"},
+			{nil, "c"},
+		}, rows)
+		require.Equal(t, 2, errs.Count("A.doc"))
+
+		b := NewResultFrameBuilder("B", []string{"value"}, 0, errs)
+		require.NoError(t, b.Append([]any{true}))
+		require.NoError(t, b.Append([]any{"x"}))
+
+		notices := errs.Notices()
+		require.Len(t, notices, 2)
+		require.Equal(t, data.NoticeSeverityWarning, notices[0].Severity)
+		require.Contains(t, notices[0].Text, "2 values of column A.doc could not be converted and are NULL, the first error: invalid JSON in field doc at row 1")
+		require.Contains(t, notices[1].Text, "1 value of column value could not be converted and is NULL, the first error: column value: unexpected string at row 1")
+	})
+
+	t.Run("a nil collection has no notices", func(t *testing.T) {
+		var errs *ConversionErrors
+		require.Empty(t, errs.Notices())
+		require.Equal(t, 0, errs.Count("A.doc"))
+	})
+}
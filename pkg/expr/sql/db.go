@@ -1,22 +1,149 @@
 package sql
 
 import (
+	"context"
 	"errors"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+var errNotImplemented = errors.New("not implemented")
+
 type DB struct {
+	// storage is where the tables the frames are loaded into keep their rows
+	storage StorageOptions
+	// conversions records the values that can not be converted, nil when they fail the query
+	conversions *ConversionErrors
+	// engine runs the queries over the tables
+	engine engine
+	// tracer traces the stages of the queries
+	tracer trace.Tracer
+}
+
+// table is a frame loaded into the database, named after the RefID of the frame.
+type table struct {
+	name    string
+	columns []Column
+	rows    RowStore
+}
+
+// engine runs the queries over the tables the frames are loaded into.
+type engine interface {
+	// query returns the columns and the rows of the result of query, args are bound to its placeholders. It stops
+	// and returns the error of ctx when ctx is done before the result.
+	query(ctx context.Context, tables []table, query string, args []any) ([]string, [][]any, error)
+}
+
+// unavailableEngine fails every query, no SQL engine is embedded in this build.
+type unavailableEngine struct{}
+
+func (unavailableEngine) query(context.Context, []table, string, []any) ([]string, [][]any, error) {
+	return nil, nil, errNotImplemented
 }
 
 func (db *DB) RunCommands(commands []string) (string, error) {
-	return "", errors.New("not implemented")
+	return "", errNotImplemented
+}
+
+// QueryFramesInto runs query over the frames and writes the result into f.
+// Every frame is loaded into a table named after its RefID, with the columns of TableColumns and the rows in the order
+// of the frame, kept in a store of the storage of the database, see LoadTable. The result is converted with
+// ResultFrame, so f has the columns and the rows in the order the engine returns them.
+// args are bound to the placeholders of the query, see BindParameters. A value that can not be converted fails the
+// query, unless TolerateConversionErrors is set.
+// The query stops with the error of ctx when ctx is done, f is only written when the query succeeds.
+// No engine is embedded in this build, the query fails once the tables are loaded.
+func (db *DB) QueryFramesInto(ctx context.Context, name string, query string, frames []*data.Frame, f *data.Frame, args ...any) error {
+	tables, err := db.loadTables(ctx, frames)
+	defer closeTables(tables)
+	if err != nil {
+		return err
+	}
+	columns, rows, err := db.query(ctx, tables, query, args)
+	if err != nil {
+		return err
+	}
+	result, err := db.convertResult(ctx, name, columns, rows)
+	if err != nil {
+		return err
+	}
+	*f = *result
+	return nil
+}
+
+// loadTables loads every frame into a table. The tables loaded before an error are returned with it.
+func (db *DB) loadTables(ctx context.Context, frames []*data.Frame) (tables []table, err error) {
+	_, span := db.tracer.Start(ctx, "SSE.ExecuteSQL.LoadTables", trace.WithAttributes(attribute.Int("tables", len(frames))))
+	defer endSpan(span, &err)
+	tables = make([]table, 0, len(frames))
+	for _, frame := range frames {
+		if err := ctx.Err(); err != nil {
+			return tables, err
+		}
+		rows, err := LoadTable(frame, db.storage, db.conversions)
+		if err != nil {
+			return tables, err
+		}
+		tables = append(tables, table{name: frame.RefID, columns: TableColumns(frame), rows: rows})
+	}
+	return tables, nil
+}
+
+// query runs the query over the tables with the engine
+func (db *DB) query(ctx context.Context, tables []table, query string, args []any) (columns []string, rows [][]any, err error) {
+	ctx, span := db.tracer.Start(ctx, "SSE.ExecuteSQL.Query")
+	defer endSpan(span, &err)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	columns, rows, err = db.engine.query(ctx, tables, query, args)
+	span.SetAttributes(attribute.Int("rows", len(rows)))
+	return columns, rows, err
+}
+
+// convertResult converts the result of the engine to a frame
+func (db *DB) convertResult(ctx context.Context, name string, columns []string, rows [][]any) (f *data.Frame, err error) {
+	_, span := db.tracer.Start(ctx, "SSE.ExecuteSQL.ConvertResult")
+	defer endSpan(span, &err)
+	return ResultFrame(name, columns, rows, db.conversions)
+}
+
+// endSpan ends the span of a stage, with the error of the stage if any
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.SetStatus(codes.Error, (*err).Error())
+		span.RecordError(*err)
+	}
+	span.End()
+}
+
+func closeTables(tables []table) {
+	for _, t := range tables {
+		_ = t.rows.Close()
+	}
+}
+
+// NewDB returns a database whose tables keep their rows as configured by storage.
+func NewDB(storage StorageOptions) *DB {
+	return &DB{storage: storage, engine: unavailableEngine{}, tracer: noop.NewTracerProvider().Tracer("sql-expressions")}
+}
+
+// TraceQueries makes the queries report a span for every stage: loading the tables, running the query and
+// converting the result.
+func (db *DB) TraceQueries(tracer trace.Tracer) {
+	db.tracer = tracer
 }
 
-func (db *DB) QueryFramesInto(name string, query string, frames []*data.Frame, f *data.Frame) error {
-	return errors.New("not implemented")
+// TolerateConversionErrors makes the queries load the values of the frames, and return the values of the results,
+// that can not be converted as NULL, and record them in errs.
+func (db *DB) TolerateConversionErrors(errs *ConversionErrors) {
+	db.conversions = errs
 }
 
 func NewInMemoryDB() *DB {
-	return &DB{}
+	return NewDB(StorageOptions{})
 }
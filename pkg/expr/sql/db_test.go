@@ -0,0 +1,203 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// engineFunc is an engine for the tests, it runs fn instead of a query
+type engineFunc func(ctx context.Context, tables []table) ([]string, [][]any, error)
+
+func (fn engineFunc) query(ctx context.Context, tables []table, _ string, _ []any) ([]string, [][]any, error) {
+	return fn(ctx, tables)
+}
+
+// selectAll returns the columns and the rows of the first table, like SELECT * FROM <table>
+func selectAll(_ context.Context, tables []table) ([]string, [][]any, error) {
+	columns := make([]string, len(tables[0].columns))
+	for i, c := range tables[0].columns {
+		columns[i] = c.Name
+	}
+	rows := [][]any{}
+	err := tables[0].rows.Each(func(row []any) error {
+		rows = append(rows, append([]any{}, row...))
+		return nil
+	})
+	return columns, rows, err
+}
+
+func TestQueryFramesInto(t *testing.T) {
+	value := 2.5
+	frame := data.NewFrame("",
+		data.NewField("name", nil, []string{"a", "b"}),
+		data.NewField("value", nil, []*float64{nil, &value}),
+	)
+	frame.RefID = "A"
+
+	t.Run("returns the result of the engine", func(t *testing.T) {
+		db := NewInMemoryDB()
+		db.engine = engineFunc(selectAll)
+		out := &data.Frame{}
+		require.NoError(t, db.QueryFramesInto(context.Background(), "B", "SELECT * FROM A", []*data.Frame{frame}, out))
+		require.Equal(t, "B", out.Name)
+		require.Equal(t, 2, out.Rows())
+		require.Equal(t, "a", out.Fields[0].At(0))
+		require.Equal(t, &value, out.Fields[1].At(1))
+	})
+
+	t.Run("fails without an engine", func(t *testing.T) {
+		out := &data.Frame{}
+		err := NewInMemoryDB().QueryFramesInto(context.Background(), "B", "SELECT * FROM A", []*data.Frame{frame}, out)
+		require.ErrorIs(t, err, errNotImplemented)
+		require.Equal(t, &data.Frame{}, out)
+	})
+
+	t.Run("does not run the query when the context is done", func(t *testing.T) {
+		db := NewInMemoryDB()
+		db.engine = engineFunc(func(context.Context, []table) ([]string, [][]any, error) {
+			t.Fatal("the engine must not be called")
+			return nil, nil, nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		out := &data.Frame{}
+		require.ErrorIs(t, db.QueryFramesInto(ctx, "B", "SELECT * FROM A", []*data.Frame{frame}, out), context.Canceled)
+		require.Equal(t, &data.Frame{}, out)
+	})
+
+	t.Run("stops the query when the context is done", func(t *testing.T) {
+		db := NewInMemoryDB()
+		db.engine = engineFunc(func(ctx context.Context, tables []table) ([]string, [][]any, error) {
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		out := &data.Frame{}
+		require.ErrorIs(t, db.QueryFramesInto(ctx, "B", "SELECT * FROM A", []*data.Frame{frame}, out), context.DeadlineExceeded)
+		require.Equal(t, &data.Frame{}, out, "the frame is not written after the query returned")
+	})
+}
+
+func TestQueryFramesIntoStorage(t *testing.T) {
+	rowCount := spillChunkRows*2 + 10
+	values := make([]int64, rowCount)
+	for i := range values {
+		values[i] = int64(i)
+	}
+	frame := data.NewFrame("", data.NewField("value", nil, values))
+	frame.RefID = "A"
+	small := data.NewFrame("", data.NewField("value", nil, values[:10]))
+	small.RefID = "B"
+
+	dir := t.TempDir()
+	db := NewDB(StorageOptions{SpillThreshold: 100, SpillDir: dir})
+	db.engine = engineFunc(func(ctx context.Context, tables []table) ([]string, [][]any, error) {
+		require.IsType(t, &spillingRowStore{}, tables[0].rows)
+		require.Equal(t, rowCount, tables[0].rows.Len())
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1, "only the rows of the table over the threshold are written to disk")
+		return selectAll(ctx, tables)
+	})
+
+	out := &data.Frame{}
+	require.NoError(t, db.QueryFramesInto(context.Background(), "C", "SELECT * FROM A", []*data.Frame{frame, small}, out))
+	require.Equal(t, rowCount, out.Rows())
+	for i := range values {
+		require.Equal(t, values[i], out.Fields[0].At(i))
+	}
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files, "the temporary files are removed after the query")
+}
+
+func TestQueryFramesIntoConversionErrors(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("doc", nil, []json.RawMessage{json.RawMessage(`{"a":1}`), json.RawMessage(`{`)}),
+	)
+	frame.RefID = "A"
+	// the engine returns the documents and a column of mixed types, e.g. the values of a JSON_EXTRACT
+	mixed := engineFunc(func(ctx context.Context, tables []table) ([]string, [][]any, error) {
+		columns, rows, err := selectAll(ctx, tables)
+		rows[0] = append(rows[0], int64(1))
+		rows[1] = append(rows[1], "b")
+		return append(columns, "value"), rows, err
+	})
+
+	t.Run("fails the query without conversion errors", func(t *testing.T) {
+		db := NewInMemoryDB()
+		db.engine = mixed
+		out := &data.Frame{}
+		err := db.QueryFramesInto(context.Background(), "B", "SELECT * FROM A", []*data.Frame{frame}, out)
+		require.ErrorContains(t, err, "invalid JSON in field doc at row 1")
+		require.Equal(t, &data.Frame{}, out)
+	})
+
+	t.Run("returns the values that can not be converted as NULL", func(t *testing.T) {
+		errs := NewConversionErrors()
+		db := NewInMemoryDB()
+		db.TolerateConversionErrors(errs)
+		db.engine = mixed
+		out := &data.Frame{}
+		require.NoError(t, db.QueryFramesInto(context.Background(), "B", "SELECT * FROM A", []*data.Frame{frame}, out))
+		require.Equal(t, 2, out.Rows())
+		require.JSONEq(t, `{"a":1}`, string(*out.Fields[0].At(0).(*json.RawMessage)))
+		require.Nil(t, out.Fields[0].At(1))
+		require.Equal(t, int64(1), *out.Fields[1].At(0).(*int64))
+		require.Nil(t, out.Fields[1].At(1))
+		require.Equal(t, 1, errs.Count("A.doc"))
+		require.Equal(t, 1, errs.Count("value"))
+	})
+}
+
+func TestQueryFramesIntoTracing(t *testing.T) {
+	frame := data.NewFrame("", data.NewField("value", nil, []int64{1, 2}))
+	frame.RefID = "A"
+	spanNames := func(spans []sdktrace.ReadOnlySpan) []string {
+		names := make([]string, len(spans))
+		for i, span := range spans {
+			names[i] = span.Name()
+		}
+		return names
+	}
+
+	t.Run("traces every stage", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		db := NewInMemoryDB()
+		db.TraceQueries(tracing.InitializeTracerForTest(tracing.WithSpanProcessor(recorder)))
+		db.engine = engineFunc(selectAll)
+		require.NoError(t, db.QueryFramesInto(context.Background(), "B", "SELECT * FROM A", []*data.Frame{frame}, &data.Frame{}))
+
+		spans := recorder.Ended()
+		require.Equal(t, []string{"SSE.ExecuteSQL.LoadTables", "SSE.ExecuteSQL.Query", "SSE.ExecuteSQL.ConvertResult"}, spanNames(spans))
+		require.Contains(t, spans[0].Attributes(), attribute.Int("tables", 1))
+		require.Contains(t, spans[1].Attributes(), attribute.Int("rows", 2))
+	})
+
+	t.Run("records the error of the failed stage", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		db := NewInMemoryDB()
+		db.TraceQueries(tracing.InitializeTracerForTest(tracing.WithSpanProcessor(recorder)))
+		require.Error(t, db.QueryFramesInto(context.Background(), "B", "SELECT * FROM A", []*data.Frame{frame}, &data.Frame{}))
+
+		spans := recorder.Ended()
+		require.Equal(t, []string{"SSE.ExecuteSQL.LoadTables", "SSE.ExecuteSQL.Query"}, spanNames(spans))
+		require.Equal(t, codes.Unset, spans[0].Status().Code)
+		require.Equal(t, codes.Error, spans[1].Status().Code)
+		require.Equal(t, errNotImplemented.Error(), spans[1].Status().Description)
+	})
+}
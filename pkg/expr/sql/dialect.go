@@ -0,0 +1,521 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnsupportedSyntaxError lists the constructs of a SQL expression that are written for another SQL dialect and
+// have no equivalent in SQL expressions, which use the MySQL dialect.
+type UnsupportedSyntaxError struct {
+	Constructs []string
+}
+
+func (e *UnsupportedSyntaxError) Error() string {
+	return "unsupported syntax in SQL expression: " + strings.Join(e.Constructs, "; ")
+}
+
+func init() {
+	RegisterFunction(Function{Name: "date_trunc", MinArgs: 2, MaxArgs: 2, Expand: expandDateTrunc})
+	RegisterFunction(Function{Name: "date_part", MinArgs: 2, MaxArgs: 2, Expand: expandDatePart})
+	RegisterFunction(Function{Name: "to_timestamp", MinArgs: 1, MaxArgs: 1, Expand: expandToTimestamp})
+	RegisterFunction(Function{Name: "string_agg", MinArgs: 2, MaxArgs: 2, Expand: expandStringAgg})
+}
+
+// castTypes are the MySQL types of the PostgreSQL types of x::type casts. numeric has no precision in PostgreSQL,
+// so it is cast to DOUBLE instead of DECIMAL, which has no decimals by default.
+var castTypes = map[string]string{
+	"int":                         "SIGNED",
+	"int4":                        "SIGNED",
+	"int8":                        "SIGNED",
+	"integer":                     "SIGNED",
+	"bigint":                      "SIGNED",
+	"smallint":                    "SIGNED",
+	"float":                       "DOUBLE",
+	"float4":                      "DOUBLE",
+	"float8":                      "DOUBLE",
+	"real":                        "DOUBLE",
+	"double precision":            "DOUBLE",
+	"numeric":                     "DOUBLE",
+	"decimal":                     "DOUBLE",
+	"text":                        "CHAR",
+	"varchar":                     "CHAR",
+	"character varying":           "CHAR",
+	"date":                        "DATE",
+	"timestamp":                   "DATETIME",
+	"timestamptz":                 "DATETIME",
+	"timestamp with time zone":    "DATETIME",
+	"timestamp without time zone": "DATETIME",
+}
+
+// intervalSeconds are the seconds of the units of PostgreSQL intervals that have a fixed length
+var intervalSeconds = map[string]int64{
+	"s": 1, "sec": 1, "secs": 1, "second": 1, "seconds": 1,
+	"m": 60, "min": 60, "mins": 60, "minute": 60, "minutes": 60,
+	"h": 3600, "hour": 3600, "hours": 3600,
+	"d": 86400, "day": 86400, "days": 86400,
+	"w": 604800, "week": 604800, "weeks": 604800,
+}
+
+// intervalUnits are the MySQL units of the units of PostgreSQL intervals
+var intervalUnits = map[string]string{
+	"s": "SECOND", "sec": "SECOND", "secs": "SECOND", "second": "SECOND", "seconds": "SECOND",
+	"m": "MINUTE", "min": "MINUTE", "mins": "MINUTE", "minute": "MINUTE", "minutes": "MINUTE",
+	"h": "HOUR", "hour": "HOUR", "hours": "HOUR",
+	"d": "DAY", "day": "DAY", "days": "DAY",
+	"w": "WEEK", "week": "WEEK", "weeks": "WEEK",
+	"mon": "MONTH", "mons": "MONTH", "month": "MONTH", "months": "MONTH",
+	"y": "YEAR", "year": "YEAR", "years": "YEAR",
+}
+
+// mysqlIntervalUnits are the units following the quantity of a MySQL interval, e.g. INTERVAL '1:30' HOUR_MINUTE
+var mysqlIntervalUnits = map[string]bool{
+	"MICROSECOND": true, "SECOND": true, "MINUTE": true, "HOUR": true, "DAY": true, "WEEK": true, "MONTH": true,
+	"QUARTER": true, "YEAR": true, "SECOND_MICROSECOND": true, "MINUTE_MICROSECOND": true, "MINUTE_SECOND": true,
+	"HOUR_MICROSECOND": true, "HOUR_SECOND": true, "HOUR_MINUTE": true, "DAY_MICROSECOND": true, "DAY_SECOND": true,
+	"DAY_MINUTE": true, "DAY_HOUR": true, "YEAR_MONTH": true,
+}
+
+// NormalizeDialect rewrites the most common PostgreSQL constructs of a SQL expression into the MySQL dialect of
+// SQL expressions, so queries written for a PostgreSQL data source can be pasted:
+//   - x::type casts become CAST(x AS type)
+//   - a ILIKE b becomes LOWER(a) LIKE LOWER(b)
+//   - INTERVAL '1 hour' becomes INTERVAL 1 HOUR
+//   - EXTRACT(EPOCH FROM ts), and the dow and doy fields, become UNIX_TIMESTAMP(ts), DAYOFWEEK and DAYOFYEAR
+//
+// The functions date_trunc, date_part, to_timestamp and string_agg are registered functions, see ExpandFunctions.
+// The constructs that can not be rewritten, such as DISTINCT ON or the || operator, are all returned in an
+// UnsupportedSyntaxError. Quoted strings, quoted identifiers and comments are left as they are.
+func NormalizeDialect(rawSQL string) (string, error) {
+	tokens, err := lexDialect(rawSQL)
+	if err != nil {
+		return "", err
+	}
+	n := dialectNormalizer{}
+	out, err := n.normalize(tokens)
+	if err != nil {
+		return "", err
+	}
+	if len(n.unsupported) > 0 {
+		return "", &UnsupportedSyntaxError{Constructs: n.unsupported}
+	}
+	return joinTokens(out), nil
+}
+
+type dialectTokenKind int
+
+const (
+	tokenBlank   dialectTokenKind = iota // spaces and comments
+	tokenWord                            // identifiers, keywords, numbers and variables
+	tokenString                          // 'quoted strings'
+	tokenQuoted                          // "quoted" and `quoted` identifiers
+	tokenPunct                           // operators and parentheses
+	tokenOperand                         // an operand rewritten by the normalizer
+)
+
+type dialectToken struct {
+	kind dialectTokenKind
+	text string
+}
+
+func (t dialectToken) is(kind dialectTokenKind, text string) bool {
+	return t.kind == kind && strings.EqualFold(t.text, text)
+}
+
+func lexDialect(s string) ([]dialectToken, error) {
+	tokens := []dialectToken{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		end := i + 1
+		kind := tokenPunct
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end = closingQuote(s, i) + 1
+			if end == 0 {
+				return nil, errors.New("unterminated quoted string in SQL expression")
+			}
+			kind = tokenQuoted
+			if c == '\'' {
+				kind = tokenString
+			}
+		case c == '#', isDashComment(s, i), strings.HasPrefix(s[i:], "/*"):
+			end, kind = commentEnd(s, i), tokenBlank
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for end < len(s) && strings.IndexByte(" \t\n\r", s[end]) >= 0 {
+				end++
+			}
+			kind = tokenBlank
+		case c == '$' && end < len(s) && s[end] == '{':
+			if close := strings.IndexByte(s[end:], '}'); close >= 0 {
+				end += close + 1
+			}
+			kind = tokenWord
+		case isIdentChar(c):
+			for end < len(s) && isIdentChar(s[end]) {
+				end++
+			}
+			kind = tokenWord
+		case strings.HasPrefix(s[i:], "::"), strings.HasPrefix(s[i:], "||"):
+			end++
+		}
+		tokens = append(tokens, dialectToken{kind: kind, text: s[i:end]})
+		i = end
+	}
+	return tokens, nil
+}
+
+func joinTokens(tokens []dialectToken) string {
+	b := strings.Builder{}
+	for _, t := range tokens {
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+type dialectNormalizer struct {
+	unsupported []string
+}
+
+func (n *dialectNormalizer) reject(construct string) {
+	for _, c := range n.unsupported {
+		if c == construct {
+			return
+		}
+	}
+	n.unsupported = append(n.unsupported, construct)
+}
+
+func (n *dialectNormalizer) normalize(tokens []dialectToken) ([]dialectToken, error) {
+	out := []dialectToken{}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.is(tokenPunct, "::"):
+			left, rest, ok := popOperand(out)
+			if !ok {
+				return nil, errors.New("missing the value to cast before ::")
+			}
+			typ, next := castType(tokens, i+1)
+			if typ == "" {
+				return nil, errors.New("missing the type to cast to after ::")
+			}
+			mysqlType, ok := castTypes[strings.ToLower(typ)]
+			if !ok {
+				n.reject(fmt.Sprintf("cast to %s, use CAST(x AS type) with SIGNED, DOUBLE, DECIMAL, CHAR, DATE or DATETIME", typ))
+			}
+			out = append(rest, dialectToken{kind: tokenOperand, text: fmt.Sprintf("CAST(%s AS %s)", joinTokens(left), mysqlType)})
+			i = next - 1
+		case t.is(tokenWord, "ILIKE"):
+			not := false
+			left, rest, ok := popOperand(out)
+			if ok && len(left) == 1 && left[0].is(tokenWord, "NOT") {
+				not = true
+				left, rest, ok = popOperand(rest)
+			}
+			right, next := readOperand(tokens, i+1)
+			if !ok || right == nil {
+				return nil, errors.New("ILIKE needs a value on both sides")
+			}
+			right, err := n.normalize(right)
+			if err != nil {
+				return nil, err
+			}
+			like := " LIKE "
+			if not {
+				like = " NOT LIKE "
+			}
+			out = append(rest, dialectToken{kind: tokenOperand, text: "LOWER(" + joinTokens(left) + ")" + like + "LOWER(" + joinTokens(right) + ")"})
+			i = next - 1
+		case t.is(tokenWord, "INTERVAL"):
+			j := skipBlanks(tokens, i+1)
+			if j == len(tokens) || tokens[j].kind != tokenString {
+				out = append(out, t)
+				continue
+			}
+			if k := skipBlanks(tokens, j+1); k < len(tokens) && tokens[k].kind == tokenWord && mysqlIntervalUnits[strings.ToUpper(tokens[k].text)] {
+				// INTERVAL '1' HOUR is MySQL already
+				out = append(out, t)
+				continue
+			}
+			interval, ok := mysqlInterval(tokens[j].text)
+			if !ok {
+				n.reject(fmt.Sprintf("INTERVAL %s, use an interval like INTERVAL 1 HOUR", tokens[j].text))
+			}
+			out = append(out, dialectToken{kind: tokenOperand, text: interval})
+			i = j
+		case t.is(tokenWord, "EXTRACT"):
+			open := skipBlanks(tokens, i+1)
+			closing := matchingParen(tokens, open)
+			field := skipBlanks(tokens, open+1)
+			from := skipBlanks(tokens, field+1)
+			if closing < 0 || from >= closing || !tokens[from].is(tokenWord, "FROM") {
+				out = append(out, t)
+				continue
+			}
+			arg, err := n.normalize(tokens[from+1 : closing])
+			if err != nil {
+				return nil, err
+			}
+			part, ok := datePart(tokens[field].text, strings.TrimSpace(joinTokens(arg)))
+			if !ok {
+				out = append(out, t)
+				continue
+			}
+			out = append(out, dialectToken{kind: tokenOperand, text: part})
+			i = closing
+		case t.is(tokenPunct, "||"):
+			n.reject("the || operator, use CONCAT(a, b) to join strings or OR")
+			out = append(out, t)
+		case t.is(tokenWord, "DISTINCT") && nextIs(tokens, i+1, tokenWord, "ON"):
+			n.reject("DISTINCT ON, use ROW_NUMBER() OVER (PARTITION BY ...) in a subquery")
+			out = append(out, t)
+		case t.is(tokenWord, "FILTER") && nextIs(tokens, i+1, tokenPunct, "(") && nextIs(tokens, skipBlanks(tokens, i+1)+1, tokenWord, "WHERE"):
+			n.reject("aggregate FILTER (WHERE ...), use CASE WHEN ... THEN x END in the aggregate")
+			out = append(out, t)
+		default:
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func skipBlanks(tokens []dialectToken, i int) int {
+	for i < len(tokens) && tokens[i].kind == tokenBlank {
+		i++
+	}
+	return i
+}
+
+func nextIs(tokens []dialectToken, i int, kind dialectTokenKind, text string) bool {
+	i = skipBlanks(tokens, i)
+	return i < len(tokens) && tokens[i].is(kind, text)
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open, or -1
+func matchingParen(tokens []dialectToken, open int) int {
+	if open >= len(tokens) || !tokens[open].is(tokenPunct, "(") {
+		return -1
+	}
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].is(tokenPunct, "("):
+			depth++
+		case tokens[i].is(tokenPunct, ")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// popOperand splits the operand at the end of tokens, a value, a column, a call or a parenthesized expression,
+// from the tokens before it. The blanks between them are dropped.
+func popOperand(tokens []dialectToken) ([]dialectToken, []dialectToken, bool) {
+	end := len(tokens)
+	for end > 0 && tokens[end-1].kind == tokenBlank {
+		end--
+	}
+	if end == 0 {
+		return nil, tokens, false
+	}
+	start := end - 1
+	switch last := tokens[start]; {
+	case last.is(tokenPunct, ")"):
+		depth := 0
+		for ; start >= 0; start-- {
+			if tokens[start].is(tokenPunct, ")") {
+				depth++
+			} else if tokens[start].is(tokenPunct, "(") {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if start < 0 {
+			return nil, tokens, false
+		}
+		if start > 0 && tokens[start-1].kind == tokenWord {
+			start--
+		}
+	case last.kind == tokenPunct || last.kind == tokenBlank:
+		return nil, tokens, false
+	}
+	// qualified columns, e.g. A.value
+	for start >= 2 && tokens[start-1].is(tokenPunct, ".") && (tokens[start-2].kind == tokenWord || tokens[start-2].kind == tokenQuoted) {
+		start -= 2
+	}
+	return tokens[start:end], tokens[:start], true
+}
+
+// readOperand returns the operand starting after the blanks at i and the index after it
+func readOperand(tokens []dialectToken, i int) ([]dialectToken, int) {
+	start := skipBlanks(tokens, i)
+	if start == len(tokens) {
+		return nil, start
+	}
+	end := start + 1
+	switch t := tokens[start]; {
+	case t.is(tokenPunct, "("):
+		end = matchingParen(tokens, start) + 1
+		if end == 0 {
+			return nil, start
+		}
+	case t.kind == tokenPunct:
+		return nil, start
+	default:
+		for end+1 < len(tokens) && tokens[end].is(tokenPunct, ".") && (tokens[end+1].kind == tokenWord || tokens[end+1].kind == tokenQuoted) {
+			end += 2
+		}
+		if t.kind == tokenWord && end < len(tokens) && tokens[end].is(tokenPunct, "(") {
+			if closing := matchingParen(tokens, end); closing > 0 {
+				end = closing + 1
+			}
+		}
+	}
+	return tokens[start:end], end
+}
+
+// castType returns the type of a cast starting after the blanks at i, e.g. double precision or varchar(20), and the
+// index after it. The length and precision of the type are dropped.
+func castType(tokens []dialectToken, i int) (string, int) {
+	start := skipBlanks(tokens, i)
+	if start == len(tokens) || tokens[start].kind != tokenWord {
+		return "", start
+	}
+	words := []string{tokens[start].text}
+	end := start + 1
+	for {
+		next := skipBlanks(tokens, end)
+		if next == len(tokens) || tokens[next].kind != tokenWord {
+			break
+		}
+		candidate := strings.ToLower(strings.Join(append(words, tokens[next].text), " "))
+		if !isCastTypePrefix(candidate) {
+			break
+		}
+		words = append(words, tokens[next].text)
+		end = next + 1
+	}
+	if end < len(tokens) && tokens[end].is(tokenPunct, "(") {
+		if closing := matchingParen(tokens, end); closing > 0 {
+			end = closing + 1
+		}
+	}
+	return strings.Join(words, " "), end
+}
+
+func isCastTypePrefix(words string) bool {
+	for typ := range castTypes {
+		if typ == words || strings.HasPrefix(typ, words+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// mysqlInterval converts a PostgreSQL interval string, e.g. '1 hour' or '1h 30m', to a MySQL interval.
+// Intervals of several units are converted to seconds, which is only possible for units of a fixed length.
+func mysqlInterval(quoted string) (string, bool) {
+	fields := strings.Fields(strings.ToLower(quoted[1 : len(quoted)-1]))
+	// split the units written next to their quantity, e.g. 5m
+	parts := []string{}
+	for _, f := range fields {
+		digits := strings.IndexFunc(f, func(r rune) bool { return (r < '0' || r > '9') && r != '-' })
+		if digits > 0 {
+			parts = append(parts, f[:digits], f[digits:])
+		} else {
+			parts = append(parts, f)
+		}
+	}
+	if len(parts) == 0 || len(parts)%2 != 0 {
+		return "", false
+	}
+	if len(parts) == 2 {
+		n, err := strconv.ParseInt(parts[0], 10, 64)
+		unit := intervalUnits[parts[1]]
+		if err != nil || unit == "" {
+			return "", false
+		}
+		return fmt.Sprintf("INTERVAL %d %s", n, unit), true
+	}
+	seconds := int64(0)
+	for i := 0; i < len(parts); i += 2 {
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		unit, ok := intervalSeconds[parts[i+1]]
+		if err != nil || !ok {
+			return "", false
+		}
+		seconds += n * unit
+	}
+	return fmt.Sprintf("INTERVAL %d SECOND", seconds), true
+}
+
+// datePart returns the MySQL expression of a field of a date, as read by EXTRACT and date_part in PostgreSQL
+func datePart(field string, ts string) (string, bool) {
+	switch strings.ToLower(strings.Trim(field, "'")) {
+	case "epoch":
+		return fmt.Sprintf("UNIX_TIMESTAMP(%s)", ts), true
+	case "dow":
+		return fmt.Sprintf("(DAYOFWEEK(%s) - 1)", ts), true
+	case "doy":
+		return fmt.Sprintf("DAYOFYEAR(%s)", ts), true
+	case "second", "minute", "hour", "day", "week", "month", "quarter", "year":
+		return fmt.Sprintf("EXTRACT(%s FROM %s)", strings.ToUpper(strings.Trim(field, "'")), ts), true
+	}
+	return "", false
+}
+
+// expandDateTrunc expands date_trunc('unit', ts) to the start of the second, minute, hour, day, week, month or
+// year of ts. Weeks start on Monday.
+func expandDateTrunc(args []string) (string, error) {
+	ts := args[1]
+	switch unit := strings.ToLower(strings.Trim(args[0], "'")); unit {
+	case "second", "minute", "hour", "day":
+		seconds := intervalSeconds[unit]
+		return fmt.Sprintf("FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(%s) / %d) * %d)", ts, seconds, seconds), nil
+	case "week":
+		return fmt.Sprintf("CAST(DATE_SUB(DATE(%s), INTERVAL WEEKDAY(%s) DAY) AS DATETIME)", ts, ts), nil
+	case "month":
+		return fmt.Sprintf("CAST(DATE_FORMAT(%s, '%%Y-%%m-01') AS DATETIME)", ts), nil
+	case "year":
+		return fmt.Sprintf("CAST(DATE_FORMAT(%s, '%%Y-01-01') AS DATETIME)", ts), nil
+	}
+	return "", fmt.Errorf("function date_trunc needs a unit of second, minute, hour, day, week, month or year, got %s", args[0])
+}
+
+// expandDatePart expands date_part('field', ts) like EXTRACT(field FROM ts)
+func expandDatePart(args []string) (string, error) {
+	part, ok := datePart(args[0], args[1])
+	if !ok {
+		return "", fmt.Errorf("function date_part needs a field of epoch, dow, doy, second, minute, hour, day, week, month, quarter or year, got %s", args[0])
+	}
+	return part, nil
+}
+
+// expandToTimestamp expands to_timestamp(seconds) to the time of a Unix timestamp
+func expandToTimestamp(args []string) (string, error) {
+	return fmt.Sprintf("FROM_UNIXTIME(%s)", args[0]), nil
+}
+
+// expandStringAgg expands string_agg(x, separator [ORDER BY ...]) to the values of x in the group joined with separator
+func expandStringAgg(args []string) (string, error) {
+	separator, order := args[1], ""
+	if strings.HasPrefix(separator, "'") {
+		if end := closingQuote(separator, 0); end > 0 {
+			separator, order = separator[:end+1], strings.TrimSpace(separator[end+1:])
+		}
+	}
+	if order != "" && !strings.HasPrefix(strings.ToUpper(order), "ORDER BY ") {
+		return "", fmt.Errorf("function string_agg needs a quoted separator, optionally followed by ORDER BY, got %s", args[1])
+	}
+	if order != "" {
+		order = " " + order
+	}
+	return fmt.Sprintf("GROUP_CONCAT(%s%s SEPARATOR %s)", args[0], order, separator), nil
+}
@@ -0,0 +1,115 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+		err      string
+	}{
+		{
+			name:     "interval",
+			sql:      "SELECT * FROM A WHERE time > NOW() - INTERVAL '1 hour'",
+			expected: "SELECT * FROM A WHERE time > NOW() - INTERVAL 1 HOUR",
+		},
+		{
+			name:     "interval of several units",
+			sql:      "SELECT NOW() - interval '1h 30m', NOW() - INTERVAL '1 day 2 hours'",
+			expected: "SELECT NOW() - INTERVAL 5400 SECOND, NOW() - INTERVAL 93600 SECOND",
+		},
+		{
+			name:     "MySQL intervals are kept",
+			sql:      "SELECT NOW() - INTERVAL 1 HOUR, NOW() - INTERVAL '1:30' HOUR_MINUTE",
+			expected: "SELECT NOW() - INTERVAL 1 HOUR, NOW() - INTERVAL '1:30' HOUR_MINUTE",
+		},
+		{
+			name:     "casts",
+			sql:      "SELECT value::int, A.value :: double precision, (a + b)::numeric(10, 2), avg(value)::text, '2024-01-01'::timestamptz::date FROM A",
+			expected: "SELECT CAST(value AS SIGNED), CAST(A.value AS DOUBLE), CAST((a + b) AS DOUBLE), CAST(avg(value) AS CHAR), CAST(CAST('2024-01-01' AS DATETIME) AS DATE) FROM A",
+		},
+		{
+			name:     "ilike",
+			sql:      "SELECT * FROM A WHERE A.host ILIKE 'web%' AND name NOT ILIKE $name",
+			expected: "SELECT * FROM A WHERE LOWER(A.host) LIKE LOWER('web%') AND LOWER(name) NOT LIKE LOWER($name)",
+		},
+		{
+			name:     "extract",
+			sql:      "SELECT EXTRACT(EPOCH FROM time), extract(dow from time::timestamp), EXTRACT(HOUR FROM time) FROM A",
+			expected: "SELECT UNIX_TIMESTAMP(time), (DAYOFWEEK(CAST(time AS DATETIME)) - 1), EXTRACT(HOUR FROM time) FROM A",
+		},
+		{
+			name:     "strings, identifiers and comments are kept",
+			sql:      "SELECT 'a::int ILIKE', `b::text` -- x::int\nFROM A",
+			expected: "SELECT 'a::int ILIKE', `b::text` -- x::int\nFROM A",
+		},
+		{
+			name: "unsupported syntax is listed",
+			sql:  "SELECT DISTINCT ON (host) host || '-' || name, count(*) FILTER (WHERE value > 1), value::jsonb FROM A WHERE time > NOW() - INTERVAL '1 month 1 day'",
+			err:  "unsupported syntax in SQL expression: DISTINCT ON, use ROW_NUMBER() OVER (PARTITION BY ...) in a subquery; the || operator, use CONCAT(a, b) to join strings or OR; aggregate FILTER (WHERE ...), use CASE WHEN ... THEN x END in the aggregate; cast to jsonb",
+		},
+		{
+			name: "cast without value",
+			sql:  "SELECT a, ::int FROM A",
+			err:  "missing the value to cast before ::",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := NormalizeDialect(tt.sql)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql)
+		})
+	}
+}
+
+func TestDialectFunctions(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+		err      string
+	}{
+		{
+			sql:      "SELECT date_trunc('hour', time) FROM A",
+			expected: "SELECT FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(time) / 3600) * 3600) FROM A",
+		},
+		{
+			sql:      "SELECT DATE_TRUNC('month', time), date_trunc('week', time) FROM A",
+			expected: "SELECT CAST(DATE_FORMAT(time, '%Y-%m-01') AS DATETIME), CAST(DATE_SUB(DATE(time), INTERVAL WEEKDAY(time) DAY) AS DATETIME) FROM A",
+		},
+		{
+			sql:      "SELECT date_part('epoch', time), date_part('hour', time), to_timestamp(ts / 1000) FROM A",
+			expected: "SELECT UNIX_TIMESTAMP(time), EXTRACT(HOUR FROM time), FROM_UNIXTIME(ts / 1000) FROM A",
+		},
+		{
+			sql:      "SELECT string_agg(host, ', ' ORDER BY host), string_agg(name, ',') FROM A",
+			expected: "SELECT GROUP_CONCAT(host ORDER BY host SEPARATOR ', '), GROUP_CONCAT(name SEPARATOR ',') FROM A",
+		},
+		{
+			sql: "SELECT date_trunc('decade', time) FROM A",
+			err: "function date_trunc needs a unit of second, minute, hour, day, week, month or year, got 'decade'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			sql, err := ExpandFunctions(tt.sql)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql)
+		})
+	}
+}
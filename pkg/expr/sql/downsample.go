@@ -0,0 +1,291 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The methods of downsampling
+const (
+	// DownsampleLTTB keeps the rows that preserve the shape of the series, with the largest triangle three buckets
+	// algorithm
+	DownsampleLTTB = "lttb"
+	// DownsampleMean replaces the rows of each time bucket with the means of their values
+	DownsampleMean = "mean"
+)
+
+// minLTTBRows are the rows LTTB keeps at least: the first, the last and one in between
+const minLTTBRows = 3
+
+// Downsampling reduces the rows of the large time series inputs of an expression before they are loaded, so
+// exploratory queries over raw data do not hold millions of rows in memory.
+type Downsampling struct {
+	// Method is DownsampleLTTB or DownsampleMean
+	Method string
+	// MaxRows is the number of rows a frame is reduced to when it has more
+	MaxRows int
+}
+
+// Validate checks the method and the number of rows
+func (d Downsampling) Validate() error {
+	if d.Method != DownsampleLTTB && d.Method != DownsampleMean {
+		return fmt.Errorf("unknown downsampling method %q, expected %s or %s", d.Method, DownsampleLTTB, DownsampleMean)
+	}
+	if d.MaxRows < minLTTBRows {
+		return fmt.Errorf("expected the maximum rows of downsampling to be at least %d, got %d", minLTTBRows, d.MaxRows)
+	}
+	return nil
+}
+
+// Downsample reduces the frames with more than MaxRows rows, a single time field and numeric fields to about
+// MaxRows rows, and returns an info notice per reduced frame. The rows of a long frame are grouped in series by the
+// values of their other fields, e.g. the labels, and every series is reduced in proportion to its rows. The rows
+// without a time are dropped. The other frames are returned unchanged, in the order of the frames.
+//
+// With DownsampleLTTB the selected rows are kept as they are, they are the rows whose values make the largest
+// triangles with the rows selected around them, summed over the numeric fields. With DownsampleMean the time range
+// of every series is split in MaxRows buckets, each bucket is a row at its start time with the means of the numeric
+// fields, as float64.
+func Downsample(frames []*data.Frame, d Downsampling) ([]*data.Frame, []data.Notice) {
+	out := make([]*data.Frame, 0, len(frames))
+	var notices []data.Notice
+	for _, f := range frames {
+		reduced := downsampleFrame(f, d)
+		out = append(out, reduced)
+		if reduced == f {
+			continue
+		}
+		name := f.RefID
+		if f.Name != "" && f.Name != f.RefID {
+			name = fmt.Sprintf("%s (%s)", f.RefID, f.Name)
+		}
+		notices = append(notices, data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("the input %s was downsampled from %d to %d rows with %s", name, f.Rows(), reduced.Rows(), d.Method),
+		})
+	}
+	return out, notices
+}
+
+// downsampleFrame returns the reduced frame, or f when it is not reduced
+func downsampleFrame(f *data.Frame, d Downsampling) *data.Frame {
+	if f == nil || f.Rows() <= d.MaxRows {
+		return f
+	}
+	timeIndices := f.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(timeIndices) != 1 {
+		return f
+	}
+	timeField := f.Fields[timeIndices[0]]
+	var numeric, other []*data.Field
+	for _, field := range f.Fields {
+		switch {
+		case field == timeField:
+		case field.Type().Numeric():
+			numeric = append(numeric, field)
+		default:
+			other = append(other, field)
+		}
+	}
+	if len(numeric) == 0 {
+		return f
+	}
+
+	times, series, total := timeSeriesRows(timeField, other)
+	if total == 0 {
+		return f
+	}
+	s := &downsampler{timeField: timeField, times: times, numeric: numeric}
+	reduced := data.NewFrame(f.Name)
+	reduced.RefID, reduced.Meta = f.RefID, f.Meta
+	for _, field := range f.Fields {
+		typ := field.Type()
+		if d.Method == DownsampleMean && field.Type().Numeric() {
+			typ = data.FieldTypeNullableFloat64
+		}
+		copied := data.NewFieldFromFieldType(typ, 0)
+		copied.Name, copied.Labels, copied.Config = field.Name, field.Labels, field.Config
+		reduced.Fields = append(reduced.Fields, copied)
+	}
+	for _, rows := range series {
+		// every series keeps its share of the rows
+		budget := max(1, d.MaxRows*len(rows)/total)
+		if d.Method == DownsampleMean {
+			s.appendMeans(reduced, f, rows, budget)
+		} else {
+			s.appendRows(reduced, f, s.lttb(rows, max(minLTTBRows, budget)))
+		}
+	}
+	return reduced
+}
+
+// timeSeriesRows groups the rows with a time by the values of the other fields, in the order the series are first
+// found, and sorts the rows of every series by time. It returns the times of the rows, the series and the number of
+// rows with a time.
+func timeSeriesRows(timeField *data.Field, other []*data.Field) ([]time.Time, [][]int, int) {
+	var series [][]int
+	index := map[string]int{}
+	times := make([]time.Time, timeField.Len())
+	total := 0
+	for i := 0; i < timeField.Len(); i++ {
+		t, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		times[i] = t.(time.Time)
+		total++
+		key := ""
+		for _, field := range other {
+			v, ok := field.ConcreteAt(i)
+			key += fmt.Sprintf("%t %v\x00", ok, v)
+		}
+		idx, ok := index[key]
+		if !ok {
+			idx = len(series)
+			index[key] = idx
+			series = append(series, nil)
+		}
+		series[idx] = append(series[idx], i)
+	}
+	for _, rows := range series {
+		sort.SliceStable(rows, func(a, b int) bool { return times[rows[a]].Before(times[rows[b]]) })
+	}
+	return times, series, total
+}
+
+type downsampler struct {
+	timeField *data.Field
+	// times are the times of the rows
+	times   []time.Time
+	numeric []*data.Field
+}
+
+func (s *downsampler) time(row int) time.Time {
+	return s.times[row]
+}
+
+// value returns the value of a numeric field as a float64, false when it is NULL or not a number
+func (s *downsampler) value(field *data.Field, row int) (float64, bool) {
+	v, err := field.NullableFloatAt(row)
+	if err != nil || v == nil || math.IsNaN(*v) {
+		return 0, false
+	}
+	return *v, true
+}
+
+// lttb selects at most threshold of the rows sorted by time, the first and the last included
+func (s *downsampler) lttb(rows []int, threshold int) []int {
+	if len(rows) <= threshold {
+		return rows
+	}
+	start := s.time(rows[0])
+	x := func(row int) float64 { return float64(s.time(row).Sub(start)) }
+
+	selected := make([]int, 0, threshold)
+	selected = append(selected, rows[0])
+	a := rows[0]
+	// the rows between the first and the last are split in threshold-2 buckets
+	every := float64(len(rows)-2) / float64(threshold-2)
+	avgY := make([]float64, len(s.numeric))
+	avgOK := make([]bool, len(s.numeric))
+	for i := 0; i < threshold-2; i++ {
+		// the average of the next bucket, the last row after the last bucket
+		nextStart := int(float64(i+1)*every) + 1
+		nextEnd := min(int(float64(i+2)*every)+1, len(rows))
+		avgX := 0.0
+		for _, row := range rows[nextStart:nextEnd] {
+			avgX += x(row)
+		}
+		avgX /= float64(nextEnd - nextStart)
+		for j, field := range s.numeric {
+			sum, n := 0.0, 0
+			for _, row := range rows[nextStart:nextEnd] {
+				if v, ok := s.value(field, row); ok {
+					sum += v
+					n++
+				}
+			}
+			avgOK[j] = n > 0
+			if n > 0 {
+				avgY[j] = sum / float64(n)
+			}
+		}
+
+		bucketStart := int(float64(i)*every) + 1
+		bucketEnd := int(float64(i+1)*every) + 1
+		maxArea, next := -1.0, rows[bucketStart]
+		for _, row := range rows[bucketStart:bucketEnd] {
+			area := 0.0
+			for j, field := range s.numeric {
+				ya, okA := s.value(field, a)
+				y, ok := s.value(field, row)
+				if !okA || !ok || !avgOK[j] {
+					continue
+				}
+				area += math.Abs((x(a)-avgX)*(y-ya)-(x(a)-x(row))*(avgY[j]-ya)) / 2
+			}
+			if area > maxArea {
+				maxArea, next = area, row
+			}
+		}
+		selected = append(selected, next)
+		a = next
+	}
+	return append(selected, rows[len(rows)-1])
+}
+
+// appendRows appends the rows of f to the reduced frame
+func (s *downsampler) appendRows(reduced *data.Frame, f *data.Frame, rows []int) {
+	for i, field := range f.Fields {
+		for _, row := range rows {
+			reduced.Fields[i].Append(field.CopyAt(row))
+		}
+	}
+}
+
+// appendMeans splits the time range of the rows sorted by time in buckets, and appends a row per bucket with rows at
+// the start of the bucket. The numeric fields are the means of their values, NULL when they have none, and the other
+// fields are the values of the series.
+func (s *downsampler) appendMeans(reduced *data.Frame, f *data.Frame, rows []int, buckets int) {
+	start, end := s.time(rows[0]), s.time(rows[len(rows)-1])
+	width := end.Sub(start)/time.Duration(buckets) + 1
+	for first := 0; first < len(rows); {
+		bucket := s.time(rows[first]).Sub(start) / width
+		last := first + 1
+		for last < len(rows) && s.time(rows[last]).Sub(start)/width == bucket {
+			last++
+		}
+		for i, field := range f.Fields {
+			switch {
+			case field == s.timeField:
+				t := start.Add(bucket * width)
+				if field.Nullable() {
+					reduced.Fields[i].Append(&t)
+				} else {
+					reduced.Fields[i].Append(t)
+				}
+			case field.Type().Numeric():
+				sum, n := 0.0, 0
+				for _, row := range rows[first:last] {
+					if v, ok := s.value(field, row); ok {
+						sum += v
+						n++
+					}
+				}
+				var mean *float64
+				if n > 0 {
+					m := sum / float64(n)
+					mean = &m
+				}
+				reduced.Fields[i].Append(mean)
+			default:
+				reduced.Fields[i].Append(field.CopyAt(rows[first]))
+			}
+		}
+		first = last
+	}
+}
@@ -0,0 +1,119 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsample(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	times := make([]time.Time, 100)
+	values := make([]float64, 100)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * time.Second)
+	}
+	// a flat series with a single spike
+	values[42] = 100
+	wide := data.NewFrame("cpu",
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+	)
+	wide.RefID = "A"
+	small := data.NewFrame("",
+		data.NewField("time", nil, times[:5]),
+		data.NewField("value", nil, values[:5]),
+	)
+	small.RefID = "B"
+	table := data.NewFrame("",
+		data.NewField("name", nil, make([]string, 100)),
+	)
+	table.RefID = "C"
+
+	t.Run("lttb keeps the first, the last and the spike", func(t *testing.T) {
+		frames, notices := Downsample([]*data.Frame{wide, small, table}, Downsampling{Method: DownsampleLTTB, MaxRows: 10})
+		require.Len(t, frames, 3)
+		require.Same(t, small, frames[1])
+		require.Same(t, table, frames[2])
+
+		reduced := frames[0]
+		require.Equal(t, "A", reduced.RefID)
+		require.Equal(t, 10, reduced.Rows())
+		require.Equal(t, times[0], reduced.Fields[0].At(0))
+		require.Equal(t, times[99], reduced.Fields[0].At(9))
+		spike := false
+		for i := 0; i < reduced.Rows(); i++ {
+			if reduced.Fields[1].At(i).(float64) == 100 {
+				spike = true
+				require.Equal(t, times[42], reduced.Fields[0].At(i))
+			}
+		}
+		require.True(t, spike)
+
+		require.Equal(t, []data.Notice{{
+			Severity: data.NoticeSeverityInfo,
+			Text:     "the input A (cpu) was downsampled from 100 to 10 rows with lttb",
+		}}, notices)
+	})
+
+	t.Run("mean averages the buckets", func(t *testing.T) {
+		frames, notices := Downsample([]*data.Frame{wide}, Downsampling{Method: DownsampleMean, MaxRows: 10})
+		require.Len(t, notices, 1)
+		reduced := frames[0]
+		require.Equal(t, 10, reduced.Rows())
+		require.Equal(t, data.FieldTypeNullableFloat64, reduced.Fields[1].Type())
+		for i := 0; i < reduced.Rows(); i++ {
+			mean := 0.0
+			if i == 4 {
+				mean = 10
+			}
+			require.Equal(t, &mean, reduced.Fields[1].At(i))
+		}
+		require.Equal(t, times[0], reduced.Fields[0].At(0))
+	})
+
+	t.Run("the series of a long frame are reduced separately", func(t *testing.T) {
+		hosts := make([]string, 0, 200)
+		longTimes := make([]time.Time, 0, 200)
+		longValues := make([]*float64, 0, 200)
+		for i := 0; i < 100; i++ {
+			for _, host := range []string{"a", "b"} {
+				v := float64(i)
+				if host == "b" && i%2 == 0 {
+					longValues = append(longValues, nil)
+				} else {
+					longValues = append(longValues, &v)
+				}
+				hosts = append(hosts, host)
+				longTimes = append(longTimes, times[i])
+			}
+		}
+		long := data.NewFrame("",
+			data.NewField("time", nil, longTimes),
+			data.NewField("value", nil, longValues),
+			data.NewField("host", nil, hosts),
+		)
+		long.RefID = "A"
+
+		frames, _ := Downsample([]*data.Frame{long}, Downsampling{Method: DownsampleMean, MaxRows: 20})
+		reduced := frames[0]
+		require.Equal(t, 20, reduced.Rows())
+		counts := map[string]int{}
+		for i := 0; i < reduced.Rows(); i++ {
+			counts[reduced.Fields[2].At(i).(string)]++
+		}
+		require.Equal(t, map[string]int{"a": 10, "b": 10}, counts)
+		// the NULL values are not counted in the means
+		first, second := 4.5, 5.0
+		require.Equal(t, &first, reduced.Fields[1].At(0))
+		require.Equal(t, &second, reduced.Fields[1].At(10))
+	})
+}
+
+func TestDownsamplingValidate(t *testing.T) {
+	require.NoError(t, Downsampling{Method: DownsampleLTTB, MaxRows: 3}.Validate())
+	require.ErrorContains(t, Downsampling{Method: "max", MaxRows: 10}.Validate(), "unknown downsampling method")
+	require.ErrorContains(t, Downsampling{Method: DownsampleMean, MaxRows: 0}.Validate(), "at least 3")
+}
@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// PlanFrameName is the name of the frame holding the query plan of a SQL expression
+const PlanFrameName = "query plan"
+
+// ExplainStatement returns the statement reading the query plan of query. The engine returns the plan as
+// a tree with one row per node, for example the join strategy and the tables scanned.
+func ExplainStatement(query string) string {
+	return "EXPLAIN " + query
+}
+
+// NewPlanFrame returns the query plan of the SQL expression refID read with ExplainStatement as a frame.
+// Reading the plan is only a debugging aid, when it fails the frame is empty and a notice explains why.
+func NewPlanFrame(refID string, query string, plan *data.Frame, err error) *data.Frame {
+	frame := &data.Frame{Name: PlanFrameName, RefID: refID}
+	if plan != nil && err == nil {
+		frame.Fields = plan.Fields
+	}
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString:    ExplainStatement(query),
+		PreferredVisualization: data.VisTypeTable,
+	}
+	if err != nil {
+		frame.Meta.Notices = []data.Notice{{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("The query plan of %s could not be read: %s", refID, err),
+		}}
+	}
+	return frame
+}
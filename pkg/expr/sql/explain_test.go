@@ -0,0 +1,28 @@
+package sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPlanFrame(t *testing.T) {
+	require.Equal(t, "EXPLAIN SELECT * FROM A", ExplainStatement("SELECT * FROM A"))
+
+	plan := data.NewFrame("", data.NewField("plan", nil, []string{"Project", " └─ Table", "     └─ name: A"}))
+	frame := NewPlanFrame("B", "SELECT * FROM A", plan, nil)
+	require.Equal(t, PlanFrameName, frame.Name)
+	require.Equal(t, "B", frame.RefID)
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, "EXPLAIN SELECT * FROM A", frame.Meta.ExecutedQueryString)
+	require.Empty(t, frame.Meta.Notices)
+
+	frame = NewPlanFrame("B", "SELECT * FROM A", nil, errors.New("not implemented"))
+	require.Empty(t, frame.Fields)
+	require.Equal(t, []data.Notice{{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "The query plan of B could not be read: not implemented",
+	}}, frame.Meta.Notices)
+}
@@ -0,0 +1,247 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// Function is a function of SQL expressions that is rewritten into standard SQL before the query runs.
+// Unlike macros, functions take columns and expressions as arguments and can be nested.
+type Function struct {
+	// Name is matched case-insensitively, it should not shadow a built-in SQL function
+	Name string
+	// MinArgs and MaxArgs are the number of arguments the function accepts
+	MinArgs int
+	MaxArgs int
+	// Expand returns the SQL replacing a call, args are already expanded
+	Expand func(args []string) (string, error)
+}
+
+var registeredFunctions = struct {
+	sync.RWMutex
+	functions map[string]Function
+}{functions: map[string]Function{}}
+
+func init() {
+	RegisterFunction(Function{Name: "time_bucket", MinArgs: 2, MaxArgs: 2, Expand: expandTimeBucket})
+	RegisterFunction(Function{Name: "rate", MinArgs: 2, MaxArgs: 3, Expand: expandRate})
+	RegisterFunction(Function{Name: "last_over_time", MinArgs: 1, MaxArgs: 2, Expand: expandLastOverTime})
+}
+
+// RegisterFunction adds a function to SQL expressions, replacing a registered function with the same name.
+func RegisterFunction(f Function) {
+	registeredFunctions.Lock()
+	defer registeredFunctions.Unlock()
+	registeredFunctions.functions[strings.ToLower(f.Name)] = f
+}
+
+// UnregisterFunction removes a function from SQL expressions.
+func UnregisterFunction(name string) {
+	registeredFunctions.Lock()
+	defer registeredFunctions.Unlock()
+	delete(registeredFunctions.functions, strings.ToLower(name))
+}
+
+func lookupFunction(name string) (Function, bool) {
+	registeredFunctions.RLock()
+	defer registeredFunctions.RUnlock()
+	f, ok := registeredFunctions.functions[strings.ToLower(name)]
+	return f, ok
+}
+
+// ExpandFunctions rewrites the calls to registered functions in a SQL expression.
+// Quoted strings, quoted identifiers and comments are left as they are.
+func ExpandFunctions(rawSQL string) (string, error) {
+	out := strings.Builder{}
+	for i := 0; i < len(rawSQL); i++ {
+		c := rawSQL[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(rawSQL, i)
+			if end < 0 {
+				return "", errors.New("unterminated quoted string in SQL expression")
+			}
+			out.WriteString(rawSQL[i : end+1])
+			i = end
+		case c == '#', isDashComment(rawSQL, i), strings.HasPrefix(rawSQL[i:], "/*"):
+			end := commentEnd(rawSQL, i)
+			out.WriteString(rawSQL[i:end])
+			i = end - 1
+		case isIdentStart(c) && (i == 0 || !isIdentChar(rawSQL[i-1]) && rawSQL[i-1] != '.'):
+			end := i + 1
+			for end < len(rawSQL) && isIdentChar(rawSQL[end]) {
+				end++
+			}
+			name := rawSQL[i:end]
+			open := end
+			for open < len(rawSQL) && (rawSQL[open] == ' ' || rawSQL[open] == '\t') {
+				open++
+			}
+			f, ok := lookupFunction(name)
+			if !ok || open == len(rawSQL) || rawSQL[open] != '(' {
+				out.WriteString(name)
+				i = end - 1
+				continue
+			}
+			args, closing, err := functionArgs(rawSQL, open)
+			if err != nil {
+				return "", fmt.Errorf("function %s: %w", name, err)
+			}
+			res, err := expandFunction(f, args)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(res)
+			i = closing
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+func expandFunction(f Function, args []string) (string, error) {
+	if len(args) < f.MinArgs || len(args) > f.MaxArgs {
+		if f.MinArgs == f.MaxArgs {
+			return "", fmt.Errorf("function %s needs %d arguments, got %d", f.Name, f.MinArgs, len(args))
+		}
+		return "", fmt.Errorf("function %s needs %d to %d arguments, got %d", f.Name, f.MinArgs, f.MaxArgs, len(args))
+	}
+	for i, arg := range args {
+		if arg == "" {
+			return "", fmt.Errorf("function %s: argument %d is empty", f.Name, i+1)
+		}
+		expanded, err := ExpandFunctions(arg)
+		if err != nil {
+			return "", err
+		}
+		args[i] = expanded
+	}
+	return f.Expand(args)
+}
+
+// functionArgs splits the arguments of the call whose parenthesis opens at open.
+// It returns the trimmed arguments and the index of the closing parenthesis.
+func functionArgs(s string, open int) ([]string, int, error) {
+	args := []string{}
+	depth := 0
+	start := open + 1
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(s, i)
+			if end < 0 {
+				return nil, 0, errors.New("unterminated quoted string")
+			}
+			i = end
+		case c == '#', isDashComment(s, i), strings.HasPrefix(s[i:], "/*"):
+			i = commentEnd(s, i) - 1
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				if arg := strings.TrimSpace(s[start:i]); arg != "" || len(args) > 0 {
+					args = append(args, arg)
+				}
+				return args, i, nil
+			}
+		case c == ',' && depth == 1:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return nil, 0, errors.New("missing closing parenthesis")
+}
+
+// commentEnd returns the index after the comment starting at i
+func commentEnd(s string, i int) int {
+	if strings.HasPrefix(s[i:], "/*") {
+		if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+			return i + end + 4
+		}
+		return len(s)
+	}
+	if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+		return i + end
+	}
+	return len(s)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9' || c == '$'
+}
+
+// operand wraps an argument in parentheses unless it is a column or a number, so it can be used in arithmetic
+func operand(arg string) string {
+	for i := 0; i < len(arg); i++ {
+		if !isIdentChar(arg[i]) && arg[i] != '.' && arg[i] != '`' {
+			return "(" + arg + ")"
+		}
+	}
+	return arg
+}
+
+// expandTimeBucket expands time_bucket(interval, ts) to the start of the interval ts falls in.
+// The interval is a duration string like '5m', e.g. $__interval, or a number of seconds.
+func expandTimeBucket(args []string) (string, error) {
+	seconds, err := bucketSeconds(args[0])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(%s) / %d) * %d)", args[1], seconds, seconds), nil
+}
+
+func bucketSeconds(interval string) (int64, error) {
+	if n, err := strconv.ParseInt(interval, 10, 64); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("function time_bucket needs a positive interval, got %s", interval)
+		}
+		return n, nil
+	}
+	if len(interval) < 2 || interval[0] != '\'' || interval[len(interval)-1] != '\'' {
+		return 0, fmt.Errorf("function time_bucket needs an interval like '5m' or a number of seconds, got %s", interval)
+	}
+	d, err := gtime.ParseInterval(interval[1 : len(interval)-1])
+	if err != nil {
+		return 0, fmt.Errorf("function time_bucket: invalid interval %s: %w", interval, err)
+	}
+	if d.Seconds() < 1 || d%time.Second != 0 {
+		return 0, fmt.Errorf("function time_bucket needs an interval of whole seconds, got %s", interval)
+	}
+	return int64(d.Seconds()), nil
+}
+
+// expandRate expands rate(col, ts[, series]) to the per-second increase of a counter since the previous row.
+// A decrease is treated as a counter reset. With series, the rows of each series are compared separately.
+// The first row of each series has no rate.
+func expandRate(args []string) (string, error) {
+	col, ts := operand(args[0]), operand(args[1])
+	window := "ORDER BY " + args[1]
+	if len(args) == 3 {
+		window = "PARTITION BY " + args[2] + " " + window
+	}
+	prev := fmt.Sprintf("LAG(%s) OVER (%s)", col, window)
+	prevTS := fmt.Sprintf("LAG(%s) OVER (%s)", ts, window)
+	return fmt.Sprintf("(CASE WHEN %s < %s THEN %s ELSE %s - %s END) / (UNIX_TIMESTAMP(%s) - UNIX_TIMESTAMP(%s))",
+		col, prev, col, col, prev, args[1], prevTS), nil
+}
+
+// expandLastOverTime expands last_over_time(col[, ts]) to an aggregate of the last numeric value of col in the group.
+// Without ts, the last value is the last row of the group in the order of the input.
+func expandLastOverTime(args []string) (string, error) {
+	if len(args) == 2 {
+		return fmt.Sprintf("CAST(SUBSTRING_INDEX(GROUP_CONCAT(%s ORDER BY %s DESC SEPARATOR ','), ',', 1) AS DOUBLE)", args[0], args[1]), nil
+	}
+	return fmt.Sprintf("CAST(SUBSTRING_INDEX(GROUP_CONCAT(%s SEPARATOR ','), ',', -1) AS DOUBLE)", args[0]), nil
+}
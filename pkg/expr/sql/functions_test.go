@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+		err      string
+	}{
+		{
+			name:     "time bucket",
+			sql:      "SELECT time_bucket('5m', time) AS t, avg(value) FROM A GROUP BY 1",
+			expected: "SELECT FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(time) / 300) * 300) AS t, avg(value) FROM A GROUP BY 1",
+		},
+		{
+			name:     "time bucket in seconds",
+			sql:      "SELECT TIME_BUCKET(60, time) FROM A",
+			expected: "SELECT FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(time) / 60) * 60) FROM A",
+		},
+		{
+			name:     "rate",
+			sql:      "SELECT time, rate(requests, time) FROM A",
+			expected: "SELECT time, (CASE WHEN requests < LAG(requests) OVER (ORDER BY time) THEN requests ELSE requests - LAG(requests) OVER (ORDER BY time) END) / (UNIX_TIMESTAMP(time) - UNIX_TIMESTAMP(LAG(time) OVER (ORDER BY time))) FROM A",
+		},
+		{
+			name:     "rate by series",
+			sql:      "SELECT rate(a.value * 8, time, host) FROM A a",
+			expected: "SELECT (CASE WHEN (a.value * 8) < LAG((a.value * 8)) OVER (PARTITION BY host ORDER BY time) THEN (a.value * 8) ELSE (a.value * 8) - LAG((a.value * 8)) OVER (PARTITION BY host ORDER BY time) END) / (UNIX_TIMESTAMP(time) - UNIX_TIMESTAMP(LAG(time) OVER (PARTITION BY host ORDER BY time))) FROM A a",
+		},
+		{
+			name:     "nested",
+			sql:      "SELECT time_bucket(300, time), last_over_time(value, time) FROM A GROUP BY time_bucket(300, time)",
+			expected: "SELECT FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(time) / 300) * 300), CAST(SUBSTRING_INDEX(GROUP_CONCAT(value ORDER BY time DESC SEPARATOR ','), ',', 1) AS DOUBLE) FROM A GROUP BY FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP(time) / 300) * 300)",
+		},
+		{
+			name:     "last over time without time column",
+			sql:      "SELECT last_over_time(value) FROM A",
+			expected: "SELECT CAST(SUBSTRING_INDEX(GROUP_CONCAT(value SEPARATOR ','), ',', -1) AS DOUBLE) FROM A",
+		},
+		{
+			name:     "strings, comments and columns are kept",
+			sql:      "SELECT 'rate(x)', `rate`, rate_total, A.rate -- rate(x, y)\nFROM A",
+			expected: "SELECT 'rate(x)', `rate`, rate_total, A.rate -- rate(x, y)\nFROM A",
+		},
+		{
+			name: "wrong number of arguments",
+			sql:  "SELECT rate(value) FROM A",
+			err:  "function rate needs 2 to 3 arguments, got 1",
+		},
+		{
+			name: "invalid interval",
+			sql:  "SELECT time_bucket('500ms', time) FROM A",
+			err:  "needs an interval of whole seconds",
+		},
+		{
+			name: "unterminated call",
+			sql:  "SELECT time_bucket('5m', time FROM A",
+			err:  "missing closing parenthesis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := ExpandFunctions(tt.sql)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql)
+		})
+	}
+}
+
+func TestRegisterFunction(t *testing.T) {
+	RegisterFunction(Function{Name: "Test_Double", MinArgs: 1, MaxArgs: 1, Expand: func(args []string) (string, error) {
+		return "(" + args[0] + " * 2)", nil
+	}})
+	sql, err := ExpandFunctions("SELECT test_double(value) FROM A")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT (value * 2) FROM A", sql)
+
+	UnregisterFunction("test_double")
+	sql, err = ExpandFunctions("SELECT test_double(value) FROM A")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT test_double(value) FROM A", sql)
+}
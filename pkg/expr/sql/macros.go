@@ -0,0 +1,80 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// macroRegexp matches $__name and $__name(args) at the start of the string
+var macroRegexp = regexp.MustCompile(`^\$(__[_a-zA-Z0-9]+)(?:\(([^\)]*)\))?`)
+
+// Interpolate expands the Grafana macros in a SQL expression, like the SQL data sources do.
+// The supported macros are $__timeFilter(column), $__timeFrom(), $__timeTo(), $__interval and $__interval_ms.
+// Quoted strings, quoted identifiers and comments are left as they are.
+func Interpolate(rawSQL string, timeRange backend.TimeRange, interval time.Duration) (string, error) {
+	out := strings.Builder{}
+	for i := 0; i < len(rawSQL); i++ {
+		c := rawSQL[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(rawSQL, i)
+			if end < 0 {
+				return "", errors.New("unterminated quoted string in SQL expression")
+			}
+			out.WriteString(rawSQL[i : end+1])
+			i = end
+		case c == '#', isDashComment(rawSQL, i), strings.HasPrefix(rawSQL[i:], "/*"):
+			end := commentEnd(rawSQL, i)
+			out.WriteString(rawSQL[i:end])
+			i = end - 1
+		case c == '$':
+			groups := macroRegexp.FindStringSubmatch(rawSQL[i:])
+			if groups == nil {
+				out.WriteByte(c)
+				continue
+			}
+			args := []string{}
+			if groups[2] != "" {
+				for _, arg := range strings.Split(groups[2], ",") {
+					args = append(args, strings.TrimSpace(arg))
+				}
+			}
+			res, err := evaluateMacro(groups[1], args, timeRange, interval)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(res)
+			i += len(groups[0]) - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+func evaluateMacro(name string, args []string, timeRange backend.TimeRange, interval time.Duration) (string, error) {
+	switch name {
+	case "__timeFilter":
+		if len(args) != 1 || args[0] == "" {
+			return "", fmt.Errorf("macro %s needs exactly one time column argument", name)
+		}
+		return fmt.Sprintf("%s BETWEEN FROM_UNIXTIME(%d) AND FROM_UNIXTIME(%d)", args[0], timeRange.From.UTC().Unix(), timeRange.To.UTC().Unix()), nil
+	case "__timeFrom":
+		return fmt.Sprintf("FROM_UNIXTIME(%d)", timeRange.From.UTC().Unix()), nil
+	case "__timeTo":
+		return fmt.Sprintf("FROM_UNIXTIME(%d)", timeRange.To.UTC().Unix()), nil
+	case "__interval":
+		return fmt.Sprintf("'%s'", gtime.FormatInterval(interval)), nil
+	case "__interval_ms":
+		return strconv.FormatInt(interval.Milliseconds(), 10), nil
+	default:
+		return "", fmt.Errorf("unknown macro %s", name)
+	}
+}
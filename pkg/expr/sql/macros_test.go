@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+		err      string
+	}{
+		{
+			name:     "time filter",
+			sql:      "SELECT * FROM A WHERE $__timeFilter(time)",
+			expected: "SELECT * FROM A WHERE time BETWEEN FROM_UNIXTIME(1704067200) AND FROM_UNIXTIME(1704070800)",
+		},
+		{
+			name:     "time from and to",
+			sql:      "SELECT * FROM A WHERE time >= $__timeFrom() AND time < $__timeTo()",
+			expected: "SELECT * FROM A WHERE time >= FROM_UNIXTIME(1704067200) AND time < FROM_UNIXTIME(1704070800)",
+		},
+		{
+			name:     "interval",
+			sql:      "SELECT $__interval, $__interval_ms FROM A",
+			expected: "SELECT '30s', 30000 FROM A",
+		},
+		{
+			name:     "no macros",
+			sql:      "SELECT * FROM A",
+			expected: "SELECT * FROM A",
+		},
+		{
+			name:     "macros in quotes and comments",
+			sql:      "SELECT 'cost in $__x', `$__interval` FROM A -- $__timeFilter()\nWHERE $__timeFilter(time) /* $__y */",
+			expected: "SELECT 'cost in $__x', `$__interval` FROM A -- $__timeFilter()\nWHERE time BETWEEN FROM_UNIXTIME(1704067200) AND FROM_UNIXTIME(1704070800) /* $__y */",
+		},
+		{
+			name: "unterminated quote",
+			sql:  "SELECT '$__interval FROM A",
+			err:  "unterminated quoted string",
+		},
+		{
+			name: "time filter without column",
+			sql:  "SELECT * FROM A WHERE $__timeFilter()",
+			err:  "needs exactly one time column argument",
+		},
+		{
+			name: "unknown macro",
+			sql:  "SELECT $__unixEpochFilter(time) FROM A",
+			err:  "unknown macro __unixEpochFilter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := Interpolate(tt.sql, tr, 30*time.Second)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql)
+		})
+	}
+}
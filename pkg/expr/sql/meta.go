@@ -0,0 +1,88 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// NewFrameMeta describes the execution of a SQL expression so it can be inspected from panels:
+// the query after the macros are expanded, the number of rows read and returned, and warnings
+// about the parts of the input frames that could not be loaded into tables as they are.
+func NewFrameMeta(executedQuery string, inputs []*data.Frame, output *data.Frame) *data.FrameMeta {
+	rowsIn := 0
+	for _, f := range inputs {
+		rowsIn += f.Rows()
+	}
+	rowsOut := 0
+	if output != nil {
+		rowsOut = output.Rows()
+	}
+
+	return &data.FrameMeta{
+		ExecutedQueryString:    executedQuery,
+		PreferredVisualization: data.VisTypeTable,
+		Stats: []data.QueryStat{
+			{FieldConfig: data.FieldConfig{DisplayName: "Input rows"}, Value: float64(rowsIn)},
+			{FieldConfig: data.FieldConfig{DisplayName: "Output rows"}, Value: float64(rowsOut)},
+		},
+		Notices: InputNotices(inputs),
+	}
+}
+
+// InputNotices returns a warning for every table whose fields lose information when the frames are
+// loaded: the labels of the fields are dropped, and enum values have no column type and are coerced.
+func InputNotices(inputs []*data.Frame) []data.Notice {
+	labeled := map[string][]string{}
+	coerced := map[string][]string{}
+	tables := []string{}
+	for _, f := range inputs {
+		if f == nil {
+			continue
+		}
+		if _, ok := labeled[f.RefID]; !ok {
+			tables = append(tables, f.RefID)
+			labeled[f.RefID] = []string{}
+		}
+		for _, field := range f.Fields {
+			if len(field.Labels) > 0 {
+				labeled[f.RefID] = appendUnique(labeled[f.RefID], field.Name)
+			}
+			if field.Type().NonNullableType() == data.FieldTypeEnum {
+				coerced[f.RefID] = appendUnique(coerced[f.RefID], field.Name)
+			}
+		}
+	}
+	sort.Strings(tables)
+
+	notices := []data.Notice{}
+	for _, table := range tables {
+		if fields := labeled[table]; len(fields) > 0 {
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("The labels of the fields %s of %s are dropped in SQL expressions.", strings.Join(fields, ", "), table),
+			})
+		}
+		if fields := coerced[table]; len(fields) > 0 {
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("The fields %s of %s are converted to text in SQL expressions.", strings.Join(fields, ", "), table),
+			})
+		}
+	}
+	if len(notices) == 0 {
+		return nil
+	}
+	return notices
+}
+
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
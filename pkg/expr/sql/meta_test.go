@@ -0,0 +1,47 @@
+package sql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFrameMeta(t *testing.T) {
+	a := data.NewFrame("",
+		data.NewField("time", nil, []float64{1, 2}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{1, 2}),
+	)
+	a.RefID = "A"
+	b := data.NewFrame("",
+		data.NewField("value", data.Labels{"host": "b"}, []float64{3}),
+		data.NewField("doc", nil, []json.RawMessage{json.RawMessage(`{}`)}),
+		data.NewField("level", nil, []data.EnumItemIndex{0}),
+	)
+	b.RefID = "B"
+	a2 := data.NewFrame("",
+		data.NewField("value", data.Labels{"host": "c"}, []float64{4}),
+	)
+	a2.RefID = "A"
+	out := data.NewFrame("", data.NewField("value", nil, []float64{1}))
+
+	meta := NewFrameMeta("SELECT value FROM A", []*data.Frame{b, a, a2}, out)
+	require.Equal(t, "SELECT value FROM A", meta.ExecutedQueryString)
+	require.Equal(t, data.VisType(data.VisTypeTable), meta.PreferredVisualization)
+	require.Equal(t, []data.QueryStat{
+		{FieldConfig: data.FieldConfig{DisplayName: "Input rows"}, Value: 4},
+		{FieldConfig: data.FieldConfig{DisplayName: "Output rows"}, Value: 1},
+	}, meta.Stats)
+	require.Equal(t, []data.Notice{
+		{Severity: data.NoticeSeverityWarning, Text: "The labels of the fields value of A are dropped in SQL expressions."},
+		{Severity: data.NoticeSeverityWarning, Text: "The labels of the fields value of B are dropped in SQL expressions."},
+		{Severity: data.NoticeSeverityWarning, Text: "The fields level of B are converted to text in SQL expressions."},
+	}, meta.Notices)
+}
+
+func TestInputNoticesWithoutWarnings(t *testing.T) {
+	f := data.NewFrame("", data.NewField("value", nil, []float64{1}))
+	f.RefID = "A"
+	require.Nil(t, InputNotices([]*data.Frame{f}))
+}
@@ -0,0 +1,63 @@
+package sql
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Column is a column of the table a frame is loaded into.
+type Column struct {
+	Name string
+	Type string
+}
+
+// TableColumns returns the columns of the table f is loaded into, in the order of the fields of f,
+// so SELECT * returns the fields in the order they have in the frame.
+func TableColumns(f *data.Frame) []Column {
+	columns := make([]Column, len(f.Fields))
+	for i, field := range f.Fields {
+		columns[i] = Column{Name: field.Name, Type: ColumnType(field.Type())}
+	}
+	return columns
+}
+
+// TableRows returns the values of the rows of f in the order of the frame. The rows are inserted in this
+// order, so a query without ORDER BY, and the rows that compare equal in an ORDER BY, keep the order of the frame.
+// With errs, the values that can not be converted are nil and recorded in errs.
+func TableRows(f *data.Frame, errs *ConversionErrors) ([][]any, error) {
+	rows := make([][]any, f.Rows())
+	for i := range rows {
+		row := make([]any, len(f.Fields))
+		for j, field := range f.Fields {
+			v, err := tableValue(f, field, i, errs)
+			if err != nil {
+				return nil, err
+			}
+			row[j] = v
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// tableValue returns the value of a field of f at idx as it is stored in its column. A value that can not be
+// converted is recorded in errs as a value of the column table.field, and is nil.
+func tableValue(f *data.Frame, field *data.Field, idx int, errs *ConversionErrors) (any, error) {
+	v, err := ColumnValue(field, idx)
+	if err != nil {
+		return nil, errs.add(f.RefID+"."+field.Name, err)
+	}
+	return v, nil
+}
+
+// ResultFrame builds the frame of a query result. The fields are in the order of the columns of the result,
+// and the rows in the order the engine returned them, so the ORDER BY of the query is kept in the frame.
+// With errs, the values that can not be converted are nil and recorded in errs.
+func ResultFrame(name string, columns []string, rows [][]any, errs *ConversionErrors) (*data.Frame, error) {
+	b := NewResultFrameBuilder(name, columns, len(rows), errs)
+	for _, row := range rows {
+		if err := b.Append(row); err != nil {
+			return nil, err
+		}
+	}
+	return b.Frame(), nil
+}
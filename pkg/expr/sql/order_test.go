@@ -0,0 +1,257 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableColumns(t *testing.T) {
+	f := data.NewFrame("A",
+		data.NewField("value", nil, []float64{1}),
+		data.NewField("time", nil, []time.Time{{}}),
+		data.NewField("name", nil, []*string{nil}),
+	)
+	require.Equal(t, []Column{
+		{Name: "value", Type: ColumnTypeDouble},
+		{Name: "time", Type: ColumnTypeDatetime},
+		{Name: "name", Type: ColumnTypeText},
+	}, TableColumns(f))
+}
+
+func TestResultFrame(t *testing.T) {
+	f, err := ResultFrame("A", []string{"b", "a"}, [][]any{{"x", int64(2)}, {"y", nil}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "b", f.Fields[0].Name)
+	require.Equal(t, "a", f.Fields[1].Name)
+	require.Equal(t, "y", f.Fields[0].At(1))
+	require.Nil(t, f.Fields[1].At(1))
+
+	_, err = ResultFrame("A", []string{"b", "a"}, [][]any{{"x"}}, nil)
+	require.Error(t, err)
+}
+
+// orderKey is a column of an ORDER BY clause
+type orderKey struct {
+	field int
+	desc  bool
+}
+
+// queryFunc runs a SELECT * FROM <frame> ORDER BY <keys> query over a frame
+type queryFunc func(frame *data.Frame, keys []orderKey) (*data.Frame, error)
+
+// TestOrderBy runs ORDER BY queries over random frames with many equal values, and checks that the fields keep
+// the order of the frame and the rows are sorted, with equal rows in the order of the frame.
+func TestOrderBy(t *testing.T) {
+	t.Run("reference engine", func(t *testing.T) {
+		checkOrderBy(t, referenceQuery)
+	})
+
+	t.Run("in-memory engine", func(t *testing.T) {
+		db := NewInMemoryDB()
+		_, err := db.RunCommands([]string{"SELECT 1"})
+		if errors.Is(err, errNotImplemented) {
+			t.Skip("the in-memory engine is not available")
+		}
+		checkOrderBy(t, func(frame *data.Frame, keys []orderKey) (*data.Frame, error) {
+			out := &data.Frame{}
+			err := db.QueryFramesInto(context.Background(), frame.RefID, orderByQuery(frame, keys), []*data.Frame{frame}, out)
+			return out, err
+		})
+	})
+}
+
+func checkOrderBy(t *testing.T, query queryFunc) {
+	t.Helper()
+	for seed := int64(1); seed <= 200; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		frame := randomFrame(r)
+		keys := randomOrderKeys(r, frame)
+
+		out, err := query(frame, keys)
+		require.NoError(t, err, "seed %d: %s", seed, orderByQuery(frame, keys))
+		requireRowOrder(t, frame, expectedOrder(frame, keys), out, fmt.Sprintf("seed %d: %s", seed, orderByQuery(frame, keys)))
+	}
+}
+
+// requireRowOrder checks that out holds the fields of frame in the same order and its rows in the given order
+func requireRowOrder(t *testing.T, frame *data.Frame, order []int, out *data.Frame, msg string) {
+	t.Helper()
+	require.Len(t, out.Fields, len(frame.Fields), msg)
+	require.Equal(t, len(order), out.Rows(), msg)
+	for i, field := range frame.Fields {
+		require.Equal(t, field.Name, out.Fields[i].Name, msg)
+		for row, idx := range order {
+			expected := valueAt(field, idx)
+			actual := valueAt(out.Fields[i], row)
+			if e, ok := expected.(time.Time); ok {
+				require.True(t, e.Equal(actual.(time.Time)), "%s: field %s row %d", msg, field.Name, row)
+				continue
+			}
+			require.Equal(t, expected, actual, "%s: field %s row %d", msg, field.Name, row)
+		}
+	}
+}
+
+// referenceQuery sorts the rows the way the engine is expected to: NULL values first, and a stable sort so
+// equal rows keep the order they were inserted in
+func referenceQuery(frame *data.Frame, keys []orderKey) (*data.Frame, error) {
+	columns := TableColumns(frame)
+	rows, err := TableRows(frame, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			c := compareValues(rows[i][k.field], rows[j][k.field])
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return ResultFrame(frame.RefID, names, rows, nil)
+}
+
+// expectedOrder returns the indexes of the rows of frame sorted by keys, computed from the fields of the frame
+func expectedOrder(frame *data.Frame, keys []orderKey) []int {
+	order := make([]int, frame.Rows())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for _, k := range keys {
+			c := compareValues(valueAt(frame.Fields[k.field], order[i]), valueAt(frame.Fields[k.field], order[j]))
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return order
+}
+
+// valueAt returns the value of the row of field, nil for NULL values instead of the zero value of ConcreteAt
+func valueAt(field *data.Field, row int) any {
+	v, ok := field.ConcreteAt(row)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// compareValues compares values of the same column, NULL is lower than any value like in MySQL
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch va := a.(type) {
+	case int64:
+		return compareOrdered(va, b.(int64))
+	case float64:
+		return compareOrdered(va, b.(float64))
+	case string:
+		return strings.Compare(va, b.(string))
+	case bool:
+		vb := b.(bool)
+		switch {
+		case va == vb:
+			return 0
+		case vb:
+			return -1
+		default:
+			return 1
+		}
+	case time.Time:
+		return va.Compare(b.(time.Time))
+	}
+	panic(fmt.Sprintf("unexpected %T", a))
+}
+
+func compareOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// randomFrame returns a frame with few distinct values per field, so ORDER BY has many equal rows
+func randomFrame(r *rand.Rand) *data.Frame {
+	rows := r.Intn(30)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fields := []*data.Field{
+		data.NewField("id", nil, make([]int64, rows)),
+		data.NewField("time", nil, make([]time.Time, rows)),
+		data.NewField("host", nil, make([]string, rows)),
+		data.NewField("value", nil, make([]*float64, rows)),
+		data.NewField("up", nil, make([]bool, rows)),
+	}
+	for i := 0; i < rows; i++ {
+		// the ids are unique, they show which row of the frame ends up where
+		fields[0].Set(i, int64(i))
+		fields[1].Set(i, base.Add(time.Duration(r.Intn(3))*time.Minute))
+		fields[2].Set(i, fmt.Sprintf("host-%d", r.Intn(3)))
+		if r.Intn(4) > 0 {
+			v := float64(r.Intn(5)) / 2
+			fields[3].Set(i, &v)
+		}
+		fields[4].Set(i, r.Intn(2) == 0)
+	}
+
+	// the fields are shuffled so the order of the frame is not the order of the types
+	r.Shuffle(len(fields), func(i, j int) { fields[i], fields[j] = fields[j], fields[i] })
+	frame := data.NewFrame("A", fields...)
+	frame.RefID = "A"
+	return frame
+}
+
+// randomOrderKeys returns up to three ORDER BY columns, the id column is never used so equal rows are likely
+func randomOrderKeys(r *rand.Rand, frame *data.Frame) []orderKey {
+	keys := []orderKey{}
+	for _, i := range r.Perm(len(frame.Fields)) {
+		if frame.Fields[i].Name == "id" {
+			continue
+		}
+		keys = append(keys, orderKey{field: i, desc: r.Intn(2) == 0})
+	}
+	return keys[:1+r.Intn(3)]
+}
+
+func orderByQuery(frame *data.Frame, keys []orderKey) string {
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		order[i] = fmt.Sprintf("`%s`", frame.Fields[k.field].Name)
+		if k.desc {
+			order[i] += " DESC"
+		}
+	}
+	return fmt.Sprintf("SELECT * FROM %s ORDER BY %s", frame.RefID, strings.Join(order, ", "))
+}
@@ -0,0 +1,109 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Parameters are the values of the dashboard and alert variables a SQL expression references, e.g. $region.
+// A multi-value variable has a value per selected option.
+type Parameters map[string][]string
+
+// BindParameters replaces the references to variables, $name and ${name}, with placeholders and returns the values
+// to bind to them, in the order of the placeholders. The values are passed apart from the query, so a value is never
+// read as SQL. A multi-value variable is expanded to a placeholder per value, e.g. region IN ($region) becomes
+// region IN (?, ?), and a variable without value to NULL, which matches no row.
+// Quoted strings, quoted identifiers and comments are left as they are, and the macros, $__name, are expanded before.
+func BindParameters(rawSQL string, params Parameters) (string, []any, error) {
+	args := []any{}
+	query, err := replaceParameters(rawSQL, func(name string) (string, error) {
+		values, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("the variable $%s of the SQL expression has no value", name)
+		}
+		if len(values) == 0 {
+			return "NULL", nil
+		}
+		for _, v := range values {
+			args = append(args, v)
+		}
+		return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return query, args, nil
+}
+
+// ParameterPlaceholders replaces the references to variables with a placeholder each, so the query can be parsed
+// before the values of the variables are known.
+func ParameterPlaceholders(rawSQL string) (string, error) {
+	return replaceParameters(rawSQL, func(string) (string, error) { return "?", nil })
+}
+
+// replaceParameters replaces the references to variables outside quotes and comments with the result of replace
+func replaceParameters(rawSQL string, replace func(name string) (string, error)) (string, error) {
+	out := strings.Builder{}
+	for i := 0; i < len(rawSQL); i++ {
+		c := rawSQL[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(rawSQL, i)
+			if end < 0 {
+				return "", errors.New("unterminated quoted string in SQL expression")
+			}
+			out.WriteString(rawSQL[i : end+1])
+			i = end
+		case c == '#', isDashComment(rawSQL, i), strings.HasPrefix(rawSQL[i:], "/*"):
+			end := commentEnd(rawSQL, i)
+			out.WriteString(rawSQL[i:end])
+			i = end - 1
+		case c == '$' && (i == 0 || !isIdentChar(rawSQL[i-1])):
+			name, end, err := parameterName(rawSQL, i)
+			if err != nil {
+				return "", err
+			}
+			if name == "" || strings.HasPrefix(name, "__") {
+				out.WriteString(rawSQL[i:end])
+				i = end - 1
+				continue
+			}
+			res, err := replace(name)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(res)
+			i = end - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+// parameterName returns the name of the variable referenced at start, $name or ${name}, and the index after the
+// reference. The name is empty when no variable is referenced.
+func parameterName(s string, start int) (string, int, error) {
+	if strings.HasPrefix(s[start:], "${") {
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", 0, errors.New("missing closing brace of a variable in SQL expression")
+		}
+		name := s[start+2 : start+end]
+		for i := 0; i < len(name); i++ {
+			if !isIdentStart(name[i]) && (i == 0 || name[i] < '0' || name[i] > '9') {
+				return "", 0, fmt.Errorf("invalid variable name %q in SQL expression", name)
+			}
+		}
+		return name, start + end + 1, nil
+	}
+	end := start + 1
+	if end == len(s) || !isIdentStart(s[end]) {
+		return "", end, nil
+	}
+	for end < len(s) && isIdentChar(s[end]) && s[end] != '$' {
+		end++
+	}
+	return s[start+1 : end], end, nil
+}
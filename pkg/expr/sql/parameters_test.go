@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindParameters(t *testing.T) {
+	params := Parameters{
+		"region": {"eu"},
+		"host":   {"a", "b'; DROP TABLE A; --"},
+		"none":   {},
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+		args     []any
+		err      string
+	}{
+		{
+			name:     "single value",
+			sql:      "SELECT * FROM A WHERE region = $region",
+			expected: "SELECT * FROM A WHERE region = ?",
+			args:     []any{"eu"},
+		},
+		{
+			name:     "multi value expanded to a list",
+			sql:      "SELECT * FROM A WHERE host IN (${host}) AND region = $region",
+			expected: "SELECT * FROM A WHERE host IN (?, ?) AND region = ?",
+			args:     []any{"a", "b'; DROP TABLE A; --", "eu"},
+		},
+		{
+			name:     "no value",
+			sql:      "SELECT * FROM A WHERE host IN ($none)",
+			expected: "SELECT * FROM A WHERE host IN (NULL)",
+			args:     []any{},
+		},
+		{
+			name:     "quotes, comments and macros are kept",
+			sql:      "SELECT '$region', `$region` FROM A -- $region\nWHERE t > $__timeFrom() AND a$region = 1",
+			expected: "SELECT '$region', `$region` FROM A -- $region\nWHERE t > $__timeFrom() AND a$region = 1",
+			args:     []any{},
+		},
+		{
+			name: "unknown variable",
+			sql:  "SELECT * FROM A WHERE env = $env",
+			err:  "the variable $env of the SQL expression has no value",
+		},
+		{
+			name: "unclosed brace",
+			sql:  "SELECT * FROM A WHERE env = ${env",
+			err:  "missing closing brace",
+		},
+		{
+			name: "formatted variable",
+			sql:  "SELECT * FROM A WHERE host IN (${host:csv})",
+			err:  `invalid variable name "host:csv"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := BindParameters(tt.sql, params)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql)
+			require.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestParameterPlaceholders(t *testing.T) {
+	sql, err := ParameterPlaceholders("SELECT * FROM A WHERE host IN ($host) AND region = ${region}")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM A WHERE host IN (?) AND region = ?", sql)
+}
@@ -0,0 +1,183 @@
+package sql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ResultFrameBuilder builds the frame of a query result from its rows as the engine iterates them, so the rows of
+// large results are not kept as values of type any. Every column appends its values into a slice of the type of its
+// first non-nil value, allocated with the estimated number of rows of the result.
+type ResultFrameBuilder struct {
+	name    string
+	columns []resultColumn
+	rows    int
+	// errs records the values that can not be converted, nil when they fail the result
+	errs *ConversionErrors
+}
+
+// NewResultFrameBuilder returns a builder for a result with the given columns. rowsHint is the estimated number
+// of rows of the result, the values of the columns are preallocated for it. It can be 0 when it is not known.
+// With errs, a value that does not have the type of its column is nil and recorded in errs.
+func NewResultFrameBuilder(name string, columns []string, rowsHint int, errs *ConversionErrors) *ResultFrameBuilder {
+	b := &ResultFrameBuilder{
+		name:    name,
+		columns: make([]resultColumn, len(columns)),
+		errs:    errs,
+	}
+	for i, column := range columns {
+		b.columns[i] = resultColumn{name: column, capacity: max(rowsHint, 0)}
+	}
+	return b
+}
+
+// Append adds a row of the result. The row is not kept, the engine can reuse it for the next row.
+func (b *ResultFrameBuilder) Append(row []any) error {
+	if len(row) != len(b.columns) {
+		return fmt.Errorf("row %d has %d values, expected %d", b.rows, len(row), len(b.columns))
+	}
+	for i, v := range row {
+		if err := b.columns[i].append(v, b.rows); err != nil {
+			if err = b.errs.add(b.columns[i].name, err); err != nil {
+				return err
+			}
+			b.columns[i].typed.appendNil()
+		}
+	}
+	b.rows++
+	return nil
+}
+
+// Frame returns the frame of the rows appended so far, with the fields in the order of the columns.
+func (b *ResultFrameBuilder) Frame() *data.Frame {
+	frame := data.NewFrame(b.name)
+	frame.Fields = make([]*data.Field, len(b.columns))
+	for i := range b.columns {
+		frame.Fields[i] = b.columns[i].field()
+	}
+	return frame
+}
+
+// resultColumn appends the values of a result column. Until the first non-nil value, the type of the column is not
+// known and only the number of nil values is counted.
+type resultColumn struct {
+	name     string
+	capacity int
+	nils     int
+	typed    typedValues
+}
+
+// typedValues are the values of a result column of a single type.
+type typedValues interface {
+	append(v any) error
+	appendNil()
+	field(name string) *data.Field
+}
+
+func (c *resultColumn) append(v any, row int) error {
+	if c.typed == nil {
+		if v == nil {
+			c.nils++
+			return nil
+		}
+		c.typed = newTypedValues(v, c.capacity)
+		for range c.nils {
+			c.typed.appendNil()
+		}
+	}
+	if v == nil {
+		c.typed.appendNil()
+		return nil
+	}
+	if err := c.typed.append(v); err != nil {
+		return fmt.Errorf("column %s: unexpected %T at row %d: %w", c.name, v, row, err)
+	}
+	return nil
+}
+
+// field returns the field of the column. A column without values, or with only nil values, is a string field.
+func (c *resultColumn) field() *data.Field {
+	if c.typed == nil {
+		if c.nils == 0 {
+			return data.NewField(c.name, nil, []string{})
+		}
+		return data.NewField(c.name, nil, make([]*string, c.nils))
+	}
+	return c.typed.field(c.name)
+}
+
+// newTypedValues returns the values of a column whose first non-nil value is v. Nested values such as the objects
+// and arrays returned by JSON_EXTRACT, and multi-value string arrays, are stored as JSON.
+func newTypedValues(v any, capacity int) typedValues {
+	switch v.(type) {
+	case string:
+		return newColumnValues[string](capacity, nil)
+	case bool:
+		return newColumnValues[bool](capacity, nil)
+	case int64:
+		return newColumnValues[int64](capacity, nil)
+	case uint64:
+		return newColumnValues[uint64](capacity, nil)
+	case float64:
+		return newColumnValues[float64](capacity, nil)
+	case time.Time:
+		return newColumnValues[time.Time](capacity, nil)
+	default:
+		return newColumnValues(capacity, toJSON)
+	}
+}
+
+// columnValues holds the values of a column in a slice of their type. The nil values are zero values marked in nulls,
+// which is only allocated with the first nil value. Without convert, the values must be of type T.
+type columnValues[T any] struct {
+	values  []T
+	nulls   []bool
+	convert func(any) (T, error)
+}
+
+func newColumnValues[T any](capacity int, convert func(any) (T, error)) *columnValues[T] {
+	return &columnValues[T]{values: make([]T, 0, capacity), convert: convert}
+}
+
+func (c *columnValues[T]) append(v any) error {
+	t, ok := v.(T)
+	if c.convert != nil {
+		var err error
+		if t, err = c.convert(v); err != nil {
+			return err
+		}
+	} else if !ok {
+		return errMixedTypes
+	}
+	c.values = append(c.values, t)
+	if c.nulls != nil {
+		c.nulls = append(c.nulls, false)
+	}
+	return nil
+}
+
+func (c *columnValues[T]) appendNil() {
+	if c.nulls == nil {
+		c.nulls = make([]bool, len(c.values), cap(c.values))
+	}
+	var zero T
+	c.values = append(c.values, zero)
+	c.nulls = append(c.nulls, true)
+}
+
+// field returns a nullable field when a value is nil. Its pointers point into the slice of values, so the values
+// are not copied again.
+func (c *columnValues[T]) field(name string) *data.Field {
+	if c.nulls == nil {
+		return data.NewField(name, nil, c.values)
+	}
+	out := make([]*T, len(c.values))
+	for i := range c.values {
+		if !c.nulls[i] {
+			out[i] = &c.values[i]
+		}
+	}
+	return data.NewField(name, nil, out)
+}
@@ -0,0 +1,96 @@
+package sql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultFrameBuilder(t *testing.T) {
+	t.Run("appends the rows into typed fields", func(t *testing.T) {
+		b := NewResultFrameBuilder("A", []string{"time", "value", "host", "tags"}, 3, nil)
+		at := time.Unix(100, 0)
+		require.NoError(t, b.Append([]any{at, nil, nil, nil}))
+		require.NoError(t, b.Append([]any{at.Add(time.Second), 1.5, "a", []any{"x"}}))
+		require.NoError(t, b.Append([]any{at.Add(2 * time.Second), nil, "b", map[string]any{"y": 1}}))
+
+		f := b.Frame()
+		require.Equal(t, "A", f.Name)
+		require.Equal(t, data.FieldTypeTime, f.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, f.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableString, f.Fields[2].Type())
+		require.Equal(t, data.FieldTypeNullableJSON, f.Fields[3].Type())
+		require.Equal(t, 3, f.Rows())
+
+		require.Nil(t, f.Fields[1].At(0), "the nil values before the first value are kept")
+		require.Equal(t, 1.5, *f.Fields[1].At(1).(*float64))
+		require.Nil(t, f.Fields[1].At(2))
+		require.Equal(t, "b", *f.Fields[2].At(2).(*string))
+	})
+
+	t.Run("keeps the rows appended before Frame", func(t *testing.T) {
+		b := NewResultFrameBuilder("A", []string{"value"}, 0, nil)
+		require.NoError(t, b.Append([]any{int64(1)}))
+		f := b.Frame()
+		require.NoError(t, b.Append([]any{int64(2)}))
+		require.Equal(t, 1, f.Rows())
+		require.Equal(t, 2, b.Frame().Rows())
+	})
+
+	t.Run("rejects invalid rows", func(t *testing.T) {
+		b := NewResultFrameBuilder("A", []string{"value"}, 0, nil)
+		require.NoError(t, b.Append([]any{int64(1)}))
+		require.ErrorContains(t, b.Append([]any{"a"}), "column value: unexpected string at row 1")
+		require.ErrorContains(t, b.Append([]any{int64(1), int64(2)}), "has 2 values, expected 1")
+	})
+
+	t.Run("tolerates invalid values with conversion errors", func(t *testing.T) {
+		errs := NewConversionErrors()
+		b := NewResultFrameBuilder("A", []string{"value", "host"}, 0, errs)
+		require.NoError(t, b.Append([]any{int64(1), "a"}))
+		require.NoError(t, b.Append([]any{"x", "b"}))
+		require.NoError(t, b.Append([]any{"y", "c"}))
+
+		f := b.Frame()
+		require.Equal(t, 3, f.Rows())
+		require.Equal(t, data.FieldTypeNullableInt64, f.Fields[0].Type())
+		require.Equal(t, int64(1), *f.Fields[0].At(0).(*int64))
+		require.Nil(t, f.Fields[0].At(1))
+		require.Nil(t, f.Fields[0].At(2))
+		require.Equal(t, 2, errs.Count("value"))
+		require.Equal(t, 0, errs.Count("host"))
+	})
+}
+
+// BenchmarkResultFrameBuilder streams the rows of a large result through a row the engine reuses, as its row
+// iterator does.
+func BenchmarkResultFrameBuilder(b *testing.B) {
+	const n = 200_000
+	start := time.Unix(0, 0)
+	hosts := make([]string, 100)
+	for i := range hosts {
+		hosts[i] = fmt.Sprintf("host-%d", i)
+	}
+	columns := []string{"time", "value", "host", "count"}
+	row := make([]any, len(columns))
+
+	b.ReportAllocs()
+	for range b.N {
+		builder := NewResultFrameBuilder("A", columns, n, nil)
+		for i := range n {
+			row[0], row[1], row[2], row[3] = start.Add(time.Duration(i)*time.Second), float64(i), hosts[i%100], int64(i)
+			if i%10 == 0 {
+				row[1] = nil
+			}
+			if err := builder.Append(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if builder.Frame().Rows() != n {
+			b.Fatal("missing rows")
+		}
+	}
+}
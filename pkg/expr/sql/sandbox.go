@@ -0,0 +1,180 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultAllowedStatements are the statement types SQL expressions can run unless configured otherwise.
+var DefaultAllowedStatements = []string{"SELECT", "WITH"}
+
+// ValidateStatement returns an error unless rawSQL holds a single statement of an allowed type.
+// The engine runs statements such as CREATE USER or SET, so anything outside the allow-list is rejected before the query is run.
+// A WITH statement is only allowed when the statement that follows the common table expressions is allowed too.
+func ValidateStatement(rawSQL string, allowed []string) error {
+	statements, err := splitStatements(rawSQL)
+	if err != nil {
+		return err
+	}
+	switch len(statements) {
+	case 0:
+		return errors.New("no statement found in SQL expression")
+	case 1:
+	default:
+		return fmt.Errorf("SQL expressions must contain a single statement, found %d", len(statements))
+	}
+
+	for _, kind := range statementTypes(statements[0]) {
+		if !isAllowed(kind, allowed) {
+			return fmt.Errorf("%s statements are not allowed in SQL expressions", kind)
+		}
+	}
+	return nil
+}
+
+func isAllowed(kind string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(a, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitStatements removes comments and splits rawSQL on the semicolons outside of quotes.
+// String literals are replaced with empty ones so their content is never read as keywords.
+func splitStatements(rawSQL string) ([]string, error) {
+	statements := []string{}
+	current := strings.Builder{}
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(rawSQL); i++ {
+		c := rawSQL[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(rawSQL, i)
+			if end < 0 {
+				return nil, errors.New("unterminated quoted string in SQL expression")
+			}
+			if c == '`' {
+				// quoted identifiers are kept, they can be table names
+				current.WriteString(rawSQL[i : end+1])
+			} else {
+				current.WriteString("''")
+			}
+			i = end
+		case c == '#', isDashComment(rawSQL, i):
+			end := strings.IndexByte(rawSQL[i:], '\n')
+			if end < 0 {
+				i = len(rawSQL)
+			} else {
+				i += end
+			}
+			current.WriteByte(' ')
+		case c == '/' && strings.HasPrefix(rawSQL[i:], "/*"):
+			if strings.HasPrefix(rawSQL[i:], "/*!") || strings.HasPrefix(rawSQL[i:], "/*+") {
+				return nil, errors.New("executable comments and optimizer hints are not allowed in SQL expressions")
+			}
+			end := strings.Index(rawSQL[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment in SQL expression")
+			}
+			i += end + 3
+			current.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements, nil
+}
+
+// isDashComment reports whether a -- comment starts at i. Like MySQL, the dashes must be followed by whitespace.
+func isDashComment(s string, i int) bool {
+	if !strings.HasPrefix(s[i:], "--") {
+		return false
+	}
+	return i+2 == len(s) || unicode.IsSpace(rune(s[i+2]))
+}
+
+// closingQuote returns the index of the quote closing the one at start, or -1.
+// Quotes are escaped by doubling them or, except for identifiers, with a backslash.
+func closingQuote(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// statementTypes returns the upper case type of the statement, for example SELECT.
+// For a WITH statement, the type of the statement following the common table expressions is returned as well.
+func statementTypes(stmt string) []string {
+	words := topLevelWords(stmt)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	if words[0] != "WITH" {
+		return words[:1]
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE", "TABLE", "VALUES":
+			return []string{"WITH", w}
+		}
+	}
+	return []string{"WITH"}
+}
+
+// topLevelWords returns the upper case words of stmt that are not inside parentheses.
+// A statement wrapped in parentheses, like (SELECT 1) UNION (SELECT 2), starts with the first word inside them.
+func topLevelWords(stmt string) []string {
+	stmt = strings.TrimLeft(stmt, "( \t\r\n")
+	words := []string{}
+	depth := 0
+	word := strings.Builder{}
+	endWord := func() {
+		if word.Len() > 0 && depth == 0 {
+			words = append(words, strings.ToUpper(word.String()))
+		}
+		word.Reset()
+	}
+	for _, r := range stmt {
+		switch {
+		case r == '(':
+			endWord()
+			depth++
+		case r == ')':
+			endWord()
+			if depth > 0 {
+				depth--
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word.WriteRune(r)
+		default:
+			endWord()
+		}
+	}
+	endWord()
+	return words
+}
@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		allowed []string
+		err     string
+	}{
+		{
+			name: "select",
+			sql:  "SELECT * FROM A;",
+		},
+		{
+			name: "lower case select in parentheses",
+			sql:  "(select * from A) union (select * from B)",
+		},
+		{
+			name: "with select",
+			sql:  "WITH x AS (SELECT * FROM A) SELECT * FROM x",
+		},
+		{
+			name: "semicolon in a string",
+			sql:  "SELECT 'a;DROP TABLE A' FROM A",
+		},
+		{
+			name: "keywords in a comment",
+			sql:  "-- DELETE FROM A\nSELECT * FROM A /* ; SET x = 1 */",
+		},
+		{
+			name: "set",
+			sql:  "SET @@autocommit = 0",
+			err:  "SET statements are not allowed",
+		},
+		{
+			name: "create user",
+			sql:  "create user admin",
+			err:  "CREATE statements are not allowed",
+		},
+		{
+			name: "with delete",
+			sql:  "WITH x AS (SELECT * FROM A) DELETE FROM A",
+			err:  "DELETE statements are not allowed",
+		},
+		{
+			name: "multiple statements",
+			sql:  "SELECT * FROM A; DROP TABLE A",
+			err:  "must contain a single statement, found 2",
+		},
+		{
+			name: "statement hidden after arithmetic that looks like a comment",
+			sql:  "SELECT 1--1; DROP TABLE A",
+			err:  "must contain a single statement, found 2",
+		},
+		{
+			name: "escaped quote",
+			sql:  `SELECT 'it\'s;' FROM A; DROP TABLE A`,
+			err:  "must contain a single statement, found 2",
+		},
+		{
+			name: "executable comment",
+			sql:  "SELECT /*! 1; DROP TABLE A */",
+			err:  "executable comments",
+		},
+		{
+			name: "unterminated string",
+			sql:  "SELECT 'a",
+			err:  "unterminated quoted string",
+		},
+		{
+			name: "empty",
+			sql:  " ; -- nothing",
+			err:  "no statement found",
+		},
+		{
+			name:    "opted in statement type",
+			sql:     "SHOW TABLES",
+			allowed: []string{"select", "with", "show"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed := tt.allowed
+			if allowed == nil {
+				allowed = DefaultAllowedStatements
+			}
+			err := ValidateStatement(tt.sql, allowed)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
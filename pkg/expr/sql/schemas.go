@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// SchemasTable is the virtual table listing the columns of the inputs of a SQL expression:
+//
+//	SELECT * FROM __schemas
+//
+// It has a row for every field of every input frame, so the data available to a query can be introspected.
+const SchemasTable = "__schemas"
+
+// ReadsSchemas returns true when one of the tables is the SchemasTable
+func ReadsSchemas(tables []string) bool {
+	for _, t := range tables {
+		if strings.EqualFold(t, SchemasTable) {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemasFrame returns the rows of the SchemasTable for the frames: the RefID and the name of the frame,
+// the name of the column, its Grafana field type, the type of the column it is loaded into and the labels of the field.
+func SchemasFrame(frames []*data.Frame) *data.Frame {
+	refIDs, names, columns, fieldTypes, columnTypes, labels := []string{}, []string{}, []string{}, []string{}, []string{}, []string{}
+	nullable := []bool{}
+	for _, f := range frames {
+		if f == nil {
+			continue
+		}
+		for _, field := range f.Fields {
+			refIDs = append(refIDs, f.RefID)
+			names = append(names, f.Name)
+			columns = append(columns, field.Name)
+			fieldTypes = append(fieldTypes, grafanaFieldType(field.Type()))
+			columnTypes = append(columnTypes, ColumnType(field.Type()))
+			nullable = append(nullable, field.Type().Nullable())
+			labels = append(labels, field.Labels.String())
+		}
+	}
+	frame := data.NewFrame(SchemasTable,
+		data.NewField("ref_id", nil, refIDs),
+		data.NewField("frame", nil, names),
+		data.NewField("column", nil, columns),
+		data.NewField("type", nil, fieldTypes),
+		data.NewField("column_type", nil, columnTypes),
+		data.NewField("nullable", nil, nullable),
+		data.NewField("labels", nil, labels),
+	)
+	frame.RefID = SchemasTable
+	return frame
+}
+
+// grafanaFieldType returns the type of a field as it is shown in Grafana, e.g. number for every numeric type
+func grafanaFieldType(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "time"
+	case t.Numeric():
+		return "number"
+	}
+	switch t.NonNullableType() {
+	case data.FieldTypeBool:
+		return "boolean"
+	case data.FieldTypeString:
+		return "string"
+	case data.FieldTypeEnum:
+		return "enum"
+	}
+	return "other"
+}
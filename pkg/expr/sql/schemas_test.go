@@ -0,0 +1,42 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemasFrame(t *testing.T) {
+	a := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{}),
+	)
+	a.RefID = "A"
+	b := data.NewFrame("",
+		data.NewField("up", nil, []bool{}),
+		data.NewField("name", nil, []string{}),
+	)
+	b.RefID = "B"
+
+	frame := SchemasFrame([]*data.Frame{a, nil, b})
+	require.Equal(t, SchemasTable, frame.RefID)
+	require.Equal(t, 4, frame.Rows())
+
+	rows := [][]any{}
+	for i := 0; i < frame.Rows(); i++ {
+		rows = append(rows, frame.RowCopy(i))
+	}
+	require.Equal(t, [][]any{
+		{"A", "cpu", "time", "time", ColumnTypeDatetime, false, ""},
+		{"A", "cpu", "value", "number", ColumnTypeDouble, true, "host=a"},
+		{"B", "", "up", "boolean", ColumnTypeBoolean, false, ""},
+		{"B", "", "name", "string", ColumnTypeText, false, ""},
+	}, rows)
+}
+
+func TestReadsSchemas(t *testing.T) {
+	require.True(t, ReadsSchemas([]string{"A", "__SCHEMAS"}))
+	require.False(t, ReadsSchemas([]string{"A", "schemas"}))
+}
@@ -0,0 +1,224 @@
+package sql
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// spillChunkRows is the number of rows written to disk at once. The rows of a chunk are stored by column,
+// so the values of a column are encoded together.
+const spillChunkRows = 1024
+
+func init() {
+	// the values of the rows are encoded as interfaces, the types that are not registered by gob must be
+	gob.Register(time.Time{})
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+// StorageOptions selects where the rows of the tables are kept.
+type StorageOptions struct {
+	// SpillThreshold is the number of rows of a table kept in memory. The rows after it are written to a temporary
+	// file, so large joins do not hold all the rows in memory. Zero keeps all the rows in memory.
+	SpillThreshold int
+	// SpillDir is the directory of the temporary files, the default directory for temporary files when empty.
+	SpillDir string
+}
+
+// RowStore holds the rows of a table in the order they are appended.
+type RowStore interface {
+	Append(row []any) error
+	// Len returns the number of rows in the store.
+	Len() int
+	// Each calls fn for every row in order until fn returns an error. The row must not be kept after fn returns.
+	Each(fn func(row []any) error) error
+	// Close releases the rows, including the temporary files.
+	Close() error
+}
+
+// NewRowStore returns the store of the rows of a table with the given columns.
+func NewRowStore(columns []Column, opts StorageOptions) RowStore {
+	if opts.SpillThreshold <= 0 {
+		return &memoryRowStore{}
+	}
+	return &spillingRowStore{columns: len(columns), opts: opts}
+}
+
+type memoryRowStore struct {
+	rows [][]any
+}
+
+func (s *memoryRowStore) Append(row []any) error {
+	s.rows = append(s.rows, row)
+	return nil
+}
+
+func (s *memoryRowStore) Len() int {
+	return len(s.rows)
+}
+
+func (s *memoryRowStore) Each(fn func(row []any) error) error {
+	for _, row := range s.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryRowStore) Close() error {
+	s.rows = nil
+	return nil
+}
+
+// spillingRowStore keeps the first rows in memory, and writes the others to a temporary file in chunks.
+type spillingRowStore struct {
+	columns int
+	opts    StorageOptions
+
+	memory  [][]any
+	pending [][]any
+	chunks  int
+	spilled int
+
+	file    *os.File
+	buf     *bufio.Writer
+	encoder *gob.Encoder
+}
+
+func (s *spillingRowStore) Append(row []any) error {
+	if len(row) != s.columns {
+		return fmt.Errorf("row has %d values, expected %d", len(row), s.columns)
+	}
+	if len(s.memory) < s.opts.SpillThreshold {
+		s.memory = append(s.memory, row)
+		return nil
+	}
+	s.pending = append(s.pending, row)
+	if len(s.pending) == spillChunkRows {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *spillingRowStore) Len() int {
+	return len(s.memory) + s.spilled + len(s.pending)
+}
+
+// flush writes the pending rows as a chunk of columns.
+func (s *spillingRowStore) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	if s.file == nil {
+		f, err := os.CreateTemp(s.opts.SpillDir, "grafana-sql-expression-*")
+		if err != nil {
+			return fmt.Errorf("failed to create the file of a large table: %w", err)
+		}
+		s.file = f
+		s.buf = bufio.NewWriter(f)
+		s.encoder = gob.NewEncoder(s.buf)
+	}
+
+	chunk := make([][]any, s.columns)
+	for i := range chunk {
+		chunk[i] = make([]any, len(s.pending))
+		for j, row := range s.pending {
+			chunk[i][j] = row[i]
+		}
+	}
+	if err := s.encoder.Encode(chunk); err != nil {
+		return fmt.Errorf("failed to write rows of a large table: %w", err)
+	}
+	if err := s.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write rows of a large table: %w", err)
+	}
+	s.chunks++
+	s.spilled += len(s.pending)
+	s.pending = s.pending[:0]
+	return nil
+}
+
+func (s *spillingRowStore) Each(fn func(row []any) error) error {
+	for _, row := range s.memory {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if s.file == nil {
+		return nil
+	}
+
+	// the chunks are read with their own handle, so rows can still be appended to the file
+	f, err := os.Open(s.file.Name())
+	if err != nil {
+		return fmt.Errorf("failed to read rows of a large table: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	decoder := gob.NewDecoder(bufio.NewReader(f))
+	row := make([]any, s.columns)
+	for c := 0; c < s.chunks; c++ {
+		var chunk [][]any
+		if err := decoder.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("failed to read rows of a large table: %w", err)
+		}
+		if len(chunk) != s.columns {
+			return fmt.Errorf("failed to read rows of a large table: chunk has %d columns, expected %d", len(chunk), s.columns)
+		}
+		for i := range chunk[0] {
+			for j := range row {
+				row[j] = chunk[j][i]
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *spillingRowStore) Close() error {
+	s.memory, s.pending = nil, nil
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	err := s.file.Close()
+	s.file, s.buf, s.encoder = nil, nil, nil
+	return errors.Join(err, os.Remove(name))
+}
+
+// LoadTable appends the rows of f to a store, in the order of the frame like TableRows.
+// With errs, the values that can not be converted are nil and recorded in errs.
+func LoadTable(f *data.Frame, opts StorageOptions, errs *ConversionErrors) (RowStore, error) {
+	store := NewRowStore(TableColumns(f), opts)
+	for i := 0; i < f.Rows(); i++ {
+		row := make([]any, len(f.Fields))
+		for j, field := range f.Fields {
+			v, err := tableValue(f, field, i, errs)
+			if err != nil {
+				_ = store.Close()
+				return nil, err
+			}
+			row[j] = v
+		}
+		if err := store.Append(row); err != nil {
+			_ = store.Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
@@ -0,0 +1,90 @@
+package sql
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowStore(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rowCount := spillChunkRows*2 + 10
+	times := make([]time.Time, rowCount)
+	values := make([]*float64, rowCount)
+	names := make([]string, rowCount)
+	for i := range times {
+		times[i] = ts.Add(time.Duration(i) * time.Second)
+		if i%3 != 0 {
+			v := float64(i)
+			values[i] = &v
+		}
+		names[i] = "host"
+	}
+	frame := data.NewFrame("A",
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+		data.NewField("host", nil, names),
+	)
+	expected, err := TableRows(frame, nil)
+	require.NoError(t, err)
+
+	readAll := func(t *testing.T, store RowStore) [][]any {
+		rows := [][]any{}
+		require.NoError(t, store.Each(func(row []any) error {
+			rows = append(rows, append([]any{}, row...))
+			return nil
+		}))
+		return rows
+	}
+
+	t.Run("keeps the rows in memory by default", func(t *testing.T) {
+		store, err := LoadTable(frame, StorageOptions{}, nil)
+		require.NoError(t, err)
+		require.IsType(t, &memoryRowStore{}, store)
+		require.Equal(t, rowCount, store.Len())
+		require.Equal(t, expected, readAll(t, store))
+		require.NoError(t, store.Close())
+	})
+
+	t.Run("spills the rows after the threshold to disk", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := LoadTable(frame, StorageOptions{SpillThreshold: 100, SpillDir: dir}, nil)
+		require.NoError(t, err)
+		require.Equal(t, rowCount, store.Len())
+		require.Equal(t, expected, readAll(t, store), "the rows keep their order and their values")
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		// rows can be appended after reading
+		require.NoError(t, store.Append([]any{ts, nil, "last"}))
+		rows := readAll(t, store)
+		require.Len(t, rows, rowCount+1)
+		require.Equal(t, []any{ts, nil, "last"}, rows[rowCount])
+
+		require.NoError(t, store.Close())
+		files, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, files, "the temporary file is removed")
+	})
+
+	t.Run("small tables are not written to disk", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := LoadTable(frame, StorageOptions{SpillThreshold: rowCount, SpillDir: dir}, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, readAll(t, store))
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+		require.NoError(t, store.Close())
+	})
+
+	t.Run("rejects rows with another number of columns", func(t *testing.T) {
+		store := NewRowStore(TableColumns(frame), StorageOptions{SpillThreshold: 1})
+		require.Error(t, store.Append([]any{ts}))
+	})
+}
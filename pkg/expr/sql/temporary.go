@@ -0,0 +1,336 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TemporaryTable is a named result persisted by a SQL expression. The SQL expressions evaluated after it
+// in the same pipeline read it like the result of a query, so expensive joins are only computed once.
+type TemporaryTable struct {
+	// Name is the name of the table
+	Name string
+	// Query computes the rows of the table
+	Query string
+	// Statement is run once the table is created and its result is the result of the expression.
+	// It is empty when the expression returns the rows of the table.
+	Statement string
+}
+
+// ParseTemporaryTable returns the table persisted by rawSQL, or nil when it does not persist one.
+// A table is persisted by the two forms:
+//
+//	CREATE TEMPORARY TABLE name AS SELECT ...
+//	WITH name AS MATERIALIZED (SELECT ...) SELECT ...
+//
+// In the second form, the common table expressions before the materialized one can be used in its query.
+func ParseTemporaryTable(rawSQL string) (*TemporaryTable, error) {
+	tokens, err := scanTokens(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	switch tokens[0].word() {
+	case "CREATE":
+		return parseCreateTemporary(rawSQL, tokens)
+	case "WITH":
+		return parseMaterializedCTE(rawSQL, tokens)
+	}
+	return nil, nil
+}
+
+// Tables returns the tables read by the queries of t, the table itself excluded
+func (t *TemporaryTable) Tables() ([]string, error) {
+	tables, err := TablesList(t.Query)
+	if err != nil {
+		return nil, err
+	}
+	if t.Statement != "" {
+		more, err := TablesList(t.Statement)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range more {
+			if !existsInList(table, tables) {
+				tables = append(tables, table)
+			}
+		}
+	}
+
+	out := []string{}
+	for _, table := range tables {
+		if table != t.Name {
+			out = append(out, table)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func parseCreateTemporary(rawSQL string, tokens tokenList) (*TemporaryTable, error) {
+	i := 1
+	if tokens.word(i) != "TEMPORARY" && tokens.word(i) != "TEMP" {
+		return nil, nil
+	}
+	i++
+	if tokens.word(i) != "TABLE" {
+		return nil, nil
+	}
+	i++
+	if tokens.word(i) == "IF" && tokens.word(i+1) == "NOT" && tokens.word(i+2) == "EXISTS" {
+		i += 3
+	}
+	name := tokens.name(i)
+	if name == "" {
+		return nil, errors.New("missing name of the temporary table")
+	}
+	i++
+	if tokens.word(i) == "AS" {
+		i++
+	}
+	start := i
+	// a parenthesized query, unlike a list of column definitions
+	for i < len(tokens) && tokens[i].text == "(" {
+		i++
+	}
+	if tokens.word(i) != "SELECT" && tokens.word(i) != "WITH" {
+		return nil, fmt.Errorf("the temporary table %s must be created from a query", name)
+	}
+	return &TemporaryTable{
+		Name:  name,
+		Query: trimStatement(rawSQL[tokens[start].start:]),
+	}, nil
+}
+
+// cte is a common table expression of a WITH statement
+type cte struct {
+	name         string
+	materialized bool
+	// start and end of the whole expression, and of its query inside the parentheses
+	start, end           int
+	queryStart, queryEnd int
+}
+
+func parseMaterializedCTE(rawSQL string, tokens tokenList) (*TemporaryTable, error) {
+	i := 1
+	if tokens.word(i) == "RECURSIVE" {
+		i++
+	}
+	if i >= len(tokens) {
+		return nil, nil
+	}
+	prefix := rawSQL[tokens[0].start:tokens[i].start]
+
+	ctes := []cte{}
+	for {
+		c := cte{name: tokens.name(i)}
+		if c.name == "" {
+			return nil, nil // not a statement we understand, the engine reports the error
+		}
+		c.start = tokens[i].start
+		i++
+		if tokens.text(i) == "(" {
+			// column list
+			end := tokens.closing(i)
+			if end < 0 {
+				return nil, nil
+			}
+			i = end + 1
+		}
+		if tokens.word(i) != "AS" {
+			return nil, nil
+		}
+		i++
+		switch {
+		case tokens.word(i) == "MATERIALIZED":
+			c.materialized = true
+			i++
+		case tokens.word(i) == "NOT" && tokens.word(i+1) == "MATERIALIZED":
+			i += 2
+		}
+		if tokens.text(i) != "(" {
+			return nil, nil
+		}
+		end := tokens.closing(i)
+		if end < 0 {
+			return nil, nil
+		}
+		c.queryStart, c.queryEnd, c.end = tokens[i].end, tokens[end].start, tokens[end].end
+		ctes = append(ctes, c)
+		i = end + 1
+		if tokens.text(i) != "," {
+			break
+		}
+		i++
+	}
+	if i >= len(tokens) {
+		return nil, nil
+	}
+	statement := trimStatement(rawSQL[tokens[i].start:])
+
+	materialized := -1
+	for j, c := range ctes {
+		if !c.materialized {
+			continue
+		}
+		if materialized >= 0 {
+			return nil, errors.New("a SQL expression can only persist one materialized common table expression")
+		}
+		materialized = j
+	}
+	if materialized < 0 {
+		return nil, nil
+	}
+
+	m := ctes[materialized]
+	table := &TemporaryTable{
+		Name:      m.name,
+		Query:     strings.TrimSpace(rawSQL[m.queryStart:m.queryEnd]),
+		Statement: statement,
+	}
+	if materialized > 0 {
+		// the previous expressions can be used in the query of the table
+		previous := make([]string, 0, materialized)
+		for _, c := range ctes[:materialized] {
+			previous = append(previous, rawSQL[c.start:c.end])
+		}
+		table.Query = prefix + strings.Join(previous, ", ") + " " + table.Query
+	}
+	others := []string{}
+	for j, c := range ctes {
+		if j != materialized {
+			others = append(others, rawSQL[c.start:c.end])
+		}
+	}
+	if len(others) > 0 {
+		table.Statement = prefix + strings.Join(others, ", ") + " " + statement
+	}
+	return table, nil
+}
+
+// trimStatement removes the spaces and the final semicolon of a statement
+func trimStatement(stmt string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+}
+
+// token is a word, a quoted identifier or a punctuation character of a statement,
+// depth is the number of parentheses around it
+type token struct {
+	text       string
+	quoted     bool
+	start, end int
+	depth      int
+}
+
+func (t token) word() string {
+	if t.quoted {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+type tokenList []token
+
+func (ts tokenList) word(i int) string {
+	if i < 0 || i >= len(ts) {
+		return ""
+	}
+	return ts[i].word()
+}
+
+func (ts tokenList) text(i int) string {
+	if i < 0 || i >= len(ts) {
+		return ""
+	}
+	return ts[i].text
+}
+
+// name returns the identifier at i, without its quotes
+func (ts tokenList) name(i int) string {
+	if i < 0 || i >= len(ts) {
+		return ""
+	}
+	t := ts[i]
+	if t.quoted {
+		return strings.ReplaceAll(t.text[1:len(t.text)-1], "``", "`")
+	}
+	if r := []rune(t.text)[0]; !unicode.IsLetter(r) && r != '_' {
+		return ""
+	}
+	return t.text
+}
+
+// closing returns the index of the parenthesis closing the one at i, or -1
+func (ts tokenList) closing(i int) int {
+	for j := i + 1; j < len(ts); j++ {
+		if ts[j].text == ")" && ts[j].depth == ts[i].depth {
+			return j
+		}
+	}
+	return -1
+}
+
+// scanTokens splits a statement into tokens, string literals and comments are skipped
+func scanTokens(rawSQL string) (tokenList, error) {
+	out := tokenList{}
+	depth := 0
+	for i := 0; i < len(rawSQL); i++ {
+		c := rawSQL[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := closingQuote(rawSQL, i)
+			if end < 0 {
+				return nil, errors.New("unterminated quoted string in SQL expression")
+			}
+			i = end
+		case c == '`':
+			end := closingQuote(rawSQL, i)
+			if end < 0 {
+				return nil, errors.New("unterminated quoted identifier in SQL expression")
+			}
+			out = append(out, token{text: rawSQL[i : end+1], quoted: true, start: i, end: end + 1, depth: depth})
+			i = end
+		case c == '#', isDashComment(rawSQL, i):
+			end := strings.IndexByte(rawSQL[i:], '\n')
+			if end < 0 {
+				i = len(rawSQL)
+			} else {
+				i += end
+			}
+		case c == '/' && strings.HasPrefix(rawSQL[i:], "/*"):
+			end := strings.Index(rawSQL[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment in SQL expression")
+			}
+			i += end + 3
+		case isWordByte(c):
+			end := i + 1
+			for end < len(rawSQL) && isWordByte(rawSQL[end]) {
+				end++
+			}
+			out = append(out, token{text: rawSQL[i:end], start: i, end: end, depth: depth})
+			i = end - 1
+		case c == '(':
+			out = append(out, token{text: "(", start: i, end: i + 1, depth: depth})
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+			out = append(out, token{text: ")", start: i, end: i + 1, depth: depth})
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			out = append(out, token{text: string(c), start: i, end: i + 1, depth: depth})
+		}
+	}
+	return out, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemporaryTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected *TemporaryTable
+		err      string
+	}{
+		{
+			name: "select",
+			sql:  "SELECT * FROM A",
+		},
+		{
+			name: "common table expressions that are not materialized",
+			sql:  "WITH j AS (SELECT * FROM A JOIN B ON A.id = B.id) SELECT * FROM j",
+		},
+		{
+			name:     "create temporary table",
+			sql:      "CREATE TEMPORARY TABLE j AS SELECT * FROM A JOIN B ON A.id = B.id;",
+			expected: &TemporaryTable{Name: "j", Query: "SELECT * FROM A JOIN B ON A.id = B.id"},
+		},
+		{
+			name:     "create temporary table without AS",
+			sql:      "create temp table if not exists `my table` (select 1)",
+			expected: &TemporaryTable{Name: "my table", Query: "(select 1)"},
+		},
+		{
+			name: "create temporary table without query",
+			sql:  "CREATE TEMPORARY TABLE j (id INT)",
+			err:  "must be created from a query",
+		},
+		{
+			name: "create table",
+			sql:  "CREATE TABLE j AS SELECT 1",
+		},
+		{
+			name: "materialized common table expression",
+			sql:  "WITH j AS MATERIALIZED (SELECT * FROM A JOIN B ON A.id = B.id) SELECT count(*) FROM j",
+			expected: &TemporaryTable{
+				Name:      "j",
+				Query:     "SELECT * FROM A JOIN B ON A.id = B.id",
+				Statement: "SELECT count(*) FROM j",
+			},
+		},
+		{
+			name: "materialized common table expression after other ones",
+			sql:  "WITH a AS (SELECT ')' AS x FROM A), j(x) AS MATERIALIZED (SELECT x FROM a), b AS NOT MATERIALIZED (SELECT 1) SELECT * FROM j, b",
+			expected: &TemporaryTable{
+				Name:      "j",
+				Query:     "WITH a AS (SELECT ')' AS x FROM A) SELECT x FROM a",
+				Statement: "WITH a AS (SELECT ')' AS x FROM A), b AS NOT MATERIALIZED (SELECT 1) SELECT * FROM j, b",
+			},
+		},
+		{
+			name: "two materialized common table expressions",
+			sql:  "WITH a AS MATERIALIZED (SELECT 1), b AS MATERIALIZED (SELECT 2) SELECT * FROM a, b",
+			err:  "only persist one",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := ParseTemporaryTable(tt.sql)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, table)
+		})
+	}
+}
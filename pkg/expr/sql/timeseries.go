@@ -0,0 +1,259 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// LongTimeColumn is the time column of the tables wide time series are loaded into
+	LongTimeColumn = "time"
+	// LongValueColumn is the value column of the series without a name
+	LongValueColumn = "value"
+)
+
+// LongTables converts the wide time series of the frames into long tables, so a query can group the series by
+// their labels: the frames of a RefID that are wide time series, one time field and numeric fields with labels,
+// are loaded into a single table with a time column, a column per value name and a column per label. The other
+// frames are returned unchanged, in the order of the frames.
+func LongTables(frames []*data.Frame) ([]*data.Frame, error) {
+	wide := map[string][]*data.Frame{}
+	for _, f := range frames {
+		if isWideTimeSeries(f) {
+			wide[f.RefID] = append(wide[f.RefID], f)
+		}
+	}
+	out := make([]*data.Frame, 0, len(frames))
+	for _, f := range frames {
+		if !isWideTimeSeries(f) {
+			out = append(out, f)
+			continue
+		}
+		series, ok := wide[f.RefID]
+		if !ok {
+			// loaded with the first frame of the RefID
+			continue
+		}
+		delete(wide, f.RefID)
+		long, err := wideToLong(f.RefID, series)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, long)
+	}
+	return out, nil
+}
+
+// WideResult converts a long time series result, a time column, numeric columns and string columns, back into a wide
+// time series with a numeric field per combination of the string columns. Other results are returned unchanged.
+func WideResult(f *data.Frame) *data.Frame {
+	if f == nil || f.Rows() == 0 || f.TimeSeriesSchema().Type != data.TimeSeriesTypeLong {
+		return f
+	}
+	wide, err := data.LongToWide(sortedByTime(f), nil)
+	if err != nil {
+		// the result is still valid as a table
+		return f
+	}
+	wide.Name, wide.RefID = f.Name, f.RefID
+	return wide
+}
+
+// TimeSeriesResult marks a result shaped as a time series, e.g. grouped by a time_bucket, so the expressions and the
+// panels reading it accept it as a time series: a single time column without NULL values and numeric columns make a
+// wide time series, and with string columns as well a long time series. The rows are sorted by time. Other results
+// are returned unchanged.
+func TimeSeriesResult(f *data.Frame) *data.Frame {
+	if f == nil || f.Rows() == 0 {
+		return f
+	}
+	var timeField *data.Field
+	numeric, texts := 0, 0
+	for _, field := range f.Fields {
+		switch {
+		case field.Type().Time():
+			if timeField != nil {
+				return f
+			}
+			timeField = field
+		case field.Type().Numeric():
+			numeric++
+		case field.Type().NonNullableType() == data.FieldTypeString:
+			texts++
+		default:
+			return f
+		}
+	}
+	if timeField == nil || numeric == 0 {
+		return f
+	}
+	for i := 0; i < timeField.Len(); i++ {
+		if _, ok := timeField.ConcreteAt(i); !ok {
+			return f
+		}
+	}
+
+	meta := data.FrameMeta{}
+	if f.Meta != nil {
+		meta = *f.Meta
+	}
+	meta.Type = data.FrameTypeTimeSeriesWide
+	if texts > 0 {
+		meta.Type = data.FrameTypeTimeSeriesLong
+	}
+	meta.TypeVersion = data.FrameTypeVersion{0, 1}
+	meta.PreferredVisualization = data.VisTypeGraph
+
+	sorted := sortedByTime(f)
+	sorted.Meta = &meta
+	return sorted
+}
+
+// isWideTimeSeries returns true for the frames with a single time field and numeric fields, and at least one row
+func isWideTimeSeries(f *data.Frame) bool {
+	if f == nil || f.Rows() == 0 || f.TimeSeriesSchema().Type != data.TimeSeriesTypeWide {
+		return false
+	}
+	for _, field := range f.Fields {
+		if !field.Type().Time() && !field.Type().Numeric() {
+			return false
+		}
+	}
+	return true
+}
+
+type longRow struct {
+	time   time.Time
+	labels data.Labels
+	key    string
+	values map[string]*float64
+}
+
+// wideToLong loads the wide frames of a RefID into a long frame. The fields with the same labels at the same time
+// are in the same row, the rows are sorted by time and labels.
+func wideToLong(refID string, frames []*data.Frame) (*data.Frame, error) {
+	var valueNames, labelNames []string
+	seenValues, seenLabels := map[string]bool{}, map[string]bool{}
+	rows := map[string]*longRow{}
+	for _, f := range frames {
+		timeIndices := f.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+		timeField := f.Fields[timeIndices[0]]
+		for _, field := range f.Fields {
+			if field == timeField {
+				continue
+			}
+			name := field.Name
+			if name == "" {
+				name = LongValueColumn
+			}
+			if !seenValues[name] {
+				seenValues[name] = true
+				valueNames = append(valueNames, name)
+			}
+			for k := range field.Labels {
+				if !seenLabels[k] {
+					seenLabels[k] = true
+					labelNames = append(labelNames, k)
+				}
+			}
+
+			for i := 0; i < field.Len(); i++ {
+				t, ok := timeField.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				v, err := field.NullableFloatAt(i)
+				if err != nil {
+					return nil, err
+				}
+				ts := t.(time.Time)
+				key := fmt.Sprintf("%d %s", ts.UnixNano(), field.Labels.String())
+				row, ok := rows[key]
+				if !ok {
+					row = &longRow{time: ts, labels: field.Labels, key: field.Labels.String(), values: map[string]*float64{}}
+					rows[key] = row
+				}
+				row.values[name] = v
+			}
+		}
+	}
+	sort.Strings(labelNames)
+	for _, l := range labelNames {
+		if l == LongTimeColumn || seenValues[l] {
+			return nil, fmt.Errorf("the label %s of %s has the name of a column of its long table", l, refID)
+		}
+	}
+
+	sorted := make([]*longRow, 0, len(rows))
+	for _, row := range rows {
+		sorted = append(sorted, row)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].time.Equal(sorted[j].time) {
+			return sorted[i].time.Before(sorted[j].time)
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	times := make([]time.Time, len(sorted))
+	values := make([][]*float64, len(valueNames))
+	labels := make([][]*string, len(labelNames))
+	for i := range values {
+		values[i] = make([]*float64, len(sorted))
+	}
+	for i := range labels {
+		labels[i] = make([]*string, len(sorted))
+	}
+	for i, row := range sorted {
+		times[i] = row.time
+		for j, name := range valueNames {
+			values[j][i] = row.values[name]
+		}
+		for j, name := range labelNames {
+			if v, ok := row.labels[name]; ok {
+				labels[j][i] = &v
+			}
+		}
+	}
+
+	long := data.NewFrame(frames[0].Name, data.NewField(LongTimeColumn, nil, times))
+	for i, name := range valueNames {
+		long.Fields = append(long.Fields, data.NewField(name, nil, values[i]))
+	}
+	for i, name := range labelNames {
+		long.Fields = append(long.Fields, data.NewField(name, nil, labels[i]))
+	}
+	long.RefID = refID
+	return long, nil
+}
+
+// sortedByTime returns the frame with its rows sorted by time, the rows at the same time keep their order
+func sortedByTime(f *data.Frame) *data.Frame {
+	timeIndices := f.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	timeField := f.Fields[timeIndices[0]]
+	order := make([]int, f.Rows())
+	for i := range order {
+		order[i] = i
+	}
+	at := func(i int) time.Time {
+		t, _ := timeField.ConcreteAt(i)
+		ts, _ := t.(time.Time)
+		return ts
+	}
+	sort.SliceStable(order, func(i, j int) bool { return at(order[i]).Before(at(order[j])) })
+
+	sorted := data.NewFrame(f.Name)
+	sorted.RefID, sorted.Meta = f.RefID, f.Meta
+	for _, field := range f.Fields {
+		copied := data.NewFieldFromFieldType(field.Type(), len(order))
+		copied.Name, copied.Labels, copied.Config = field.Name, field.Labels, field.Config
+		for i, idx := range order {
+			copied.Set(i, field.CopyAt(idx))
+		}
+		sorted.Fields = append(sorted.Fields, copied)
+	}
+	return sorted
+}
@@ -0,0 +1,161 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongTables(t *testing.T) {
+	t0, t1 := time.Unix(0, 0).UTC(), time.Unix(60, 0).UTC()
+	a := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{t1, t0}),
+		data.NewField("cpu", data.Labels{"host": "a"}, []float64{2, 1}),
+	)
+	a.RefID = "A"
+	b := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{t0}),
+		data.NewField("cpu", data.Labels{"host": "b", "dc": "eu"}, []float64{3}),
+	)
+	b.RefID = "A"
+	table := data.NewFrame("",
+		data.NewField("name", nil, []string{"x"}),
+	)
+	table.RefID = "B"
+
+	frames, err := LongTables([]*data.Frame{a, table, b})
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	require.Same(t, table, frames[1])
+
+	long := frames[0]
+	require.Equal(t, "A", long.RefID)
+	require.Equal(t, data.TimeSeriesTypeLong, long.TimeSeriesSchema().Type)
+	names := []string{}
+	for _, f := range long.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"time", "cpu", "dc", "host"}, names)
+
+	rows := [][]any{}
+	for i := 0; i < long.Rows(); i++ {
+		row := []any{}
+		for _, v := range long.RowCopy(i) {
+			switch v := v.(type) {
+			case *float64:
+				row = append(row, *v)
+			case *string:
+				if v == nil {
+					row = append(row, nil)
+				} else {
+					row = append(row, *v)
+				}
+			default:
+				row = append(row, v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	require.Equal(t, [][]any{
+		{t0, 3.0, "eu", "b"},
+		{t0, 1.0, nil, "a"},
+		{t1, 2.0, nil, "a"},
+	}, rows)
+
+	t.Run("labels with the name of a column", func(t *testing.T) {
+		f := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{t0}),
+			data.NewField("", data.Labels{"value": "x"}, []float64{1}),
+		)
+		f.RefID = "C"
+		_, err := LongTables([]*data.Frame{f})
+		require.EqualError(t, err, "the label value of C has the name of a column of its long table")
+	})
+}
+
+func TestWideResult(t *testing.T) {
+	t0, t1 := time.Unix(0, 0).UTC(), time.Unix(60, 0).UTC()
+	long := data.NewFrame("result",
+		data.NewField("time", nil, []time.Time{t1, t0, t0}),
+		data.NewField("avg", nil, []float64{3, 1, 2}),
+		data.NewField("host", nil, []string{"a", "a", "b"}),
+	)
+	long.RefID = "S"
+
+	wide := WideResult(long)
+	require.Equal(t, "S", wide.RefID)
+	require.Equal(t, "result", wide.Name)
+	require.Equal(t, data.TimeSeriesTypeWide, wide.TimeSeriesSchema().Type)
+	require.Len(t, wide.Fields, 3)
+	require.Equal(t, data.Labels{"host": "a"}, wide.Fields[1].Labels)
+	require.Equal(t, data.Labels{"host": "b"}, wide.Fields[2].Labels)
+	require.Equal(t, 2, wide.Rows())
+
+	t.Run("tables are returned unchanged", func(t *testing.T) {
+		table := data.NewFrame("", data.NewField("host", nil, []string{"a"}))
+		require.Same(t, table, WideResult(table))
+	})
+}
+
+func TestTimeSeriesResult(t *testing.T) {
+	t0, t1 := time.Unix(0, 0).UTC(), time.Unix(60, 0).UTC()
+
+	t.Run("time and numeric columns are a wide time series", func(t *testing.T) {
+		f := data.NewFrame("result",
+			data.NewField("bucket", nil, []*time.Time{&t1, &t0}),
+			data.NewField("avg", nil, []float64{2, 1}),
+		)
+		f.RefID = "S"
+		f.Meta = &data.FrameMeta{ExecutedQueryString: "SELECT 1", PreferredVisualization: data.VisTypeTable}
+
+		ts := TimeSeriesResult(f)
+		require.Equal(t, "S", ts.RefID)
+		require.Equal(t, data.FrameTypeTimeSeriesWide, ts.Meta.Type)
+		require.Equal(t, data.VisType(data.VisTypeGraph), ts.Meta.PreferredVisualization)
+		require.Equal(t, "SELECT 1", ts.Meta.ExecutedQueryString)
+		require.Equal(t, data.VisType(data.VisTypeTable), f.Meta.PreferredVisualization, "the result is not changed")
+		require.Equal(t, &t0, ts.Fields[0].At(0))
+		require.Equal(t, 1.0, ts.Fields[1].At(0))
+	})
+
+	t.Run("string columns make a long time series", func(t *testing.T) {
+		f := data.NewFrame("result",
+			data.NewField("bucket", nil, []time.Time{t0, t0}),
+			data.NewField("avg", nil, []float64{1, 2}),
+			data.NewField("host", nil, []string{"a", "b"}),
+		)
+		require.Equal(t, data.FrameTypeTimeSeriesLong, TimeSeriesResult(f).Meta.Type)
+	})
+
+	t.Run("other results are returned unchanged", func(t *testing.T) {
+		for name, f := range map[string]*data.Frame{
+			"no time": data.NewFrame("", data.NewField("avg", nil, []float64{1})),
+			"no numeric": data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t0}),
+				data.NewField("host", nil, []string{"a"}),
+			),
+			"two times": data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t0}),
+				data.NewField("end", nil, []time.Time{t1}),
+				data.NewField("avg", nil, []float64{1}),
+			),
+			"null time": data.NewFrame("",
+				data.NewField("time", nil, []*time.Time{nil}),
+				data.NewField("avg", nil, []float64{1}),
+			),
+			"other types": data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t0}),
+				data.NewField("avg", nil, []float64{1}),
+				data.NewField("up", nil, []bool{true}),
+			),
+			"no rows": data.NewFrame("",
+				data.NewField("time", nil, []time.Time{}),
+				data.NewField("avg", nil, []float64{}),
+			),
+		} {
+			require.Same(t, f, TimeSeriesResult(f), name)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Column types of the tables the input frames are loaded into.
+const (
+	ColumnTypeBoolean  = "BOOLEAN"
+	ColumnTypeInt      = "BIGINT"
+	ColumnTypeUint     = "BIGINT UNSIGNED"
+	ColumnTypeDouble   = "DOUBLE"
+	ColumnTypeText     = "TEXT"
+	ColumnTypeDatetime = "DATETIME(6)"
+	// ColumnTypeJSON holds JSON fields and nested values.
+	ColumnTypeJSON = "JSON"
+)
+
+// ColumnType returns the type of the column a field of the given type is loaded into.
+// Enums have no column type and are stored as text.
+func ColumnType(t data.FieldType) string {
+	switch t.NonNullableType() {
+	case data.FieldTypeBool:
+		return ColumnTypeBoolean
+	case data.FieldTypeInt8, data.FieldTypeInt16, data.FieldTypeInt32, data.FieldTypeInt64:
+		return ColumnTypeInt
+	case data.FieldTypeUint8, data.FieldTypeUint16, data.FieldTypeUint32, data.FieldTypeUint64:
+		return ColumnTypeUint
+	case data.FieldTypeFloat32, data.FieldTypeFloat64:
+		return ColumnTypeDouble
+	case data.FieldTypeTime:
+		return ColumnTypeDatetime
+	case data.FieldTypeJSON:
+		return ColumnTypeJSON
+	default:
+		return ColumnTypeText
+	}
+}
+
+// ColumnValue returns the value of a field at idx as it is stored in its column.
+// JSON values are decoded into the maps, slices and scalars of the document.
+func ColumnValue(f *data.Field, idx int) (any, error) {
+	v, ok := f.ConcreteAt(idx)
+	if !ok {
+		return nil, nil
+	}
+	switch val := v.(type) {
+	case json.RawMessage:
+		var doc any
+		if err := json.Unmarshal(val, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON in field %s at row %d: %w", f.Name, idx, err)
+		}
+		return doc, nil
+	case data.EnumItemIndex:
+		return enumText(f, val), nil
+	default:
+		return v, nil
+	}
+}
+
+func enumText(f *data.Field, idx data.EnumItemIndex) string {
+	if f.Config != nil && f.Config.TypeConfig != nil && f.Config.TypeConfig.Enum != nil {
+		if text := f.Config.TypeConfig.Enum.Text; int(idx) < len(text) {
+			return text[idx]
+		}
+	}
+	return fmt.Sprintf("%d", idx)
+}
+
+// ResultField builds a field from the values of a result column. Nested values such as the
+// objects and arrays returned by JSON_EXTRACT, and multi-value string arrays, are returned
+// as JSON fields instead of being rejected. The field is nullable when a value is nil.
+func ResultField(name string, values []any) (*data.Field, error) {
+	column := resultColumn{name: name, capacity: len(values)}
+	for i, v := range values {
+		if err := column.append(v, i); err != nil {
+			return nil, err
+		}
+	}
+	return column.field(), nil
+}
+
+var errMixedTypes = errors.New("values of different types")
+
+func toJSON(v any) (json.RawMessage, error) {
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnType(t *testing.T) {
+	require.Equal(t, ColumnTypeJSON, ColumnType(data.FieldTypeJSON))
+	require.Equal(t, ColumnTypeJSON, ColumnType(data.FieldTypeNullableJSON))
+	require.Equal(t, ColumnTypeDouble, ColumnType(data.FieldTypeNullableFloat64))
+	require.Equal(t, ColumnTypeText, ColumnType(data.FieldTypeEnum))
+}
+
+func TestColumnValue(t *testing.T) {
+	doc := data.NewField("doc", nil, []*json.RawMessage{rawJSON(`{"name":"a","tags":["x","y"]}`), nil})
+	v, err := ColumnValue(doc, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "a", "tags": []any{"x", "y"}}, v)
+
+	v, err = ColumnValue(doc, 1)
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	_, err = ColumnValue(data.NewField("doc", nil, []json.RawMessage{json.RawMessage(`{`)}), 0)
+	require.Error(t, err)
+
+	level := data.NewField("level", nil, []data.EnumItemIndex{1, 2})
+	level.SetConfig(&data.FieldConfig{TypeConfig: &data.FieldTypeConfig{Enum: &data.EnumFieldConfig{Text: []string{"low", "high"}}}})
+	v, err = ColumnValue(level, 0)
+	require.NoError(t, err)
+	require.Equal(t, "high", v)
+	v, err = ColumnValue(level, 1)
+	require.NoError(t, err)
+	require.Equal(t, "2", v)
+}
+
+func TestResultField(t *testing.T) {
+	t.Run("converts nested values to JSON", func(t *testing.T) {
+		f, err := ResultField("tags", []any{[]string{"a", "b"}, map[string]any{"x": 1}, nil})
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeNullableJSON, f.Type())
+		require.Equal(t, json.RawMessage(`["a","b"]`), *f.At(0).(*json.RawMessage))
+		require.Equal(t, json.RawMessage(`{"x":1}`), *f.At(1).(*json.RawMessage))
+		require.Nil(t, f.At(2))
+	})
+
+	t.Run("keeps scalar types", func(t *testing.T) {
+		f, err := ResultField("value", []any{1.5, 2.0})
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeFloat64, f.Type())
+
+		f, err = ResultField("empty", []any{})
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeString, f.Type())
+	})
+
+	t.Run("rejects mixed types", func(t *testing.T) {
+		_, err := ResultField("value", []any{1.5, "a"})
+		require.Error(t, err)
+	})
+}
+
+func rawJSON(s string) *json.RawMessage {
+	raw := json.RawMessage(s)
+	return &raw
+}
@@ -4,9 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
@@ -19,15 +27,76 @@ type SQLCommand struct {
 	query       string
 	varsToQuery []string
 	refID       string
+	timeRange   TimeRange
+	interval    time.Duration
+	// table is the result persisted for the SQL expressions evaluated after this one, if any
+	table *sql.TemporaryTable
+	// explain returns the query plan as an extra frame
+	explain bool
+	// longFormat loads the wide time series inputs as long tables and returns long time series results as wide
+	longFormat bool
+	// schemas is true when the query reads the columns of the inputs from sql.SchemasTable
+	schemas bool
+	// parameters are the values of the variables referenced by the query, bound to its statements by the engine
+	parameters sql.Parameters
+	// tolerant returns the values that can not be converted as NULL rather than failing, and reports them as notices
+	tolerant bool
+	// downsampling reduces the large time series inputs before they are loaded, if set
+	downsampling *sql.Downsampling
+
+	allowedStatements []string
+	limits            sql.Limits
+	storage           sql.StorageOptions
+	orgID             int64
+	metrics           *metrics
+	// timeout is the maximum duration of the queries of the command, zero is unlimited
+	timeout time.Duration
 }
 
 // NewSQLCommand creates a new SQLCommand.
-func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+// Macros such as $__timeFilter(column) and $__interval are expanded against timeRange and interval when the command is executed.
+// Variables such as $region are bound to the values set with Parameters.
+func NewSQLCommand(refID, rawSQL string, timeRange TimeRange, interval time.Duration) (*SQLCommand, error) {
 	if rawSQL == "" {
 		return nil, errutil.BadRequest("sql-missing-query",
 			errutil.WithPublicMessage("missing SQL query"))
 	}
-	tables, err := sql.TablesList(rawSQL)
+	// the tables do not depend on the time range, so expand the macros with the current time to parse the query
+	expanded, err := interpolateSQL(rawSQL, timeRange, interval, time.Now())
+	var unsupported *sql.UnsupportedSyntaxError
+	if errors.As(err, &unsupported) {
+		logger.Warn("unsupported syntax in sql query", "sql", rawSQL, "error", err)
+		return nil, errutil.BadRequest("sql-unsupported-syntax",
+			errutil.WithPublicMessage(err.Error()),
+		)
+	}
+	if err != nil {
+		logger.Warn("invalid macro in sql query", "sql", rawSQL, "error", err)
+		return nil, errutil.BadRequest("sql-invalid-macro",
+			errutil.WithPublicMessage(fmt.Sprintf("error expanding SQL macros: %s", err)),
+		)
+	}
+	// the values of the variables are bound when the command is executed
+	expanded, err = sql.ParameterPlaceholders(expanded)
+	if err != nil {
+		logger.Warn("invalid variable in sql query", "sql", rawSQL, "error", err)
+		return nil, errutil.BadRequest("sql-invalid-variable",
+			errutil.WithPublicMessage(fmt.Sprintf("error reading SQL variables: %s", err)),
+		)
+	}
+	table, err := sql.ParseTemporaryTable(expanded)
+	if err != nil {
+		logger.Warn("invalid temporary table in sql query", "sql", rawSQL, "error", err)
+		return nil, errutil.BadRequest("sql-invalid-temporary-table",
+			errutil.WithPublicMessage(fmt.Sprintf("error reading SQL command: %s", err)),
+		)
+	}
+	var tables []string
+	if table != nil {
+		tables, err = table.Tables()
+	} else {
+		tables, err = sql.TablesList(expanded)
+	}
 	if err != nil {
 		logger.Warn("invalid sql query", "sql", rawSQL, "error", err)
 		return nil, errutil.BadRequest("sql-invalid-sql",
@@ -40,10 +109,21 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 	if tables != nil {
 		logger.Debug("REF tables", "tables", tables, "sql", rawSQL)
 	}
+	schemas := sql.ReadsSchemas(tables)
+	if schemas {
+		// the virtual table is not an input, it lists all of them
+		tables = slices.DeleteFunc(tables, func(t string) bool { return sql.ReadsSchemas([]string{t}) })
+	}
 	return &SQLCommand{
 		query:       rawSQL,
 		varsToQuery: tables,
 		refID:       refID,
+		timeRange:   timeRange,
+		interval:    interval,
+		table:       table,
+		schemas:     schemas,
+
+		allowedStatements: sql.DefaultAllowedStatements,
 	}, nil
 }
 
@@ -65,7 +145,259 @@ func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
 		return nil, fmt.Errorf("expected sql expression to be type string, but got type %T", expressionRaw)
 	}
 
-	return NewSQLCommand(rn.RefID, expression)
+	intervalMS := defaultIntervalMS
+	if rawIntervalMS, ok := rn.Query["intervalMs"]; ok {
+		floatIntervalMS, ok := rawIntervalMS.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected intervalMs to be an float64, got type %T for refId %v", rawIntervalMS, rn.RefID)
+		}
+		intervalMS = int64(floatIntervalMS)
+	}
+
+	cmd, err := NewSQLCommand(rn.RefID, expression, rn.TimeRange, time.Duration(intervalMS)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	if rawExplain, ok := rn.Query["explain"]; ok {
+		explain, ok := rawExplain.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected explain to be a bool, got type %T for refId %v", rawExplain, rn.RefID)
+		}
+		cmd.Explain(explain)
+	}
+	if rawLongFormat, ok := rn.Query["longFormat"]; ok {
+		longFormat, ok := rawLongFormat.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected longFormat to be a bool, got type %T for refId %v", rawLongFormat, rn.RefID)
+		}
+		cmd.LongFormat(longFormat)
+	}
+	if rawParameters, ok := rn.Query["parameters"]; ok {
+		parameters, err := readSQLParameters(rawParameters)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameters for refId %v: %w", rn.RefID, err)
+		}
+		cmd.Parameters(parameters)
+	}
+	if rawTolerant, ok := rn.Query["tolerateConversionErrors"]; ok {
+		tolerant, ok := rawTolerant.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected tolerateConversionErrors to be a bool, got type %T for refId %v", rawTolerant, rn.RefID)
+		}
+		cmd.TolerateConversionErrors(tolerant)
+	}
+	if rawDownsample, ok := rn.Query["downsample"]; ok {
+		downsampling, err := readSQLDownsampling(rawDownsample)
+		if err != nil {
+			return nil, fmt.Errorf("invalid downsample for refId %v: %w", rn.RefID, err)
+		}
+		cmd.Downsample(downsampling)
+	}
+	return cmd, nil
+}
+
+// readSQLDownsampling reads the downsampling of the inputs, an object with the method, lttb by default, and the
+// maximum rows of an input, e.g. {"method": "mean", "maxRows": 10000}
+func readSQLDownsampling(raw any) (*sql.Downsampling, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got type %T", raw)
+	}
+	downsampling := &sql.Downsampling{Method: sql.DownsampleLTTB}
+	if rawMethod, ok := obj["method"]; ok {
+		method, ok := rawMethod.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected method to be a string, got type %T", rawMethod)
+		}
+		downsampling.Method = method
+	}
+	rawMaxRows, ok := obj["maxRows"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected maxRows to be a number, got type %T", obj["maxRows"])
+	}
+	downsampling.MaxRows = int(rawMaxRows)
+	if err := downsampling.Validate(); err != nil {
+		return nil, err
+	}
+	return downsampling, nil
+}
+
+// readSQLParameters reads the values of the variables of a query, an object whose values are a value or a list of
+// values, e.g. {"region": "eu", "host": ["a", "b"]}. Numbers and booleans are bound as their text.
+func readSQLParameters(raw any) (sql.Parameters, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got type %T", raw)
+	}
+	parameters := make(sql.Parameters, len(obj))
+	for name, v := range obj {
+		list, ok := v.([]any)
+		if !ok {
+			list = []any{v}
+		}
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			switch t := item.(type) {
+			case string:
+				values = append(values, t)
+			case float64:
+				values = append(values, strconv.FormatFloat(t, 'f', -1, 64))
+			case bool:
+				values = append(values, strconv.FormatBool(t))
+			default:
+				return nil, fmt.Errorf("expected the values of %s to be strings, numbers or booleans, got type %T", name, item)
+			}
+		}
+		parameters[name] = values
+	}
+	return parameters, nil
+}
+
+// interpolateSQL expands the macros, rewrites the PostgreSQL constructs and expands the functions in rawSQL.
+// Without a time range the macros expand to an empty range at now.
+func interpolateSQL(rawSQL string, timeRange TimeRange, interval time.Duration, now time.Time) (string, error) {
+	tr := backend.TimeRange{From: now, To: now}
+	if timeRange != nil {
+		tr = timeRange.AbsoluteTime(now)
+	}
+	interpolated, err := sql.Interpolate(rawSQL, tr, interval)
+	if err != nil {
+		return "", err
+	}
+	normalized, err := sql.NormalizeDialect(interpolated)
+	if err != nil {
+		return "", err
+	}
+	return sql.ExpandFunctions(normalized)
+}
+
+// AllowStatements sets the statement types the command is allowed to run, for example SELECT.
+func (gr *SQLCommand) AllowStatements(statements []string) {
+	gr.allowedStatements = statements
+}
+
+// Explain makes the command return the query plan of its statement as an extra frame, after the result.
+func (gr *SQLCommand) Explain(explain bool) {
+	gr.explain = explain
+}
+
+// LongFormat makes the command load the wide time series of its inputs as long tables, with a time column, a value
+// column per series name and a column per label, so they can be grouped by label. A result in the long format is
+// returned as a wide time series.
+func (gr *SQLCommand) LongFormat(longFormat bool) {
+	gr.longFormat = longFormat
+}
+
+// Parameters sets the values of the dashboard and alert variables the query references, e.g. WHERE region = $region.
+// The values are bound by the engine rather than interpolated into the query, and a multi-value variable is
+// expanded to a list, e.g. WHERE region IN ($region).
+func (gr *SQLCommand) Parameters(parameters sql.Parameters) {
+	gr.parameters = parameters
+}
+
+// TolerateConversionErrors makes the command return the values of its inputs and its result that can not be
+// converted, e.g. invalid JSON, as NULL instead of failing. The number of such values per column is returned as
+// warning notices of the result.
+func (gr *SQLCommand) TolerateConversionErrors(tolerant bool) {
+	gr.tolerant = tolerant
+}
+
+// Downsample makes the command reduce the inputs with more rows than the maximum rows of the downsampling and a time
+// field before they are loaded, so exploratory queries over raw data do not blow the memory. The reduction of every
+// input is returned as an info notice of the result.
+func (gr *SQLCommand) Downsample(downsampling *sql.Downsampling) {
+	gr.downsampling = downsampling
+}
+
+// configureSQLCommand applies the statement types configured for the org, the complexity and duration limits and
+// the storage of large tables to SQL expressions, and makes them report metrics. SQL expressions are rejected in the
+// orgs they are not enabled for.
+// Without configuration, SQL expressions keep the default allow-list, have no limits and keep their tables in memory.
+func (s *Service) configureSQLCommand(node *CMDNode, orgID int64) error {
+	sqlCmd, ok := node.Command.(*SQLCommand)
+	if !ok {
+		return nil
+	}
+	sqlCmd.orgID = orgID
+	sqlCmd.metrics = s.metrics
+	if s.cfg == nil {
+		return nil
+	}
+	if !s.cfg.SQLExpressionsEnabledForOrg(orgID) {
+		return errutil.Forbidden("sql-disabled-for-org",
+			errutil.WithPublicMessage("SQL expressions are not enabled for this organization"))
+	}
+	if allowed := s.cfg.SQLExpressionsAllowedStatementsForOrg(orgID); len(allowed) > 0 {
+		sqlCmd.AllowStatements(allowed)
+	}
+	sqlCmd.storage = sql.StorageOptions{
+		SpillThreshold: s.cfg.SQLExpressionsSpillThreshold,
+		SpillDir:       s.cfg.SQLExpressionsSpillDir,
+	}
+	sqlCmd.limits = sql.Limits{
+		MaxJoins:         s.cfg.SQLExpressionsMaxJoins,
+		MaxSubqueryDepth: s.cfg.SQLExpressionsMaxSubqueryDepth,
+	}
+	sqlCmd.timeout = s.cfg.SQLExpressionsTimeout
+	return nil
+}
+
+// ReadsSchemas returns true when the command reads the columns of all the query results from sql.SchemasTable,
+// it is then evaluated after the data source queries.
+func (gr *SQLCommand) ReadsSchemas() bool {
+	return gr.schemas
+}
+
+// schemaInputs returns the frames listed by sql.SchemasTable: the results evaluated before the command, sorted by
+// RefID, and the temporary tables it reads
+func schemaInputs(vars mathexp.Vars, inputs []*data.Frame, refID string) []*data.Frame {
+	refIDs := make([]string, 0, len(vars))
+	for ref, res := range vars {
+		if ref != refID && res.Error == nil {
+			refIDs = append(refIDs, ref)
+		}
+	}
+	sort.Strings(refIDs)
+	frames := []*data.Frame{}
+	for _, ref := range refIDs {
+		frames = append(frames, vars[ref].Values.AsDataFrames(ref)...)
+	}
+	for _, f := range inputs {
+		if _, ok := vars[f.RefID]; !ok {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// TemporaryTable returns the name of the table persisted by the command for the SQL expressions evaluated after it,
+// it is empty when the command does not persist a table.
+func (gr *SQLCommand) TemporaryTable() string {
+	if gr.table == nil {
+		return ""
+	}
+	return gr.table.Name
+}
+
+// resolveTables replaces the tables of the query that name a query, an expression or a temporary table in another
+// case, the identifiers of SQL are case insensitive, with the name in the registry. The other tables, e.g. the
+// tables of common table expressions, are not dependencies and are kept.
+func (gr *SQLCommand) resolveTables(registry map[string]Node) {
+	for i, table := range gr.varsToQuery {
+		if _, ok := registry[table]; ok {
+			continue
+		}
+		matches := []string{}
+		for name := range registry {
+			if strings.EqualFold(name, table) {
+				matches = append(matches, name)
+			}
+		}
+		// with several matches, e.g. a and A for table a, the table is ambiguous and left to the query
+		if len(matches) == 1 {
+			gr.varsToQuery[i] = matches[0]
+		}
+	}
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -76,36 +408,136 @@ func (gr *SQLCommand) NeedsVars() []string {
 
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
-func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
-	_, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (rsp mathexp.Results, err error) {
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
 	defer span.End()
 
+	start := time.Now()
+	tables := sqlTablesFromContext(ctx)
 	allFrames := []*data.Frame{}
+	rowsIn := 0
 	for _, ref := range gr.varsToQuery {
-		results, ok := vars[ref]
-		if !ok {
+		var frames []*data.Frame
+		if results, ok := vars[ref]; ok {
+			frames = results.Values.AsDataFrames(ref)
+		} else if table := tables.get(ref); table != nil {
+			// persisted by a SQL expression evaluated before this one
+			frames = []*data.Frame{table}
+		} else {
 			logger.Warn("no results found for", "ref", ref)
 			continue
 		}
-		frames := results.Values.AsDataFrames(ref)
+		for _, f := range frames {
+			rowsIn += f.Rows()
+		}
 		allFrames = append(allFrames, frames...)
 	}
+	var downsampled []data.Notice
+	if gr.downsampling != nil {
+		allFrames, downsampled = sql.Downsample(allFrames, *gr.downsampling)
+	}
+	var longErr error
+	if gr.longFormat {
+		allFrames, longErr = sql.LongTables(allFrames)
+	}
+	if gr.schemas {
+		allFrames = append(allFrames, sql.SchemasFrame(schemaInputs(vars, allFrames, gr.refID)))
+	}
 
-	rsp := mathexp.Results{}
+	span.SetAttributes(
+		attribute.String("refId", gr.refID),
+		attribute.Int64("org_id", gr.orgID),
+		attribute.Int("input_frames", len(allFrames)),
+		attribute.Int("input_rows", rowsIn),
+	)
+	defer func() {
+		rowsOut := 0
+		for _, v := range rsp.Values {
+			rowsOut += v.AsDataFrame().Rows()
+		}
+		span.SetAttributes(attribute.Int("output_rows", rowsOut))
+		if rsp.Error != nil {
+			span.SetStatus(codes.Error, "failed to execute SQL expression")
+			span.RecordError(rsp.Error)
+		}
+		gr.observe(rsp.Error, rowsIn, rowsOut, time.Since(start))
+	}()
 
-	db := sql.NewInMemoryDB()
-	var frame = &data.Frame{}
+	query, err := interpolateSQL(gr.query, gr.timeRange, gr.interval, now)
+	if err != nil {
+		logger.Error("Failed to expand macros", "error", err.Error())
+		rsp.Error = err
+		return rsp, nil
+	}
+	if err := gr.validateStatements(query); err != nil {
+		logger.Warn("Rejected sql query", "query", gr.query, "error", err.Error())
+		rsp.Error = err
+		return rsp, nil
+	}
+	if longErr != nil {
+		logger.Error("Failed to convert time series to the long format", "error", longErr.Error())
+		rsp.Error = longErr
+		return rsp, nil
+	}
 
-	logger.Debug("Executing query", "query", gr.query, "frames", len(allFrames))
-	err := db.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	if gr.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gr.timeout)
+		defer cancel()
+	}
+	db := sql.NewDB(gr.storage)
+	db.TraceQueries(tracer)
+	var conversions *sql.ConversionErrors
+	if gr.tolerant {
+		conversions = sql.NewConversionErrors()
+		db.TolerateConversionErrors(conversions)
+	}
+	var frame *data.Frame
+	// the statement returning the result of the expression and the frames it reads
+	statement, inputs := query, allFrames
+	table, err := sql.ParseTemporaryTable(query)
+	if err == nil && table != nil {
+		var persisted *data.Frame
+		frame, persisted, err = gr.createTemporaryTable(ctx, tracer, db, table, allFrames, tables)
+		statement = table.Query
+		if table.Statement != "" {
+			statement, inputs = table.Statement, append(allFrames, persisted)
+		}
+	} else if err == nil {
+		frame, err = gr.queryFrames(ctx, tracer, db, gr.refID, query, allFrames)
+	}
+	if errors.Is(err, context.DeadlineExceeded) && gr.timeout > 0 {
+		logger.Warn("SQL expression timed out", "query", gr.query, "timeout", gr.timeout)
+		rsp.Error = errutil.Timeout("sql-timeout",
+			errutil.WithPublicMessage(fmt.Sprintf("SQL expression did not complete within %s", gr.timeout)),
+		).Errorf("query timed out: %w", err)
+		return rsp, nil
+	}
+	if sql.IsAmbiguousColumnError(err) {
+		logger.Warn("Ambiguous column in sql query", "query", gr.query, "error", err.Error())
+		rsp.Error = errutil.BadRequest("sql-ambiguous-column",
+			errutil.WithPublicMessage(fmt.Sprintf("error in SQL command: %s. Qualify the column with its table and name the columns of the joined frames with AS, e.g. SELECT A.value AS a_value, B.value AS b_value", err)),
+		).Errorf("ambiguous column: %w", err)
+		return rsp, nil
+	}
 	if err != nil {
 		logger.Error("Failed to query frames", "error", err.Error())
 		rsp.Error = err
 		return rsp, nil
 	}
-	logger.Debug("Done Executing query", "query", gr.query, "rows", frame.Rows())
 
+	if gr.longFormat {
+		frame = sql.WideResult(frame)
+	}
 	frame.RefID = gr.refID
+	frame.Meta = mergeSQLFrameMeta(frame.Meta, sql.NewFrameMeta(query, allFrames, frame))
+	if notices := conversions.Notices(); len(notices) > 0 {
+		logger.Warn("SQL expression values could not be converted", "query", gr.query, "columns", len(notices))
+		frame.Meta.Notices = append(frame.Meta.Notices, notices...)
+	}
+	frame.Meta.Notices = append(frame.Meta.Notices, downsampled...)
+	// a time series result, e.g. grouped by a time bucket, is read as series by the reduce and threshold expressions
+	frame = sql.TimeSeriesResult(frame)
 
 	if frame.Rows() == 0 {
 		rsp.Values = mathexp.Values{
@@ -116,10 +548,164 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 	rsp.Values = mathexp.Values{
 		mathexp.TableData{Frame: frame},
 	}
+	if gr.explain {
+		rsp.Values = append(rsp.Values, mathexp.TableData{Frame: gr.queryPlan(ctx, tracer, db, statement, inputs)})
+	}
 
 	return rsp, nil
 }
 
+// queryPlan returns the plan of the statement as a frame, failing to read it does not fail the expression
+func (gr *SQLCommand) queryPlan(ctx context.Context, tracer tracing.Tracer, db *sql.DB, statement string, frames []*data.Frame) *data.Frame {
+	plan, err := gr.queryFrames(ctx, tracer, db, gr.refID, sql.ExplainStatement(statement), frames)
+	if err != nil {
+		logger.Warn("Failed to read the query plan", "query", statement, "error", err.Error())
+	}
+	return sql.NewPlanFrame(gr.refID, statement, plan, err)
+}
+
+// validateStatements checks that the statements of the interpolated query are allowed and within the complexity
+// limits, so the macros and functions can not expand into statements the raw query would be rejected for. The
+// statements that compute and use a temporary table are checked instead of the CREATE statement, so creating it
+// needs no other statement type.
+func (gr *SQLCommand) validateStatements(query string) error {
+	table, err := sql.ParseTemporaryTable(query)
+	if err != nil {
+		return err
+	}
+	statements := []string{query}
+	if table != nil {
+		statements = []string{table.Query}
+		if table.Statement != "" {
+			statements = append(statements, table.Statement)
+		}
+	}
+	for _, statement := range statements {
+		if err := sql.ValidateStatement(statement, gr.allowedStatements); err != nil {
+			return err
+		}
+		if err := sql.ValidateComplexity(statement, gr.limits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTemporaryTable computes the rows of the table and keeps them for the SQL expressions evaluated after
+// this one, then runs the statement of the expression with the table. The persisted frame is returned with the result.
+func (gr *SQLCommand) createTemporaryTable(ctx context.Context, tracer tracing.Tracer, db *sql.DB, table *sql.TemporaryTable, frames []*data.Frame, tables *sqlTables) (*data.Frame, *data.Frame, error) {
+	rows, err := gr.queryFrames(ctx, tracer, db, table.Name, table.Query, frames)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the other expressions get their own frame, the result of this one is named and annotated after the expression
+	persisted := &data.Frame{Name: rows.Name, RefID: table.Name, Fields: rows.Fields}
+	if err := tables.set(table.Name, persisted); err != nil {
+		return nil, nil, err
+	}
+	if table.Statement == "" {
+		return rows, persisted, nil
+	}
+	result, err := gr.queryFrames(ctx, tracer, db, gr.refID, table.Statement, append(frames, persisted))
+	return result, persisted, err
+}
+
+func (gr *SQLCommand) queryFrames(ctx context.Context, tracer tracing.Tracer, db *sql.DB, name string, query string, frames []*data.Frame) (*data.Frame, error) {
+	frame := &data.Frame{}
+	bound, args, err := sql.BindParameters(query, gr.parameters)
+	if err != nil {
+		return nil, errutil.BadRequest("sql-invalid-variable",
+			errutil.WithPublicMessage(fmt.Sprintf("error in SQL command: %s", err)),
+		).Errorf("binding variables: %w", err)
+	}
+	logger.Debug("Executing query", "query", bound, "frames", len(frames), "parameters", len(args))
+	ctx, querySpan := tracer.Start(ctx, "SSE.ExecuteSQL.QueryFramesInto")
+	defer querySpan.End()
+	if err := db.QueryFramesInto(ctx, name, bound, frames, frame, args...); err != nil {
+		querySpan.SetStatus(codes.Error, "failed to query frames")
+		querySpan.RecordError(err)
+		return nil, err
+	}
+	logger.Debug("Done Executing query", "query", query, "rows", frame.Rows())
+	// the columns of joined frames can have the same name, the fields of the result must be told apart
+	if notices := sql.UniqueColumns(query, frames, frame); len(notices) > 0 {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.Notices = append(frame.Meta.Notices, notices...)
+	}
+	return frame, nil
+}
+
+// mergeSQLFrameMeta adds the inspection data of the execution to the meta set by the engine, if any.
+func mergeSQLFrameMeta(meta, execution *data.FrameMeta) *data.FrameMeta {
+	if meta == nil {
+		return execution
+	}
+	meta.ExecutedQueryString = execution.ExecutedQueryString
+	if meta.PreferredVisualization == "" {
+		meta.PreferredVisualization = execution.PreferredVisualization
+	}
+	meta.Stats = append(meta.Stats, execution.Stats...)
+	meta.Notices = append(meta.Notices, execution.Notices...)
+	return meta
+}
+
+// observe records the metrics of one execution. Commands built outside of a pipeline have no metrics.
+func (gr *SQLCommand) observe(err error, rowsIn, rowsOut int, duration time.Duration) {
+	if gr.metrics == nil {
+		return
+	}
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	gr.metrics.sqlCommandDuration.WithLabelValues(status).Observe(float64(duration.Milliseconds()))
+	gr.metrics.sqlCommandInputRows.Observe(float64(rowsIn))
+	gr.metrics.sqlCommandOutputRows.Observe(float64(rowsOut))
+}
+
 func (gr *SQLCommand) Type() string {
 	return TypeSQL.String()
 }
+
+type sqlTablesKey struct{}
+
+// sqlTables holds the tables persisted by the SQL expressions during one evaluation of a pipeline
+type sqlTables struct {
+	mu     sync.Mutex
+	frames map[string]*data.Frame
+}
+
+// withSQLTables returns a context holding the tables of a new evaluation of a pipeline
+func withSQLTables(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sqlTablesKey{}, &sqlTables{frames: map[string]*data.Frame{}})
+}
+
+// sqlTablesFromContext returns the tables of the evaluation, nil for commands executed outside of a pipeline
+func sqlTablesFromContext(ctx context.Context) *sqlTables {
+	tables, _ := ctx.Value(sqlTablesKey{}).(*sqlTables)
+	return tables
+}
+
+func (t *sqlTables) get(name string) *data.Frame {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.frames[name]
+}
+
+func (t *sqlTables) set(name string, frame *data.Frame) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.frames[name]; ok {
+		return fmt.Errorf("the temporary table %s already exists", name)
+	}
+	t.frames[name] = frame
+	return nil
+}
@@ -1,13 +1,29 @@
 package expr
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestNewCommand(t *testing.T) {
 	t.Skip()
-	cmd, err := NewSQLCommand("a", "select a from foo, bar")
+	cmd, err := NewSQLCommand("a", "select a from foo, bar", nil, time.Second)
 	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
 		return
 	}
@@ -25,3 +41,152 @@ func TestNewCommand(t *testing.T) {
 		return
 	}
 }
+
+func TestConfigureSQLCommand(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.SQLExpressionsDisabledOrgs = []int64{2}
+	cfg.SQLExpressionsMaxJoins = 1
+	cfg.SQLExpressionsTimeout = time.Nanosecond
+	s := &Service{cfg: cfg, metrics: newMetrics(nil)}
+	newNode := func(query string) *CMDNode {
+		return &CMDNode{Command: &SQLCommand{query: query, refID: "B", allowedStatements: sql.DefaultAllowedStatements}}
+	}
+	execute := func(node *CMDNode) error {
+		rsp, err := node.Command.Execute(context.Background(), time.Now(), mathexp.Vars{}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return rsp.Error
+	}
+
+	t.Run("disabled for the org", func(t *testing.T) {
+		err := s.configureSQLCommand(newNode("SELECT * FROM A"), 2)
+		require.ErrorContains(t, err, "sql-disabled-for-org")
+	})
+
+	t.Run("too many joins", func(t *testing.T) {
+		node := newNode("SELECT * FROM A JOIN B ON A.x = B.x JOIN C ON A.x = C.x")
+		require.NoError(t, s.configureSQLCommand(node, 1))
+		require.ErrorContains(t, execute(node), "at most 1 joins, found 2")
+	})
+
+	t.Run("too many joins after the functions are expanded", func(t *testing.T) {
+		sql.RegisterFunction(sql.Function{Name: "test_joined_max", MinArgs: 1, MaxArgs: 1, Expand: func(args []string) (string, error) {
+			return "(SELECT MAX(" + args[0] + ") FROM A JOIN B ON A.x = B.x JOIN C ON A.x = C.x)", nil
+		}})
+		t.Cleanup(func() { sql.UnregisterFunction("test_joined_max") })
+		node := newNode("SELECT test_joined_max(value) FROM A")
+		require.NoError(t, s.configureSQLCommand(node, 1))
+		require.ErrorContains(t, execute(node), "at most 1 joins, found 2")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		node := newNode("SELECT * FROM A")
+		require.NoError(t, s.configureSQLCommand(node, 1))
+		require.ErrorContains(t, execute(node), "sql-timeout")
+	})
+}
+
+func TestReadSQLParameters(t *testing.T) {
+	parameters, err := readSQLParameters(map[string]any{"region": "eu", "host": []any{"a", "b"}, "limit": float64(10)})
+	require.NoError(t, err)
+	require.Equal(t, sql.Parameters{"region": {"eu"}, "host": {"a", "b"}, "limit": {"10"}}, parameters)
+
+	_, err = readSQLParameters(map[string]any{"region": map[string]any{}})
+	require.ErrorContains(t, err, "expected the values of region to be strings")
+	_, err = readSQLParameters("region=eu")
+	require.ErrorContains(t, err, "expected an object")
+}
+
+func TestReadSQLDownsampling(t *testing.T) {
+	downsampling, err := readSQLDownsampling(map[string]any{"maxRows": float64(100)})
+	require.NoError(t, err)
+	require.Equal(t, &sql.Downsampling{Method: sql.DownsampleLTTB, MaxRows: 100}, downsampling)
+
+	downsampling, err = readSQLDownsampling(map[string]any{"method": "mean", "maxRows": float64(10)})
+	require.NoError(t, err)
+	require.Equal(t, &sql.Downsampling{Method: sql.DownsampleMean, MaxRows: 10}, downsampling)
+
+	_, err = readSQLDownsampling(map[string]any{"method": "median", "maxRows": float64(10)})
+	require.ErrorContains(t, err, `unknown downsampling method "median"`)
+	_, err = readSQLDownsampling(map[string]any{"method": "mean"})
+	require.ErrorContains(t, err, "expected maxRows to be a number")
+	_, err = readSQLDownsampling(map[string]any{"maxRows": float64(1)})
+	require.ErrorContains(t, err, "at least 3")
+}
+
+func TestSQLCommandTelemetry(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	s := &Service{cfg: setting.NewCfg(), metrics: newMetrics(reg)}
+	node := &CMDNode{Command: &SQLCommand{query: "SELECT * FROM A", refID: "B", varsToQuery: []string{"A"}, allowedStatements: sql.DefaultAllowedStatements}}
+	require.NoError(t, s.configureSQLCommand(node, 3))
+
+	frame := data.NewFrame("", data.NewField("value", nil, []int64{1, 2, 3}))
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}}
+	recorder := tracetest.NewSpanRecorder()
+	rsp, err := node.Command.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest(tracing.WithSpanProcessor(recorder)))
+	require.NoError(t, err)
+	require.Error(t, rsp.Error, "no engine is embedded")
+
+	t.Run("records the metrics without the org", func(t *testing.T) {
+		require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP grafana_sse_sql_command_input_rows Number of rows in the frames SQL expressions read
+# TYPE grafana_sse_sql_command_input_rows histogram
+grafana_sse_sql_command_input_rows_bucket{le="10"} 1
+grafana_sse_sql_command_input_rows_bucket{le="100"} 1
+grafana_sse_sql_command_input_rows_bucket{le="1000"} 1
+grafana_sse_sql_command_input_rows_bucket{le="10000"} 1
+grafana_sse_sql_command_input_rows_bucket{le="100000"} 1
+grafana_sse_sql_command_input_rows_bucket{le="1e+06"} 1
+grafana_sse_sql_command_input_rows_bucket{le="+Inf"} 1
+grafana_sse_sql_command_input_rows_sum 3
+grafana_sse_sql_command_input_rows_count 1
+# HELP grafana_sse_sql_command_output_rows Number of rows SQL expressions return
+# TYPE grafana_sse_sql_command_output_rows histogram
+grafana_sse_sql_command_output_rows_bucket{le="10"} 1
+grafana_sse_sql_command_output_rows_bucket{le="100"} 1
+grafana_sse_sql_command_output_rows_bucket{le="1000"} 1
+grafana_sse_sql_command_output_rows_bucket{le="10000"} 1
+grafana_sse_sql_command_output_rows_bucket{le="100000"} 1
+grafana_sse_sql_command_output_rows_bucket{le="1e+06"} 1
+grafana_sse_sql_command_output_rows_bucket{le="+Inf"} 1
+grafana_sse_sql_command_output_rows_sum 0
+grafana_sse_sql_command_output_rows_count 1
+`), "grafana_sse_sql_command_input_rows", "grafana_sse_sql_command_output_rows"))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() != "grafana_sse_sql_command_duration_milliseconds" {
+				continue
+			}
+			require.Len(t, family.GetMetric(), 1)
+			labels := family.GetMetric()[0].GetLabel()
+			require.Len(t, labels, 1)
+			require.Equal(t, "status", labels[0].GetName())
+			require.Equal(t, "failure", labels[0].GetValue())
+			require.Equal(t, uint64(1), family.GetMetric()[0].GetHistogram().GetSampleCount())
+			return
+		}
+		t.Fatal("the duration is not recorded")
+	})
+
+	t.Run("traces the execution and its stages", func(t *testing.T) {
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+		execution := spans["SSE.ExecuteSQL"]
+		require.NotNil(t, execution)
+		require.Contains(t, execution.Attributes(), attribute.Int64("org_id", 3))
+		require.Contains(t, execution.Attributes(), attribute.Int("input_rows", 3))
+		require.Equal(t, codes.Error, execution.Status().Code)
+
+		query := spans["SSE.ExecuteSQL.QueryFramesInto"]
+		require.NotNil(t, query)
+		require.Equal(t, execution.SpanContext().SpanID(), query.Parent().SpanID())
+		for _, stage := range []string{"SSE.ExecuteSQL.LoadTables", "SSE.ExecuteSQL.Query"} {
+			require.NotNil(t, spans[stage], stage)
+			require.Equal(t, query.SpanContext().SpanID(), spans[stage].Parent().SpanID(), stage)
+		}
+		require.Equal(t, codes.Error, spans["SSE.ExecuteSQL.Query"].Status().Code)
+	})
+}
@@ -147,9 +147,12 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 		return util.Pointer(float64(0))
 	}
 
-	refVarResult := vars[tc.ReferenceVar]
-	newRes := mathexp.Results{Values: make(mathexp.Values, 0, len(refVarResult.Values))}
-	for _, val := range refVarResult.Values {
+	vals, err := timeSeriesValues(vars[tc.ReferenceVar].Values)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	newRes := mathexp.Results{Values: make(mathexp.Values, 0, len(vals))}
+	for _, val := range vals {
 		switch v := val.(type) {
 		case mathexp.Series:
 			s := mathexp.NewSeries(tc.RefID, v.GetLabels(), v.Len())
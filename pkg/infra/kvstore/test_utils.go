@@ -54,15 +54,14 @@ func (f *FakeKVStore) Keys(ctx context.Context, orgId int64, namespace string, k
 
 func (f *FakeKVStore) GetAll(ctx context.Context, orgId int64, namespace string) (map[int64]map[string]string, error) {
 	items := make(map[int64]map[string]string)
-	for k := range f.store {
-		orgId := k.OrgId
-		namespace := k.Namespace
-
-		if _, ok := items[orgId]; !ok {
-			items[orgId] = make(map[string]string)
+	for k, v := range f.store {
+		if k.Namespace != namespace || (orgId != AllOrganizations && k.OrgId != orgId) {
+			continue
 		}
-
-		items[orgId][namespace] = f.store[k]
+		if _, ok := items[k.OrgId]; !ok {
+			items[k.OrgId] = make(map[string]string)
+		}
+		items[k.OrgId][k.Key] = v
 	}
 
 	return items, nil
@@ -2,6 +2,7 @@ package requestmeta
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/web"
@@ -50,6 +51,16 @@ const (
 	SLOGroupNone SLOGroup = "none"
 )
 
+// ParseSLOGroup returns the SLO group with the given name, e.g. high-slow.
+func ParseSLOGroup(name string) (SLOGroup, error) {
+	switch g := SLOGroup(name); g {
+	case SLOGroupHighFast, SLOGroupHighMedium, SLOGroupHighSlow, SLOGroupLow, SLOGroupNone:
+		return g, nil
+	}
+	return "", fmt.Errorf("unknown SLO group %q, expected one of %s, %s, %s, %s or %s", name,
+		SLOGroupHighFast, SLOGroupHighMedium, SLOGroupHighSlow, SLOGroupLow, SLOGroupNone)
+}
+
 type rMDContextKey struct{}
 
 var requestMetaDataContextKey = rMDContextKey{}
@@ -45,3 +45,14 @@ func TestWithStatusSource(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSLOGroup(t *testing.T) {
+	for _, g := range []SLOGroup{SLOGroupHighFast, SLOGroupHighMedium, SLOGroupHighSlow, SLOGroupLow, SLOGroupNone} {
+		parsed, err := ParseSLOGroup(string(g))
+		require.NoError(t, err)
+		require.Equal(t, g, parsed)
+	}
+
+	_, err := ParseSLOGroup("fast")
+	require.ErrorContains(t, err, `unknown SLO group "fast"`)
+}
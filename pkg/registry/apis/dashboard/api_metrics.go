@@ -0,0 +1,175 @@
+package dashboard
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/endpoints/responsewriter"
+
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// APIMetrics are the metrics of the requests of the dashboards API group, per verb and version, so the requests of
+// the dashboards API can be compared with the ones of the legacy HTTP API while the clients migrate. The verbs are
+// search, get, list, write and versions, the other requests of the group are counted as other.
+type APIMetrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+func NewAPIMetrics(reg prometheus.Registerer) *APIMetrics {
+	sizeBuckets := prometheus.ExponentialBuckets(256, 4, 10)
+	return &APIMetrics{
+		requests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboards_api",
+			Name:      "requests_total",
+			Help:      "The number of requests of the dashboards API, per verb, version and status code.",
+		}, []string{"verb", "version", "status_code"}),
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboards_api",
+			Name:      "request_duration_seconds",
+			Help:      "The duration of the requests of the dashboards API, per verb and version.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"verb", "version"}),
+		requestSize: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboards_api",
+			Name:      "request_size_bytes",
+			Help:      "The size of the bodies of the requests of the dashboards API, per verb and version.",
+			Buckets:   sizeBuckets,
+		}, []string{"verb", "version"}),
+		responseSize: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboards_api",
+			Name:      "response_size_bytes",
+			Help:      "The size of the bodies of the responses of the dashboards API, per verb and version.",
+			Buckets:   sizeBuckets,
+		}, []string{"verb", "version"}),
+	}
+}
+
+// Handler records the metrics of the requests of the dashboards API group, the other requests and the watches are
+// passed through. The metrics are recorded with the trace ID of sampled requests as exemplar.
+func (m *APIMetrics) Handler(handler http.Handler) http.Handler {
+	if m == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		info, ok := k8srequest.RequestInfoFrom(req.Context())
+		if !ok || info.APIGroup != dashboard.GROUP || info.Verb == "watch" {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		verb := apiVerb(info)
+
+		start := time.Now()
+		body := &countingReader{ReadCloser: req.Body}
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = body
+		}
+		rw := &metricsResponseWriter{ResponseWriter: w}
+		handler.ServeHTTP(responsewriter.WrapForHTTP1Or2(rw), req)
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+
+		exemplar := prometheus.Labels{}
+		if traceID := tracing.TraceIDFromContext(req.Context(), true); traceID != "" {
+			exemplar["traceID"] = traceID
+		}
+		addWithExemplar(m.requests.WithLabelValues(verb, info.APIVersion, strconv.Itoa(rw.status)), exemplar)
+		observeWithExemplar(m.duration.WithLabelValues(verb, info.APIVersion), time.Since(start).Seconds(), exemplar)
+		if body.n > 0 {
+			observeWithExemplar(m.requestSize.WithLabelValues(verb, info.APIVersion), float64(body.n), exemplar)
+		}
+		observeWithExemplar(m.responseSize.WithLabelValues(verb, info.APIVersion), float64(rw.written), exemplar)
+	})
+}
+
+func addWithExemplar(counter prometheus.Counter, exemplar prometheus.Labels) {
+	if len(exemplar) == 0 {
+		counter.Inc()
+		return
+	}
+	counter.(prometheus.ExemplarAdder).AddWithExemplar(1, exemplar)
+}
+
+func observeWithExemplar(histogram prometheus.Observer, v float64, exemplar prometheus.Labels) {
+	if len(exemplar) == 0 {
+		histogram.Observe(v)
+		return
+	}
+	histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(v, exemplar)
+}
+
+// apiVerb returns the verb of a request of the dashboards API group as it is labeled in the metrics
+func apiVerb(info *k8srequest.RequestInfo) string {
+	switch {
+	case info.Resource == "search" || (info.Resource == "legacy" && info.Name == "search"):
+		return "search"
+	case info.Resource != dashboard.DashboardResourceInfo.GroupResource().Resource:
+		return "other"
+	case info.Subresource == "versions" || info.Subresource == "history":
+		return "versions"
+	case info.Subresource != "":
+		return "other"
+	}
+	switch info.Verb {
+	case "get", "list":
+		return info.Verb
+	case "create", "update", "patch", "delete", "deletecollection":
+		return "write"
+	}
+	return "other"
+}
+
+// countingReader counts the bytes of the request body read by the handler
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// metricsResponseWriter keeps the status code and counts the bytes of the response body
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+var _ responsewriter.UserProvidedDecorator = &metricsResponseWriter{}
+
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *metricsResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
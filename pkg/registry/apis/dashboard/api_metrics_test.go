@@ -0,0 +1,69 @@
+package dashboard
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestAPIVerb(t *testing.T) {
+	tests := []struct {
+		info k8srequest.RequestInfo
+		verb string
+	}{
+		{k8srequest.RequestInfo{Verb: "get", Resource: "search", Name: "name"}, "search"},
+		{k8srequest.RequestInfo{Verb: "get", Resource: "legacy", Name: "search"}, "search"},
+		{k8srequest.RequestInfo{Verb: "get", Resource: "dashboards", Name: "abc"}, "get"},
+		{k8srequest.RequestInfo{Verb: "list", Resource: "dashboards"}, "list"},
+		{k8srequest.RequestInfo{Verb: "create", Resource: "dashboards"}, "write"},
+		{k8srequest.RequestInfo{Verb: "patch", Resource: "dashboards", Name: "abc"}, "write"},
+		{k8srequest.RequestInfo{Verb: "delete", Resource: "dashboards", Name: "abc"}, "write"},
+		{k8srequest.RequestInfo{Verb: "get", Resource: "dashboards", Name: "abc", Subresource: "versions"}, "versions"},
+		{k8srequest.RequestInfo{Verb: "get", Resource: "dashboards", Name: "abc", Subresource: "history"}, "versions"},
+		{k8srequest.RequestInfo{Verb: "get", Resource: "dashboards", Name: "abc", Subresource: "dto"}, "other"},
+		{k8srequest.RequestInfo{Verb: "get", Resource: "librarypanels"}, "other"},
+		{k8srequest.RequestInfo{Verb: "create", Resource: "import"}, "other"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.verb, apiVerb(&tt.info), "%+v", tt.info)
+	}
+}
+
+func TestAPIMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewAPIMetrics(reg)
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.ReadAll(req.Body)
+		if req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(`{"kind":"Dashboard"}`))
+	}))
+	serve := func(method string, body string, info *k8srequest.RequestInfo) {
+		req := httptest.NewRequest(method, "/apis/dashboard.grafana.app/v1alpha1/namespaces/default/dashboards", strings.NewReader(body))
+		if info != nil {
+			req = req.WithContext(k8srequest.WithRequestInfo(req.Context(), info))
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(http.MethodGet, "", &k8srequest.RequestInfo{APIGroup: "dashboard.grafana.app", APIVersion: "v1alpha1", Verb: "list", Resource: "dashboards"})
+	serve(http.MethodPost, `{"spec":{}}`, &k8srequest.RequestInfo{APIGroup: "dashboard.grafana.app", APIVersion: "v1alpha1", Verb: "create", Resource: "dashboards"})
+	serve(http.MethodGet, "", &k8srequest.RequestInfo{APIGroup: "dashboard.grafana.app", APIVersion: "v1alpha1", Verb: "watch", Resource: "dashboards"})
+	serve(http.MethodGet, "", &k8srequest.RequestInfo{APIGroup: "folder.grafana.app", APIVersion: "v0alpha1", Verb: "list", Resource: "folders"})
+	serve(http.MethodGet, "", nil)
+
+	require.Equal(t, 2, testutil.CollectAndCount(m.requests), "the watches and the requests of the other groups are not counted")
+	require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("list", "v1alpha1", "200")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("write", "v1alpha1", "201")))
+	require.Equal(t, 2, testutil.CollectAndCount(m.duration))
+	require.Equal(t, 1, testutil.CollectAndCount(m.requestSize), "only the requests with a body have a request size")
+	require.Equal(t, 2, testutil.CollectAndCount(m.responseSize))
+}
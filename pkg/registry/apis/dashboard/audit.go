@@ -0,0 +1,201 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Verbs of the dashboard audit events
+const (
+	AuditVerbCreate = "create"
+	AuditVerbUpdate = "update"
+	AuditVerbDelete = "delete"
+)
+
+// DashboardAuditor publishes the changes made to dashboards through the dashboards API to the events bus, and appends
+// them to the audit log file when one is configured. The changes made by the legacy API have their own audit hooks.
+type DashboardAuditor struct {
+	bus bus.Bus
+	log log.Logger
+	now func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func ProvideDashboardAuditor(cfg *setting.Cfg, bus bus.Bus) (*DashboardAuditor, error) {
+	a := &DashboardAuditor{
+		bus: bus,
+		log: log.New("dashboards.audit"),
+		now: time.Now,
+	}
+	if cfg.DashboardAuditLogPath != "" {
+		f, err := os.OpenFile(cfg.DashboardAuditLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the dashboard audit log: %w", err)
+		}
+		a.file = f
+	}
+	return a, nil
+}
+
+// record publishes the change of a dashboard. The previous and the new object are nil when the dashboard
+// did not exist before or after the change. Failures are logged, the change is already made.
+func (a *DashboardAuditor) record(ctx context.Context, verb string, previous, current runtime.Object) {
+	if a == nil {
+		return
+	}
+	e := &events.DashboardChanged{
+		Timestamp:  a.now(),
+		Verb:       verb,
+		OldVersion: auditGeneration(previous),
+		NewVersion: auditGeneration(current),
+	}
+	obj := current
+	if obj == nil {
+		obj = previous
+	}
+	if m, err := utils.MetaAccessor(obj); err == nil {
+		e.Namespace = m.GetNamespace()
+		e.UID = m.GetName()
+	}
+	if info, ok := k8srequest.RequestInfoFrom(ctx); ok {
+		e.APIVersion = info.APIGroup + "/" + info.APIVersion
+		if e.Namespace == "" {
+			e.Namespace = info.Namespace
+		}
+	}
+	if user, err := identity.GetRequester(ctx); err == nil {
+		e.ActorUID = user.GetUID()
+		e.ActorLogin = user.GetLogin()
+	}
+	if origin, ok := grafanarequest.OriginFrom(ctx); ok {
+		e.SourceIP = origin.SourceIP
+		e.UserAgent = origin.UserAgent
+	}
+
+	if a.bus != nil {
+		if err := a.bus.Publish(ctx, e); err != nil {
+			a.log.Error("Failed to publish dashboard change", "uid", e.UID, "verb", verb, "error", err)
+		}
+	}
+	if a.file != nil {
+		a.write(e)
+	}
+}
+
+func (a *DashboardAuditor) write(e *events.DashboardChanged) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		a.log.Error("Failed to encode dashboard change", "uid", e.UID, "verb", e.Verb, "error", err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		a.log.Error("Failed to write the dashboard audit log", "uid", e.UID, "verb", e.Verb, "error", err)
+	}
+}
+
+func auditGeneration(obj runtime.Object) int64 {
+	if obj == nil {
+		return 0
+	}
+	m, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return 0
+	}
+	return m.GetGeneration()
+}
+
+// auditStorage records the changes made to dashboards with the DashboardAuditor
+type auditStorage struct {
+	grafanarest.Storage
+	auditor *DashboardAuditor
+}
+
+// auditWatchStorage keeps watch support of storages that implement it
+type auditWatchStorage struct {
+	*auditStorage
+	rest.Watcher
+}
+
+// WithAudit records the changes made to dashboards through the storage, other storages are returned unchanged
+func WithAudit(store rest.Storage, auditor *DashboardAuditor) rest.Storage {
+	s, ok := store.(grafanarest.Storage)
+	if !ok || auditor == nil {
+		return store
+	}
+	if w, ok := store.(rest.Watcher); ok {
+		return &auditWatchStorage{auditStorage: &auditStorage{Storage: s, auditor: auditor}, Watcher: w}
+	}
+	return &auditStorage{Storage: s, auditor: auditor}
+}
+
+func (s *auditStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	created, err := s.Storage.Create(ctx, obj, createValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.auditor.record(ctx, AuditVerbCreate, nil, created)
+	}
+	return created, err
+}
+
+func (s *auditStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	// the storage reads the current dashboard to validate the update, keep it to know the previous version
+	var previous runtime.Object
+	validate := func(ctx context.Context, obj, old runtime.Object) error {
+		previous = old
+		if updateValidation != nil {
+			return updateValidation(ctx, obj, old)
+		}
+		return nil
+	}
+	updated, created, err := s.Storage.Update(ctx, name, objInfo, createValidation, validate, forceAllowCreate, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		verb := AuditVerbUpdate
+		if created {
+			verb, previous = AuditVerbCreate, nil
+		}
+		s.auditor.record(ctx, verb, previous, updated)
+	}
+	return updated, created, err
+}
+
+func (s *auditStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	deleted, immediate, err := s.Storage.Delete(ctx, name, deleteValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.auditor.record(ctx, AuditVerbDelete, deleted, nil)
+	}
+	return deleted, immediate, err
+}
+
+func (s *auditStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	deleted, err := s.Storage.DeleteCollection(ctx, deleteValidation, options, listOptions)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		_ = meta.EachListItem(deleted, func(obj runtime.Object) error {
+			s.auditor.record(ctx, AuditVerbDelete, obj, nil)
+			return nil
+		})
+	}
+	return deleted, err
+}
@@ -0,0 +1,145 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestAuditStorage(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	published := &fakeAuditBus{}
+	auditor, err := ProvideDashboardAuditor(&setting.Cfg{DashboardAuditLogPath: logPath}, published)
+	require.NoError(t, err)
+	auditor.now = func() time.Time { return now }
+
+	current := &dashboardv0alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "default", Generation: 3}}
+	store := WithAudit(&fakeAuditedStorage{current: current}, auditor).(*auditStorage)
+
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{UserUID: "u1", Login: "admin", OrgID: 1, FallbackType: claims.TypeUser})
+	ctx = k8srequest.WithRequestInfo(ctx, &k8srequest.RequestInfo{APIGroup: "dashboard.grafana.app", APIVersion: "v0alpha1", Namespace: "default"})
+	ctx = grafanarequest.WithOrigin(ctx, grafanarequest.Origin{SourceIP: "10.0.0.1", UserAgent: "terraform"})
+
+	t.Run("records updates with the previous and the new version", func(t *testing.T) {
+		published.events = nil
+		_, _, err := store.Update(ctx, "abc", nil, nil, nil, false, &metav1.UpdateOptions{})
+		require.NoError(t, err)
+		require.Len(t, published.events, 1)
+		require.Equal(t, &events.DashboardChanged{
+			Timestamp:  now,
+			Namespace:  "default",
+			UID:        "abc",
+			Verb:       AuditVerbUpdate,
+			APIVersion: "dashboard.grafana.app/v0alpha1",
+			ActorUID:   "user:u1",
+			ActorLogin: "admin",
+			OldVersion: 3,
+			NewVersion: 4,
+			SourceIP:   "10.0.0.1",
+			UserAgent:  "terraform",
+		}, published.events[0])
+	})
+
+	t.Run("records creates and deletes", func(t *testing.T) {
+		published.events = nil
+		_, err := store.Create(ctx, current.DeepCopy(), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		_, _, err = store.Delete(ctx, "abc", nil, &metav1.DeleteOptions{})
+		require.NoError(t, err)
+		require.Len(t, published.events, 2)
+		require.Equal(t, AuditVerbCreate, published.events[0].Verb)
+		require.Equal(t, int64(0), published.events[0].OldVersion)
+		require.Equal(t, AuditVerbDelete, published.events[1].Verb)
+		require.Equal(t, int64(3), published.events[1].OldVersion)
+		require.Equal(t, int64(0), published.events[1].NewVersion)
+	})
+
+	t.Run("does not record dry runs", func(t *testing.T) {
+		published.events = nil
+		_, err := store.Create(ctx, current.DeepCopy(), nil, &metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		require.NoError(t, err)
+		require.Empty(t, published.events)
+	})
+
+	t.Run("appends the changes to the audit log", func(t *testing.T) {
+		content, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		lines := splitLines(content)
+		require.Len(t, lines, 3)
+		var e events.DashboardChanged
+		require.NoError(t, json.Unmarshal(lines[0], &e))
+		require.Equal(t, AuditVerbUpdate, e.Verb)
+		require.Equal(t, "admin", e.ActorLogin)
+	})
+
+	t.Run("keeps watch support", func(t *testing.T) {
+		_, ok := WithAudit(&fakeAuditedStorage{}, auditor).(rest.Watcher)
+		require.False(t, ok)
+		_, ok = WithAudit(&fakeWatchDashboardStorage{}, auditor).(rest.Watcher)
+		require.True(t, ok)
+	})
+}
+
+func splitLines(content []byte) [][]byte {
+	lines := [][]byte{}
+	start := 0
+	for i, c := range content {
+		if c == '\n' {
+			lines = append(lines, content[start:i])
+			start = i + 1
+		}
+	}
+	return lines
+}
+
+type fakeAuditBus struct {
+	events []*events.DashboardChanged
+}
+
+func (b *fakeAuditBus) Publish(_ context.Context, msg bus.Msg) error {
+	b.events = append(b.events, msg.(*events.DashboardChanged))
+	return nil
+}
+
+func (b *fakeAuditBus) AddEventListener(_ bus.HandlerFunc) {}
+
+type fakeAuditedStorage struct {
+	fakeDashboardStorage
+	current *dashboardv0alpha1.Dashboard
+}
+
+func (s *fakeAuditedStorage) Create(_ context.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	return obj, nil
+}
+
+func (s *fakeAuditedStorage) Update(ctx context.Context, _ string, _ rest.UpdatedObjectInfo, _ rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, _ bool, _ *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	updated := s.current.DeepCopy()
+	updated.Generation++
+	if err := updateValidation(ctx, updated, s.current.DeepCopy()); err != nil {
+		return nil, false, err
+	}
+	return updated, false, nil
+}
+
+func (s *fakeAuditedStorage) Delete(_ context.Context, _ string, _ rest.ValidateObjectFunc, _ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	return s.current.DeepCopy(), true, nil
+}
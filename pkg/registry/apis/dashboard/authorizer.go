@@ -8,16 +8,19 @@ import (
 	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/apis/dashboard"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
 )
 
 func GetAuthorizer(dashboardService dashboards.DashboardService, l log.Logger) authorizer.Authorizer {
 	return authorizer.AuthorizerFunc(
 		func(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
 			// Use the standard authorizer
-			if !attr.IsResourceRequest() || attr.GetResource() == "search" {
+			// Snapshots are checked by the snapshot storage
+			if !attr.IsResourceRequest() || attr.GetResource() == "search" || attr.GetResource() == "snapshots" {
 				return authorizer.DecisionNoOpinion, "", nil
 			}
 
@@ -26,6 +29,14 @@ func GetAuthorizer(dashboardService dashboards.DashboardService, l log.Logger) a
 				return authorizer.DecisionDeny, "", err
 			}
 
+			// Templates are published by org admins, reading and instantiating them is checked with the org role
+			if attr.GetResource() == dashboardv0alpha1.DashboardTemplateResourceInfo.GroupResource().Resource {
+				if attr.IsReadOnly() || attr.GetSubresource() != "" || user.HasRole(org.RoleAdmin) {
+					return authorizer.DecisionNoOpinion, "", nil
+				}
+				return authorizer.DecisionDeny, "only org admins can publish dashboard templates", nil
+			}
+
 			if attr.GetName() == "" {
 				// Discourage use of the "list" command for non super admin users
 				if attr.GetVerb() == "list" && attr.GetResource() == dashboard.DashboardResourceInfo.GroupResource().Resource {
@@ -61,7 +72,15 @@ func GetAuthorizer(dashboardService dashboards.DashboardService, l log.Logger) a
 				return authorizer.DecisionDeny, "", err
 			}
 
-			switch attr.GetVerb() {
+			verb := attr.GetVerb()
+			switch attr.GetSubresource() {
+			case "annotations", "permissions", "public", "variables", "lint":
+				// Annotation, dashboard and public sharing permissions are checked by the subresource, it only requires access to the dashboard.
+				// Resolving variables and linting do not change the dashboard, and the datasource permissions are checked by the query service
+				verb = "get"
+			}
+
+			switch verb {
 			case "get":
 				ok, err = guardian.CanView()
 				if !ok || err != nil {
@@ -89,7 +108,7 @@ func GetAuthorizer(dashboardService dashboards.DashboardService, l log.Logger) a
 					return authorizer.DecisionDeny, "can not delete dashboard", err
 				}
 			default:
-				l.Info("unknown verb", "verb", attr.GetVerb())
+				l.Info("unknown verb", "verb", verb)
 				return authorizer.DecisionNoOpinion, "unsupported verb", nil // Unknown verb
 			}
 			return authorizer.DecisionAllow, "", nil
@@ -0,0 +1,622 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	BundleKindFolder       = "Folder"
+	BundleKindLibraryPanel = "LibraryPanel"
+	BundleKindDashboard    = "Dashboard"
+
+	BundleActionCreated = "created"
+	BundleActionUpdated = "updated"
+	BundleActionSkipped = "skipped"
+	BundleActionFailed  = "failed"
+)
+
+// ErrInvalidBundle is returned when a bundle cannot be applied because of its content.
+var ErrInvalidBundle = errutil.BadRequest("dashboards.bundle.invalid")
+
+// ErrBundleNotApplied is returned with the result of a bundle when one of its items failed, none of them is applied.
+var ErrBundleNotApplied = errutil.Conflict("dashboards.bundle.notApplied")
+
+// ErrBundlePermissionsDenied fails the items of a bundle whose permissions the user is not allowed to change.
+var ErrBundlePermissionsDenied = errutil.Forbidden("dashboards.bundle.permissionsDenied",
+	errutil.WithPublicMessage("You are not allowed to change the permissions of the items of the bundle"))
+
+// ErrBundleNotAtomic is returned when the folders or dashboards are not stored in the database of the transaction
+// of a bundle, it could then be partially applied.
+var ErrBundleNotAtomic = errutil.NotImplemented("dashboards.bundle.notAtomic",
+	errutil.WithPublicMessage("Bundles can not be applied while folders or dashboards are stored in unified storage"))
+
+// defaultMaxBundleSize is the maximum size of the body of an applied bundle, in bytes
+const defaultMaxBundleSize = 64 << 20
+
+// errBundleDryRun is used to roll back the transaction of a dry run.
+var errBundleDryRun = errors.New("dry run")
+
+// Bundle is a set of folders, library panels and dashboards that are applied together.
+// Items may reference each other by UID, and are applied in dependency order.
+type Bundle struct {
+	Folders       []BundleFolder       `json:"folders,omitempty"`
+	LibraryPanels []BundleLibraryPanel `json:"libraryPanels,omitempty"`
+	Dashboards    []BundleDashboard    `json:"dashboards,omitempty"`
+}
+
+type BundleFolder struct {
+	UID         string             `json:"uid"`
+	Title       string             `json:"title"`
+	Description string             `json:"description,omitempty"`
+	ParentUID   string             `json:"parentUid,omitempty"`
+	Permissions []BundlePermission `json:"permissions,omitempty"`
+}
+
+type BundleLibraryPanel struct {
+	UID       string         `json:"uid"`
+	Name      string         `json:"name"`
+	FolderUID string         `json:"folderUid,omitempty"`
+	Model     map[string]any `json:"model"`
+}
+
+type BundleDashboard struct {
+	UID         string             `json:"uid"`
+	FolderUID   string             `json:"folderUid,omitempty"`
+	Spec        map[string]any     `json:"spec"`
+	Permissions []BundlePermission `json:"permissions,omitempty"`
+}
+
+// BundlePermission is a permission template of a folder or dashboard. Only the permissions of the built-in roles
+// are part of a bundle, the users and teams are specific to an instance. When a folder or dashboard of the bundle
+// has permissions, they replace the permissions of the built-in roles when it is applied.
+type BundlePermission struct {
+	// One of Viewer, Editor or Admin
+	Role string `json:"role"`
+	// One of View, Edit or Admin
+	Permission string `json:"permission"`
+}
+
+// BundleItemResult is the outcome of applying a single item of the bundle.
+type BundleItemResult struct {
+	Kind   string `json:"kind"`
+	UID    string `json:"uid"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BundleResult is the outcome of applying a bundle. Items are listed in the order they were applied.
+type BundleResult struct {
+	Applied bool               `json:"applied"`
+	DryRun  bool               `json:"dryRun,omitempty"`
+	Items   []BundleItemResult `json:"items"`
+}
+
+type bundleItem struct {
+	kind string
+	uid  string
+	deps []bundleRef
+
+	folder       *BundleFolder
+	libraryPanel *BundleLibraryPanel
+	dashboard    *BundleDashboard
+}
+
+type bundleRef struct {
+	kind string
+	uid  string
+}
+
+func (r bundleRef) String() string {
+	return r.kind + "/" + r.uid
+}
+
+// BundleApplier applies bundles using the legacy services inside a single database transaction, and exports
+// folder trees as bundles.
+type BundleApplier struct {
+	db                   db.DB
+	features             featuremgmt.FeatureToggles
+	cfg                  *setting.Cfg
+	folders              folder.Service
+	dashboards           dashboards.DashboardService
+	libraryElements      libraryelements.Service
+	accessControl        accesscontrol.AccessControl
+	folderPermissions    accesscontrol.FolderPermissionsService
+	dashboardPermissions accesscontrol.DashboardPermissionsService
+	// maxBundleSize is the maximum size of the body of an applied bundle, in bytes
+	maxBundleSize int64
+	log           log.Logger
+}
+
+func NewBundleApplier(sql db.DB, features featuremgmt.FeatureToggles, cfg *setting.Cfg, folders folder.Service, dashboardService dashboards.DashboardService,
+	libraryElements libraryelements.Service, accessControl accesscontrol.AccessControl, folderPermissions accesscontrol.FolderPermissionsService,
+	dashboardPermissions accesscontrol.DashboardPermissionsService) *BundleApplier {
+	return &BundleApplier{
+		db:                   sql,
+		features:             features,
+		cfg:                  cfg,
+		folders:              folders,
+		dashboards:           dashboardService,
+		libraryElements:      libraryElements,
+		accessControl:        accessControl,
+		folderPermissions:    folderPermissions,
+		dashboardPermissions: dashboardPermissions,
+		maxBundleSize:        defaultMaxBundleSize,
+		log:                  log.New("dashboard.bundle"),
+	}
+}
+
+// Apply applies all items of the bundle or none of them. When an item fails, the whole bundle
+// is rolled back, the remaining items are reported as skipped and the result is returned with
+// ErrBundleNotApplied. A dry run applies the bundle and always rolls it back.
+// Bundles are rejected with ErrBundleNotAtomic when the folders or dashboards are not written
+// in the transaction, see checkAtomic.
+func (a *BundleApplier) Apply(ctx context.Context, user identity.Requester, bundle Bundle, dryRun bool) (*BundleResult, error) {
+	items, err := sortBundle(bundle)
+	if err != nil {
+		return nil, ErrInvalidBundle.Errorf("%w", err)
+	}
+	if err := a.checkAtomic(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &BundleResult{DryRun: dryRun, Items: make([]BundleItemResult, 0, len(items))}
+	err = a.db.InTransaction(ctx, func(ctx context.Context) error {
+		for i, item := range items {
+			action, err := a.applyItem(ctx, user, item)
+			if err != nil {
+				result.Items = append(result.Items, BundleItemResult{Kind: item.kind, UID: item.uid, Action: BundleActionFailed, Error: err.Error()})
+				for _, rest := range items[i+1:] {
+					result.Items = append(result.Items, BundleItemResult{Kind: rest.kind, UID: rest.uid, Action: BundleActionSkipped})
+				}
+				return fmt.Errorf("failed to apply %s/%s: %w", item.kind, item.uid, err)
+			}
+			result.Items = append(result.Items, BundleItemResult{Kind: item.kind, UID: item.uid, Action: action})
+		}
+		if dryRun {
+			return errBundleDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBundleDryRun) {
+		a.log.Debug("Bundle was rolled back", "error", err)
+		return result, ErrBundleNotApplied.Errorf("%w", err)
+	}
+	result.Applied = !dryRun
+	return result, nil
+}
+
+// checkAtomic returns ErrBundleNotAtomic when the items of a bundle are not all written in the transaction of the
+// database, see checkLegacyTransaction.
+func (a *BundleApplier) checkAtomic(ctx context.Context) error {
+	if err := checkLegacyTransaction(ctx, a.features, a.cfg); err != nil {
+		return ErrBundleNotAtomic.Errorf("%w", err)
+	}
+	return nil
+}
+
+// checkLegacyTransaction returns an error when the folders and dashboards are not all written in the transaction of
+// the database: the folders of the kubernetes folder service, and the dashboards written to unified storage, are not
+// rolled back with it.
+func checkLegacyTransaction(ctx context.Context, features featuremgmt.FeatureToggles, cfg *setting.Cfg) error {
+	if features != nil && features.IsEnabled(ctx, featuremgmt.FlagKubernetesFolders) {
+		return fmt.Errorf("the folders are stored by the kubernetes folder service, %s is enabled", featuremgmt.FlagKubernetesFolders)
+	}
+	if cfg == nil {
+		return nil
+	}
+	for _, gr := range []schema.GroupResource{
+		folderv0alpha1.FolderResourceInfo.GroupResource(),
+		dashboardv0alpha1.DashboardResourceInfo.GroupResource(),
+	} {
+		if mode := cfg.UnifiedStorage[gr.String()].DualWriterMode; mode != grafanarest.Mode0 {
+			return fmt.Errorf("%s are written to unified storage, the dual writer mode is %d", gr.Resource, mode)
+		}
+	}
+	return nil
+}
+
+func (a *BundleApplier) applyItem(ctx context.Context, user identity.Requester, item bundleItem) (string, error) {
+	switch item.kind {
+	case BundleKindFolder:
+		return a.applyFolder(ctx, user, item.folder)
+	case BundleKindLibraryPanel:
+		return a.applyLibraryPanel(ctx, user, item.libraryPanel)
+	case BundleKindDashboard:
+		return a.applyDashboard(ctx, user, item.dashboard)
+	}
+	return "", fmt.Errorf("unknown kind %q", item.kind)
+}
+
+func (a *BundleApplier) applyFolder(ctx context.Context, user identity.Requester, f *BundleFolder) (string, error) {
+	existing, err := a.folders.Get(ctx, &folder.GetFolderQuery{UID: &f.UID, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil {
+		if !errors.Is(err, dashboards.ErrFolderNotFound) && !errors.Is(err, folder.ErrFolderNotFound) {
+			return "", err
+		}
+		_, err = a.folders.Create(ctx, &folder.CreateFolderCommand{
+			UID:          f.UID,
+			OrgID:        user.GetOrgID(),
+			Title:        f.Title,
+			Description:  f.Description,
+			ParentUID:    f.ParentUID,
+			SignedInUser: user,
+		})
+		if err != nil {
+			return "", err
+		}
+		return BundleActionCreated, a.applyPermissions(ctx, user, a.folderPermissions, folderPermissionsWrite(f.UID), f.UID, f.Permissions)
+	}
+
+	if existing.Title != f.Title || existing.Description != f.Description {
+		_, err = a.folders.Update(ctx, &folder.UpdateFolderCommand{
+			UID:            f.UID,
+			OrgID:          user.GetOrgID(),
+			NewTitle:       &f.Title,
+			NewDescription: &f.Description,
+			Overwrite:      true,
+			SignedInUser:   user,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if existing.ParentUID != f.ParentUID {
+		_, err = a.folders.Move(ctx, &folder.MoveFolderCommand{
+			UID:          f.UID,
+			NewParentUID: f.ParentUID,
+			OrgID:        user.GetOrgID(),
+			SignedInUser: user,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return BundleActionUpdated, a.applyPermissions(ctx, user, a.folderPermissions, folderPermissionsWrite(f.UID), f.UID, f.Permissions)
+}
+
+func (a *BundleApplier) applyLibraryPanel(ctx context.Context, user identity.Requester, p *BundleLibraryPanel) (string, error) {
+	m := simplejson.NewFromAny(p.Model)
+	m.Set("uid", p.UID)
+	m.Set("title", p.Name)
+	raw, err := m.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := a.libraryElements.GetElement(ctx, user, model.GetLibraryElementCommand{UID: p.UID, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		if !errors.Is(err, model.ErrLibraryElementNotFound) {
+			return "", err
+		}
+		_, err = a.libraryElements.CreateElement(ctx, user, model.CreateLibraryElementCommand{
+			FolderUID: &p.FolderUID,
+			Name:      p.Name,
+			Model:     raw,
+			Kind:      int64(model.PanelElement),
+			UID:       p.UID,
+		})
+		return BundleActionCreated, err
+	}
+
+	_, err = a.libraryElements.PatchElement(ctx, user, model.PatchLibraryElementCommand{
+		FolderUID: &p.FolderUID,
+		Name:      p.Name,
+		Model:     raw,
+		Kind:      int64(model.PanelElement),
+		Version:   existing.Version,
+		UID:       p.UID,
+	}, p.UID)
+	return BundleActionUpdated, err
+}
+
+func (a *BundleApplier) applyDashboard(ctx context.Context, user identity.Requester, d *BundleDashboard) (string, error) {
+	action := BundleActionCreated
+	_, err := a.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: d.UID, OrgID: user.GetOrgID()})
+	if err == nil {
+		action = BundleActionUpdated
+	} else if !errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return "", err
+	}
+
+	data := simplejson.NewFromAny(d.Spec)
+	data.Set("uid", d.UID)
+	data.Del("id")
+	dash := dashboards.NewDashboardFromJson(data)
+	dash.OrgID = user.GetOrgID()
+	dash.FolderUID = d.FolderUID
+
+	saved, err := a.dashboards.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     user.GetOrgID(),
+		User:      user,
+		Message:   "applied from bundle",
+		Overwrite: true,
+		Dashboard: dash,
+	}, false)
+	if err != nil {
+		return "", err
+	}
+
+	panels := libraryPanelRefs(d.Spec)
+	if len(panels) > 0 {
+		if err := a.libraryElements.ConnectElementsToDashboard(ctx, user, panels, saved.ID); err != nil {
+			return "", err
+		}
+	}
+	return action, a.applyPermissions(ctx, user, a.dashboardPermissions, dashboardPermissionsWrite(d.UID), d.UID, d.Permissions)
+}
+
+// applyPermissions replaces the managed permissions of the built-in roles on the folder or dashboard with the
+// templates, the permissions of users and teams are kept. Nothing is changed when the item has no templates.
+// The permissions service does not authorize the user, the templates are rejected with ErrBundlePermissionsDenied
+// unless the user is allowed the permissions write action of the item. It is evaluated with the permissions the
+// user had before the bundle, so the items created by the bundle need the action on their folder or on all of them.
+func (a *BundleApplier) applyPermissions(ctx context.Context, user identity.Requester, permissions accesscontrol.PermissionsService, evaluator accesscontrol.Evaluator, uid string, templates []BundlePermission) error {
+	if permissions == nil || len(templates) == 0 {
+		return nil
+	}
+	if a.accessControl == nil {
+		return ErrBundlePermissionsDenied.Errorf("no access control to authorize the permissions of %s", uid)
+	}
+	allowed, err := a.accessControl.Evaluate(ctx, user, evaluator)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrBundlePermissionsDenied.Errorf("user is not allowed to change the permissions of %s", uid)
+	}
+	current, err := permissions.GetPermissions(ctx, user, uid)
+	if err != nil {
+		return err
+	}
+	desired := make(map[string]string, len(templates))
+	commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(templates))
+	for _, t := range templates {
+		desired[t.Role] = t.Permission
+		commands = append(commands, accesscontrol.SetResourcePermissionCommand{BuiltinRole: t.Role, Permission: t.Permission})
+	}
+	for _, p := range current {
+		if p.IsManaged && !p.IsInherited && p.BuiltInRole != "" {
+			if _, ok := desired[p.BuiltInRole]; !ok {
+				desired[p.BuiltInRole] = ""
+				commands = append(commands, accesscontrol.SetResourcePermissionCommand{BuiltinRole: p.BuiltInRole})
+			}
+		}
+	}
+	_, err = permissions.SetPermissions(ctx, user.GetOrgID(), uid, commands...)
+	return err
+}
+
+func folderPermissionsWrite(uid string) accesscontrol.Evaluator {
+	return accesscontrol.EvalPermission(dashboards.ActionFoldersPermissionsWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(uid))
+}
+
+func dashboardPermissionsWrite(uid string) accesscontrol.Evaluator {
+	return accesscontrol.EvalPermission(dashboards.ActionDashboardsPermissionsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
+}
+
+// sortBundle validates the bundle and returns its items ordered so that every item comes after the
+// items of the bundle it depends on. References to objects that are not part of the bundle are
+// expected to exist already. Items without dependencies between them keep the order of the bundle.
+func sortBundle(bundle Bundle) ([]bundleItem, error) {
+	items := make([]bundleItem, 0, len(bundle.Folders)+len(bundle.LibraryPanels)+len(bundle.Dashboards))
+	for i := range bundle.Folders {
+		f := &bundle.Folders[i]
+		item := bundleItem{kind: BundleKindFolder, uid: f.UID, folder: f}
+		if f.Title == "" {
+			return nil, fmt.Errorf("folder %q is missing a title", f.UID)
+		}
+		if f.ParentUID != "" {
+			item.deps = append(item.deps, bundleRef{kind: BundleKindFolder, uid: f.ParentUID})
+		}
+		if err := validateBundlePermissions(f.Permissions); err != nil {
+			return nil, fmt.Errorf("folder %q: %w", f.UID, err)
+		}
+		items = append(items, item)
+	}
+	for i := range bundle.LibraryPanels {
+		p := &bundle.LibraryPanels[i]
+		item := bundleItem{kind: BundleKindLibraryPanel, uid: p.UID, libraryPanel: p}
+		if p.Name == "" {
+			return nil, fmt.Errorf("library panel %q is missing a name", p.UID)
+		}
+		if p.FolderUID != "" {
+			item.deps = append(item.deps, bundleRef{kind: BundleKindFolder, uid: p.FolderUID})
+		}
+		items = append(items, item)
+	}
+	for i := range bundle.Dashboards {
+		d := &bundle.Dashboards[i]
+		item := bundleItem{kind: BundleKindDashboard, uid: d.UID, dashboard: d}
+		if d.Spec == nil {
+			return nil, fmt.Errorf("dashboard %q is missing a spec", d.UID)
+		}
+		if d.FolderUID != "" {
+			item.deps = append(item.deps, bundleRef{kind: BundleKindFolder, uid: d.FolderUID})
+		}
+		for _, uid := range libraryPanelRefs(d.Spec) {
+			item.deps = append(item.deps, bundleRef{kind: BundleKindLibraryPanel, uid: uid})
+		}
+		if err := validateBundlePermissions(d.Permissions); err != nil {
+			return nil, fmt.Errorf("dashboard %q: %w", d.UID, err)
+		}
+		items = append(items, item)
+	}
+
+	index := make(map[bundleRef]int, len(items))
+	for i, item := range items {
+		if item.uid == "" {
+			return nil, fmt.Errorf("%s at position %d is missing a uid", item.kind, i)
+		}
+		ref := bundleRef{kind: item.kind, uid: item.uid}
+		if _, ok := index[ref]; ok {
+			return nil, fmt.Errorf("duplicate %s", ref)
+		}
+		index[ref] = i
+	}
+
+	// Kahn's algorithm, always picking the first ready item to keep the order stable.
+	dependents := make([][]int, len(items))
+	pending := make([]int, len(items))
+	for i, item := range items {
+		for _, dep := range item.deps {
+			j, ok := index[dep]
+			if !ok {
+				continue
+			}
+			if j == i {
+				return nil, fmt.Errorf("%s references itself", dep)
+			}
+			dependents[j] = append(dependents[j], i)
+			pending[i]++
+		}
+	}
+	ready := make([]int, 0, len(items))
+	for i := range items {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	sorted := make([]bundleItem, 0, len(items))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		i := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, items[i])
+		for _, j := range dependents[i] {
+			pending[j]--
+			if pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	if len(sorted) != len(items) {
+		for i, item := range items {
+			if pending[i] > 0 {
+				return nil, fmt.Errorf("%s is part of a dependency cycle", bundleRef{kind: item.kind, uid: item.uid})
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// validateBundlePermissions validates the permission templates as the permissions of the built-in roles of a dashboard
+func validateBundlePermissions(templates []BundlePermission) error {
+	seen := map[string]bool{}
+	for _, t := range templates {
+		if err := validateDashboardPermission(DashboardPermission{Role: t.Role, Permission: t.Permission}); err != nil {
+			return err
+		}
+		if seen[t.Role] {
+			return fmt.Errorf("duplicate permission for role:%s", t.Role)
+		}
+		seen[t.Role] = true
+	}
+	return nil
+}
+
+// RemapUIDs returns a copy of the bundle with the UIDs of its items, and the references to them, replaced as in the map,
+// e.g. to import a bundle next to the objects it was exported from. The UIDs that are not in the map are kept.
+func (b Bundle) RemapUIDs(uids map[string]string) (Bundle, error) {
+	remap := func(uid string) string {
+		if to, ok := uids[uid]; ok && uid != "" {
+			return to
+		}
+		return uid
+	}
+	out := Bundle{
+		Folders:       make([]BundleFolder, 0, len(b.Folders)),
+		LibraryPanels: make([]BundleLibraryPanel, 0, len(b.LibraryPanels)),
+		Dashboards:    make([]BundleDashboard, 0, len(b.Dashboards)),
+	}
+	for _, f := range b.Folders {
+		f.UID = remap(f.UID)
+		f.ParentUID = remap(f.ParentUID)
+		out.Folders = append(out.Folders, f)
+	}
+	for _, p := range b.LibraryPanels {
+		p.UID = remap(p.UID)
+		p.FolderUID = remap(p.FolderUID)
+		out.LibraryPanels = append(out.LibraryPanels, p)
+	}
+	for _, d := range b.Dashboards {
+		d.UID = remap(d.UID)
+		d.FolderUID = remap(d.FolderUID)
+		if d.Spec != nil {
+			// the panels are changed in place, so they are copied first
+			spec := map[string]any{}
+			raw, err := json.Marshal(d.Spec)
+			if err != nil {
+				return Bundle{}, err
+			}
+			if err := json.Unmarshal(raw, &spec); err != nil {
+				return Bundle{}, err
+			}
+			walkLibraryPanels(spec, func(lib map[string]any) {
+				if uid, ok := lib["uid"].(string); ok {
+					lib["uid"] = remap(uid)
+				}
+			})
+			d.Spec = spec
+		}
+		out.Dashboards = append(out.Dashboards, d)
+	}
+	return out, nil
+}
+
+// libraryPanelRefs returns the UIDs of the library panels used by the dashboard, including panels nested in rows.
+func libraryPanelRefs(spec map[string]any) []string {
+	var uids []string
+	seen := map[string]bool{}
+	walkLibraryPanels(spec, func(lib map[string]any) {
+		if uid, ok := lib["uid"].(string); ok && uid != "" && !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	})
+	return uids
+}
+
+// walkLibraryPanels calls fn with the library panel reference of every panel of the dashboard, including panels
+// nested in rows.
+func walkLibraryPanels(spec map[string]any, fn func(lib map[string]any)) {
+	var walk func(panels any)
+	walk = func(panels any) {
+		list, ok := panels.([]any)
+		if !ok {
+			return
+		}
+		for _, p := range list {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if lib, ok := panel["libraryPanel"].(map[string]any); ok {
+				fn(lib)
+			}
+			walk(panel["panels"])
+		}
+	}
+	walk(spec["panels"])
+}
@@ -0,0 +1,250 @@
+package dashboard
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	searchmodel "github.com/grafana/grafana/pkg/services/search/model"
+)
+
+const (
+	// maxBundleFolders is the maximum number of folders of an exported bundle
+	maxBundleFolders     = 1000
+	bundleExportPageSize = 1000
+
+	bundleTarContentType = "application/x-tar"
+
+	// the directories of the items in a tar bundle
+	bundleTarFolders       = "folders"
+	bundleTarLibraryPanels = "librarypanels"
+	bundleTarDashboards    = "dashboards"
+)
+
+// Export returns the folders and all their subfolders the user can view as a bundle, with the dashboards and library
+// panels in them and the permission templates of the folders and dashboards. The folders are exported as root
+// folders of the bundle, so it can be applied in another instance where their parents do not exist.
+func (a *BundleApplier) Export(ctx context.Context, user identity.Requester, folderUIDs []string) (*Bundle, error) {
+	if len(folderUIDs) == 0 {
+		return nil, ErrInvalidBundle.Errorf("missing folder")
+	}
+	roots := map[string]bool{}
+	queue := make([]*folder.Folder, 0, len(folderUIDs))
+	for _, uid := range folderUIDs {
+		if roots[uid] {
+			continue
+		}
+		f, err := a.folders.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: user.GetOrgID(), SignedInUser: user})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+				return nil, ErrInvalidBundle.Errorf("folder %q not found", uid)
+			}
+			return nil, err
+		}
+		roots[uid] = true
+		queue = append(queue, f)
+	}
+
+	bundle := &Bundle{}
+	seen := map[string]bool{}
+	uids := []string{}
+	for i := 0; i < len(queue); i++ {
+		f := queue[i]
+		if seen[f.UID] {
+			continue
+		}
+		seen[f.UID] = true
+		uids = append(uids, f.UID)
+		if len(uids) > maxBundleFolders {
+			return nil, ErrInvalidBundle.Errorf("the folders have more than %d subfolders, export a subfolder instead", maxBundleFolders)
+		}
+
+		item := BundleFolder{UID: f.UID, Title: f.Title, Description: f.Description, ParentUID: f.ParentUID}
+		if roots[f.UID] {
+			item.ParentUID = ""
+		}
+		permissions, err := a.permissionTemplates(ctx, user, a.folderPermissions, f.UID)
+		if err != nil {
+			return nil, err
+		}
+		item.Permissions = permissions
+		bundle.Folders = append(bundle.Folders, item)
+
+		children, err := a.folders.GetChildren(ctx, &folder.GetChildrenQuery{UID: f.UID, OrgID: user.GetOrgID(), SignedInUser: user})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderAccessDenied) {
+				continue
+			}
+			return nil, err
+		}
+		queue = append(queue, children...)
+	}
+
+	if err := a.exportLibraryPanels(ctx, user, uids, bundle); err != nil {
+		return nil, err
+	}
+	if err := a.exportDashboards(ctx, user, uids, bundle); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+func (a *BundleApplier) exportLibraryPanels(ctx context.Context, user identity.Requester, folderUIDs []string, bundle *Bundle) error {
+	for page := 1; ; page++ {
+		result, err := a.libraryElements.GetAllElements(ctx, user, model.SearchLibraryElementsQuery{
+			PerPage:          bundleExportPageSize,
+			Page:             page,
+			Kind:             int(model.PanelElement),
+			FolderFilterUIDs: strings.Join(folderUIDs, ","),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read the library panels: %w", err)
+		}
+		for _, e := range result.Elements {
+			m := map[string]any{}
+			if err := json.Unmarshal(e.Model, &m); err != nil {
+				return fmt.Errorf("invalid model of library panel %s: %w", e.UID, err)
+			}
+			bundle.LibraryPanels = append(bundle.LibraryPanels, BundleLibraryPanel{UID: e.UID, Name: e.Name, FolderUID: e.FolderUID, Model: m})
+		}
+		if len(result.Elements) < bundleExportPageSize {
+			return nil
+		}
+	}
+}
+
+func (a *BundleApplier) exportDashboards(ctx context.Context, user identity.Requester, folderUIDs []string, bundle *Bundle) error {
+	for page := int64(1); ; page++ {
+		hits, err := a.dashboards.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+			OrgId:        user.GetOrgID(),
+			SignedInUser: user,
+			Type:         string(searchmodel.DashHitDB),
+			FolderUIDs:   folderUIDs,
+			Limit:        bundleExportPageSize,
+			Page:         page,
+		})
+		if err != nil {
+			return err
+		}
+		for _, hit := range hits {
+			dash, err := a.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: hit.UID, OrgID: user.GetOrgID()})
+			if err != nil {
+				return fmt.Errorf("failed to load dashboard %s: %w", hit.UID, err)
+			}
+			spec := map[string]any{}
+			if dash.Data != nil {
+				spec, _ = simplejson.NewFromAny(dash.Data.Interface()).Interface().(map[string]any)
+			}
+			delete(spec, "id")
+			delete(spec, "version")
+			permissions, err := a.permissionTemplates(ctx, user, a.dashboardPermissions, dash.UID)
+			if err != nil {
+				return err
+			}
+			bundle.Dashboards = append(bundle.Dashboards, BundleDashboard{UID: dash.UID, FolderUID: dash.FolderUID, Spec: spec, Permissions: permissions})
+		}
+		if int64(len(hits)) < bundleExportPageSize {
+			return nil
+		}
+	}
+}
+
+// permissionTemplates returns the managed permissions of the built-in roles on the folder or dashboard, sorted by role
+func (a *BundleApplier) permissionTemplates(ctx context.Context, user identity.Requester, permissions accesscontrol.PermissionsService, uid string) ([]BundlePermission, error) {
+	if permissions == nil {
+		return nil, nil
+	}
+	current, err := permissions.GetPermissions(ctx, user, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the permissions of %s: %w", uid, err)
+	}
+	var templates []BundlePermission
+	for _, p := range current {
+		if p.IsManaged && !p.IsInherited && p.BuiltInRole != "" {
+			templates = append(templates, BundlePermission{Role: p.BuiltInRole, Permission: permissions.MapActions(p)})
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Role < templates[j].Role })
+	return templates, nil
+}
+
+// WriteBundleTar writes the bundle as a tar archive with a JSON file per item, in the folders, librarypanels and
+// dashboards directories. The items are written in the order of the bundle.
+func WriteBundleTar(w io.Writer, bundle Bundle) error {
+	tw := tar.NewWriter(w)
+	write := func(dir string, uid string, item any) error {
+		raw, err := json.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: path.Join(dir, uid+".json"), Mode: 0o644, Size: int64(len(raw))}); err != nil {
+			return err
+		}
+		_, err = tw.Write(raw)
+		return err
+	}
+	for _, f := range bundle.Folders {
+		if err := write(bundleTarFolders, f.UID, f); err != nil {
+			return err
+		}
+	}
+	for _, p := range bundle.LibraryPanels {
+		if err := write(bundleTarLibraryPanels, p.UID, p); err != nil {
+			return err
+		}
+	}
+	for _, d := range bundle.Dashboards {
+		if err := write(bundleTarDashboards, d.UID, d); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// ReadBundleTar reads a bundle written by WriteBundleTar. The files outside of the item directories are ignored.
+func ReadBundleTar(r io.Reader) (Bundle, error) {
+	bundle := Bundle{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return bundle, nil
+		}
+		if err != nil {
+			return Bundle{}, err
+		}
+		if hdr.Typeflag != tar.TypeReg || path.Ext(hdr.Name) != ".json" {
+			continue
+		}
+		dec := json.NewDecoder(tr)
+		switch path.Dir(path.Clean(hdr.Name)) {
+		case bundleTarFolders:
+			item := BundleFolder{}
+			err = dec.Decode(&item)
+			bundle.Folders = append(bundle.Folders, item)
+		case bundleTarLibraryPanels:
+			item := BundleLibraryPanel{}
+			err = dec.Decode(&item)
+			bundle.LibraryPanels = append(bundle.LibraryPanels, item)
+		case bundleTarDashboards:
+			item := BundleDashboard{}
+			err = dec.Decode(&item)
+			bundle.Dashboards = append(bundle.Dashboards, item)
+		}
+		if err != nil {
+			return Bundle{}, fmt.Errorf("invalid file %s: %w", hdr.Name, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	dashboardv1alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v1alpha1"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+)
+
+// bundleBody is a bundle, or a list of resources in the shape of the API, e.g. the files of a GitOps repository
+type bundleBody struct {
+	Bundle
+	Kind  string           `json:"kind"`
+	Items []bundleResource `json:"items"`
+}
+
+type bundleResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name        string            `json:"name"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+	} `json:"metadata"`
+	Spec map[string]any `json:"spec"`
+}
+
+// DecodeBundle reads a bundle, or a list of Folder, LibraryPanel and Dashboard resources as returned by the API
+// applied as a bundle. The name of a resource is its UID, and its grafana.app/folder annotation is the UID of its
+// folder. The dashboards are read as v0alpha1 or v1alpha1, their spec is the dashboard JSON.
+func DecodeBundle(r io.Reader) (Bundle, error) {
+	body := bundleBody{}
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return Bundle{}, err
+	}
+	if body.Kind != "List" {
+		return body.Bundle, nil
+	}
+	return readBundleResources(body.Items)
+}
+
+// readBundleResources maps the resources to the items of a bundle, in the order of the resources
+func readBundleResources(resources []bundleResource) (Bundle, error) {
+	bundle := Bundle{}
+	for i, res := range resources {
+		gv, err := schema.ParseGroupVersion(res.APIVersion)
+		if err != nil {
+			return Bundle{}, fmt.Errorf("resource at position %d: %w", i, err)
+		}
+		uid := res.Metadata.Name
+		folderUID := res.Metadata.Annotations[utils.AnnoKeyFolder]
+		switch {
+		case gv.Group == folderv0alpha1.GROUP && res.Kind == folderv0alpha1.FolderResourceInfo.GroupVersionKind().Kind:
+			title, _ := res.Spec["title"].(string)
+			description, _ := res.Spec["description"].(string)
+			bundle.Folders = append(bundle.Folders, BundleFolder{UID: uid, Title: title, Description: description, ParentUID: folderUID})
+		case gv.Group == dashboardv0alpha1.GROUP && res.Kind == dashboardv0alpha1.LibraryPanelResourceInfo.GroupVersionKind().Kind:
+			name, _ := res.Spec["title"].(string)
+			bundle.LibraryPanels = append(bundle.LibraryPanels, BundleLibraryPanel{UID: uid, Name: name, FolderUID: folderUID, Model: res.Spec})
+		case gv.Group == dashboardv0alpha1.GROUP && res.Kind == dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind().Kind:
+			if gv.Version != dashboardv0alpha1.VERSION && gv.Version != dashboardv1alpha1.VERSION {
+				return Bundle{}, fmt.Errorf("dashboard %q: version %s is not supported, expected %s or %s", uid, gv.Version, dashboardv0alpha1.VERSION, dashboardv1alpha1.VERSION)
+			}
+			bundle.Dashboards = append(bundle.Dashboards, BundleDashboard{UID: uid, FolderUID: folderUID, Spec: res.Spec})
+		default:
+			return Bundle{}, fmt.Errorf("resource at position %d: unsupported %s %s, expected a Folder, LibraryPanel or Dashboard", i, res.APIVersion, res.Kind)
+		}
+	}
+	return bundle, nil
+}
@@ -0,0 +1,345 @@
+package dashboard
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSortBundle(t *testing.T) {
+	refs := func(items []bundleItem) []string {
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			out = append(out, bundleRef{kind: item.kind, uid: item.uid}.String())
+		}
+		return out
+	}
+
+	t.Run("orders items after their dependencies", func(t *testing.T) {
+		items, err := sortBundle(Bundle{
+			Dashboards: []BundleDashboard{{
+				UID:       "dash",
+				FolderUID: "child",
+				Spec: map[string]any{
+					"panels": []any{
+						map[string]any{"type": "row", "panels": []any{
+							map[string]any{"libraryPanel": map[string]any{"uid": "lib"}},
+						}},
+					},
+				},
+			}},
+			LibraryPanels: []BundleLibraryPanel{{UID: "lib", Name: "lib", FolderUID: "parent"}},
+			Folders: []BundleFolder{
+				{UID: "child", Title: "child", ParentUID: "parent"},
+				{UID: "parent", Title: "parent"},
+				{UID: "other", Title: "other", ParentUID: "existing"},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"Folder/parent",
+			"Folder/child",
+			"Folder/other",
+			"LibraryPanel/lib",
+			"Dashboard/dash",
+		}, refs(items))
+	})
+
+	t.Run("fails on duplicates", func(t *testing.T) {
+		_, err := sortBundle(Bundle{Folders: []BundleFolder{{UID: "a", Title: "a"}, {UID: "a", Title: "b"}}})
+		require.ErrorContains(t, err, "duplicate Folder/a")
+	})
+
+	t.Run("fails on cycles", func(t *testing.T) {
+		_, err := sortBundle(Bundle{Folders: []BundleFolder{
+			{UID: "a", Title: "a", ParentUID: "b"},
+			{UID: "b", Title: "b", ParentUID: "a"},
+		}})
+		require.ErrorContains(t, err, "dependency cycle")
+	})
+
+	t.Run("fails on missing fields", func(t *testing.T) {
+		_, err := sortBundle(Bundle{Dashboards: []BundleDashboard{{Spec: map[string]any{}}}})
+		require.ErrorContains(t, err, "missing a uid")
+
+		_, err = sortBundle(Bundle{LibraryPanels: []BundleLibraryPanel{{UID: "lib"}}})
+		require.ErrorContains(t, err, "missing a name")
+	})
+
+	t.Run("fails on invalid permission templates", func(t *testing.T) {
+		_, err := sortBundle(Bundle{Folders: []BundleFolder{{UID: "a", Title: "a", Permissions: []BundlePermission{{Role: "Editor", Permission: "Write"}}}}})
+		require.ErrorContains(t, err, `folder "a": invalid permission "Write"`)
+
+		_, err = sortBundle(Bundle{Dashboards: []BundleDashboard{{UID: "d", Spec: map[string]any{}, Permissions: []BundlePermission{
+			{Role: "Viewer", Permission: "View"},
+			{Role: "Viewer", Permission: "Edit"},
+		}}}})
+		require.ErrorContains(t, err, "duplicate permission for role:Viewer")
+	})
+}
+
+func TestBundleRemapUIDs(t *testing.T) {
+	bundle := Bundle{
+		Folders: []BundleFolder{
+			{UID: "parent", Title: "parent", Permissions: []BundlePermission{{Role: "Viewer", Permission: "View"}}},
+			{UID: "child", Title: "child", ParentUID: "parent"},
+		},
+		LibraryPanels: []BundleLibraryPanel{{UID: "lib", Name: "lib", FolderUID: "child"}},
+		Dashboards: []BundleDashboard{{
+			UID:       "dash",
+			FolderUID: "child",
+			Spec: map[string]any{
+				"title": "dash",
+				"panels": []any{
+					map[string]any{"type": "row", "panels": []any{
+						map[string]any{"libraryPanel": map[string]any{"uid": "lib", "name": "lib"}},
+					}},
+					map[string]any{"libraryPanel": map[string]any{"uid": "external"}},
+				},
+			},
+		}},
+	}
+
+	remapped, err := bundle.RemapUIDs(map[string]string{"parent": "prod-parent", "lib": "prod-lib", "dash": "prod-dash"})
+	require.NoError(t, err)
+	require.Equal(t, Bundle{
+		Folders: []BundleFolder{
+			{UID: "prod-parent", Title: "parent", Permissions: []BundlePermission{{Role: "Viewer", Permission: "View"}}},
+			{UID: "child", Title: "child", ParentUID: "prod-parent"},
+		},
+		LibraryPanels: []BundleLibraryPanel{{UID: "prod-lib", Name: "lib", FolderUID: "child"}},
+		Dashboards: []BundleDashboard{{
+			UID:       "prod-dash",
+			FolderUID: "child",
+			Spec: map[string]any{
+				"title": "dash",
+				"panels": []any{
+					map[string]any{"type": "row", "panels": []any{
+						map[string]any{"libraryPanel": map[string]any{"uid": "prod-lib", "name": "lib"}},
+					}},
+					map[string]any{"libraryPanel": map[string]any{"uid": "external"}},
+				},
+			},
+		}},
+	}, remapped)
+	require.Equal(t, []string{"lib", "external"}, libraryPanelRefs(bundle.Dashboards[0].Spec), "the bundle is not changed")
+}
+
+func TestDecodeBundle(t *testing.T) {
+	t.Run("reads a bundle", func(t *testing.T) {
+		bundle, err := DecodeBundle(strings.NewReader(`{"folders":[{"uid":"f","title":"Folder"}]}`))
+		require.NoError(t, err)
+		require.Equal(t, Bundle{Folders: []BundleFolder{{UID: "f", Title: "Folder"}}}, bundle)
+	})
+
+	t.Run("reads a list of resources", func(t *testing.T) {
+		bundle, err := DecodeBundle(strings.NewReader(`{
+			"apiVersion": "v1",
+			"kind": "List",
+			"items": [
+				{
+					"apiVersion": "dashboard.grafana.app/v0alpha1",
+					"kind": "Dashboard",
+					"metadata": {"name": "dash", "annotations": {"grafana.app/folder": "f"}},
+					"spec": {"title": "Dash", "panels": [{"libraryPanel": {"uid": "lib"}}]}
+				},
+				{
+					"apiVersion": "dashboard.grafana.app/v0alpha1",
+					"kind": "LibraryPanel",
+					"metadata": {"name": "lib", "annotations": {"grafana.app/folder": "f"}},
+					"spec": {"title": "CPU", "type": "timeseries"}
+				},
+				{
+					"apiVersion": "folder.grafana.app/v0alpha1",
+					"kind": "Folder",
+					"metadata": {"name": "f", "annotations": {"grafana.app/folder": "parent"}},
+					"spec": {"title": "Folder", "description": "desc"}
+				}
+			]
+		}`))
+		require.NoError(t, err)
+		require.Equal(t, Bundle{
+			Folders:       []BundleFolder{{UID: "f", Title: "Folder", Description: "desc", ParentUID: "parent"}},
+			LibraryPanels: []BundleLibraryPanel{{UID: "lib", Name: "CPU", FolderUID: "f", Model: map[string]any{"title": "CPU", "type": "timeseries"}}},
+			Dashboards: []BundleDashboard{{UID: "dash", FolderUID: "f", Spec: map[string]any{
+				"title":  "Dash",
+				"panels": []any{map[string]any{"libraryPanel": map[string]any{"uid": "lib"}}},
+			}}},
+		}, bundle)
+
+		items, err := sortBundle(bundle)
+		require.NoError(t, err)
+		require.Equal(t, BundleKindDashboard, items[2].kind, "the dashboard is applied after its library panel")
+	})
+
+	t.Run("fails on unsupported resources", func(t *testing.T) {
+		_, err := DecodeBundle(strings.NewReader(`{"kind":"List","items":[{"apiVersion":"dashboard.grafana.app/v2alpha1","kind":"Dashboard","metadata":{"name":"d"},"spec":{}}]}`))
+		require.ErrorContains(t, err, `dashboard "d": version v2alpha1 is not supported`)
+
+		_, err = DecodeBundle(strings.NewReader(`{"kind":"List","items":[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c"}}]}`))
+		require.ErrorContains(t, err, "unsupported v1 ConfigMap")
+	})
+}
+
+func TestReadBundle(t *testing.T) {
+	a := &BundleApplier{maxBundleSize: 64}
+	read := func(body string, contentType string) (Bundle, error) {
+		r := httptest.NewRequest(http.MethodPost, "/bundle/apply", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		return a.readBundle(httptest.NewRecorder(), r)
+	}
+
+	bundle, err := read(`{"folders":[{"uid":"f","title":"Folder"}]}`, "application/json")
+	require.NoError(t, err)
+	require.Equal(t, Bundle{Folders: []BundleFolder{{UID: "f", Title: "Folder"}}}, bundle)
+
+	_, err = read(`{"folders":[{"uid":"f","title":"`+strings.Repeat("x", 64)+`"}]}`, "application/json")
+	maxBytesErr := &http.MaxBytesError{}
+	require.ErrorAs(t, err, &maxBytesErr, "the bodies larger than the maximum size are rejected")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteBundleTar(buf, Bundle{Folders: []BundleFolder{{UID: "f", Title: "Folder"}}}))
+	_, err = read(buf.String(), bundleTarContentType)
+	require.ErrorAs(t, err, &maxBytesErr, "the archives are limited too")
+}
+
+func TestBundleTar(t *testing.T) {
+	bundle := Bundle{
+		Folders: []BundleFolder{
+			{UID: "parent", Title: "parent", Permissions: []BundlePermission{{Role: "Editor", Permission: "Edit"}}},
+			{UID: "child", Title: "child", ParentUID: "parent"},
+		},
+		LibraryPanels: []BundleLibraryPanel{{UID: "lib", Name: "lib", FolderUID: "child", Model: map[string]any{"type": "timeseries"}}},
+		Dashboards:    []BundleDashboard{{UID: "dash", FolderUID: "child", Spec: map[string]any{"title": "dash"}}},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteBundleTar(buf, bundle))
+
+	names := []string{}
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	require.Equal(t, []string{"folders/parent.json", "folders/child.json", "librarypanels/lib.json", "dashboards/dash.json"}, names)
+
+	read, err := ReadBundleTar(buf)
+	require.NoError(t, err)
+	require.Equal(t, bundle, read)
+}
+
+// transactionDB runs the transactions of the bundles without a database
+type transactionDB struct {
+	db.DB
+}
+
+func (transactionDB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestBundleApply(t *testing.T) {
+	user := &identity.StaticRequester{OrgID: 1}
+	bundle := Bundle{
+		Folders:    []BundleFolder{{UID: "f1", Title: "Folder"}},
+		Dashboards: []BundleDashboard{{UID: "d1", FolderUID: "f1", Spec: map[string]any{"title": "Dashboard"}}},
+	}
+
+	t.Run("a failed item fails the bundle", func(t *testing.T) {
+		folders := foldertest.NewFakeService()
+		folders.ExpectedError = errors.New("database is locked")
+		a := NewBundleApplier(transactionDB{}, featuremgmt.WithFeatures(), setting.NewCfg(), folders, nil, nil, nil, nil, nil)
+
+		result, err := a.Apply(context.Background(), user, bundle, false)
+		require.ErrorIs(t, err, ErrBundleNotApplied)
+		require.ErrorContains(t, err, "database is locked")
+		require.False(t, result.Applied)
+		require.Equal(t, []BundleItemResult{
+			{Kind: BundleKindFolder, UID: "f1", Action: BundleActionFailed, Error: "database is locked"},
+			{Kind: BundleKindDashboard, UID: "d1", Action: BundleActionSkipped},
+		}, result.Items)
+	})
+
+	t.Run("rejects the bundle when the kubernetes folders are enabled", func(t *testing.T) {
+		a := NewBundleApplier(transactionDB{}, featuremgmt.WithFeatures(featuremgmt.FlagKubernetesFolders), setting.NewCfg(), foldertest.NewFakeService(), nil, nil, nil, nil, nil)
+		result, err := a.Apply(context.Background(), user, bundle, false)
+		require.ErrorIs(t, err, ErrBundleNotAtomic)
+		require.Nil(t, result)
+	})
+
+	t.Run("rejects the bundle when the dashboards are written to unified storage", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.UnifiedStorage = map[string]setting.UnifiedStorageConfig{
+			"dashboards.dashboard.grafana.app": {DualWriterMode: grafanarest.Mode2},
+		}
+		a := NewBundleApplier(transactionDB{}, featuremgmt.WithFeatures(), cfg, foldertest.NewFakeService(), nil, nil, nil, nil, nil)
+		_, err := a.Apply(context.Background(), user, bundle, false)
+		require.ErrorIs(t, err, ErrBundleNotAtomic)
+		require.ErrorContains(t, err, "dashboards are written to unified storage")
+	})
+}
+
+func TestBundleApplyPermissions(t *testing.T) {
+	ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	editor := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		dashboards.ActionFoldersRead:     {dashboards.ScopeFoldersAll},
+		dashboards.ActionFoldersWrite:    {dashboards.ScopeFoldersAll},
+		dashboards.ActionDashboardsWrite: {dashboards.ScopeFoldersAll},
+	}}}
+	admin := &identity.StaticRequester{Type: claims.TypeUser, UserID: 2, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		dashboards.ActionFoldersRead:             {dashboards.ScopeFoldersAll},
+		dashboards.ActionFoldersWrite:            {dashboards.ScopeFoldersAll},
+		dashboards.ActionFoldersPermissionsWrite: {dashboards.ScopeFoldersProvider.GetResourceScopeUID("f1")},
+	}}}
+	body := `{"folders": [{"uid": "f1", "title": "Folder", "permissions": [{"role": "Viewer", "permission": "Admin"}]}]}`
+	apply := func(user identity.Requester) (*httptest.ResponseRecorder, *recordingPermissionsService) {
+		folders := foldertest.NewFakeService()
+		folders.ExpectedFolder = &folder.Folder{UID: "f1", Title: "Folder"}
+		permissions := &recordingPermissionsService{}
+		a := NewBundleApplier(transactionDB{}, featuremgmt.WithFeatures(), setting.NewCfg(), folders, nil, nil, ac, permissions, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/bundle/apply", strings.NewReader(body))
+		req = mux.SetURLVars(req.WithContext(identity.WithRequester(req.Context(), user)), map[string]string{"namespace": "default"})
+		rec := httptest.NewRecorder()
+		a.handleApply(rec, req)
+		return rec, permissions
+	}
+
+	t.Run("fails the bundle when the user can not change the permissions of an item", func(t *testing.T) {
+		rec, permissions := apply(editor)
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"action":"failed"`)
+		require.Contains(t, rec.Body.String(), "not allowed to change the permissions of f1")
+		require.Empty(t, permissions.set)
+	})
+
+	t.Run("applies the permissions the user can change", func(t *testing.T) {
+		rec, permissions := apply(admin)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, []recordedPermissions{{orgID: 1, uid: "f1", commands: []accesscontrol.SetResourcePermissionCommand{{BuiltinRole: "Viewer", Permission: "Admin"}}}}, permissions.set)
+	})
+}
@@ -0,0 +1,338 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+const (
+	CopyRefDatasource   = "Datasource"
+	CopyRefLibraryPanel = "LibraryPanel"
+)
+
+var (
+	// ErrInvalidCopy is returned when a copy request cannot be applied because of its content.
+	ErrInvalidCopy = errutil.BadRequest("dashboards.copy.invalid")
+	// ErrCopyAccessDenied is returned when a user that is not a server admin copies a dashboard to another org.
+	ErrCopyAccessDenied = errutil.Forbidden("dashboards.copy.forbidden", errutil.WithPublicMessage("Only server admins can copy dashboards to another org"))
+)
+
+// builtinDatasources exist in every org, their references are never rewritten
+var builtinDatasources = map[string]bool{
+	"grafana":          true,
+	"-- Grafana --":    true,
+	"-- Mixed --":      true,
+	"-- Dashboard --":  true,
+	expr.DatasourceUID: true,
+}
+
+// CopyRequest copies a dashboard of the namespace of the request to another namespace.
+type CopyRequest struct {
+	DashboardUID    string `json:"dashboardUid"`
+	TargetNamespace string `json:"targetNamespace"`
+	// FolderUID is the folder of the copy in the target namespace, the root when empty
+	FolderUID string `json:"folderUid,omitempty"`
+	// UID of the copy, defaults to the UID of the dashboard
+	UID       string `json:"uid,omitempty"`
+	Overwrite bool   `json:"overwrite,omitempty"`
+	// LibraryPanels copies the library panels used by the dashboard that do not exist in the target namespace
+	LibraryPanels bool `json:"libraryPanels,omitempty"`
+	// Datasources maps the UIDs, or the names of the references by name, of the data sources of the dashboard
+	// to the UIDs of data sources of the target namespace
+	Datasources map[string]string `json:"datasources,omitempty"`
+}
+
+// UnresolvedReference is a reference of the copy to an object that does not exist in the target namespace.
+type UnresolvedReference struct {
+	Kind string `json:"kind"`
+	Ref  string `json:"ref"`
+}
+
+// CopyResult is the outcome of a copy. The copy is saved even with unresolved references, they must be fixed in the target namespace.
+type CopyResult struct {
+	UID           string                `json:"uid"`
+	Namespace     string                `json:"namespace"`
+	LibraryPanels []string              `json:"libraryPanels,omitempty"`
+	Unresolved    []UnresolvedReference `json:"unresolved"`
+}
+
+// DashboardCopier copies dashboards between orgs with the legacy services. The copy is saved, and its library panels
+// are created, inside a single database transaction.
+type DashboardCopier struct {
+	db              db.DB
+	folders         folder.Service
+	dashboards      dashboards.DashboardService
+	datasources     datasources.DataSourceService
+	libraryElements libraryelements.Service
+	log             log.Logger
+}
+
+func NewDashboardCopier(sql db.DB, folders folder.Service, dashboardService dashboards.DashboardService, datasourceService datasources.DataSourceService, libraryElements libraryelements.Service) *DashboardCopier {
+	return &DashboardCopier{
+		db:              sql,
+		folders:         folders,
+		dashboards:      dashboardService,
+		datasources:     datasourceService,
+		libraryElements: libraryElements,
+		log:             log.New("dashboard.copy"),
+	}
+}
+
+// Copy saves a copy of the dashboard in the org of the target namespace. The data source references are rewritten with
+// the mapping of the request, the references that are not mapped are kept when the data source exists in the target org.
+// Copying to another org requires the server admin role, the copy is saved as an admin of the target org.
+func (c *DashboardCopier) Copy(ctx context.Context, user identity.Requester, req CopyRequest) (*CopyResult, error) {
+	if !user.GetIsGrafanaAdmin() {
+		return nil, ErrCopyAccessDenied.Errorf("user is not a server admin")
+	}
+	if req.DashboardUID == "" {
+		return nil, ErrInvalidCopy.Errorf("missing dashboard uid")
+	}
+	target, err := claims.ParseNamespace(req.TargetNamespace)
+	if err != nil {
+		return nil, ErrInvalidCopy.Errorf("invalid target namespace: %w", err)
+	}
+	if target.OrgID < 1 {
+		return nil, ErrInvalidCopy.Errorf("the target namespace %q is not an org", req.TargetNamespace)
+	}
+	targetUser := copyRequester(user, target)
+
+	dash, err := c.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: req.DashboardUID, OrgID: user.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, ErrInvalidCopy.Errorf("dashboard %q not found", req.DashboardUID)
+		}
+		return nil, err
+	}
+	if req.FolderUID != "" {
+		_, err := c.folders.Get(ctx, &folder.GetFolderQuery{UID: &req.FolderUID, OrgID: target.OrgID, SignedInUser: targetUser})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+				return nil, ErrInvalidCopy.Errorf("folder %q not found in %s", req.FolderUID, req.TargetNamespace)
+			}
+			return nil, err
+		}
+	}
+
+	spec := map[string]any{}
+	if dash.Data != nil {
+		spec, _ = simplejson.NewFromAny(dash.Data.Interface()).Interface().(map[string]any)
+	}
+	mapped, err := c.resolveDatasources(ctx, target.OrgID, req.Datasources)
+	if err != nil {
+		return nil, err
+	}
+	unresolved, err := c.rewriteDatasources(ctx, target.OrgID, spec, mapped)
+	if err != nil {
+		return nil, err
+	}
+
+	uid := req.UID
+	if uid == "" {
+		uid = dash.UID
+	}
+	result := &CopyResult{UID: uid, Namespace: req.TargetNamespace}
+	err = c.db.InTransaction(ctx, func(ctx context.Context) error {
+		panels := libraryPanelRefs(spec)
+		for _, p := range panels {
+			copied, missing, err := c.copyLibraryPanel(ctx, user, targetUser, p, req)
+			if err != nil {
+				return err
+			}
+			if copied {
+				result.LibraryPanels = append(result.LibraryPanels, p)
+			}
+			if missing {
+				unresolved = append(unresolved, UnresolvedReference{Kind: CopyRefLibraryPanel, Ref: p})
+			}
+		}
+
+		data := simplejson.NewFromAny(spec)
+		data.Set("uid", uid)
+		data.Del("id")
+		data.Del("version")
+		copied := dashboards.NewDashboardFromJson(data)
+		copied.OrgID = target.OrgID
+		copied.FolderUID = req.FolderUID
+		saved, err := c.dashboards.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+			OrgID:     target.OrgID,
+			User:      targetUser,
+			Message:   fmt.Sprintf("copied from %s", user.GetNamespace()),
+			Overwrite: req.Overwrite,
+			Dashboard: copied,
+		}, false)
+		if err != nil {
+			return err
+		}
+		if len(panels) > 0 {
+			return c.libraryElements.ConnectElementsToDashboard(ctx, targetUser, panels, saved.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, importError(err)
+	}
+
+	sort.SliceStable(unresolved, func(i, j int) bool {
+		if unresolved[i].Kind != unresolved[j].Kind {
+			return unresolved[i].Kind < unresolved[j].Kind
+		}
+		return unresolved[i].Ref < unresolved[j].Ref
+	})
+	result.Unresolved = unresolved
+	return result, nil
+}
+
+// copyRequester is the server admin acting as an admin of the target org
+func copyRequester(user identity.Requester, target claims.NamespaceInfo) identity.Requester {
+	userID, _ := identity.UserIdentifier(user.GetID())
+	return &identity.StaticRequester{
+		Type:           user.GetIdentityType(),
+		UserID:         userID,
+		UserUID:        user.GetUID(),
+		OrgID:          target.OrgID,
+		OrgRole:        identity.RoleAdmin,
+		Login:          user.GetLogin(),
+		Name:           user.GetName(),
+		Namespace:      target.Value,
+		IsGrafanaAdmin: true,
+		Permissions: map[int64]map[string][]string{
+			target.OrgID: {"*": {"*"}},
+		},
+	}
+}
+
+// resolveDatasources reads the data sources the mapping of the request points to, they must exist in the target org
+func (c *DashboardCopier) resolveDatasources(ctx context.Context, orgID int64, mapping map[string]string) (map[string]*datasources.DataSource, error) {
+	resolved := make(map[string]*datasources.DataSource, len(mapping))
+	for from, to := range mapping {
+		ds, err := c.datasources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: to, OrgID: orgID})
+		if err != nil {
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return nil, ErrInvalidCopy.Errorf("data source %q mapped from %q not found in the target org", to, from)
+			}
+			return nil, err
+		}
+		resolved[from] = ds
+	}
+	return resolved, nil
+}
+
+// rewriteDatasources replaces the data source references of the spec with the mapped data sources, and returns the
+// references that are neither mapped nor exist in the target org. References to variables are kept.
+func (c *DashboardCopier) rewriteDatasources(ctx context.Context, orgID int64, spec map[string]any, mapped map[string]*datasources.DataSource) ([]UnresolvedReference, error) {
+	checked := map[string]bool{}
+	unresolved := []UnresolvedReference{}
+	var walkErr error
+	exists := func(ref string, byName bool) {
+		if _, ok := checked[ref]; ok || walkErr != nil {
+			return
+		}
+		q := &datasources.GetDataSourceQuery{UID: ref, OrgID: orgID}
+		if byName {
+			q = &datasources.GetDataSourceQuery{Name: ref, OrgID: orgID}
+		}
+		_, err := c.datasources.GetDataSource(ctx, q)
+		switch {
+		case err == nil:
+			checked[ref] = true
+		case errors.Is(err, datasources.ErrDataSourceNotFound):
+			checked[ref] = false
+			unresolved = append(unresolved, UnresolvedReference{Kind: CopyRefDatasource, Ref: ref})
+		default:
+			walkErr = err
+		}
+	}
+
+	rewriteDatasourceRefs(spec, func(ref string, byName bool) any {
+		if ds, ok := mapped[ref]; ok {
+			return map[string]any{"type": ds.Type, "uid": ds.UID}
+		}
+		exists(ref, byName)
+		return nil
+	})
+	return unresolved, walkErr
+}
+
+// rewriteDatasourceRefs calls rewrite with every data source reference of the spec, by UID or by name for the
+// references of old dashboards, and replaces the reference with the returned value unless it is nil.
+// Built-in data sources and variables are skipped.
+func rewriteDatasourceRefs(v any, rewrite func(ref string, byName bool) any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if k != "datasource" {
+				rewriteDatasourceRefs(child, rewrite)
+				continue
+			}
+			var ref string
+			byName := false
+			switch ds := child.(type) {
+			case string:
+				ref, byName = ds, true
+			case map[string]any:
+				ref, _ = ds["uid"].(string)
+			}
+			if ref == "" || builtinDatasources[ref] || strings.HasPrefix(ref, "$") {
+				continue
+			}
+			if replaced := rewrite(ref, byName); replaced != nil {
+				v[k] = replaced
+			}
+		}
+	case []any:
+		for _, child := range v {
+			rewriteDatasourceRefs(child, rewrite)
+		}
+	}
+}
+
+// copyLibraryPanel creates the library panel in the target org when it is missing and the request copies library panels.
+// It returns whether the panel was copied, and whether it is still missing in the target org.
+func (c *DashboardCopier) copyLibraryPanel(ctx context.Context, user identity.Requester, targetUser identity.Requester, uid string, req CopyRequest) (bool, bool, error) {
+	_, err := c.libraryElements.GetElement(ctx, targetUser, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err == nil {
+		return false, false, nil
+	}
+	if !errors.Is(err, model.ErrLibraryElementNotFound) {
+		return false, false, err
+	}
+	if !req.LibraryPanels {
+		return false, true, nil
+	}
+
+	source, err := c.libraryElements.GetElement(ctx, user, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		if errors.Is(err, model.ErrLibraryElementNotFound) {
+			return false, true, nil
+		}
+		return false, false, err
+	}
+	_, err = c.libraryElements.CreateElement(ctx, targetUser, model.CreateLibraryElementCommand{
+		FolderUID: &req.FolderUID,
+		Name:      source.Name,
+		Model:     source.Model,
+		Kind:      source.Kind,
+		UID:       uid,
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return true, false, nil
+}
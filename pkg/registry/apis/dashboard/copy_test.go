@@ -0,0 +1,87 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+)
+
+func TestDashboardCopier(t *testing.T) {
+	// the data sources of the target org
+	ds := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "p2", Name: "Prometheus", OrgID: 2, Type: "prometheus"},
+		{UID: "l2", Name: "Loki", OrgID: 2, Type: "loki"},
+	}}
+	copier := &DashboardCopier{datasources: ds}
+
+	t.Run("requires a server admin", func(t *testing.T) {
+		user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, OrgRole: identity.RoleAdmin}
+		_, err := copier.Copy(context.Background(), user, CopyRequest{DashboardUID: "abc", TargetNamespace: "org-2"})
+		require.ErrorIs(t, err, ErrCopyAccessDenied)
+	})
+
+	t.Run("validates the request", func(t *testing.T) {
+		admin := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, IsGrafanaAdmin: true}
+		_, err := copier.Copy(context.Background(), admin, CopyRequest{TargetNamespace: "org-2"})
+		require.ErrorContains(t, err, "missing dashboard uid")
+
+		_, err = copier.Copy(context.Background(), admin, CopyRequest{DashboardUID: "abc", TargetNamespace: "stacks-x"})
+		require.ErrorIs(t, err, ErrInvalidCopy)
+	})
+
+	t.Run("mapped data sources must exist in the target org", func(t *testing.T) {
+		mapped, err := copier.resolveDatasources(context.Background(), 2, map[string]string{"p1": "p2"})
+		require.NoError(t, err)
+		require.Equal(t, "p2", mapped["p1"].UID)
+
+		_, err = copier.resolveDatasources(context.Background(), 2, map[string]string{"p1": "missing"})
+		require.ErrorIs(t, err, ErrInvalidCopy)
+	})
+
+	t.Run("rewrites the data source references", func(t *testing.T) {
+		spec := map[string]any{
+			"panels": []any{
+				map[string]any{
+					"datasource": map[string]any{"uid": "p1", "type": "prometheus"},
+					"targets": []any{
+						map[string]any{"refId": "A", "datasource": map[string]any{"uid": "p1"}},
+						map[string]any{"refId": "B", "datasource": map[string]any{"uid": "__expr__"}},
+					},
+				},
+				map[string]any{"type": "row", "panels": []any{
+					map[string]any{"datasource": "Old prometheus"},
+					map[string]any{"datasource": map[string]any{"uid": "l2"}},
+					map[string]any{"datasource": map[string]any{"uid": "${ds}"}},
+					map[string]any{"datasource": map[string]any{"uid": "e1"}},
+				}},
+			},
+			"templating": map[string]any{"list": []any{
+				map[string]any{"name": "job", "datasource": map[string]any{"uid": "e1"}},
+			}},
+		}
+		mapped, err := copier.resolveDatasources(context.Background(), 2, map[string]string{"p1": "p2", "Old prometheus": "p2"})
+		require.NoError(t, err)
+
+		unresolved, err := copier.rewriteDatasources(context.Background(), 2, spec, mapped)
+		require.NoError(t, err)
+		require.Equal(t, []UnresolvedReference{{Kind: CopyRefDatasource, Ref: "e1"}}, unresolved, "reported once")
+
+		panels := spec["panels"].([]any)
+		first := panels[0].(map[string]any)
+		require.Equal(t, map[string]any{"uid": "p2", "type": "prometheus"}, first["datasource"])
+		targets := first["targets"].([]any)
+		require.Equal(t, map[string]any{"uid": "p2", "type": "prometheus"}, targets[0].(map[string]any)["datasource"])
+		require.Equal(t, map[string]any{"uid": "__expr__"}, targets[1].(map[string]any)["datasource"])
+
+		nested := panels[1].(map[string]any)["panels"].([]any)
+		require.Equal(t, map[string]any{"uid": "p2", "type": "prometheus"}, nested[0].(map[string]any)["datasource"], "references by name become references by uid")
+		require.Equal(t, map[string]any{"uid": "l2"}, nested[1].(map[string]any)["datasource"], "exists in the target org")
+		require.Equal(t, map[string]any{"uid": "${ds}"}, nested[2].(map[string]any)["datasource"])
+	})
+}
@@ -0,0 +1,211 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+)
+
+// DashboardDependencies are the data sources, library panels and panel plugins a dashboard depends on
+type DashboardDependencies struct {
+	Datasources   []DatasourceDependency   `json:"datasources"`
+	LibraryPanels []LibraryPanelDependency `json:"libraryPanels"`
+	Plugins       []PluginDependency       `json:"plugins"`
+
+	// Broken is true when one of the dependencies does not exist
+	Broken bool `json:"broken"`
+}
+
+// DatasourceDependency is a data source referenced by the panels, queries, variables or annotations of a dashboard.
+// Old dashboards reference data sources by name instead of UID.
+type DatasourceDependency struct {
+	UID    string `json:"uid,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Exists bool   `json:"exists"`
+}
+
+// LibraryPanelDependency is a library panel used by a dashboard
+type LibraryPanelDependency struct {
+	UID    string `json:"uid"`
+	Name   string `json:"name,omitempty"`
+	Exists bool   `json:"exists"`
+}
+
+// PluginDependency is a panel plugin used by a dashboard
+type PluginDependency struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Exists  bool   `json:"exists"`
+}
+
+// DependencyResolver looks up the dependencies of a dashboard spec in the org of the dashboard
+type DependencyResolver struct {
+	datasources     datasources.DataSourceService
+	libraryElements libraryelements.Service
+	plugins         pluginstore.Store
+}
+
+func NewDependencyResolver(datasourceService datasources.DataSourceService, libraryElements libraryelements.Service, pluginStore pluginstore.Store) *DependencyResolver {
+	return &DependencyResolver{
+		datasources:     datasourceService,
+		libraryElements: libraryElements,
+		plugins:         pluginStore,
+	}
+}
+
+// Resolve returns the dependencies of the spec, in the classic dashboard JSON model, and whether they exist.
+// Library panels the user cannot read are reported as missing.
+func (r *DependencyResolver) Resolve(ctx context.Context, user identity.Requester, spec map[string]any) (*DashboardDependencies, error) {
+	deps := &DashboardDependencies{
+		Datasources:   []DatasourceDependency{},
+		LibraryPanels: []LibraryPanelDependency{},
+		Plugins:       []PluginDependency{},
+	}
+
+	for _, ref := range datasourceDependencies(spec) {
+		q := &datasources.GetDataSourceQuery{UID: ref.UID, Name: ref.Name, OrgID: user.GetOrgID()}
+		ds, err := r.datasources.GetDataSource(ctx, q)
+		switch {
+		case err == nil:
+			ref.UID, ref.Name, ref.Type, ref.Exists = ds.UID, ds.Name, ds.Type, true
+		case !errors.Is(err, datasources.ErrDataSourceNotFound):
+			return nil, err
+		}
+		deps.Datasources = append(deps.Datasources, ref)
+	}
+
+	for _, uid := range libraryPanelRefs(spec) {
+		dep := LibraryPanelDependency{UID: uid}
+		element, err := r.libraryElements.GetElement(ctx, user, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+		switch {
+		case err == nil:
+			dep.Name, dep.Exists = element.Name, true
+		case !errors.Is(err, model.ErrLibraryElementNotFound):
+			return nil, err
+		}
+		deps.LibraryPanels = append(deps.LibraryPanels, dep)
+	}
+
+	for _, id := range panelPluginRefs(spec) {
+		dep := PluginDependency{ID: id}
+		if p, ok := r.plugins.Plugin(ctx, id); ok {
+			dep.Name, dep.Version, dep.Exists = p.Name, p.Info.Version, true
+		}
+		deps.Plugins = append(deps.Plugins, dep)
+	}
+
+	for _, d := range deps.Datasources {
+		deps.Broken = deps.Broken || !d.Exists
+	}
+	for _, d := range deps.LibraryPanels {
+		deps.Broken = deps.Broken || !d.Exists
+	}
+	for _, d := range deps.Plugins {
+		deps.Broken = deps.Broken || !d.Exists
+	}
+	return deps, nil
+}
+
+// datasourceDependencies returns the data sources referenced anywhere in the spec, sorted by UID and name.
+// Built-in data sources, variables and the default data source are skipped.
+func datasourceDependencies(spec map[string]any) []DatasourceDependency {
+	seen := map[DatasourceDependency]bool{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				if k != "datasource" {
+					walk(child)
+					continue
+				}
+				ref := DatasourceDependency{}
+				switch ds := child.(type) {
+				case string:
+					ref.Name = ds
+				case map[string]any:
+					ref.UID, _ = ds["uid"].(string)
+					ref.Type, _ = ds["type"].(string)
+				}
+				name := ref.UID + ref.Name
+				if name == "" || builtinDatasources[name] || strings.HasPrefix(name, "$") {
+					continue
+				}
+				seen[ref] = true
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(spec)
+
+	// a reference without a type is the same data source as the one with the type
+	typed := map[string]bool{}
+	for ref := range seen {
+		if ref.Type != "" {
+			typed[ref.UID] = true
+		}
+	}
+	refs := make([]DatasourceDependency, 0, len(seen))
+	for ref := range seen {
+		if ref.Type == "" && ref.UID != "" && typed[ref.UID] {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].UID != refs[j].UID {
+			return refs[i].UID < refs[j].UID
+		}
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Type < refs[j].Type
+	})
+	return refs
+}
+
+// panelPluginRefs returns the sorted types of the panels of the spec, including the panels of collapsed rows.
+// Rows are skipped, and so are library panels, their type is saved in the library panel.
+func panelPluginRefs(spec map[string]any) []string {
+	seen := map[string]bool{}
+	var walk func(panels any)
+	walk = func(panels any) {
+		list, ok := panels.([]any)
+		if !ok {
+			return
+		}
+		for _, p := range list {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if t, ok := panel["type"].(string); ok && t != "" && t != "row" {
+				if _, ok := panel["libraryPanel"]; !ok {
+					seen[t] = true
+				}
+			}
+			walk(panel["panels"])
+		}
+	}
+	walk(spec["panels"])
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,87 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	fakeLibraryElements "github.com/grafana/grafana/pkg/services/libraryelements/fake"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+)
+
+func TestDependencyResolver(t *testing.T) {
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, OrgRole: identity.RoleAdmin}
+	ds := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "p1", Name: "Prometheus", OrgID: 1, Type: "prometheus"},
+		{UID: "l1", Name: "Loki", OrgID: 1, Type: "loki"},
+	}}
+	libraryElements := &fakeLibraryElements.LibraryElementService{}
+	_, err := libraryElements.CreateElement(context.Background(), user, model.CreateLibraryElementCommand{UID: "lib1", Name: "CPU", Kind: int64(model.PanelElement)})
+	require.NoError(t, err)
+	store := pluginstore.NewFakePluginStore(pluginstore.Plugin{JSONData: plugins.JSONData{
+		ID: "timeseries", Name: "Time series", Type: plugins.TypePanel, Info: plugins.Info{Version: "1.0.0"},
+	}})
+	resolver := NewDependencyResolver(ds, libraryElements, store)
+
+	spec := map[string]any{
+		"panels": []any{
+			map[string]any{
+				"type":       "timeseries",
+				"datasource": map[string]any{"uid": "p1", "type": "prometheus"},
+				"targets": []any{
+					map[string]any{"refId": "A", "datasource": map[string]any{"uid": "p1"}},
+					map[string]any{"refId": "B", "datasource": map[string]any{"uid": "__expr__"}},
+				},
+			},
+			map[string]any{"type": "row", "panels": []any{
+				map[string]any{"type": "piechart", "datasource": "Loki"},
+				map[string]any{"type": "timeseries", "datasource": map[string]any{"uid": "${ds}"}},
+				map[string]any{"libraryPanel": map[string]any{"uid": "lib1"}},
+				map[string]any{"type": "stat", "libraryPanel": map[string]any{"uid": "lib2"}},
+			}},
+		},
+		"templating": map[string]any{"list": []any{
+			map[string]any{"name": "job", "datasource": map[string]any{"uid": "e1", "type": "elasticsearch"}},
+		}},
+		"annotations": map[string]any{"list": []any{
+			map[string]any{"name": "Annotations & Alerts", "datasource": map[string]any{"uid": "-- Grafana --"}},
+		}},
+	}
+
+	deps, err := resolver.Resolve(context.Background(), user, spec)
+	require.NoError(t, err)
+	require.Equal(t, &DashboardDependencies{
+		Datasources: []DatasourceDependency{
+			{Name: "Loki", UID: "l1", Type: "loki", Exists: true},
+			{UID: "e1", Type: "elasticsearch"},
+			{UID: "p1", Name: "Prometheus", Type: "prometheus", Exists: true},
+		},
+		LibraryPanels: []LibraryPanelDependency{
+			{UID: "lib1", Name: "CPU", Exists: true},
+			{UID: "lib2"},
+		},
+		Plugins: []PluginDependency{
+			{ID: "piechart"},
+			{ID: "timeseries", Name: "Time series", Version: "1.0.0", Exists: true},
+		},
+		Broken: true,
+	}, deps)
+
+	t.Run("not broken when all the dependencies exist", func(t *testing.T) {
+		deps, err := resolver.Resolve(context.Background(), user, map[string]any{
+			"panels": []any{
+				map[string]any{"type": "timeseries", "datasource": map[string]any{"uid": "p1"}},
+			},
+		})
+		require.NoError(t, err)
+		require.False(t, deps.Broken)
+		require.Empty(t, deps.LibraryPanels)
+	})
+}
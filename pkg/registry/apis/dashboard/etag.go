@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+)
+
+// Dashboards may be kept by clients, but must be revalidated with the ETag before they are used
+const dashboardCacheControl = "private, no-cache"
+
+// etagStorage answers GET requests of a dashboard with an ETag based on its resource version and generation.
+// When the client already has the current version (If-None-Match), a 304 Not Modified error is returned
+// and the dashboard is not serialized.
+type etagStorage struct {
+	grafanarest.Storage
+}
+
+// etagWatchStorage keeps watch support of storages that implement it
+type etagWatchStorage struct {
+	*etagStorage
+	rest.Watcher
+}
+
+// WithETag adds ETag support to the dashboard storage, other storages are returned unchanged
+func WithETag(store rest.Storage) rest.Storage {
+	s, ok := store.(grafanarest.Storage)
+	if !ok {
+		return store
+	}
+	if w, ok := store.(rest.Watcher); ok {
+		return &etagWatchStorage{etagStorage: &etagStorage{Storage: s}, Watcher: w}
+	}
+	return &etagStorage{Storage: s}
+}
+
+func (s *etagStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	obj, err := s.Storage.Get(ctx, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// connectors read the dashboard with the context of their own request
+	info, ok := k8srequest.RequestInfoFrom(ctx)
+	if !ok || info.Verb != "get" || info.Subresource != "" || info.Name != name {
+		return obj, nil
+	}
+	cond, ok := grafanarequest.ConditionalGetFrom(ctx)
+	if !ok {
+		return obj, nil
+	}
+
+	etag := dashboardETag(obj)
+	if etag == "" {
+		return obj, nil
+	}
+	cond.ETag = etag
+	cond.CacheControl = dashboardCacheControl
+	if cond.Matches(etag) {
+		return nil, &apierrors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusNotModified,
+			Reason:  metav1.StatusReason("NotModified"),
+			Message: fmt.Sprintf("dashboard %q is not modified", name),
+		}}
+	}
+	return obj, nil
+}
+
+// dashboardETag is a weak ETag, the dashboard is encoded differently depending on the API version and Accept header
+func dashboardETag(obj runtime.Object) string {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return ""
+	}
+	rv := meta.GetResourceVersion()
+	if rv == "" {
+		return ""
+	}
+	return fmt.Sprintf(`W/"%s-%d"`, rv, meta.GetGeneration())
+}
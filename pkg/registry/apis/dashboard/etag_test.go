@@ -0,0 +1,80 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+)
+
+func TestETagStorage(t *testing.T) {
+	store := WithETag(&fakeDashboardStorage{dash: &dashboardv0alpha1.Dashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc", ResourceVersion: "12", Generation: 3},
+	}}).(rest.Getter)
+
+	get := func(info *k8srequest.RequestInfo, ifNoneMatch string) (runtime.Object, *grafanarequest.ConditionalGet, error) {
+		cond := &grafanarequest.ConditionalGet{IfNoneMatch: ifNoneMatch}
+		ctx := grafanarequest.WithConditionalGet(k8srequest.WithRequestInfo(context.Background(), info), cond)
+		obj, err := store.Get(ctx, "abc", &metav1.GetOptions{})
+		return obj, cond, err
+	}
+
+	t.Run("sets the ETag", func(t *testing.T) {
+		obj, cond, err := get(&k8srequest.RequestInfo{Verb: "get", Name: "abc"}, "")
+		require.NoError(t, err)
+		require.NotNil(t, obj)
+		require.Equal(t, `W/"12-3"`, cond.ETag)
+		require.Equal(t, dashboardCacheControl, cond.CacheControl)
+	})
+
+	t.Run("returns not modified if the ETag matches", func(t *testing.T) {
+		_, _, err := get(&k8srequest.RequestInfo{Verb: "get", Name: "abc"}, `W/"12-3"`)
+		var status apierrors.APIStatus
+		require.ErrorAs(t, err, &status)
+		require.Equal(t, int32(http.StatusNotModified), status.Status().Code)
+	})
+
+	t.Run("returns the dashboard if it changed", func(t *testing.T) {
+		obj, _, err := get(&k8srequest.RequestInfo{Verb: "get", Name: "abc"}, `W/"11-2"`)
+		require.NoError(t, err)
+		require.NotNil(t, obj)
+	})
+
+	t.Run("ignores reads of subresources", func(t *testing.T) {
+		obj, cond, err := get(&k8srequest.RequestInfo{Verb: "get", Name: "abc", Subresource: "dto"}, `W/"12-3"`)
+		require.NoError(t, err)
+		require.NotNil(t, obj)
+		require.Empty(t, cond.ETag)
+	})
+
+	t.Run("keeps watch support", func(t *testing.T) {
+		_, ok := WithETag(&fakeDashboardStorage{}).(rest.Watcher)
+		require.False(t, ok)
+		_, ok = WithETag(&fakeWatchDashboardStorage{}).(rest.Watcher)
+		require.True(t, ok)
+	})
+}
+
+type fakeDashboardStorage struct {
+	grafanarest.Storage
+	dash *dashboardv0alpha1.Dashboard
+}
+
+func (s *fakeDashboardStorage) Get(_ context.Context, _ string, _ *metav1.GetOptions) (runtime.Object, error) {
+	return s.dash.DeepCopy(), nil
+}
+
+type fakeWatchDashboardStorage struct {
+	fakeDashboardStorage
+	rest.Watcher
+}
@@ -0,0 +1,187 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	dashboardv1alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v1alpha1"
+	dashboardv2alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v2alpha1"
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+)
+
+// fieldsStorage answers GET requests of a dashboard with the fields option with only the requested paths of the
+// spec, e.g. fields=spec.panels[*].title,spec.templating. The metadata is always returned.
+type fieldsStorage struct {
+	grafanarest.Storage
+}
+
+// fieldsWatchStorage keeps watch support of storages that implement it
+type fieldsWatchStorage struct {
+	*fieldsStorage
+	rest.Watcher
+}
+
+// WithFields adds support of the fields option to the dashboard storage, other storages are returned unchanged
+func WithFields(store rest.Storage) rest.Storage {
+	s, ok := store.(grafanarest.Storage)
+	if !ok {
+		return store
+	}
+	if w, ok := store.(rest.Watcher); ok {
+		return &fieldsWatchStorage{fieldsStorage: &fieldsStorage{Storage: s}, Watcher: w}
+	}
+	return &fieldsStorage{Storage: s}
+}
+
+func (s *fieldsStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	// connectors read the dashboard with the context of their own request
+	info, ok := k8srequest.RequestInfoFrom(ctx)
+	if !ok || info.Verb != "get" || info.Subresource != "" || info.Name != name {
+		return s.Storage.Get(ctx, name, options)
+	}
+	fields, ok := grafanarequest.FieldsFrom(ctx)
+	if !ok {
+		return s.Storage.Get(ctx, name, options)
+	}
+	projection, err := parseFieldProjection(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.Storage.Get(ctx, name, options)
+	if err != nil {
+		return nil, err
+	}
+	return projectDashboard(obj, projection), nil
+}
+
+// fieldProjection is the tree of the requested paths of the spec. A node either selects the whole value, or the
+// keys of an object, or with [*] every item of an array.
+type fieldProjection struct {
+	all   bool
+	keys  map[string]*fieldProjection
+	items *fieldProjection
+}
+
+// parseFieldProjection parses the requested paths. They start with spec, and are made of keys separated by dots
+// with [*] after a key to select every item of an array.
+func parseFieldProjection(paths []string) (*fieldProjection, error) {
+	root := &fieldProjection{}
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		if segments[0] != "spec" {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid field %q: only the fields of the spec can be requested", path))
+		}
+		node := root
+		for _, segment := range segments[1:] {
+			key, brackets, _ := strings.Cut(segment, "[")
+			if key == "" && brackets == "" {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid field %q: empty key", path))
+			}
+			if key != "" {
+				node = node.key(key)
+			}
+			if brackets == "" {
+				continue
+			}
+			for _, b := range strings.SplitAfter("["+brackets, "]") {
+				if b == "" {
+					continue
+				}
+				if b != "[*]" {
+					return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid field %q: only [*] is supported in brackets", path))
+				}
+				if node.items == nil {
+					node.items = &fieldProjection{}
+				}
+				node = node.items
+			}
+		}
+		node.all = true
+	}
+	return root, nil
+}
+
+func (p *fieldProjection) key(key string) *fieldProjection {
+	if p.keys == nil {
+		p.keys = make(map[string]*fieldProjection)
+	}
+	child, ok := p.keys[key]
+	if !ok {
+		child = &fieldProjection{}
+		p.keys[key] = child
+	}
+	return child
+}
+
+// apply returns the projection of a value, and false when nothing of it is selected. The items of an array that
+// are not selected are dropped.
+func (p *fieldProjection) apply(v any) (any, bool) {
+	if p.all {
+		return v, true
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		if p.keys == nil {
+			return nil, false
+		}
+		out := make(map[string]any, len(p.keys))
+		for key, child := range p.keys {
+			if value, ok := v[key]; ok {
+				if value, ok := child.apply(value); ok {
+					out[key] = value
+				}
+			}
+		}
+		return out, true
+	case []any:
+		if p.items == nil {
+			return nil, false
+		}
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			if item, ok := p.items.apply(item); ok {
+				out = append(out, item)
+			}
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// projectDashboard returns a copy of the dashboard with the projection of its spec. The title of the spec of
+// v1alpha1 and v2alpha1 dashboards is a required field, and is always returned.
+func projectDashboard(obj runtime.Object, p *fieldProjection) runtime.Object {
+	project := func(spec map[string]any) map[string]any {
+		out, _ := p.apply(spec)
+		m, _ := out.(map[string]any)
+		if m == nil {
+			m = map[string]any{}
+		}
+		return m
+	}
+	switch dash := obj.(type) {
+	case *dashboardv0alpha1.Dashboard:
+		dash = dash.DeepCopy()
+		dash.Spec.Object = project(dash.Spec.Object)
+		return dash
+	case *dashboardv1alpha1.Dashboard:
+		dash = dash.DeepCopy()
+		dash.Spec.Object = project(dash.Spec.Object)
+		return dash
+	case *dashboardv2alpha1.Dashboard:
+		dash = dash.DeepCopy()
+		dash.Spec.Object = project(dash.Spec.Object)
+		return dash
+	}
+	return obj
+}
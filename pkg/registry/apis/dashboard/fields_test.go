@@ -0,0 +1,76 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	dashboardv1alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v1alpha1"
+)
+
+func TestFieldProjection(t *testing.T) {
+	spec := func() map[string]any {
+		return map[string]any{
+			"title": "Overview",
+			"panels": []any{
+				map[string]any{"id": int64(1), "title": "CPU", "type": "timeseries"},
+				map[string]any{"id": int64(2), "type": "row", "panels": []any{
+					map[string]any{"id": int64(3), "title": "Disk"},
+				}},
+			},
+			"templating": map[string]any{"list": []any{map[string]any{"name": "host"}}},
+		}
+	}
+
+	project := func(t *testing.T, paths ...string) any {
+		t.Helper()
+		p, err := parseFieldProjection(paths)
+		require.NoError(t, err)
+		out, _ := p.apply(spec())
+		return out
+	}
+
+	t.Run("selects the keys of the items of arrays", func(t *testing.T) {
+		require.Equal(t, map[string]any{
+			"panels":     []any{map[string]any{"title": "CPU"}, map[string]any{}},
+			"templating": map[string]any{"list": []any{map[string]any{"name": "host"}}},
+		}, project(t, "spec.panels[*].title", "spec.templating"))
+	})
+
+	t.Run("selects nested arrays", func(t *testing.T) {
+		require.Equal(t, map[string]any{
+			"panels": []any{map[string]any{"id": int64(1)}, map[string]any{"id": int64(2), "panels": []any{map[string]any{"title": "Disk"}}}},
+		}, project(t, "spec.panels[*].id", "spec.panels[*].panels[*].title"))
+	})
+
+	t.Run("a parent path selects the whole value", func(t *testing.T) {
+		require.Equal(t, spec()["panels"], project(t, "spec.panels[*].title", "spec.panels").(map[string]any)["panels"])
+		require.Equal(t, spec(), project(t, "spec"))
+	})
+
+	t.Run("rejects invalid paths", func(t *testing.T) {
+		for _, path := range []string{"metadata.name", "spec..title", "spec.panels[0].title", "spec.panels[*"} {
+			_, err := parseFieldProjection([]string{path})
+			require.Error(t, err, path)
+		}
+	})
+
+	t.Run("projects the spec of the dashboard versions", func(t *testing.T) {
+		p, err := parseFieldProjection([]string{"spec.templating"})
+		require.NoError(t, err)
+
+		v0 := &dashboardv0alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "abc"}, Spec: common.Unstructured{Object: spec()}}
+		out := projectDashboard(v0, p).(*dashboardv0alpha1.Dashboard)
+		require.Equal(t, "abc", out.Name)
+		require.Equal(t, map[string]any{"templating": spec()["templating"]}, out.Spec.Object)
+		require.Len(t, v0.Spec.Object, 3, "the dashboard of the storage is not modified")
+
+		v1 := &dashboardv1alpha1.Dashboard{Spec: dashboardv1alpha1.DashboardSpec{Title: "Overview", Unstructured: common.Unstructured{Object: spec()}}}
+		out1 := projectDashboard(v1, p).(*dashboardv1alpha1.Dashboard)
+		require.Equal(t, "Overview", out1.Spec.Title)
+		require.Equal(t, map[string]any{"templating": spec()["templating"]}, out1.Spec.Object)
+	})
+}
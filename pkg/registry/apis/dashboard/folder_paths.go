@@ -0,0 +1,108 @@
+package dashboard
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/search/model"
+)
+
+// folderPathCacheTTL is how long the folders of an org are cached, moved and renamed folders are shown after at most this long
+const folderPathCacheTTL = time.Minute
+
+// folderPathCache caches the titles and parents of the folders of each org, so the paths of the folders of the search
+// hits are resolved without reading the same folders for every hit and every search. Like the folder titles of
+// /api/search, the titles of the folders of the hits are not filtered by the folder permissions of the user.
+type folderPathCache struct {
+	folders folder.Service
+	log     log.Logger
+	now     func() time.Time
+
+	mu   sync.Mutex
+	orgs map[int64]*orgFolders
+}
+
+type orgFolders struct {
+	expires time.Time
+	nodes   map[string]folderNode
+}
+
+type folderNode struct {
+	title     string
+	parentUID string
+}
+
+func newFolderPathCache(folders folder.Service, logger log.Logger) *folderPathCache {
+	return &folderPathCache{folders: folders, log: logger, now: time.Now, orgs: map[int64]*orgFolders{}}
+}
+
+// path returns the folders from the root to the folder, the folder included. It is empty when the folder can not be read.
+func (t *folderPathCache) path(ctx context.Context, user identity.Requester, uid string) []model.HitFolder {
+	if t == nil || t.folders == nil || uid == "" {
+		return nil
+	}
+	orgID := user.GetOrgID()
+	if path, ok := t.cachedPath(orgID, uid); ok {
+		return path
+	}
+
+	f, err := t.folders.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: orgID, SignedInUser: user})
+	if err != nil {
+		t.log.Debug("failed to read the folder of a search result", "folder", uid, "error", err)
+		return nil
+	}
+	parents, err := t.folders.GetParents(ctx, folder.GetParentsQuery{UID: uid, OrgID: orgID})
+	if err != nil {
+		t.log.Debug("failed to read the parents of the folder of a search result", "folder", uid, "error", err)
+		parents = nil
+	}
+	t.add(orgID, append(parents, f))
+
+	path, _ := t.cachedPath(orgID, uid)
+	return path
+}
+
+// cachedPath returns the path of the folder and whether all its folders are cached
+func (t *folderPathCache) cachedPath(orgID int64, uid string) ([]model.HitFolder, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	org := t.orgs[orgID]
+	if org == nil || t.now().After(org.expires) {
+		return nil, false
+	}
+
+	path := []model.HitFolder{}
+	seen := map[string]bool{}
+	for uid != "" && !seen[uid] {
+		seen[uid] = true
+		node, ok := org.nodes[uid]
+		if !ok {
+			return nil, false
+		}
+		path = append(path, model.HitFolder{UID: uid, Title: node.title})
+		uid = node.parentUID
+	}
+	// root first
+	slices.Reverse(path)
+	return path, true
+}
+
+func (t *folderPathCache) add(orgID int64, folders []*folder.Folder) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	org := t.orgs[orgID]
+	if org == nil || t.now().After(org.expires) {
+		org = &orgFolders{expires: t.now().Add(folderPathCacheTTL), nodes: map[string]folderNode{}}
+		t.orgs[orgID] = org
+	}
+	for _, f := range folders {
+		if f != nil && f.UID != "" {
+			org.nodes[f.UID] = folderNode{title: f.Title, parentUID: f.ParentUID}
+		}
+	}
+}
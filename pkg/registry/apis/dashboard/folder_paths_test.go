@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/search/model"
+)
+
+type countingFolderService struct {
+	*foldertest.FakeService
+	gets int
+}
+
+func (s *countingFolderService) Get(ctx context.Context, q *folder.GetFolderQuery) (*folder.Folder, error) {
+	s.gets++
+	return s.FakeService.Get(ctx, q)
+}
+
+func TestFolderPathCache(t *testing.T) {
+	folders := &countingFolderService{FakeService: foldertest.NewFakeService()}
+	folders.ExpectedFolder = &folder.Folder{UID: "c", Title: "Hosts", ParentUID: "b"}
+	folders.ExpectedFolders = []*folder.Folder{
+		{UID: "a", Title: "Datacenter"},
+		{UID: "b", Title: "Europe", ParentUID: "a"},
+	}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Namespace: "default"}
+	now := time.Now()
+	cache := newFolderPathCache(folders, log.NewNopLogger())
+	cache.now = func() time.Time { return now }
+
+	path := []model.HitFolder{{UID: "a", Title: "Datacenter"}, {UID: "b", Title: "Europe"}, {UID: "c", Title: "Hosts"}}
+	require.Equal(t, path, cache.path(context.Background(), user, "c"))
+	require.Equal(t, path[:2], cache.path(context.Background(), user, "b"))
+	require.Equal(t, 1, folders.gets, "the ancestors are cached with the folder")
+
+	t.Run("the folders are read again once expired", func(t *testing.T) {
+		now = now.Add(folderPathCacheTTL + time.Second)
+		require.Equal(t, path, cache.path(context.Background(), user, "c"))
+		require.Equal(t, 2, folders.gets)
+	})
+
+	t.Run("unreadable folders have no path", func(t *testing.T) {
+		folders.ExpectedError = folder.ErrFolderNotFound
+		require.Empty(t, cache.path(context.Background(), user, "missing"))
+	})
+}
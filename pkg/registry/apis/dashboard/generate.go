@@ -0,0 +1,255 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+// generateMaxDashboards is the maximum number of dashboards generated by a request
+const generateMaxDashboards = 100
+
+// ErrInvalidGenerate is returned when the template or the values of a generate request are not valid.
+var ErrInvalidGenerate = errutil.BadRequest("dashboards.generate.invalid")
+
+// placeholderRegex matches the placeholders of a template, {{ .name }} or {{ .name.field }}. The leading dot keeps
+// them apart from the {{label}} legend formats of the panels.
+var placeholderRegex = regexp.MustCompile(`\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)\s*\}\}`)
+
+// GenerateRequest renders dashboards from a template with placeholders
+type GenerateRequest struct {
+	// Template is a dashboard, in the classic dashboard JSON model, with placeholders in its strings
+	Template map[string]any `json:"template"`
+	// Values are the values of the placeholders, a string that is only a placeholder is replaced by the value
+	// itself, the other placeholders by the value formatted as a string
+	Values map[string]any `json:"values,omitempty"`
+	// Foreach is the name of a list of the values, one dashboard is generated per item of the list with the item as
+	// the value of the name. A single dashboard is generated when it is empty.
+	Foreach string `json:"foreach,omitempty"`
+	// Save saves the generated dashboards in the folder, otherwise they are only returned
+	Save      bool   `json:"save,omitempty"`
+	FolderUID string `json:"folderUid,omitempty"`
+	Overwrite bool   `json:"overwrite,omitempty"`
+}
+
+// GeneratedDashboard is a dashboard rendered from a template
+type GeneratedDashboard struct {
+	UID   string         `json:"uid,omitempty"`
+	Title string         `json:"title"`
+	Spec  map[string]any `json:"spec"`
+}
+
+// GenerateResult are the dashboards rendered from a template, in the order of the foreach list
+type GenerateResult struct {
+	Dashboards []GeneratedDashboard `json:"dashboards"`
+	Saved      bool                 `json:"saved"`
+}
+
+// DashboardGenerator renders dashboards from templates, and saves them with the legacy services. The dashboards of
+// a request are saved inside a single database transaction.
+type DashboardGenerator struct {
+	db         db.DB
+	folders    folder.Service
+	dashboards dashboards.DashboardService
+}
+
+func NewDashboardGenerator(sql db.DB, folders folder.Service, dashboardService dashboards.DashboardService) *DashboardGenerator {
+	return &DashboardGenerator{
+		db:         sql,
+		folders:    folders,
+		dashboards: dashboardService,
+	}
+}
+
+// Generate renders the dashboards of the request and saves them when requested. Saving the dashboards checks that
+// the user can create dashboards in the folder.
+func (g *DashboardGenerator) Generate(ctx context.Context, user identity.Requester, req GenerateRequest) (*GenerateResult, error) {
+	generated, err := renderTemplate(req)
+	if err != nil {
+		return nil, err
+	}
+	result := &GenerateResult{Dashboards: generated}
+	if !req.Save {
+		return result, nil
+	}
+
+	if req.FolderUID != "" {
+		_, err := g.folders.Get(ctx, &folder.GetFolderQuery{UID: &req.FolderUID, OrgID: user.GetOrgID(), SignedInUser: user})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+				return nil, ErrInvalidGenerate.Errorf("folder %q not found", req.FolderUID)
+			}
+			return nil, err
+		}
+	}
+
+	err = g.db.InTransaction(ctx, func(ctx context.Context) error {
+		for i := range generated {
+			data := simplejson.NewFromAny(generated[i].Spec)
+			data.Del("id")
+			dash := dashboards.NewDashboardFromJson(data)
+			dash.OrgID = user.GetOrgID()
+			dash.FolderUID = req.FolderUID
+			saved, err := g.dashboards.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+				OrgID:     user.GetOrgID(),
+				User:      user,
+				Message:   "generated from template",
+				Overwrite: req.Overwrite,
+				Dashboard: dash,
+			}, false)
+			if err != nil {
+				return err
+			}
+			generated[i].UID = saved.UID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, importError(err)
+	}
+	result.Saved = true
+	return result, nil
+}
+
+// renderTemplate renders the dashboards of the request. The generated dashboards must have a title, and different
+// titles and UIDs so they can be saved in the same folder.
+func renderTemplate(req GenerateRequest) ([]GeneratedDashboard, error) {
+	if len(req.Template) == 0 {
+		return nil, ErrInvalidGenerate.Errorf("missing template")
+	}
+
+	valueSets := []map[string]any{req.Values}
+	if req.Foreach != "" {
+		items, ok := req.Values[req.Foreach].([]any)
+		if !ok {
+			return nil, ErrInvalidGenerate.Errorf("foreach value %q is not a list", req.Foreach)
+		}
+		if len(items) > generateMaxDashboards {
+			return nil, ErrInvalidGenerate.Errorf("a template generates at most %d dashboards, %q has %d items", generateMaxDashboards, req.Foreach, len(items))
+		}
+		valueSets = make([]map[string]any, 0, len(items))
+		for _, item := range items {
+			values := make(map[string]any, len(req.Values))
+			for k, v := range req.Values {
+				values[k] = v
+			}
+			values[req.Foreach] = item
+			valueSets = append(valueSets, values)
+		}
+	}
+
+	titles, uids := map[string]bool{}, map[string]bool{}
+	out := make([]GeneratedDashboard, 0, len(valueSets))
+	for _, values := range valueSets {
+		rendered, err := renderValue(req.Template, values)
+		if err != nil {
+			return nil, err
+		}
+		spec, _ := rendered.(map[string]any)
+		d := GeneratedDashboard{Spec: spec}
+		d.Title, _ = spec["title"].(string)
+		d.UID, _ = spec["uid"].(string)
+		if d.Title == "" {
+			return nil, ErrInvalidGenerate.Errorf("a generated dashboard is missing a title")
+		}
+		if titles[d.Title] {
+			return nil, ErrInvalidGenerate.Errorf("the title %q is generated more than once", d.Title)
+		}
+		if d.UID != "" && uids[d.UID] {
+			return nil, ErrInvalidGenerate.Errorf("the uid %q is generated more than once", d.UID)
+		}
+		titles[d.Title], uids[d.UID] = true, true
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// renderValue returns a copy of v with the placeholders of its strings replaced by the values
+func renderValue(v any, values map[string]any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return renderString(v, values)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			rendered, err := renderValue(child, values)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, child := range v {
+			rendered, err := renderValue(child, values)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rendered)
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func renderString(s string, values map[string]any) (any, error) {
+	matches := placeholderRegex.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	// a string that is only a placeholder keeps the type of the value
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return lookupValue(values, s[matches[0][2]:matches[0][3]])
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		v, err := lookupValue(values, s[m[2]:m[3]])
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s[last:m[0]])
+		switch v := v.(type) {
+		case string:
+			b.WriteString(v)
+		case map[string]any, []any:
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			b.Write(raw)
+		default:
+			b.WriteString(fmt.Sprint(v))
+		}
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// lookupValue returns the value of a placeholder, the fields of objects are separated by dots
+func lookupValue(values map[string]any, path string) (any, error) {
+	var current any = values
+	for _, name := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, ErrInvalidGenerate.Errorf("missing value for placeholder %q", path)
+		}
+		if current, ok = obj[name]; !ok {
+			return nil, ErrInvalidGenerate.Errorf("missing value for placeholder %q", path)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,100 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+func TestDashboardGenerator(t *testing.T) {
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+	template := func() map[string]any {
+		return map[string]any{
+			"uid":      "svc-{{ .service.name }}",
+			"title":    "Service {{.service.name}} ({{ .team }})",
+			"tags":     []any{"{{ .team }}"},
+			"refresh":  "{{ .refresh }}",
+			"editable": "{{ .editable }}",
+			"panels": []any{
+				map[string]any{
+					"title":         "Replicas: {{ .service.replicas }}",
+					"maxDataPoints": "{{ .service.replicas }}",
+					"targets": []any{
+						map[string]any{"expr": `up{job="{{ .service.name }}"}`, "legendFormat": "{{instance}}"},
+					},
+				},
+			},
+		}
+	}
+	values := func() map[string]any {
+		return map[string]any{
+			"team":     "payments",
+			"refresh":  "1m",
+			"editable": false,
+			"service": []any{
+				map[string]any{"name": "api", "replicas": float64(3)},
+				map[string]any{"name": "worker", "replicas": float64(1)},
+			},
+		}
+	}
+	generator := NewDashboardGenerator(nil, nil, nil)
+
+	t.Run("generates a dashboard per item of the foreach list", func(t *testing.T) {
+		res, err := generator.Generate(context.Background(), user, GenerateRequest{
+			Template: template(),
+			Values:   values(),
+			Foreach:  "service",
+		})
+		require.NoError(t, err)
+		require.False(t, res.Saved)
+		require.Len(t, res.Dashboards, 2)
+
+		api := res.Dashboards[0]
+		require.Equal(t, "svc-api", api.UID)
+		require.Equal(t, "Service api (payments)", api.Title)
+		require.Equal(t, []any{"payments"}, api.Spec["tags"])
+		require.Equal(t, false, api.Spec["editable"])
+		panel := api.Spec["panels"].([]any)[0].(map[string]any)
+		require.Equal(t, "Replicas: 3", panel["title"])
+		require.Equal(t, float64(3), panel["maxDataPoints"])
+		require.Equal(t, map[string]any{"expr": `up{job="api"}`, "legendFormat": "{{instance}}"}, panel["targets"].([]any)[0])
+
+		require.Equal(t, "svc-worker", res.Dashboards[1].UID)
+	})
+
+	t.Run("generates a single dashboard without foreach", func(t *testing.T) {
+		v := values()
+		v["service"] = map[string]any{"name": "api", "replicas": 2}
+		res, err := generator.Generate(context.Background(), user, GenerateRequest{Template: template(), Values: v})
+		require.NoError(t, err)
+		require.Len(t, res.Dashboards, 1)
+		require.Equal(t, "Service api (payments)", res.Dashboards[0].Title)
+	})
+
+	t.Run("validates the request", func(t *testing.T) {
+		for name, req := range map[string]GenerateRequest{
+			"missing template": {Values: values()},
+			"missing value":    {Template: template(), Values: map[string]any{"service": []any{map[string]any{"name": "api"}}}, Foreach: "service"},
+			"foreach no list":  {Template: template(), Values: values(), Foreach: "team"},
+			"duplicate title":  {Template: map[string]any{"title": "{{ .team }}"}, Values: values(), Foreach: "service"},
+			"missing title":    {Template: map[string]any{"uid": "x"}},
+		} {
+			_, err := generator.Generate(context.Background(), user, req)
+			require.ErrorIs(t, err, ErrInvalidGenerate, name)
+		}
+	})
+
+	t.Run("limits the number of dashboards", func(t *testing.T) {
+		items := make([]any, generateMaxDashboards+1)
+		_, err := generator.Generate(context.Background(), user, GenerateRequest{
+			Template: template(),
+			Values:   map[string]any{"service": items},
+			Foreach:  "service",
+		})
+		require.ErrorContains(t, err, "at most 100 dashboards")
+	})
+}
@@ -0,0 +1,219 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// The scopes of a home dashboard, the resolved home dashboard is the one of the first scope that sets one
+const (
+	HomeScopeUser     = "user"
+	HomeScopeTeam     = "team"
+	HomeScopeOrg      = "org"
+	HomeScopeHomePage = "homePage"
+	HomeScopeDefault  = "default"
+)
+
+var (
+	ErrInvalidHome      = errutil.BadRequest("dashboards.home.invalid")
+	ErrHomeAccessDenied = errutil.Forbidden("dashboards.home.forbidden")
+)
+
+// HomeDashboard is the resolved home dashboard of a user
+type HomeDashboard struct {
+	// Scope is the scope the home dashboard is set for, or homePage and default when none is set
+	Scope        string `json:"scope"`
+	TeamID       int64  `json:"teamId,omitempty"`
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	// URL is the url of the dashboard, or the configured home page
+	URL string `json:"url,omitempty"`
+	// Spec is the default home dashboard, when no home dashboard is set
+	Spec map[string]any `json:"spec,omitempty"`
+}
+
+// SetHomeRequest sets or clears the home dashboard of a scope
+type SetHomeRequest struct {
+	Scope  string `json:"scope"`
+	TeamID int64  `json:"teamId,omitempty"`
+	// DashboardUID is the home dashboard, an empty uid clears the home dashboard of the scope
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// HomeDashboards resolves and sets the home dashboards stored in the user, team and org preferences
+type HomeDashboards struct {
+	cfg           *setting.Cfg
+	prefs         pref.Service
+	dashboards    dashboards.DashboardService
+	teams         team.Service
+	accessControl accesscontrol.AccessControl
+	log           log.Logger
+}
+
+func NewHomeDashboards(cfg *setting.Cfg, prefs pref.Service, dashboardService dashboards.DashboardService, teams team.Service, accessControl accesscontrol.AccessControl) *HomeDashboards {
+	return &HomeDashboards{
+		cfg:           cfg,
+		prefs:         prefs,
+		dashboards:    dashboardService,
+		teams:         teams,
+		accessControl: accessControl,
+		log:           log.New("dashboards.home"),
+	}
+}
+
+// Get resolves the home dashboard of the user: the home dashboard of the user, then of its teams and then of the
+// org. A home dashboard that no longer exists is skipped. Without any, the configured home page or the default home
+// dashboard is returned.
+func (h *HomeDashboards) Get(ctx context.Context, user identity.Requester) (*HomeDashboard, error) {
+	orgID := user.GetOrgID()
+	candidates := []pref.GetPreferenceQuery{}
+	if userID, err := identity.UserIdentifier(user.GetID()); err == nil && userID > 0 {
+		candidates = append(candidates, pref.GetPreferenceQuery{OrgID: orgID, UserID: userID})
+
+		teams, err := h.teams.GetTeamIDsByUser(ctx, &team.GetTeamIDsByUserQuery{OrgID: orgID, UserID: userID})
+		if err != nil {
+			return nil, err
+		}
+		// like the merged preferences, the team with the highest id wins
+		slices.Sort(teams)
+		for i := len(teams) - 1; i >= 0; i-- {
+			candidates = append(candidates, pref.GetPreferenceQuery{OrgID: orgID, TeamID: teams[i]})
+		}
+	}
+	candidates = append(candidates, pref.GetPreferenceQuery{OrgID: orgID})
+
+	for _, query := range candidates {
+		home, err := h.resolve(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		if home != nil {
+			return home, nil
+		}
+	}
+
+	if h.cfg.HomePage != "" {
+		return &HomeDashboard{Scope: HomeScopeHomePage, URL: h.cfg.HomePage}, nil
+	}
+	spec, err := h.defaultSpec()
+	if err != nil {
+		return nil, err
+	}
+	return &HomeDashboard{Scope: HomeScopeDefault, Spec: spec}, nil
+}
+
+// resolve returns the home dashboard of the preferences, or nil when they do not set an existing one
+func (h *HomeDashboards) resolve(ctx context.Context, query pref.GetPreferenceQuery) (*HomeDashboard, error) {
+	p, err := h.prefs.Get(ctx, &query)
+	if err != nil {
+		if errors.Is(err, pref.ErrPrefNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if p == nil || p.HomeDashboardID == 0 {
+		return nil, nil
+	}
+	ref, err := h.dashboards.GetDashboardUIDByID(ctx, &dashboards.GetDashboardRefByIDQuery{ID: p.HomeDashboardID})
+	if err != nil {
+		h.log.Warn("Failed to get the home dashboard", "orgId", query.OrgID, "userId", query.UserID, "teamId", query.TeamID, "dashboardId", p.HomeDashboardID, "err", err)
+		return nil, nil
+	}
+
+	home := &HomeDashboard{Scope: HomeScopeOrg, DashboardUID: ref.UID, URL: dashboards.GetDashboardURL(ref.UID, ref.Slug)}
+	switch {
+	case query.UserID != 0:
+		home.Scope = HomeScopeUser
+	case query.TeamID != 0:
+		home.Scope, home.TeamID = HomeScopeTeam, query.TeamID
+	}
+	return home, nil
+}
+
+func (h *HomeDashboards) defaultSpec() (map[string]any, error) {
+	filePath := h.cfg.DefaultHomeDashboardPath
+	if filePath == "" {
+		filePath = filepath.Join(h.cfg.StaticRootPath, "dashboards/home.json")
+	}
+	// the path comes from the configuration
+	// nolint:gosec
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load home dashboard: %w", err)
+	}
+	spec := map[string]any{}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("failed to load home dashboard: %w", err)
+	}
+	return spec, nil
+}
+
+// Set sets the home dashboard of a scope. The home dashboard of the org requires the orgs.preferences:write
+// permission, the one of a team the teams:write permission on the team, and users can only set their own.
+func (h *HomeDashboards) Set(ctx context.Context, user identity.Requester, req SetHomeRequest) (*HomeDashboard, error) {
+	orgID := user.GetOrgID()
+	cmd := pref.PatchPreferenceCommand{OrgID: orgID}
+	var evaluator accesscontrol.Evaluator
+	switch req.Scope {
+	case HomeScopeOrg:
+		evaluator = accesscontrol.EvalPermission(accesscontrol.ActionOrgsPreferencesWrite)
+	case HomeScopeTeam:
+		if req.TeamID <= 0 {
+			return nil, ErrInvalidHome.Errorf("missing teamId")
+		}
+		cmd.TeamID = req.TeamID
+		evaluator = accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite, accesscontrol.Scope("teams", "id", strconv.FormatInt(req.TeamID, 10)))
+	case HomeScopeUser:
+		userID, err := identity.UserIdentifier(user.GetID())
+		if err != nil || userID <= 0 {
+			return nil, ErrHomeAccessDenied.Errorf("only users have a home dashboard")
+		}
+		cmd.UserID = userID
+	default:
+		return nil, ErrInvalidHome.Errorf("unknown scope %q, expected user, team or org", req.Scope)
+	}
+
+	if evaluator != nil {
+		allowed, err := h.accessControl.Evaluate(ctx, user, evaluator)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrHomeAccessDenied.Errorf("missing permission to set the home dashboard of the %s", req.Scope)
+		}
+	}
+
+	home := &HomeDashboard{Scope: req.Scope, TeamID: cmd.TeamID}
+	var dashboardID int64
+	if req.DashboardUID != "" {
+		dash, err := h.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: req.DashboardUID, OrgID: orgID})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				return nil, ErrInvalidHome.Errorf("dashboard %q not found", req.DashboardUID)
+			}
+			return nil, err
+		}
+		dashboardID = dash.ID
+		home.DashboardUID, home.URL = dash.UID, dashboards.GetDashboardURL(dash.UID, dash.Slug)
+	}
+	cmd.HomeDashboardID = &dashboardID
+	cmd.HomeDashboardUID = &req.DashboardUID
+	if err := h.prefs.Patch(ctx, &cmd); err != nil {
+		return nil, err
+	}
+	return home, nil
+}
@@ -0,0 +1,155 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/preference/preftest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeHomePreferences stores the home dashboards of the preferences by user and team
+type fakeHomePreferences struct {
+	*preftest.FakePreferenceService
+	homes map[[2]int64]int64
+}
+
+func (f *fakeHomePreferences) Get(_ context.Context, query *pref.GetPreferenceQuery) (*pref.Preference, error) {
+	id, ok := f.homes[[2]int64{query.UserID, query.TeamID}]
+	if !ok {
+		return nil, pref.ErrPrefNotFound
+	}
+	return &pref.Preference{OrgID: query.OrgID, UserID: query.UserID, TeamID: query.TeamID, HomeDashboardID: id}, nil
+}
+
+func (f *fakeHomePreferences) Patch(_ context.Context, cmd *pref.PatchPreferenceCommand) error {
+	f.homes[[2]int64{cmd.UserID, cmd.TeamID}] = *cmd.HomeDashboardID
+	return nil
+}
+
+func TestHomeDashboards(t *testing.T) {
+	newHome := func(t *testing.T, cfg *setting.Cfg, homes map[[2]int64]int64) (*HomeDashboards, *fakeHomePreferences) {
+		svc := dashboards.NewFakeDashboardService(t)
+		svc.On("GetDashboardUIDByID", mock.Anything, mock.Anything).Return(func(_ context.Context, q *dashboards.GetDashboardRefByIDQuery) (*dashboards.DashboardRef, error) {
+			if q.ID == 404 {
+				return nil, dashboards.ErrDashboardNotFound
+			}
+			return &dashboards.DashboardRef{UID: fmt.Sprintf("uid%d", q.ID), Slug: "dash"}, nil
+		}).Maybe()
+		svc.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, q *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+			if q.UID != "uid7" {
+				return nil, dashboards.ErrDashboardNotFound
+			}
+			return &dashboards.Dashboard{ID: 7, UID: "uid7", Slug: "dash"}, nil
+		}).Maybe()
+		prefs := &fakeHomePreferences{FakePreferenceService: preftest.NewPreferenceServiceFake(), homes: homes}
+		teams := teamtest.NewFakeService()
+		teams.ExpectedTeamsByUser = []*team.TeamDTO{{ID: 3}, {ID: 2}}
+		ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+		return NewHomeDashboards(cfg, prefs, svc, teams, ac), prefs
+	}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+
+	t.Run("resolves the user, then the teams and then the org home dashboard", func(t *testing.T) {
+		homes := map[[2]int64]int64{{0, 0}: 1, {0, 2}: 2, {0, 3}: 3, {1, 0}: 4}
+		h, _ := newHome(t, setting.NewCfg(), homes)
+
+		home, err := h.Get(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, &HomeDashboard{Scope: HomeScopeUser, DashboardUID: "uid4", URL: "/d/uid4/dash"}, home)
+
+		delete(homes, [2]int64{1, 0})
+		home, err = h.Get(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, &HomeDashboard{Scope: HomeScopeTeam, TeamID: 3, DashboardUID: "uid3", URL: "/d/uid3/dash"}, home)
+
+		homes[[2]int64{0, 3}] = 404
+		home, err = h.Get(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, HomeScopeTeam, home.Scope)
+		require.Equal(t, int64(2), home.TeamID, "a deleted home dashboard is skipped")
+
+		delete(homes, [2]int64{0, 2})
+		delete(homes, [2]int64{0, 3})
+		home, err = h.Get(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, HomeScopeOrg, home.Scope)
+		require.Equal(t, "uid1", home.DashboardUID)
+	})
+
+	t.Run("falls back to the home page and the default home dashboard", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.HomePage = "/explore"
+		h, _ := newHome(t, cfg, map[[2]int64]int64{})
+		home, err := h.Get(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, &HomeDashboard{Scope: HomeScopeHomePage, URL: "/explore"}, home)
+
+		cfg = setting.NewCfg()
+		cfg.DefaultHomeDashboardPath = filepath.Join(t.TempDir(), "home.json")
+		require.NoError(t, os.WriteFile(cfg.DefaultHomeDashboardPath, []byte(`{"title": "Home"}`), 0o600))
+		h, _ = newHome(t, cfg, map[[2]int64]int64{})
+		home, err = h.Get(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, &HomeDashboard{Scope: HomeScopeDefault, Spec: map[string]any{"title": "Home"}}, home)
+	})
+
+	t.Run("sets the home dashboard of a scope", func(t *testing.T) {
+		h, prefs := newHome(t, setting.NewCfg(), map[[2]int64]int64{})
+
+		home, err := h.Set(context.Background(), user, SetHomeRequest{Scope: HomeScopeUser, DashboardUID: "uid7"})
+		require.NoError(t, err)
+		require.Equal(t, &HomeDashboard{Scope: HomeScopeUser, DashboardUID: "uid7", URL: "/d/uid7/dash"}, home)
+		require.Equal(t, int64(7), prefs.homes[[2]int64{1, 0}])
+
+		_, err = h.Set(context.Background(), user, SetHomeRequest{Scope: HomeScopeUser})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), prefs.homes[[2]int64{1, 0}], "an empty uid clears the home dashboard")
+
+		_, err = h.Set(context.Background(), user, SetHomeRequest{Scope: HomeScopeUser, DashboardUID: "missing"})
+		require.ErrorIs(t, err, ErrInvalidHome)
+		_, err = h.Set(context.Background(), user, SetHomeRequest{Scope: "folder", DashboardUID: "uid7"})
+		require.ErrorIs(t, err, ErrInvalidHome)
+		_, err = h.Set(context.Background(), user, SetHomeRequest{Scope: HomeScopeTeam, DashboardUID: "uid7"})
+		require.ErrorIs(t, err, ErrInvalidHome)
+	})
+
+	t.Run("requires the permissions of the org and the team", func(t *testing.T) {
+		h, prefs := newHome(t, setting.NewCfg(), map[[2]int64]int64{})
+
+		_, err := h.Set(context.Background(), user, SetHomeRequest{Scope: HomeScopeOrg, DashboardUID: "uid7"})
+		require.ErrorIs(t, err, ErrHomeAccessDenied)
+		_, err = h.Set(context.Background(), user, SetHomeRequest{Scope: HomeScopeTeam, TeamID: 2, DashboardUID: "uid7"})
+		require.ErrorIs(t, err, ErrHomeAccessDenied)
+
+		admin := &identity.StaticRequester{Type: claims.TypeUser, UserID: 5, OrgID: 1, Permissions: map[int64]map[string][]string{
+			1: {
+				accesscontrol.ActionOrgsPreferencesWrite: {},
+				accesscontrol.ActionTeamsWrite:           {"teams:id:2"},
+			},
+		}}
+		_, err = h.Set(context.Background(), admin, SetHomeRequest{Scope: HomeScopeOrg, DashboardUID: "uid7"})
+		require.NoError(t, err)
+		_, err = h.Set(context.Background(), admin, SetHomeRequest{Scope: HomeScopeTeam, TeamID: 2, DashboardUID: "uid7"})
+		require.NoError(t, err)
+		_, err = h.Set(context.Background(), admin, SetHomeRequest{Scope: HomeScopeTeam, TeamID: 3, DashboardUID: "uid7"})
+		require.ErrorIs(t, err, ErrHomeAccessDenied)
+		require.Equal(t, map[[2]int64]int64{{0, 0}: 7, {0, 2}: 7}, prefs.homes)
+	})
+}
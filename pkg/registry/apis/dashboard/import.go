@@ -0,0 +1,204 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+const (
+	importInputDatasource = "datasource"
+	importInputConstant   = "constant"
+)
+
+var (
+	ErrInvalidImport      = errutil.BadRequest("dashboards.import.invalid")
+	ErrImportAccessDenied = errutil.Forbidden("dashboards.import.forbidden")
+	ErrImportConflict     = errutil.Conflict("dashboards.import.conflict")
+)
+
+// ImportRequest imports a dashboard exported for sharing externally, like the dashboards of grafana.com.
+// The inputs declared in __inputs are replaced in the whole dashboard before it is saved.
+type ImportRequest struct {
+	Dashboard map[string]any `json:"dashboard"`
+	// Inputs maps the names of the inputs of the dashboard to their value, the UID of a data source for
+	// data source inputs. Constants keep the value exported with the dashboard when they are not mapped.
+	Inputs    map[string]string `json:"inputs,omitempty"`
+	FolderUID string            `json:"folderUid,omitempty"`
+	Overwrite bool              `json:"overwrite,omitempty"`
+}
+
+// importInput is an input declared in the __inputs of an exported dashboard
+type importInput struct {
+	Name     string
+	Type     string
+	PluginID string
+	Value    string
+}
+
+// DashboardImporter imports exported dashboards with the legacy services
+type DashboardImporter struct {
+	folders     folder.Service
+	dashboards  dashboards.DashboardService
+	datasources datasources.DataSourceService
+	log         log.Logger
+}
+
+func NewDashboardImporter(folders folder.Service, dashboardService dashboards.DashboardService, datasourceService datasources.DataSourceService) *DashboardImporter {
+	return &DashboardImporter{
+		folders:     folders,
+		dashboards:  dashboardService,
+		datasources: datasourceService,
+		log:         log.New("dashboard.import"),
+	}
+}
+
+// Import substitutes the inputs of the dashboard, saves it in the folder of the request and returns its UID.
+// Saving the dashboard checks that the user can create dashboards in the folder.
+func (i *DashboardImporter) Import(ctx context.Context, user identity.Requester, req ImportRequest) (string, error) {
+	if len(req.Dashboard) == 0 {
+		return "", ErrInvalidImport.Errorf("missing dashboard")
+	}
+	template := simplejson.NewFromAny(req.Dashboard)
+	if len(template.Get("__elements").MustMap()) > 0 || len(template.Get("__elements").MustArray()) > 0 {
+		return "", ErrInvalidImport.Errorf("dashboards with library panels must be imported with bundle/apply")
+	}
+
+	if req.FolderUID != "" {
+		_, err := i.folders.Get(ctx, &folder.GetFolderQuery{UID: &req.FolderUID, OrgID: user.GetOrgID(), SignedInUser: user})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+				return "", ErrInvalidImport.Errorf("folder %q not found", req.FolderUID)
+			}
+			return "", err
+		}
+	}
+
+	inputs, err := i.resolveInputs(ctx, user, template, req.Inputs)
+	if err != nil {
+		return "", err
+	}
+	generated, err := utils.NewDashTemplateEvaluator(template, inputs).Eval()
+	if err != nil {
+		return "", ErrInvalidImport.Errorf("%w", err)
+	}
+	// No need to keep these in the stored dashboard JSON
+	generated.Del("__elements")
+	generated.Del("__inputs")
+	generated.Del("__requires")
+	generated.Del("id")
+
+	var userID int64
+	if id, err := identity.UserIdentifier(user.GetID()); err == nil {
+		userID = id
+	}
+	cmd := dashboards.SaveDashboardCommand{
+		Dashboard: generated,
+		OrgID:     user.GetOrgID(),
+		UserID:    userID,
+		Overwrite: req.Overwrite,
+		FolderUID: req.FolderUID,
+		Message:   "imported",
+	}
+	saved, err := i.dashboards.ImportDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     cmd.OrgID,
+		Dashboard: cmd.GetDashboardModel(),
+		Overwrite: cmd.Overwrite,
+		Message:   cmd.Message,
+		User:      user,
+	})
+	if err != nil {
+		return "", importError(err)
+	}
+	return saved.UID, nil
+}
+
+// resolveInputs returns the values of the inputs declared by the dashboard. The data sources of the data source
+// inputs must exist in the org of the user and be of the plugin of the input.
+func (i *DashboardImporter) resolveInputs(ctx context.Context, user identity.Requester, template *simplejson.Json, mapping map[string]string) ([]dashboardimport.ImportDashboardInput, error) {
+	declared := map[string]bool{}
+	inputs := []dashboardimport.ImportDashboardInput{}
+	for _, raw := range template.Get("__inputs").MustArray() {
+		def := simplejson.NewFromAny(raw)
+		in := importInput{
+			Name:     def.Get("name").MustString(),
+			Type:     def.Get("type").MustString(),
+			PluginID: def.Get("pluginId").MustString(),
+			Value:    def.Get("value").MustString(),
+		}
+		if in.Name == "" {
+			return nil, ErrInvalidImport.Errorf("an input of the dashboard is missing a name")
+		}
+		declared[in.Name] = true
+
+		value, mapped := mapping[in.Name]
+		switch {
+		case in.Type == importInputDatasource && in.PluginID == expr.DatasourceType:
+			// expressions are not a data source of the org, the evaluator sets them
+			continue
+		case in.Type == importInputDatasource:
+			if !mapped || value == "" {
+				return nil, ErrInvalidImport.Errorf("missing data source for input %s", in.Name)
+			}
+			ds, err := i.datasources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: value, OrgID: user.GetOrgID()})
+			if err != nil {
+				if errors.Is(err, datasources.ErrDataSourceNotFound) {
+					return nil, ErrInvalidImport.Errorf("data source %q of input %s not found", value, in.Name)
+				}
+				return nil, err
+			}
+			if in.PluginID != "" && ds.Type != in.PluginID {
+				return nil, ErrInvalidImport.Errorf("data source %q of input %s is a %s data source, expected %s", value, in.Name, ds.Type, in.PluginID)
+			}
+		case !mapped && in.Type == importInputConstant:
+			value = in.Value
+		case !mapped:
+			return nil, ErrInvalidImport.Errorf("missing value for input %s", in.Name)
+		}
+		inputs = append(inputs, dashboardimport.ImportDashboardInput{Name: in.Name, Type: in.Type, PluginId: in.PluginID, Value: value})
+	}
+
+	unknown := []string{}
+	for name := range mapping {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, ErrInvalidImport.Errorf("the dashboard has no inputs named %v", unknown)
+	}
+	return inputs, nil
+}
+
+// importError returns the errors of the dashboard service with the status code they are returned with by the legacy API
+func importError(err error) error {
+	if errors.Is(err, dashboards.ErrDashboardWithSameUIDExists) {
+		return ErrImportConflict.Errorf("%w", err)
+	}
+	var dashErr dashboards.DashboardErr
+	if !errors.As(err, &dashErr) {
+		return err
+	}
+	switch dashErr.StatusCode {
+	case 400, 404:
+		return ErrInvalidImport.Errorf("%w", err)
+	case 403:
+		return ErrImportAccessDenied.Errorf("%w", err)
+	case 412:
+		return ErrImportConflict.Errorf("%w", err)
+	}
+	return fmt.Errorf("failed to import dashboard: %w", err)
+}
@@ -0,0 +1,132 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+)
+
+func TestDashboardImporter(t *testing.T) {
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Namespace: "default"}
+	exported := func() map[string]any {
+		return map[string]any{
+			"__inputs": []any{
+				map[string]any{"name": "DS_PROMETHEUS", "type": "datasource", "pluginId": "prometheus"},
+				map[string]any{"name": "VAR_ENV", "type": "constant", "value": "prod"},
+			},
+			"__requires": []any{map[string]any{"type": "datasource", "id": "prometheus"}},
+			"id":         42,
+			"uid":        "abc",
+			"title":      "Node exporter",
+			"panels": []any{
+				map[string]any{"datasource": map[string]any{"uid": "${DS_PROMETHEUS}"}, "title": "CPU in ${VAR_ENV}"},
+			},
+		}
+	}
+	folders := foldertest.NewFakeService()
+	folders.ExpectedFolder = &folder.Folder{UID: "xyz", Title: "Hosts"}
+	ds := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "p1", OrgID: 1, Type: "prometheus"},
+		{UID: "l1", OrgID: 1, Type: "loki"},
+	}}
+
+	t.Run("substitutes the inputs and saves the dashboard in the folder", func(t *testing.T) {
+		svc := dashboards.NewFakeDashboardService(t)
+		var saved *dashboards.SaveDashboardDTO
+		svc.On("ImportDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*dashboards.SaveDashboardDTO)
+		}).Return(&dashboards.Dashboard{UID: "abc"}, nil)
+
+		uid, err := NewDashboardImporter(folders, svc, ds).Import(context.Background(), user, ImportRequest{
+			Dashboard: exported(),
+			Inputs:    map[string]string{"DS_PROMETHEUS": "p1"},
+			FolderUID: "xyz",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "abc", uid)
+
+		require.Equal(t, "xyz", saved.Dashboard.FolderUID)
+		require.False(t, saved.Overwrite)
+		data := saved.Dashboard.Data
+		require.Equal(t, "p1", data.GetPath("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "CPU in prod", data.GetPath("panels").GetIndex(0).Get("title").MustString())
+		for _, key := range []string{"__inputs", "__requires", "id"} {
+			_, ok := data.CheckGet(key)
+			require.False(t, ok, key)
+		}
+	})
+
+	t.Run("maps constants", func(t *testing.T) {
+		svc := dashboards.NewFakeDashboardService(t)
+		var saved *dashboards.SaveDashboardDTO
+		svc.On("ImportDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*dashboards.SaveDashboardDTO)
+		}).Return(&dashboards.Dashboard{UID: "abc"}, nil)
+
+		_, err := NewDashboardImporter(folders, svc, ds).Import(context.Background(), user, ImportRequest{
+			Dashboard: exported(),
+			Inputs:    map[string]string{"DS_PROMETHEUS": "p1", "VAR_ENV": "dev"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "CPU in dev", saved.Dashboard.Data.GetPath("panels").GetIndex(0).Get("title").MustString())
+	})
+
+	t.Run("rejects invalid inputs", func(t *testing.T) {
+		importer := NewDashboardImporter(folders, dashboards.NewFakeDashboardService(t), ds)
+		for name, inputs := range map[string]map[string]string{
+			"missing data source": {},
+			"unknown data source": {"DS_PROMETHEUS": "nope"},
+			"wrong data source":   {"DS_PROMETHEUS": "l1"},
+			"unknown input":       {"DS_PROMETHEUS": "p1", "DS_LOKI": "l1"},
+		} {
+			_, err := importer.Import(context.Background(), user, ImportRequest{Dashboard: exported(), Inputs: inputs})
+			require.ErrorIs(t, err, ErrInvalidImport, name)
+		}
+
+		withElements := exported()
+		withElements["__elements"] = map[string]any{"lib": map[string]any{"uid": "lib"}}
+		_, err := importer.Import(context.Background(), user, ImportRequest{Dashboard: withElements, Inputs: map[string]string{"DS_PROMETHEUS": "p1"}})
+		require.ErrorIs(t, err, ErrInvalidImport)
+
+		_, err = importer.Import(context.Background(), user, ImportRequest{})
+		require.ErrorIs(t, err, ErrInvalidImport)
+	})
+
+	t.Run("rejects a missing folder", func(t *testing.T) {
+		missing := foldertest.NewFakeService()
+		missing.ExpectedError = dashboards.ErrFolderNotFound
+		_, err := NewDashboardImporter(missing, dashboards.NewFakeDashboardService(t), ds).Import(context.Background(), user, ImportRequest{
+			Dashboard: exported(),
+			Inputs:    map[string]string{"DS_PROMETHEUS": "p1"},
+			FolderUID: "nope",
+		})
+		require.ErrorIs(t, err, ErrInvalidImport)
+	})
+
+	t.Run("returns the errors of the dashboard service with their status", func(t *testing.T) {
+		for err, expected := range map[error]error{
+			dashboards.ErrDashboardWithSameUIDExists:  ErrImportConflict,
+			dashboards.ErrDashboardVersionMismatch:    ErrImportConflict,
+			dashboards.ErrDashboardUpdateAccessDenied: ErrImportAccessDenied,
+			dashboards.ErrDashboardTitleEmpty:         ErrInvalidImport,
+		} {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("ImportDashboard", mock.Anything, mock.Anything).Return(nil, err)
+			_, got := NewDashboardImporter(folders, svc, ds).Import(context.Background(), user, ImportRequest{
+				Dashboard: exported(),
+				Inputs:    map[string]string{"DS_PROMETHEUS": "p1"},
+			})
+			require.ErrorIs(t, got, expected, err.Error())
+		}
+	})
+}
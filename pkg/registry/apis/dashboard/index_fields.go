@@ -0,0 +1,167 @@
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+const (
+	// dashboardIndexKind is the kind the search index stores dashboards with
+	dashboardIndexKind = "Dashboard"
+
+	IndexFieldSchemaVersion  = "schema_version"
+	IndexFieldPanelCount     = "panel_count"
+	IndexFieldDatasourceType = "ds_type"
+)
+
+// SearchIndexFields are the fields computed from the spec of dashboards when they are indexed, so the search
+// can be used to audit all dashboards, e.g. to find the ones still saved with an old schema.
+func SearchIndexFields() []resource.IndexField {
+	return []resource.IndexField{
+		{
+			Field: IndexFieldSchemaVersion,
+			Type:  "int",
+			Value: func(spec map[string]any) (any, bool) {
+				// the index decodes specs with the unstructured decoder, integers are int64
+				switch v := spec["schemaVersion"].(type) {
+				case int64:
+					return v, true
+				case float64:
+					return int64(v), true
+				}
+				return nil, false
+			},
+		},
+		{
+			Field: IndexFieldPanelCount,
+			Type:  "int",
+			Value: func(spec map[string]any) (any, bool) {
+				count := 0
+				walkPanels(spec["panels"], func(map[string]any) { count++ })
+				return count, true
+			},
+		},
+		{
+			Field: IndexFieldDatasourceType,
+			Type:  "string[]",
+			Value: func(spec map[string]any) (any, bool) {
+				types := datasourceTypes(spec)
+				return types, len(types) > 0
+			},
+		},
+	}
+}
+
+// walkPanels calls fn with every panel, including the panels nested in rows
+func walkPanels(panels any, fn func(panel map[string]any)) {
+	list, _ := panels.([]any)
+	for _, p := range list {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		fn(panel)
+		walkPanels(panel["panels"], fn)
+	}
+}
+
+// datasourceTypes returns the sorted types of the data sources referenced by the panels and their queries.
+// References by name and to variables have no type, they are skipped.
+func datasourceTypes(spec map[string]any) []string {
+	seen := map[string]bool{}
+	add := func(ref any) {
+		if ds, ok := ref.(map[string]any); ok {
+			if t, _ := ds["type"].(string); t != "" && !strings.HasPrefix(t, "$") {
+				seen[t] = true
+			}
+		}
+	}
+	walkPanels(spec["panels"], func(panel map[string]any) {
+		add(panel["datasource"])
+		targets, _ := panel["targets"].([]any)
+		for _, t := range targets {
+			if target, ok := t.(map[string]any); ok {
+				add(target["datasource"])
+			}
+		}
+	})
+
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// fieldFilter converts a filter on an indexed field of dashboards into a query clause:
+//
+//	filter=ds_type=prometheus  dashboards using a prometheus data source
+//	filter=schema_version<30   dashboards saved with a schema older than 30
+//	filter=updated<now-1y      dashboards not updated in a year
+//	filter=views_last_30d=0    dashboards not viewed in the last 30 days
+//
+// Numeric fields also support the >, <= and >= operators, the updated and created dates and the indexed dates like
+// last_viewed_at only support them.
+// The dates are relative to now, like now-7d, or dates like 2024-01-31, see parseSearchDate.
+func fieldFilter(filter string, now time.Time) (string, error) {
+	i := strings.IndexAny(filter, "<>=")
+	if i < 1 {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, expected a field, an operator and a value", filter))
+	}
+	field, rest := filter[:i], filter[i:]
+	op := rest[:1]
+	if strings.HasPrefix(rest, "<=") || strings.HasPrefix(rest, ">=") {
+		op = rest[:2]
+	}
+	value := rest[len(op):]
+	if value == "" {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, missing a value", filter))
+	}
+
+	if alias, ok := searchFieldAliases[field]; ok {
+		field = alias
+	}
+	dateField, isDate := searchDateFields[field]
+	fieldType, indexed := resource.IndexFieldType(dashboardIndexKind, field)
+	if !isDate && fieldType == "time" {
+		dateField, isDate = "Spec."+field, true
+	}
+	if isDate {
+		if op == "=" {
+			return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, %s only supports <, <=, > and >=", filter, field))
+		}
+		date, err := parseSearchDate(value, now)
+		if err != nil {
+			return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, %s", filter, err))
+		}
+		return dateField + ":" + op + `"` + date.UTC().Format(time.RFC3339) + `"`, nil
+	}
+	if !indexed {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, %s is not an indexed field", filter, field))
+	}
+	switch fieldType {
+	case "int", "int64", "float64":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, %s is a number", filter, field))
+		}
+		if op == "=" {
+			// bleve matches numbers with an inclusive range
+			return "+Spec." + field + ":>=" + value + " +Spec." + field + ":<=" + value, nil
+		}
+		return "Spec." + field + ":" + op + value, nil
+	case "string", "string[]":
+		if op != "=" {
+			return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, %s only supports =", filter, field))
+		}
+		return "Spec." + field + `:"` + termEscaper.Replace(value) + `"`, nil
+	}
+	return "", apierrors.NewBadRequest(fmt.Sprintf("invalid filter %q, %s can not be filtered", filter, field))
+}
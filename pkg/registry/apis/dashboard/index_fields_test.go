@@ -0,0 +1,75 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestSearchIndexFields(t *testing.T) {
+	spec := map[string]any{
+		"schemaVersion": int64(27),
+		"panels": []any{
+			map[string]any{"type": "timeseries", "datasource": map[string]any{"type": "prometheus", "uid": "p1"}},
+			map[string]any{"type": "row", "panels": []any{
+				map[string]any{"type": "table", "datasource": "Old", "targets": []any{
+					map[string]any{"datasource": map[string]any{"type": "loki", "uid": "l1"}},
+					map[string]any{"datasource": map[string]any{"type": "prometheus", "uid": "p2"}},
+				}},
+			}},
+		},
+	}
+	values := map[string]any{}
+	for _, f := range SearchIndexFields() {
+		if v, ok := f.Value(spec); ok {
+			values[f.Field] = v
+		}
+	}
+	require.Equal(t, map[string]any{
+		IndexFieldSchemaVersion:  int64(27),
+		IndexFieldPanelCount:     3,
+		IndexFieldDatasourceType: []string{"loki", "prometheus"},
+	}, values)
+}
+
+func TestFieldFilter(t *testing.T) {
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, SearchIndexFields()...))
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, NewDashboardViews(kvstore.NewFakeKVStore(), nil, nil).IndexFields()...))
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for filter, clause := range map[string]string{
+		"ds_type=prometheus":  `Spec.ds_type:"prometheus"`,
+		"schema_version<30":   "Spec.schema_version:<30",
+		"panel_count>=50":     "Spec.panel_count:>=50",
+		"panel_count=0":       "+Spec.panel_count:>=0 +Spec.panel_count:<=0",
+		"title=CPU":           `Spec.title:"CPU"`,
+		"panels>20":           "Spec.panel_count:>20",
+		"updated<now-7d":      `UpdatedAt:<"2024-03-03T12:00:00Z"`,
+		"created>=2024-01-31": `CreatedAt:>="2024-01-31T00:00:00Z"`,
+		"views_last_30d=0":    "+Spec.views_last_30d:>=0 +Spec.views_last_30d:<=0",
+		"views>100":           "Spec.views_last_30d:>100",
+		"lastViewed<now-90d":  `Spec.last_viewed_at:<"2023-12-11T12:00:00Z"`,
+	} {
+		actual, err := fieldFilter(filter, now)
+		require.NoError(t, err, filter)
+		require.Equal(t, clause, actual, filter)
+	}
+
+	for filter, msg := range map[string]string{
+		"prometheus":          "expected a field, an operator and a value",
+		"ds_type=":            "missing a value",
+		"stars>10":            "stars is not an indexed field",
+		"last_viewed_at=now":  "last_viewed_at only supports <, <=, > and >=",
+		"schema_version<old":  "schema_version is a number",
+		"ds_type>=prometheus": "ds_type only supports =",
+		"updated=now":         "updated only supports <, <=, > and >=",
+		"updated<last-week":   "invalid date last-week",
+	} {
+		_, err := fieldFilter(filter, now)
+		require.ErrorContains(t, err, msg, filter)
+	}
+}
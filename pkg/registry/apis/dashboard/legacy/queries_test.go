@@ -69,6 +69,13 @@ func TestDashboardQueries(t *testing.T) {
 						LastID: 22,
 					}),
 				},
+				{
+					Name: "dashboard_summary",
+					Data: getQuery(&DashboardQuery{
+						OrgID:   2,
+						Summary: true,
+					}),
+				},
 			},
 			sqlQueryPanels: {
 				{
@@ -12,7 +12,7 @@ SELECT
     dashboard_version.version, dashboard_version.message, dashboard_version.data
     {{ else }}
     dashboard.updated, updated_user.uid as updated_by, dashboard.updated_by   as updated_by_id,
-    dashboard.version, '' as message, dashboard.data
+    dashboard.version, '' as message, {{ if .Query.Summary }}dashboard.title{{ else }}dashboard.data{{ end }}
     {{ end }}
     FROM {{ .Ident .DashboardTable }} as dashboard
     {{ if .Query.UseHistoryTable }}
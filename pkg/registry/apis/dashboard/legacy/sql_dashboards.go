@@ -33,6 +33,9 @@ var (
 	_ DashboardAccess = (*dashboardSqlAccess)(nil)
 )
 
+// PluginRepositoryName is the repository name of dashboards installed by an app plugin, the plugin ID is the repository path
+const PluginRepositoryName = "plugin"
+
 type dashboardRow struct {
 	// The numeric version for this dashboard
 	RV int64
@@ -109,8 +112,9 @@ func (a *dashboardSqlAccess) getRows(ctx context.Context, sql *legacysql.LegacyD
 		rows = nil
 	}
 	return &rowsWrapper{
-		rows: rows,
-		a:    a,
+		rows:    rows,
+		a:       a,
+		summary: query.Summary,
 		// This looks up rules from the permissions on a user
 		canReadDashboard: func(scopes ...string) bool {
 			return true // ???
@@ -125,6 +129,9 @@ type rowsWrapper struct {
 	a    *dashboardSqlAccess
 	rows *sql.Rows
 
+	// summary rows have the title of the dashboards instead of their spec
+	summary bool
+
 	canReadDashboard func(scopes ...string) bool
 
 	// Current
@@ -151,7 +158,7 @@ func (r *rowsWrapper) Next() bool {
 
 	// breaks after first readable value
 	for r.rows.Next() {
-		r.row, err = r.a.scanRow(r.rows)
+		r.row, err = r.a.scanRow(r.rows, r.summary)
 		if err != nil {
 			r.err = err
 			return false
@@ -212,7 +219,7 @@ func (r *rowsWrapper) Value() []byte {
 	return b
 }
 
-func (a *dashboardSqlAccess) scanRow(rows *sql.Rows) (*dashboardRow, error) {
+func (a *dashboardSqlAccess) scanRow(rows *sql.Rows, summary bool) (*dashboardRow, error) {
 	dash := &dashboard.Dashboard{
 		TypeMeta:   dashboard.DashboardResourceInfo.TypeMeta(),
 		ObjectMeta: metav1.ObjectMeta{Annotations: make(map[string]string)},
@@ -237,7 +244,7 @@ func (a *dashboardSqlAccess) scanRow(rows *sql.Rows) (*dashboardRow, error) {
 	var origin_path sql.NullString
 	var origin_ts sql.NullInt64
 	var origin_hash sql.NullString
-	var data []byte // the dashboard JSON
+	var data []byte // the dashboard JSON, or the title of a summary
 	var version int64
 
 	err := rows.Scan(&orgId, &dashboard_id, &dash.Name, &folder_uid,
@@ -295,11 +302,16 @@ func (a *dashboardSqlAccess) scanRow(rows *sql.Rows) (*dashboardRow, error) {
 			})
 		} else if plugin_id != "" {
 			meta.SetRepositoryInfo(&utils.ResourceRepositoryInfo{
-				Name: "plugin",
+				Name: PluginRepositoryName,
 				Path: plugin_id,
 			})
 		}
 
+		if summary {
+			meta.SetAnnotation(dashboard.AnnoKeyTitle, string(data))
+			return row, nil
+		}
+
 		if len(data) > 0 {
 			err = dash.Spec.UnmarshalJSON(data)
 			if err != nil {
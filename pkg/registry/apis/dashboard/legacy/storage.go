@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -209,6 +210,57 @@ func (a *dashboardSqlAccess) ListIterator(ctx context.Context, req *resource.Lis
 	return listRV, err
 }
 
+// ListDashboardSummaries lists the dashboards like ListIterator, but only reads their title instead of their spec
+func (a *dashboardSqlAccess) ListDashboardSummaries(ctx context.Context, query DashboardSummaryQuery) (*metav1.PartialObjectMetadataList, error) {
+	token, err := readContinueToken(query.Continue)
+	if err != nil {
+		return nil, err
+	}
+	if token.orgId > 0 && token.orgId != query.OrgID {
+		return nil, fmt.Errorf("token and orgID mismatch")
+	}
+	if query.Limit < 1 {
+		query.Limit = 50 // the default page size of the resource server
+	}
+
+	sql, err := a.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+	listRV, err := sql.GetResourceVersion(ctx, "dashboard", "updated")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.getRows(ctx, sql, &DashboardQuery{
+		OrgID:   query.OrgID,
+		LastID:  token.id,
+		Summary: true,
+	})
+	if rows != nil {
+		defer func() {
+			_ = rows.Close()
+		}()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metav1.PartialObjectMetadataList{
+		ListMeta: metav1.ListMeta{ResourceVersion: strconv.FormatInt(listRV, 10)},
+	}
+	for rows.Next() {
+		list.Items = append(list.Items, metav1.PartialObjectMetadata{ObjectMeta: rows.row.Dash.ObjectMeta})
+		if len(list.Items) >= int(query.Limit) {
+			next := rows.ContinueToken()
+			if rows.Next() {
+				list.Continue = next
+			}
+			break
+		}
+	}
+	return list, rows.Error()
+}
+
 // Watch implements AppendingStore.
 func (a *dashboardSqlAccess) WatchWriteEvents(ctx context.Context) (<-chan *resource.WrittenEvent, error) {
 	stream := make(chan *resource.WrittenEvent, 10)
@@ -3,6 +3,8 @@ package legacy
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 )
@@ -27,12 +29,23 @@ type DashboardQuery struct {
 
 	// The label requirements
 	Labels []*resource.Requirement
+
+	// Only read the title of the dashboards, not their spec
+	Summary bool
 }
 
 func (r *DashboardQuery) UseHistoryTable() bool {
 	return r.GetHistory || r.Version > 0
 }
 
+type DashboardSummaryQuery struct {
+	OrgID int64
+	Limit int64
+
+	// The continue token of the previous page
+	Continue string
+}
+
 type LibraryPanelQuery struct {
 	OrgID int64
 	UID   string // to select a single dashboard
@@ -51,6 +64,9 @@ type DashboardAccess interface {
 	SaveDashboard(ctx context.Context, orgId int64, dash *dashboard.Dashboard) (*dashboard.Dashboard, bool, error)
 	DeleteDashboard(ctx context.Context, orgId int64, uid string) (*dashboard.Dashboard, bool, error)
 
+	// List the metadata of the dashboards, with their title in the dashboard.AnnoKeyTitle annotation
+	ListDashboardSummaries(ctx context.Context, query DashboardSummaryQuery) (*metav1.PartialObjectMetadataList, error)
+
 	// Get a typed list
 	GetLibraryPanels(ctx context.Context, query LibraryPanelQuery) (*dashboard.LibraryPanelList, error)
 }
@@ -49,5 +49,9 @@ func (s *DashboardStorage) NewStore(scheme *runtime.Scheme, defaultOptsGetter ge
 	optsGetter := apistore.NewRESTOptionsGetterForClient(client,
 		defaultOpts.StorageConfig.Config,
 	)
-	return grafanaregistry.NewRegistryStore(scheme, resourceInfo, optsGetter)
+	store, err := grafanaregistry.NewRegistryStore(scheme, resourceInfo, optsGetter)
+	if err != nil {
+		return nil, err
+	}
+	return WithSummaryList(store, s.Access), nil
 }
@@ -0,0 +1,270 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// libraryPanelGCPageSize is the number of dashboards searched at once when looking for orphaned references
+const libraryPanelGCPageSize = 500
+
+// ErrLibraryPanelGCAccessDenied is returned when a non admin user lists or inlines the orphaned library panel references.
+var ErrLibraryPanelGCAccessDenied = errutil.Forbidden("dashboards.librarypanels.gc.forbidden", errutil.WithPublicMessage("Only org admins can collect orphaned library panel references"))
+
+// libraryPanelGCPermissions are the permissions of the background job in every org
+var libraryPanelGCPermissions = []accesscontrol.Permission{
+	{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+}
+
+// OrphanedLibraryPanel is a panel of a dashboard referencing a library panel that does not exist anymore.
+type OrphanedLibraryPanel struct {
+	DashboardUID     string `json:"dashboardUid"`
+	DashboardTitle   string `json:"dashboardTitle"`
+	PanelID          int64  `json:"panelId,omitempty"`
+	LibraryPanelUID  string `json:"libraryPanelUid"`
+	LibraryPanelName string `json:"libraryPanelName,omitempty"`
+}
+
+// LibraryPanelGCReport lists the orphaned library panel references of the dashboards of a namespace.
+type LibraryPanelGCReport struct {
+	Namespace string `json:"namespace"`
+	// Dashboards is the number of dashboards that were scanned
+	Dashboards int                    `json:"dashboards"`
+	Orphans    []OrphanedLibraryPanel `json:"orphans"`
+	// Inlined are the UIDs of the dashboards whose orphaned references were replaced with inlined panels
+	Inlined []string `json:"inlined,omitempty"`
+}
+
+// LibraryPanelGarbageCollector finds the panels of dashboards referencing library panels that were deleted, which
+// dashboards cannot render. It runs in the background for every org, and on demand for a namespace.
+type LibraryPanelGarbageCollector struct {
+	cfg        setting.DashboardLibraryPanelGCSettings
+	features   featuremgmt.FeatureToggles
+	dashboards dashboards.DashboardService
+	namespacer request.NamespaceMapper
+	// orgIDs returns the orgs with dashboards, and libraryPanels the UIDs of the library panels of an org whatever
+	// the folder they are in, so a panel the user cannot read is not mistaken for a deleted one
+	orgIDs        func(ctx context.Context) ([]int64, error)
+	libraryPanels func(ctx context.Context, orgID int64) (map[string]bool, error)
+	log           log.Logger
+}
+
+func ProvideLibraryPanelGarbageCollector(cfg *setting.Cfg, features featuremgmt.FeatureToggles, sql db.DB, dashboardService dashboards.DashboardService) *LibraryPanelGarbageCollector {
+	return &LibraryPanelGarbageCollector{
+		cfg:        cfg.DashboardLibraryPanelGC,
+		features:   features,
+		dashboards: dashboardService,
+		namespacer: request.GetNamespaceMapper(cfg),
+		orgIDs: func(ctx context.Context) ([]int64, error) {
+			var orgIDs []int64
+			err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+				return sess.SQL("SELECT DISTINCT org_id FROM dashboard").Find(&orgIDs)
+			})
+			return orgIDs, err
+		},
+		libraryPanels: func(ctx context.Context, orgID int64) (map[string]bool, error) {
+			var uids []string
+			err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+				return sess.SQL("SELECT uid FROM library_element WHERE org_id = ?", orgID).Find(&uids)
+			})
+			existing := make(map[string]bool, len(uids))
+			for _, uid := range uids {
+				existing[uid] = true
+			}
+			return existing, err
+		},
+		log: log.New("grafana-apiserver.dashboards.librarypanels.gc"),
+	}
+}
+
+// IsDisabled returns true when the job is not enabled or the dashboards API is not registered
+func (gc *LibraryPanelGarbageCollector) IsDisabled() bool {
+	return !gc.cfg.Enabled || (!gc.features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) &&
+		!gc.features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI))
+}
+
+func (gc *LibraryPanelGarbageCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(gc.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			gc.runOnce(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (gc *LibraryPanelGarbageCollector) runOnce(ctx context.Context) {
+	orgIDs, err := gc.orgIDs(ctx)
+	if err != nil {
+		gc.log.Error("Failed to fetch organizations with dashboards", "err", err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		user := accesscontrol.BackgroundUser("library_panel_gc", orgID, org.RoleAdmin, libraryPanelGCPermissions)
+		report, err := gc.Collect(ctx, user, gc.cfg.Inline)
+		if err != nil {
+			gc.log.Error("Failed to collect orphaned library panel references", "orgId", orgID, "err", err)
+			continue
+		}
+		if len(report.Orphans) > 0 {
+			gc.log.Warn("Dashboards reference deleted library panels", "namespace", report.Namespace, "orphans", len(report.Orphans), "inlined", len(report.Inlined))
+		}
+	}
+}
+
+// Collect reports the panels of the dashboards of the org of the user that reference deleted library panels.
+// With inline, the references are replaced with an inlined copy of the panel and the dashboards are saved.
+func (gc *LibraryPanelGarbageCollector) Collect(ctx context.Context, user identity.Requester, inline bool) (*LibraryPanelGCReport, error) {
+	if !user.HasRole(org.RoleAdmin) {
+		return nil, ErrLibraryPanelGCAccessDenied.Errorf("user is not an org admin")
+	}
+	orgID := user.GetOrgID()
+	existing, err := gc.libraryPanels(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the library panels: %w", err)
+	}
+
+	report := &LibraryPanelGCReport{Namespace: gc.namespacer(orgID), Orphans: []OrphanedLibraryPanel{}}
+	for page := int64(1); ; page++ {
+		hits, err := gc.dashboards.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+			OrgId:        orgID,
+			SignedInUser: user,
+			Type:         string(model.DashHitDB),
+			Limit:        libraryPanelGCPageSize,
+			Page:         page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			if err := gc.collectDashboard(ctx, user, hit.UID, existing, inline, report); err != nil {
+				return nil, err
+			}
+		}
+		if len(hits) < libraryPanelGCPageSize {
+			return report, nil
+		}
+	}
+}
+
+// collectDashboard adds the orphaned references of a dashboard to the report, and inlines them when requested
+func (gc *LibraryPanelGarbageCollector) collectDashboard(ctx context.Context, user identity.Requester, uid string, existing map[string]bool, inline bool, report *LibraryPanelGCReport) error {
+	dash, err := gc.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()})
+	if err != nil {
+		return fmt.Errorf("failed to load dashboard %s: %w", uid, err)
+	}
+	report.Dashboards++
+	if dash.Data == nil {
+		return nil
+	}
+	spec, _ := simplejson.NewFromAny(dash.Data.Interface()).Interface().(map[string]any)
+	orphans := orphanedLibraryPanels(spec, existing)
+	if len(orphans) == 0 {
+		return nil
+	}
+	for _, panel := range orphans {
+		lib := panel["libraryPanel"].(map[string]any)
+		orphan := OrphanedLibraryPanel{DashboardUID: dash.UID, DashboardTitle: dash.Title}
+		orphan.PanelID, _ = panelID(panel)
+		orphan.LibraryPanelUID, _ = lib["uid"].(string)
+		orphan.LibraryPanelName, _ = lib["name"].(string)
+		report.Orphans = append(report.Orphans, orphan)
+	}
+	if !inline {
+		return nil
+	}
+
+	for _, panel := range orphans {
+		inlineLibraryPanel(panel)
+	}
+	dash.Data = simplejson.NewFromAny(spec)
+	_, err = gc.dashboards.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     user.GetOrgID(),
+		User:      user,
+		Message:   "inlined deleted library panels",
+		Dashboard: dash,
+	}, false)
+	if err != nil {
+		return fmt.Errorf("failed to save dashboard %s: %w", uid, err)
+	}
+	report.Inlined = append(report.Inlined, dash.UID)
+	gc.log.Info("Inlined deleted library panels", "dashboard", dash.UID, "orgId", user.GetOrgID(), "panels", len(orphans))
+	return nil
+}
+
+// orphanedLibraryPanels returns the panels of the spec, including panels nested in rows, referencing a library panel
+// that does not exist.
+func orphanedLibraryPanels(spec map[string]any, existing map[string]bool) []map[string]any {
+	var orphans []map[string]any
+	var walk func(panels any)
+	walk = func(panels any) {
+		list, ok := panels.([]any)
+		if !ok {
+			return
+		}
+		for _, p := range list {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if lib, ok := panel["libraryPanel"].(map[string]any); ok {
+				if uid, ok := lib["uid"].(string); ok && uid != "" && !existing[uid] {
+					orphans = append(orphans, panel)
+				}
+			}
+			walk(panel["panels"])
+		}
+	}
+	walk(spec["panels"])
+	return orphans
+}
+
+// inlineLibraryPanel replaces the reference of a panel to a deleted library panel with the panel itself. The model
+// kept in the reference by older dashboards is copied into the panel. Without a model nor a type, the panel becomes
+// a text panel telling the library panel was deleted, so the dashboard still renders it.
+func inlineLibraryPanel(panel map[string]any) {
+	lib, _ := panel["libraryPanel"].(map[string]any)
+	delete(panel, "libraryPanel")
+	if m, ok := lib["model"].(map[string]any); ok {
+		for k, v := range m {
+			if _, ok := panel[k]; ok || k == "id" || k == "gridPos" || k == "libraryPanel" {
+				continue
+			}
+			panel[k] = v
+		}
+	}
+	uid, _ := lib["uid"].(string)
+	name, _ := lib["name"].(string)
+	if _, ok := panel["title"]; !ok && name != "" {
+		panel["title"] = name
+	}
+	if _, ok := panel["type"]; !ok {
+		panel["type"] = "text"
+		panel["options"] = map[string]any{
+			"mode":    "markdown",
+			"content": fmt.Sprintf("The library panel %q (%s) used by this panel was deleted.", name, uid),
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func TestLibraryPanelGarbageCollector(t *testing.T) {
+	spec := func() map[string]any {
+		return map[string]any{
+			"title": "Overview",
+			"panels": []any{
+				map[string]any{"id": 1, "gridPos": map[string]any{"x": 0}, "libraryPanel": map[string]any{"uid": "cpu", "name": "CPU"}},
+				map[string]any{"id": 2, "libraryPanel": map[string]any{"uid": "deleted", "name": "Memory"}},
+				map[string]any{"id": 3, "type": "row", "panels": []any{
+					map[string]any{"id": 4, "libraryPanel": map[string]any{"uid": "old", "name": "Disk", "model": map[string]any{
+						"id": 40, "type": "timeseries", "title": "Disk usage", "targets": []any{map[string]any{"refId": "A"}},
+					}}},
+				}},
+			},
+		}
+	}
+	existing := map[string]bool{"cpu": true}
+
+	t.Run("finds the references to deleted library panels in rows", func(t *testing.T) {
+		orphans := orphanedLibraryPanels(spec(), existing)
+		require.Len(t, orphans, 2)
+		require.Equal(t, 2, orphans[0]["id"])
+		require.Equal(t, 4, orphans[1]["id"])
+	})
+
+	t.Run("inlines the model kept by the reference", func(t *testing.T) {
+		panel := spec()["panels"].([]any)[2].(map[string]any)["panels"].([]any)[0].(map[string]any)
+		inlineLibraryPanel(panel)
+		require.Equal(t, map[string]any{
+			"id": 4, "type": "timeseries", "title": "Disk usage", "targets": []any{map[string]any{"refId": "A"}},
+		}, panel)
+	})
+
+	t.Run("inlines a text panel without a model", func(t *testing.T) {
+		panel := spec()["panels"].([]any)[1].(map[string]any)
+		inlineLibraryPanel(panel)
+		require.NotContains(t, panel, "libraryPanel")
+		require.Equal(t, "text", panel["type"])
+		require.Equal(t, "Memory", panel["title"])
+		require.Contains(t, panel["options"].(map[string]any)["content"], `"Memory" (deleted)`)
+	})
+
+	newGC := func(t *testing.T) (*LibraryPanelGarbageCollector, *dashboards.FakeDashboardService) {
+		svc := dashboards.NewFakeDashboardService(t)
+		svc.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{{UID: "abc"}}, nil).Maybe()
+		svc.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, q *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+			return &dashboards.Dashboard{ID: 1, UID: q.UID, OrgID: q.OrgID, Title: "Overview", Data: simplejson.NewFromAny(spec())}, nil
+		}).Maybe()
+		return &LibraryPanelGarbageCollector{
+			dashboards: svc,
+			namespacer: func(orgID int64) string { return "default" },
+			libraryPanels: func(ctx context.Context, orgID int64) (map[string]bool, error) {
+				return existing, nil
+			},
+			log: log.NewNopLogger(),
+		}, svc
+	}
+	admin := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, OrgRole: identity.RoleAdmin}
+
+	t.Run("requires an org admin", func(t *testing.T) {
+		gc, _ := newGC(t)
+		editor := &identity.StaticRequester{Type: claims.TypeUser, UserID: 2, OrgID: 1, OrgRole: identity.RoleEditor}
+		_, err := gc.Collect(context.Background(), editor, false)
+		require.ErrorIs(t, err, ErrLibraryPanelGCAccessDenied)
+	})
+
+	t.Run("reports the orphaned references", func(t *testing.T) {
+		gc, _ := newGC(t)
+		report, err := gc.Collect(context.Background(), admin, false)
+		require.NoError(t, err)
+		require.Equal(t, &LibraryPanelGCReport{
+			Namespace:  "default",
+			Dashboards: 1,
+			Orphans: []OrphanedLibraryPanel{
+				{DashboardUID: "abc", DashboardTitle: "Overview", PanelID: 2, LibraryPanelUID: "deleted", LibraryPanelName: "Memory"},
+				{DashboardUID: "abc", DashboardTitle: "Overview", PanelID: 4, LibraryPanelUID: "old", LibraryPanelName: "Disk"},
+			},
+		}, report)
+	})
+
+	t.Run("saves the dashboards with inlined panels", func(t *testing.T) {
+		gc, svc := newGC(t)
+		var saved *dashboards.SaveDashboardDTO
+		svc.On("SaveDashboard", mock.Anything, mock.Anything, false).Return(func(_ context.Context, dto *dashboards.SaveDashboardDTO, _ bool) (*dashboards.Dashboard, error) {
+			saved = dto
+			return dto.Dashboard, nil
+		}).Once()
+
+		report, err := gc.Collect(context.Background(), admin, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc"}, report.Inlined)
+		require.NotNil(t, saved)
+		spec, _ := saved.Dashboard.Data.Interface().(map[string]any)
+		require.Len(t, orphanedLibraryPanels(spec, existing), 0)
+		require.Equal(t, "cpu", saved.Dashboard.Data.Get("panels").GetIndex(0).Get("libraryPanel").Get("uid").MustString())
+	})
+}
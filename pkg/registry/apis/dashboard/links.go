@@ -0,0 +1,258 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/grafana/authlib/claims"
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// dashboardLinkPath matches the paths of the links to dashboards, with or without the sub path of the instance
+var dashboardLinkPath = regexp.MustCompile(`(?:^|/)d/([^/?#]+)`)
+
+// LinkValidator checks the URLs of the dashboard links, panel links and data links of the saved dashboards: the
+// scheme and the host of the external links, and the dashboards of the internal links. The links are checked with
+// the settings of the org of the dashboard, the invalid links are reported as warnings or reject the dashboard.
+type LinkValidator struct {
+	settings setting.DashboardLinkValidationSettings
+}
+
+func NewLinkValidator(cfg *setting.Cfg) *LinkValidator {
+	return &LinkValidator{settings: cfg.DashboardLinkValidation}
+}
+
+// linkProblem is an invalid link of a dashboard
+type linkProblem struct {
+	path *field.Path
+	url  string
+	msg  string
+}
+
+// Validate checks the links of created and updated dashboards. It rejects the dashboards with invalid links with
+// 422 Unprocessable Entity in the reject mode, and adds a warning to the response for every invalid link otherwise.
+func (v *LinkValidator) Validate(ctx context.Context, a admission.Attributes, getter rest.Getter) error {
+	if v == nil || a.GetObject() == nil {
+		return nil
+	}
+	if a.GetResource().Resource != dashboard.DashboardResourceInfo.GroupResource().Resource || a.GetSubresource() != "" {
+		return nil
+	}
+	if op := a.GetOperation(); op != admission.Create && op != admission.Update {
+		return nil
+	}
+	var orgID int64
+	if info, err := claims.ParseNamespace(a.GetNamespace()); err == nil {
+		orgID = info.OrgID
+	}
+	settings := v.settings.ForOrg(orgID)
+	if settings.Mode == "" || settings.Mode == setting.DashboardLinkValidationOff {
+		return nil
+	}
+
+	spec, err := linkSpec(a.GetObject())
+	if err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("invalid dashboard spec: %s", err))
+	}
+	if !settings.CheckDashboardUIDs || a.GetNamespace() == "" {
+		getter = nil
+	} else {
+		ctx = request.WithNamespace(ctx, a.GetNamespace())
+	}
+	problems, err := checkLinks(ctx, spec, settings, getter)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if settings.Mode == setting.DashboardLinkValidationReject {
+		errs := make(field.ErrorList, 0, len(problems))
+		for _, p := range problems {
+			errs = append(errs, field.Invalid(p.path, p.url, p.msg))
+		}
+		return apierrors.NewInvalid(dashboard.DashboardResourceInfo.GroupVersionKind().GroupKind(), a.GetName(), errs)
+	}
+	for _, p := range problems {
+		warning.AddWarning(ctx, "", fmt.Sprintf("%s: %s", p.path, p.msg))
+	}
+	return nil
+}
+
+// linkSpec returns the spec of a dashboard as a map, the specs of the versions after v1 are typed
+func linkSpec(obj any) (map[string]any, error) {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := meta.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	if u, ok := spec.(commonV0.Unstructured); ok {
+		return u.Object, nil
+	}
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{}
+	return res, json.Unmarshal(b, &res)
+}
+
+// checkLinks returns the invalid links of the spec, found in every links list of the spec: the links of the
+// dashboard, and the links and data links of the panels, including the field overrides and the nested panels.
+// The dashboards of the internal links are looked up with the getter when it is set.
+func checkLinks(ctx context.Context, spec map[string]any, settings setting.DashboardLinkValidation, getter rest.Getter) ([]linkProblem, error) {
+	problems := []linkProblem{}
+	exists := map[string]bool{}
+	var err error
+	walkLinks(spec, field.NewPath("spec"), func(path *field.Path, link string) {
+		if err != nil {
+			return
+		}
+		msg, uid := checkLinkURL(link, settings)
+		if msg == "" && uid != "" && getter != nil {
+			found, checked := exists[uid]
+			if !checked {
+				_, getErr := getter.Get(ctx, uid, &metav1.GetOptions{})
+				switch {
+				case getErr == nil:
+					found = true
+				case apierrors.IsNotFound(getErr):
+					found = false
+				case apierrors.IsForbidden(getErr):
+					// the dashboard exists, the user can not read it
+					found = true
+				default:
+					err = getErr
+					return
+				}
+				exists[uid] = found
+			}
+			if !found {
+				msg = fmt.Sprintf("the linked dashboard %q does not exist", uid)
+			}
+		}
+		if msg != "" {
+			problems = append(problems, linkProblem{path: path, url: link, msg: msg})
+		}
+	})
+	return problems, err
+}
+
+// walkLinks calls fn with the URL of every item of the lists named links, in the order of the keys of the spec
+func walkLinks(v any, path *field.Path, fn func(path *field.Path, link string)) {
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if links, ok := t[k].([]any); ok && k == "links" {
+				for i, l := range links {
+					if link, ok := l.(map[string]any); ok {
+						if u, ok := link["url"].(string); ok {
+							fn(path.Child(k).Index(i).Child("url"), u)
+						}
+					}
+				}
+				continue
+			}
+			walkLinks(t[k], path.Child(k), fn)
+		}
+	case []any:
+		for i, item := range t {
+			walkLinks(item, path.Index(i), fn)
+		}
+	}
+}
+
+// checkLinkURL checks the scheme and the host of a link. It returns why the link is not allowed, or the UID of the
+// dashboard of an internal link. The parts of the links with template variables are not checked, their value is
+// only known when the dashboard is rendered.
+func checkLinkURL(link string, settings setting.DashboardLinkValidation) (string, string) {
+	// browsers ignore the leading spaces and the tabs and new lines of the URLs, e.g. "java\tscript:"
+	link = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, strings.TrimLeftFunc(link, func(r rune) bool { return r <= ' ' }))
+	if link == "" {
+		return "", ""
+	}
+
+	scheme := ""
+	if i := strings.IndexAny(link, ":/?#"); i > 0 && link[i] == ':' {
+		scheme = strings.ToLower(link[:i])
+	}
+	if strings.ContainsAny(scheme, "${") {
+		return "", ""
+	}
+	if scheme != "" && !containsFold(settings.AllowedSchemes, scheme) {
+		return fmt.Sprintf("the %s: scheme is not allowed in links", scheme), ""
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		if strings.Contains(link, "$") {
+			return "", ""
+		}
+		return fmt.Sprintf("the link is not a valid URL: %s", err), ""
+	}
+	if u.Host != "" {
+		host := strings.ToLower(u.Hostname())
+		if len(settings.AllowedHosts) > 0 && !strings.Contains(host, "$") && !hostAllowed(settings.AllowedHosts, host) {
+			return fmt.Sprintf("links to %s are not allowed", host), ""
+		}
+		return "", ""
+	}
+	if scheme != "" {
+		return "", ""
+	}
+	if m := dashboardLinkPath.FindStringSubmatch(u.Path); m != nil && !strings.Contains(m[1], "$") {
+		return "", m[1]
+	}
+	return "", ""
+}
+
+func hostAllowed(allowed []string, host string) bool {
+	for _, a := range allowed {
+		if a == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(a, "*"); ok && strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCheckLinkURL(t *testing.T) {
+	settings := setting.DashboardLinkValidation{
+		Mode:           setting.DashboardLinkValidationReject,
+		AllowedSchemes: []string{"http", "https", "mailto"},
+		AllowedHosts:   []string{"grafana.com", "*.example.com"},
+	}
+	tests := []struct {
+		link string
+		msg  string
+		uid  string
+	}{
+		{link: "https://grafana.com/docs"},
+		{link: "https://play.example.com/runbook?id=1"},
+		{link: "https://example.com", msg: "links to example.com are not allowed"},
+		{link: "https://evil.com", msg: "links to evil.com are not allowed"},
+		{link: "mailto:oncall@example.com"},
+		{link: "javascript:alert(1)", msg: "the javascript: scheme is not allowed in links"},
+		{link: " java\tscript:alert(1)", msg: "the javascript: scheme is not allowed in links"},
+		{link: "${scheme}://anything"},
+		{link: "https://${host}/path"},
+		{link: "/d/abc/overview?var-a=1", uid: "abc"},
+		{link: "/grafana/d/abc", uid: "abc"},
+		{link: "d/${uid}"},
+		{link: "/explore?left=1"},
+		{link: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.link, func(t *testing.T) {
+			msg, uid := checkLinkURL(tc.link, settings)
+			require.Equal(t, tc.msg, msg)
+			require.Equal(t, tc.uid, uid)
+		})
+	}
+}
+
+func TestWalkLinks(t *testing.T) {
+	spec := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"links": [{"url": "https://a"}, {"title": "no url"}],
+		"panels": [
+			{"links": [{"url": "https://b"}], "fieldConfig": {"defaults": {"links": [{"url": "https://c"}]}}},
+			{"panels": [{"links": [{"url": "https://d"}]}]}
+		]
+	}`), &spec))
+
+	found := map[string]string{}
+	walkLinks(spec, field.NewPath("spec"), func(path *field.Path, link string) {
+		found[path.String()] = link
+	})
+	require.Equal(t, map[string]string{
+		"spec.links[0].url":                                "https://a",
+		"spec.panels[0].links[0].url":                      "https://b",
+		"spec.panels[0].fieldConfig.defaults.links[0].url": "https://c",
+		"spec.panels[1].panels[0].links[0].url":            "https://d",
+	}, found)
+}
+
+func TestLinkValidator(t *testing.T) {
+	attributes := func(links ...string) admission.Attributes {
+		items := []any{}
+		for _, l := range links {
+			items = append(items, map[string]any{"url": l})
+		}
+		dash := &dashboardv0alpha1.Dashboard{Spec: commonV0.Unstructured{Object: map[string]any{"title": "a", "links": items}}}
+		return admission.NewAttributesRecord(dash, nil, dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(), "default", "abc",
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionResource(), "", admission.Create, nil, false, &user.SignedInUser{})
+	}
+	validator := func(mode string) *LinkValidator {
+		return &LinkValidator{settings: setting.DashboardLinkValidationSettings{
+			Default: setting.DashboardLinkValidation{
+				Mode:               mode,
+				AllowedSchemes:     []string{"http", "https"},
+				CheckDashboardUIDs: true,
+			},
+		}}
+	}
+	getter := &fakeLinkGetter{existing: map[string]bool{"exists": true}}
+
+	t.Run("rejects the invalid links", func(t *testing.T) {
+		err := validator(setting.DashboardLinkValidationReject).Validate(context.Background(), attributes("javascript:alert(1)", "/d/exists", "/d/missing"), getter)
+		require.True(t, apierrors.IsInvalid(err), err)
+		causes := err.(apierrors.APIStatus).Status().Details.Causes
+		require.Len(t, causes, 2)
+		require.Equal(t, "spec.links[0].url", causes[0].Field)
+		require.Equal(t, "spec.links[2].url", causes[1].Field)
+		require.Contains(t, causes[1].Message, `the linked dashboard "missing" does not exist`)
+	})
+
+	t.Run("accepts the valid links", func(t *testing.T) {
+		err := validator(setting.DashboardLinkValidationReject).Validate(context.Background(), attributes("https://grafana.com", "/d/exists"), getter)
+		require.NoError(t, err)
+	})
+
+	t.Run("only warns in the warn mode", func(t *testing.T) {
+		err := validator(setting.DashboardLinkValidationWarn).Validate(context.Background(), attributes("javascript:alert(1)"), getter)
+		require.NoError(t, err)
+	})
+
+	t.Run("skips the validation when it is off", func(t *testing.T) {
+		err := validator(setting.DashboardLinkValidationOff).Validate(context.Background(), attributes("javascript:alert(1)"), getter)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns the errors of the lookup", func(t *testing.T) {
+		err := validator(setting.DashboardLinkValidationReject).Validate(context.Background(), attributes("/d/exists"), &fakeLinkGetter{err: apierrors.NewInternalError(context.DeadlineExceeded)})
+		require.True(t, apierrors.IsInternalError(err), err)
+	})
+}
+
+type fakeLinkGetter struct {
+	existing map[string]bool
+	err      error
+}
+
+func (g *fakeLinkGetter) Get(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if !g.existing[name] {
+		return nil, apierrors.NewNotFound(dashboardv0alpha1.DashboardResourceInfo.GroupResource(), name)
+	}
+	return &dashboardv0alpha1.Dashboard{}, nil
+}
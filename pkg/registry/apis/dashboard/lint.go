@@ -0,0 +1,195 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+	LintSeverityInfo    = "info"
+
+	LintRuleDeprecatedPanel      = "deprecated-panel-type"
+	LintRuleMissingDatasourceUID = "missing-datasource-uid"
+	LintRuleTooManyQueries       = "too-many-queries"
+	LintRuleUnboundedTimeRange   = "unbounded-time-range"
+	LintRuleDuplicatePanelID     = "duplicate-panel-id"
+
+	// maxPanelQueries and maxDashboardQueries are the number of queries above which a panel or a dashboard
+	// is slow to load, every query is a request to a data source on each refresh
+	maxPanelQueries     = 10
+	maxDashboardQueries = 100
+)
+
+// deprecatedPanelTypes maps the panel types that are removed or only run with Angular to their replacement
+var deprecatedPanelTypes = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+	"natel-discrete-panel":     "state-timeline",
+}
+
+// timeFilterMacros limit a raw query to the time range of the dashboard
+var timeFilterMacros = []string{
+	"$__timeFilter", "$__timeFrom", "$__timeTo",
+	"$__unixEpochFilter", "$__unixEpochFrom", "$__unixEpochTo",
+	"$__unixEpochNanoFilter", "$__unixEpochNanoFrom", "$__unixEpochNanoTo",
+	"$__from", "$__to", "${__from", "${__to",
+	"$timeFilter",
+}
+
+// LintFinding is a problem found in a dashboard spec
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	PanelID  int64  `json:"panelId,omitempty"`
+	RefID    string `json:"refId,omitempty"`
+}
+
+// LintReport lists the problems of a dashboard spec, most severe first
+type LintReport struct {
+	Findings []LintFinding `json:"findings"`
+}
+
+// lintDashboard checks the spec of a dashboard, including the panels nested in rows, for common problems:
+// deprecated panel types, data sources referenced without a UID, panels or dashboards with too many queries,
+// raw queries that are not limited to the time range of the dashboard, and panel ids used more than once.
+func lintDashboard(spec map[string]any) *LintReport {
+	report := &LintReport{Findings: []LintFinding{}}
+	seen := map[int64]bool{}
+	queries := 0
+
+	var walk func(panels any)
+	walk = func(panels any) {
+		list, _ := panels.([]any)
+		for _, p := range list {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			id, hasID := panelID(panel)
+			if hasID && seen[id] {
+				report.add(LintRuleDuplicatePanelID, LintSeverityError, id, "",
+					fmt.Sprintf("panel id %d is used by more than one panel, links and embeds can open the wrong one", id))
+			}
+			if hasID {
+				seen[id] = true
+			}
+			queries += report.lintPanel(id, panel)
+			walk(panel["panels"])
+		}
+	}
+	walk(spec["panels"])
+
+	if queries > maxDashboardQueries {
+		report.add(LintRuleTooManyQueries, LintSeverityWarning, 0, "",
+			fmt.Sprintf("the dashboard runs %d queries on each refresh, split it or use fewer queries (at most %d)", queries, maxDashboardQueries))
+	}
+
+	rank := map[string]int{LintSeverityError: 0, LintSeverityWarning: 1, LintSeverityInfo: 2}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return rank[report.Findings[i].Severity] < rank[report.Findings[j].Severity]
+	})
+	return report
+}
+
+// lintPanel checks a single panel and returns the number of its queries
+func (r *LintReport) lintPanel(id int64, panel map[string]any) int {
+	panelType, _ := panel["type"].(string)
+	if replacement, ok := deprecatedPanelTypes[panelType]; ok {
+		r.add(LintRuleDeprecatedPanel, LintSeverityWarning, id, "",
+			fmt.Sprintf("the %s panel is deprecated, use the %s panel instead", panelType, replacement))
+	}
+	if msg := datasourceRefProblem(panel["datasource"]); msg != "" {
+		r.add(LintRuleMissingDatasourceUID, LintSeverityWarning, id, "", "the panel "+msg)
+	}
+
+	targets, _ := panel["targets"].([]any)
+	for _, t := range targets {
+		target, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		refID, _ := target["refId"].(string)
+		if msg := datasourceRefProblem(target["datasource"]); msg != "" {
+			r.add(LintRuleMissingDatasourceUID, LintSeverityWarning, id, refID, "the query "+msg)
+		}
+		if raw := rawQuery(target); raw != "" && !hasTimeFilter(raw) {
+			r.add(LintRuleUnboundedTimeRange, LintSeverityWarning, id, refID,
+				"the query is not limited to the time range of the dashboard, use a time filter macro like $__timeFilter")
+		}
+	}
+	if len(targets) > maxPanelQueries {
+		r.add(LintRuleTooManyQueries, LintSeverityInfo, id, "",
+			fmt.Sprintf("the panel runs %d queries, consider splitting it (at most %d)", len(targets), maxPanelQueries))
+	}
+	return len(targets)
+}
+
+func (r *LintReport) add(rule, severity string, panelID int64, refID, message string) {
+	r.Findings = append(r.Findings, LintFinding{Rule: rule, Severity: severity, Message: message, PanelID: panelID, RefID: refID})
+}
+
+// panelID reads the id of a panel, JSON numbers are decoded as float64, or as json.Number by simplejson
+func panelID(panel map[string]any) (int64, bool) {
+	switch v := panel["id"].(type) {
+	case float64:
+		return int64(v), true
+	case json.Number:
+		id, err := v.Int64()
+		return id, err == nil
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// datasourceRefProblem describes why a data source reference has no UID, it is empty when the reference is fine.
+// References to a data source variable are resolved by the frontend and are fine.
+func datasourceRefProblem(ref any) string {
+	switch v := ref.(type) {
+	case nil:
+		return ""
+	case string:
+		if v == "" || strings.HasPrefix(v, "$") {
+			return ""
+		}
+		return fmt.Sprintf("refers to the data source %q by name, the reference breaks when the data source is renamed", v)
+	case map[string]any:
+		if uid, _ := v["uid"].(string); uid != "" {
+			return ""
+		}
+		return "refers to a data source without a UID, the default data source is used instead"
+	}
+	return ""
+}
+
+// rawQuery returns the query text of SQL data sources, and of the other data sources in raw query mode
+func rawQuery(target map[string]any) string {
+	if sql, ok := target["rawSql"].(string); ok {
+		return sql
+	}
+	if raw, _ := target["rawQuery"].(bool); raw {
+		query, _ := target["query"].(string)
+		return query
+	}
+	return ""
+}
+
+func hasTimeFilter(query string) bool {
+	for _, macro := range timeFilterMacros {
+		if strings.Contains(query, macro) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintDashboard(t *testing.T) {
+	spec := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"panels": [
+			{"id": 1, "type": "graph", "datasource": {"type": "prometheus", "uid": "prom"},
+				"targets": [{"refId": "A"}, {"refId": "B", "datasource": "MySQL"}]},
+			{"id": 2, "type": "table", "datasource": {"type": "mysql"},
+				"targets": [
+					{"refId": "A", "rawSql": "SELECT * FROM logs"},
+					{"refId": "B", "rawSql": "SELECT * FROM logs WHERE $__timeFilter(time)"}
+				]},
+			{"id": 3, "type": "row", "panels": [
+				{"id": 1, "type": "stat", "datasource": "${ds}",
+					"targets": [{"refId": "A", "rawQuery": true, "query": "SELECT mean(v) FROM cpu"}]}
+			]}
+		]
+	}`), &spec))
+
+	report := lintDashboard(spec)
+	require.Equal(t, []LintFinding{
+		{Rule: LintRuleDuplicatePanelID, Severity: LintSeverityError, PanelID: 1,
+			Message: "panel id 1 is used by more than one panel, links and embeds can open the wrong one"},
+		{Rule: LintRuleDeprecatedPanel, Severity: LintSeverityWarning, PanelID: 1,
+			Message: "the graph panel is deprecated, use the timeseries panel instead"},
+		{Rule: LintRuleMissingDatasourceUID, Severity: LintSeverityWarning, PanelID: 1, RefID: "B",
+			Message: `the query refers to the data source "MySQL" by name, the reference breaks when the data source is renamed`},
+		{Rule: LintRuleMissingDatasourceUID, Severity: LintSeverityWarning, PanelID: 2,
+			Message: "the panel refers to a data source without a UID, the default data source is used instead"},
+		{Rule: LintRuleUnboundedTimeRange, Severity: LintSeverityWarning, PanelID: 2, RefID: "A",
+			Message: "the query is not limited to the time range of the dashboard, use a time filter macro like $__timeFilter"},
+		{Rule: LintRuleUnboundedTimeRange, Severity: LintSeverityWarning, PanelID: 1, RefID: "A",
+			Message: "the query is not limited to the time range of the dashboard, use a time filter macro like $__timeFilter"},
+	}, report.Findings)
+
+	t.Run("reports too many queries", func(t *testing.T) {
+		panels := []any{}
+		for i := 0; i < 9; i++ {
+			targets := []any{}
+			for j := 0; j < 12; j++ {
+				targets = append(targets, map[string]any{"refId": "A"})
+			}
+			panels = append(panels, map[string]any{"id": float64(i + 1), "type": "timeseries", "targets": targets})
+		}
+		report := lintDashboard(map[string]any{"panels": panels})
+		require.Len(t, report.Findings, 10)
+		require.Equal(t, LintSeverityWarning, report.Findings[0].Severity)
+		require.Equal(t, int64(0), report.Findings[0].PanelID, "the dashboard finding is not about a panel")
+		for _, f := range report.Findings {
+			require.Equal(t, LintRuleTooManyQueries, f.Rule)
+		}
+	})
+
+	t.Run("an empty spec has no findings", func(t *testing.T) {
+		require.Equal(t, &LintReport{Findings: []LintFinding{}}, lintDashboard(map[string]any{}))
+	})
+}
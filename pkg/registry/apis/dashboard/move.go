@@ -0,0 +1,178 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	MoveActionMoved      = "moved"
+	MoveActionUnchanged  = "unchanged"
+	MoveActionSkipped    = "skipped"
+	MoveActionFailed     = "failed"
+	MoveActionRolledBack = "rolledBack"
+
+	// maxMoveDashboards limits how many dashboards are moved in a single transaction
+	maxMoveDashboards = 1000
+)
+
+// ErrInvalidMove is returned when a move request cannot be applied because of its content.
+var ErrInvalidMove = errutil.BadRequest("dashboards.move.invalid")
+
+// ErrMoveNotApplied is returned with the result of a move when one of its dashboards failed, none of them is moved.
+var ErrMoveNotApplied = errutil.Conflict("dashboards.move.notApplied")
+
+// ErrMoveNotAtomic is returned when the dashboards are not stored in the database of the transaction of a move, it
+// could then be partially applied.
+var ErrMoveNotAtomic = errutil.NotImplemented("dashboards.move.notAtomic",
+	errutil.WithPublicMessage("Dashboards can not be moved together while folders or dashboards are stored in unified storage"))
+
+// MoveRequest moves dashboards to a folder. An empty folder UID moves the dashboards to the root.
+type MoveRequest struct {
+	DashboardUIDs []string `json:"dashboardUids"`
+	FolderUID     string   `json:"folderUid"`
+}
+
+// MoveItemResult is the outcome of moving a single dashboard.
+type MoveItemResult struct {
+	UID    string `json:"uid"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MoveResult is the outcome of a move request. Items keep the order of the request.
+type MoveResult struct {
+	Moved bool             `json:"moved"`
+	Items []MoveItemResult `json:"items"`
+}
+
+// DashboardMover moves dashboards between folders inside a single database transaction.
+type DashboardMover struct {
+	db         db.DB
+	features   featuremgmt.FeatureToggles
+	cfg        *setting.Cfg
+	folders    folder.Service
+	dashboards dashboards.DashboardService
+	log        log.Logger
+}
+
+func NewDashboardMover(sql db.DB, features featuremgmt.FeatureToggles, cfg *setting.Cfg, folders folder.Service, dashboardService dashboards.DashboardService) *DashboardMover {
+	return &DashboardMover{
+		db:         sql,
+		features:   features,
+		cfg:        cfg,
+		folders:    folders,
+		dashboards: dashboardService,
+		log:        log.New("dashboard.move"),
+	}
+}
+
+// Move moves all dashboards of the request or none of them. Saving the dashboard checks that the
+// user can edit it and can save dashboards in the target folder. When a dashboard fails, the move
+// is rolled back, the dashboards moved before it are reported as rolled back, the remaining ones as
+// skipped, and the result is returned with ErrMoveNotApplied. Moves are rejected with ErrMoveNotAtomic
+// when the dashboards are not written in the transaction, see checkLegacyTransaction.
+func (m *DashboardMover) Move(ctx context.Context, user identity.Requester, req MoveRequest) (*MoveResult, error) {
+	if err := validateMoveRequest(req); err != nil {
+		return nil, ErrInvalidMove.Errorf("%w", err)
+	}
+	if err := checkLegacyTransaction(ctx, m.features, m.cfg); err != nil {
+		return nil, ErrMoveNotAtomic.Errorf("%w", err)
+	}
+
+	if req.FolderUID != "" {
+		_, err := m.folders.Get(ctx, &folder.GetFolderQuery{UID: &req.FolderUID, OrgID: user.GetOrgID(), SignedInUser: user})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+				return nil, ErrInvalidMove.Errorf("folder %q not found", req.FolderUID)
+			}
+			return nil, err
+		}
+	}
+
+	result := &MoveResult{Items: make([]MoveItemResult, 0, len(req.DashboardUIDs))}
+	failed := false
+	err := m.db.InTransaction(ctx, func(ctx context.Context) error {
+		for i, uid := range req.DashboardUIDs {
+			action, err := m.moveDashboard(ctx, user, uid, req.FolderUID)
+			if err != nil {
+				failed = true
+				for j := range result.Items {
+					if result.Items[j].Action == MoveActionMoved {
+						result.Items[j].Action = MoveActionRolledBack
+					}
+				}
+				result.Items = append(result.Items, MoveItemResult{UID: uid, Action: MoveActionFailed, Error: err.Error()})
+				for _, rest := range req.DashboardUIDs[i+1:] {
+					result.Items = append(result.Items, MoveItemResult{UID: rest, Action: MoveActionSkipped})
+				}
+				return fmt.Errorf("failed to move dashboard %s: %w", uid, err)
+			}
+			result.Items = append(result.Items, MoveItemResult{UID: uid, Action: action})
+		}
+		return nil
+	})
+	if err != nil {
+		if failed {
+			m.log.Debug("Move was rolled back", "error", err)
+			return result, ErrMoveNotApplied.Errorf("%w", err)
+		}
+		return nil, err
+	}
+	result.Moved = true
+	return result, nil
+}
+
+func (m *DashboardMover) moveDashboard(ctx context.Context, user identity.Requester, uid string, folderUID string) (string, error) {
+	dash, err := m.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()})
+	if err != nil {
+		return "", err
+	}
+	if dash.FolderUID == folderUID {
+		return MoveActionUnchanged, nil
+	}
+
+	// the folder ID is resolved from the UID when saving, a stale ID would move the dashboard back when moving to the root
+	dash.FolderID = 0 // nolint:staticcheck
+	dash.FolderUID = folderUID
+	_, err = m.dashboards.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     user.GetOrgID(),
+		User:      user,
+		Message:   "moved to another folder",
+		Dashboard: dash,
+	}, false)
+	if err != nil {
+		return "", err
+	}
+	return MoveActionMoved, nil
+}
+
+func validateMoveRequest(req MoveRequest) error {
+	if len(req.DashboardUIDs) == 0 {
+		return errors.New("no dashboards to move")
+	}
+	if len(req.DashboardUIDs) > maxMoveDashboards {
+		return fmt.Errorf("too many dashboards, at most %d can be moved at once", maxMoveDashboards)
+	}
+	seen := make(map[string]bool, len(req.DashboardUIDs))
+	for i, uid := range req.DashboardUIDs {
+		if uid == "" {
+			return fmt.Errorf("dashboard at position %d is missing a uid", i)
+		}
+		if seen[uid] {
+			return fmt.Errorf("duplicate dashboard %s", uid)
+		}
+		seen[uid] = true
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestValidateMoveRequest(t *testing.T) {
+	require.NoError(t, validateMoveRequest(MoveRequest{DashboardUIDs: []string{"a", "b"}, FolderUID: "f"}))
+	require.NoError(t, validateMoveRequest(MoveRequest{DashboardUIDs: []string{"a"}}), "moving to the root")
+
+	err := validateMoveRequest(MoveRequest{FolderUID: "f"})
+	require.ErrorContains(t, err, "no dashboards to move")
+
+	err = validateMoveRequest(MoveRequest{DashboardUIDs: []string{"a", ""}})
+	require.ErrorContains(t, err, "position 1 is missing a uid")
+
+	err = validateMoveRequest(MoveRequest{DashboardUIDs: []string{"a", "b", "a"}})
+	require.ErrorContains(t, err, "duplicate dashboard a")
+
+	err = validateMoveRequest(MoveRequest{DashboardUIDs: make([]string, maxMoveDashboards+1)})
+	require.ErrorContains(t, err, "too many dashboards")
+}
+
+// failedCommitDB runs the transactions and fails to commit them
+type failedCommitDB struct {
+	transactionDB
+}
+
+func (failedCommitDB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return errors.New("commit failed")
+}
+
+func TestDashboardMove(t *testing.T) {
+	user := &identity.StaticRequester{OrgID: 1}
+	req := MoveRequest{DashboardUIDs: []string{"a", "b", "c"}}
+	newService := func(t *testing.T) *dashboards.FakeDashboardService {
+		svc := dashboards.NewFakeDashboardService(t)
+		svc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool { return q.UID != "b" })).
+			Return(&dashboards.Dashboard{UID: "a", FolderUID: "old"}, nil).Maybe()
+		svc.On("SaveDashboard", mock.Anything, mock.Anything, false).Return(&dashboards.Dashboard{}, nil).Maybe()
+		return svc
+	}
+
+	t.Run("a failed dashboard rolls back the moved dashboards", func(t *testing.T) {
+		svc := newService(t)
+		svc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool { return q.UID == "b" })).
+			Return(nil, dashboards.ErrDashboardNotFound)
+		m := NewDashboardMover(transactionDB{}, featuremgmt.WithFeatures(), setting.NewCfg(), foldertest.NewFakeService(), svc)
+
+		result, err := m.Move(context.Background(), user, req)
+		require.ErrorIs(t, err, ErrMoveNotApplied)
+		require.False(t, result.Moved)
+		require.Equal(t, []MoveItemResult{
+			{UID: "a", Action: MoveActionRolledBack},
+			{UID: "b", Action: MoveActionFailed, Error: dashboards.ErrDashboardNotFound.Error()},
+			{UID: "c", Action: MoveActionSkipped},
+		}, result.Items)
+	})
+
+	t.Run("returns the errors of the transaction", func(t *testing.T) {
+		m := NewDashboardMover(failedCommitDB{}, featuremgmt.WithFeatures(), setting.NewCfg(), foldertest.NewFakeService(), newService(t))
+		result, err := m.Move(context.Background(), user, MoveRequest{DashboardUIDs: []string{"a"}})
+		require.EqualError(t, err, "commit failed")
+		require.NotErrorIs(t, err, ErrMoveNotApplied)
+		require.Nil(t, result)
+	})
+
+	t.Run("rejects the move when the dashboards are written to unified storage", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.UnifiedStorage = map[string]setting.UnifiedStorageConfig{
+			"dashboards.dashboard.grafana.app": {DualWriterMode: grafanarest.Mode2},
+		}
+		m := NewDashboardMover(transactionDB{}, featuremgmt.WithFeatures(), cfg, foldertest.NewFakeService(), dashboards.NewFakeDashboardService(t))
+		_, err := m.Move(context.Background(), user, req)
+		require.ErrorIs(t, err, ErrMoveNotAtomic)
+		require.ErrorContains(t, err, "dashboards are written to unified storage")
+	})
+}
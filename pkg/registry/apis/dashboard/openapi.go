@@ -0,0 +1,126 @@
+package dashboard
+
+import (
+	"slices"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+)
+
+// ExperimentalExtension marks operations that may change or be removed without a new API version
+const ExperimentalExtension = "x-grafana-experimental"
+
+// Subresources only used by the Grafana frontend, they are not part of the documented API
+var internalSubresources = []string{"dto"}
+
+// Custom namespace routes that are still experimental
+var experimentalRoutes = []string{
+	"bundle/apply",
+	"import",
+	dashboard.DashboardResourceInfo.GroupResource().Resource + ":move",
+	dashboard.DashboardResourceInfo.GroupResource().Resource + ":copy",
+	"tags",
+	"tags/rename",
+	"tags/merge",
+	"quota",
+	"legacy/search",
+	"search/autocomplete",
+}
+
+// Examples of the 200 responses of the subresources
+var subresourceExamples = map[string]string{
+	"history": `{"kind":"PartialObjectMetadataList","apiVersion":"meta.k8s.io/v1","metadata":{},"items":[` +
+		`{"metadata":{"name":"abc","namespace":"default","resourceVersion":"1712312345000","generation":3,` +
+		`"annotations":{"grafana.app/updatedBy":"user:u000000001","grafana.app/updatedTimestamp":"2024-04-05T10:19:05Z","grafana.app/message":"Added a panel"}}}]}`,
+}
+
+const searchExample = `{"items":[{"resourceVersion":"1712312345000","value":"eyJtZXRhZGF0YSI6eyJuYW1lIjoiYWJjIn19"}]}`
+
+// EnrichOpenAPI documents the dashboards API of a version rooted at root (/apis/<group>/<version>/):
+// operations of the dashboard subresources are grouped in their own tags, examples are added to the search and
+// history responses, the custom routes are marked experimental and the internal routes are removed.
+func EnrichOpenAPI(oas *spec3.OpenAPI, root string) {
+	if oas == nil || oas.Paths == nil {
+		return
+	}
+	kind := dashboard.DashboardResourceInfo.GroupVersionKind().Kind
+	namespace := root + "namespaces/{namespace}/"
+	item := namespace + dashboard.DashboardResourceInfo.GroupResource().Resource + "/{name}/"
+
+	for path, p := range oas.Paths.Paths {
+		if p == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(path, namespace+"watch/"):
+			// deprecated, the list operations support the watch parameter
+			delete(oas.Paths.Paths, path)
+		case strings.HasPrefix(path, item):
+			sub := strings.TrimPrefix(path, item)
+			if slices.Contains(internalSubresources, sub) {
+				delete(oas.Paths.Paths, path)
+				continue
+			}
+			tag := kind + " " + subresourceTitle(sub)
+			for _, op := range builder.GetPathOperations(p) {
+				if op != nil {
+					op.Tags = []string{tag}
+				}
+			}
+			if example, ok := subresourceExamples[sub]; ok {
+				setResponseExample(p.Get, example)
+			}
+		case path == root+"search":
+			setResponseExample(p.Get, searchExample)
+			markExperimental(p)
+		case slices.Contains(experimentalRoutes, strings.TrimPrefix(path, namespace)):
+			markExperimental(p)
+		}
+	}
+}
+
+// subresourceTitle turns a subresource into a tag title, provisioning-status is "Provisioning Status"
+func subresourceTitle(sub string) string {
+	words := strings.Split(sub, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func markExperimental(p *spec3.Path) {
+	for _, op := range builder.GetPathOperations(p) {
+		if op == nil {
+			continue
+		}
+		if op.Extensions == nil {
+			op.Extensions = spec.Extensions{}
+		}
+		op.Extensions[ExperimentalExtension] = true
+		if op.Description != "" {
+			op.Description += "\n\n"
+		}
+		op.Description += "This endpoint is experimental and may change without a new API version."
+	}
+}
+
+func setResponseExample(op *spec3.Operation, example string) {
+	if op == nil || op.Responses == nil {
+		return
+	}
+	rsp := op.Responses.StatusCodeResponses[200]
+	if rsp == nil {
+		return
+	}
+	for _, media := range rsp.Content {
+		if media != nil && media.Example == nil {
+			media.Example = example
+		}
+	}
+}
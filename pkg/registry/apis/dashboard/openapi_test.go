@@ -0,0 +1,50 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func TestEnrichOpenAPI(t *testing.T) {
+	root := "/apis/dashboard.grafana.app/v0alpha1/"
+	namespace := root + "namespaces/{namespace}/"
+	get := func() *spec3.Path {
+		return &spec3.Path{PathProps: spec3.PathProps{Get: &spec3.Operation{OperationProps: spec3.OperationProps{
+			Tags: []string{"Dashboard"},
+			Responses: &spec3.Responses{ResponsesProps: spec3.ResponsesProps{StatusCodeResponses: map[int]*spec3.Response{
+				200: {ResponseProps: spec3.ResponseProps{Content: map[string]*spec3.MediaType{"application/json": {}}}},
+			}}},
+		}}}}
+	}
+	oas := &spec3.OpenAPI{Paths: &spec3.Paths{Paths: map[string]*spec3.Path{
+		namespace + "dashboards/{name}":                     get(),
+		namespace + "dashboards/{name}/dto":                 get(),
+		namespace + "dashboards/{name}/history":             get(),
+		namespace + "dashboards/{name}/provisioning-status": get(),
+		namespace + "watch/dashboards":                      get(),
+		namespace + "tags":                                  get(),
+		root + "search":                                     get(),
+	}}}
+
+	EnrichOpenAPI(oas, root)
+	paths := oas.Paths.Paths
+
+	require.NotContains(t, paths, namespace+"dashboards/{name}/dto")
+	require.NotContains(t, paths, namespace+"watch/dashboards")
+
+	require.Equal(t, []string{"Dashboard"}, paths[namespace+"dashboards/{name}"].Get.Tags)
+	require.Equal(t, []string{"Dashboard History"}, paths[namespace+"dashboards/{name}/history"].Get.Tags)
+	require.Equal(t, []string{"Dashboard Provisioning Status"}, paths[namespace+"dashboards/{name}/provisioning-status"].Get.Tags)
+
+	history := paths[namespace+"dashboards/{name}/history"].Get.Responses.StatusCodeResponses[200].Content["application/json"]
+	require.Equal(t, subresourceExamples["history"], history.Example)
+	search := paths[root+"search"].Get
+	require.Equal(t, searchExample, search.Responses.StatusCodeResponses[200].Content["application/json"].Example)
+
+	require.Equal(t, true, search.Extensions[ExperimentalExtension])
+	require.Equal(t, true, paths[namespace+"tags"].Get.Extensions[ExperimentalExtension])
+	require.Contains(t, paths[namespace+"tags"].Get.Description, "experimental")
+	require.NotContains(t, paths[namespace+"dashboards/{name}"].Get.Extensions, ExperimentalExtension)
+}
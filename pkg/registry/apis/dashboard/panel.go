@@ -0,0 +1,185 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+var libraryPanelsGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "librarypanels"}
+
+// DashboardPanel is a single panel of a dashboard with the template variables it uses, enough to render the panel
+// without the rest of the dashboard
+type DashboardPanel struct {
+	// Panel is the panel, a library panel is replaced by its model
+	Panel map[string]any `json:"panel"`
+
+	// Variables are the template variables used by the panel, and the variables they use, in the order of the dashboard
+	Variables []map[string]any `json:"variables"`
+}
+
+// PanelExtractor extracts single panels from dashboard specs
+type PanelExtractor struct {
+	libraryElements libraryelements.Service
+}
+
+func NewPanelExtractor(libraryElements libraryelements.Service) *PanelExtractor {
+	return &PanelExtractor{libraryElements: libraryElements}
+}
+
+// Extract returns the panel with the id from the spec, in the classic dashboard JSON model, including the panels
+// of collapsed rows. Library panels are read with the permissions of the user.
+func (e *PanelExtractor) Extract(ctx context.Context, user identity.Requester, spec map[string]any, id int64) (*DashboardPanel, error) {
+	panel := findPanel(spec["panels"], id)
+	if panel == nil {
+		return nil, apierrors.NewNotFound(panelsGroupResource, strconv.FormatInt(id, 10))
+	}
+
+	if lib, ok := panel["libraryPanel"].(map[string]any); ok {
+		uid, _ := lib["uid"].(string)
+		element, err := e.libraryElements.GetElement(ctx, user, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+		if err != nil {
+			if errors.Is(err, model.ErrLibraryElementNotFound) {
+				return nil, apierrors.NewNotFound(libraryPanelsGroupResource, uid)
+			}
+			return nil, err
+		}
+		if panel, err = libraryPanelModel(panel, element); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DashboardPanel{
+		Panel:     panel,
+		Variables: panelVariables(spec, panel),
+	}, nil
+}
+
+// findPanel returns the panel with the id, rows are not panels of their own
+func findPanel(panels any, id int64) map[string]any {
+	list, _ := panels.([]any)
+	for _, p := range list {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if t, _ := panel["type"].(string); t != "row" {
+			if pid, ok := panelID(panel); ok && pid == id {
+				return panel
+			}
+		}
+		if nested := findPanel(panel["panels"], id); nested != nil {
+			return nested
+		}
+	}
+	return nil
+}
+
+// libraryPanelModel replaces a library panel reference by the model of the library panel, like the frontend does
+// when it loads the dashboard. The panel keeps its id and position in the dashboard.
+func libraryPanelModel(panel map[string]any, element model.LibraryElementDTO) (map[string]any, error) {
+	resolved := map[string]any{}
+	if len(element.Model) > 0 {
+		if err := json.Unmarshal(element.Model, &resolved); err != nil {
+			return nil, fmt.Errorf("failed to read the model of library panel %s: %w", element.UID, err)
+		}
+	}
+	for _, key := range []string{"id", "gridPos"} {
+		if v, ok := panel[key]; ok {
+			resolved[key] = v
+		}
+	}
+	resolved["libraryPanel"] = map[string]any{
+		"uid":     element.UID,
+		"name":    element.Name,
+		"version": element.Version,
+	}
+	return resolved, nil
+}
+
+// panelVariables returns the template variables of the spec used by the panel, directly or through the other
+// variables it uses, in the order they are defined
+func panelVariables(spec map[string]any, panel map[string]any) []map[string]any {
+	templating, _ := spec["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+	vars := map[string]map[string]any{}
+	for _, item := range list {
+		if v, ok := item.(map[string]any); ok {
+			if name, _ := v["name"].(string); name != "" {
+				vars[name] = v
+			}
+		}
+	}
+
+	used := map[string]bool{}
+	pending := referencedVariables(panel)
+	if repeat, _ := panel["repeat"].(string); repeat != "" {
+		pending = append(pending, repeat)
+	}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		v, ok := vars[name]
+		if !ok || used[name] {
+			continue
+		}
+		used[name] = true
+		// the current value and the options are values, not references
+		definition := make(map[string]any, len(v))
+		for k, value := range v {
+			if k != "current" && k != "options" {
+				definition[k] = value
+			}
+		}
+		pending = append(pending, referencedVariables(definition)...)
+	}
+
+	out := []map[string]any{}
+	for _, item := range list {
+		if v, ok := item.(map[string]any); ok {
+			if name, _ := v["name"].(string); used[name] {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// referencedVariables returns the names of the variables referenced in the strings of v
+func referencedVariables(v any) []string {
+	var names []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			for _, groups := range variableRegex.FindAllStringSubmatch(v, -1) {
+				for _, name := range []string{groups[1], groups[2], groups[4]} {
+					if name != "" {
+						names = append(names, name)
+					}
+				}
+			}
+		case map[string]any:
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+	return names
+}
@@ -0,0 +1,100 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	fakeLibraryElements "github.com/grafana/grafana/pkg/services/libraryelements/fake"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+func TestPanelExtractor(t *testing.T) {
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, OrgRole: identity.RoleAdmin}
+	libraryElements := &fakeLibraryElements.LibraryElementService{}
+	_, err := libraryElements.CreateElement(context.Background(), user, model.CreateLibraryElementCommand{
+		UID:   "lib1",
+		Name:  "Errors",
+		Kind:  int64(model.PanelElement),
+		Model: json.RawMessage(`{"type": "stat", "title": "Errors", "targets": [{"expr": "errors{env=\"$env\"}"}]}`),
+	})
+	require.NoError(t, err)
+	extractor := NewPanelExtractor(libraryElements)
+
+	// read like the dashboard service reads dashboards, numbers are json.Number
+	dash, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": {"uid": "${ds}"},
+				"targets": [{"expr": "rate(http_requests{job=\"[[job]]\", instance=~\"${instance:regex}\"}[5m])"}]},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "gridPos": {"x": 0, "y": 9}, "libraryPanel": {"uid": "lib1", "name": "Errors"}},
+				{"id": 4, "libraryPanel": {"uid": "missing"}}
+			]},
+			{"id": 5, "type": "text", "repeat": "env"}
+		],
+		"templating": {"list": [
+			{"name": "ds", "type": "datasource", "query": "prometheus"},
+			{"name": "env", "type": "custom", "query": "prod,dev"},
+			{"name": "job", "type": "query", "query": "label_values(up{env=\"$env\"}, job)", "current": {"value": "$__all"}},
+			{"name": "instance", "type": "query", "datasource": {"uid": "${ds}"}, "query": "label_values(up{job=\"$job\"}, instance)"},
+			{"name": "unused", "type": "textbox", "query": "x"}
+		]}
+	}`))
+	require.NoError(t, err)
+	spec, _ := dash.Interface().(map[string]any)
+
+	variableNames := func(p *DashboardPanel) []string {
+		names := []string{}
+		for _, v := range p.Variables {
+			names = append(names, v["name"].(string))
+		}
+		return names
+	}
+
+	t.Run("panel with the variables it uses", func(t *testing.T) {
+		p, err := extractor.Extract(context.Background(), user, spec, 1)
+		require.NoError(t, err)
+		require.Equal(t, "timeseries", p.Panel["type"])
+		require.Equal(t, []string{"ds", "env", "job", "instance"}, variableNames(p))
+	})
+
+	t.Run("library panel in a collapsed row", func(t *testing.T) {
+		p, err := extractor.Extract(context.Background(), user, spec, 3)
+		require.NoError(t, err)
+		require.Equal(t, "stat", p.Panel["type"])
+		require.Equal(t, json.Number("3"), p.Panel["id"])
+		require.Equal(t, map[string]any{"x": json.Number("0"), "y": json.Number("9")}, p.Panel["gridPos"])
+		require.Equal(t, map[string]any{"uid": "lib1", "name": "Errors", "version": int64(1)}, p.Panel["libraryPanel"])
+		require.Equal(t, []string{"env"}, variableNames(p))
+	})
+
+	t.Run("repeated panel", func(t *testing.T) {
+		p, err := extractor.Extract(context.Background(), user, spec, 5)
+		require.NoError(t, err)
+		require.Equal(t, []string{"env"}, variableNames(p))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, id := range []int64{2, 4, 42} {
+			_, err := extractor.Extract(context.Background(), user, spec, id)
+			require.True(t, apierrors.IsNotFound(err), id)
+		}
+	})
+}
+
+func TestPanelIDFromPath(t *testing.T) {
+	id, err := panelIDFromPath("/apis/dashboard.grafana.app/v1alpha1/namespaces/default/dashboards/abc/panels/12")
+	require.NoError(t, err)
+	require.Equal(t, int64(12), id)
+
+	for _, path := range []string{".../dashboards/abc/panels", ".../dashboards/abc/panels/x", ".../dashboards/abc/lint"} {
+		_, err := panelIDFromPath(path)
+		require.True(t, apierrors.IsBadRequest(err), path)
+	}
+}
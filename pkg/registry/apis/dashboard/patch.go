@@ -0,0 +1,233 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+)
+
+// specRule checks the values of the spec at a path, [*] matches all the items of a list
+type specRule struct {
+	path  string
+	check func(v any) string
+}
+
+// patchRules are the fields of the classic dashboard JSON model that automation commonly patches
+var patchRules = []specRule{
+	{path: "title", check: nonEmptyString},
+	{path: "tags", check: stringList},
+	{path: "timezone", check: isString},
+	{path: "editable", check: isBool},
+	{path: "refresh", check: stringOrFalse},
+	{path: "schemaVersion", check: isNumber},
+	{path: "time", check: isObject},
+	{path: "time.from", check: nonEmptyString},
+	{path: "time.to", check: nonEmptyString},
+	{path: "templating.list", check: objectList},
+	{path: "panels", check: objectList},
+	{path: "panels[*].id", check: isNumber},
+	{path: "panels[*].type", check: isString},
+	{path: "panels[*].title", check: isString},
+	{path: "panels[*].gridPos.x", check: isNumber},
+	{path: "panels[*].gridPos.y", check: isNumber},
+	{path: "panels[*].gridPos.w", check: isNumber},
+	{path: "panels[*].gridPos.h", check: isNumber},
+	{path: "panels[*].fieldConfig.defaults.thresholds.mode", check: isString},
+	{path: "panels[*].fieldConfig.defaults.thresholds.steps", check: objectList},
+	{path: "panels[*].fieldConfig.defaults.thresholds.steps[*].color", check: nonEmptyString},
+	{path: "panels[*].fieldConfig.defaults.thresholds.steps[*].value", check: numberOrNull},
+}
+
+// ValidateSpecPatch rejects JSON patches and merge patches of dashboards that set fields of the spec to values of
+// the wrong type. Only the fields changed by the patch are checked, so patches of dashboards that already have
+// invalid fields are accepted. All the invalid fields are returned with their path in a 422 Unprocessable Entity.
+func ValidateSpecPatch(a admission.Attributes) error {
+	if a.GetOperation() != admission.Update || a.GetObject() == nil || a.GetOldObject() == nil {
+		return nil
+	}
+	if _, ok := a.GetOperationOptions().(*metav1.PatchOptions); !ok {
+		return nil
+	}
+	if a.GetResource().Resource != dashboard.DashboardResourceInfo.GroupResource().Resource || a.GetSubresource() != "" {
+		return nil
+	}
+
+	spec, err := unstructuredSpec(a.GetObject())
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	old, err := unstructuredSpec(a.GetOldObject())
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	if errs := validateSpecChanges(spec, old, field.NewPath("spec")); len(errs) > 0 {
+		return apierrors.NewInvalid(dashboard.DashboardResourceInfo.GroupVersionKind().GroupKind(), a.GetName(), errs)
+	}
+	return nil
+}
+
+func unstructuredSpec(obj runtime.Object) (map[string]any, error) {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := meta.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	switch s := spec.(type) {
+	case commonV0.Unstructured:
+		return s.Object, nil
+	case map[string]any:
+		return s, nil
+	}
+	return nil, nil
+}
+
+// validateSpecChanges returns the errors of the values of the rules that differ from the old spec
+func validateSpecChanges(spec, old map[string]any, root *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if spec == nil {
+		return errs
+	}
+	for _, rule := range patchRules {
+		segments := strings.Split(rule.path, ".")
+		oldValues := map[string]any{}
+		walkSpec(old, segments, root, func(p *field.Path, v any) {
+			oldValues[p.String()] = v
+		})
+		walkSpec(spec, segments, root, func(p *field.Path, v any) {
+			if prev, ok := oldValues[p.String()]; ok && reflect.DeepEqual(prev, v) {
+				return
+			}
+			if msg := rule.check(v); msg != "" {
+				errs = append(errs, field.Invalid(p, invalidValue(v), msg))
+			}
+		})
+	}
+	return errs
+}
+
+// invalidValue is the value of an error, the values of objects and lists are omitted as they can be very large
+func invalidValue(v any) any {
+	switch v.(type) {
+	case map[string]any, []any:
+		return field.OmitValueType{}
+	}
+	return v
+}
+
+// walkSpec calls fn with the values at the path of the segments, missing values are skipped
+func walkSpec(v any, segments []string, p *field.Path, fn func(*field.Path, any)) {
+	if len(segments) == 0 {
+		fn(p, v)
+		return
+	}
+	name, all := strings.CutSuffix(segments[0], "[*]")
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	child, ok := obj[name]
+	if !ok {
+		return
+	}
+	p = p.Child(name)
+	if !all {
+		walkSpec(child, segments[1:], p, fn)
+		return
+	}
+	items, ok := child.([]any)
+	if !ok {
+		return
+	}
+	for i, item := range items {
+		walkSpec(item, segments[1:], p.Index(i), fn)
+	}
+}
+
+func isString(v any) string {
+	if _, ok := v.(string); !ok {
+		return "must be a string"
+	}
+	return ""
+}
+
+func nonEmptyString(v any) string {
+	if s, ok := v.(string); !ok || s == "" {
+		return "must be a non-empty string"
+	}
+	return ""
+}
+
+func stringOrFalse(v any) string {
+	if b, ok := v.(bool); ok && !b {
+		return ""
+	}
+	return isString(v)
+}
+
+func isBool(v any) string {
+	if _, ok := v.(bool); !ok {
+		return "must be a boolean"
+	}
+	return ""
+}
+
+func isNumber(v any) string {
+	switch v.(type) {
+	case int, int32, int64, float32, float64, json.Number:
+		return ""
+	}
+	return "must be a number"
+}
+
+func numberOrNull(v any) string {
+	if v == nil {
+		return ""
+	}
+	return isNumber(v)
+}
+
+func isObject(v any) string {
+	if _, ok := v.(map[string]any); !ok {
+		return "must be an object"
+	}
+	return ""
+}
+
+func stringList(v any) string {
+	items, ok := v.([]any)
+	if !ok {
+		return "must be a list of strings"
+	}
+	for _, item := range items {
+		if _, ok := item.(string); !ok {
+			return "must be a list of strings"
+		}
+	}
+	return ""
+}
+
+func objectList(v any) string {
+	items, ok := v.([]any)
+	if !ok {
+		return "must be a list of objects"
+	}
+	for _, item := range items {
+		if _, ok := item.(map[string]any); !ok {
+			return "must be a list of objects"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestValidateSpecPatch(t *testing.T) {
+	newDashboard := func(spec string) *dashboardv0alpha1.Dashboard {
+		obj := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(spec), &obj))
+		return &dashboardv0alpha1.Dashboard{Spec: commonV0.Unstructured{Object: obj}}
+	}
+	validate := func(options runtime.Object, obj, old runtime.Object) error {
+		return ValidateSpecPatch(admission.NewAttributesRecord(
+			obj,
+			old,
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(),
+			"default",
+			"abc",
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionResource(),
+			"",
+			admission.Update,
+			options,
+			false,
+			&user.SignedInUser{},
+		))
+	}
+	// the old dashboard already has an invalid refresh
+	old := newDashboard(`{
+		"title": "Service",
+		"refresh": 5,
+		"time": {"from": "now-6h", "to": "now"},
+		"panels": [{"id": 1, "fieldConfig": {"defaults": {"thresholds": {"steps": [{"color": "green", "value": null}, {"color": "red", "value": 80}]}}}}]
+	}`)
+
+	t.Run("valid patch", func(t *testing.T) {
+		obj := newDashboard(`{
+			"title": "Service",
+			"refresh": 5,
+			"time": {"from": "now-1h", "to": "now"},
+			"panels": [{"id": 1, "fieldConfig": {"defaults": {"thresholds": {"steps": [{"color": "green", "value": null}, {"color": "red", "value": 90}]}}}}]
+		}`)
+		require.NoError(t, validate(&metav1.PatchOptions{}, obj, old))
+	})
+
+	t.Run("invalid fields with their path", func(t *testing.T) {
+		obj := newDashboard(`{
+			"title": "",
+			"refresh": 5,
+			"time": {"from": 1, "to": "now"},
+			"panels": [{"id": 1, "fieldConfig": {"defaults": {"thresholds": {"steps": [{"color": "green", "value": null}, {"color": "red", "value": "high"}]}}}}]
+		}`)
+		err := validate(&metav1.PatchOptions{}, obj, old)
+		require.True(t, apierrors.IsInvalid(err), err)
+
+		causes := map[string]string{}
+		for _, cause := range err.(apierrors.APIStatus).Status().Details.Causes {
+			causes[cause.Field] = string(cause.Type)
+		}
+		require.Equal(t, map[string]string{
+			"spec.title":     "FieldValueInvalid",
+			"spec.time.from": "FieldValueInvalid",
+			"spec.panels[0].fieldConfig.defaults.thresholds.steps[1].value": "FieldValueInvalid",
+		}, causes)
+	})
+
+	t.Run("only patches are validated", func(t *testing.T) {
+		obj := newDashboard(`{"title": ""}`)
+		require.NoError(t, validate(&metav1.UpdateOptions{}, obj, old))
+	})
+}
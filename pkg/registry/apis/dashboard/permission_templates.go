@@ -0,0 +1,208 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+// permissionTemplateNamespace is the kvstore namespace of the permission templates, they are keyed by the UID of the
+// folder in its org
+const permissionTemplateNamespace = "dashboards.permission-templates"
+
+var (
+	ErrInvalidPermissionTemplate      = errutil.BadRequest("dashboards.permission-templates.invalid")
+	ErrPermissionTemplateAccessDenied = errutil.Forbidden("dashboards.permission-templates.forbidden", errutil.WithPublicMessage("You are not allowed to manage the permission template of the folder"))
+	ErrPermissionTemplateNotFound     = errutil.NotFound("dashboards.permission-templates.not-found", errutil.WithPublicMessage("The folder has no permission template"))
+)
+
+// FolderPermissionTemplate are the team and role permissions given to the dashboards created in a folder through the
+// dashboards API, in addition to the default permissions of a new dashboard
+type FolderPermissionTemplate struct {
+	FolderUID   string                `json:"folderUid"`
+	Permissions []DashboardPermission `json:"permissions"`
+	UpdatedAt   time.Time             `json:"updatedAt"`
+	UpdatedBy   string                `json:"updatedBy,omitempty"`
+}
+
+// PermissionTemplates keeps the permission templates of the folders and applies them to the dashboards created in the
+// folders. The template of the folder a dashboard is created in is applied, the templates of its parents are not, the
+// permissions of the parent folders are already inherited.
+type PermissionTemplates struct {
+	kv          kvstore.KVStore
+	folders     folder.Service
+	permissions accesscontrol.DashboardPermissionsService
+	now         func() time.Time
+	log         log.Logger
+}
+
+func NewPermissionTemplates(kv kvstore.KVStore, folders folder.Service, permissions accesscontrol.DashboardPermissionsService) *PermissionTemplates {
+	return &PermissionTemplates{
+		kv:          kv,
+		folders:     folders,
+		permissions: permissions,
+		now:         time.Now,
+		log:         log.New("grafana-apiserver.dashboards.permission-templates"),
+	}
+}
+
+// Get returns the permission template of the folder, ErrPermissionTemplateNotFound when the folder has none
+func (t *PermissionTemplates) Get(ctx context.Context, orgID int64, folderUID string) (*FolderPermissionTemplate, error) {
+	value, ok, err := t.kv.Get(ctx, orgID, permissionTemplateNamespace, folderUID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermissionTemplateNotFound.Errorf("folder %s has no permission template", folderUID)
+	}
+	template := &FolderPermissionTemplate{}
+	if err := json.Unmarshal([]byte(value), template); err != nil {
+		return nil, fmt.Errorf("invalid permission template of folder %s: %w", folderUID, err)
+	}
+	return template, nil
+}
+
+// Set replaces the permission template of the folder. The permissions are given to teams or roles, a template can
+// not give permissions to single users.
+func (t *PermissionTemplates) Set(ctx context.Context, user identity.Requester, folderUID string, permissions []DashboardPermission) (*FolderPermissionTemplate, error) {
+	if err := t.requireFolder(ctx, user, folderUID); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	template := &FolderPermissionTemplate{
+		FolderUID:   folderUID,
+		Permissions: make([]DashboardPermission, 0, len(permissions)),
+		UpdatedAt:   t.now().UTC(),
+		UpdatedBy:   user.GetUID(),
+	}
+	for _, p := range permissions {
+		if p.UserID != 0 {
+			return nil, ErrInvalidPermissionTemplate.Errorf("a permission template can not give permissions to users, use a team instead")
+		}
+		if err := validateDashboardPermission(p); err != nil {
+			return nil, ErrInvalidPermissionTemplate.Errorf("%w", err)
+		}
+		if seen[p.key()] {
+			return nil, ErrInvalidPermissionTemplate.Errorf("duplicate permission for %s", p.key())
+		}
+		seen[p.key()] = true
+		template.Permissions = append(template.Permissions, DashboardPermission{TeamID: p.TeamID, Role: p.Role, Permission: p.Permission})
+	}
+	value, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.kv.Set(ctx, user.GetOrgID(), permissionTemplateNamespace, folderUID, string(value)); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Delete removes the permission template of the folder, the permissions of the dashboards it was applied to are kept
+func (t *PermissionTemplates) Delete(ctx context.Context, user identity.Requester, folderUID string) error {
+	if _, err := t.Get(ctx, user.GetOrgID(), folderUID); err != nil {
+		return err
+	}
+	return t.kv.Del(ctx, user.GetOrgID(), permissionTemplateNamespace, folderUID)
+}
+
+func (t *PermissionTemplates) requireFolder(ctx context.Context, user identity.Requester, folderUID string) error {
+	if folderUID == "" {
+		return ErrInvalidPermissionTemplate.Errorf("missing folderUid, the General folder can not have a permission template")
+	}
+	_, err := t.folders.Get(ctx, &folder.GetFolderQuery{UID: &folderUID, OrgID: user.GetOrgID(), SignedInUser: user})
+	if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+		return ErrInvalidPermissionTemplate.Errorf("folder %q not found", folderUID)
+	}
+	return err
+}
+
+// apply gives the permissions of the template of its folder to a created dashboard. Failures are logged, the
+// dashboard is already created with the default permissions.
+func (t *PermissionTemplates) apply(ctx context.Context, created runtime.Object) {
+	m, err := utils.MetaAccessor(created)
+	if err != nil || m.GetFolder() == "" {
+		return
+	}
+	info, err := claims.ParseNamespace(m.GetNamespace())
+	if err != nil {
+		return
+	}
+	template, err := t.Get(ctx, info.OrgID, m.GetFolder())
+	if err != nil {
+		if !errors.Is(err, ErrPermissionTemplateNotFound) {
+			t.log.Error("Failed to read the permission template of a folder", "folderUid", m.GetFolder(), "orgId", info.OrgID, "error", err)
+		}
+		return
+	}
+	if len(template.Permissions) == 0 {
+		return
+	}
+	commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(template.Permissions))
+	for _, p := range template.Permissions {
+		commands = append(commands, accesscontrol.SetResourcePermissionCommand{TeamID: p.TeamID, BuiltinRole: p.Role, Permission: p.Permission})
+	}
+	if _, err := t.permissions.SetPermissions(ctx, info.OrgID, m.GetName(), commands...); err != nil {
+		t.log.Error("Failed to apply the permission template of a folder to a created dashboard", "uid", m.GetName(), "folderUid", m.GetFolder(), "orgId", info.OrgID, "error", err)
+	}
+}
+
+// permissionTemplateStorage applies the permission template of their folder to the dashboards created through the
+// storage. The permissions are set once the dashboard exists, so the template is applied after the create instead of
+// in the admission of the request.
+type permissionTemplateStorage struct {
+	grafanarest.Storage
+	templates *PermissionTemplates
+}
+
+// permissionTemplateWatchStorage keeps watch support of storages that implement it
+type permissionTemplateWatchStorage struct {
+	*permissionTemplateStorage
+	rest.Watcher
+}
+
+// WithPermissionTemplates applies the permission templates of the folders to the dashboards created through the
+// storage, other storages are returned unchanged
+func WithPermissionTemplates(store rest.Storage, templates *PermissionTemplates) rest.Storage {
+	s, ok := store.(grafanarest.Storage)
+	if !ok || templates == nil {
+		return store
+	}
+	if w, ok := store.(rest.Watcher); ok {
+		return &permissionTemplateWatchStorage{permissionTemplateStorage: &permissionTemplateStorage{Storage: s, templates: templates}, Watcher: w}
+	}
+	return &permissionTemplateStorage{Storage: s, templates: templates}
+}
+
+func (s *permissionTemplateStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	created, err := s.Storage.Create(ctx, obj, createValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.templates.apply(ctx, created)
+	}
+	return created, err
+}
+
+func (s *permissionTemplateStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	updated, created, err := s.Storage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	if err == nil && created && (options == nil || len(options.DryRun) == 0) {
+		s.templates.apply(ctx, updated)
+	}
+	return updated, created, err
+}
@@ -0,0 +1,150 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestPermissionTemplateStorage(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	folders := foldertest.NewFakeService()
+	folders.ExpectedFolder = &folder.Folder{UID: "xyz"}
+	permissions := &recordingPermissionsService{}
+	templates := NewPermissionTemplates(kvstore.NewFakeKVStore(), folders, permissions)
+	templates.now = func() time.Time { return now }
+
+	requester := &user.SignedInUser{UserUID: "u1", OrgID: 1, FallbackType: claims.TypeUser}
+	ctx := identity.WithRequester(context.Background(), requester)
+	template, err := templates.Set(ctx, requester, "xyz", []DashboardPermission{
+		{TeamID: 2, Permission: "Edit"},
+		{Role: "Viewer", Permission: "View"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &FolderPermissionTemplate{
+		FolderUID:   "xyz",
+		Permissions: []DashboardPermission{{TeamID: 2, Permission: "Edit"}, {Role: "Viewer", Permission: "View"}},
+		UpdatedAt:   now,
+		UpdatedBy:   "user:u1",
+	}, template)
+
+	dashboardIn := func(folderUID string) *dashboardv0alpha1.Dashboard {
+		dash := &dashboardv0alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "default"}}
+		m, err := utils.MetaAccessor(dash)
+		require.NoError(t, err)
+		m.SetFolder(folderUID)
+		return dash
+	}
+	store := WithPermissionTemplates(&fakeAuditedStorage{}, templates).(*permissionTemplateStorage)
+
+	t.Run("applies the template of the folder to the created dashboards", func(t *testing.T) {
+		permissions.set = nil
+		_, err := store.Create(ctx, dashboardIn("xyz"), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []recordedPermissions{{orgID: 1, uid: "abc", commands: []accesscontrol.SetResourcePermissionCommand{
+			{TeamID: 2, Permission: "Edit"},
+			{BuiltinRole: "Viewer", Permission: "View"},
+		}}}, permissions.set)
+	})
+
+	t.Run("ignores the dashboards of folders without a template and dry runs", func(t *testing.T) {
+		permissions.set = nil
+		_, err := store.Create(ctx, dashboardIn("other"), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		_, err = store.Create(ctx, dashboardIn(""), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		_, err = store.Create(ctx, dashboardIn("xyz"), nil, &metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		require.NoError(t, err)
+		require.Empty(t, permissions.set)
+	})
+
+	t.Run("rejects invalid templates", func(t *testing.T) {
+		for _, p := range [][]DashboardPermission{
+			{{UserID: 1, Permission: "Edit"}},
+			{{TeamID: 2, Role: "Viewer", Permission: "Edit"}},
+			{{Role: "Owner", Permission: "Edit"}},
+			{{TeamID: 2, Permission: "Delete"}},
+			{{TeamID: 2, Permission: "Edit"}, {TeamID: 2, Permission: "View"}},
+		} {
+			_, err := templates.Set(ctx, requester, "xyz", p)
+			require.ErrorIs(t, err, ErrInvalidPermissionTemplate, "%+v", p)
+		}
+		_, err := templates.Set(ctx, requester, "", nil)
+		require.ErrorIs(t, err, ErrInvalidPermissionTemplate)
+	})
+
+	t.Run("deletes the template", func(t *testing.T) {
+		require.NoError(t, templates.Delete(ctx, requester, "xyz"))
+		_, err := templates.Get(ctx, 1, "xyz")
+		require.ErrorIs(t, err, ErrPermissionTemplateNotFound)
+		require.ErrorIs(t, templates.Delete(ctx, requester, "xyz"), ErrPermissionTemplateNotFound)
+	})
+}
+
+func TestPermissionTemplateRoute(t *testing.T) {
+	folders := foldertest.NewFakeService()
+	folders.ExpectedFolder = &folder.Folder{UID: "xyz"}
+	templates := NewPermissionTemplates(kvstore.NewFakeKVStore(), folders, &recordingPermissionsService{})
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{UserUID: "u1", OrgID: 1, FallbackType: claims.TypeUser})
+
+	serve := func(allowed bool, method string, query string, body string) *httptest.ResponseRecorder {
+		route := templates.APIRoutes(dashboardv0alpha1.DashboardResourceInfo, actest.FakeAccessControl{ExpectedEvaluate: allowed})[0]
+		req := httptest.NewRequest(method, "/permissiontemplates"+query, strings.NewReader(body)).WithContext(ctx)
+		req = mux.SetURLVars(req, map[string]string{"namespace": "default"})
+		rec := httptest.NewRecorder()
+		route.Handler(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusNotFound, serve(true, http.MethodGet, "?folderUid=xyz", "").Code)
+
+	rec := serve(true, http.MethodPut, "", `{"folderUid":"xyz","permissions":[{"teamId":2,"permission":"Edit"}]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(true, http.MethodGet, "?folderUid=xyz", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	template := FolderPermissionTemplate{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &template))
+	require.Equal(t, []DashboardPermission{{TeamID: 2, Permission: "Edit"}}, template.Permissions)
+
+	require.Equal(t, http.StatusBadRequest, serve(true, http.MethodGet, "", "").Code)
+	require.Equal(t, http.StatusBadRequest, serve(true, http.MethodPut, "", `{"folderUid":"xyz","permissions":[{"userId":1,"permission":"Edit"}]}`).Code)
+	require.Equal(t, http.StatusForbidden, serve(false, http.MethodGet, "?folderUid=xyz", "").Code)
+	require.Equal(t, http.StatusForbidden, serve(false, http.MethodDelete, "?folderUid=xyz", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(true, http.MethodDelete, "?folderUid=xyz", "").Code)
+}
+
+type recordedPermissions struct {
+	orgID    int64
+	uid      string
+	commands []accesscontrol.SetResourcePermissionCommand
+}
+
+type recordingPermissionsService struct {
+	actest.FakePermissionsService
+	set []recordedPermissions
+}
+
+func (s *recordingPermissionsService) SetPermissions(_ context.Context, orgID int64, resourceID string, commands ...accesscontrol.SetResourcePermissionCommand) ([]accesscontrol.ResourcePermission, error) {
+	s.set = append(s.set, recordedPermissions{orgID: orgID, uid: resourceID, commands: commands})
+	return nil, nil
+}
@@ -0,0 +1,211 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/admission"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrInvalidQuotaRequest = errutil.BadRequest("dashboards.quota.invalid")
+	ErrQuotaAccessDenied   = errutil.Forbidden("dashboards.quota.forbidden", errutil.WithPublicMessage("You are not allowed to read the quotas of the org"))
+)
+
+// Scopes of the dashboard quotas
+const (
+	QuotaScopeGlobal = "global"
+	QuotaScopeOrg    = "org"
+	QuotaScopeFolder = "folder"
+)
+
+// QuotaUsage is the number of dashboards used in a quota scope, a negative limit is unlimited
+type QuotaUsage struct {
+	Scope     string `json:"scope"`
+	FolderUID string `json:"folderUid,omitempty"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+}
+
+// Reached is true when no more dashboards can be created in the scope
+func (u QuotaUsage) Reached() bool {
+	return u.Limit >= 0 && u.Used >= u.Limit
+}
+
+func (u QuotaUsage) description() string {
+	switch {
+	case u.Scope != QuotaScopeFolder:
+		return u.Scope
+	case u.FolderUID == "":
+		return "General folder"
+	default:
+		return fmt.Sprintf("folder %q", u.FolderUID)
+	}
+}
+
+// QuotaStatus lists the dashboard quotas of an org and a folder
+type QuotaStatus struct {
+	Enabled bool         `json:"enabled"`
+	Quotas  []QuotaUsage `json:"quotas"`
+}
+
+// QuotaGuard enforces the dashboard quotas of the legacy API in the dashboards API: the dashboards of the instance,
+// of the org and of each folder. Dashboards without a folder are counted in the General folder.
+type QuotaGuard struct {
+	quotas      quota.Service
+	dashboards  dashboards.DashboardService
+	enabled     bool
+	folderLimit int64
+}
+
+func NewQuotaGuard(cfg *setting.Cfg, quotaService quota.Service, dashboardService dashboards.DashboardService) *QuotaGuard {
+	return &QuotaGuard{
+		quotas:      quotaService,
+		dashboards:  dashboardService,
+		enabled:     cfg.Quota.Enabled,
+		folderLimit: cfg.Quota.Org.FolderDashboard,
+	}
+}
+
+// Status returns the usage of the global, org and folder dashboard quotas
+func (g *QuotaGuard) Status(ctx context.Context, user identity.Requester, orgID int64, folderUID string) (*QuotaStatus, error) {
+	if !g.enabled {
+		return &QuotaStatus{Quotas: []QuotaUsage{}}, nil
+	}
+	usage, err := g.orgUsage(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	folderUsage, err := g.folderUsage(ctx, user, orgID, folderUID)
+	if err != nil {
+		return nil, err
+	}
+	return &QuotaStatus{Enabled: true, Quotas: append(usage, folderUsage)}, nil
+}
+
+// Validate rejects the creation of dashboards above the quotas, and moves of dashboards into a full folder,
+// with 403 Forbidden and the current usage
+func (g *QuotaGuard) Validate(ctx context.Context, a admission.Attributes) error {
+	if g == nil || !g.enabled {
+		return nil
+	}
+	if a.GetResource().Resource != dashboard.DashboardResourceInfo.GroupResource().Resource || a.GetSubresource() != "" {
+		return nil
+	}
+
+	var usage []QuotaUsage
+	switch a.GetOperation() {
+	case admission.Create:
+		orgID, user, err := quotaRequester(ctx, a)
+		if err != nil {
+			return err
+		}
+		if usage, err = g.orgUsage(ctx, orgID); err != nil {
+			return err
+		}
+		folderUsage, err := g.writeFolderUsage(ctx, user, orgID, a)
+		if err != nil {
+			return err
+		}
+		usage = append(usage, folderUsage...)
+	case admission.Update:
+		if a.GetOldObject() == nil || folderOf(a.GetObject()) == folderOf(a.GetOldObject()) {
+			return nil
+		}
+		orgID, user, err := quotaRequester(ctx, a)
+		if err != nil {
+			return err
+		}
+		if usage, err = g.writeFolderUsage(ctx, user, orgID, a); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	for _, u := range usage {
+		if u.Reached() {
+			return apierrors.NewForbidden(dashboard.DashboardResourceInfo.GroupResource(), a.GetName(),
+				fmt.Errorf("dashboard quota reached: %d of %d dashboards used in the %s", u.Used, u.Limit, u.description()))
+		}
+	}
+	return nil
+}
+
+// orgUsage returns the usage of the global and org dashboard quotas
+func (g *QuotaGuard) orgUsage(ctx context.Context, orgID int64) ([]QuotaUsage, error) {
+	usage := []QuotaUsage{}
+	for _, scope := range []struct {
+		scope quota.Scope
+		id    int64
+		name  string
+	}{
+		{scope: quota.GlobalScope, name: QuotaScopeGlobal},
+		{scope: quota.OrgScope, id: orgID, name: QuotaScopeOrg},
+	} {
+		quotas, err := g.quotas.GetQuotasByScope(ctx, scope.scope, scope.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range quotas {
+			if q.Target == string(dashboards.QuotaTarget) {
+				usage = append(usage, QuotaUsage{Scope: scope.name, Limit: q.Limit, Used: q.Used})
+			}
+		}
+	}
+	return usage, nil
+}
+
+func (g *QuotaGuard) folderUsage(ctx context.Context, user identity.Requester, orgID int64, folderUID string) (QuotaUsage, error) {
+	used, err := g.dashboards.CountInFolders(ctx, orgID, []string{folderUID}, user)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+	return QuotaUsage{Scope: QuotaScopeFolder, FolderUID: folderUID, Limit: g.folderLimit, Used: used}, nil
+}
+
+// writeFolderUsage returns the usage of the folder the dashboard is written to, the folder is not counted without a limit
+func (g *QuotaGuard) writeFolderUsage(ctx context.Context, user identity.Requester, orgID int64, a admission.Attributes) ([]QuotaUsage, error) {
+	if g.folderLimit < 0 {
+		return nil, nil
+	}
+	usage, err := g.folderUsage(ctx, user, orgID, folderOf(a.GetObject()))
+	if err != nil {
+		return nil, err
+	}
+	return []QuotaUsage{usage}, nil
+}
+
+func quotaRequester(ctx context.Context, a admission.Attributes) (int64, identity.Requester, error) {
+	info, err := claims.ParseNamespace(a.GetNamespace())
+	if err != nil {
+		return 0, nil, apierrors.NewBadRequest(fmt.Sprintf("invalid namespace: %s", err))
+	}
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	return info.OrgID, user, nil
+}
+
+// folderOf returns the folder of a dashboard, empty for the General folder
+func folderOf(obj any) string {
+	if obj == nil {
+		return ""
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return ""
+	}
+	return meta.GetFolder()
+}
@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQuotaGuard(t *testing.T) {
+	ctx := identity.WithRequester(context.Background(), &identity.StaticRequester{UserID: 1, OrgID: 1})
+	newDashboard := func(folder string) *dashboardv0alpha1.Dashboard {
+		dash := &dashboardv0alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "default"}}
+		meta, err := utils.MetaAccessor(dash)
+		require.NoError(t, err)
+		meta.SetFolder(folder)
+		return dash
+	}
+	newGuard := func(orgUsed int64, folderLimit int64, folderUsed map[string]int64) *QuotaGuard {
+		cfg := setting.NewCfg()
+		cfg.Quota.Enabled = true
+		cfg.Quota.Org.FolderDashboard = folderLimit
+
+		svc := dashboards.NewFakeDashboardService(t)
+		for folder, used := range folderUsed {
+			svc.On("CountInFolders", mock.Anything, int64(1), []string{folder}, mock.Anything).Return(used, nil).Maybe()
+		}
+		return NewQuotaGuard(cfg, &fakeQuotaService{
+			FakeQuotaService: quotatest.New(false, nil),
+			usage: map[quota.Scope]quota.QuotaDTO{
+				quota.GlobalScope: {Target: string(dashboards.QuotaTarget), Limit: -1, Used: 20},
+				quota.OrgScope:    {Target: string(dashboards.QuotaTarget), Limit: 10, Used: orgUsed},
+			},
+		}, svc)
+	}
+	validate := func(g *QuotaGuard, op admission.Operation, obj runtime.Object, old runtime.Object) error {
+		return g.Validate(ctx, admission.NewAttributesRecord(
+			obj,
+			old,
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(),
+			"default",
+			"abc",
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionResource(),
+			"",
+			op,
+			nil,
+			false,
+			&user.SignedInUser{},
+		))
+	}
+
+	t.Run("org quota", func(t *testing.T) {
+		require.NoError(t, validate(newGuard(9, -1, nil), admission.Create, newDashboard(""), nil))
+
+		err := validate(newGuard(10, -1, nil), admission.Create, newDashboard(""), nil)
+		require.True(t, apierrors.IsForbidden(err), "create: %v", err)
+		require.Contains(t, err.Error(), "10 of 10 dashboards used in the org")
+
+		require.NoError(t, validate(newGuard(10, -1, nil), admission.Update, newDashboard(""), newDashboard("")))
+	})
+
+	t.Run("folder quota", func(t *testing.T) {
+		guard := newGuard(0, 5, map[string]int64{"": 5, "xyz": 4, "full": 5})
+		require.NoError(t, validate(guard, admission.Create, newDashboard("xyz"), nil))
+
+		err := validate(guard, admission.Create, newDashboard(""), nil)
+		require.True(t, apierrors.IsForbidden(err), "create: %v", err)
+		require.Contains(t, err.Error(), "5 of 5 dashboards used in the General folder")
+
+		err = validate(guard, admission.Update, newDashboard("full"), newDashboard("xyz"))
+		require.True(t, apierrors.IsForbidden(err), "move: %v", err)
+		require.Contains(t, err.Error(), `folder "full"`)
+
+		require.NoError(t, validate(guard, admission.Update, newDashboard("full"), newDashboard("full")))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		guard := NewQuotaGuard(cfg, quotatest.New(true, nil), dashboards.NewFakeDashboardService(t))
+		require.NoError(t, validate(guard, admission.Create, newDashboard(""), nil))
+
+		status, err := guard.Status(ctx, &identity.StaticRequester{}, 1, "")
+		require.NoError(t, err)
+		require.Equal(t, &QuotaStatus{Quotas: []QuotaUsage{}}, status)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		status, err := newGuard(3, -1, map[string]int64{"xyz": 2}).Status(ctx, &identity.StaticRequester{}, 1, "xyz")
+		require.NoError(t, err)
+		require.Equal(t, &QuotaStatus{Enabled: true, Quotas: []QuotaUsage{
+			{Scope: QuotaScopeGlobal, Limit: -1, Used: 20},
+			{Scope: QuotaScopeOrg, Limit: 10, Used: 3},
+			{Scope: QuotaScopeFolder, FolderUID: "xyz", Limit: -1, Used: 2},
+		}}, status)
+	})
+}
+
+type fakeQuotaService struct {
+	*quotatest.FakeQuotaService
+	usage map[quota.Scope]quota.QuotaDTO
+}
+
+func (f *fakeQuotaService) GetQuotasByScope(_ context.Context, scope quota.Scope, _ int64) ([]quota.QuotaDTO, error) {
+	return []quota.QuotaDTO{f.usage[scope]}, nil
+}
@@ -0,0 +1,94 @@
+package dashboard
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/admission"
+
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Kinds of requests limited by the NamespaceRateLimiter, each namespace has a token bucket for each kind
+const (
+	RateLimitSearch = "search"
+	RateLimitWrite  = "write"
+)
+
+// NamespaceRateLimiter limits the searches and the writes of dashboards in each namespace, so automation misbehaving
+// in one org does not slow down the search index of the others. The limiter is shared by all the API versions.
+type NamespaceRateLimiter struct {
+	enabled bool
+	limits  map[string]rateLimit
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+}
+
+type rateLimit struct {
+	rate  rate.Limit
+	burst int
+}
+
+func ProvideNamespaceRateLimiter(cfg *setting.Cfg) *NamespaceRateLimiter {
+	s := cfg.DashboardRateLimit
+	return &NamespaceRateLimiter{
+		enabled: s.Enabled,
+		limits: map[string]rateLimit{
+			RateLimitSearch: {rate: rate.Limit(s.SearchRate), burst: s.SearchBurst},
+			RateLimitWrite:  {rate: rate.Limit(s.WriteRate), burst: s.WriteBurst},
+		},
+		now:     time.Now,
+		buckets: map[string]*rate.Limiter{},
+	}
+}
+
+// Allow takes a token from the bucket of the kind of request in the namespace.
+// When the bucket is empty, it returns a 429 Too Many Requests error telling when to retry.
+func (l *NamespaceRateLimiter) Allow(namespace string, kind string) error {
+	if l == nil || !l.enabled {
+		return nil
+	}
+	limit, ok := l.limits[kind]
+	if !ok {
+		return nil
+	}
+
+	l.mu.Lock()
+	key := kind + "/" + namespace
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = rate.NewLimiter(limit.rate, limit.burst)
+		l.buckets[key] = bucket
+	}
+	l.mu.Unlock()
+
+	now := l.now()
+	r := bucket.ReserveN(now, 1)
+	delay := r.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	r.CancelAt(now)
+	return apierrors.NewTooManyRequests(
+		fmt.Sprintf("too many dashboard %s requests in namespace %s", kind, namespace),
+		int(math.Ceil(delay.Seconds())))
+}
+
+// Validate limits the creates, updates and deletes of dashboards
+func (l *NamespaceRateLimiter) Validate(a admission.Attributes) error {
+	if a.GetResource().Resource != dashboard.DashboardResourceInfo.GroupResource().Resource || a.GetSubresource() != "" {
+		return nil
+	}
+	switch a.GetOperation() {
+	case admission.Create, admission.Update, admission.Delete:
+		return l.Allow(a.GetNamespace(), RateLimitWrite)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/admission"
+
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNamespaceRateLimiter(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DashboardRateLimit = setting.DashboardRateLimitSettings{Enabled: true, SearchRate: 1, SearchBurst: 2, WriteRate: 0.1, WriteBurst: 1}
+	l := ProvideNamespaceRateLimiter(cfg)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	t.Run("searches are limited per namespace", func(t *testing.T) {
+		require.NoError(t, l.Allow("default", RateLimitSearch))
+		require.NoError(t, l.Allow("default", RateLimitSearch))
+		err := l.Allow("default", RateLimitSearch)
+		require.True(t, apierrors.IsTooManyRequests(err))
+		retry, ok := apierrors.SuggestsClientDelay(err)
+		require.True(t, ok)
+		require.Equal(t, 1, retry)
+
+		require.NoError(t, l.Allow("org-2", RateLimitSearch), "other namespaces have their own bucket")
+
+		now = now.Add(time.Second)
+		require.NoError(t, l.Allow("default", RateLimitSearch), "the bucket is refilled over time")
+	})
+
+	t.Run("writes are limited in the admission", func(t *testing.T) {
+		attrs := func(op admission.Operation, subresource string) admission.Attributes {
+			return admission.NewAttributesRecord(nil, nil, dashboard.DashboardResourceInfo.GroupVersionKind(), "default", "dash",
+				dashboard.DashboardResourceInfo.GroupVersionResource(), subresource, op, nil, false, nil)
+		}
+		require.NoError(t, l.Validate(attrs(admission.Create, "")))
+		err := l.Validate(attrs(admission.Delete, ""))
+		require.True(t, apierrors.IsTooManyRequests(err))
+		retry, _ := apierrors.SuggestsClientDelay(err)
+		require.Equal(t, 10, retry)
+
+		require.NoError(t, l.Validate(attrs(admission.Update, "status")), "subresources are not limited")
+		now = now.Add(time.Second)
+		require.NoError(t, l.Allow("default", RateLimitSearch), "searches have their own bucket")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var nilLimiter *NamespaceRateLimiter
+		require.NoError(t, nilLimiter.Allow("default", RateLimitSearch))
+		disabled := ProvideNamespaceRateLimiter(setting.NewCfg())
+		for i := 0; i < 100; i++ {
+			require.NoError(t, disabled.Allow("default", RateLimitWrite))
+		}
+	})
+}
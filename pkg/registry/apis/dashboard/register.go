@@ -1,6 +1,9 @@
 package dashboard
 
 import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
@@ -18,21 +21,25 @@ import (
 )
 
 var (
-	_ builder.APIGroupBuilder      = (*DashboardsAPIBuilder)(nil)
-	_ builder.OpenAPIPostProcessor = (*DashboardsAPIBuilder)(nil)
+	_ builder.APIGroupBuilder       = (*DashboardsAPIBuilder)(nil)
+	_ builder.OpenAPIPostProcessor  = (*DashboardsAPIBuilder)(nil)
+	_ builder.APIGroupRequestFilter = (*DashboardsAPIBuilder)(nil)
 )
 
 // This is used just so wire has something unique to return
-type DashboardsAPIBuilder struct{}
+type DashboardsAPIBuilder struct {
+	metrics *APIMetrics
+}
 
 func RegisterAPIService(
 	features featuremgmt.FeatureToggles,
 	apiregistration builder.APIRegistrar,
+	reg prometheus.Registerer,
 ) *DashboardsAPIBuilder {
 	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) && !features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI) {
 		return nil // skip registration unless opting into experimental apis or dashboards in the k8s api
 	}
-	builder := &DashboardsAPIBuilder{}
+	builder := &DashboardsAPIBuilder{metrics: NewAPIMetrics(reg)}
 	apiregistration.RegisterAPI(builder)
 	return builder
 }
@@ -72,6 +79,11 @@ func (b *DashboardsAPIBuilder) PostProcessOpenAPI(oas *spec3.OpenAPI) (*spec3.Op
 	return oas, nil
 }
 
+// FilterRequests records the metrics of the requests of all the versions of the dashboards API group
+func (b *DashboardsAPIBuilder) FilterRequests(handler http.Handler) http.Handler {
+	return b.metrics.Handler(handler)
+}
+
 func (b *DashboardsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 	return nil // no custom API routes
 }
@@ -0,0 +1,177 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/shorturls"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrInvalidResolve   = errutil.BadRequest("dashboards.resolve.invalid")
+	ErrResolveNotFound  = errutil.NotFound("dashboards.resolve.not-found")
+	ErrResolveAmbiguous = errutil.Conflict("dashboards.resolve.ambiguous")
+)
+
+// ResolvedLink is the dashboard a legacy link moved to, as the information of a 301 redirect
+type ResolvedLink struct {
+	// Status is always 301, the link moved permanently to the URL
+	Status int `json:"status"`
+	// Name is the name of the dashboard resource, its UID
+	Name string `json:"name"`
+	// URL is the canonical url of the dashboard, with the query of a short URL
+	URL string `json:"url"`
+	// APIPath is the path of the dashboard resource in the API
+	APIPath string `json:"apiPath,omitempty"`
+}
+
+// LinkResolver maps the legacy links of dashboards, their slugs, short URLs and numeric IDs, to the name of the
+// dashboard resource, so the old links keep working once the dashboards are read through the API. The dashboards the
+// user can not read are not found.
+type LinkResolver struct {
+	dashboards    dashboards.DashboardService
+	shortURLs     shorturls.Service
+	accessControl accesscontrol.AccessControl
+	// bySlug returns the UIDs of the dashboards of an org with a slug
+	bySlug func(ctx context.Context, orgID int64, slug string) ([]string, error)
+	log    log.Logger
+}
+
+func NewLinkResolver(sql db.DB, dashboardService dashboards.DashboardService, shortURLs shorturls.Service, accessControl accesscontrol.AccessControl) *LinkResolver {
+	return &LinkResolver{
+		dashboards:    dashboardService,
+		shortURLs:     shortURLs,
+		accessControl: accessControl,
+		bySlug: func(ctx context.Context, orgID int64, slug string) ([]string, error) {
+			uids := []string{}
+			err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+				return sess.SQL("SELECT uid FROM dashboard WHERE org_id = ? AND slug = ? AND is_folder = ? AND deleted IS NULL",
+					orgID, slug, sql.GetDialect().BooleanStr(false)).Find(&uids)
+			})
+			return uids, err
+		},
+		log: log.New("dashboards.resolve"),
+	}
+}
+
+// ResolveID resolves the numeric ID of a dashboard, e.g. of the legacy API
+func (l *LinkResolver) ResolveID(ctx context.Context, user identity.Requester, id int64) (*ResolvedLink, error) {
+	return l.resolve(ctx, user, &dashboards.GetDashboardQuery{ID: id, OrgID: user.GetOrgID()}, "")
+}
+
+// ResolveSlug resolves the slug of a dashboard, e.g. of a /dashboard/db/:slug link. A slug is not unique, it is
+// ambiguous when the user can read several dashboards with it.
+func (l *LinkResolver) ResolveSlug(ctx context.Context, user identity.Requester, slug string) (*ResolvedLink, error) {
+	uids, err := l.bySlug(ctx, user.GetOrgID(), slug)
+	if err != nil {
+		return nil, err
+	}
+	var resolved *ResolvedLink
+	for _, uid := range uids {
+		link, err := l.resolve(ctx, user, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()}, "")
+		if errors.Is(err, ErrResolveNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resolved != nil {
+			return nil, ErrResolveAmbiguous.Errorf("several dashboards have the slug %q, link them by uid", slug)
+		}
+		resolved = link
+	}
+	if resolved == nil {
+		return nil, ErrResolveNotFound.Errorf("no dashboard with the slug %q", slug)
+	}
+	return resolved, nil
+}
+
+// ResolveShortURL resolves a short URL, e.g. of a /goto/:uid link, to the dashboard it links to. The query of the
+// short URL, e.g. the time range, is kept in the URL of the dashboard. With visit, the short URL is marked as seen,
+// as when it is followed, so it is not deleted as stale.
+func (l *LinkResolver) ResolveShortURL(ctx context.Context, user identity.Requester, uid string, visit bool) (*ResolvedLink, error) {
+	shortURL, err := l.shortURLs.GetShortURLByUID(ctx, signedInUser(user), uid)
+	if err != nil {
+		if shorturls.ErrShortURLNotFound.Is(err) {
+			return nil, ErrResolveNotFound.Errorf("short URL %q not found", uid)
+		}
+		return nil, err
+	}
+	if visit {
+		if err := l.shortURLs.UpdateLastSeenAt(ctx, shortURL); err != nil {
+			l.log.Warn("Failed to update the short URL last seen at", "uid", uid, "error", err)
+		}
+	}
+
+	target, err := url.Parse(shortURL.Path)
+	if err != nil {
+		return nil, ErrInvalidResolve.Errorf("short URL %q has an invalid path: %w", uid, err)
+	}
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(target.Path, setting.AppSubUrl), "/"), "/")
+	var link *ResolvedLink
+	switch {
+	case len(segments) >= 2 && (segments[0] == "d" || segments[0] == "d-solo"):
+		link, err = l.resolve(ctx, user, &dashboards.GetDashboardQuery{UID: segments[1], OrgID: user.GetOrgID()}, target.RawQuery)
+	case len(segments) == 3 && segments[0] == "dashboard" && segments[1] == "db":
+		link, err = l.ResolveSlug(ctx, user, segments[2])
+		if err == nil && target.RawQuery != "" {
+			link.URL += "?" + target.RawQuery
+		}
+	default:
+		return nil, ErrInvalidResolve.Errorf("short URL %q does not link to a dashboard", uid)
+	}
+	return link, err
+}
+
+// resolve returns the canonical link of the dashboard of the query, when the user can read it
+func (l *LinkResolver) resolve(ctx context.Context, user identity.Requester, query *dashboards.GetDashboardQuery, rawQuery string) (*ResolvedLink, error) {
+	dash, err := l.dashboards.GetDashboard(ctx, query)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, ErrResolveNotFound.Errorf("dashboard not found")
+		}
+		return nil, err
+	}
+	if dash.IsFolder {
+		return nil, ErrResolveNotFound.Errorf("dashboard not found")
+	}
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.UID))
+	allowed, err := l.accessControl.Evaluate(ctx, user, evaluator)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		// the dashboards the user can not read are not disclosed
+		return nil, ErrResolveNotFound.Errorf("dashboard not found")
+	}
+
+	link := &ResolvedLink{
+		Status: http.StatusMovedPermanently,
+		Name:   dash.UID,
+		URL:    dashboards.GetDashboardURL(dash.UID, dash.Slug),
+	}
+	if rawQuery != "" {
+		link.URL += "?" + rawQuery
+	}
+	return link, nil
+}
+
+// parseResolveID reads the numeric ID of a dashboard
+func parseResolveID(raw string) (int64, error) {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidResolve.Errorf("expected a positive numeric id, got %q", raw)
+	}
+	return id, nil
+}
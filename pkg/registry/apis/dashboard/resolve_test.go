@@ -0,0 +1,172 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/shorturls"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// fakeShortURLs stores the paths of the short URLs by uid
+type fakeShortURLs struct {
+	shorturls.Service
+	paths map[string]string
+	seen  []string
+}
+
+func (f *fakeShortURLs) GetShortURLByUID(_ context.Context, _ *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
+	p, ok := f.paths[uid]
+	if !ok {
+		return nil, shorturls.ErrShortURLNotFound.Errorf("short url not found")
+	}
+	return &shorturls.ShortUrl{Uid: uid, Path: p}, nil
+}
+
+func (f *fakeShortURLs) UpdateLastSeenAt(_ context.Context, shortURL *shorturls.ShortUrl) error {
+	f.seen = append(f.seen, shortURL.Uid)
+	return nil
+}
+
+func TestLinkResolver(t *testing.T) {
+	dashes := []*dashboards.Dashboard{
+		{ID: 1, UID: "abc", Slug: "overview", OrgID: 1},
+		{ID: 2, UID: "def", Slug: "shared", OrgID: 1},
+		{ID: 3, UID: "ghi", Slug: "shared", OrgID: 1},
+		{ID: 4, UID: "folder", Slug: "folder", OrgID: 1, IsFolder: true},
+	}
+	svc := dashboards.NewFakeDashboardService(t)
+	svc.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, q *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+		for _, d := range dashes {
+			if d.OrgID == q.OrgID && (d.ID == q.ID || d.UID == q.UID) {
+				return d, nil
+			}
+		}
+		return nil, dashboards.ErrDashboardNotFound
+	}).Maybe()
+	shortURLs := &fakeShortURLs{paths: map[string]string{
+		"short1": "d/abc/old-title?from=now-6h&to=now",
+		"short2": "dashboard/db/overview",
+		"short3": "explore?left=xyz",
+	}}
+	newResolver := func(allowed bool) *LinkResolver {
+		l := NewLinkResolver(nil, svc, shortURLs, actest.FakeAccessControl{ExpectedEvaluate: allowed})
+		l.bySlug = func(_ context.Context, orgID int64, slug string) ([]string, error) {
+			uids := []string{}
+			for _, d := range dashes {
+				if d.OrgID == orgID && d.Slug == slug {
+					uids = append(uids, d.UID)
+				}
+			}
+			return uids, nil
+		}
+		return l
+	}
+	resolver := newResolver(true)
+	ctx := context.Background()
+	requester := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+
+	t.Run("resolves the numeric id", func(t *testing.T) {
+		link, err := resolver.ResolveID(ctx, requester, 1)
+		require.NoError(t, err)
+		require.Equal(t, &ResolvedLink{Status: http.StatusMovedPermanently, Name: "abc", URL: "/d/abc/overview"}, link)
+
+		_, err = resolver.ResolveID(ctx, requester, 404)
+		require.ErrorIs(t, err, ErrResolveNotFound)
+		_, err = resolver.ResolveID(ctx, requester, 4)
+		require.ErrorIs(t, err, ErrResolveNotFound, "folders are not resolved")
+	})
+
+	t.Run("resolves the slug", func(t *testing.T) {
+		link, err := resolver.ResolveSlug(ctx, requester, "overview")
+		require.NoError(t, err)
+		require.Equal(t, "abc", link.Name)
+
+		_, err = resolver.ResolveSlug(ctx, requester, "shared")
+		require.ErrorIs(t, err, ErrResolveAmbiguous)
+		_, err = resolver.ResolveSlug(ctx, requester, "unknown")
+		require.ErrorIs(t, err, ErrResolveNotFound)
+	})
+
+	t.Run("resolves the short url with its query", func(t *testing.T) {
+		shortURLs.seen = nil
+		link, err := resolver.ResolveShortURL(ctx, requester, "short1", false)
+		require.NoError(t, err)
+		require.Equal(t, &ResolvedLink{Status: http.StatusMovedPermanently, Name: "abc", URL: "/d/abc/overview?from=now-6h&to=now"}, link)
+		require.Empty(t, shortURLs.seen, "resolving a short URL does not mark it as seen")
+
+		link, err = resolver.ResolveShortURL(ctx, requester, "short2", false)
+		require.NoError(t, err)
+		require.Equal(t, "abc", link.Name)
+
+		_, err = resolver.ResolveShortURL(ctx, requester, "short3", false)
+		require.ErrorIs(t, err, ErrInvalidResolve)
+		_, err = resolver.ResolveShortURL(ctx, requester, "missing", false)
+		require.ErrorIs(t, err, ErrResolveNotFound)
+	})
+
+	t.Run("marks the visited short url as seen", func(t *testing.T) {
+		shortURLs.seen = nil
+		_, err := resolver.ResolveShortURL(ctx, requester, "short1", true)
+		require.NoError(t, err)
+		require.Equal(t, []string{"short1"}, shortURLs.seen)
+	})
+
+	t.Run("the dashboards the user can not read are not found", func(t *testing.T) {
+		denied := newResolver(false)
+		_, err := denied.ResolveID(ctx, requester, 1)
+		require.ErrorIs(t, err, ErrResolveNotFound)
+		_, err = denied.ResolveSlug(ctx, requester, "overview")
+		require.ErrorIs(t, err, ErrResolveNotFound)
+	})
+
+	t.Run("route", func(t *testing.T) {
+		route := resolver.APIRoutes(dashboardv0alpha1.DashboardResourceInfo)[0]
+		serveMethod := func(method string, query string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, "/resolve"+query, nil)
+			req = req.WithContext(identity.WithRequester(req.Context(), requester))
+			req = mux.SetURLVars(req, map[string]string{"namespace": "default"})
+			rec := httptest.NewRecorder()
+			route.Handler(rec, req)
+			return rec
+		}
+		serve := func(query string) *httptest.ResponseRecorder {
+			return serveMethod(http.MethodGet, query)
+		}
+
+		rec := serve("?id=1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		link := ResolvedLink{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &link))
+		require.Equal(t, ResolvedLink{
+			Status:  http.StatusMovedPermanently,
+			Name:    "abc",
+			URL:     "/d/abc/overview",
+			APIPath: "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc",
+		}, link)
+
+		require.Equal(t, http.StatusBadRequest, serve("").Code)
+		require.Equal(t, http.StatusBadRequest, serve("?id=1&slug=overview").Code)
+		require.Equal(t, http.StatusBadRequest, serve("?id=abc").Code)
+		require.Equal(t, http.StatusNotFound, serve("?id=404").Code)
+		require.Equal(t, http.StatusConflict, serve("?slug=shared").Code)
+
+		shortURLs.seen = nil
+		require.Equal(t, http.StatusOK, serve("?shortUrl=short1").Code)
+		require.Empty(t, shortURLs.seen, "GET does not write")
+		require.Equal(t, http.StatusOK, serveMethod(http.MethodPost, "?shortUrl=short1").Code)
+		require.Equal(t, []string{"short1"}, shortURLs.seen)
+	})
+}
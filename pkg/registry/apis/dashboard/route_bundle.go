@@ -0,0 +1,261 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the routes applying and exporting bundles
+func (a *BundleApplier) APIRoutes() []builder.APIRouteHandler {
+	tags := []string{"Bundle"}
+	return []builder.APIRouteHandler{
+		{
+			Path: "bundle/apply",
+			Spec: &spec3.PathProps{
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Apply a bundle of folders, library panels and dashboards",
+						Description: "Items are applied in dependency order within a single transaction. If any item fails, nothing is applied. The permissions of a folder or dashboard are only applied when the user can change them. The body is a bundle, or a List of Folder, LibraryPanel and Dashboard resources as returned by the API.",
+						Parameters: []*spec3.Parameter{
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "namespace",
+									In:          "path",
+									Required:    true,
+									Example:     "default",
+									Description: "workspace",
+									Schema:      spec.StringProperty(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "dryRun",
+									In:          "query",
+									Description: "validate and apply the bundle, then roll it back",
+									Schema:      spec.BooleanProperty(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "remap",
+									In:          "query",
+									Description: "replace a UID of the bundle, and the references to it, as old:new. It can be repeated.",
+									Example:     "dev-folder:prod-folder",
+									Schema:      spec.ArrayProperty(spec.StringProperty()),
+								},
+							},
+						},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content: map[string]*spec3.MediaType{
+									"application/json": {
+										MediaTypeProps: spec3.MediaTypeProps{
+											Schema: spec.MapProperty(nil),
+										},
+									},
+									bundleTarContentType: {
+										MediaTypeProps: spec3.MediaTypeProps{
+											Schema: spec.StringProperty(),
+										},
+									},
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "Per item results of the bundle",
+											Content: map[string]*spec3.MediaType{
+												"application/json": {
+													MediaTypeProps: spec3.MediaTypeProps{
+														Schema: spec.MapProperty(nil),
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: a.handleApply,
+		},
+		{
+			Path: "bundle/export",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Export folders with their subfolders, library panels and dashboards as a bundle",
+						Description: "The bundle includes the permissions of the built-in roles as templates, and can be applied in another instance.",
+						Parameters: []*spec3.Parameter{
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "namespace",
+									In:          "path",
+									Required:    true,
+									Example:     "default",
+									Description: "workspace",
+									Schema:      spec.StringProperty(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "folder",
+									In:          "query",
+									Required:    true,
+									Description: "the UID of a folder to export. It can be repeated.",
+									Schema:      spec.ArrayProperty(spec.StringProperty()),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "format",
+									In:          "query",
+									Description: "json (the default) or tar, with a file per item",
+									Schema:      spec.StringProperty(),
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The bundle",
+											Content: map[string]*spec3.MediaType{
+												"application/json": {
+													MediaTypeProps: spec3.MediaTypeProps{
+														Schema: spec.MapProperty(nil),
+													},
+												},
+												bundleTarContentType: {
+													MediaTypeProps: spec3.MediaTypeProps{
+														Schema: spec.StringProperty(),
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: a.handleExport,
+		},
+	}
+}
+
+func (a *BundleApplier) handleApply(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidBundle)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			errhttp.Write(ctx, ErrInvalidBundle.Errorf("invalid dryRun parameter: %w", err), w)
+			return
+		}
+	}
+
+	bundle, err := a.readBundle(w, r)
+	if err != nil {
+		errhttp.Write(ctx, ErrInvalidBundle.Errorf("bad request data: %w", err), w)
+		return
+	}
+
+	if values := r.URL.Query()["remap"]; len(values) > 0 {
+		uids := make(map[string]string, len(values))
+		for _, v := range values {
+			from, to, ok := strings.Cut(v, ":")
+			if !ok || from == "" || to == "" {
+				errhttp.Write(ctx, ErrInvalidBundle.Errorf("invalid remap parameter %q, expected old:new", v), w)
+				return
+			}
+			uids[from] = to
+		}
+		bundle, err = bundle.RemapUIDs(uids)
+		if err != nil {
+			errhttp.Write(ctx, ErrInvalidBundle.Errorf("failed to remap the bundle: %w", err), w)
+			return
+		}
+	}
+
+	result, err := a.Apply(ctx, user, bundle, dryRun)
+	// the results of the items tell which one failed
+	if err != nil && (result == nil || !errors.Is(err, ErrBundleNotApplied)) {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// readBundle reads the bundle of the body, a tar archive written by WriteBundleTar or JSON, see DecodeBundle.
+// Bodies larger than the maximum size of a bundle are rejected before they are read in full.
+func (a *BundleApplier) readBundle(w http.ResponseWriter, r *http.Request) (Bundle, error) {
+	body := http.MaxBytesReader(w, r.Body, a.maxBundleSize)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == bundleTarContentType {
+		return ReadBundleTar(body)
+	}
+	return DecodeBundle(body)
+}
+
+func (a *BundleApplier) handleExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidBundle)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "tar" {
+		errhttp.Write(ctx, ErrInvalidBundle.Errorf("invalid format %q, expected json or tar", format), w)
+		return
+	}
+
+	bundle, err := a.Export(ctx, user, r.URL.Query()["folder"])
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	if format == "tar" {
+		w.Header().Set("Content-Type", bundleTarContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="bundle.tar"`)
+		if err := WriteBundleTar(w, *bundle); err != nil {
+			a.log.Warn("Failed to write the bundle", "error", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(bundle)
+}
@@ -0,0 +1,74 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route copying dashboards of the resource to another namespace
+func (c *DashboardCopier) APIRoutes(resource utils.ResourceInfo) []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: resource.GroupResource().Resource + ":copy",
+			Spec: &spec3.PathProps{
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{resource.GroupVersionKind().Kind},
+						Summary:     "Copy a dashboard to another namespace",
+						Description: "Only server admins can copy dashboards. The data source references are rewritten with the mapping, optionally the library panels are copied too. The references that do not exist in the target namespace are returned as unresolved.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content:  jsonContent(`{"dashboardUid":"abc","targetNamespace":"org-2","folderUid":"xyz","libraryPanels":true,"datasources":{"P1809F7CD0C75ACF3":"PBFA97CFB590B2093"}}`),
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The copy and the references that are not resolved in the target namespace",
+											Content:     jsonContent(`{"uid":"abc","namespace":"org-2","libraryPanels":["lib1"],"unresolved":[{"kind":"Datasource","ref":"loki"}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: c.handleCopy,
+		},
+	}
+}
+
+func (c *DashboardCopier) handleCopy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidCopy)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	req := CopyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errhttp.Write(ctx, ErrInvalidCopy.Errorf("bad request data: %w", err), w)
+		return
+	}
+
+	result, err := c.Copy(ctx, user, req)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
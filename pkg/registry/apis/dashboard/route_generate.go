@@ -0,0 +1,74 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route generating dashboards of the resource from a template
+func (g *DashboardGenerator) APIRoutes(resource utils.ResourceInfo) []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: resource.GroupResource().Resource + ":generate",
+			Spec: &spec3.PathProps{
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{resource.GroupVersionKind().Kind},
+						Summary:     "Generate dashboards from a template",
+						Description: "The {{ .name }} placeholders of the template are replaced with the values. With foreach, one dashboard is generated per item of the list value of that name. The dashboards are returned, and saved in the folder when save is set, all of them or none.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content:  jsonContent(`{"template":{"uid":"svc-{{ .service.name }}","title":"Service {{ .service.name }}","tags":["{{ .team }}"]},"values":{"team":"payments","service":[{"name":"api"},{"name":"worker"}]},"foreach":"service","save":true,"folderUid":"xyz"}`),
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The generated dashboards",
+											Content:     jsonContent(`{"dashboards":[{"uid":"svc-api","title":"Service api","spec":{"uid":"svc-api","title":"Service api","tags":["payments"]}}],"saved":true}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: g.handleGenerate,
+		},
+	}
+}
+
+func (g *DashboardGenerator) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidGenerate)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	req := GenerateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errhttp.Write(ctx, ErrInvalidGenerate.Errorf("bad request data: %w", err), w)
+		return
+	}
+
+	result, err := g.Generate(ctx, user, req)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,92 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route resolving the home dashboard of the user, and setting the home dashboard of the user,
+// a team or the org
+func (h *HomeDashboards) APIRoutes(resource utils.ResourceInfo) []builder.APIRouteHandler {
+	tags := []string{resource.GroupVersionKind().Kind}
+	homeResponse := func(description string) *spec3.Responses {
+		return &spec3.Responses{
+			ResponsesProps: spec3.ResponsesProps{
+				StatusCodeResponses: map[int]*spec3.Response{
+					200: {
+						ResponseProps: spec3.ResponseProps{
+							Description: description,
+							Content:     jsonContent(`{"scope":"team","teamId":2,"dashboardUid":"xyz","url":"/d/xyz/overview"}`),
+						},
+					},
+				},
+			},
+		}
+	}
+	return []builder.APIRouteHandler{
+		{
+			Path: "home",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Get the home dashboard",
+						Description: "The home dashboard of the user, then of its teams and then of the org. Without any, the configured home page url, or the default home dashboard in the spec.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						Responses:   homeResponse("The resolved home dashboard"),
+					},
+				},
+				Put: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Set the home dashboard of the user, a team or the org",
+						Description: "An empty dashboardUid clears the home dashboard of the scope. The org scope requires the orgs.preferences:write permission, and the team scope the teams:write permission on the team.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content:  jsonContent(`{"scope":"team","teamId":2,"dashboardUid":"xyz"}`),
+							},
+						},
+						Responses: homeResponse("The home dashboard of the scope"),
+					},
+				},
+			},
+			Handler: h.handleHome,
+		},
+	}
+}
+
+func (h *HomeDashboards) handleHome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidHome)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	var home *HomeDashboard
+	if r.Method == http.MethodPut {
+		req := SetHomeRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errhttp.Write(ctx, ErrInvalidHome.Errorf("bad request data: %w", err), w)
+			return
+		}
+		home, err = h.Set(ctx, user, req)
+	} else {
+		home, err = h.Get(ctx, user)
+	}
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(home)
+}
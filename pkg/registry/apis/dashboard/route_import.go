@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route importing dashboards exported for sharing externally. The imported dashboard is
+// returned as it is read from the storage of the resource, which only exists once the API group is installed.
+func (i *DashboardImporter) APIRoutes(resource utils.ResourceInfo, dashboards func() rest.Getter) []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: "import",
+			Spec: &spec3.PathProps{
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{resource.GroupVersionKind().Kind},
+						Summary:     "Import a dashboard exported for sharing externally",
+						Description: "The inputs declared in __inputs are replaced with the mapped data source UIDs and constants, then the dashboard is saved in the folder. Constants that are not mapped keep their exported value.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content:  jsonContent(`{"dashboard":{"__inputs":[{"name":"DS_PROMETHEUS","type":"datasource","pluginId":"prometheus"}],"title":"Node exporter"},"inputs":{"DS_PROMETHEUS":"P1809F7CD0C75ACF3"},"folderUid":"xyz","overwrite":false}`),
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									201: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The imported dashboard",
+											Content:     jsonContent(`{"kind":"Dashboard","apiVersion":"dashboard.grafana.app/v0alpha1","metadata":{"name":"abc","namespace":"default"},"spec":{"title":"Node exporter"}}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				i.handleImport(w, r, resource, dashboards())
+			},
+		},
+	}
+}
+
+func (i *DashboardImporter) handleImport(w http.ResponseWriter, r *http.Request, resource utils.ResourceInfo, dashboards rest.Getter) {
+	ctx := r.Context()
+	namespace := mux.Vars(r)["namespace"]
+	user, _, err := requireOrgNamespace(r, ErrInvalidImport)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	req := ImportRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errhttp.Write(ctx, ErrInvalidImport.Errorf("bad request data: %w", err), w)
+		return
+	}
+
+	uid, err := i.Import(ctx, user, req)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	// return the dashboard as it is read from the API
+	obj, err := dashboards.Get(k8srequest.WithNamespace(ctx, namespace), uid, &metav1.GetOptions{})
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	obj.GetObjectKind().SetGroupVersionKind(resource.GroupVersionKind())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(obj)
+}
@@ -0,0 +1,79 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// ErrInvalidLibraryPanelGC is returned when the namespace of an orphaned library panel request is invalid.
+var ErrInvalidLibraryPanelGC = errutil.BadRequest("dashboards.librarypanels.gc.invalid")
+
+// APIRoutes returns the route listing the orphaned library panel references of the dashboards, and inlining them
+func (gc *LibraryPanelGarbageCollector) APIRoutes(resource utils.ResourceInfo) []builder.APIRouteHandler {
+	tags := []string{resource.GroupVersionKind().Kind}
+	reportResponse := func(description string) *spec3.Responses {
+		return &spec3.Responses{
+			ResponsesProps: spec3.ResponsesProps{
+				StatusCodeResponses: map[int]*spec3.Response{
+					200: {
+						ResponseProps: spec3.ResponseProps{
+							Description: description,
+							Content:     jsonContent(`{"namespace":"default","dashboards":42,"orphans":[{"dashboardUid":"abc","dashboardTitle":"Overview","panelId":3,"libraryPanelUid":"lib1","libraryPanelName":"CPU"}],"inlined":["abc"]}`),
+						},
+					},
+				},
+			},
+		}
+	}
+	return []builder.APIRouteHandler{
+		{
+			Path: "librarypanels/orphans",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "List the panels of dashboards referencing deleted library panels",
+						Description: "Requires the org admin role. The dashboards of the namespace are scanned for libraryPanel references to library panels that do not exist.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						Responses:   reportResponse("The orphaned library panel references"),
+					},
+				},
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Inline the panels of dashboards referencing deleted library panels",
+						Description: "Requires the org admin role. The orphaned references are replaced with an inlined copy of the panel, or a text panel when the dashboard did not keep the model of the library panel, and the dashboards are saved.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						Responses:   reportResponse("The orphaned library panel references and the dashboards that were saved"),
+					},
+				},
+			},
+			Handler: gc.handleOrphans,
+		},
+	}
+}
+
+func (gc *LibraryPanelGarbageCollector) handleOrphans(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidLibraryPanelGC)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	report, err := gc.Collect(ctx, user, r.Method == http.MethodPost)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,104 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route moving dashboards of the resource between folders
+func (m *DashboardMover) APIRoutes(resource utils.ResourceInfo) []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: resource.GroupResource().Resource + ":move",
+			Spec: &spec3.PathProps{
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{resource.GroupVersionKind().Kind},
+						Summary:     "Move dashboards to a folder",
+						Description: "Dashboards are moved within a single transaction. If any dashboard fails, none are moved and the response is a 409 with the result of each dashboard.",
+						Parameters: []*spec3.Parameter{
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "namespace",
+									In:          "path",
+									Required:    true,
+									Example:     "default",
+									Description: "workspace",
+									Schema:      spec.StringProperty(),
+								},
+							},
+						},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content: map[string]*spec3.MediaType{
+									"application/json": {
+										MediaTypeProps: spec3.MediaTypeProps{
+											Schema:  spec.MapProperty(nil),
+											Example: `{"dashboardUids":["abc","def"],"folderUid":"xyz"}`,
+										},
+									},
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "Per dashboard results of the move",
+											Content: map[string]*spec3.MediaType{
+												"application/json": {
+													MediaTypeProps: spec3.MediaTypeProps{
+														Schema: spec.MapProperty(nil),
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: m.handleMove,
+		},
+	}
+}
+
+func (m *DashboardMover) handleMove(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidMove)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	req := MoveRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errhttp.Write(ctx, ErrInvalidMove.Errorf("bad request data: %w", err), w)
+		return
+	}
+
+	result, err := m.Move(ctx, user, req)
+	// the results of the dashboards tell which one failed
+	if err != nil && (result == nil || !errors.Is(err, ErrMoveNotApplied)) {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
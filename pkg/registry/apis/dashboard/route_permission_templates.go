@@ -0,0 +1,165 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// SetPermissionTemplateRequest replaces the permission template of a folder
+type SetPermissionTemplateRequest struct {
+	FolderUID   string                `json:"folderUid"`
+	Permissions []DashboardPermission `json:"permissions"`
+}
+
+// APIRoutes returns the route managing the permission templates of the folders. Reading a template requires the
+// folders.permissions:read permission on the folder, changing it the folders.permissions:write permission.
+func (t *PermissionTemplates) APIRoutes(resource utils.ResourceInfo, accessControl accesscontrol.AccessControl) []builder.APIRouteHandler {
+	tags := []string{resource.GroupVersionKind().Kind}
+	folderParam := &spec3.Parameter{
+		ParameterProps: spec3.ParameterProps{
+			Name:        "folderUid",
+			In:          "query",
+			Required:    true,
+			Description: "the folder of the permission template",
+			Schema:      spec.StringProperty(),
+		},
+	}
+	templateResponse := func(description string) *spec3.Responses {
+		return &spec3.Responses{
+			ResponsesProps: spec3.ResponsesProps{
+				StatusCodeResponses: map[int]*spec3.Response{
+					200: {
+						ResponseProps: spec3.ResponseProps{
+							Description: description,
+							Content:     jsonContent(`{"folderUid":"xyz","permissions":[{"teamId":2,"permission":"Edit"},{"role":"Viewer","permission":"View"}],"updatedAt":"2024-01-01T00:00:00Z","updatedBy":"user:u000000001"}`),
+						},
+					},
+				},
+			},
+		}
+	}
+	return []builder.APIRouteHandler{
+		{
+			Path: "permissiontemplates",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Get the permission template of a folder",
+						Description: "The team and role permissions given to the dashboards created in the folder through the dashboards API. Requires the folders.permissions:read permission on the folder.",
+						Parameters:  []*spec3.Parameter{namespaceParam, folderParam},
+						Responses:   templateResponse("The permission template of the folder"),
+					},
+				},
+				Put: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Set the permission template of a folder",
+						Description: "Replaces the permissions given to the dashboards created in the folder, the dashboards already in the folder keep their permissions. Every permission sets a teamId or a role, and View, Edit or Admin. Requires the folders.permissions:write permission on the folder.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: &spec3.RequestBody{
+							RequestBodyProps: spec3.RequestBodyProps{
+								Required: true,
+								Content:  jsonContent(`{"folderUid":"xyz","permissions":[{"teamId":2,"permission":"Edit"},{"role":"Viewer","permission":"View"}]}`),
+							},
+						},
+						Responses: templateResponse("The permission template of the folder"),
+					},
+				},
+				Delete: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Delete the permission template of a folder",
+						Description: "The dashboards created in the folder then only get the default permissions. Requires the folders.permissions:write permission on the folder.",
+						Parameters:  []*spec3.Parameter{namespaceParam, folderParam},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									204: {ResponseProps: spec3.ResponseProps{Description: "The permission template is deleted"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				t.handleTemplates(w, r, accessControl)
+			},
+		},
+	}
+}
+
+func (t *PermissionTemplates) handleTemplates(w http.ResponseWriter, r *http.Request, accessControl accesscontrol.AccessControl) {
+	ctx := r.Context()
+	user, info, err := requireOrgNamespace(r, ErrInvalidPermissionTemplate)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	folderUID := r.URL.Query().Get("folderUid")
+	req := SetPermissionTemplateRequest{}
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errhttp.Write(ctx, ErrInvalidPermissionTemplate.Errorf("bad request data: %w", err), w)
+			return
+		}
+		folderUID = req.FolderUID
+	}
+	if folderUID == "" {
+		errhttp.Write(ctx, ErrInvalidPermissionTemplate.Errorf("missing folderUid"), w)
+		return
+	}
+	action := dashboards.ActionFoldersPermissionsWrite
+	if r.Method == http.MethodGet {
+		action = dashboards.ActionFoldersPermissionsRead
+	}
+	if err := t.authorize(r, user, accessControl, action, folderUID); err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	var template *FolderPermissionTemplate
+	switch r.Method {
+	case http.MethodPut:
+		template, err = t.Set(ctx, user, folderUID, req.Permissions)
+	case http.MethodDelete:
+		if err := t.Delete(ctx, user, folderUID); err != nil {
+			errhttp.Write(ctx, err, w)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		template, err = t.Get(ctx, info.OrgID, folderUID)
+	}
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(template)
+}
+
+func (t *PermissionTemplates) authorize(r *http.Request, user identity.Requester, accessControl accesscontrol.AccessControl, action string, folderUID string) error {
+	scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID)
+	allowed, err := accessControl.Evaluate(r.Context(), user, accesscontrol.EvalPermission(action, scope))
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrPermissionTemplateAccessDenied.Errorf("missing permission %s on %s", action, scope)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route reporting the usage of the dashboard quotas, readable with the orgs.quotas:read permission
+func (g *QuotaGuard) APIRoutes(resource utils.ResourceInfo, accessControl accesscontrol.AccessControl) []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: "quota",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{resource.GroupVersionKind().Kind},
+						Summary:     "Get the usage of the dashboard quotas",
+						Description: "The global and org quotas, and the quota of a folder. Dashboards without a folder count in the General folder. Requires the orgs.quotas:read permission.",
+						Parameters: []*spec3.Parameter{
+							namespaceParam,
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "folderUid",
+									In:          "query",
+									Description: "the folder to get the quota of, the General folder when empty",
+									Schema:      spec.StringProperty(),
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "Quota limits and usage, a negative limit is unlimited",
+											Content:     jsonContent(`{"enabled":true,"quotas":[{"scope":"org","limit":100,"used":12},{"scope":"folder","folderUid":"xyz","limit":-1,"used":3}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				g.handleStatus(w, r, accessControl)
+			},
+		},
+	}
+}
+
+func (g *QuotaGuard) handleStatus(w http.ResponseWriter, r *http.Request, accessControl accesscontrol.AccessControl) {
+	ctx := r.Context()
+	user, info, err := requireOrgNamespace(r, ErrInvalidQuotaRequest)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	allowed, err := accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(accesscontrol.ActionOrgsQuotasRead))
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	if !allowed {
+		errhttp.Write(ctx, ErrQuotaAccessDenied.Errorf("missing permission %s", accesscontrol.ActionOrgsQuotasRead), w)
+		return
+	}
+
+	status, err := g.Status(ctx, user, info.OrgID, r.URL.Query().Get("folderUid"))
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,121 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route resolving the legacy links of dashboards to the name of the dashboard resource
+func (l *LinkResolver) APIRoutes(resource utils.ResourceInfo) []builder.APIRouteHandler {
+	tags := []string{resource.GroupVersionKind().Kind}
+	queryParam := func(name string, description string, example string) *spec3.Parameter {
+		return &spec3.Parameter{
+			ParameterProps: spec3.ParameterProps{
+				Name:        name,
+				In:          "query",
+				Description: description,
+				Example:     example,
+				Schema:      spec.StringProperty(),
+			},
+		}
+	}
+	parameters := []*spec3.Parameter{
+		namespaceParam,
+		queryParam("id", "the numeric id of the dashboard", "12"),
+		queryParam("slug", "the slug of a /dashboard/db/:slug link", "overview"),
+		queryParam("shortUrl", "the uid of a /goto/:uid short URL", "AbCdEfGh"),
+	}
+	responses := &spec3.Responses{
+		ResponsesProps: spec3.ResponsesProps{
+			StatusCodeResponses: map[int]*spec3.Response{
+				200: {
+					ResponseProps: spec3.ResponseProps{
+						Description: "The dashboard the link moved to",
+						Content:     jsonContent(`{"status":301,"name":"xyz","url":"/d/xyz/overview?from=now-6h","apiPath":"/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/xyz"}`),
+					},
+				},
+			},
+		},
+	}
+	return []builder.APIRouteHandler{
+		{
+			Path: "resolve",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Resolve a legacy dashboard link",
+						Description: "Maps the numeric id, the slug or the short URL of a legacy link to the name of the dashboard, with its canonical url and resource path. Exactly one of id, slug and shortUrl is expected.",
+						Parameters:  parameters,
+						Responses:   responses,
+					},
+				},
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Follow a legacy dashboard link",
+						Description: "Resolves the link like GET, and marks a short URL as seen, as when it is followed, so it is not deleted as stale.",
+						Parameters:  parameters,
+						Responses:   responses,
+					},
+				},
+			},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				l.handleResolve(w, r, resource)
+			},
+		},
+	}
+}
+
+func (l *LinkResolver) handleResolve(w http.ResponseWriter, r *http.Request, resource utils.ResourceInfo) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidResolve)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	query := r.URL.Query()
+	set := 0
+	for _, key := range []string{"id", "slug", "shortUrl"} {
+		if query.Get(key) != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		errhttp.Write(ctx, ErrInvalidResolve.Errorf("expected exactly one of id, slug and shortUrl"), w)
+		return
+	}
+
+	var link *ResolvedLink
+	switch {
+	case query.Get("id") != "":
+		var id int64
+		if id, err = parseResolveID(query.Get("id")); err == nil {
+			link, err = l.ResolveID(ctx, user, id)
+		}
+	case query.Get("slug") != "":
+		link, err = l.ResolveSlug(ctx, user, query.Get("slug"))
+	default:
+		link, err = l.ResolveShortURL(ctx, user, query.Get("shortUrl"), r.Method == http.MethodPost)
+	}
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	gvr := resource.GroupVersionResource()
+	link.APIPath = path.Join("/apis", gvr.Group, gvr.Version, "namespaces", mux.Vars(r)["namespace"], gvr.Resource, link.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(link)
+}
@@ -0,0 +1,81 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route completing the title prefixes of dashboards and folders
+func (a *SearchAutocomplete) APIRoutes() []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: "search/autocomplete",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{"Search"},
+						Summary:     "Complete the title prefix of dashboards and folders",
+						Description: "Returns the names and titles of the best matches only, for suggestions while typing. Use the search for the full results.",
+						Parameters: []*spec3.Parameter{
+							namespaceParam,
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "prefix",
+									In:          "query",
+									Description: "the typed text, the last word may be incomplete",
+									Example:     "cpu us",
+									Schema:      spec.StringProperty(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "limit",
+									In:          "query",
+									Description: "number of completions, at most 50",
+									Example:     autocompleteDefaultLimit,
+									Schema:      spec.Int64Property(),
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The completions, best match first",
+											Content:     jsonContent(`{"completions":[{"name":"abc","title":"CPU usage","kind":"Dashboard"}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: a.handleComplete,
+		},
+	}
+}
+
+func (a *SearchAutocomplete) handleComplete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidAutocomplete)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	list, err := a.Complete(ctx, user, r.URL.Query())
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
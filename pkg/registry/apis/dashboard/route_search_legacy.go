@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the route searching with the parameters and the hits of /api/search
+func (l *LegacySearch) APIRoutes() []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: "legacy/search",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{"Search"},
+						Summary:     "Search dashboards and folders with the parameters of /api/search",
+						Description: "Returns the legacy hit list, for clients moving from /api/search. The hits in a folder have the path of the folder from the root folder. Searching by id, in deleted dashboards or for editable dashboards is not supported.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The dashboards and folders found",
+											Content:     jsonContent(`[{"uid":"abc","title":"CPU","uri":"db/cpu","url":"/d/abc/cpu","type":"dash-db","tags":["prod"],"isStarred":false,"folderUid":"xyz","folderTitle":"Hosts","folderUrl":"/dashboards/f/xyz/hosts","folderPath":[{"uid":"dc","title":"Datacenter"},{"uid":"xyz","title":"Hosts"}]}]`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: l.handleSearch,
+		},
+	}
+}
+
+func (l *LegacySearch) handleSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidLegacySearch)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	hits, err := l.Search(ctx, user, r.URL.Query())
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(hits)
+}
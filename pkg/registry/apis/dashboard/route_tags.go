@@ -0,0 +1,149 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the routes listing, renaming and merging the tags of the dashboards
+func (m *TagManager) APIRoutes() []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: "tags",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{"Tags"},
+						Summary:     "List the tags used by dashboards",
+						Description: "Tags are listed with the number of dashboards using them, most used first.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "Tags with usage counts",
+											Content:     jsonContent(`{"tags":[{"tag":"prod","count":12}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: m.handleList,
+		},
+		{
+			Path: "tags/rename",
+			Spec: &spec3.PathProps{
+				Post: tagUpdate("Rename a tag on all dashboards",
+					"Requires the org admin role. Fails when the new tag is already used, merge the tags instead. Dashboards are updated in a single transaction, it is rejected while dashboards are stored in unified storage.",
+					`{"from":"production","to":"prod"}`),
+			},
+			Handler: m.handleRename,
+		},
+		{
+			Path: "tags/merge",
+			Spec: &spec3.PathProps{
+				Post: tagUpdate("Merge a tag into another one on all dashboards",
+					"Requires the org admin role. Dashboards using both tags keep a single copy of the target tag. Dashboards are updated in a single transaction, it is rejected while dashboards are stored in unified storage.",
+					`{"source":"production","target":"prod"}`),
+			},
+			Handler: m.handleMerge,
+		},
+	}
+}
+
+// tagUpdate is the operation of a route updating a tag on all dashboards
+func tagUpdate(summary string, description string, example string) *spec3.Operation {
+	return &spec3.Operation{
+		OperationProps: spec3.OperationProps{
+			Tags:        []string{"Tags"},
+			Summary:     summary,
+			Description: description,
+			Parameters:  []*spec3.Parameter{namespaceParam},
+			RequestBody: &spec3.RequestBody{
+				RequestBodyProps: spec3.RequestBodyProps{
+					Required: true,
+					Content:  jsonContent(example),
+				},
+			},
+			Responses: &spec3.Responses{
+				ResponsesProps: spec3.ResponsesProps{
+					StatusCodeResponses: map[int]*spec3.Response{
+						200: {
+							ResponseProps: spec3.ResponseProps{
+								Description: "The UIDs of the updated dashboards",
+								Content:     jsonContent(`{"dashboards":["abc"]}`),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (m *TagManager) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidTagCommand)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	list, err := m.List(ctx, user)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+func (m *TagManager) handleRename(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidTagCommand)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	cmd := RenameTagCommand{}
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		errhttp.Write(ctx, ErrInvalidTagCommand.Errorf("bad request data: %w", err), w)
+		return
+	}
+	result, err := m.Rename(ctx, user, cmd)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (m *TagManager) handleMerge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, _, err := requireOrgNamespace(r, ErrInvalidTagCommand)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	cmd := MergeTagsCommand{}
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		errhttp.Write(ctx, ErrInvalidTagCommand.Errorf("bad request data: %w", err), w)
+		return
+	}
+	result, err := m.Merge(ctx, user, cmd)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,109 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+var (
+	ErrInvalidTombstonesRequest = errutil.BadRequest("dashboards.tombstones.invalid")
+	ErrTombstonesAccessDenied   = errutil.Forbidden("dashboards.tombstones.forbidden", errutil.WithPublicMessage("You are not allowed to list the deleted dashboards"))
+	ErrTombstonesDisabled       = errutil.NotFound("dashboards.tombstones.disabled", errutil.WithPublicMessage("Deleted dashboards are not recorded, [dashboards] tombstone_retention is 0"))
+)
+
+// APIRoutes returns the route listing the dashboards deleted in the retention window, readable by the users who can
+// read all the dashboards of the org
+func (s *TombstoneStore) APIRoutes(resource utils.ResourceInfo, accessControl accesscontrol.AccessControl) []builder.APIRouteHandler {
+	return []builder.APIRouteHandler{
+		{
+			Path: "deleted",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{resource.GroupVersionKind().Kind},
+						Summary:     "List the deleted dashboards",
+						Description: "The dashboards deleted through the dashboards API since a time, oldest first, with the time they were deleted and the user who deleted them. Deletions are kept for [dashboards] tombstone_retention, a client that last synced before retainedSince must list all the dashboards again. Requires the dashboards:read permission on all dashboards.",
+						Parameters: []*spec3.Parameter{
+							namespaceParam,
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "since",
+									In:          "query",
+									Description: "only list the dashboards deleted after this time, RFC 3339",
+									Example:     "2024-01-01T00:00:00Z",
+									Schema:      spec.DateTimeProperty(),
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The deleted dashboards",
+											Content:     jsonContent(`{"namespace":"default","retainedSince":"2024-01-01T00:00:00Z","items":[{"uid":"abc","title":"Overview","deletedAt":"2024-01-03T10:00:00Z","deletedBy":"user:u000000001"}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				s.handleList(w, r, accessControl)
+			},
+		},
+	}
+}
+
+func (s *TombstoneStore) handleList(w http.ResponseWriter, r *http.Request, accessControl accesscontrol.AccessControl) {
+	ctx := r.Context()
+	user, info, err := requireOrgNamespace(r, ErrInvalidTombstonesRequest)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	if !s.Enabled() {
+		errhttp.Write(ctx, ErrTombstonesDisabled.Errorf("tombstones are disabled"), w)
+		return
+	}
+	allowed, err := accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsAll))
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	if !allowed {
+		errhttp.Write(ctx, ErrTombstonesAccessDenied.Errorf("missing permission %s on %s", dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsAll), w)
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			errhttp.Write(ctx, ErrInvalidTombstonesRequest.Errorf("invalid since %q, expected an RFC 3339 time", v), w)
+			return
+		}
+	}
+
+	list, err := s.List(ctx, info.OrgID, since)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	list.Namespace = info.Value
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
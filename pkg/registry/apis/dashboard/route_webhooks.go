@@ -0,0 +1,229 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+)
+
+// APIRoutes returns the routes managing the webhooks of the namespace and listing their recent deliveries, they
+// require the orgs:write permission. The path, query and credentials of the URLs are redacted in the responses as
+// they often hold the token authenticating the requests.
+func (w *DashboardWebhooks) APIRoutes(resource utils.ResourceInfo, accessControl accesscontrol.AccessControl) []builder.APIRouteHandler {
+	tags := []string{resource.GroupVersionKind().Kind}
+	uidParam := func(description string) *spec3.Parameter {
+		return &spec3.Parameter{
+			ParameterProps: spec3.ParameterProps{
+				Name:        "uid",
+				In:          "query",
+				Required:    true,
+				Description: description,
+				Schema:      spec.StringProperty(),
+			},
+		}
+	}
+	webhookResponse := &spec3.Responses{
+		ResponsesProps: spec3.ResponsesProps{
+			StatusCodeResponses: map[int]*spec3.Response{
+				200: {
+					ResponseProps: spec3.ResponseProps{
+						Description: "The webhook",
+						Content:     jsonContent(`{"uid":"a1b2c3","url":"https://example.com/***","events":["create","update"],"hasSecret":true,"updatedAt":"2024-01-01T00:00:00Z","updatedBy":"user:u000000001"}`),
+					},
+				},
+			},
+		},
+	}
+	webhookRequest := &spec3.RequestBody{
+		RequestBodyProps: spec3.RequestBodyProps{
+			Required: true,
+			Content:  jsonContent(`{"url":"https://example.com/hooks/grafana","secret":"s3cr3t","events":["create","update"]}`),
+		},
+	}
+	return []builder.APIRouteHandler{
+		{
+			Path: "webhooks",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "List the dashboard webhooks",
+						Description: "The webhooks receiving a POST request for every dashboard created, updated, deleted or restored through the dashboards API, with a summary of the changes of the updates. The path, query and credentials of the URLs are redacted. Requires the orgs:write permission.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The webhooks of the namespace",
+											Content:     jsonContent(`{"items":[{"uid":"a1b2c3","url":"https://example.com/***","hasSecret":true,"updatedAt":"2024-01-01T00:00:00Z"}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Post: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Create a dashboard webhook",
+						Description: "Without events, the webhook receives all of create, update, delete and restore. With a secret, the requests are signed with the X-Grafana-Signature header, sha256= and the hex encoded HMAC-SHA256 of the body. The requests are never sent to private, loopback or link-local addresses. Failed requests are retried twice on network errors, 429 and 5xx responses. Requires the orgs:write permission.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: webhookRequest,
+						Responses:   webhookResponse,
+					},
+				},
+				Put: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Replace a dashboard webhook",
+						Description: "Replaces the webhook with the uid of the body, the secret is kept when it is not set and removed when it is empty. Requires the orgs:write permission.",
+						Parameters:  []*spec3.Parameter{namespaceParam},
+						RequestBody: webhookRequest,
+						Responses:   webhookResponse,
+					},
+				},
+				Delete: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "Delete a dashboard webhook",
+						Description: "Deletes the webhook and its deliveries. Requires the orgs:write permission.",
+						Parameters:  []*spec3.Parameter{namespaceParam, uidParam("the webhook to delete")},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									204: {ResponseProps: spec3.ResponseProps{Description: "The webhook is deleted"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: func(rw http.ResponseWriter, r *http.Request) {
+				w.handleWebhooks(rw, r, accessControl)
+			},
+		},
+		{
+			Path: "webhooks/deliveries",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        tags,
+						Summary:     "List the deliveries of a dashboard webhook",
+						Description: "The recent deliveries of the webhook, newest first, with their status, the number of attempts and the status code or error of the last attempt. Requires the orgs:write permission.",
+						Parameters:  []*spec3.Parameter{namespaceParam, uidParam("the webhook")},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Description: "The recent deliveries of the webhook",
+											Content:     jsonContent(`{"items":[{"id":"d4e5f6","event":"update","dashboardUid":"abc","status":"failed","attempts":3,"statusCode":503,"error":"unexpected response status 503","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:35Z"}]}`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: func(rw http.ResponseWriter, r *http.Request) {
+				w.handleDeliveries(rw, r, accessControl)
+			},
+		},
+	}
+}
+
+func (w *DashboardWebhooks) handleWebhooks(rw http.ResponseWriter, r *http.Request, accessControl accesscontrol.AccessControl) {
+	ctx := r.Context()
+	user, info, err := requireOrgNamespace(r, ErrInvalidWebhook)
+	if err != nil {
+		errhttp.Write(ctx, err, rw)
+		return
+	}
+	allowed, err := accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(accesscontrol.ActionOrgsWrite))
+	if err != nil {
+		errhttp.Write(ctx, err, rw)
+		return
+	}
+	if !allowed {
+		errhttp.Write(ctx, ErrWebhookAccessDenied.Errorf("missing permission %s", accesscontrol.ActionOrgsWrite), rw)
+		return
+	}
+
+	var result any
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		req := DashboardWebhookRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errhttp.Write(ctx, ErrInvalidWebhook.Errorf("bad request data: %w", err), rw)
+			return
+		}
+		if r.Method == http.MethodPut && req.UID == "" {
+			errhttp.Write(ctx, ErrInvalidWebhook.Errorf("missing uid"), rw)
+			return
+		}
+		if r.Method == http.MethodPost {
+			req.UID = ""
+		}
+		var hook *DashboardWebhook
+		if hook, err = w.Save(ctx, user, req); err == nil {
+			hook.URL = redactWebhookURL(hook.URL)
+		}
+		result = hook
+	case http.MethodDelete:
+		if err := w.Delete(ctx, user, r.URL.Query().Get("uid")); err != nil {
+			errhttp.Write(ctx, err, rw)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		var items []DashboardWebhook
+		items, err = w.List(ctx, info.OrgID)
+		for i := range items {
+			items[i].URL = redactWebhookURL(items[i].URL)
+		}
+		result = map[string]any{"items": items}
+	}
+	if err != nil {
+		errhttp.Write(ctx, err, rw)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(result)
+}
+
+func (w *DashboardWebhooks) handleDeliveries(rw http.ResponseWriter, r *http.Request, accessControl accesscontrol.AccessControl) {
+	ctx := r.Context()
+	user, info, err := requireOrgNamespace(r, ErrInvalidWebhook)
+	if err != nil {
+		errhttp.Write(ctx, err, rw)
+		return
+	}
+	allowed, err := accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(accesscontrol.ActionOrgsWrite))
+	if err != nil {
+		errhttp.Write(ctx, err, rw)
+		return
+	}
+	if !allowed {
+		errhttp.Write(ctx, ErrWebhookAccessDenied.Errorf("missing permission %s", accesscontrol.ActionOrgsWrite), rw)
+		return
+	}
+
+	deliveries, err := w.Deliveries(ctx, info.OrgID, r.URL.Query().Get("uid"))
+	if err != nil {
+		errhttp.Write(ctx, err, rw)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(map[string]any{"items": deliveries})
+}
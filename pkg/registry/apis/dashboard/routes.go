@@ -0,0 +1,54 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+// namespaceParam is the path parameter of the namespaced routes
+var namespaceParam = &spec3.Parameter{
+	ParameterProps: spec3.ParameterProps{
+		Name:        "namespace",
+		In:          "path",
+		Required:    true,
+		Example:     "default",
+		Description: "workspace",
+		Schema:      spec.StringProperty(),
+	},
+}
+
+// jsonContent is the JSON content of a route request or response, described by an example
+func jsonContent(example string) map[string]*spec3.MediaType {
+	return map[string]*spec3.MediaType{
+		"application/json": {
+			MediaTypeProps: spec3.MediaTypeProps{
+				Schema:  spec.MapProperty(nil),
+				Example: example,
+			},
+		},
+	}
+}
+
+// requireOrgNamespace returns the user of a request to a namespaced route and the org of the namespace, after checking
+// the user belongs to it. The namespace errors are invalid, the bad request error of the route.
+func requireOrgNamespace(r *http.Request, invalid errutil.Base) (identity.Requester, claims.NamespaceInfo, error) {
+	user, err := identity.GetRequester(r.Context())
+	if err != nil {
+		return nil, claims.NamespaceInfo{}, err
+	}
+	info, err := claims.ParseNamespace(mux.Vars(r)["namespace"])
+	if err != nil {
+		return nil, claims.NamespaceInfo{}, invalid.Errorf("expected namespace: %w", err)
+	}
+	if info.OrgID != user.GetOrgID() {
+		return nil, claims.NamespaceInfo{}, invalid.Errorf("user orgId does not match namespace (%d != %d)", info.OrgID, user.GetOrgID())
+	}
+	return user, info, nil
+}
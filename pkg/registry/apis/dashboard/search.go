@@ -3,34 +3,86 @@ package dashboard
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/registry/rest"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/util"
 )
 
+const (
+	// maxRecentDashboards is the maximum number of recently viewed dashboards that can be sent with a search
+	maxRecentDashboards = 50
+	// starredBoost is added to the score of starred dashboards
+	starredBoost = 5
+	// maxSearchFolders is the maximum number of folders a recursive search can be limited to
+	maxSearchFolders = 1000
+	// maxFuzziness is the largest edit distance of a typo-tolerant search supported by the index
+	maxFuzziness = 2
+)
+
+// searchTypes are the kinds of the values of the types parameter, with the hit types of the legacy search
+var searchTypes = map[string]string{
+	"dash":                      "Dashboard",
+	string(model.DashHitDB):     "Dashboard",
+	"folder":                    "Folder",
+	string(model.DashHitFolder): "Folder",
+}
+
 // The DTO returns everything the UI needs in a single request
 type SearchConnector struct {
 	newFunc func() runtime.Object
 	client  resource.ResourceIndexClient
+	stars   star.Service
+	folders folder.Service
+	limiter *NamespaceRateLimiter
 	log     log.Logger
+
+	metrics *searchMetrics
+	// slowThreshold is the duration above which searches are logged, 0 disables the log
+	slowThreshold time.Duration
 }
 
 func NewSearchConnector(
 	client resource.ResourceIndexClient,
+	stars star.Service,
+	folders folder.Service,
+	limiter *NamespaceRateLimiter,
+	slowThreshold time.Duration,
+	reg prometheus.Registerer,
 	newFunc func() runtime.Object,
 ) (rest.Storage, error) {
 	v := &SearchConnector{
-		client:  client,
-		newFunc: newFunc,
-		log:     log.New("grafana-apiserver.dashboards.search"),
+		client:        client,
+		stars:         stars,
+		folders:       folders,
+		limiter:       limiter,
+		newFunc:       newFunc,
+		log:           log.New("grafana-apiserver.dashboards.search"),
+		metrics:       newSearchMetrics(reg),
+		slowThreshold: slowThreshold,
 	}
 	return v, nil
 }
@@ -78,6 +130,13 @@ func (s *SearchConnector) Connect(ctx context.Context, name string, opts runtime
 	if err != nil {
 		return nil, err
 	}
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limiter.Allow(info.Value, RateLimitSearch); err != nil {
+		return nil, err
+	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		queryParams, err := url.ParseQuery(r.URL.RawQuery)
@@ -86,31 +145,28 @@ func (s *SearchConnector) Connect(ctx context.Context, name string, opts runtime
 			return
 		}
 
-		// get limit and offset from query params
-		limit := 0
-		offset := 0
-		if queryParams.Has("limit") {
-			limit, _ = strconv.Atoi(queryParams.Get("limit"))
-		}
-		if queryParams.Has("offset") {
-			offset, _ = strconv.Atoi(queryParams.Get("offset"))
+		searchRequest, _, err := s.searchRequest(r.Context(), user, queryParams)
+		if err != nil {
+			responder.Error(err)
+			return
 		}
-
-		searchRequest := &resource.SearchRequest{
-			Tenant:    user.GetNamespace(), //<< not necessary it is in the namespace (and user context)
-			Kind:      strings.Split(queryParams.Get("kind"), ","),
-			QueryType: queryParams.Get("queryType"),
-			Query:     queryParams.Get("query"),
-			Limit:     int64(limit),
-			Offset:    int64(offset),
+		if searchRequest == nil {
+			// nothing can match, e.g. the user has not starred any dashboard yet
+			_, _ = w.Write([]byte("{}"))
+			return
 		}
 
-		// TODO... actually query
-		result, err := s.client.Search(r.Context(), searchRequest)
+		result, err := s.search(r.Context(), info.Value, searchRequest)
 		if err != nil {
 			responder.Error(err)
 			return
 		}
+		if queryParams.Has("types") {
+			if err := typedResults(result); err != nil {
+				responder.Error(err)
+				return
+			}
+		}
 
 		jj, err := json.Marshal(result)
 		if err != nil {
@@ -120,3 +176,372 @@ func (s *SearchConnector) Connect(ctx context.Context, name string, opts runtime
 		_, _ = w.Write(jj)
 	}), nil
 }
+
+// searchRequest builds the search of the query parameters for the user, the filters are added as required clauses.
+// The request is nil when nothing can match.
+func (s *SearchConnector) searchRequest(ctx context.Context, user identity.Requester, queryParams url.Values, filters ...string) (*resource.SearchRequest, *searchSignals, error) {
+	// get limit and offset from query params
+	limit := 0
+	offset := 0
+	if queryParams.Has("limit") {
+		limit, _ = strconv.Atoi(queryParams.Get("limit"))
+	}
+	if queryParams.Has("offset") {
+		offset, _ = strconv.Atoi(queryParams.Get("offset"))
+	}
+
+	kinds, err := searchKinds(queryParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	fuzziness, err := searchFuzziness(queryParams.Get("fuzziness"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sortBy, err := searchSort(queryParams.Get("sort"))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	text, err := parseSearchQuery(queryParams.Get("query"), fuzziness, now)
+	if err != nil {
+		return nil, nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	signals, err := s.userSignals(ctx, user, queryParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	if signals.isEmptyFilter() {
+		return nil, signals, nil
+	}
+
+	folderFilter, err := s.folderFilter(ctx, user, queryParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	if folderFilter != "" {
+		filters = append(filters, folderFilter)
+	}
+	for _, f := range queryParams["filter"] {
+		clause, err := fieldFilter(f, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		filters = append(filters, clause)
+	}
+	permissions, none, err := s.permissionQuery(ctx, user)
+	if errors.Is(err, errTooManySearchFolders) {
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("the dashboards of more than %d folders can be read, search in a folder instead", maxSearchFolders))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if none {
+		return nil, signals, nil
+	}
+
+	req := &resource.SearchRequest{
+		Tenant: user.GetNamespace(), //<< not necessary it is in the namespace (and user context)
+		Kind:   kinds,
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	}
+	if len(kinds) > 1 && queryParams.Has("types") {
+		// as in the legacy search, the folders are returned before the dashboards
+		req.SortBy = []string{"-Kind"}
+	}
+	if req.SortBy = append(req.SortBy, sortBy...); len(req.SortBy) > 0 {
+		req.SortBy = append(req.SortBy, "-_score")
+	}
+	// the results the user can not read are filtered in the index, so the pages and the total are accurate
+	q := signals.query(text, filters...)
+	if permissions != nil {
+		if q == nil {
+			q = permissions
+		} else {
+			q = bleve.NewConjunctionQuery(q, permissions)
+		}
+	}
+	// the query is sent to the index as JSON, the query string of the index can not express the query syntax
+	if q != nil {
+		body, err := json.Marshal(q)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Query, req.QueryType = string(body), resource.QueryTypeJSON
+	}
+	return req, signals, nil
+}
+
+// searchKinds reads the kinds of resources to search, in a single request to the index:
+//
+//	kind=Dashboard,Folder  the kinds of the resources
+//	types=dash,folder      the types of the legacy search, dash-db and dash-folder are also accepted
+func searchKinds(params url.Values) ([]string, error) {
+	if !params.Has("types") {
+		return strings.Split(params.Get("kind"), ","), nil
+	}
+	if params.Has("kind") {
+		return nil, apierrors.NewBadRequest("kind and types can not be used together")
+	}
+	kinds := []string{}
+	for _, t := range strings.Split(params.Get("types"), ",") {
+		kind, ok := searchTypes[strings.TrimSpace(t)]
+		if !ok {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("unsupported type %q, expected dash or folder", t))
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// searchSort reads the order of the results, they are ordered by relevance when it is not set:
+//
+//	sort=-views_last_30d        the most viewed dashboards of the last 30 days first
+//	sort=last_viewed_at         the dashboards viewed the longest time ago first
+//	sort=-panels,updated        the dashboards with the most panels first, the least recently updated first
+//
+// The results can be ordered by the updated and created dates and by the numeric and date indexed fields, in
+// descending order with a - prefix. The results without a value are last, the ties are ordered by relevance.
+func searchSort(param string) ([]string, error) {
+	if param == "" {
+		return nil, nil
+	}
+	sortBy := []string{}
+	for _, s := range strings.Split(param, ",") {
+		name, descending := strings.CutPrefix(strings.TrimSpace(s), "-")
+		field, fieldType := searchRangeField(name)
+		switch fieldType {
+		case "int", "int64", "float64", "time":
+		case "":
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid sort %q, %s is not an indexed field", param, name))
+		default:
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid sort %q, %s can not be sorted", param, name))
+		}
+		if descending {
+			field = "-" + field
+		}
+		sortBy = append(sortBy, field)
+	}
+	return sortBy, nil
+}
+
+// typedResults adds the type of the legacy search to the results, so the dashboards and folders of a search of
+// several types can be told apart as in the legacy search
+func typedResults(res *resource.SearchResponse) error {
+	for _, item := range res.Items {
+		r := map[string]any{}
+		if err := json.Unmarshal(item.Value, &r); err != nil {
+			return fmt.Errorf("failed to read search result: %w", err)
+		}
+		r["type"] = model.DashHitDB
+		if r["Kind"] == "Folder" {
+			r["type"] = model.DashHitFolder
+		}
+		value, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		item.Value = value
+	}
+	return nil
+}
+
+// searchSignals are the per user signals that are joined into a search.
+// The views the server records are shared by all users, the recently viewed dashboards of the user are tracked
+// by the frontend and sent with the request.
+type searchSignals struct {
+	// starred are the UIDs of the dashboards starred by the user
+	starred []string
+	// recent are the UIDs of recently viewed dashboards, most recent first
+	recent []string
+
+	starredOnly bool
+	recentOnly  bool
+}
+
+// userSignals reads the per user search parameters:
+//
+//	starred=true      only return dashboards starred by the user
+//	recent=uid1,uid2  recently viewed dashboards, most recent first, they are ranked higher
+//	recentOnly=true   only return the recently viewed dashboards
+//
+// Starred dashboards are always ranked higher than the other results.
+func (s *SearchConnector) userSignals(ctx context.Context, user identity.Requester, params url.Values) (*searchSignals, error) {
+	signals := &searchSignals{
+		starredOnly: params.Get("starred") == "true",
+		recentOnly:  params.Get("recentOnly") == "true",
+	}
+
+	if v := params.Get("recent"); v != "" {
+		signals.recent = strings.Split(v, ",")
+	}
+	if len(signals.recent) > maxRecentDashboards {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("at most %d recent dashboards can be sent", maxRecentDashboards))
+	}
+	for _, uid := range signals.recent {
+		if !util.IsValidShortUID(uid) {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid recent dashboard uid %q", uid))
+		}
+	}
+
+	// anonymous and other identities can not star dashboards
+	userID, err := identity.UserIdentifier(user.GetID())
+	if err != nil || s.stars == nil {
+		return signals, nil
+	}
+	res, err := s.stars.GetByUser(ctx, &star.GetUserStarsQuery{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for uid, starred := range res.UserStars {
+		if starred {
+			signals.starred = append(signals.starred, uid)
+		}
+	}
+	sort.Strings(signals.starred)
+	return signals, nil
+}
+
+// isEmptyFilter is true when the results are restricted to an empty set of dashboards
+func (s *searchSignals) isEmptyFilter() bool {
+	return (s.starredOnly && len(s.starred) == 0) || (s.recentOnly && len(s.recent) == 0)
+}
+
+// query joins the signals and the other filters with the text query of the user. Filters are required clauses,
+// and the starred and recent dashboards are optional clauses that only add to the score.
+func (s *searchSignals) query(text query.Query, filters ...string) query.Query {
+	if len(filters) == 0 && !s.starredOnly && !s.recentOnly && len(s.starred) == 0 && len(s.recent) == 0 {
+		return text
+	}
+
+	clauses := []query.Query{}
+	if text != nil {
+		clauses = append(clauses, text)
+	}
+	for _, filter := range filters {
+		clauses = append(clauses, bleve.NewQueryStringQuery(filter))
+	}
+	if s.starredOnly {
+		clauses = append(clauses, bleve.NewQueryStringQuery(termClauses("Name", s.starred, nil)))
+	}
+	if s.recentOnly {
+		clauses = append(clauses, bleve.NewQueryStringQuery(termClauses("Name", s.recent, nil)))
+	}
+	if len(clauses) == 0 {
+		// no text or filter, nothing to rank
+		return text
+	}
+
+	q := bleve.NewBooleanQuery()
+	q.AddMust(clauses...)
+	if len(s.starred) > 0 && !s.starredOnly {
+		q.AddShould(bleve.NewQueryStringQuery(termClauses("Name", s.starred, func(int) int { return starredBoost })))
+	}
+	if len(s.recent) > 0 {
+		// the most recently viewed dashboard is ranked first
+		q.AddShould(bleve.NewQueryStringQuery(termClauses("Name", s.recent, func(i int) int { return len(s.recent) - i })))
+	}
+	return q
+}
+
+var termEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// termClauses matches any of the uids in the field, e.g. the dashboard names, optionally with a boost per uid
+func termClauses(field string, uids []string, boost func(i int) int) string {
+	parts := make([]string, 0, len(uids))
+	for i, uid := range uids {
+		clause := field + `:"` + termEscaper.Replace(uid) + `"`
+		if boost != nil {
+			clause += "^" + strconv.Itoa(boost(i))
+		}
+		parts = append(parts, clause)
+	}
+	return strings.Join(parts, " ")
+}
+
+// folderFilter reads the folder the search is limited to:
+//
+//	folder=uid                 only return the dashboards and folders directly in the folder
+//	folder=uid&recursive=true  also return the ones in all its subfolders
+//
+// The general folder is the root of the tree. The user must be able to view the folder,
+// and only the subfolders the user can view are searched.
+func (s *SearchConnector) folderFilter(ctx context.Context, user identity.Requester, params url.Values) (string, error) {
+	uid := params.Get("folder")
+	if uid == "" {
+		return "", nil
+	}
+	recursive := params.Get("recursive") == "true"
+
+	if uid == folder.GeneralFolderUID {
+		if recursive {
+			return "", nil // everything is in the root folder
+		}
+		// dashboards in the root have no folder
+		return "-FolderId:/.+/", nil
+	}
+
+	if s.folders == nil {
+		return "", apierrors.NewBadRequest("searching in folders is not supported")
+	}
+	f, err := s.folders.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound) {
+			return "", apierrors.NewBadRequest(fmt.Sprintf("folder %q not found", uid))
+		}
+		if errors.Is(err, dashboards.ErrFolderAccessDenied) {
+			return "", apierrors.NewForbidden(folderv0alpha1.FolderResourceInfo.GroupResource(), uid, err)
+		}
+		return "", err
+	}
+
+	uids := []string{f.UID}
+	if recursive {
+		uids, err = s.folderSubtree(ctx, user, f.UID)
+		if errors.Is(err, errTooManySearchFolders) {
+			return "", apierrors.NewBadRequest(fmt.Sprintf("the folder has more than %d subfolders, search in a subfolder instead", maxSearchFolders))
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return termClauses("FolderId", uids, nil), nil
+}
+
+var errTooManySearchFolders = fmt.Errorf("more than %d folders", maxSearchFolders)
+
+// folderSubtree returns the folders and all their descendants the user can view, breadth first
+func (s *SearchConnector) folderSubtree(ctx context.Context, user identity.Requester, roots ...string) ([]string, error) {
+	uids := []string{}
+	seen := map[string]bool{}
+	for _, uid := range roots {
+		if !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+	for i := 0; i < len(uids); i++ {
+		children, err := s.folders.GetChildren(ctx, &folder.GetChildrenQuery{UID: uids[i], OrgID: user.GetOrgID(), SignedInUser: user})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderAccessDenied) {
+				continue
+			}
+			return nil, err
+		}
+		for _, child := range children {
+			if seen[child.UID] {
+				continue
+			}
+			seen[child.UID] = true
+			uids = append(uids, child.UID)
+		}
+		if len(uids) > maxSearchFolders {
+			return nil, errTooManySearchFolders
+		}
+	}
+	return uids, nil
+}
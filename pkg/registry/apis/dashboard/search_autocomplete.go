@@ -0,0 +1,136 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+const (
+	// autocompleteDefaultLimit and autocompleteMaxLimit are the number of completions returned
+	autocompleteDefaultLimit = 10
+	autocompleteMaxLimit     = 50
+)
+
+var ErrInvalidAutocomplete = errutil.BadRequest("dashboards.search.autocomplete.invalid")
+
+// Completion is a dashboard or folder with a title starting with the typed prefix.
+type Completion struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+}
+
+// CompletionList are the completions of a prefix, best match first.
+type CompletionList struct {
+	Completions []Completion `json:"completions"`
+}
+
+// SearchAutocomplete completes title prefixes for the command palette, it only reads the names and titles of the
+// hits so it can be called on every keystroke. Starred and recently viewed dashboards are ranked first, like in the search.
+type SearchAutocomplete struct {
+	search *SearchConnector
+}
+
+func NewSearchAutocomplete(client resource.ResourceIndexClient, stars star.Service, folders folder.Service) *SearchAutocomplete {
+	return &SearchAutocomplete{
+		search: &SearchConnector{
+			client:  client,
+			stars:   stars,
+			folders: folders,
+			log:     log.New("grafana-apiserver.dashboards.search.autocomplete"),
+		},
+	}
+}
+
+// Complete returns the dashboards and folders with a title starting with the prefix. Every word of the prefix must
+// start a word of the title, the last one may be incomplete:
+//
+//	prefix=cpu%20us  matches "CPU usage" and "Host CPU usage"
+//
+// The kind, folder, recursive, starred and recent parameters of the search are supported.
+func (a *SearchAutocomplete) Complete(ctx context.Context, user identity.Requester, params url.Values) (*CompletionList, error) {
+	list := &CompletionList{Completions: []Completion{}}
+	query := prefixQuery(params.Get("prefix"))
+	if query == "" {
+		return list, nil
+	}
+
+	limit := autocompleteDefaultLimit
+	if v := params.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return nil, ErrInvalidAutocomplete.Errorf("invalid limit %q", v)
+		}
+		limit = min(l, autocompleteMaxLimit)
+	}
+
+	searchParams := url.Values{}
+	for _, p := range []string{"folder", "recursive", "starred", "recent", "recentOnly"} {
+		if params.Has(p) {
+			searchParams.Set(p, params.Get(p))
+		}
+	}
+	searchParams.Set("kind", "Dashboard,Folder")
+	if k := params.Get("kind"); k != "" {
+		searchParams.Set("kind", k)
+	}
+	searchParams.Set("limit", strconv.Itoa(limit))
+
+	req, _, err := a.search.searchRequest(ctx, user, searchParams, query)
+	if err != nil {
+		switch {
+		case apierrors.IsBadRequest(err):
+			return nil, ErrInvalidAutocomplete.Errorf("%w", err)
+		case apierrors.IsForbidden(err):
+			return nil, ErrLegacySearchAccessDenied.Errorf("%w", err)
+		}
+		return nil, err
+	}
+	if req == nil {
+		return list, nil
+	}
+
+	res, err := a.search.client.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range res.Items {
+		r := resource.IndexedResource{}
+		if err := json.Unmarshal(item.Value, &r); err != nil {
+			return nil, fmt.Errorf("failed to read search result: %w", err)
+		}
+		list.Completions = append(list.Completions, Completion{Name: r.Name, Title: r.Title, Kind: r.Kind})
+	}
+	return list, nil
+}
+
+// prefixQuery requires a title word for each word of the prefix, the last word only needs to start a title word.
+// The title is indexed as lowercase words, the other characters of the prefix are separators.
+func prefixQuery(prefix string) string {
+	words := strings.FieldsFunc(strings.ToLower(prefix), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	clauses := make([]string, 0, len(words))
+	for i, word := range words {
+		clause := "+Title:" + word
+		if i == len(words)-1 {
+			clause += "*"
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " ")
+}
@@ -0,0 +1,63 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/star/startest"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	require.Equal(t, "", prefixQuery(""))
+	require.Equal(t, "", prefixQuery(" - "))
+	require.Equal(t, "+Title:cp*", prefixQuery("Cp"))
+	require.Equal(t, "+Title:cpu +Title:us*", prefixQuery("CPU us"))
+	require.Equal(t, "+Title:node +Title:exporter +Title:f*", prefixQuery(`node-exporter "f`))
+}
+
+func TestSearchAutocomplete(t *testing.T) {
+	value := func(r resource.IndexedResource) []byte {
+		v, err := json.Marshal(r)
+		require.NoError(t, err)
+		return v
+	}
+	client := &fakeIndexClient{response: &resource.SearchResponse{Items: []*resource.ResourceWrapper{
+		{Value: value(resource.IndexedResource{Kind: "Dashboard", Name: "abc", Title: "CPU usage"})},
+		{Value: value(resource.IndexedResource{Kind: "Folder", Name: "xyz", Title: "CPU"})},
+	}}}
+	stars := startest.NewStarServiceFake()
+	stars.ExpectedUserStars = &star.GetUserStarsResult{UserStars: map[string]bool{"abc": true}}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Namespace: "default", Permissions: readAllPermissions}
+	a := NewSearchAutocomplete(client, stars, nil)
+
+	list, err := a.Complete(context.Background(), user, url.Values{"prefix": {"cpu"}, "limit": {"100"}})
+	require.NoError(t, err)
+	require.Equal(t, &CompletionList{Completions: []Completion{
+		{Name: "abc", Title: "CPU usage", Kind: "Dashboard"},
+		{Name: "xyz", Title: "CPU", Kind: "Folder"},
+	}}, list)
+	require.JSONEq(t, `{
+		"must": {"conjuncts": [{"query": "+Title:cpu*"}]},
+		"should": {"disjuncts": [{"query": "Name:\"abc\"^5"}], "min": 0}
+	}`, client.request.Query)
+	require.Equal(t, resource.QueryTypeJSON, client.request.QueryType)
+	require.Equal(t, []string{"Dashboard", "Folder"}, client.request.Kind)
+	require.Equal(t, int64(autocompleteMaxLimit), client.request.Limit)
+
+	client.request = nil
+	list, err = a.Complete(context.Background(), user, url.Values{"prefix": {" "}})
+	require.NoError(t, err)
+	require.Empty(t, list.Completions)
+	require.Nil(t, client.request, "nothing is searched without a prefix")
+
+	_, err = a.Complete(context.Background(), user, url.Values{"prefix": {"cpu"}, "limit": {"-1"}})
+	require.ErrorIs(t, err, ErrInvalidAutocomplete)
+}
@@ -0,0 +1,48 @@
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// searchMetrics are the metrics of the searches of the dashboards API
+type searchMetrics struct {
+	queryCost *prometheus.HistogramVec
+}
+
+func newSearchMetrics(reg prometheus.Registerer) *searchMetrics {
+	return &searchMetrics{
+		queryCost: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboards_api",
+			Name:      "search_query_cost_bytes",
+			Help:      "The number of bytes read from the search index by the searches of dashboards, per namespace.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+		}, []string{"namespace"}),
+	}
+}
+
+// search runs the search in the index and accounts its cost in the namespace. Searches slower than the threshold
+// are logged with their query, so expensive queries can be found.
+func (s *SearchConnector) search(ctx context.Context, namespace string, req *resource.SearchRequest) (*resource.SearchResponse, error) {
+	start := time.Now()
+	res, err := s.client.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	if s.metrics != nil {
+		s.metrics.queryCost.WithLabelValues(namespace).Observe(float64(res.QueryCost))
+	}
+	if s.slowThreshold > 0 && elapsed >= s.slowThreshold {
+		s.log.FromContext(ctx).Warn("Slow dashboard search", "namespace", namespace, "duration", elapsed,
+			"query", req.Query, "queryType", req.QueryType, "limit", req.Limit, "offset", req.Offset, "queryCost", res.QueryCost)
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestSearchQueryCost(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	client := &fakeIndexClient{response: &resource.SearchResponse{QueryCost: 2048}}
+	s := &SearchConnector{client: client, metrics: newSearchMetrics(reg), log: log.NewNopLogger()}
+
+	req := &resource.SearchRequest{Query: "*", Limit: 10}
+	for i := 0; i < 2; i++ {
+		res, err := s.search(context.Background(), "org-2", req)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2048), res.QueryCost)
+	}
+	require.Same(t, req, client.request)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "grafana_dashboards_api_search_query_cost_bytes", families[0].GetName())
+	metric := families[0].GetMetric()
+	require.Len(t, metric, 1)
+	require.Equal(t, "org-2", metric[0].GetLabel()[0].GetValue())
+	require.Equal(t, uint64(2), metric[0].GetHistogram().GetSampleCount())
+	require.Equal(t, float64(4096), metric[0].GetHistogram().GetSampleSum())
+}
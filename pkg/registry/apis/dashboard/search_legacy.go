@@ -0,0 +1,235 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+const (
+	// legacySearchDefaultLimit and legacySearchMaxLimit are the page sizes of the legacy search
+	legacySearchDefaultLimit = 1000
+	legacySearchMaxLimit     = 5000
+)
+
+var (
+	ErrInvalidLegacySearch      = errutil.BadRequest("dashboards.search.invalid")
+	ErrLegacySearchAccessDenied = errutil.Forbidden("dashboards.search.forbidden")
+)
+
+// LegacySearch answers requests with the parameters of the legacy /api/search with the search of the dashboards API,
+// and returns the results as the legacy hit list, so the frontend can move to the new search gradually.
+type LegacySearch struct {
+	search *SearchConnector
+	// paths resolves the paths of the folders of the hits
+	paths *folderPathCache
+}
+
+func NewLegacySearch(client resource.ResourceIndexClient, stars star.Service, folders folder.Service) *LegacySearch {
+	logger := log.New("grafana-apiserver.dashboards.search.legacy")
+	return &LegacySearch{
+		search: &SearchConnector{
+			client:  client,
+			stars:   stars,
+			folders: folders,
+			log:     logger,
+		},
+		paths: newFolderPathCache(folders, logger),
+	}
+}
+
+// Search translates the legacy search parameters, runs the search and re-shapes the results into legacy hits.
+// Searching by internal ids, in deleted dashboards or for dashboards the user can edit is not supported.
+func (l *LegacySearch) Search(ctx context.Context, user identity.Requester, legacy url.Values) (model.HitList, error) {
+	params, filters, sortBy, err := legacySearchParams(legacy)
+	if err != nil {
+		return nil, err
+	}
+
+	req, signals, err := l.search.searchRequest(ctx, user, params, filters...)
+	if err != nil {
+		switch {
+		case apierrors.IsBadRequest(err):
+			return nil, ErrInvalidLegacySearch.Errorf("%w", err)
+		case apierrors.IsForbidden(err):
+			return nil, ErrLegacySearchAccessDenied.Errorf("%w", err)
+		}
+		return nil, err
+	}
+	if req == nil {
+		return model.HitList{}, nil
+	}
+	req.SortBy = sortBy
+
+	res, err := l.search.client.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return l.hits(ctx, user, res, signals)
+}
+
+// legacySearchParams translates the parameters of the legacy search into the parameters of the search connector,
+// the required filters and the sort order
+func legacySearchParams(legacy url.Values) (url.Values, []string, []string, error) {
+	if legacy.Has("dashboardIds") || legacy.Has("folderIds") {
+		return nil, nil, nil, ErrInvalidLegacySearch.Errorf("searching by id is not supported, use dashboardUIDs and folderUIDs")
+	}
+	if legacy.Get("deleted") == "true" {
+		return nil, nil, nil, ErrInvalidLegacySearch.Errorf("searching deleted dashboards is not supported")
+	}
+	if p := legacy.Get("permission"); p != "" && p != "View" {
+		return nil, nil, nil, ErrInvalidLegacySearch.Errorf("only the dashboards the user can view can be searched")
+	}
+
+	params := url.Values{}
+	if q := legacy.Get("query"); q != "" {
+		params.Set("query", q)
+	}
+	if legacy.Get("starred") == "true" {
+		params.Set("starred", "true")
+	}
+
+	switch model.HitType(legacy.Get("type")) {
+	case "":
+		params.Set("kind", "Dashboard,Folder")
+	case model.DashHitDB:
+		params.Set("kind", "Dashboard")
+	case model.DashHitFolder:
+		params.Set("kind", "Folder")
+	default:
+		return nil, nil, nil, ErrInvalidLegacySearch.Errorf("unsupported type %q", legacy.Get("type"))
+	}
+
+	limit := int64(legacySearchDefaultLimit)
+	if v := legacy.Get("limit"); v != "" {
+		l, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || l < 0 {
+			return nil, nil, nil, ErrInvalidLegacySearch.Errorf("invalid limit %q", v)
+		}
+		if l > legacySearchMaxLimit {
+			return nil, nil, nil, ErrInvalidLegacySearch.Errorf("limit is above maximum allowed (%d), use page parameter to access hits beyond limit", legacySearchMaxLimit)
+		}
+		if l > 0 {
+			limit = l
+		}
+	}
+	page := int64(1)
+	if v := legacy.Get("page"); v != "" {
+		p, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, nil, nil, ErrInvalidLegacySearch.Errorf("invalid page %q", v)
+		}
+		if p > 1 {
+			page = p
+		}
+	}
+	params.Set("limit", strconv.FormatInt(limit, 10))
+	params.Set("offset", strconv.FormatInt((page-1)*limit, 10))
+
+	switch folders := legacy["folderUIDs"]; len(folders) {
+	case 0:
+	case 1:
+		params.Set("folder", folders[0])
+	default:
+		return nil, nil, nil, ErrInvalidLegacySearch.Errorf("searching in more than one folder is not supported")
+	}
+
+	filters := []string{}
+	uids := legacy["dashboardUIDs"]
+	if len(uids) == 0 {
+		// still sent by Grafana 9 clients
+		uids = legacy["dashboardUID"]
+	}
+	if len(uids) > 0 {
+		filters = append(filters, termClauses("Name", uids, nil))
+	}
+	// dashboards must have all the tags
+	for _, tag := range legacy["tag"] {
+		filters = append(filters, termClauses("Spec.tags", []string{tag}, nil))
+	}
+
+	var sortBy []string
+	switch legacy.Get("sort") {
+	case "":
+	case "alpha-asc":
+		sortBy = []string{"title"}
+	case "alpha-desc":
+		sortBy = []string{"-title"}
+	default:
+		return nil, nil, nil, ErrInvalidLegacySearch.Errorf("unsupported sort %q", legacy.Get("sort"))
+	}
+	return params, filters, sortBy, nil
+}
+
+// hits re-shapes the search results into legacy hits. The hits have the path of their folder, from the root folder,
+// so they can be shown as "Folder / Subfolder".
+func (l *LegacySearch) hits(ctx context.Context, user identity.Requester, res *resource.SearchResponse, signals *searchSignals) (model.HitList, error) {
+	hits := model.HitList{}
+	for _, item := range res.Items {
+		r := resource.IndexedResource{}
+		if err := json.Unmarshal(item.Value, &r); err != nil {
+			return nil, fmt.Errorf("failed to read search result: %w", err)
+		}
+		isFolder := r.Kind == "Folder"
+		if !isFolder && r.Kind != "Dashboard" {
+			continue
+		}
+
+		slug := slugify.Slugify(r.Title)
+		hit := &model.Hit{
+			UID:       r.Name,
+			Title:     r.Title,
+			URI:       "db/" + slug,
+			URL:       dashboards.GetDashboardFolderURL(isFolder, r.Name, slug),
+			Type:      model.DashHitDB,
+			Tags:      specStrings(r.Spec["tags"]),
+			IsStarred: slices.Contains(signals.starred, r.Name),
+			FolderUID: r.FolderId,
+		}
+		if isFolder {
+			hit.Type = model.DashHitFolder
+		}
+		if r.FolderId != "" {
+			title := ""
+			if path := l.paths.path(ctx, user, r.FolderId); len(path) > 0 {
+				title = path[len(path)-1].Title
+				hit.FolderPath = path
+			}
+			hit.FolderTitle = title
+			hit.FolderURL = dashboards.GetFolderURL(r.FolderId, slugify.Slugify(title))
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// specStrings reads a string list of the indexed spec, a single value is not returned as a list by the index
+func specStrings(v any) []string {
+	out := []string{}
+	switch v := v.(type) {
+	case string:
+		out = append(out, v)
+	case []any:
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/star/startest"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestLegacySearchParams(t *testing.T) {
+	t.Run("translates the legacy parameters", func(t *testing.T) {
+		params, filters, sortBy, err := legacySearchParams(url.Values{
+			"query":         {"cpu"},
+			"type":          {"dash-db"},
+			"starred":       {"true"},
+			"limit":         {"20"},
+			"page":          {"3"},
+			"folderUIDs":    {"xyz"},
+			"dashboardUIDs": {"a", "b"},
+			"tag":           {"prod", "db"},
+			"sort":          {"alpha-desc"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, url.Values{
+			"query":   {"cpu"},
+			"kind":    {"Dashboard"},
+			"starred": {"true"},
+			"limit":   {"20"},
+			"offset":  {"40"},
+			"folder":  {"xyz"},
+		}, params)
+		require.Equal(t, []string{`Name:"a" Name:"b"`, `Spec.tags:"prod"`, `Spec.tags:"db"`}, filters)
+		require.Equal(t, []string{"-title"}, sortBy)
+	})
+
+	t.Run("uses the legacy defaults", func(t *testing.T) {
+		params, filters, sortBy, err := legacySearchParams(url.Values{})
+		require.NoError(t, err)
+		require.Equal(t, url.Values{
+			"kind":   {"Dashboard,Folder"},
+			"limit":  {"1000"},
+			"offset": {"0"},
+		}, params)
+		require.Empty(t, filters)
+		require.Nil(t, sortBy)
+	})
+
+	t.Run("rejects unsupported parameters", func(t *testing.T) {
+		for _, legacy := range []url.Values{
+			{"dashboardIds": {"1"}},
+			{"folderIds": {"1"}},
+			{"folderUIDs": {"a", "b"}},
+			{"deleted": {"true"}},
+			{"permission": {"Edit"}},
+			{"limit": {"5001"}},
+			{"type": {"dash-home"}},
+			{"sort": {"views-desc"}},
+		} {
+			_, _, _, err := legacySearchParams(legacy)
+			require.ErrorIs(t, err, ErrInvalidLegacySearch, "%v", legacy)
+		}
+	})
+}
+
+func TestLegacySearch(t *testing.T) {
+	value := func(r resource.IndexedResource) []byte {
+		v, err := json.Marshal(r)
+		require.NoError(t, err)
+		return v
+	}
+	client := &fakeIndexClient{response: &resource.SearchResponse{Items: []*resource.ResourceWrapper{
+		{Value: value(resource.IndexedResource{Kind: "Folder", Name: "xyz", Title: "Hosts"})},
+		{Value: value(resource.IndexedResource{Kind: "Dashboard", Name: "abc", Title: "CPU usage", FolderId: "xyz", Spec: map[string]any{"tags": []any{"prod", "db"}}})},
+		{Value: value(resource.IndexedResource{Kind: "Dashboard", Name: "def", Title: "Memory", Spec: map[string]any{"tags": "prod"}})},
+		{Value: value(resource.IndexedResource{Kind: "Playlist", Name: "ghi", Title: "Rotation"})},
+	}}}
+	stars := startest.NewStarServiceFake()
+	stars.ExpectedUserStars = &star.GetUserStarsResult{UserStars: map[string]bool{"def": true}}
+	folders := foldertest.NewFakeService()
+	folders.ExpectedFolder = &folder.Folder{UID: "xyz", Title: "Hosts"}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Namespace: "default", Permissions: readAllPermissions}
+
+	hits, err := NewLegacySearch(client, stars, folders).Search(context.Background(), user, url.Values{"sort": {"alpha-asc"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"title"}, client.request.SortBy)
+	require.Equal(t, model.HitList{
+		{UID: "xyz", Title: "Hosts", URI: "db/hosts", URL: "/dashboards/f/xyz/hosts", Type: model.DashHitFolder, Tags: []string{}},
+		{UID: "abc", Title: "CPU usage", URI: "db/cpu-usage", URL: "/d/abc/cpu-usage", Type: model.DashHitDB, Tags: []string{"prod", "db"},
+			FolderUID: "xyz", FolderTitle: "Hosts", FolderURL: "/dashboards/f/xyz/hosts", FolderPath: []model.HitFolder{{UID: "xyz", Title: "Hosts"}}},
+		{UID: "def", Title: "Memory", URI: "db/memory", URL: "/d/def/memory", Type: model.DashHitDB, Tags: []string{"prod"}, IsStarred: true},
+	}, hits)
+
+	client.request = nil
+	stars.ExpectedUserStars = &star.GetUserStarsResult{}
+	hits, err = NewLegacySearch(client, stars, folders).Search(context.Background(), user, url.Values{"starred": {"true"}})
+	require.NoError(t, err)
+	require.Empty(t, hits)
+	require.Nil(t, client.request, "nothing can match without starred dashboards")
+}
+
+type fakeIndexClient struct {
+	resource.ResourceIndexClient
+	request  *resource.SearchRequest
+	response *resource.SearchResponse
+}
+
+func (f *fakeIndexClient) Search(_ context.Context, req *resource.SearchRequest, _ ...grpc.CallOption) (*resource.SearchResponse, error) {
+	f.request = req
+	return f.response, nil
+}
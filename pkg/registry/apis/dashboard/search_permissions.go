@@ -0,0 +1,135 @@
+package dashboard
+
+import (
+	"context"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+// permissionQuery returns the query limiting a search to the dashboards and folders the user can read, so the index
+// filters the results before they are paged and counted. It is nil when the user can read all of them, and none is
+// true when the user can not read any. As in the legacy search, the permissions on a folder apply to the dashboards
+// and folders of all its subfolders, and the permissions on the general folder to the dashboards without folder.
+func (s *SearchConnector) permissionQuery(ctx context.Context, user identity.Requester) (q query.Query, none bool, err error) {
+	permissions := user.GetPermissions()
+	dashboardScopes := permissions[dashboards.ActionDashboardsRead]
+	folderScopes := permissions[dashboards.ActionFoldersRead]
+	folderWildcards := accesscontrol.WildcardsFromPrefix(dashboards.ScopeFoldersPrefix)
+	allDashboards := hasWildcardScope(dashboardScopes, accesscontrol.WildcardsFromPrefix(dashboards.ScopeDashboardsPrefix), folderWildcards)
+	allFolders := hasWildcardScope(folderScopes, folderWildcards)
+	if allDashboards && allFolders {
+		return nil, false, nil
+	}
+
+	clauses := []query.Query{}
+	if allDashboards {
+		clauses = append(clauses, fieldQuery("Kind", "Dashboard"))
+	} else {
+		readable := []query.Query{}
+		for _, uid := range scopeUIDs(dashboardScopes, dashboards.ScopeDashboardsPrefix) {
+			readable = append(readable, uidQuery("Name", uid))
+		}
+		uids := scopeUIDs(dashboardScopes, dashboards.ScopeFoldersPrefix)
+		folders, err := s.permittedFolders(ctx, user, uids)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, uid := range folders {
+			readable = append(readable, uidQuery("FolderId", uid))
+		}
+		for _, uid := range uids {
+			if uid == folder.GeneralFolderUID {
+				root := bleve.NewBooleanQuery()
+				root.AddMustNot(regexpQuery("FolderId", ".+"))
+				readable = append(readable, root)
+			}
+		}
+		if len(readable) > 0 {
+			clauses = append(clauses, bleve.NewConjunctionQuery(fieldQuery("Kind", "Dashboard"), bleve.NewDisjunctionQuery(readable...)))
+		}
+	}
+	if allFolders {
+		clauses = append(clauses, fieldQuery("Kind", "Folder"))
+	} else {
+		folders, err := s.permittedFolders(ctx, user, scopeUIDs(folderScopes, dashboards.ScopeFoldersPrefix))
+		if err != nil {
+			return nil, false, err
+		}
+		readable := []query.Query{}
+		for _, uid := range folders {
+			readable = append(readable, uidQuery("Name", uid))
+		}
+		if len(readable) > 0 {
+			clauses = append(clauses, bleve.NewConjunctionQuery(fieldQuery("Kind", "Folder"), bleve.NewDisjunctionQuery(readable...)))
+		}
+	}
+
+	if len(clauses) == 0 {
+		return nil, true, nil
+	}
+	return bleve.NewDisjunctionQuery(clauses...), false, nil
+}
+
+// permittedFolders returns the folders and all their subfolders, the general folder excluded. The subfolders are
+// only resolved when the connector has a folder service.
+func (s *SearchConnector) permittedFolders(ctx context.Context, user identity.Requester, uids []string) ([]string, error) {
+	roots := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if uid != folder.GeneralFolderUID {
+			roots = append(roots, uid)
+		}
+	}
+	if s.folders == nil || len(roots) == 0 {
+		return roots, nil
+	}
+	return s.folderSubtree(ctx, user, roots...)
+}
+
+func hasWildcardScope(scopes []string, wildcards ...accesscontrol.Wildcards) bool {
+	for _, scope := range scopes {
+		for _, w := range wildcards {
+			if w.Contains(scope) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopeUIDs returns the uids of the scopes with the prefix, e.g. the folders of folders:uid:<uid>
+func scopeUIDs(scopes []string, prefix string) []string {
+	uids := []string{}
+	for _, scope := range scopes {
+		if uid, ok := strings.CutPrefix(scope, prefix); ok && uid != "" {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// fieldQuery matches the value in the field, as the phrases of termClauses
+func fieldQuery(field string, value string) query.Query {
+	q := bleve.NewMatchPhraseQuery(value)
+	q.SetField(field)
+	return q
+}
+
+// uidQuery matches the exact UID in a field indexed as a keyword, not the UIDs it is a part of
+func uidQuery(field string, uid string) query.Query {
+	q := bleve.NewTermQuery(uid)
+	q.SetField(field)
+	return q
+}
+
+func regexpQuery(field string, regexp string) query.Query {
+	q := bleve.NewRegexpQuery(regexp)
+	q.SetField(field)
+	return q
+}
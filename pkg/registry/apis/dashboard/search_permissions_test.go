@@ -0,0 +1,111 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+)
+
+// readAllPermissions can read all the dashboards and folders of org 1
+var readAllPermissions = map[int64]map[string][]string{1: {
+	dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsAll},
+	dashboards.ActionFoldersRead:    {dashboards.ScopeFoldersAll},
+}}
+
+func TestSearchPermissionQuery(t *testing.T) {
+	folders := &folderTree{
+		FakeService: foldertest.NewFakeService(),
+		children:    map[string][]string{"a": {"b"}},
+	}
+	s := &SearchConnector{folders: folders}
+	userWith := func(permissions map[string][]string) identity.Requester {
+		return &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}}
+	}
+
+	t.Run("no filter when everything can be read", func(t *testing.T) {
+		q, none, err := s.permissionQuery(context.Background(), userWith(readAllPermissions[1]))
+		require.NoError(t, err)
+		require.False(t, none)
+		require.Nil(t, q)
+
+		q, _, err = s.permissionQuery(context.Background(), userWith(map[string][]string{
+			dashboards.ActionDashboardsRead: {dashboards.ScopeFoldersAll},
+			dashboards.ActionFoldersRead:    {"*"},
+		}))
+		require.NoError(t, err)
+		require.Nil(t, q, "the dashboards of all folders are all the dashboards")
+	})
+
+	t.Run("nothing can be read", func(t *testing.T) {
+		q, none, err := s.permissionQuery(context.Background(), userWith(nil))
+		require.NoError(t, err)
+		require.True(t, none)
+		require.Nil(t, q)
+
+		req, _, err := s.searchRequest(context.Background(), userWith(nil), url.Values{"query": {"cpu"}})
+		require.NoError(t, err)
+		require.Nil(t, req)
+	})
+
+	t.Run("dashboards and folders by uid, with the subfolders", func(t *testing.T) {
+		q, none, err := s.permissionQuery(context.Background(), userWith(map[string][]string{
+			dashboards.ActionDashboardsRead: {"dashboards:uid:d1", "folders:uid:a", "folders:uid:general"},
+			dashboards.ActionFoldersRead:    {"folders:uid:a"},
+		}))
+		require.NoError(t, err)
+		require.False(t, none)
+		requireQueryJSON(t, `{"disjuncts": [
+			{"conjuncts": [
+				{"match_phrase": "Dashboard", "field": "Kind", "fuzziness": 0},
+				{"disjuncts": [
+					{"term": "d1", "field": "Name"},
+					{"term": "a", "field": "FolderId"},
+					{"term": "b", "field": "FolderId"},
+					{"must_not": {"disjuncts": [{"regexp": ".+", "field": "FolderId"}], "min": 0}}
+				], "min": 0}
+			]},
+			{"conjuncts": [
+				{"match_phrase": "Folder", "field": "Kind", "fuzziness": 0},
+				{"disjuncts": [
+					{"term": "a", "field": "Name"},
+					{"term": "b", "field": "Name"}
+				], "min": 0}
+			]}
+		], "min": 0}`, q)
+	})
+
+	t.Run("the permissions are required clauses of the search", func(t *testing.T) {
+		req, _, err := s.searchRequest(context.Background(), userWith(map[string][]string{
+			dashboards.ActionDashboardsRead: {"dashboards:uid:d1"},
+		}), url.Values{"query": {"cpu"}})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"conjuncts": [
+			{"match": "cpu", "prefix_length": 0, "fuzziness": 0},
+			{"disjuncts": [{"conjuncts": [
+				{"match_phrase": "Dashboard", "field": "Kind", "fuzziness": 0},
+				{"disjuncts": [{"term": "d1", "field": "Name"}], "min": 0}
+			]}], "min": 0}
+		]}`, req.Query)
+	})
+
+	t.Run("too many folders", func(t *testing.T) {
+		children := make([]string, maxSearchFolders+1)
+		for i := range children {
+			children[i] = fmt.Sprintf("f%d", i)
+		}
+		s := &SearchConnector{folders: &folderTree{FakeService: foldertest.NewFakeService(), children: map[string][]string{"a": children}}}
+		_, _, err := s.searchRequest(context.Background(), userWith(map[string][]string{
+			dashboards.ActionDashboardsRead: {"folders:uid:a"},
+		}), url.Values{})
+		require.True(t, apierrors.IsBadRequest(err), err)
+	})
+}
@@ -0,0 +1,516 @@
+package dashboard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// searchQueryFields are the names of the fields of the query syntax, other names are looked up in the indexed
+// fields of dashboards
+var searchQueryFields = map[string]string{
+	"title":       "Title",
+	"description": "Spec.description",
+	"tag":         "Spec.tags",
+	"folder":      "FolderId",
+	"kind":        "Kind",
+	"uid":         "Name",
+}
+
+// searchDateFields are the dates of dashboards the query syntax and the filters compare, e.g. updated>now-7d
+var searchDateFields = map[string]string{
+	"updated": "UpdatedAt",
+	"created": "CreatedAt",
+}
+
+// searchFieldAliases are the names of indexed fields in the query syntax and the filters
+var searchFieldAliases = map[string]string{
+	"panels":        IndexFieldPanelCount,
+	"schemaVersion": IndexFieldSchemaVersion,
+	"views":         IndexFieldViewsLast30d,
+	"lastViewed":    IndexFieldLastViewedAt,
+}
+
+// querySyntaxError is returned when the text query is malformed, pos is the byte offset of the error
+type querySyntaxError struct {
+	pos int
+	msg string
+}
+
+func (e *querySyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.pos+1, e.msg)
+}
+
+// parseSearchQuery translates the text query of a search into a query of the index:
+//
+//	cpu usage             dashboards matching both words, terms next to each other must all match
+//	"cpu usage"           the exact phrase
+//	tag:prod              dashboards with a field matching the value, e.g. title, description, tag, folder, kind,
+//	                      uid or an indexed field like ds_type
+//	title:"cpu usage"     the exact phrase in the field
+//	cpu OR memory         either of the terms
+//	cpu AND NOT staging   the first term but not the second, -staging is the same as NOT staging
+//	(cpu OR mem) AND prod parentheses group the terms, NOT binds tighter than AND, and AND tighter than OR
+//	mem*                  terms starting with mem, wildcards are only supported at the end of a term
+//	panels>20             dashboards with a numeric indexed field in a range, with >, >=, < or <=, e.g. panels
+//	                      or schemaVersion
+//	updated<now-1y        dashboards updated or created before or after a date, relative to now like now-7d,
+//	                      or a date like 2024-01-31 or 2024-01-31T12:00:00Z
+//
+// The operators are uppercase, lowercase and, or and not are plain words. With fuzziness, the plain words
+// also match words with up to that many typos. The query is nil when the text is empty.
+func parseSearchQuery(text string, fuzziness int, now time.Time) (query.Query, error) {
+	p := &queryParser{text: text, fuzziness: fuzziness, now: now}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return q, nil
+}
+
+// searchFuzziness reads the fuzziness parameter, the edit distance allowed for the plain words of the query:
+//
+//	query=cpu%20usage&fuzziness=1  matches "cpu usage", but also "cpu usge"
+func searchFuzziness(param string) (int, error) {
+	if param == "" {
+		return 0, nil
+	}
+	distance, err := strconv.Atoi(param)
+	if err != nil || distance < 0 || distance > maxFuzziness {
+		return 0, apierrors.NewBadRequest(fmt.Sprintf("fuzziness must be between 0 and %d", maxFuzziness))
+	}
+	return distance, nil
+}
+
+type queryTokenType int
+
+const (
+	tokTerm queryTokenType = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokOpen
+	tokClose
+)
+
+type queryToken struct {
+	typ  queryTokenType
+	pos  int
+	text string
+	// field and value of terms, the field is empty for the terms of the default field
+	field  string
+	value  string
+	phrase bool
+	// op is the comparison of range terms, e.g. >=
+	op string
+}
+
+type queryParser struct {
+	text      string
+	fuzziness int
+	tokens    []queryToken
+	pos       int
+	// now is the time the relative dates are resolved against
+	now time.Time
+}
+
+func (p *queryParser) lex() error {
+	s := p.text
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			p.tokens = append(p.tokens, queryToken{typ: tokOpen, pos: i, text: "("})
+			i++
+		case c == ')':
+			p.tokens = append(p.tokens, queryToken{typ: tokClose, pos: i, text: ")"})
+			i++
+		case c == '-' && i+1 < len(s) && !unicode.IsSpace(rune(s[i+1])):
+			p.tokens = append(p.tokens, queryToken{typ: tokNot, pos: i, text: "-"})
+			i++
+		case c == '"':
+			value, end, err := readPhrase(s, i)
+			if err != nil {
+				return err
+			}
+			p.tokens = append(p.tokens, queryToken{typ: tokTerm, pos: i, text: s[i:end], value: value, phrase: true})
+			i = end
+		default:
+			tok, end, err := readTerm(s, i)
+			if err != nil {
+				return err
+			}
+			p.tokens = append(p.tokens, tok)
+			i = end
+		}
+	}
+	return nil
+}
+
+// readPhrase reads the quoted phrase starting at i, quotes in the phrase are escaped with a backslash
+func readPhrase(s string, i int) (string, int, error) {
+	phrase := strings.Builder{}
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if j+1 < len(s) {
+				j++
+				phrase.WriteByte(s[j])
+			}
+		case '"':
+			if phrase.Len() == 0 {
+				return "", 0, &querySyntaxError{pos: i, msg: "empty phrase"}
+			}
+			return phrase.String(), j + 1, nil
+		default:
+			phrase.WriteByte(s[j])
+		}
+	}
+	return "", 0, &querySyntaxError{pos: i, msg: "unterminated phrase"}
+}
+
+// readTerm reads the word, the field:value or the field>value term starting at i, the value can be a phrase
+func readTerm(s string, i int) (queryToken, int, error) {
+	end := i
+	for end < len(s) && !unicode.IsSpace(rune(s[end])) && !strings.ContainsRune(`()":<>`, rune(s[end])) {
+		end++
+	}
+	word := s[i:end]
+	if end < len(s) && (s[end] == '<' || s[end] == '>') {
+		return readRangeTerm(s, i, end)
+	}
+	if end == len(s) || s[end] != ':' {
+		switch word {
+		case "AND":
+			return queryToken{typ: tokAnd, pos: i, text: word}, end, nil
+		case "OR":
+			return queryToken{typ: tokOr, pos: i, text: word}, end, nil
+		case "NOT":
+			return queryToken{typ: tokNot, pos: i, text: word}, end, nil
+		}
+		return queryToken{typ: tokTerm, pos: i, text: word, value: word}, end, nil
+	}
+
+	// field:value
+	if word == "" {
+		return queryToken{}, 0, &querySyntaxError{pos: i, msg: "missing field name before :"}
+	}
+	tok := queryToken{typ: tokTerm, pos: i, field: word}
+	start := end + 1
+	if start < len(s) && s[start] == '"' {
+		value, next, err := readPhrase(s, start)
+		if err != nil {
+			return queryToken{}, 0, err
+		}
+		tok.value, tok.phrase, tok.text = value, true, s[i:next]
+		return tok, next, nil
+	}
+	next := start
+	for next < len(s) && !unicode.IsSpace(rune(s[next])) && !strings.ContainsRune(`()":`, rune(s[next])) {
+		next++
+	}
+	if next == start {
+		return queryToken{}, 0, &querySyntaxError{pos: i, msg: fmt.Sprintf("missing value for field %s", word)}
+	}
+	tok.value, tok.text = s[start:next], s[i:next]
+	return tok, next, nil
+}
+
+// readRangeTerm reads the field>value term starting at i, the comparison starts at op
+func readRangeTerm(s string, i int, op int) (queryToken, int, error) {
+	if op == i {
+		return queryToken{}, 0, &querySyntaxError{pos: i, msg: fmt.Sprintf("missing field name before %c", s[op])}
+	}
+	tok := queryToken{typ: tokTerm, pos: i, field: s[i:op], op: s[op : op+1]}
+	start := op + 1
+	if start < len(s) && s[start] == '=' {
+		tok.op += "="
+		start++
+	}
+	next := start
+	for next < len(s) && !unicode.IsSpace(rune(s[next])) && !strings.ContainsRune(`()"<>`, rune(s[next])) {
+		next++
+	}
+	if next == start {
+		return queryToken{}, 0, &querySyntaxError{pos: i, msg: fmt.Sprintf("missing value for field %s", tok.field)}
+	}
+	tok.value, tok.text = s[start:next], s[i:next]
+	return tok, next, nil
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) parseOr() (query.Query, error) {
+	q, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	disjuncts := []query.Query{q}
+	for tok := p.peek(); tok != nil && tok.typ == tokOr; tok = p.peek() {
+		p.pos++
+		q, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		disjuncts = append(disjuncts, q)
+	}
+	if len(disjuncts) == 1 {
+		return disjuncts[0], nil
+	}
+	return bleve.NewDisjunctionQuery(disjuncts...), nil
+}
+
+// parseAnd joins the terms until an OR or a closing parenthesis, AND is optional between terms
+func (p *queryParser) parseAnd() (query.Query, error) {
+	q, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	conjuncts := []query.Query{q}
+	for tok := p.peek(); tok != nil && tok.typ != tokOr && tok.typ != tokClose; tok = p.peek() {
+		if tok.typ == tokAnd {
+			p.pos++
+		}
+		q, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		conjuncts = append(conjuncts, q)
+	}
+	if len(conjuncts) == 1 {
+		return conjuncts[0], nil
+	}
+	return bleve.NewConjunctionQuery(conjuncts...), nil
+}
+
+func (p *queryParser) parseNot() (query.Query, error) {
+	tok := p.peek()
+	if tok == nil || tok.typ != tokNot {
+		return p.parsePrimary()
+	}
+	p.pos++
+	q, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	// a boolean query with only excluded clauses matches all the other documents
+	not := bleve.NewBooleanQuery()
+	not.AddMustNot(q)
+	return not, nil
+}
+
+func (p *queryParser) parsePrimary() (query.Query, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, &querySyntaxError{pos: len(p.text), msg: "unexpected end of query"}
+	}
+	p.pos++
+	switch tok.typ {
+	case tokTerm:
+		return p.term(tok)
+	case tokOpen:
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.typ != tokClose {
+			return nil, &querySyntaxError{pos: tok.pos, msg: "missing closing parenthesis"}
+		}
+		p.pos++
+		return q, nil
+	}
+	return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+// term translates a term into a query of its field
+func (p *queryParser) term(tok *queryToken) (query.Query, error) {
+	if tok.op != "" {
+		return p.rangeTerm(tok)
+	}
+	if _, ok := searchDateFields[tok.field]; ok {
+		return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("%s is a date, compare it with <, <=, > or >=, e.g. %s>now-7d", tok.field, tok.field)}
+	}
+	field, numeric, err := searchQueryField(tok)
+	if err != nil {
+		return nil, err
+	}
+	if numeric {
+		return numericTerm(tok, field)
+	}
+
+	if tok.phrase {
+		q := bleve.NewMatchPhraseQuery(tok.value)
+		q.SetField(field)
+		return q, nil
+	}
+	if tok.value == "*" && field == "" {
+		return bleve.NewMatchAllQuery(), nil
+	}
+	if i := strings.IndexAny(tok.value, "*?"); i >= 0 {
+		if i == 0 || i != len(tok.value)-1 || tok.value[i] != '*' {
+			return nil, &querySyntaxError{pos: tok.pos, msg: "wildcards are only supported at the end of a term"}
+		}
+		// wildcards are not analyzed by the index, they are matched against the lowercase tokens
+		q := bleve.NewWildcardQuery(strings.ToLower(tok.value))
+		q.SetField(field)
+		return q, nil
+	}
+	q := bleve.NewMatchQuery(tok.value)
+	q.SetField(field)
+	if field == "" && p.fuzziness > 0 {
+		q.SetFuzziness(p.fuzziness)
+	}
+	return q, nil
+}
+
+// numericTerm matches a numeric indexed field, e.g. panel_count:3
+func numericTerm(tok *queryToken, field string) (query.Query, error) {
+	v, err := strconv.ParseFloat(tok.value, 64)
+	if err != nil || tok.phrase {
+		return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("%s is a number", tok.field)}
+	}
+	inclusive := true
+	q := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+	q.SetField(field)
+	return q, nil
+}
+
+// rangeTerm matches the dates and the numeric indexed fields in a range, e.g. panels>20 or updated<now-1y
+func (p *queryParser) rangeTerm(tok *queryToken) (query.Query, error) {
+	field, fieldType := searchRangeField(tok.field)
+	minInclusive, maxInclusive := tok.op == ">=", tok.op == "<="
+	switch fieldType {
+	case "int", "int64", "float64":
+		v, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("%s is a number", tok.field)}
+		}
+		var q *query.NumericRangeQuery
+		if strings.HasPrefix(tok.op, ">") {
+			q = bleve.NewNumericRangeInclusiveQuery(&v, nil, &minInclusive, nil)
+		} else {
+			q = bleve.NewNumericRangeInclusiveQuery(nil, &v, nil, &maxInclusive)
+		}
+		q.SetField(field)
+		return q, nil
+	case "time":
+		date, err := parseSearchDate(tok.value, p.now)
+		if err != nil {
+			return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("%s is a date: %s", tok.field, err)}
+		}
+		var q *query.DateRangeQuery
+		if strings.HasPrefix(tok.op, ">") {
+			q = bleve.NewDateRangeInclusiveQuery(date, time.Time{}, &minInclusive, nil)
+		} else {
+			q = bleve.NewDateRangeInclusiveQuery(time.Time{}, date, nil, &maxInclusive)
+		}
+		q.SetField(field)
+		return q, nil
+	case "":
+		return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("unknown field %s", tok.field)}
+	}
+	return nil, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("%s can not be compared with %s", tok.field, tok.op)}
+}
+
+// searchRangeField returns the index field and the type of a field compared with a range, the type is empty for
+// unknown fields
+func searchRangeField(name string) (string, string) {
+	if field, ok := searchDateFields[name]; ok {
+		return field, "time"
+	}
+	if _, ok := searchQueryFields[name]; ok {
+		return searchQueryFields[name], "string"
+	}
+	if alias, ok := searchFieldAliases[name]; ok {
+		name = alias
+	}
+	fieldType, _ := resource.IndexFieldType(dashboardIndexKind, name)
+	return "Spec." + name, fieldType
+}
+
+// parseSearchDate reads a date of the query syntax and the filters, relative to now like now-7d or now, or a date
+// like 2024-01-31 or 2024-01-31T12:00:00Z. Relative dates support the units of durations and d, w, M and y.
+func parseSearchDate(value string, now time.Time) (time.Time, error) {
+	if rest, ok := strings.CutPrefix(value, "now"); ok {
+		if rest == "" {
+			return now, nil
+		}
+		if rest[0] != '-' && rest[0] != '+' {
+			return time.Time{}, fmt.Errorf("invalid relative date %s, expected e.g. now-7d", value)
+		}
+		sign := 1
+		if rest[0] == '-' {
+			sign = -1
+		}
+		// months and years are calendar months and years, so now-1y is the same day of the previous year
+		if n, err := strconv.Atoi(rest[1 : len(rest)-1]); err == nil && n >= 0 {
+			switch rest[len(rest)-1] {
+			case 'M':
+				return now.AddDate(0, sign*n, 0), nil
+			case 'y':
+				return now.AddDate(sign*n, 0, 0), nil
+			}
+		}
+		d, err := gtime.ParseDuration(rest[1:])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative date %s, expected e.g. now-7d", value)
+		}
+		return now.Add(time.Duration(sign) * d), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %s, expected e.g. now-7d, 2024-01-31 or 2024-01-31T12:00:00Z", value)
+}
+
+// searchQueryField returns the index field of a term, it is empty for the default field
+func searchQueryField(tok *queryToken) (string, bool, error) {
+	if tok.field == "" {
+		return "", false, nil
+	}
+	if field, ok := searchQueryFields[tok.field]; ok {
+		return field, false, nil
+	}
+	name := tok.field
+	if alias, ok := searchFieldAliases[name]; ok {
+		name = alias
+	}
+	switch fieldType, _ := resource.IndexFieldType(dashboardIndexKind, name); fieldType {
+	case "string", "string[]":
+		return "Spec." + name, false, nil
+	case "int", "int64", "float64":
+		return "Spec." + name, true, nil
+	case "time":
+		return "", false, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("%s is a date, compare it with <, <=, > or >=, e.g. %s>now-7d", tok.field, tok.field)}
+	}
+	return "", false, &querySyntaxError{pos: tok.pos, msg: fmt.Sprintf("unknown field %s", tok.field)}
+}
@@ -0,0 +1,179 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, SearchIndexFields()...))
+
+	cases := map[string]string{
+		`cpu`:            `{"match": "cpu", "prefix_length": 0, "fuzziness": 0}`,
+		`"cpu usage"`:    `{"match_phrase": "cpu usage", "fuzziness": 0}`,
+		`"say \"hi\""`:   `{"match_phrase": "say \"hi\"", "fuzziness": 0}`,
+		`tag:prod`:       `{"match": "prod", "field": "Spec.tags", "prefix_length": 0, "fuzziness": 0}`,
+		`title:"cpu a"`:  `{"match_phrase": "cpu a", "field": "Title", "fuzziness": 0}`,
+		`ds_type:loki`:   `{"match": "loki", "field": "Spec.ds_type", "prefix_length": 0, "fuzziness": 0}`,
+		`panel_count:3`:  `{"min": 3, "max": 3, "inclusive_min": true, "inclusive_max": true, "field": "Spec.panel_count"}`,
+		`Mem*`:           `{"wildcard": "mem*"}`,
+		`title:cpu*`:     `{"wildcard": "cpu*", "field": "Title"}`,
+		`*`:              `{"boost": null, "match_all": {}}`,
+		`cpu and memory`: `{"conjuncts": [{"match": "cpu", "prefix_length": 0, "fuzziness": 0}, {"match": "and", "prefix_length": 0, "fuzziness": 0}, {"match": "memory", "prefix_length": 0, "fuzziness": 0}]}`,
+		`cpu OR memory`:  `{"disjuncts": [{"match": "cpu", "prefix_length": 0, "fuzziness": 0}, {"match": "memory", "prefix_length": 0, "fuzziness": 0}], "min": 0}`,
+		`cpu AND NOT tag:staging`: `{"conjuncts": [
+			{"match": "cpu", "prefix_length": 0, "fuzziness": 0},
+			{"must_not": {"disjuncts": [{"match": "staging", "field": "Spec.tags", "prefix_length": 0, "fuzziness": 0}], "min": 0}}
+		]}`,
+		`-staging`: `{"must_not": {"disjuncts": [{"match": "staging", "prefix_length": 0, "fuzziness": 0}], "min": 0}}`,
+		`(cpu OR mem) prod`: `{"conjuncts": [
+			{"disjuncts": [{"match": "cpu", "prefix_length": 0, "fuzziness": 0}, {"match": "mem", "prefix_length": 0, "fuzziness": 0}], "min": 0},
+			{"match": "prod", "prefix_length": 0, "fuzziness": 0}
+		]}`,
+		`a OR b c`: `{"disjuncts": [
+			{"match": "a", "prefix_length": 0, "fuzziness": 0},
+			{"conjuncts": [{"match": "b", "prefix_length": 0, "fuzziness": 0}, {"match": "c", "prefix_length": 0, "fuzziness": 0}]}
+		], "min": 0}`,
+		`cpu-usage`: `{"match": "cpu-usage", "prefix_length": 0, "fuzziness": 0}`,
+	}
+	for text, expected := range cases {
+		t.Run(text, func(t *testing.T) {
+			q, err := parseSearchQuery(text, 0, time.Time{})
+			require.NoError(t, err)
+			requireQueryJSON(t, expected, q)
+		})
+	}
+
+	t.Run("empty query", func(t *testing.T) {
+		q, err := parseSearchQuery("  ", 0, time.Time{})
+		require.NoError(t, err)
+		require.Nil(t, q)
+	})
+
+	t.Run("fuzziness only applies to plain words", func(t *testing.T) {
+		q, err := parseSearchQuery(`cpu tag:prod mem*`, 1, time.Time{})
+		require.NoError(t, err)
+		requireQueryJSON(t, `{"conjuncts": [
+			{"match": "cpu", "prefix_length": 0, "fuzziness": 1},
+			{"match": "prod", "field": "Spec.tags", "prefix_length": 0, "fuzziness": 0},
+			{"wildcard": "mem*"}
+		]}`, q)
+	})
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, SearchIndexFields()...))
+
+	cases := map[string]string{
+		`"cpu`:              `invalid query at position 1: unterminated phrase`,
+		`""`:                `invalid query at position 1: empty phrase`,
+		`cpu AND`:           `invalid query at position 8: unexpected end of query`,
+		`OR cpu`:            `invalid query at position 1: unexpected "OR"`,
+		`(cpu OR mem`:       `invalid query at position 1: missing closing parenthesis`,
+		`cpu)`:              `invalid query at position 4: unexpected ")"`,
+		`owner:me`:          `invalid query at position 1: unknown field owner`,
+		`tag:`:              `invalid query at position 1: missing value for field tag`,
+		`:prod`:             `invalid query at position 1: missing field name before :`,
+		`*mem`:              `invalid query at position 1: wildcards are only supported at the end of a term`,
+		`cpu m?m`:           `invalid query at position 5: wildcards are only supported at the end of a term`,
+		`panel_count:three`: `invalid query at position 1: panel_count is a number`,
+		`panels>many`:       `invalid query at position 1: panels is a number`,
+		`updated:2024`:      `invalid query at position 1: updated is a date, compare it with <, <=, > or >=, e.g. updated>now-7d`,
+		`updated>yesterday`: `invalid query at position 1: updated is a date: invalid date yesterday, expected e.g. now-7d, 2024-01-31 or 2024-01-31T12:00:00Z`,
+		`updated>now-7x`:    `invalid query at position 1: updated is a date: invalid relative date now-7x, expected e.g. now-7d`,
+		`tag>prod`:          `invalid query at position 1: tag can not be compared with >`,
+		`stars>10`:          `invalid query at position 1: unknown field stars`,
+		`>10`:               `invalid query at position 1: missing field name before >`,
+		`panels>=`:          `invalid query at position 1: missing value for field panels`,
+	}
+	for text, expected := range cases {
+		t.Run(text, func(t *testing.T) {
+			_, err := parseSearchQuery(text, 0, time.Time{})
+			require.EqualError(t, err, expected)
+		})
+	}
+}
+
+func TestParseSearchQueryRanges(t *testing.T) {
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, SearchIndexFields()...))
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, NewDashboardViews(kvstore.NewFakeKVStore(), nil, nil).IndexFields()...))
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	// the open end of a date range is the zero time
+	cases := map[string]string{
+		`panels>20`:                         `{"min": 20, "inclusive_min": false, "field": "Spec.panel_count"}`,
+		`schemaVersion<30`:                  `{"max": 30, "inclusive_max": false, "field": "Spec.schema_version"}`,
+		`schema_version<=30`:                `{"max": 30, "inclusive_max": true, "field": "Spec.schema_version"}`,
+		`updated>=now-7d`:                   `{"start": "2024-03-03T12:00:00Z", "end": "0001-01-01T00:00:00Z", "inclusive_start": true, "field": "UpdatedAt"}`,
+		`created<2024-01-31`:                `{"start": "0001-01-01T00:00:00Z", "end": "2024-01-31T00:00:00Z", "inclusive_end": false, "field": "CreatedAt"}`,
+		`updated>now`:                       `{"start": "2024-03-10T12:00:00Z", "end": "0001-01-01T00:00:00Z", "inclusive_start": false, "field": "UpdatedAt"}`,
+		`panels:3`:                          `{"min": 3, "max": 3, "inclusive_min": true, "inclusive_max": true, "field": "Spec.panel_count"}`,
+		`cpu updated<now-1y`:                `{"conjuncts": [{"match": "cpu", "prefix_length": 0, "fuzziness": 0}, {"start": "0001-01-01T00:00:00Z", "end": "2023-03-10T12:00:00Z", "inclusive_end": false, "field": "UpdatedAt"}]}`,
+		`updated>2024-03-01T08:00:00+02:00`: `{"start": "2024-03-01T06:00:00Z", "end": "0001-01-01T00:00:00Z", "inclusive_start": false, "field": "UpdatedAt"}`,
+		`lastViewed<now-90d`:                `{"start": "0001-01-01T00:00:00Z", "end": "2023-12-11T12:00:00Z", "inclusive_end": false, "field": "Spec.last_viewed_at"}`,
+		`views>=100`:                        `{"min": 100, "inclusive_min": true, "field": "Spec.views_last_30d"}`,
+	}
+	for text, expected := range cases {
+		t.Run(text, func(t *testing.T) {
+			q, err := parseSearchQuery(text, 0, now)
+			require.NoError(t, err)
+			requireQueryJSON(t, expected, q)
+		})
+	}
+}
+
+func TestFuzzyQuery(t *testing.T) {
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, SearchIndexFields()...))
+
+	// fuzzyQuery reads the fuzziness parameter and parses the text query with it, like the search requests
+	fuzzyQuery := func(text string, param string) (query.Query, error) {
+		fuzziness, err := searchFuzziness(param)
+		if err != nil {
+			return nil, err
+		}
+		return parseSearchQuery(text, fuzziness, time.Time{})
+	}
+
+	q, err := fuzzyQuery("CPU usge", "1")
+	require.NoError(t, err)
+	requireQueryJSON(t, `{"conjuncts": [
+		{"match": "CPU", "prefix_length": 0, "fuzziness": 1},
+		{"match": "usge", "prefix_length": 0, "fuzziness": 1}
+	]}`, q)
+
+	// the terms using the query syntax are not fuzzy
+	q, err = fuzzyQuery(`title:cpu "host a" mem*`, "2")
+	require.NoError(t, err)
+	requireQueryJSON(t, `{"conjuncts": [
+		{"match": "cpu", "field": "Title", "prefix_length": 0, "fuzziness": 0},
+		{"match_phrase": "host a", "fuzziness": 0},
+		{"wildcard": "mem*"}
+	]}`, q)
+
+	q, err = fuzzyQuery("cpu", "0")
+	require.NoError(t, err)
+	requireQueryJSON(t, `{"match": "cpu", "prefix_length": 0, "fuzziness": 0}`, q)
+
+	for _, fuzziness := range []string{"3", "-1", "x"} {
+		_, err = fuzzyQuery("cpu", fuzziness)
+		require.Error(t, err, fuzziness)
+	}
+}
+
+func TestSearchFuzziness(t *testing.T) {
+	for param, expected := range map[string]int{"": 0, "0": 0, "2": 2} {
+		distance, err := searchFuzziness(param)
+		require.NoError(t, err)
+		require.Equal(t, expected, distance)
+	}
+	for _, param := range []string{"3", "-1", "x"} {
+		_, err := searchFuzziness(param)
+		require.Error(t, err, param)
+	}
+}
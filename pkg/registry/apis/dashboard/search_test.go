@@ -0,0 +1,223 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/star/startest"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestSearchSignals(t *testing.T) {
+	cpu := bleve.NewQueryStringQuery("cpu")
+
+	t.Run("keeps the query when there are no signals", func(t *testing.T) {
+		s := &searchSignals{}
+		require.Equal(t, cpu, s.query(cpu))
+		require.Nil(t, s.query(nil))
+	})
+
+	t.Run("ranks starred and recent dashboards higher", func(t *testing.T) {
+		s := &searchSignals{starred: []string{"a"}, recent: []string{"b", "c"}}
+		requireQueryJSON(t, `{
+			"must": {"conjuncts": [{"query": "cpu"}]},
+			"should": {"disjuncts": [{"query": "Name:\"a\"^5"}, {"query": "Name:\"b\"^2 Name:\"c\"^1"}], "min": 0}
+		}`, s.query(cpu))
+		require.Nil(t, s.query(nil), "nothing to rank without a query or a filter")
+	})
+
+	t.Run("filters starred dashboards", func(t *testing.T) {
+		s := &searchSignals{starred: []string{"a", "b"}, starredOnly: true}
+		requireQueryJSON(t, `{"must": {"conjuncts": [{"query": "Name:\"a\" Name:\"b\""}]}}`, s.query(nil))
+		requireQueryJSON(t, `{"must": {"conjuncts": [{"query": "cpu"}, {"query": "Name:\"a\" Name:\"b\""}]}}`, s.query(cpu))
+		require.False(t, s.isEmptyFilter())
+		require.True(t, (&searchSignals{starredOnly: true}).isEmptyFilter())
+	})
+
+	t.Run("filters recent dashboards in order of the views", func(t *testing.T) {
+		s := &searchSignals{starred: []string{"a"}, recent: []string{"b", "a"}, recentOnly: true}
+		requireQueryJSON(t, `{
+			"must": {"conjuncts": [{"query": "Name:\"b\" Name:\"a\""}]},
+			"should": {"disjuncts": [{"query": "Name:\"a\"^5"}, {"query": "Name:\"b\"^2 Name:\"a\"^1"}], "min": 0}
+		}`, s.query(nil))
+		require.True(t, (&searchSignals{recentOnly: true}).isEmptyFilter())
+	})
+
+	t.Run("adds the filters as required clauses", func(t *testing.T) {
+		s := &searchSignals{starred: []string{"a"}}
+		requireQueryJSON(t, `{
+			"must": {"conjuncts": [{"query": "cpu"}, {"query": "FolderId:\"f\""}]},
+			"should": {"disjuncts": [{"query": "Name:\"a\"^5"}], "min": 0}
+		}`, s.query(cpu, `FolderId:"f"`))
+		requireQueryJSON(t, `{"must": {"conjuncts": [{"query": "FolderId:\"f\""}]}}`, (&searchSignals{}).query(nil, `FolderId:"f"`))
+	})
+}
+
+func requireQueryJSON(t *testing.T, expected string, q query.Query) {
+	t.Helper()
+	actual, err := json.Marshal(q)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(actual))
+}
+
+func TestSearchUserSignals(t *testing.T) {
+	stars := startest.NewStarServiceFake()
+	stars.ExpectedUserStars = &star.GetUserStarsResult{UserStars: map[string]bool{"b": true, "a": true, "c": false}}
+	s := &SearchConnector{stars: stars}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1}
+
+	signals, err := s.userSignals(context.Background(), user, url.Values{
+		"starred": {"true"},
+		"recent":  {"x,y"},
+	})
+	require.NoError(t, err)
+	require.True(t, signals.starredOnly)
+	require.False(t, signals.recentOnly)
+	require.Equal(t, []string{"a", "b"}, signals.starred)
+	require.Equal(t, []string{"x", "y"}, signals.recent)
+
+	_, err = s.userSignals(context.Background(), user, url.Values{"recent": {`x"y`}})
+	require.Error(t, err)
+}
+
+// folderTree returns the children of the folders by uid
+type folderTree struct {
+	*foldertest.FakeService
+	children map[string][]string
+	denied   map[string]bool
+}
+
+func (f *folderTree) GetChildren(ctx context.Context, q *folder.GetChildrenQuery) ([]*folder.Folder, error) {
+	if f.denied[q.UID] {
+		return nil, dashboards.ErrFolderAccessDenied
+	}
+	children := []*folder.Folder{}
+	for _, uid := range f.children[q.UID] {
+		children = append(children, &folder.Folder{UID: uid})
+	}
+	return children, nil
+}
+
+func TestSearchFolderFilter(t *testing.T) {
+	folders := &folderTree{
+		FakeService: foldertest.NewFakeService(),
+		children: map[string][]string{
+			"a": {"b", "c"},
+			"b": {"d"},
+			"c": {"a"}, // a cycle is only visited once
+		},
+		denied: map[string]bool{"d": true},
+	}
+	folders.ExpectedFolder = &folder.Folder{UID: "a"}
+	s := &SearchConnector{folders: folders}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+
+	filter, err := s.folderFilter(context.Background(), user, url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, "", filter)
+
+	filter, err = s.folderFilter(context.Background(), user, url.Values{"folder": {"a"}})
+	require.NoError(t, err)
+	require.Equal(t, `FolderId:"a"`, filter)
+
+	filter, err = s.folderFilter(context.Background(), user, url.Values{"folder": {"a"}, "recursive": {"true"}})
+	require.NoError(t, err)
+	require.Equal(t, `FolderId:"a" FolderId:"b" FolderId:"c" FolderId:"d"`, filter)
+
+	filter, err = s.folderFilter(context.Background(), user, url.Values{"folder": {folder.GeneralFolderUID}})
+	require.NoError(t, err)
+	require.Equal(t, "-FolderId:/.+/", filter)
+
+	filter, err = s.folderFilter(context.Background(), user, url.Values{"folder": {folder.GeneralFolderUID}, "recursive": {"true"}})
+	require.NoError(t, err)
+	require.Equal(t, "", filter)
+
+	folders.ExpectedError = dashboards.ErrFolderNotFound
+	_, err = s.folderFilter(context.Background(), user, url.Values{"folder": {"missing"}})
+	require.Error(t, err)
+}
+
+func TestSearchKinds(t *testing.T) {
+	s := &SearchConnector{}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Permissions: readAllPermissions}
+
+	req, _, err := s.searchRequest(context.Background(), user, url.Values{"kind": {"Dashboard,Folder"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Dashboard", "Folder"}, req.Kind)
+	require.Nil(t, req.SortBy)
+
+	req, _, err = s.searchRequest(context.Background(), user, url.Values{"types": {"dash,dash-folder,folder"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Dashboard", "Folder"}, req.Kind)
+	require.Equal(t, []string{"-Kind", "-_score"}, req.SortBy, "folders are returned first")
+
+	req, _, err = s.searchRequest(context.Background(), user, url.Values{"types": {"folder"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Folder"}, req.Kind)
+	require.Nil(t, req.SortBy)
+
+	_, _, err = s.searchRequest(context.Background(), user, url.Values{"types": {"playlist"}})
+	require.True(t, apierrors.IsBadRequest(err))
+	_, _, err = s.searchRequest(context.Background(), user, url.Values{"types": {"dash"}, "kind": {"Folder"}})
+	require.True(t, apierrors.IsBadRequest(err))
+}
+
+func TestSearchSort(t *testing.T) {
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, SearchIndexFields()...))
+	require.NoError(t, resource.RegisterIndexFields(dashboardIndexKind, NewDashboardViews(kvstore.NewFakeKVStore(), nil, nil).IndexFields()...))
+	s := &SearchConnector{}
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Permissions: readAllPermissions}
+
+	req, _, err := s.searchRequest(context.Background(), user, url.Values{"sort": {"-views_last_30d"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"-Spec.views_last_30d", "-_score"}, req.SortBy)
+
+	req, _, err = s.searchRequest(context.Background(), user, url.Values{"sort": {"lastViewed, -updated"}, "types": {"dash,folder"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"-Kind", "Spec.last_viewed_at", "-UpdatedAt", "-_score"}, req.SortBy)
+
+	for sort, msg := range map[string]string{
+		"stars":   "stars is not an indexed field",
+		"ds_type": "ds_type can not be sorted",
+		"title":   "title can not be sorted",
+	} {
+		_, _, err = s.searchRequest(context.Background(), user, url.Values{"sort": {sort}})
+		require.True(t, apierrors.IsBadRequest(err), sort)
+		require.ErrorContains(t, err, msg, sort)
+	}
+}
+
+func TestSearchTypedResults(t *testing.T) {
+	value := func(r resource.IndexedResource) []byte {
+		b, err := json.Marshal(r)
+		require.NoError(t, err)
+		return b
+	}
+	res := &resource.SearchResponse{Items: []*resource.ResourceWrapper{
+		{Value: value(resource.IndexedResource{Kind: "Folder", Name: "xyz"})},
+		{Value: value(resource.IndexedResource{Kind: "Dashboard", Name: "abc"})},
+	}}
+	require.NoError(t, typedResults(res))
+
+	types := []string{}
+	for _, item := range res.Items {
+		r := map[string]any{}
+		require.NoError(t, json.Unmarshal(item.Value, &r))
+		types = append(types, r["type"].(string))
+	}
+	require.Equal(t, []string{"dash-folder", "dash-db"}, types)
+}
@@ -0,0 +1,163 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+)
+
+// SpecSizeLimit rejects dashboards with a spec larger than the configured size.
+// Very large dashboards are slow to save and to encode on every read, so they are refused when they are written.
+type SpecSizeLimit struct {
+	maxBytes int64
+}
+
+// NewSpecSizeLimit returns a limit of the spec size in bytes, zero or less disables the limit
+func NewSpecSizeLimit(maxBytes int64) *SpecSizeLimit {
+	return &SpecSizeLimit{maxBytes: maxBytes}
+}
+
+// Validate rejects creates and updates of dashboards with a spec above the limit with 413 Request Entity Too Large
+func (l *SpecSizeLimit) Validate(a admission.Attributes) error {
+	if l == nil || l.maxBytes <= 0 || a.GetObject() == nil {
+		return nil
+	}
+	if a.GetResource().Resource != dashboard.DashboardResourceInfo.GroupResource().Resource || a.GetSubresource() != "" {
+		return nil
+	}
+	if op := a.GetOperation(); op != admission.Create && op != admission.Update {
+		return nil
+	}
+
+	size, err := specSize(a.GetObject(), l.maxBytes)
+	if err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("invalid dashboard spec: %s", err))
+	}
+	if size > l.maxBytes {
+		return apierrors.NewRequestEntityTooLargeError(
+			fmt.Sprintf("the dashboard spec is larger than the maximum size of %d bytes", l.maxBytes))
+	}
+	return nil
+}
+
+// specSize returns the size of the spec encoded as JSON. Unstructured specs are measured by walking them instead of
+// encoding them, the walk stops as soon as the size is above the limit.
+func specSize(obj runtime.Object, limit int64) (int64, error) {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return 0, err
+	}
+	spec, err := meta.GetSpec()
+	if err != nil {
+		return 0, err
+	}
+	if u, ok := spec.(commonV0.Unstructured); ok {
+		spec = u.Object
+	}
+	m := &jsonSizer{limit: limit}
+	return m.size(spec)
+}
+
+type jsonSizer struct {
+	limit int64
+	n     int64
+	buf   [64]byte
+}
+
+// size adds the size of the JSON encoding of v. It matches encoding/json for the values of unstructured objects,
+// except for floats encoded with an exponent, other values are encoded to measure them.
+func (m *jsonSizer) size(v any) (int64, error) {
+	if m.limit > 0 && m.n > m.limit {
+		return m.n, nil
+	}
+	switch t := v.(type) {
+	case nil:
+		m.n += 4
+	case bool:
+		if t {
+			m.n += 4
+		} else {
+			m.n += 5
+		}
+	case string:
+		m.n += quotedLen(t)
+	case int64:
+		m.n += int64(len(strconv.AppendInt(m.buf[:0], t, 10)))
+	case int:
+		m.n += int64(len(strconv.AppendInt(m.buf[:0], int64(t), 10)))
+	case float64:
+		m.n += int64(len(strconv.AppendFloat(m.buf[:0], t, 'f', -1, 64)))
+	case json.Number:
+		m.n += int64(len(t))
+	case map[string]any:
+		m.n += 2 // {}
+		first := true
+		for k, val := range t {
+			if !first {
+				m.n++ // ,
+			}
+			first = false
+			m.n += quotedLen(k) + 1 // "key":
+			if _, err := m.size(val); err != nil {
+				return m.n, err
+			}
+		}
+	case []any:
+		m.n += 2 // []
+		for i, val := range t {
+			if i > 0 {
+				m.n++ // ,
+			}
+			if _, err := m.size(val); err != nil {
+				return m.n, err
+			}
+		}
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return m.n, err
+		}
+		m.n += int64(len(b))
+	}
+	return m.n, nil
+}
+
+// quotedLen is the length of s as a JSON string, with the escaping of encoding/json
+func quotedLen(s string) int64 {
+	n := int64(2)
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\' || b == '\n' || b == '\r' || b == '\t':
+				n += 2
+			case b < 0x20 || b == '<' || b == '>' || b == '&':
+				n += 6 // \u00XX
+			default:
+				n++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			n += 6 // \ufffd
+		case r == '\u2028' || r == '\u2029':
+			n += 6 // escaped for JavaScript
+		default:
+			n += int64(size)
+		}
+		i += size
+	}
+	return n
+}
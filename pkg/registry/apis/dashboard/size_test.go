@@ -0,0 +1,70 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestSpecSize(t *testing.T) {
+	spec := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "Quotes \" and \\ <html> & tabs\t, unicode ✓ and \u2028",
+		"version": 3,
+		"ratio": 0.25,
+		"editable": true,
+		"links": null,
+		"panels": [{"id": 1, "targets": [{"expr": "rate(up{job=\"a\"}[5m])"}]}, {"id": 2}],
+		"templating": {"list": []}
+	}`), &spec))
+	expected, err := json.Marshal(spec)
+	require.NoError(t, err)
+
+	dash := &dashboardv0alpha1.Dashboard{Spec: commonV0.Unstructured{Object: spec}}
+	size, err := specSize(dash, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(expected)), size)
+}
+
+func TestSpecSizeLimit(t *testing.T) {
+	limit := NewSpecSizeLimit(100)
+	validate := func(op admission.Operation, subresource string, obj runtime.Object) error {
+		return limit.Validate(admission.NewAttributesRecord(
+			obj,
+			nil,
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(),
+			"default",
+			"abc",
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionResource(),
+			subresource,
+			op,
+			nil,
+			false,
+			&user.SignedInUser{},
+		))
+	}
+	small := &dashboardv0alpha1.Dashboard{Spec: commonV0.Unstructured{Object: map[string]any{"title": "small"}}}
+	large := &dashboardv0alpha1.Dashboard{Spec: commonV0.Unstructured{Object: map[string]any{"title": strings.Repeat("x", 200)}}}
+
+	require.NoError(t, validate(admission.Create, "", small))
+	require.NoError(t, validate(admission.Delete, "", large))
+	require.NoError(t, validate(admission.Update, "status", large))
+
+	err := validate(admission.Update, "", large)
+	require.True(t, apierrors.IsRequestEntityTooLargeError(err))
+	require.Equal(t, int32(http.StatusRequestEntityTooLarge), err.(apierrors.APIStatus).Status().Code)
+
+	require.NoError(t, NewSpecSizeLimit(0).Validate(admission.NewAttributesRecord(large, nil,
+		dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(), "default", "abc",
+		dashboardv0alpha1.DashboardResourceInfo.GroupVersionResource(), "", admission.Create, nil, false, &user.SignedInUser{})))
+}
@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+const (
+	snapshotGCInterval  = 10 * time.Minute
+	snapshotGCBatchSize = 100
+)
+
+// SnapshotGarbageCollector removes dashboard snapshots once they expire.
+// Snapshots published externally are removed from the external server as well.
+type SnapshotGarbageCollector struct {
+	service        dashboardsnapshots.Service
+	features       featuremgmt.FeatureToggles
+	deleteExternal func(url string) error
+	log            log.Logger
+}
+
+func ProvideSnapshotGarbageCollector(service dashboardsnapshots.Service, features featuremgmt.FeatureToggles) *SnapshotGarbageCollector {
+	return &SnapshotGarbageCollector{
+		service:        service,
+		features:       features,
+		deleteExternal: dashboardsnapshots.DeleteExternalDashboardSnapshot,
+		log:            log.New("grafana-apiserver.dashboards.snapshots.gc"),
+	}
+}
+
+// IsDisabled returns true when the snapshot resource is not registered
+func (gc *SnapshotGarbageCollector) IsDisabled() bool {
+	return !gc.features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) &&
+		!gc.features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI)
+}
+
+func (gc *SnapshotGarbageCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(snapshotGCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := gc.collect(ctx)
+			if err != nil {
+				gc.log.Error("Failed to delete expired snapshots", "err", err)
+			}
+			if deleted > 0 {
+				gc.log.Debug("Deleted expired snapshots", "count", deleted)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// collect deletes expired snapshots in batches until none are left.
+// Failing to delete the external copy does not keep the snapshot, the external server expires it on its own.
+func (gc *SnapshotGarbageCollector) collect(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		expired, err := gc.service.GetExpiredDashboardSnapshots(ctx, &dashboardsnapshots.GetExpiredDashboardSnapshotsQuery{
+			Limit: snapshotGCBatchSize,
+		})
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, snap := range expired {
+			if snap.External && snap.ExternalDeleteURL != "" {
+				if err := gc.deleteExternal(snap.ExternalDeleteURL); err != nil {
+					gc.log.Warn("Failed to delete external snapshot", "key", snap.Key, "orgId", snap.OrgID, "err", err)
+				}
+			}
+			err := gc.service.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{
+				DeleteKey: snap.DeleteKey,
+			})
+			if err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(expired) < snapshotGCBatchSize || ctx.Err() != nil {
+			return deleted, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+)
+
+func TestSnapshotGarbageCollector(t *testing.T) {
+	t.Run("deletes expired snapshots and their external copies", func(t *testing.T) {
+		svc := dashboardsnapshots.NewMockService(t)
+		svc.On("GetExpiredDashboardSnapshots", mock.Anything, mock.Anything).Return([]*dashboardsnapshots.DashboardSnapshot{
+			{Key: "local", DeleteKey: "delete-local"},
+			{Key: "external", DeleteKey: "delete-external", External: true, ExternalDeleteURL: "http://snapshots/delete"},
+		}, nil).Once()
+		svc.On("DeleteDashboardSnapshot", mock.Anything, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "delete-local"}).Return(nil).Once()
+		svc.On("DeleteDashboardSnapshot", mock.Anything, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "delete-external"}).Return(nil).Once()
+
+		var external []string
+		gc := &SnapshotGarbageCollector{
+			service: svc,
+			deleteExternal: func(url string) error {
+				external = append(external, url)
+				return errors.New("external server is down")
+			},
+			log: log.NewNopLogger(),
+		}
+
+		deleted, err := gc.collect(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+		require.Equal(t, []string{"http://snapshots/delete"}, external)
+	})
+
+	t.Run("continues with the next batch when the batch is full", func(t *testing.T) {
+		full := make([]*dashboardsnapshots.DashboardSnapshot, snapshotGCBatchSize)
+		for i := range full {
+			full[i] = &dashboardsnapshots.DashboardSnapshot{DeleteKey: "key"}
+		}
+		svc := dashboardsnapshots.NewMockService(t)
+		svc.On("GetExpiredDashboardSnapshots", mock.Anything, mock.Anything).Return(full, nil).Once()
+		svc.On("GetExpiredDashboardSnapshots", mock.Anything, mock.Anything).Return([]*dashboardsnapshots.DashboardSnapshot{}, nil).Once()
+		svc.On("DeleteDashboardSnapshot", mock.Anything, mock.Anything).Return(nil).Times(snapshotGCBatchSize)
+
+		gc := &SnapshotGarbageCollector{service: svc, log: log.NewNopLogger()}
+		deleted, err := gc.collect(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, snapshotGCBatchSize, deleted)
+	})
+}
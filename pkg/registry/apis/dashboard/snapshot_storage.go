@@ -0,0 +1,405 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	_ rest.Scoper               = (*SnapshotStore)(nil)
+	_ rest.SingularNameProvider = (*SnapshotStore)(nil)
+	_ rest.Getter               = (*SnapshotStore)(nil)
+	_ rest.Lister               = (*SnapshotStore)(nil)
+	_ rest.Creater              = (*SnapshotStore)(nil)
+	_ rest.GracefulDeleter      = (*SnapshotStore)(nil)
+	_ rest.Storage              = (*SnapshotStore)(nil)
+)
+
+var snapshotResourceInfo = dashboardv0alpha1.DashboardSnapshotResourceInfo
+
+// SnapshotStore exposes the legacy dashboard snapshots as a resource.
+// Snapshots are immutable, they can only be created, read and deleted.
+// The snapshots are not checked by the authorizer, every request requires the snapshots action of its verb.
+type SnapshotStore struct {
+	service       dashboardsnapshots.Service
+	accessControl accesscontrol.AccessControl
+	namespacer    request.NamespaceMapper
+	cfg           *setting.Cfg
+}
+
+func NewSnapshotStore(service dashboardsnapshots.Service, accessControl accesscontrol.AccessControl, cfg *setting.Cfg) *SnapshotStore {
+	return &SnapshotStore{
+		service:       service,
+		accessControl: accessControl,
+		namespacer:    request.GetNamespaceMapper(cfg),
+		cfg:           cfg,
+	}
+}
+
+func (s *SnapshotStore) New() runtime.Object {
+	return snapshotResourceInfo.NewFunc()
+}
+
+func (s *SnapshotStore) Destroy() {}
+
+func (s *SnapshotStore) NamespaceScoped() bool {
+	return true // namespace == org
+}
+
+func (s *SnapshotStore) GetSingularName() string {
+	return snapshotResourceInfo.GetSingularName()
+}
+
+func (s *SnapshotStore) NewList() runtime.Object {
+	return snapshotResourceInfo.NewListFunc()
+}
+
+func (s *SnapshotStore) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return snapshotResourceInfo.TableConverter().ConvertToTable(ctx, object, tableOptions)
+}
+
+func (s *SnapshotStore) checkEnabled(name string) error {
+	if !s.cfg.SnapshotEnabled {
+		return apierrors.NewForbidden(snapshotResourceInfo.GroupResource(), name, fmt.Errorf("dashboard snapshots are disabled"))
+	}
+	return nil
+}
+
+// authorize returns the user of the request when they have the snapshots action
+func (s *SnapshotStore) authorize(ctx context.Context, action string, name string) (identity.Requester, error) {
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action))
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apierrors.NewForbidden(snapshotResourceInfo.GroupResource(), name, fmt.Errorf("missing permission %s", action))
+	}
+	return user, nil
+}
+
+// List returns all snapshots in the org for admins, and the snapshots created by the user for everyone else.
+// The dashboard body is not included.
+func (s *SnapshotStore) List(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	user, err := s.authorize(ctx, dashboards.ActionSnapshotsRead, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEnabled(""); err != nil {
+		return nil, err
+	}
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 1000
+	if options.Limit > 0 {
+		limit = int(options.Limit)
+	}
+	res, err := s.service.SearchDashboardSnapshots(ctx, &dashboardsnapshots.GetDashboardSnapshotsQuery{
+		OrgID:        info.OrgID,
+		SignedInUser: user,
+		Limit:        limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := &dashboardv0alpha1.DashboardSnapshotList{}
+	for _, v := range res {
+		snap, err := s.toResource(&dashboardsnapshots.DashboardSnapshot{
+			Name:        v.Name,
+			Key:         v.Key,
+			OrgID:       v.OrgID,
+			External:    v.External,
+			ExternalURL: v.ExternalURL,
+			Expires:     v.Expires,
+			Created:     v.Created,
+			Updated:     v.Updated,
+		})
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, *snap)
+	}
+	return list, nil
+}
+
+func (s *SnapshotStore) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	if _, err := s.authorize(ctx, dashboards.ActionSnapshotsRead, name); err != nil {
+		return nil, err
+	}
+	if err := s.checkEnabled(name); err != nil {
+		return nil, err
+	}
+	snap, err := s.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResource(snap)
+}
+
+func (s *SnapshotStore) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	snap, ok := obj.(*dashboardv0alpha1.DashboardSnapshot)
+	if !ok {
+		return nil, apierrors.NewBadRequest("expected a dashboard snapshot")
+	}
+	user, err := s.authorize(ctx, dashboards.ActionSnapshotsCreate, snap.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEnabled(snap.Name); err != nil {
+		return nil, err
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Spec.Expires < 0 {
+		return nil, apierrors.NewBadRequest("expires must not be negative")
+	}
+
+	// The snapshot is a copy of an existing dashboard the user can see
+	uid := snap.Spec.Dashboard.GetNestedString("uid")
+	if !util.IsValidShortUID(uid) {
+		return nil, apierrors.NewBadRequest("invalid dashboard UID")
+	}
+	if err := s.service.ValidateDashboardExists(ctx, info.OrgID, uid); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewBadRequest("dashboard not found")
+		}
+		return nil, err
+	}
+	g, err := guardian.NewByUID(ctx, uid, info.OrgID, user)
+	if err != nil {
+		return nil, err
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return nil, apierrors.NewForbidden(snapshotResourceInfo.GroupResource(), snap.Name, fmt.Errorf("can not view dashboard"))
+	}
+
+	title := snap.Spec.Title
+	if title == "" {
+		title = "Unnamed snapshot"
+	}
+	cmd := &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Name:      title,
+			Dashboard: snap.Spec.Dashboard.DeepCopy(),
+			Expires:   snap.Spec.Expires,
+			External:  snap.Spec.External,
+		},
+		Key:   snap.Name,
+		OrgID: info.OrgID,
+	}
+	cmd.UserID, _ = identity.UserIdentifier(user.GetID())
+	if cmd.Key == "" {
+		if cmd.Key, err = util.GetRandomString(32); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.DeleteKey, err = util.GetRandomString(32); err != nil {
+		return nil, err
+	}
+
+	if cmd.External {
+		if !s.cfg.ExternalEnabled {
+			return nil, apierrors.NewForbidden(snapshotResourceInfo.GroupResource(), snap.Name, fmt.Errorf("external dashboard snapshots are disabled"))
+		}
+		if err := dashboardsnapshots.PublishExternalDashboardSnapshot(cmd, s.cfg.ExternalSnapshotUrl); err != nil {
+			return nil, apierrors.NewInternalError(fmt.Errorf("failed to create external snapshot: %w", err))
+		}
+	} else {
+		cmd.Dashboard.SetNestedField("/d/"+uid, "snapshot", "originalUrl")
+		metrics.MApiDashboardSnapshotCreate.Inc()
+	}
+
+	created, err := s.service.CreateDashboardSnapshot(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.toResource(created)
+	if err != nil {
+		return nil, err
+	}
+	out.Spec.Dashboard = *cmd.Dashboard
+	out.Status.OriginalURL = out.Spec.Dashboard.GetNestedString("snapshot", "originalUrl")
+	return out, nil
+}
+
+// Delete removes the snapshot, and its copy on the external server when it was published externally.
+// It requires the snapshots:delete permission, then like the legacy API, only the creator or users that can edit the
+// original dashboard may delete a snapshot.
+func (s *SnapshotStore) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	user, err := s.authorize(ctx, dashboards.ActionSnapshotsDelete, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.checkEnabled(name); err != nil {
+		return nil, false, err
+	}
+	snap, err := s.get(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	obj, err := s.toResource(snap)
+	if err != nil {
+		return nil, false, err
+	}
+	if deleteValidation != nil {
+		if err := deleteValidation(ctx, obj); err != nil {
+			return nil, false, err
+		}
+	}
+
+	userID, _ := identity.UserIdentifier(user.GetID())
+	if snap.UserID == 0 || snap.UserID != userID {
+		canEdit, err := s.canEditDashboard(ctx, user, snap)
+		if err != nil {
+			return nil, false, err
+		}
+		if !canEdit {
+			return nil, false, apierrors.NewForbidden(snapshotResourceInfo.GroupResource(), name, fmt.Errorf("access denied to this snapshot"))
+		}
+	}
+
+	if snap.External && snap.ExternalDeleteURL != "" {
+		if err := dashboardsnapshots.DeleteExternalDashboardSnapshot(snap.ExternalDeleteURL); err != nil {
+			return nil, false, apierrors.NewInternalError(fmt.Errorf("failed to delete external snapshot: %w", err))
+		}
+	}
+
+	err = s.service.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{
+		DeleteKey: snap.DeleteKey,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return obj, true, nil
+}
+
+// get returns the snapshot if it belongs to the org of the request
+func (s *SnapshotStore) get(ctx context.Context, name string) (*dashboardsnapshots.DashboardSnapshot, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := s.service.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: name})
+	if err != nil {
+		if errors.Is(err, dashboardsnapshots.ErrBaseNotFound) {
+			return nil, apierrors.NewNotFound(snapshotResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+	if snap == nil || snap.OrgID != info.OrgID {
+		return nil, apierrors.NewNotFound(snapshotResourceInfo.GroupResource(), name)
+	}
+	return snap, nil
+}
+
+// canEditDashboard checks the permissions on the dashboard the snapshot was taken from.
+// Snapshots of dashboards that no longer exist can be deleted by anyone in the org, while
+// snapshots without a dashboard (published externally) can only be deleted by their creator.
+func (s *SnapshotStore) canEditDashboard(ctx context.Context, user identity.Requester, snap *dashboardsnapshots.DashboardSnapshot) (bool, error) {
+	if snap.Dashboard == nil {
+		return false, nil
+	}
+	uid := snap.Dashboard.Get("uid").MustString()
+	if uid == "" {
+		return false, nil
+	}
+	g, err := guardian.NewByUID(ctx, uid, snap.OrgID, user)
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	canEdit, err := g.CanEdit()
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return true, nil
+	}
+	return canEdit, err
+}
+
+func (s *SnapshotStore) toResource(v *dashboardsnapshots.DashboardSnapshot) (*dashboardv0alpha1.DashboardSnapshot, error) {
+	status := &dashboardv0alpha1.DashboardSnapshotStatus{
+		URL:         setting.ToAbsUrl("dashboard/snapshot/" + v.Key),
+		ExternalURL: v.ExternalURL,
+	}
+	if v.External {
+		status.URL = v.ExternalURL
+	}
+
+	// Snapshots that never expire are stored with an expiry date far in the future
+	var expires int64
+	if v.Expires.Before(time.Date(2070, time.January, 0, 0, 0, 0, 0, time.UTC)) {
+		status.ExpiresAt = v.Expires.UnixMilli()
+		expires = int64(v.Expires.Sub(v.Created).Round(time.Second).Seconds())
+	}
+
+	snap := &dashboardv0alpha1.DashboardSnapshot{
+		TypeMeta: snapshotResourceInfo.TypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              v.Key,
+			Namespace:         s.namespacer(v.OrgID),
+			ResourceVersion:   fmt.Sprintf("%d", v.Updated.UnixMilli()),
+			CreationTimestamp: metav1.NewTime(v.Created),
+		},
+		Spec: dashboardv0alpha1.DashboardSnapshotSpec{
+			Title:    v.Name,
+			Expires:  expires,
+			External: v.External,
+		},
+		Status: status,
+	}
+
+	if v.Dashboard != nil {
+		body, err := v.Dashboard.Map()
+		if err != nil {
+			return nil, err
+		}
+		snap.Spec.Dashboard = common.Unstructured{Object: body}
+		status.OriginalURL = snap.Spec.Dashboard.GetNestedString("snapshot", "originalUrl")
+	}
+
+	if v.Updated != v.Created {
+		meta, err := utils.MetaAccessor(snap)
+		if err != nil {
+			return nil, err
+		}
+		meta.SetUpdatedTimestamp(&v.Updated)
+	}
+	return snap, nil
+}
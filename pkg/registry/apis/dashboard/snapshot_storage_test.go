@@ -0,0 +1,69 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSnapshotStorePermissions(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.SnapshotEnabled = true
+	ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	withUser := func(permissions ...string) context.Context {
+		user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {}}}
+		for _, action := range permissions {
+			user.Permissions[1][action] = []string{}
+		}
+		return k8srequest.WithNamespace(identity.WithRequester(context.Background(), user), "default")
+	}
+
+	t.Run("denies the requests without the snapshots actions before reading the snapshots", func(t *testing.T) {
+		// the service is not called
+		store := NewSnapshotStore(dashboardsnapshots.NewMockService(t), ac, cfg)
+		ctx := withUser()
+
+		_, err := store.Create(ctx, &dashboardv0alpha1.DashboardSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap"}}, nil, &metav1.CreateOptions{})
+		require.True(t, apierrors.IsForbidden(err), err)
+		_, err = store.Get(ctx, "snap", &metav1.GetOptions{})
+		require.True(t, apierrors.IsForbidden(err), err)
+		_, err = store.List(ctx, &internalversion.ListOptions{})
+		require.True(t, apierrors.IsForbidden(err), err)
+		_, _, err = store.Delete(ctx, "snap", nil, &metav1.DeleteOptions{})
+		require.True(t, apierrors.IsForbidden(err), err)
+	})
+
+	t.Run("checks the action of the verb", func(t *testing.T) {
+		svc := dashboardsnapshots.NewMockService(t)
+		svc.On("GetDashboardSnapshot", mock.Anything, mock.Anything).Return(nil, dashboardsnapshots.ErrBaseNotFound)
+		svc.On("ValidateDashboardExists", mock.Anything, int64(1), mock.Anything).Return(dashboards.ErrDashboardNotFound)
+		store := NewSnapshotStore(svc, ac, cfg)
+
+		_, err := store.Get(withUser(dashboards.ActionSnapshotsRead), "snap", &metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err), err)
+		_, _, err = store.Delete(withUser(dashboards.ActionSnapshotsRead), "snap", nil, &metav1.DeleteOptions{})
+		require.True(t, apierrors.IsForbidden(err), err)
+		_, _, err = store.Delete(withUser(dashboards.ActionSnapshotsDelete), "snap", nil, &metav1.DeleteOptions{})
+		require.True(t, apierrors.IsNotFound(err), err)
+
+		// the dashboard is read once the user can create snapshots
+		_, err = store.Create(withUser(dashboards.ActionSnapshotsCreate), &dashboardv0alpha1.DashboardSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap"}}, nil, &metav1.CreateOptions{})
+		require.True(t, apierrors.IsBadRequest(err), err)
+	})
+}
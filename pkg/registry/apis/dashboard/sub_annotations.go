@@ -0,0 +1,411 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+const defaultAnnotationsLimit = 100
+
+var annotationsGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "annotations"}
+
+// DashboardAnnotation is an annotation of a dashboard as returned by the annotations subresource
+type DashboardAnnotation struct {
+	ID      int64            `json:"id"`
+	PanelID int64            `json:"panelId,omitempty"`
+	Time    int64            `json:"time"`
+	TimeEnd int64            `json:"timeEnd,omitempty"`
+	Text    string           `json:"text"`
+	Tags    []string         `json:"tags,omitempty"`
+	Data    *simplejson.Json `json:"data,omitempty"`
+	Login   string           `json:"login,omitempty"`
+	Created int64            `json:"created"`
+	Updated int64            `json:"updated"`
+}
+
+type DashboardAnnotationList struct {
+	Items []DashboardAnnotation `json:"items"`
+}
+
+// DashboardAnnotationCommand is the body used to create or patch an annotation.
+// When patching, only the fields that are set are changed.
+type DashboardAnnotationCommand struct {
+	PanelID *int64           `json:"panelId,omitempty"`
+	Time    *int64           `json:"time,omitempty"`
+	TimeEnd *int64           `json:"timeEnd,omitempty"`
+	Text    *string          `json:"text,omitempty"`
+	Tags    []string         `json:"tags,omitempty"`
+	Data    *simplejson.Json `json:"data,omitempty"`
+}
+
+// The annotations subresource reads and writes annotations of a single dashboard.
+// Writes are allowed according to the dashboard annotation permissions returned in the access info of the DTO.
+type AnnotationsConnector struct {
+	dashboards    dashboards.DashboardService
+	repo          annotations.Repository
+	accessControl accesscontrol.AccessControl
+	newFunc       func() runtime.Object
+	log           log.Logger
+}
+
+func NewAnnotationsConnector(
+	dashboardService dashboards.DashboardService,
+	repo annotations.Repository,
+	accessControl accesscontrol.AccessControl,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &AnnotationsConnector{
+		dashboards:    dashboardService,
+		repo:          repo,
+		accessControl: accessControl,
+		newFunc:       newFunc,
+		log:           log.New("grafana-apiserver.dashboards.annotations"),
+	}
+}
+
+var (
+	_ rest.Connecter       = (*AnnotationsConnector)(nil)
+	_ rest.StorageMetadata = (*AnnotationsConnector)(nil)
+)
+
+func (r *AnnotationsConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *AnnotationsConnector) Destroy() {
+}
+
+func (r *AnnotationsConnector) ConnectMethods() []string {
+	return []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+}
+
+func (r *AnnotationsConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, true, "" // the trailing path is the annotation id
+}
+
+func (r *AnnotationsConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *AnnotationsConnector) ProducesObject(verb string) interface{} {
+	return &DashboardAnnotationList{}
+}
+
+func (r *AnnotationsConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+
+	guardian, err := guardian.NewByDashboard(ctx, dash, info.OrgID, user)
+	if err != nil {
+		return nil, err
+	}
+	canView, err := guardian.CanView()
+	if err != nil || !canView {
+		return nil, apierrors.NewForbidden(dashboard.DashboardResourceInfo.GroupResource(), name, fmt.Errorf("not allowed to view"))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, err := annotationIDFromPath(req.URL.Path)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		switch {
+		case req.Method == http.MethodGet && id == 0:
+			r.list(w, req, responder, user, dash)
+		case req.Method == http.MethodGet:
+			item, err := r.get(req.Context(), user, dash, id)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, toDashboardAnnotation(item), responder)
+		case req.Method == http.MethodPost && id == 0:
+			r.create(w, req, responder, user, dash)
+		case req.Method == http.MethodPatch && id != 0:
+			r.patch(w, req, responder, user, dash, id)
+		case req.Method == http.MethodDelete && id != 0:
+			r.delete(w, req, responder, user, dash, id)
+		default:
+			responder.Error(apierrors.NewMethodNotSupported(annotationsGroupResource, req.Method))
+		}
+	}), nil
+}
+
+func (r *AnnotationsConnector) list(w http.ResponseWriter, req *http.Request, responder rest.Responder, user identity.Requester, dash *dashboards.Dashboard) {
+	params := req.URL.Query()
+	query := &annotations.ItemQuery{
+		OrgID:        dash.OrgID,
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		Tags:         params["tags"],
+		MatchAny:     params.Get("matchAny") == "true",
+		Limit:        defaultAnnotationsLimit,
+		SignedInUser: user,
+	}
+	var err error
+	for key, dst := range map[string]*int64{"from": &query.From, "to": &query.To, "limit": &query.Limit, "panelId": &query.PanelID} {
+		if v := params.Get(key); v != "" {
+			if *dst, err = strconv.ParseInt(v, 10, 64); err != nil {
+				responder.Error(apierrors.NewBadRequest(fmt.Sprintf("invalid %s parameter: %s", key, err)))
+				return
+			}
+		}
+	}
+
+	items, err := r.repo.Find(req.Context(), query)
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+	list := &DashboardAnnotationList{Items: make([]DashboardAnnotation, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, toDashboardAnnotation(item))
+	}
+	writeJSON(w, http.StatusOK, list, responder)
+}
+
+func (r *AnnotationsConnector) create(w http.ResponseWriter, req *http.Request, responder rest.Responder, user identity.Requester, dash *dashboards.Dashboard) {
+	if !r.canPerform(req.Context(), user, accesscontrol.ActionAnnotationsCreate) {
+		responder.Error(apierrors.NewForbidden(annotationsGroupResource, dash.UID, fmt.Errorf("not allowed to add annotations")))
+		return
+	}
+
+	cmd := DashboardAnnotationCommand{}
+	if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+		responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+		return
+	}
+	if cmd.Text == nil || *cmd.Text == "" {
+		responder.Error(apierrors.NewBadRequest("text field should not be empty"))
+		return
+	}
+
+	userID, _ := identity.UserIdentifier(user.GetID())
+	item := annotations.Item{
+		OrgID:       dash.OrgID,
+		UserID:      userID,
+		DashboardID: dash.ID,
+		Text:        *cmd.Text,
+		Tags:        cmd.Tags,
+		Data:        cmd.Data,
+	}
+	if cmd.PanelID != nil {
+		item.PanelID = *cmd.PanelID
+	}
+	if cmd.Time != nil {
+		item.Epoch = *cmd.Time
+	}
+	if cmd.TimeEnd != nil {
+		item.EpochEnd = *cmd.TimeEnd
+	}
+
+	if err := r.repo.Save(req.Context(), &item); err != nil {
+		if errors.Is(err, annotations.ErrTimerangeMissing) {
+			responder.Error(apierrors.NewBadRequest(err.Error()))
+			return
+		}
+		responder.Error(err)
+		return
+	}
+
+	created, err := r.get(req.Context(), user, dash, item.ID)
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toDashboardAnnotation(created), responder)
+}
+
+func (r *AnnotationsConnector) patch(w http.ResponseWriter, req *http.Request, responder rest.Responder, user identity.Requester, dash *dashboards.Dashboard, id int64) {
+	if !r.canPerform(req.Context(), user, accesscontrol.ActionAnnotationsWrite) {
+		responder.Error(apierrors.NewForbidden(annotationsGroupResource, strconv.FormatInt(id, 10), fmt.Errorf("not allowed to edit annotations")))
+		return
+	}
+
+	cmd := DashboardAnnotationCommand{}
+	if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+		responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+		return
+	}
+
+	existing, err := r.get(req.Context(), user, dash, id)
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+
+	userID, _ := identity.UserIdentifier(user.GetID())
+	item := annotations.Item{
+		ID:          id,
+		OrgID:       dash.OrgID,
+		UserID:      userID,
+		DashboardID: dash.ID,
+		PanelID:     existing.PanelID,
+		Epoch:       existing.Time,
+		EpochEnd:    existing.TimeEnd,
+		Text:        existing.Text,
+		Tags:        existing.Tags,
+		Data:        existing.Data,
+	}
+	if cmd.Text != nil && *cmd.Text != "" {
+		item.Text = *cmd.Text
+	}
+	if cmd.Time != nil && *cmd.Time > 0 {
+		item.Epoch = *cmd.Time
+	}
+	if cmd.TimeEnd != nil && *cmd.TimeEnd > 0 {
+		item.EpochEnd = *cmd.TimeEnd
+	}
+	if cmd.Tags != nil {
+		item.Tags = cmd.Tags
+	}
+	if cmd.Data != nil {
+		item.Data = cmd.Data
+	}
+
+	if err := r.repo.Update(req.Context(), &item); err != nil {
+		responder.Error(err)
+		return
+	}
+
+	updated, err := r.get(req.Context(), user, dash, id)
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toDashboardAnnotation(updated), responder)
+}
+
+func (r *AnnotationsConnector) delete(w http.ResponseWriter, req *http.Request, responder rest.Responder, user identity.Requester, dash *dashboards.Dashboard, id int64) {
+	if !r.canPerform(req.Context(), user, accesscontrol.ActionAnnotationsDelete) {
+		responder.Error(apierrors.NewForbidden(annotationsGroupResource, strconv.FormatInt(id, 10), fmt.Errorf("not allowed to delete annotations")))
+		return
+	}
+
+	// Make sure the annotation belongs to this dashboard
+	if _, err := r.get(req.Context(), user, dash, id); err != nil {
+		responder.Error(err)
+		return
+	}
+
+	err := r.repo.Delete(req.Context(), &annotations.DeleteParams{
+		OrgID: dash.OrgID,
+		ID:    id,
+	})
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// get returns the annotation with the given id if it belongs to the dashboard
+func (r *AnnotationsConnector) get(ctx context.Context, user identity.Requester, dash *dashboards.Dashboard, id int64) (*annotations.ItemDTO, error) {
+	items, err := r.repo.Find(ctx, &annotations.ItemQuery{
+		OrgID:        dash.OrgID,
+		AnnotationID: id,
+		SignedInUser: user,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 || items[0].DashboardID != dash.ID {
+		return nil, apierrors.NewNotFound(annotationsGroupResource, strconv.FormatInt(id, 10))
+	}
+	return items[0], nil
+}
+
+// canPerform evaluates the same permissions that are returned as dashboard annotation permissions in the access info
+func (r *AnnotationsConnector) canPerform(ctx context.Context, user identity.Requester, action string) bool {
+	ok, err := r.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action, accesscontrol.ScopeAnnotationsTypeDashboard))
+	if err != nil {
+		r.log.Warn("Failed to evaluate permission", "err", err, "action", action, "scope", accesscontrol.ScopeAnnotationsTypeDashboard)
+		return false
+	}
+	return ok
+}
+
+// annotationIDFromPath returns the annotation id following the annotations subresource, or zero if there is none
+func annotationIDFromPath(path string) (int64, error) {
+	idx := strings.LastIndex(path, "/annotations")
+	if idx < 0 {
+		return 0, apierrors.NewBadRequest("expected annotations path")
+	}
+	raw := strings.Trim(path[idx+len("/annotations"):], "/")
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, apierrors.NewBadRequest(fmt.Sprintf("invalid annotation id: %q", raw))
+	}
+	return id, nil
+}
+
+func toDashboardAnnotation(item *annotations.ItemDTO) DashboardAnnotation {
+	return DashboardAnnotation{
+		ID:      item.ID,
+		PanelID: item.PanelID,
+		Time:    item.Time,
+		TimeEnd: item.TimeEnd,
+		Text:    item.Text,
+		Tags:    item.Tags,
+		Data:    item.Data,
+		Login:   item.Login,
+		Created: item.Created,
+		Updated: item.Updated,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any, responder rest.Responder) {
+	jj, err := json.Marshal(body)
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(jj)
+}
@@ -0,0 +1,32 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationIDFromPath(t *testing.T) {
+	base := "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/annotations"
+
+	id, err := annotationIDFromPath(base)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), id)
+
+	id, err = annotationIDFromPath(base + "/")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), id)
+
+	id, err = annotationIDFromPath(base + "/42")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), id)
+
+	_, err = annotationIDFromPath(base + "/abc")
+	require.Error(t, err)
+
+	_, err = annotationIDFromPath(base + "/-1")
+	require.Error(t, err)
+
+	_, err = annotationIDFromPath("/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/dto")
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// The dependencies subresource lists the data sources, library panels and panel plugins a dashboard depends on,
+// and whether they exist, so the import and export tools and health checks can find broken references:
+//
+//	GET .../dependencies
+//
+// The response is broken when one of the dependencies does not exist.
+type DependenciesConnector struct {
+	dashboards dashboards.DashboardService
+	resolver   *DependencyResolver
+	newFunc    func() runtime.Object
+}
+
+func NewDependenciesConnector(
+	dashboardService dashboards.DashboardService,
+	resolver *DependencyResolver,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &DependenciesConnector{
+		dashboards: dashboardService,
+		resolver:   resolver,
+		newFunc:    newFunc,
+	}
+}
+
+var (
+	_ rest.Connecter       = (*DependenciesConnector)(nil)
+	_ rest.StorageMetadata = (*DependenciesConnector)(nil)
+)
+
+func (r *DependenciesConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *DependenciesConnector) Destroy() {
+}
+
+func (r *DependenciesConnector) ConnectMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func (r *DependenciesConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *DependenciesConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *DependenciesConnector) ProducesObject(verb string) interface{} {
+	return &DashboardDependencies{}
+}
+
+func (r *DependenciesConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		dash, err := r.dashboards.GetDashboard(req.Context(), &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				err = apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+			}
+			responder.Error(err)
+			return
+		}
+		spec := map[string]any{}
+		if dash.Data != nil {
+			spec, _ = dash.Data.Interface().(map[string]any)
+		}
+
+		deps, err := r.resolver.Resolve(req.Context(), user, spec)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		writeJSON(w, http.StatusOK, deps, responder)
+	}), nil
+}
@@ -2,10 +2,12 @@ package dashboard
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/registry/rest"
@@ -20,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/storage/unified/apistore"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
@@ -32,6 +35,8 @@ type DTOConnector struct {
 	unified       resource.ResourceClient
 	largeObjects  apistore.LargeObjectSupport
 	accessControl accesscontrol.AccessControl
+	folders       folder.Service
+	views         *DashboardViews
 	scheme        *runtime.Scheme
 	newFunc       func() runtime.Object
 	log           log.Logger
@@ -43,6 +48,8 @@ func NewDTOConnector(
 	legacyAccess legacy.DashboardAccess,
 	resourceClient resource.ResourceClient,
 	accessControl accesscontrol.AccessControl,
+	folders folder.Service,
+	views *DashboardViews,
 	scheme *runtime.Scheme,
 	newFunc func() runtime.Object,
 ) (rest.Storage, error) {
@@ -50,6 +57,8 @@ func NewDTOConnector(
 	v := &DTOConnector{
 		legacy:        legacyAccess,
 		accessControl: accessControl,
+		folders:       folders,
+		views:         views,
 		unified:       resourceClient,
 		largeObjects:  largeObjects,
 		newFunc:       newFunc,
@@ -127,7 +136,7 @@ func (r *DTOConnector) Connect(ctx context.Context, name string, opts runtime.Ob
 	}
 	if repo != nil && repo.Name == "SQL" {
 		dto.ID, err = strconv.ParseInt(repo.Path, 10, 64)
-		if err == nil {
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -137,8 +146,14 @@ func (r *DTOConnector) Connect(ctx context.Context, name string, opts runtime.Ob
 		return nil, err
 	}
 	canView, err := guardian.CanView()
-	if err != nil || !canView {
-		return nil, fmt.Errorf("not allowed to view")
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, apierrors.NewForbidden(dashboard.DashboardResourceInfo.GroupResource(), name, fmt.Errorf("not allowed to view"))
+	}
+	if r.views != nil {
+		r.views.Record(info.OrgID, name)
 	}
 
 	access := dashboard.DashboardAccess{}
@@ -147,6 +162,7 @@ func (r *DTOConnector) Connect(ctx context.Context, name string, opts runtime.Ob
 	access.CanAdmin, _ = guardian.CanAdmin()
 	access.CanDelete, _ = guardian.CanDelete()
 	access.CanStar = user.IsIdentityType(claims.TypeUser)
+	access.CanShare = r.canSharePublicly(ctx, user, name)
 
 	access.AnnotationsPermissions = &dashboard.AnnotationPermission{}
 	r.getAnnotationPermissionsByScope(ctx, user, &access.AnnotationsPermissions.Dashboard, accesscontrol.ScopeAnnotationsTypeDashboard)
@@ -169,6 +185,9 @@ func (r *DTOConnector) Connect(ctx context.Context, name string, opts runtime.Ob
 
 	access.Slug = slugify.Slugify(dash.Spec.GetNestedString("title"))
 	access.Url = dashboards.GetDashboardFolderURL(false, name, access.Slug)
+	if err = r.setFolderInfo(ctx, user, info.OrgID, obj.GetFolder(), &access); err != nil {
+		return nil, err
+	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		responder.Object(http.StatusOK, &dashboard.DashboardWithAccessInfo{
@@ -178,6 +197,37 @@ func (r *DTOConnector) Connect(ctx context.Context, name string, opts runtime.Ob
 	}), nil
 }
 
+// setFolderInfo sets the folder of the dashboard in access, like the folder title and URL of the legacy DTO.
+// Users can view a dashboard without viewing its folder, the folder title is then left empty.
+func (r *DTOConnector) setFolderInfo(ctx context.Context, user identity.Requester, orgID int64, folderUID string, access *dashboard.DashboardAccess) error {
+	if folderUID == "" {
+		access.FolderTitle = folder.GeneralFolder.Title
+		return nil
+	}
+	access.FolderUid = folderUID
+	f, err := r.folders.Get(ctx, &folder.GetFolderQuery{UID: &folderUID, OrgID: orgID, SignedInUser: user})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrFolderAccessDenied) {
+			return nil
+		}
+		return err
+	}
+	access.FolderTitle = f.Title
+	access.FolderUrl = dashboards.GetFolderURL(f.UID, slugify.Slugify(f.Title))
+	return nil
+}
+
+// canSharePublicly tells if the user can configure public sharing of the dashboard with the public subresource
+func (r *DTOConnector) canSharePublicly(ctx context.Context, user identity.Requester, uid string) bool {
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid)
+	ok, err := r.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, scope))
+	if err != nil {
+		r.log.Warn("Failed to evaluate permission", "err", err, "action", dashboards.ActionDashboardsPublicWrite, "scope", scope)
+		return false
+	}
+	return ok
+}
+
 func (r *DTOConnector) getAnnotationPermissionsByScope(ctx context.Context, user identity.Requester, actions *dashboard.AnnotationActions, scope string) {
 	var err error
 
@@ -0,0 +1,48 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestDTOFolderInfo(t *testing.T) {
+	ctx := context.Background()
+	u := &user.SignedInUser{UserID: 1, OrgID: 1}
+	folders := foldertest.NewFakeService()
+	r := &DTOConnector{folders: folders, log: log.NewNopLogger()}
+
+	t.Run("dashboards in the root are in the General folder", func(t *testing.T) {
+		access := dashboard.DashboardAccess{}
+		require.NoError(t, r.setFolderInfo(ctx, u, 1, "", &access))
+		require.Equal(t, dashboard.DashboardAccess{FolderTitle: "General"}, access)
+	})
+
+	t.Run("sets the title and URL of the folder", func(t *testing.T) {
+		folders.ExpectedFolder = &folder.Folder{UID: "f1", Title: "Team A"}
+		access := dashboard.DashboardAccess{}
+		require.NoError(t, r.setFolderInfo(ctx, u, 1, "f1", &access))
+		require.Equal(t, dashboard.DashboardAccess{FolderUid: "f1", FolderTitle: "Team A", FolderUrl: "/dashboards/f/f1/team-a"}, access)
+	})
+
+	t.Run("users that can not view the folder only get its UID", func(t *testing.T) {
+		folders.ExpectedFolder, folders.ExpectedError = nil, dashboards.ErrFolderAccessDenied
+		access := dashboard.DashboardAccess{}
+		require.NoError(t, r.setFolderInfo(ctx, u, 1, "f1", &access))
+		require.Equal(t, dashboard.DashboardAccess{FolderUid: "f1"}, access)
+	})
+
+	t.Run("other errors fail the request", func(t *testing.T) {
+		folders.ExpectedError = errors.New("db down")
+		require.Error(t, r.setFolderInfo(ctx, u, 1, "f1", &dashboard.DashboardAccess{}))
+	})
+}
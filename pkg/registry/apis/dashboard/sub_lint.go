@@ -0,0 +1,103 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// The lint subresource analyzes a dashboard spec for common problems, so they can be shown before it is saved:
+//
+//	GET  .../lint  analyzes the saved dashboard
+//	POST .../lint  analyzes the spec in the body, for example the changes of the save drawer
+//
+// The spec is read in the classic dashboard JSON model.
+type LintConnector struct {
+	dashboards dashboards.DashboardService
+	newFunc    func() runtime.Object
+	log        log.Logger
+}
+
+func NewLintConnector(
+	dashboardService dashboards.DashboardService,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &LintConnector{
+		dashboards: dashboardService,
+		newFunc:    newFunc,
+		log:        log.New("grafana-apiserver.dashboards.lint"),
+	}
+}
+
+var (
+	_ rest.Connecter       = (*LintConnector)(nil)
+	_ rest.StorageMetadata = (*LintConnector)(nil)
+)
+
+func (r *LintConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *LintConnector) Destroy() {
+}
+
+func (r *LintConnector) ConnectMethods() []string {
+	return []string{http.MethodGet, http.MethodPost}
+}
+
+func (r *LintConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *LintConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *LintConnector) ProducesObject(verb string) interface{} {
+	return &LintReport{}
+}
+
+func (r *LintConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		spec := map[string]any{}
+		switch req.Method {
+		case http.MethodGet:
+			dash, err := r.dashboards.GetDashboard(req.Context(), &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+			if err != nil {
+				if errors.Is(err, dashboards.ErrDashboardNotFound) {
+					err = apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+				}
+				responder.Error(err)
+				return
+			}
+			if dash.Data != nil {
+				spec, _ = dash.Data.Interface().(map[string]any)
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(req.Body).Decode(&spec); err != nil {
+				responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+				return
+			}
+		default:
+			responder.Error(apierrors.NewMethodNotSupported(dashboard.DashboardResourceInfo.GroupResource(), req.Method))
+			return
+		}
+		writeJSON(w, http.StatusOK, lintDashboard(spec), responder)
+	}), nil
+}
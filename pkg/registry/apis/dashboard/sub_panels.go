@@ -0,0 +1,128 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+var panelsGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "panels"}
+
+// The panels subresource returns a single panel of a dashboard, so embedding tools and the solo panel renderer
+// do not need to download the whole dashboard:
+//
+//	GET .../panels/{panelId}
+//
+// Library panels are replaced by their model, and the template variables used by the panel are included.
+type PanelsConnector struct {
+	dashboards dashboards.DashboardService
+	extractor  *PanelExtractor
+	newFunc    func() runtime.Object
+}
+
+func NewPanelsConnector(
+	dashboardService dashboards.DashboardService,
+	extractor *PanelExtractor,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &PanelsConnector{
+		dashboards: dashboardService,
+		extractor:  extractor,
+		newFunc:    newFunc,
+	}
+}
+
+var (
+	_ rest.Connecter       = (*PanelsConnector)(nil)
+	_ rest.StorageMetadata = (*PanelsConnector)(nil)
+)
+
+func (r *PanelsConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *PanelsConnector) Destroy() {
+}
+
+func (r *PanelsConnector) ConnectMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func (r *PanelsConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, true, "" // the trailing path is the panel id
+}
+
+func (r *PanelsConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *PanelsConnector) ProducesObject(verb string) interface{} {
+	return &DashboardPanel{}
+}
+
+func (r *PanelsConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, err := panelIDFromPath(req.URL.Path)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		dash, err := r.dashboards.GetDashboard(req.Context(), &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				err = apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+			}
+			responder.Error(err)
+			return
+		}
+		spec := map[string]any{}
+		if dash.Data != nil {
+			spec, _ = dash.Data.Interface().(map[string]any)
+		}
+
+		panel, err := r.extractor.Extract(req.Context(), user, spec, id)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		writeJSON(w, http.StatusOK, panel, responder)
+	}), nil
+}
+
+// panelIDFromPath returns the panel id following the panels subresource
+func panelIDFromPath(path string) (int64, error) {
+	idx := strings.LastIndex(path, "/panels")
+	if idx < 0 {
+		return 0, apierrors.NewBadRequest("expected panels path")
+	}
+	raw := strings.Trim(path[idx+len("/panels"):], "/")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, apierrors.NewBadRequest(fmt.Sprintf("invalid panel id: %q", raw))
+	}
+	return id, nil
+}
@@ -0,0 +1,305 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+var permissionsGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "permissions"}
+
+// DashboardPermission is a single entry of the permission list of a dashboard.
+// Exactly one of user, team or role is set.
+type DashboardPermission struct {
+	UserID           int64  `json:"userId,omitempty"`
+	UserUID          string `json:"userUid,omitempty"`
+	UserLogin        string `json:"userLogin,omitempty"`
+	IsServiceAccount bool   `json:"isServiceAccount,omitempty"`
+	TeamID           int64  `json:"teamId,omitempty"`
+	TeamUID          string `json:"teamUid,omitempty"`
+	Team             string `json:"team,omitempty"`
+	Role             string `json:"role,omitempty"`
+
+	// One of View, Edit or Admin
+	Permission string `json:"permission"`
+
+	// Inherited permissions come from the parent folders and can not be changed on the dashboard
+	Inherited bool `json:"inherited,omitempty"`
+}
+
+type DashboardPermissionList struct {
+	Items []DashboardPermission `json:"items"`
+}
+
+// The permissions subresource reads and replaces the full permission list of a dashboard.
+// It replaces the legacy /api/dashboards/uid/:uid/permissions endpoints.
+type PermissionsConnector struct {
+	dashboards    dashboards.DashboardService
+	permissions   accesscontrol.DashboardPermissionsService
+	accessControl accesscontrol.AccessControl
+	hiddenUsers   map[string]struct{}
+	newFunc       func() runtime.Object
+	log           log.Logger
+}
+
+func NewPermissionsConnector(
+	dashboardService dashboards.DashboardService,
+	permissions accesscontrol.DashboardPermissionsService,
+	accessControl accesscontrol.AccessControl,
+	hiddenUsers map[string]struct{},
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &PermissionsConnector{
+		dashboards:    dashboardService,
+		permissions:   permissions,
+		accessControl: accessControl,
+		hiddenUsers:   hiddenUsers,
+		newFunc:       newFunc,
+		log:           log.New("grafana-apiserver.dashboards.permissions"),
+	}
+}
+
+var (
+	_ rest.Connecter       = (*PermissionsConnector)(nil)
+	_ rest.StorageMetadata = (*PermissionsConnector)(nil)
+)
+
+func (r *PermissionsConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *PermissionsConnector) Destroy() {
+}
+
+func (r *PermissionsConnector) ConnectMethods() []string {
+	return []string{http.MethodGet, http.MethodPut}
+}
+
+func (r *PermissionsConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *PermissionsConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *PermissionsConnector) ProducesObject(verb string) interface{} {
+	return &DashboardPermissionList{}
+}
+
+func (r *PermissionsConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			if !r.canPerform(req.Context(), user, dashboards.ActionDashboardsPermissionsRead, dash.UID) {
+				responder.Error(apierrors.NewForbidden(permissionsGroupResource, dash.UID, fmt.Errorf("not allowed to read dashboard permissions")))
+				return
+			}
+			current, err := r.list(req.Context(), user, dash.UID)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, &DashboardPermissionList{Items: r.visible(user, current)}, responder)
+		case http.MethodPut:
+			if !r.canPerform(req.Context(), user, dashboards.ActionDashboardsPermissionsWrite, dash.UID) {
+				responder.Error(apierrors.NewForbidden(permissionsGroupResource, dash.UID, fmt.Errorf("not allowed to update dashboard permissions")))
+				return
+			}
+			body := DashboardPermissionList{}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+				return
+			}
+			current, err := r.list(req.Context(), user, dash.UID)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			commands, err := r.permissionCommands(user, body.Items, current)
+			if err != nil {
+				responder.Error(apierrors.NewBadRequest(err.Error()))
+				return
+			}
+			if _, err := r.permissions.SetPermissions(req.Context(), dash.OrgID, dash.UID, commands...); err != nil {
+				responder.Error(err)
+				return
+			}
+			updated, err := r.list(req.Context(), user, dash.UID)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, &DashboardPermissionList{Items: r.visible(user, updated)}, responder)
+		default:
+			responder.Error(apierrors.NewMethodNotSupported(permissionsGroupResource, req.Method))
+		}
+	}), nil
+}
+
+// list returns the managed permissions of the dashboard
+func (r *PermissionsConnector) list(ctx context.Context, user identity.Requester, uid string) ([]DashboardPermission, error) {
+	permissions, err := r.permissions.GetPermissions(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DashboardPermission, 0, len(permissions))
+	for _, p := range permissions {
+		if !p.IsManaged {
+			continue
+		}
+		items = append(items, DashboardPermission{
+			UserID:           p.UserID,
+			UserUID:          p.UserUID,
+			UserLogin:        p.UserLogin,
+			IsServiceAccount: p.IsServiceAccount,
+			TeamID:           p.TeamID,
+			TeamUID:          p.TeamUID,
+			Team:             p.Team,
+			Role:             p.BuiltInRole,
+			Permission:       r.permissions.MapActions(p),
+			Inherited:        p.IsInherited,
+		})
+	}
+	return items, nil
+}
+
+// permissionCommands builds the commands that replace the current permissions of the dashboard with the desired ones.
+// Inherited permissions are ignored, and permissions of hidden users are kept as they are.
+func (r *PermissionsConnector) permissionCommands(user identity.Requester, desired, current []DashboardPermission) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(desired)+len(current))
+	seen := make(map[string]struct{}, len(desired))
+	for _, p := range desired {
+		if p.Inherited {
+			continue
+		}
+		if err := validateDashboardPermission(p); err != nil {
+			return nil, err
+		}
+		key := p.key()
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("duplicate permission for %s", key)
+		}
+		seen[key] = struct{}{}
+		commands = append(commands, accesscontrol.SetResourcePermissionCommand{
+			UserID:      p.UserID,
+			TeamID:      p.TeamID,
+			BuiltinRole: p.Role,
+			Permission:  p.Permission,
+		})
+	}
+
+	for _, p := range current {
+		if p.Inherited || r.isHidden(user, p.UserLogin) {
+			continue
+		}
+		if _, ok := seen[p.key()]; ok {
+			continue
+		}
+		// Remove permissions that are no longer in the list
+		commands = append(commands, accesscontrol.SetResourcePermissionCommand{
+			UserID:      p.UserID,
+			TeamID:      p.TeamID,
+			BuiltinRole: p.Role,
+			Permission:  "",
+		})
+	}
+	return commands, nil
+}
+
+// visible filters out the permissions of users hidden from the requester
+func (r *PermissionsConnector) visible(user identity.Requester, items []DashboardPermission) []DashboardPermission {
+	out := make([]DashboardPermission, 0, len(items))
+	for _, p := range items {
+		if !r.isHidden(user, p.UserLogin) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (r *PermissionsConnector) isHidden(user identity.Requester, login string) bool {
+	if login == "" || user.GetIsGrafanaAdmin() || login == user.GetLogin() {
+		return false
+	}
+	_, hidden := r.hiddenUsers[login]
+	return hidden
+}
+
+func (r *PermissionsConnector) canPerform(ctx context.Context, user identity.Requester, action, uid string) bool {
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid)
+	ok, err := r.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action, scope))
+	if err != nil {
+		r.log.Warn("Failed to evaluate permission", "err", err, "action", action, "scope", scope)
+		return false
+	}
+	return ok
+}
+
+func (p DashboardPermission) key() string {
+	switch {
+	case p.UserID != 0:
+		return fmt.Sprintf("user:%d", p.UserID)
+	case p.TeamID != 0:
+		return fmt.Sprintf("team:%d", p.TeamID)
+	default:
+		return "role:" + p.Role
+	}
+}
+
+func validateDashboardPermission(p DashboardPermission) error {
+	set := 0
+	if p.UserID != 0 {
+		set++
+	}
+	if p.TeamID != 0 {
+		set++
+	}
+	if p.Role != "" {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of userId, teamId or role must be set")
+	}
+	if p.Role != "" && !identity.RoleType(p.Role).IsValid() {
+		return fmt.Errorf("invalid role: %s", p.Role)
+	}
+	switch p.Permission {
+	case "View", "Edit", "Admin":
+		return nil
+	default:
+		return fmt.Errorf("invalid permission %q for %s, expected View, Edit or Admin", p.Permission, p.key())
+	}
+}
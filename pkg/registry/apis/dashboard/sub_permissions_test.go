@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+func TestPermissionCommands(t *testing.T) {
+	r := &PermissionsConnector{hiddenUsers: map[string]struct{}{"hidden": {}}}
+	user := &identity.StaticRequester{Login: "editor"}
+
+	current := []DashboardPermission{
+		{UserID: 1, UserLogin: "a", Permission: "View"},
+		{UserID: 2, UserLogin: "hidden", Permission: "Admin"},
+		{TeamID: 3, Permission: "Edit"},
+		{Role: "Viewer", Permission: "View", Inherited: true},
+	}
+
+	t.Run("replaces the current permissions", func(t *testing.T) {
+		commands, err := r.permissionCommands(user, []DashboardPermission{
+			{UserID: 1, Permission: "Edit"},
+			{Role: "Editor", Permission: "Edit"},
+			{Role: "Viewer", Permission: "View", Inherited: true},
+		}, current)
+		require.NoError(t, err)
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{
+			{UserID: 1, Permission: "Edit"},
+			{BuiltinRole: "Editor", Permission: "Edit"},
+			{TeamID: 3, Permission: ""},
+		}, commands)
+	})
+
+	t.Run("fails on invalid permissions", func(t *testing.T) {
+		_, err := r.permissionCommands(user, []DashboardPermission{{UserID: 1, TeamID: 1, Permission: "View"}}, current)
+		require.ErrorContains(t, err, "exactly one of")
+
+		_, err = r.permissionCommands(user, []DashboardPermission{{Role: "Owner", Permission: "View"}}, current)
+		require.ErrorContains(t, err, "invalid role")
+
+		_, err = r.permissionCommands(user, []DashboardPermission{{TeamID: 1, Permission: "Query"}}, current)
+		require.ErrorContains(t, err, "invalid permission")
+
+		_, err = r.permissionCommands(user, []DashboardPermission{{TeamID: 1, Permission: "View"}, {TeamID: 1, Permission: "Edit"}}, current)
+		require.ErrorContains(t, err, "duplicate permission for team:1")
+	})
+
+	t.Run("hides hidden users", func(t *testing.T) {
+		require.Len(t, r.visible(user, current), 3)
+		require.Len(t, r.visible(&identity.StaticRequester{IsGrafanaAdmin: true}, current), 4)
+	})
+}
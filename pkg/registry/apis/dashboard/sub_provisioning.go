@@ -0,0 +1,197 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/registry/apis/dashboard/legacy"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+)
+
+// ProvisioningStatus tells if a dashboard is managed by file provisioning, and where it is provisioned from.
+type ProvisioningStatus struct {
+	Provisioned bool `json:"provisioned"`
+
+	// ReadOnly is false when the provisioner allows changes from the UI, they are overwritten on the next provisioning run
+	ReadOnly bool `json:"readOnly"`
+
+	Provisioner string     `json:"provisioner,omitempty"`
+	Path        string     `json:"path,omitempty"`
+	Checksum    string     `json:"checksum,omitempty"`
+	Timestamp   *time.Time `json:"timestamp,omitempty"`
+}
+
+// ProvisioningGuard keeps provisioned dashboards read only through the API.
+// The origin of a dashboard is set by the store in the repository annotations, clients can not set them.
+type ProvisioningGuard struct {
+	provisioning provisioning.ProvisioningService
+}
+
+func NewProvisioningGuard(provisioningService provisioning.ProvisioningService) *ProvisioningGuard {
+	return &ProvisioningGuard{provisioning: provisioningService}
+}
+
+// Status reads the provisioning origin of a dashboard.
+func (g *ProvisioningGuard) Status(obj runtime.Object) (*ProvisioningStatus, error) {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := meta.GetRepositoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	// dashboards installed by plugins can be edited
+	if repo == nil || repo.Name == "" || repo.Name == legacy.PluginRepositoryName {
+		return &ProvisioningStatus{}, nil
+	}
+	return &ProvisioningStatus{
+		Provisioned: true,
+		ReadOnly:    !g.provisioning.GetAllowUIUpdatesFromConfig(repo.Name),
+		Provisioner: repo.Name,
+		Path:        repo.Path,
+		Checksum:    repo.Hash,
+		Timestamp:   repo.Timestamp,
+	}, nil
+}
+
+// Validate rejects changes to read only provisioned dashboards with a conflict, and rejects
+// writes that try to set the provisioning origin.
+func (g *ProvisioningGuard) Validate(ctx context.Context, a admission.Attributes, getter rest.Getter) error {
+	if a.GetResource().Resource != dashboard.DashboardResourceInfo.GroupResource().Resource || a.GetSubresource() != "" {
+		return nil
+	}
+
+	switch a.GetOperation() {
+	case admission.Create:
+		return g.validateOrigin(a.GetObject(), nil)
+	case admission.Update:
+		if err := g.validateWritable(a, a.GetOldObject()); err != nil {
+			return err
+		}
+		return g.validateOrigin(a.GetObject(), a.GetOldObject())
+	case admission.Delete:
+		existing := a.GetOldObject()
+		if existing == nil {
+			existing = a.GetObject()
+		}
+		if existing == nil && getter != nil {
+			obj, err := getter.Get(ctx, a.GetName(), &metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			existing = obj
+		}
+		return g.validateWritable(a, existing)
+	}
+	return nil
+}
+
+func (g *ProvisioningGuard) validateWritable(a admission.Attributes, existing runtime.Object) error {
+	if existing == nil {
+		return nil
+	}
+	status, err := g.Status(existing)
+	if err != nil {
+		return err
+	}
+	if status.ReadOnly {
+		return apierrors.NewConflict(dashboard.DashboardResourceInfo.GroupResource(), a.GetName(),
+			fmt.Errorf("dashboard is provisioned by %q from %q and can only be changed in its provisioning source", status.Provisioner, status.Path))
+	}
+	return nil
+}
+
+// validateOrigin checks that the repository annotations of the object are the ones set by the store
+func (g *ProvisioningGuard) validateOrigin(obj runtime.Object, old runtime.Object) error {
+	if obj == nil {
+		return nil
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return err
+	}
+	current := ""
+	if old != nil {
+		oldMeta, err := utils.MetaAccessor(old)
+		if err != nil {
+			return err
+		}
+		current = oldMeta.GetRepositoryName()
+	}
+	if name := meta.GetRepositoryName(); name != "" && name != current {
+		return apierrors.NewBadRequest(fmt.Sprintf("the %s annotation is set by provisioning and can not be changed", utils.AnnoKeyRepoName))
+	}
+	return nil
+}
+
+// The provisioning-status subresource tells if a dashboard is provisioned and can be changed through the API.
+type ProvisioningStatusConnector struct {
+	getter  rest.Getter
+	guard   *ProvisioningGuard
+	newFunc func() runtime.Object
+}
+
+func NewProvisioningStatusConnector(dash rest.Storage, guard *ProvisioningGuard, newFunc func() runtime.Object) (rest.Storage, error) {
+	getter, ok := dash.(rest.Getter)
+	if !ok {
+		return nil, fmt.Errorf("dashboard storage must implement getter")
+	}
+	return &ProvisioningStatusConnector{
+		getter:  getter,
+		guard:   guard,
+		newFunc: newFunc,
+	}, nil
+}
+
+var (
+	_ rest.Connecter       = (*ProvisioningStatusConnector)(nil)
+	_ rest.StorageMetadata = (*ProvisioningStatusConnector)(nil)
+)
+
+func (r *ProvisioningStatusConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *ProvisioningStatusConnector) Destroy() {
+}
+
+func (r *ProvisioningStatusConnector) ConnectMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func (r *ProvisioningStatusConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *ProvisioningStatusConnector) ProducesMIMETypes(verb string) []string {
+	return nil
+}
+
+func (r *ProvisioningStatusConnector) ProducesObject(verb string) interface{} {
+	return &ProvisioningStatus{}
+}
+
+func (r *ProvisioningStatusConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	obj, err := r.getter.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	status, err := r.guard.Status(obj)
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, status, responder)
+	}), nil
+}
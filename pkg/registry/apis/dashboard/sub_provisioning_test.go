@@ -0,0 +1,97 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestProvisioningGuard(t *testing.T) {
+	mock := provisioning.NewProvisioningServiceMock(context.Background())
+	mock.GetAllowUIUpdatesFromConfigFunc = func(name string) bool {
+		return name == "editable"
+	}
+	guard := NewProvisioningGuard(mock)
+
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newDashboard := func(repo *utils.ResourceRepositoryInfo) *dashboardv0alpha1.Dashboard {
+		dash := &dashboardv0alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "default"}}
+		meta, err := utils.MetaAccessor(dash)
+		require.NoError(t, err)
+		meta.SetRepositoryInfo(repo)
+		return dash
+	}
+	provisioned := newDashboard(&utils.ResourceRepositoryInfo{Name: "files", Path: "team/abc.json", Hash: "xyz", Timestamp: &ts})
+	editable := newDashboard(&utils.ResourceRepositoryInfo{Name: "editable", Path: "abc.json"})
+	plugin := newDashboard(&utils.ResourceRepositoryInfo{Name: "plugin", Path: "grafana-app"})
+	plain := newDashboard(nil)
+
+	validate := func(op admission.Operation, obj runtime.Object, old runtime.Object) error {
+		return guard.Validate(context.Background(), admission.NewAttributesRecord(
+			obj,
+			old,
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(),
+			"default",
+			"abc",
+			dashboardv0alpha1.DashboardResourceInfo.GroupVersionResource(),
+			"",
+			op,
+			nil,
+			false,
+			&user.SignedInUser{},
+		), nil)
+	}
+
+	t.Run("status", func(t *testing.T) {
+		status, err := guard.Status(provisioned)
+		require.NoError(t, err)
+		require.Equal(t, &ProvisioningStatus{
+			Provisioned: true,
+			ReadOnly:    true,
+			Provisioner: "files",
+			Path:        "team/abc.json",
+			Checksum:    "xyz",
+			Timestamp:   &ts,
+		}, status)
+
+		status, err = guard.Status(editable)
+		require.NoError(t, err)
+		require.True(t, status.Provisioned)
+		require.False(t, status.ReadOnly)
+
+		status, err = guard.Status(plugin)
+		require.NoError(t, err)
+		require.Equal(t, &ProvisioningStatus{}, status)
+	})
+
+	t.Run("provisioned dashboards are read only", func(t *testing.T) {
+		err := validate(admission.Update, plain, provisioned)
+		require.True(t, apierrors.IsConflict(err), "update: %v", err)
+
+		err = validate(admission.Delete, nil, provisioned)
+		require.True(t, apierrors.IsConflict(err), "delete: %v", err)
+
+		require.NoError(t, validate(admission.Update, editable, editable), "the provisioner allows ui updates")
+		require.NoError(t, validate(admission.Delete, nil, plugin), "plugin dashboards can be removed")
+		require.NoError(t, validate(admission.Update, plain, plain))
+	})
+
+	t.Run("the origin can not be set through the api", func(t *testing.T) {
+		err := validate(admission.Create, provisioned, nil)
+		require.True(t, apierrors.IsBadRequest(err), "create: %v", err)
+
+		err = validate(admission.Update, provisioned, plain)
+		require.True(t, apierrors.IsBadRequest(err), "update: %v", err)
+	})
+}
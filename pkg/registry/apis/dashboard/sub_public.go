@@ -0,0 +1,310 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	pdmodels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const publicRotateTokenPath = "rotate-token"
+
+var publicGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "public"}
+
+// DashboardPublicConfig is the public sharing configuration of a dashboard
+type DashboardPublicConfig struct {
+	UID         string `json:"uid"`
+	AccessToken string `json:"accessToken"`
+
+	IsEnabled            bool   `json:"isEnabled"`
+	TimeSelectionEnabled bool   `json:"timeSelectionEnabled"`
+	AnnotationsEnabled   bool   `json:"annotationsEnabled"`
+	Share                string `json:"share"`
+
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+}
+
+// DashboardPublicConfigCommand is the body used to configure public sharing.
+// Only the fields that are set are changed, a new configuration is disabled unless isEnabled is set.
+type DashboardPublicConfigCommand struct {
+	IsEnabled            *bool  `json:"isEnabled,omitempty"`
+	TimeSelectionEnabled *bool  `json:"timeSelectionEnabled,omitempty"`
+	AnnotationsEnabled   *bool  `json:"annotationsEnabled,omitempty"`
+	Share                string `json:"share,omitempty"`
+}
+
+// The public subresource configures public sharing of a dashboard.
+// It replaces the legacy /api/dashboards/uid/:uid/public-dashboards endpoints:
+//
+//	GET    .../public              the current configuration
+//	PUT    .../public              create or update the configuration
+//	DELETE .../public              stop sharing the dashboard
+//	POST   .../public/rotate-token replace the access token, existing links stop working
+type PublicConnector struct {
+	dashboards    dashboards.DashboardService
+	public        publicdashboards.Service
+	accessControl accesscontrol.AccessControl
+	enabled       bool
+	newFunc       func() runtime.Object
+	log           log.Logger
+}
+
+func NewPublicConnector(
+	dashboardService dashboards.DashboardService,
+	public publicdashboards.Service,
+	accessControl accesscontrol.AccessControl,
+	enabled bool,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &PublicConnector{
+		dashboards:    dashboardService,
+		public:        public,
+		accessControl: accessControl,
+		enabled:       enabled,
+		newFunc:       newFunc,
+		log:           log.New("grafana-apiserver.dashboards.public"),
+	}
+}
+
+var (
+	_ rest.Connecter       = (*PublicConnector)(nil)
+	_ rest.StorageMetadata = (*PublicConnector)(nil)
+)
+
+func (r *PublicConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *PublicConnector) Destroy() {
+}
+
+func (r *PublicConnector) ConnectMethods() []string {
+	return []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete}
+}
+
+func (r *PublicConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, true, "" // the trailing path is the action
+}
+
+func (r *PublicConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *PublicConnector) ProducesObject(verb string) interface{} {
+	return &DashboardPublicConfig{}
+}
+
+func (r *PublicConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	if !r.enabled {
+		return nil, apierrors.NewNotFound(publicGroupResource, name)
+	}
+
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		action, err := publicActionFromPath(req.URL.Path)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		switch {
+		case req.Method == http.MethodGet && action == "":
+			if !r.canPerform(req.Context(), user, dashboards.ActionDashboardsRead, dash.UID) {
+				responder.Error(apierrors.NewForbidden(publicGroupResource, dash.UID, fmt.Errorf("not allowed to view the dashboard")))
+				return
+			}
+			pubdash, err := r.find(req.Context(), dash)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, toDashboardPublicConfig(pubdash), responder)
+		case req.Method == http.MethodPut && action == "":
+			if !r.canWrite(req, responder, user, dash) {
+				return
+			}
+			r.save(w, req, responder, user, dash)
+		case req.Method == http.MethodDelete && action == "":
+			if !r.canWrite(req, responder, user, dash) {
+				return
+			}
+			pubdash, err := r.find(req.Context(), dash)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			if err := r.public.Delete(req.Context(), pubdash.Uid, dash.UID); err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, toDashboardPublicConfig(pubdash), responder)
+		case req.Method == http.MethodPost && action == publicRotateTokenPath:
+			if !r.canWrite(req, responder, user, dash) {
+				return
+			}
+			pubdash, err := r.find(req.Context(), dash)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			rotated, err := r.public.RotateAccessToken(req.Context(), signedInUser(user), pubdash.Uid, dash.UID)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, toDashboardPublicConfig(rotated), responder)
+		default:
+			responder.Error(apierrors.NewMethodNotSupported(publicGroupResource, req.Method))
+		}
+	}), nil
+}
+
+// save creates the public configuration of the dashboard, or updates the existing one
+func (r *PublicConnector) save(w http.ResponseWriter, req *http.Request, responder rest.Responder, requester identity.Requester, dash *dashboards.Dashboard) {
+	cmd := DashboardPublicConfigCommand{}
+	if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+		responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+		return
+	}
+
+	existing, err := r.public.FindByDashboardUid(req.Context(), dash.OrgID, dash.UID)
+	if err != nil && !errors.Is(err, pdmodels.ErrPublicDashboardNotFound) {
+		responder.Error(err)
+		return
+	}
+
+	u := signedInUser(requester)
+	dto := &pdmodels.SavePublicDashboardDTO{
+		UserId:       u.UserID,
+		OrgID:        dash.OrgID,
+		DashboardUid: dash.UID,
+		PublicDashboard: &pdmodels.PublicDashboardDTO{
+			IsEnabled:            cmd.IsEnabled,
+			TimeSelectionEnabled: cmd.TimeSelectionEnabled,
+			AnnotationsEnabled:   cmd.AnnotationsEnabled,
+			Share:                pdmodels.ShareType(cmd.Share),
+		},
+	}
+
+	var saved *pdmodels.PublicDashboard
+	status := http.StatusOK
+	if existing == nil {
+		saved, err = r.public.Create(req.Context(), u, dto)
+		status = http.StatusCreated
+	} else {
+		dto.Uid = existing.Uid
+		saved, err = r.public.Update(req.Context(), u, dto)
+	}
+	if err != nil {
+		responder.Error(err)
+		return
+	}
+	writeJSON(w, status, toDashboardPublicConfig(saved), responder)
+}
+
+func (r *PublicConnector) find(ctx context.Context, dash *dashboards.Dashboard) (*pdmodels.PublicDashboard, error) {
+	pubdash, err := r.public.FindByDashboardUid(ctx, dash.OrgID, dash.UID)
+	if err != nil {
+		if errors.Is(err, pdmodels.ErrPublicDashboardNotFound) {
+			return nil, apierrors.NewNotFound(publicGroupResource, dash.UID)
+		}
+		return nil, err
+	}
+	if pubdash == nil {
+		return nil, apierrors.NewNotFound(publicGroupResource, dash.UID)
+	}
+	return pubdash, nil
+}
+
+func (r *PublicConnector) canWrite(req *http.Request, responder rest.Responder, user identity.Requester, dash *dashboards.Dashboard) bool {
+	if !r.canPerform(req.Context(), user, dashboards.ActionDashboardsPublicWrite, dash.UID) {
+		responder.Error(apierrors.NewForbidden(publicGroupResource, dash.UID, fmt.Errorf("not allowed to share the dashboard publicly")))
+		return false
+	}
+	return true
+}
+
+func (r *PublicConnector) canPerform(ctx context.Context, user identity.Requester, action, uid string) bool {
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid)
+	ok, err := r.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action, scope))
+	if err != nil {
+		r.log.Warn("Failed to evaluate permission", "err", err, "action", action, "scope", scope)
+		return false
+	}
+	return ok
+}
+
+// publicActionFromPath returns the action following the public subresource, or an empty string if there is none
+func publicActionFromPath(path string) (string, error) {
+	idx := strings.LastIndex(path, "/public")
+	if idx < 0 {
+		return "", apierrors.NewBadRequest("expected public path")
+	}
+	action := strings.Trim(path[idx+len("/public"):], "/")
+	switch action {
+	case "", publicRotateTokenPath:
+		return action, nil
+	}
+	return "", apierrors.NewNotFound(publicGroupResource, action)
+}
+
+// signedInUser returns the user the public dashboard service expects, the service only reads the ids and login
+func signedInUser(requester identity.Requester) *user.SignedInUser {
+	if u, ok := requester.(*user.SignedInUser); ok {
+		return u
+	}
+	userID, _ := identity.UserIdentifier(requester.GetID())
+	return &user.SignedInUser{
+		UserID: userID,
+		OrgID:  requester.GetOrgID(),
+		Login:  requester.GetLogin(),
+	}
+}
+
+func toDashboardPublicConfig(pubdash *pdmodels.PublicDashboard) DashboardPublicConfig {
+	return DashboardPublicConfig{
+		UID:                  pubdash.Uid,
+		AccessToken:          pubdash.AccessToken,
+		IsEnabled:            pubdash.IsEnabled,
+		TimeSelectionEnabled: pubdash.TimeSelectionEnabled,
+		AnnotationsEnabled:   pubdash.AnnotationsEnabled,
+		Share:                string(pubdash.Share),
+		Created:              pubdash.CreatedAt.UnixMilli(),
+		Updated:              pubdash.UpdatedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	pdmodels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestPublicActionFromPath(t *testing.T) {
+	action, err := publicActionFromPath("/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/public")
+	require.NoError(t, err)
+	require.Equal(t, "", action)
+
+	action, err = publicActionFromPath("/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/public/rotate-token")
+	require.NoError(t, err)
+	require.Equal(t, publicRotateTokenPath, action)
+
+	_, err = publicActionFromPath("/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/public/other")
+	require.Error(t, err)
+}
+
+func TestSignedInUser(t *testing.T) {
+	u := &user.SignedInUser{UserID: 1, OrgID: 2}
+	require.Same(t, u, signedInUser(u))
+
+	converted := signedInUser(&identity.StaticRequester{Type: claims.TypeUser, UserID: 3, OrgID: 4, Login: "admin"})
+	require.Equal(t, &user.SignedInUser{UserID: 3, OrgID: 4, Login: "admin"}, converted)
+}
+
+func TestToDashboardPublicConfig(t *testing.T) {
+	created := time.UnixMilli(1000)
+	require.Equal(t, DashboardPublicConfig{
+		UID:                  "pub",
+		AccessToken:          "token",
+		IsEnabled:            true,
+		TimeSelectionEnabled: true,
+		Share:                "public",
+		Created:              1000,
+		Updated:              2000,
+	}, toDashboardPublicConfig(&pdmodels.PublicDashboard{
+		Uid:                  "pub",
+		AccessToken:          "token",
+		IsEnabled:            true,
+		TimeSelectionEnabled: true,
+		Share:                pdmodels.PublicShareType,
+		CreatedAt:            created,
+		UpdatedAt:            created.Add(time.Second),
+	}))
+}
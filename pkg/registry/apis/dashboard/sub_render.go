@@ -0,0 +1,267 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var renderGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "render"}
+
+// The render subresource returns a PNG or PDF of a dashboard, or of a single panel when panelId is set.
+// It replaces the legacy /render/d/:uid and /render/d-solo/:uid endpoints, and requires access to view the dashboard.
+type RenderConnector struct {
+	dashboards dashboards.DashboardService
+	renderer   rendering.Service
+	cfg        *setting.Cfg
+	newFunc    func() runtime.Object
+	log        log.Logger
+}
+
+func NewRenderConnector(
+	dashboardService dashboards.DashboardService,
+	renderer rendering.Service,
+	cfg *setting.Cfg,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &RenderConnector{
+		dashboards: dashboardService,
+		renderer:   renderer,
+		cfg:        cfg,
+		newFunc:    newFunc,
+		log:        log.New("grafana-apiserver.dashboards.render"),
+	}
+}
+
+var (
+	_ rest.Connecter       = (*RenderConnector)(nil)
+	_ rest.StorageMetadata = (*RenderConnector)(nil)
+)
+
+func (r *RenderConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *RenderConnector) Destroy() {
+}
+
+func (r *RenderConnector) ConnectMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func (r *RenderConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *RenderConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"image/png", "application/pdf"}
+}
+
+func (r *RenderConnector) ProducesObject(verb string) interface{} {
+	return ""
+}
+
+func (r *RenderConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		params, err := parseRenderParams(req.URL.Query(), r.cfg)
+		if err != nil {
+			responder.Error(apierrors.NewBadRequest(err.Error()))
+			return
+		}
+
+		if !r.renderer.IsAvailable(req.Context()) {
+			responder.Error(apierrors.NewServiceUnavailable("image renderer is not available"))
+			return
+		}
+
+		userID, err := identity.UserIdentifier(user.GetID())
+		if err != nil {
+			r.log.Debug("Failed to parse user id", "err", err)
+		}
+
+		headers := http.Header{}
+		if lang := req.Header.Values("Accept-Language"); len(lang) > 0 {
+			headers["Accept-Language"] = lang
+		}
+
+		result, err := r.renderer.Render(req.Context(), params.renderType, rendering.Opts{
+			CommonOpts: rendering.CommonOpts{
+				TimeoutOpts: rendering.TimeoutOpts{
+					Timeout: params.timeout,
+				},
+				AuthOpts: rendering.AuthOpts{
+					OrgID:   info.OrgID,
+					UserID:  userID,
+					OrgRole: user.GetOrgRole(),
+				},
+				Path:            params.path(dash),
+				Timezone:        params.timezone,
+				ConcurrentLimit: r.cfg.RendererConcurrentRequestLimit,
+				Headers:         headers,
+			},
+			ErrorOpts: rendering.ErrorOpts{
+				ErrorConcurrentLimitReached: true,
+				ErrorRenderUnavailable:      true,
+			},
+			Width:             params.width,
+			Height:            params.height,
+			DeviceScaleFactor: params.scale,
+			Theme:             params.theme,
+		}, nil)
+		if err != nil {
+			switch {
+			case errors.Is(err, rendering.ErrTimeout):
+				responder.Error(apierrors.NewTimeoutError(err.Error(), 0))
+			case errors.Is(err, rendering.ErrConcurrentLimitReached):
+				responder.Error(apierrors.NewTooManyRequests(err.Error(), 0))
+			case errors.Is(err, rendering.ErrRenderUnavailable):
+				responder.Error(apierrors.NewServiceUnavailable(err.Error()))
+			default:
+				responder.Error(apierrors.NewInternalError(fmt.Errorf("rendering failed: %w", err)))
+			}
+			return
+		}
+
+		if params.renderType == rendering.RenderPDF {
+			w.Header().Set("Content-Type", "application/pdf")
+		} else {
+			w.Header().Set("Content-Type", "image/png")
+		}
+		w.Header().Set("Cache-Control", "private")
+		http.ServeFile(w, req, result.FilePath)
+	}), nil
+}
+
+type renderParams struct {
+	renderType rendering.RenderType
+	panelID    int64
+	from       string
+	to         string
+	timezone   string
+	theme      models.Theme
+	width      int
+	height     int
+	scale      float64
+	timeout    time.Duration
+}
+
+// path returns the frontend URL the renderer loads
+func (p renderParams) path(dash *dashboards.Dashboard) string {
+	u := url.URL{}
+	q := u.Query()
+	q.Set("orgId", strconv.FormatInt(dash.OrgID, 10))
+	if p.panelID > 0 {
+		u.Path = path.Join("d-solo", dash.UID, dash.Slug)
+		q.Set("panelId", strconv.FormatInt(p.panelID, 10))
+	} else {
+		u.Path = path.Join("d", dash.UID, dash.Slug)
+	}
+	if p.from != "" {
+		q.Set("from", p.from)
+	}
+	if p.to != "" {
+		q.Set("to", p.to)
+	}
+	if p.timezone != "" {
+		q.Set("tz", p.timezone)
+	}
+	q.Set("width", strconv.Itoa(p.width))
+	q.Set("height", strconv.Itoa(p.height))
+	q.Set("theme", string(p.theme))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func parseRenderParams(query url.Values, cfg *setting.Cfg) (renderParams, error) {
+	var err error
+	p := renderParams{
+		renderType: rendering.RenderPNG,
+		from:       query.Get("from"),
+		to:         query.Get("to"),
+		timezone:   query.Get("tz"),
+		theme:      models.ThemeDark,
+		width:      cfg.RendererDefaultImageWidth,
+		height:     cfg.RendererDefaultImageHeight,
+		scale:      cfg.RendererDefaultImageScale,
+		timeout:    60 * time.Second,
+	}
+
+	switch format := query.Get("format"); format {
+	case "", "png":
+	case "pdf":
+		p.renderType = rendering.RenderPDF
+	default:
+		return p, fmt.Errorf("invalid format %q, expected png or pdf", format)
+	}
+
+	if v := query.Get("theme"); v != "" {
+		if p.theme, err = models.ParseTheme(v); err != nil {
+			return p, fmt.Errorf("invalid theme %q, expected light or dark", v)
+		}
+	}
+	if v := query.Get("panelId"); v != "" {
+		if p.panelID, err = strconv.ParseInt(v, 10, 64); err != nil || p.panelID < 1 {
+			return p, fmt.Errorf("invalid panelId %q", v)
+		}
+	}
+	if v := query.Get("width"); v != "" {
+		if p.width, err = strconv.Atoi(v); err != nil || p.width < 1 {
+			return p, fmt.Errorf("invalid width %q", v)
+		}
+	}
+	if v := query.Get("height"); v != "" {
+		if p.height, err = strconv.Atoi(v); err != nil || p.height < 1 {
+			return p, fmt.Errorf("invalid height %q", v)
+		}
+	}
+	if v := query.Get("scale"); v != "" {
+		if p.scale, err = strconv.ParseFloat(v, 64); err != nil || p.scale <= 0 {
+			return p, fmt.Errorf("invalid scale %q", v)
+		}
+	}
+	if v := query.Get("timeout"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 1 {
+			return p, fmt.Errorf("invalid timeout %q, expected seconds", v)
+		}
+		p.timeout = time.Duration(seconds) * time.Second
+	}
+	return p, nil
+}
@@ -0,0 +1,67 @@
+package dashboard
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRenderParams(t *testing.T) {
+	cfg := &setting.Cfg{
+		RendererDefaultImageWidth:  1000,
+		RendererDefaultImageHeight: 500,
+		RendererDefaultImageScale:  1,
+	}
+	dash := &dashboards.Dashboard{UID: "abc", Slug: "my-dash", OrgID: 2}
+
+	t.Run("defaults to a png of the dashboard", func(t *testing.T) {
+		p, err := parseRenderParams(url.Values{}, cfg)
+		require.NoError(t, err)
+		require.Equal(t, rendering.RenderPNG, p.renderType)
+		require.Equal(t, models.ThemeDark, p.theme)
+		require.Equal(t, 60*time.Second, p.timeout)
+		require.Equal(t, "d/abc/my-dash?height=500&orgId=2&theme=dark&width=1000", p.path(dash))
+	})
+
+	t.Run("renders a single panel", func(t *testing.T) {
+		p, err := parseRenderParams(url.Values{
+			"panelId": {"4"},
+			"from":    {"now-1h"},
+			"to":      {"now"},
+			"theme":   {"light"},
+			"width":   {"300"},
+			"height":  {"200"},
+			"scale":   {"2"},
+			"tz":      {"UTC"},
+			"format":  {"pdf"},
+			"timeout": {"10"},
+		}, cfg)
+		require.NoError(t, err)
+		require.Equal(t, rendering.RenderPDF, p.renderType)
+		require.Equal(t, 2.0, p.scale)
+		require.Equal(t, 10*time.Second, p.timeout)
+		require.Equal(t, "d-solo/abc/my-dash?from=now-1h&height=200&orgId=2&panelId=4&theme=light&to=now&tz=UTC&width=300", p.path(dash))
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for key, value := range map[string]string{
+			"format":  "svg",
+			"theme":   "blue",
+			"panelId": "x",
+			"width":   "0",
+			"height":  "-1",
+			"scale":   "0",
+			"timeout": "soon",
+		} {
+			_, err := parseRenderParams(url.Values{key: {value}}, cfg)
+			require.Error(t, err, key)
+		}
+	})
+}
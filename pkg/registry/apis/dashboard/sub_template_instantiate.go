@@ -0,0 +1,95 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+// The instantiate subresource of dashboard templates returns the dashboard of a template with its variables
+// replaced, ready to be created by the create flow:
+//
+//	POST .../dashboardtemplates/{name}/instantiate  {"title": "...", "variables": {"name": "value"}}
+//
+// The dashboard is not saved, the client creates it with the dashboards API so the folder permissions and
+// quotas apply as for any new dashboard.
+type TemplateInstantiateConnector struct {
+	templates rest.Getter
+}
+
+func NewTemplateInstantiateConnector(templates rest.Getter) rest.Storage {
+	return &TemplateInstantiateConnector{templates: templates}
+}
+
+var (
+	_ rest.Connecter       = (*TemplateInstantiateConnector)(nil)
+	_ rest.StorageMetadata = (*TemplateInstantiateConnector)(nil)
+)
+
+func (r *TemplateInstantiateConnector) New() runtime.Object {
+	return &dashboardv0alpha1.Dashboard{}
+}
+
+func (r *TemplateInstantiateConnector) Destroy() {
+}
+
+func (r *TemplateInstantiateConnector) ConnectMethods() []string {
+	return []string{http.MethodPost}
+}
+
+func (r *TemplateInstantiateConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *TemplateInstantiateConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *TemplateInstantiateConnector) ProducesObject(verb string) interface{} {
+	return &dashboardv0alpha1.Dashboard{}
+}
+
+func (r *TemplateInstantiateConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := r.templates.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	t, ok := obj.(*dashboardv0alpha1.DashboardTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected dashboard template")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		instance := DashboardTemplateInstance{}
+		if req.ContentLength != 0 {
+			if err := json.NewDecoder(req.Body).Decode(&instance); err != nil {
+				responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+				return
+			}
+		}
+		spec, err := instantiateTemplate(t, instance)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		writeJSON(w, http.StatusOK, &dashboardv0alpha1.Dashboard{
+			TypeMeta:   metav1.TypeMeta{APIVersion: dashboardv0alpha1.APIVERSION, Kind: dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind().Kind},
+			ObjectMeta: metav1.ObjectMeta{Namespace: info.Value},
+			Spec:       commonV0.Unstructured{Object: spec},
+		}, responder)
+	}), nil
+}
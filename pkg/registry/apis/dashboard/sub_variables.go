@@ -0,0 +1,297 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+const (
+	variablesResolvePath = "resolve"
+	variableQueryRefID   = "variable"
+)
+
+var variablesGroupResource = schema.GroupResource{Group: dashboard.GROUP, Resource: "variables"}
+
+// VariablesResolveRequest sets the values of the variables, a variable that is not set keeps its saved value
+type VariablesResolveRequest struct {
+	// The values by variable name, either a single value or a list of values
+	Values map[string]any `json:"values,omitempty"`
+
+	// The time range query variables are evaluated with, defaults to the last 6 hours
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// VariablesResolveResponse is the dashboard spec with its variables interpolated
+type VariablesResolveResponse struct {
+	Spec      map[string]any     `json:"spec"`
+	Variables []ResolvedVariable `json:"variables"`
+}
+
+// The variables subresource resolves the template variables of a dashboard server side, so clients
+// rendering dashboards outside the frontend do not need to evaluate them:
+//
+//	POST .../variables/resolve
+//
+// Query variables are evaluated against their datasources with the permissions of the user.
+type VariablesConnector struct {
+	dashboards dashboards.DashboardService
+	query      query.Service
+	newFunc    func() runtime.Object
+	log        log.Logger
+}
+
+func NewVariablesConnector(
+	dashboardService dashboards.DashboardService,
+	queryService query.Service,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &VariablesConnector{
+		dashboards: dashboardService,
+		query:      queryService,
+		newFunc:    newFunc,
+		log:        log.New("grafana-apiserver.dashboards.variables"),
+	}
+}
+
+var (
+	_ rest.Connecter       = (*VariablesConnector)(nil)
+	_ rest.StorageMetadata = (*VariablesConnector)(nil)
+)
+
+func (r *VariablesConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *VariablesConnector) Destroy() {
+}
+
+func (r *VariablesConnector) ConnectMethods() []string {
+	return []string{http.MethodPost}
+}
+
+func (r *VariablesConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, true, "" // the trailing path is the action
+}
+
+func (r *VariablesConnector) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+func (r *VariablesConnector) ProducesObject(verb string) interface{} {
+	return &VariablesResolveResponse{}
+}
+
+func (r *VariablesConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		idx := strings.LastIndex(req.URL.Path, "/variables")
+		if idx < 0 || strings.Trim(req.URL.Path[idx+len("/variables"):], "/") != variablesResolvePath {
+			responder.Error(apierrors.NewNotFound(variablesGroupResource, name))
+			return
+		}
+
+		cmd := VariablesResolveRequest{}
+		if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+			responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+			return
+		}
+		if cmd.From == "" {
+			cmd.From = "now-6h"
+		}
+		if cmd.To == "" {
+			cmd.To = "now"
+		}
+
+		requested := make(map[string][]string, len(cmd.Values))
+		for k, v := range cmd.Values {
+			requested[k] = toStrings(v)
+		}
+
+		spec := map[string]any{}
+		if dash.Data != nil {
+			spec, _ = dash.Data.Interface().(map[string]any)
+		}
+
+		resolved, err := resolveVariables(req.Context(), readTemplateVariables(spec), requested, r.querier(user, cmd.From, cmd.To))
+		if err != nil {
+			responder.Error(apierrors.NewBadRequest(err.Error()))
+			return
+		}
+		writeJSON(w, http.StatusOK, VariablesResolveResponse{
+			Spec:      interpolateSpec(spec, resolved),
+			Variables: resolved,
+		}, responder)
+	}), nil
+}
+
+// querier runs the query of a variable with the query service, the options are read from the returned frames
+func (r *VariablesConnector) querier(user identity.Requester, from, to string) variableQuerier {
+	return func(ctx context.Context, datasource any, q map[string]any) ([]VariableOption, error) {
+		if uid, ok := datasource.(string); ok {
+			datasource = map[string]any{"uid": uid}
+		}
+		model := make(map[string]any, len(q)+2)
+		for k, v := range q {
+			model[k] = v
+		}
+		model["refId"] = variableQueryRefID
+		if datasource != nil {
+			model["datasource"] = datasource
+		}
+
+		rsp, err := r.query.QueryData(ctx, user, false, dtos.MetricRequest{
+			From:    from,
+			To:      to,
+			Queries: []*simplejson.Json{simplejson.NewFromAny(model)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		res, ok := rsp.Responses[variableQueryRefID]
+		if !ok {
+			return []VariableOption{}, nil
+		}
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		return variableOptionsFromFrames(res.Frames), nil
+	}
+}
+
+// variableOptionsFromFrames reads the options from the text and value fields, or from the first field of each frame
+func variableOptionsFromFrames(frames data.Frames) []VariableOption {
+	options := []VariableOption{}
+	for _, frame := range frames {
+		if frame == nil || len(frame.Fields) == 0 {
+			continue
+		}
+		var text, value *data.Field
+		for _, f := range frame.Fields {
+			switch f.Name {
+			case "__text", "text":
+				text = f
+			case "__value", "value":
+				value = f
+			}
+		}
+		if text == nil && value == nil {
+			text = frame.Fields[0]
+		}
+		if text == nil {
+			text = value
+		}
+		if value == nil {
+			value = text
+		}
+		for i := 0; i < text.Len(); i++ {
+			t, ok := text.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			v, ok := value.ConcreteAt(i)
+			if !ok {
+				v = t
+			}
+			options = append(options, VariableOption{Text: fmt.Sprint(t), Value: fmt.Sprint(v)})
+		}
+	}
+	return options
+}
+
+// interpolateSpec replaces the variables in the spec and saves the resolved values as the current values
+func interpolateSpec(spec map[string]any, resolved []ResolvedVariable) map[string]any {
+	values := make(map[string]ResolvedVariable, len(resolved))
+	for _, v := range resolved {
+		values[v.Name] = v
+	}
+
+	out := make(map[string]any, len(spec))
+	for k, v := range spec {
+		if k == "templating" {
+			out[k] = v
+			continue
+		}
+		out[k] = interpolateJSON(v, values)
+	}
+
+	templating, _ := spec["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+	if list == nil {
+		return out
+	}
+	updated := make([]any, 0, len(list))
+	for _, item := range list {
+		raw, ok := item.(map[string]any)
+		if !ok {
+			updated = append(updated, item)
+			continue
+		}
+		name, _ := raw["name"].(string)
+		v, ok := values[name]
+		if !ok {
+			updated = append(updated, item)
+			continue
+		}
+		copied := make(map[string]any, len(raw))
+		for k, val := range raw {
+			copied[k] = val
+		}
+		copied["current"] = currentVariableValue(v)
+		if v.Options != nil {
+			copied["options"] = v.Options
+		}
+		updated = append(updated, copied)
+	}
+	copiedTemplating := make(map[string]any, len(templating))
+	for k, val := range templating {
+		copiedTemplating[k] = val
+	}
+	copiedTemplating["list"] = updated
+	out["templating"] = copiedTemplating
+	return out
+}
+
+// currentVariableValue is the current value as the frontend saves it, single values are not wrapped in a list
+func currentVariableValue(v ResolvedVariable) map[string]any {
+	if len(v.Value) == 1 && len(v.Text) == 1 {
+		return map[string]any{"text": v.Text[0], "value": v.Value[0]}
+	}
+	return map[string]any{"text": v.Text, "value": v.Value}
+}
@@ -0,0 +1,329 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+const (
+	defaultVersionsLimit = 100
+	maxVersionsLimit     = 1000
+)
+
+// DashboardVersionLabelCommand is the body used to label or pin a version, only the fields that are set are changed
+type DashboardVersionLabelCommand struct {
+	Label  *string `json:"label,omitempty"`
+	Pinned *bool   `json:"pinned,omitempty"`
+}
+
+// The versions subresource lists the saved versions of a dashboard, newest first.
+// The list is paged with the limit and continue parameters, like the lists of the resources, and filtered with the
+// createdBy, from, to and message parameters of VersionsQueryOptions.
+// A version is labeled or pinned with a PATCH of versions/{version}, pinned versions are not deleted by the clean up.
+type VersionsConnector struct {
+	dashboards dashboards.DashboardService
+	versions   dashver.Service
+	newFunc    func() runtime.Object
+}
+
+func NewVersionsConnector(
+	dashboardService dashboards.DashboardService,
+	versions dashver.Service,
+	newFunc func() runtime.Object,
+) rest.Storage {
+	return &VersionsConnector{
+		dashboards: dashboardService,
+		versions:   versions,
+		newFunc:    newFunc,
+	}
+}
+
+var (
+	_ rest.Connecter       = (*VersionsConnector)(nil)
+	_ rest.StorageMetadata = (*VersionsConnector)(nil)
+)
+
+func (r *VersionsConnector) New() runtime.Object {
+	return r.newFunc()
+}
+
+func (r *VersionsConnector) Destroy() {
+}
+
+func (r *VersionsConnector) ConnectMethods() []string {
+	return []string{http.MethodGet, http.MethodPatch}
+}
+
+func (r *VersionsConnector) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, true, "" // the trailing path is the version
+}
+
+func (r *VersionsConnector) ProducesMIMETypes(verb string) []string {
+	return nil
+}
+
+func (r *VersionsConnector) ProducesObject(verb string) interface{} {
+	return &dashboard.DashboardVersionList{}
+}
+
+func (r *VersionsConnector) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: name, OrgID: info.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), name)
+		}
+		return nil, err
+	}
+
+	guardian, err := guardian.NewByDashboard(ctx, dash, info.OrgID, user)
+	if err != nil {
+		return nil, err
+	}
+	canView, err := guardian.CanView()
+	if err != nil || !canView {
+		return nil, apierrors.NewForbidden(dashboard.DashboardResourceInfo.GroupResource(), name, fmt.Errorf("not allowed to view"))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		version, err := versionFromPath(req.URL.Path)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		switch {
+		case req.Method == http.MethodGet && version == 0:
+			list, err := r.list(req.Context(), dash, req.URL.Query())
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			responder.Object(http.StatusOK, list)
+		case req.Method == http.MethodPatch && version != 0:
+			canSave, err := guardian.CanSave()
+			if err != nil || !canSave {
+				responder.Error(apierrors.NewForbidden(dashboard.DashboardResourceInfo.GroupResource(), name, fmt.Errorf("not allowed to edit")))
+				return
+			}
+			cmd := DashboardVersionLabelCommand{}
+			if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+				responder.Error(apierrors.NewBadRequest(fmt.Sprintf("bad request data: %s", err)))
+				return
+			}
+			info, err := r.updateLabel(req.Context(), dash, version, cmd)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			writeJSON(w, http.StatusOK, info, responder)
+		default:
+			responder.Error(apierrors.NewMethodNotSupported(dashboard.DashboardResourceInfo.GroupResource(), req.Method))
+		}
+	}), nil
+}
+
+func (r *VersionsConnector) updateLabel(ctx context.Context, dash *dashboards.Dashboard, version int, cmd DashboardVersionLabelCommand) (*dashboard.DashboardVersionInfo, error) {
+	updated, err := r.versions.UpdateLabel(ctx, &dashver.UpdateLabelCommand{
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		OrgID:        dash.OrgID,
+		Version:      version,
+		Label:        cmd.Label,
+		Pinned:       cmd.Pinned,
+	})
+	switch {
+	case errors.Is(err, dashver.ErrDashboardVersionNotFound):
+		return nil, apierrors.NewNotFound(dashboard.DashboardResourceInfo.GroupResource(), fmt.Sprintf("%s/versions/%d", dash.UID, version))
+	case errors.Is(err, dashver.ErrDashboardVersionLabelTooLong):
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the label is longer than %d characters", dashver.MaxLabelLength))
+	case err != nil:
+		return nil, err
+	}
+	info := toDashboardVersionInfo(updated)
+	return &info, nil
+}
+
+// list returns a page of versions. One more version than the limit is read to know if there is a next page,
+// the continue token of the next page is the oldest version of the current one.
+func (r *VersionsConnector) list(ctx context.Context, dash *dashboards.Dashboard, params url.Values) (*dashboard.DashboardVersionList, error) {
+	limit := defaultVersionsLimit
+	if v := params.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 0 {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid limit parameter: %q", v))
+		}
+		if l > 0 {
+			limit = min(l, maxVersionsLimit)
+		}
+	}
+	before, err := decodeVersionsContinue(params.Get("continue"))
+	if err != nil {
+		return nil, err
+	}
+	filters, err := versionsFilters(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &dashver.ListDashboardVersionsQuery{
+		DashboardID:   dash.ID,
+		DashboardUID:  dash.UID,
+		OrgID:         dash.OrgID,
+		Limit:         limit + 1,
+		BeforeVersion: before,
+		Message:       filters.Message,
+	}
+	if filters.CreatedBy != "" {
+		query.CreatedBy, err = versionCreatedByID(filters.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if filters.From > 0 {
+		query.From = time.UnixMilli(filters.From)
+	}
+	if filters.To > 0 {
+		query.To = time.UnixMilli(filters.To)
+	}
+	versions, err := r.versions.List(ctx, query)
+	if err != nil && !errors.Is(err, dashver.ErrNoVersionsForDashboardID) {
+		return nil, err
+	}
+
+	list := &dashboard.DashboardVersionList{Items: make([]dashboard.DashboardVersionInfo, 0, min(len(versions), limit))}
+	for i, v := range versions {
+		if i == limit {
+			list.Continue = encodeVersionsContinue(versions[i-1].Version)
+			break
+		}
+		list.Items = append(list.Items, toDashboardVersionInfo(v))
+	}
+	return list, nil
+}
+
+func toDashboardVersionInfo(v *dashver.DashboardVersionDTO) dashboard.DashboardVersionInfo {
+	return dashboard.DashboardVersionInfo{
+		Version:       v.Version,
+		ParentVersion: v.ParentVersion,
+		Created:       v.Created.UnixMilli(),
+		CreatedBy:     versionCreatedBy(v.CreatedBy),
+		Message:       v.Message,
+		Label:         v.Label,
+		Pinned:        v.Pinned,
+	}
+}
+
+// versionFromPath returns the version in the trailing path of the versions subresource, zero when there is none
+func versionFromPath(path string) (int, error) {
+	idx := strings.LastIndex(path, "/versions")
+	if idx < 0 {
+		return 0, apierrors.NewBadRequest("expected versions path")
+	}
+	raw := strings.Trim(path[idx+len("/versions"):], "/")
+	if raw == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version <= 0 {
+		return 0, apierrors.NewBadRequest(fmt.Sprintf("invalid version: %q", raw))
+	}
+	return version, nil
+}
+
+func encodeVersionsContinue(before int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(before)))
+}
+
+// decodeVersionsContinue returns the version the next page starts before, zero starts at the newest version
+func decodeVersionsContinue(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, apierrors.NewBadRequest("invalid continue token")
+	}
+	before, err := strconv.Atoi(string(raw))
+	if err != nil || before <= 0 {
+		return 0, apierrors.NewBadRequest("invalid continue token")
+	}
+	return before, nil
+}
+
+// versionsFilters reads the filters of the versions list from the query parameters
+func versionsFilters(params url.Values) (*dashboard.VersionsQueryOptions, error) {
+	opts := &dashboard.VersionsQueryOptions{
+		CreatedBy: params.Get("createdBy"),
+		Message:   params.Get("message"),
+	}
+	for name, ms := range map[string]*int64{"from": &opts.From, "to": &opts.To} {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid %s parameter, expected milliseconds since the epoch: %q", name, v))
+		}
+		*ms = parsed
+	}
+	if opts.From > 0 && opts.To > 0 && opts.From > opts.To {
+		return nil, apierrors.NewBadRequest("the from parameter is after the to parameter")
+	}
+	return opts, nil
+}
+
+// versionCreatedByID returns the id of the versions table of an author as it is listed in createdBy, the inverse of
+// versionCreatedBy
+func versionCreatedByID(createdBy string) (int64, error) {
+	if createdBy == versionCreatedBy(-1) {
+		return -1, nil
+	}
+	typ, id, err := identity.ParseTypeAndID(createdBy)
+	if err == nil && typ == claims.TypeUser {
+		if userID, err := strconv.ParseInt(id, 10, 64); err == nil && userID > 0 {
+			return userID, nil
+		}
+	}
+	return 0, apierrors.NewBadRequest(fmt.Sprintf("invalid createdBy parameter, expected user:<id> or provisioning: %q", createdBy))
+}
+
+func versionCreatedBy(id int64) string {
+	switch {
+	case id > 0:
+		return identity.NewTypedID(claims.TypeUser, id)
+	case id == -1:
+		return identity.NewTypedIDString(claims.TypeProvisioning, "")
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package dashboard
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/dashboardversion/dashvertest"
+)
+
+func TestVersionsConnectorList(t *testing.T) {
+	created := time.Date(2024, 11, 26, 10, 0, 0, 0, time.UTC)
+	versions := dashvertest.NewDashboardVersionServiceFake()
+	versions.ExpectedListDashboarVersions = []*dashver.DashboardVersionDTO{
+		{Version: 3, ParentVersion: 2, Created: created, CreatedBy: 1, Message: "third"},
+		{Version: 2, ParentVersion: 1, Created: created, CreatedBy: -1},
+		{Version: 1, Created: created},
+	}
+	r := &VersionsConnector{versions: versions}
+	dash := &dashboards.Dashboard{ID: 1, UID: "abc", OrgID: 1}
+
+	list, err := r.list(context.Background(), dash, url.Values{"limit": {"2"}})
+	require.NoError(t, err)
+	require.Equal(t, []dashboard.DashboardVersionInfo{
+		{Version: 3, ParentVersion: 2, Created: created.UnixMilli(), CreatedBy: "user:1", Message: "third"},
+		{Version: 2, ParentVersion: 1, Created: created.UnixMilli(), CreatedBy: "provisioning:"},
+	}, list.Items)
+	require.Equal(t, encodeVersionsContinue(2), list.Continue)
+
+	list, err = r.list(context.Background(), dash, url.Values{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 3)
+	require.Empty(t, list.Continue, "the last page has no continue token")
+
+	versions.ExpectedListDashboarVersions = nil
+	versions.ExpectedError = dashver.ErrNoVersionsForDashboardID
+	list, err = r.list(context.Background(), dash, url.Values{"continue": {encodeVersionsContinue(1)}})
+	require.NoError(t, err)
+	require.Empty(t, list.Items)
+
+	_, err = r.list(context.Background(), dash, url.Values{"limit": {"-1"}})
+	require.Error(t, err)
+}
+
+func TestVersionsConnectorListFilters(t *testing.T) {
+	versions := dashvertest.NewDashboardVersionServiceFake()
+	r := &VersionsConnector{versions: versions}
+	dash := &dashboards.Dashboard{ID: 1, UID: "abc", OrgID: 1}
+
+	_, err := r.list(context.Background(), dash, url.Values{
+		"createdBy": {"user:2"},
+		"from":      {"1732615200000"},
+		"to":        {"1732618800000"},
+		"message":   {"restore"},
+	})
+	require.NoError(t, err)
+	require.Len(t, versions.ListQueries, 1)
+	query := versions.ListQueries[0]
+	require.Equal(t, int64(2), query.CreatedBy)
+	require.True(t, query.From.Equal(time.Date(2024, 11, 26, 10, 0, 0, 0, time.UTC)))
+	require.True(t, query.To.Equal(time.Date(2024, 11, 26, 11, 0, 0, 0, time.UTC)))
+	require.Equal(t, "restore", query.Message)
+
+	_, err = r.list(context.Background(), dash, url.Values{"createdBy": {"provisioning:"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), versions.ListQueries[1].CreatedBy)
+	require.True(t, versions.ListQueries[1].From.IsZero())
+	require.True(t, versions.ListQueries[1].To.IsZero())
+
+	for _, params := range []url.Values{
+		{"createdBy": {"user:abc"}},
+		{"createdBy": {"team:1"}},
+		{"createdBy": {"2"}},
+		{"from": {"yesterday"}},
+		{"to": {"-1"}},
+		{"from": {"1732618800000"}, "to": {"1732615200000"}},
+	} {
+		_, err = r.list(context.Background(), dash, params)
+		require.True(t, apierrors.IsBadRequest(err), "%v", params)
+	}
+	require.Len(t, versions.ListQueries, 2)
+}
+
+func TestVersionsConnectorUpdateLabel(t *testing.T) {
+	created := time.Date(2024, 11, 26, 10, 0, 0, 0, time.UTC)
+	versions := dashvertest.NewDashboardVersionServiceFake()
+	versions.ExpectedUpdatedVersion = &dashver.DashboardVersionDTO{Version: 2, Created: created, CreatedBy: 1, Label: "last known good", Pinned: true}
+	r := &VersionsConnector{versions: versions}
+	dash := &dashboards.Dashboard{ID: 1, UID: "abc", OrgID: 1}
+
+	label, pinned := "last known good", true
+	info, err := r.updateLabel(context.Background(), dash, 2, DashboardVersionLabelCommand{Label: &label, Pinned: &pinned})
+	require.NoError(t, err)
+	require.Equal(t, &dashboard.DashboardVersionInfo{
+		Version: 2, Created: created.UnixMilli(), CreatedBy: "user:1", Label: "last known good", Pinned: true,
+	}, info)
+	require.Equal(t, []*dashver.UpdateLabelCommand{
+		{DashboardID: 1, DashboardUID: "abc", OrgID: 1, Version: 2, Label: &label, Pinned: &pinned},
+	}, versions.UpdateLabelCommands)
+
+	versions.ExpectedError = dashver.ErrDashboardVersionNotFound
+	_, err = r.updateLabel(context.Background(), dash, 5, DashboardVersionLabelCommand{Pinned: &pinned})
+	require.True(t, apierrors.IsNotFound(err))
+
+	versions.ExpectedError = dashver.ErrDashboardVersionLabelTooLong
+	_, err = r.updateLabel(context.Background(), dash, 2, DashboardVersionLabelCommand{Label: &label})
+	require.True(t, apierrors.IsBadRequest(err))
+}
+
+func TestVersionFromPath(t *testing.T) {
+	base := "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/versions"
+	version, err := versionFromPath(base)
+	require.NoError(t, err)
+	require.Equal(t, 0, version)
+
+	version, err = versionFromPath(base + "/3")
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	for _, path := range []string{base + "/abc", base + "/0", "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards/abc/dto"} {
+		_, err = versionFromPath(path)
+		require.Error(t, err, path)
+	}
+}
+
+func TestVersionsContinueToken(t *testing.T) {
+	before, err := decodeVersionsContinue("")
+	require.NoError(t, err)
+	require.Equal(t, 0, before)
+
+	before, err = decodeVersionsContinue(encodeVersionsContinue(42))
+	require.NoError(t, err)
+	require.Equal(t, 42, before)
+
+	for _, token := range []string{"%%%", encodeVersionsContinue(0), "YWJj"} {
+		_, err = decodeVersionsContinue(token)
+		require.Error(t, err, token)
+	}
+}
@@ -0,0 +1,92 @@
+package dashboard
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/registry/apis/dashboard/legacy"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+// summaryStorage lists the dashboards without reading their spec when the response only has their metadata: the
+// tables whose rows do not include the objects, e.g. catalogs or kubectl get, and the lists of partial object
+// metadata. The items of these lists are the partial metadata of the dashboards, with their title in the
+// dashboard.AnnoKeyTitle annotation.
+type summaryStorage struct {
+	grafanarest.LegacyStorage
+	access legacy.DashboardAccess
+}
+
+// summaryWatchStorage keeps watch support of storages that implement it
+type summaryWatchStorage struct {
+	*summaryStorage
+	rest.Watcher
+}
+
+// WithSummaryList adds the lists of the metadata of the dashboards to the legacy storage
+func WithSummaryList(store grafanarest.LegacyStorage, access legacy.DashboardAccess) grafanarest.LegacyStorage {
+	if w, ok := store.(rest.Watcher); ok {
+		return &summaryWatchStorage{summaryStorage: &summaryStorage{LegacyStorage: store, access: access}, Watcher: w}
+	}
+	return &summaryStorage{LegacyStorage: store, access: access}
+}
+
+func (s *summaryStorage) List(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	// the summaries are not filtered, the selectors are left to the storage
+	if !listsMetadataOnly(ctx) || hasSelectors(options) {
+		return s.LegacyStorage.List(ctx, options)
+	}
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	query := legacy.DashboardSummaryQuery{OrgID: info.OrgID}
+	if options != nil {
+		query.Limit = options.Limit
+		query.Continue = options.Continue
+	}
+	return s.access.ListDashboardSummaries(ctx, query)
+}
+
+// listsMetadataOnly is true for the list requests whose response only has the metadata of the dashboards
+func listsMetadataOnly(ctx context.Context) bool {
+	info, ok := k8srequest.RequestInfoFrom(ctx)
+	if !ok || info.Verb != "list" || info.Subresource != "" {
+		return false
+	}
+	accept, ok := grafanarequest.AcceptHeaderFrom(ctx)
+	if !ok {
+		return false
+	}
+	// the response has the first media type, the others are fallbacks of clients
+	first, _, _ := strings.Cut(accept, ",")
+	_, params, err := mime.ParseMediaType(first)
+	if err != nil || params["g"] != metav1.GroupName {
+		return false
+	}
+	switch params["as"] {
+	case "PartialObjectMetadataList":
+		return true
+	case "Table":
+		policy, _ := grafanarequest.IncludeObjectFrom(ctx)
+		return policy != metav1.IncludeObject
+	}
+	return false
+}
+
+func hasSelectors(options *internalversion.ListOptions) bool {
+	if options == nil {
+		return false
+	}
+	return (options.LabelSelector != nil && !options.LabelSelector.Empty()) ||
+		(options.FieldSelector != nil && !options.FieldSelector.Empty())
+}
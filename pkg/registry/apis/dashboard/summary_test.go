@@ -0,0 +1,104 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	dashboardinternal "github.com/grafana/grafana/pkg/apis/dashboard"
+	dashboardv1alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v1alpha1"
+	grafanarequest "github.com/grafana/grafana/pkg/apiserver/endpoints/request"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/registry/apis/dashboard/legacy"
+)
+
+type fakeListStorage struct {
+	grafanarest.LegacyStorage
+}
+
+func (s *fakeListStorage) List(_ context.Context, _ *internalversion.ListOptions) (runtime.Object, error) {
+	return &dashboardv1alpha1.DashboardList{Items: []dashboardv1alpha1.Dashboard{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}}}, nil
+}
+
+type fakeWatchListStorage struct {
+	fakeListStorage
+	rest.Watcher
+}
+
+type fakeSummaryAccess struct {
+	legacy.DashboardAccess
+	queries []legacy.DashboardSummaryQuery
+}
+
+func (a *fakeSummaryAccess) ListDashboardSummaries(_ context.Context, query legacy.DashboardSummaryQuery) (*metav1.PartialObjectMetadataList, error) {
+	a.queries = append(a.queries, query)
+	return &metav1.PartialObjectMetadataList{Items: []metav1.PartialObjectMetadata{{ObjectMeta: metav1.ObjectMeta{
+		Name:        "a",
+		Annotations: map[string]string{dashboardinternal.AnnoKeyTitle: "A"},
+	}}}}, nil
+}
+
+func TestSummaryList(t *testing.T) {
+	list := func(t *testing.T, accept string, policy metav1.IncludeObjectPolicy, options *internalversion.ListOptions) (runtime.Object, *fakeSummaryAccess) {
+		t.Helper()
+		ctx := k8srequest.WithRequestInfo(context.Background(), &k8srequest.RequestInfo{Verb: "list", Resource: "dashboards"})
+		ctx = k8srequest.WithNamespace(ctx, "default")
+		ctx = grafanarequest.WithAcceptHeader(ctx, accept)
+		ctx = grafanarequest.WithIncludeObject(ctx, policy)
+		access := &fakeSummaryAccess{}
+		obj, err := WithSummaryList(&fakeListStorage{}, access).List(ctx, options)
+		require.NoError(t, err)
+		return obj, access
+	}
+	table := "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+	t.Run("lists the summaries for tables", func(t *testing.T) {
+		obj, access := list(t, table, "", &internalversion.ListOptions{Limit: 10, Continue: "org:1/start:5/folder:"})
+		require.IsType(t, &metav1.PartialObjectMetadataList{}, obj)
+		require.Equal(t, []legacy.DashboardSummaryQuery{{OrgID: 1, Limit: 10, Continue: "org:1/start:5/folder:"}}, access.queries)
+
+		out, err := dashboardv1alpha1.DashboardResourceInfo.TableConverter().ConvertToTable(context.Background(), obj, nil)
+		require.NoError(t, err)
+		require.Equal(t, "A", out.Rows[0].Cells[1])
+	})
+
+	t.Run("lists the summaries for partial object metadata", func(t *testing.T) {
+		obj, _ := list(t, "application/json;as=PartialObjectMetadataList;v=v1;g=meta.k8s.io", "", nil)
+		require.IsType(t, &metav1.PartialObjectMetadataList{}, obj)
+	})
+
+	t.Run("lists the dashboards when the response has the objects", func(t *testing.T) {
+		for name, accept := range map[string]string{
+			"json":            "application/json",
+			"fallback":        "application/json,application/json;as=Table;v=v1;g=meta.k8s.io",
+			"no accept":       "",
+			"other api group": "application/json;as=Table;v=v1;g=example.com",
+		} {
+			obj, access := list(t, accept, "", nil)
+			require.IsType(t, &dashboardv1alpha1.DashboardList{}, obj, name)
+			require.Empty(t, access.queries, name)
+		}
+
+		obj, _ := list(t, table, metav1.IncludeObject, nil)
+		require.IsType(t, &dashboardv1alpha1.DashboardList{}, obj, "the rows include the objects")
+	})
+
+	t.Run("lists the dashboards with selectors", func(t *testing.T) {
+		obj, _ := list(t, table, "", &internalversion.ListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{"a": "b"})})
+		require.IsType(t, &dashboardv1alpha1.DashboardList{}, obj)
+	})
+
+	t.Run("keeps watch support", func(t *testing.T) {
+		_, ok := WithSummaryList(&fakeListStorage{}, nil).(rest.Watcher)
+		require.False(t, ok)
+		_, ok = WithSummaryList(&fakeWatchListStorage{}, nil).(rest.Watcher)
+		require.True(t, ok)
+	})
+}
@@ -0,0 +1,228 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+const (
+	// maxTags limits how many tags are listed
+	maxTags = 1000
+
+	// tagSearchPageSize is the number of dashboards loaded at once when updating tags
+	tagSearchPageSize = 500
+)
+
+var (
+	// ErrInvalidTagCommand is returned when a tag rename or merge cannot be applied because of its content.
+	ErrInvalidTagCommand = errutil.BadRequest("dashboards.tags.invalid")
+	// ErrTagAccessDenied is returned when a non admin user tries to rename or merge tags.
+	ErrTagAccessDenied = errutil.Forbidden("dashboards.tags.forbidden", errutil.WithPublicMessage("Only org admins can rename or merge tags"))
+	// ErrTagUpdateNotAtomic is returned when the dashboards are not stored in the database of the transaction of a
+	// rename or merge, it could then be partially applied.
+	ErrTagUpdateNotAtomic = errutil.NotImplemented("dashboards.tags.notAtomic",
+		errutil.WithPublicMessage("Tags can not be renamed or merged while folders or dashboards are stored in unified storage"))
+)
+
+// TagCount is a tag and the number of dashboards using it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// TagList is the list of tags used by the dashboards of a namespace, most used first.
+type TagList struct {
+	Tags []TagCount `json:"tags"`
+}
+
+// RenameTagCommand renames a tag on every dashboard. Renaming to a tag that is already used fails, use MergeTagsCommand instead.
+type RenameTagCommand struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MergeTagsCommand replaces a tag with another one on every dashboard, dashboards keep a single copy of the target tag.
+type MergeTagsCommand struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// TagUpdateResult is the outcome of a rename or merge.
+type TagUpdateResult struct {
+	Dashboards []string `json:"dashboards"`
+}
+
+// TagManager lists dashboard tags from the search index, and renames or merges them with the legacy dashboard service.
+type TagManager struct {
+	db         db.DB
+	features   featuremgmt.FeatureToggles
+	cfg        *setting.Cfg
+	index      resource.ResourceIndexClient
+	dashboards dashboards.DashboardService
+	log        log.Logger
+}
+
+func NewTagManager(sql db.DB, features featuremgmt.FeatureToggles, cfg *setting.Cfg, index resource.ResourceIndexClient, dashboardService dashboards.DashboardService) *TagManager {
+	return &TagManager{
+		db:         sql,
+		features:   features,
+		cfg:        cfg,
+		index:      index,
+		dashboards: dashboardService,
+		log:        log.New("dashboard.tags"),
+	}
+}
+
+// List returns the tags of the dashboards in the namespace of the user, with their usage counts.
+func (m *TagManager) List(ctx context.Context, user identity.Requester) (*TagList, error) {
+	rsp, err := m.index.Search(ctx, &resource.SearchRequest{
+		Tenant:  user.GetNamespace(),
+		Query:   "*",
+		Kind:    []string{"dashboard"},
+		Limit:   1, // only the facet is used
+		GroupBy: []*resource.GroupBy{{Name: "tags", Limit: maxTags}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := &TagList{Tags: make([]TagCount, 0, len(rsp.Groups))}
+	for _, group := range rsp.Groups {
+		list.Tags = append(list.Tags, TagCount{Tag: group.Name, Count: group.Count})
+	}
+	sort.SliceStable(list.Tags, func(i, j int) bool {
+		if list.Tags[i].Count != list.Tags[j].Count {
+			return list.Tags[i].Count > list.Tags[j].Count
+		}
+		return list.Tags[i].Tag < list.Tags[j].Tag
+	})
+	return list, nil
+}
+
+// Rename renames a tag on every dashboard of the org.
+func (m *TagManager) Rename(ctx context.Context, user identity.Requester, cmd RenameTagCommand) (*TagUpdateResult, error) {
+	if cmd.From == "" || cmd.To == "" {
+		return nil, ErrInvalidTagCommand.Errorf("both from and to are required")
+	}
+	if cmd.From == cmd.To {
+		return nil, ErrInvalidTagCommand.Errorf("tag %q is renamed to itself", cmd.From)
+	}
+	existing, err := m.dashboardsWithTag(ctx, user, cmd.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, ErrInvalidTagCommand.Errorf("tag %q is already used by %d dashboards, merge the tags instead", cmd.To, len(existing))
+	}
+	return m.replaceTag(ctx, user, cmd.From, cmd.To)
+}
+
+// Merge replaces the source tag with the target tag on every dashboard of the org.
+func (m *TagManager) Merge(ctx context.Context, user identity.Requester, cmd MergeTagsCommand) (*TagUpdateResult, error) {
+	if cmd.Source == "" || cmd.Target == "" {
+		return nil, ErrInvalidTagCommand.Errorf("both source and target are required")
+	}
+	if cmd.Source == cmd.Target {
+		return nil, ErrInvalidTagCommand.Errorf("tag %q is merged into itself", cmd.Source)
+	}
+	return m.replaceTag(ctx, user, cmd.Source, cmd.Target)
+}
+
+// replaceTag updates all dashboards using the tag in a single transaction. It is rejected with ErrTagUpdateNotAtomic
+// when the dashboards are not written in the transaction, see checkLegacyTransaction.
+func (m *TagManager) replaceTag(ctx context.Context, user identity.Requester, from string, to string) (*TagUpdateResult, error) {
+	if !user.HasRole(org.RoleAdmin) {
+		return nil, ErrTagAccessDenied.Errorf("user is not an org admin")
+	}
+	if err := checkLegacyTransaction(ctx, m.features, m.cfg); err != nil {
+		return nil, ErrTagUpdateNotAtomic.Errorf("%w", err)
+	}
+
+	uids, err := m.dashboardsWithTag(ctx, user, from)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TagUpdateResult{Dashboards: uids}
+	err = m.db.InTransaction(ctx, func(ctx context.Context) error {
+		for _, uid := range uids {
+			dash, err := m.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()})
+			if err != nil {
+				return fmt.Errorf("failed to load dashboard %s: %w", uid, err)
+			}
+			dash.Data.Set("tags", withTagReplaced(dash.Data.Get("tags").MustStringArray(), from, to))
+			_, err = m.dashboards.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+				OrgID:     user.GetOrgID(),
+				User:      user,
+				Message:   fmt.Sprintf("replaced tag %s with %s", from, to),
+				Dashboard: dash,
+			}, false)
+			if err != nil {
+				return fmt.Errorf("failed to save dashboard %s: %w", uid, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.log.Info("Replaced dashboard tag", "from", from, "to", to, "dashboards", len(uids), "orgId", user.GetOrgID())
+	return result, nil
+}
+
+// dashboardsWithTag returns the UIDs of all dashboards of the org using the tag.
+func (m *TagManager) dashboardsWithTag(ctx context.Context, user identity.Requester, tag string) ([]string, error) {
+	uids := []string{}
+	seen := map[string]bool{}
+	for page := int64(1); ; page++ {
+		hits, err := m.dashboards.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+			OrgId:        user.GetOrgID(),
+			SignedInUser: user,
+			Type:         string(model.DashHitDB),
+			Tags:         []string{tag},
+			Limit:        tagSearchPageSize,
+			Page:         page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			if !seen[hit.UID] {
+				seen[hit.UID] = true
+				uids = append(uids, hit.UID)
+			}
+		}
+		if len(hits) < tagSearchPageSize {
+			return uids, nil
+		}
+	}
+}
+
+// withTagReplaced returns the tags with from replaced by to, keeping a single copy of to and the order of the tags.
+func withTagReplaced(tags []string, from string, to string) []string {
+	out := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		if tag == from {
+			tag = to
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
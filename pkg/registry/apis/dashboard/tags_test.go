@@ -0,0 +1,35 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestWithTagReplaced(t *testing.T) {
+	require.Equal(t, []string{"a", "prod", "c"}, withTagReplaced([]string{"a", "production", "c"}, "production", "prod"))
+	require.Equal(t, []string{"prod", "a"}, withTagReplaced([]string{"prod", "a", "production"}, "production", "prod"), "keeps a single copy of the target")
+	require.Equal(t, []string{"a"}, withTagReplaced([]string{"a"}, "production", "prod"))
+	require.Equal(t, []string{}, withTagReplaced(nil, "production", "prod"))
+}
+
+func TestTagManagerAtomic(t *testing.T) {
+	admin := &identity.StaticRequester{OrgID: 1, OrgRole: org.RoleAdmin}
+	svc := dashboards.NewFakeDashboardService(t)
+	// the target of a rename is not used yet
+	svc.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{}, nil).Once()
+	m := NewTagManager(transactionDB{}, featuremgmt.WithFeatures(featuremgmt.FlagKubernetesFolders), setting.NewCfg(), nil, svc)
+
+	_, err := m.Rename(context.Background(), admin, RenameTagCommand{From: "production", To: "prod"})
+	require.ErrorIs(t, err, ErrTagUpdateNotAtomic)
+	_, err = m.Merge(context.Background(), admin, MergeTagsCommand{Source: "production", Target: "prod"})
+	require.ErrorIs(t, err, ErrTagUpdateNotAtomic)
+}
@@ -0,0 +1,159 @@
+package dashboard
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+)
+
+var (
+	// templatePlaceholder matches the {{name}} placeholders of the variables in the dashboard of a template
+	templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+	templateVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// DashboardTemplateInstance is the body of the instantiate subresource of a dashboard template
+type DashboardTemplateInstance struct {
+	// The title of the new dashboard, the title of the template by default
+	Title string `json:"title,omitempty"`
+
+	// The values of the variables of the template
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// ValidateTemplate rejects dashboard templates without a title, with invalid variables, or with placeholders in their
+// dashboard that are not variables of the template
+func ValidateTemplate(a admission.Attributes) error {
+	if (a.GetOperation() != admission.Create && a.GetOperation() != admission.Update) || a.GetSubresource() != "" {
+		return nil
+	}
+	if a.GetResource().Resource != dashboardv0alpha1.DashboardTemplateResourceInfo.GroupResource().Resource {
+		return nil
+	}
+	t, ok := a.GetObject().(*dashboardv0alpha1.DashboardTemplate)
+	if !ok {
+		return nil
+	}
+	if errs := validateTemplateSpec(&t.Spec, field.NewPath("spec")); len(errs) > 0 {
+		return apierrors.NewInvalid(dashboardv0alpha1.DashboardTemplateResourceInfo.GroupVersionKind().GroupKind(), a.GetName(), errs)
+	}
+	return nil
+}
+
+func validateTemplateSpec(spec *dashboardv0alpha1.DashboardTemplateSpec, root *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if strings.TrimSpace(spec.Title) == "" {
+		errs = append(errs, field.Required(root.Child("title"), "the template must have a title"))
+	}
+	if spec.Dashboard.Object == nil {
+		errs = append(errs, field.Required(root.Child("dashboard"), "the template must have a dashboard"))
+	}
+
+	declared := make(map[string]bool, len(spec.Variables))
+	for i, v := range spec.Variables {
+		path := root.Child("variables").Index(i).Child("name")
+		switch {
+		case !templateVariableName.MatchString(v.Name):
+			errs = append(errs, field.Invalid(path, v.Name, "must start with a letter or _, followed by letters, digits or _"))
+		case declared[v.Name]:
+			errs = append(errs, field.Duplicate(path, v.Name))
+		}
+		declared[v.Name] = true
+	}
+	for _, name := range templatePlaceholders(spec.Dashboard.Object) {
+		if !declared[name] {
+			errs = append(errs, field.Invalid(root.Child("dashboard"), "{{"+name+"}}", "the placeholder is not a variable of the template"))
+		}
+	}
+	return errs
+}
+
+// templatePlaceholders returns the sorted names of the placeholders in the string values of the dashboard
+func templatePlaceholders(dashboard map[string]any) []string {
+	found := map[string]bool{}
+	walkTemplateStrings(dashboard, func(s string) string {
+		for _, m := range templatePlaceholder.FindAllStringSubmatch(s, -1) {
+			found[m[1]] = true
+		}
+		return s
+	})
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// instantiateTemplate returns the dashboard of the template with the placeholders replaced with the values of the
+// instance, or the default values of the variables. The dashboard is a copy without uid, id and version, so it can
+// be created as a new dashboard.
+func instantiateTemplate(t *dashboardv0alpha1.DashboardTemplate, instance DashboardTemplateInstance) (map[string]any, error) {
+	values := make(map[string]string, len(t.Spec.Variables))
+	var missing []string
+	for _, v := range t.Spec.Variables {
+		if value, ok := instance.Variables[v.Name]; ok {
+			values[v.Name] = value
+		} else if v.Default != nil {
+			values[v.Name] = *v.Default
+		} else {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("missing values of the variables %s", strings.Join(missing, ", ")))
+	}
+	for name := range instance.Variables {
+		if _, ok := values[name]; !ok {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("%q is not a variable of the template", name))
+		}
+	}
+
+	dash := t.Spec.Dashboard.DeepCopy().Object
+	if dash == nil {
+		dash = map[string]any{}
+	}
+	walkTemplateStrings(dash, func(s string) string {
+		return templatePlaceholder.ReplaceAllStringFunc(s, func(p string) string {
+			name := templatePlaceholder.FindStringSubmatch(p)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return p
+		})
+	})
+	delete(dash, "uid")
+	delete(dash, "id")
+	delete(dash, "version")
+	dash["title"] = t.Spec.Title
+	if instance.Title != "" {
+		dash["title"] = instance.Title
+	}
+	return dash, nil
+}
+
+// walkTemplateStrings replaces the string values of the dashboard, including the ones nested in objects and arrays,
+// with the result of fn
+func walkTemplateStrings(v any, fn func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case map[string]any:
+		for k, child := range v {
+			v[k] = walkTemplateStrings(child, fn)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = walkTemplateStrings(child, fn)
+		}
+	}
+	return v
+}
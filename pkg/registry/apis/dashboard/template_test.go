@@ -0,0 +1,86 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+)
+
+func TestDashboardTemplate(t *testing.T) {
+	region := "eu-west-1"
+	template := func() *dashboardv0alpha1.DashboardTemplate {
+		return &dashboardv0alpha1.DashboardTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "service", Namespace: "default"},
+			Spec: dashboardv0alpha1.DashboardTemplateSpec{
+				Title: "Service overview",
+				Variables: []dashboardv0alpha1.DashboardTemplateVariable{
+					{Name: "service", Label: "Service"},
+					{Name: "region", Default: &region},
+				},
+				Dashboard: common.Unstructured{Object: map[string]any{
+					"uid":   "template",
+					"title": "{{service}}",
+					"panels": []any{
+						map[string]any{"title": "Requests of {{ service }} in {{region}}", "targets": []any{
+							map[string]any{"expr": `rate(http_requests_total{service="{{service}}", job="$job"}[5m])`},
+						}},
+					},
+				}},
+			},
+		}
+	}
+
+	t.Run("replaces the placeholders with the values and defaults", func(t *testing.T) {
+		dash, err := instantiateTemplate(template(), DashboardTemplateInstance{Variables: map[string]string{"service": "api"}})
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"title": "Service overview",
+			"panels": []any{
+				map[string]any{"title": "Requests of api in eu-west-1", "targets": []any{
+					map[string]any{"expr": `rate(http_requests_total{service="api", job="$job"}[5m])`},
+				}},
+			},
+		}, dash)
+	})
+
+	t.Run("uses the title of the instance and keeps the template unchanged", func(t *testing.T) {
+		tmpl := template()
+		dash, err := instantiateTemplate(tmpl, DashboardTemplateInstance{Title: "API", Variables: map[string]string{"service": "api", "region": "us"}})
+		require.NoError(t, err)
+		require.Equal(t, "API", dash["title"])
+		require.Equal(t, "{{service}}", tmpl.Spec.Dashboard.Object["title"])
+	})
+
+	t.Run("rejects missing and unknown variables", func(t *testing.T) {
+		_, err := instantiateTemplate(template(), DashboardTemplateInstance{})
+		require.ErrorContains(t, err, "missing values of the variables service")
+
+		_, err = instantiateTemplate(template(), DashboardTemplateInstance{Variables: map[string]string{"service": "api", "team": "a"}})
+		require.ErrorContains(t, err, `"team" is not a variable of the template`)
+	})
+
+	t.Run("validates the templates", func(t *testing.T) {
+		require.Empty(t, validateTemplateSpec(&template().Spec, field.NewPath("spec")))
+
+		tmpl := template()
+		tmpl.Spec.Title = ""
+		tmpl.Spec.Variables = append(tmpl.Spec.Variables, dashboardv0alpha1.DashboardTemplateVariable{Name: "region"}, dashboardv0alpha1.DashboardTemplateVariable{Name: "1st"})
+		tmpl.Spec.Dashboard.Object["description"] = "{{team}}"
+		errs := validateTemplateSpec(&tmpl.Spec, field.NewPath("spec"))
+		require.Len(t, errs, 4)
+		require.Equal(t, "spec.title", errs[0].Field)
+		require.Equal(t, field.ErrorTypeDuplicate, errs[1].Type)
+		require.Equal(t, "spec.variables[3].name", errs[2].Field)
+		require.Equal(t, "{{team}}", errs[3].BadValue)
+
+		err := ValidateTemplate(admission.NewAttributesRecord(tmpl, nil, dashboardv0alpha1.DashboardTemplateResourceInfo.GroupVersionKind(), "default", "service",
+			dashboardv0alpha1.DashboardTemplateResourceInfo.GroupVersionResource(), "", admission.Create, &metav1.CreateOptions{}, false, nil))
+		require.ErrorContains(t, err, "spec.title: Required value")
+	})
+}
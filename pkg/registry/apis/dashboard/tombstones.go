@@ -0,0 +1,343 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/authlib/claims"
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// tombstoneNamespace is the kvstore namespace of the tombstones, they are keyed by the UID of the dashboard in its org
+const tombstoneNamespace = "dashboards.tombstones"
+
+// DashboardTombstone records the deletion of a dashboard
+type DashboardTombstone struct {
+	UID       string    `json:"uid"`
+	Title     string    `json:"title,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy,omitempty"`
+}
+
+// DashboardTombstoneList are the dashboards of a namespace deleted since a time, oldest first
+type DashboardTombstoneList struct {
+	Namespace string `json:"namespace"`
+	// RetainedSince is the oldest deletion time that is listed, a client that last synced before it must list all
+	// the dashboards again as some deletions are not listed anymore
+	RetainedSince time.Time            `json:"retainedSince"`
+	Items         []DashboardTombstone `json:"items"`
+}
+
+// TombstoneStore keeps a tombstone of the dashboards deleted through the dashboards API for the retention window, so
+// sync tools such as GitOps controllers can find what was deleted since their last sync. The tombstone of a dashboard
+// is removed when a dashboard with the same UID is created again.
+type TombstoneStore struct {
+	kv        kvstore.KVStore
+	retention time.Duration
+	now       func() time.Time
+	log       log.Logger
+}
+
+func ProvideTombstoneStore(cfg *setting.Cfg, sql db.DB) *TombstoneStore {
+	return NewTombstoneStore(kvstore.ProvideService(sql), cfg.DashboardTombstoneRetention)
+}
+
+func NewTombstoneStore(kv kvstore.KVStore, retention time.Duration) *TombstoneStore {
+	return &TombstoneStore{
+		kv:        kv,
+		retention: retention,
+		now:       time.Now,
+		log:       log.New("grafana-apiserver.dashboards.tombstones"),
+	}
+}
+
+// Enabled is false when the retention is 0, deleted dashboards are then not recorded
+func (s *TombstoneStore) Enabled() bool {
+	return s != nil && s.retention > 0
+}
+
+// record keeps the tombstone of a deleted dashboard. Failures are logged, the dashboard is already deleted.
+func (s *TombstoneStore) record(ctx context.Context, deleted runtime.Object) {
+	m, orgID, ok := s.meta(deleted)
+	if !ok {
+		return
+	}
+	t := DashboardTombstone{
+		UID:       m.GetName(),
+		Title:     tombstoneTitle(m),
+		DeletedAt: s.now().UTC(),
+	}
+	if user, err := identity.GetRequester(ctx); err == nil {
+		t.DeletedBy = user.GetUID()
+	}
+	value, err := json.Marshal(t)
+	if err == nil {
+		err = s.kv.Set(ctx, orgID, tombstoneNamespace, t.UID, string(value))
+	}
+	if err != nil {
+		s.log.Error("Failed to record the tombstone of a deleted dashboard", "uid", t.UID, "orgId", orgID, "error", err)
+	}
+}
+
+// tombstoneTitle reads the title of the spec, the spec of the dashboards before v2 is unstructured
+func tombstoneTitle(m utils.GrafanaMetaAccessor) string {
+	if title := m.FindTitle(""); title != "" {
+		return title
+	}
+	spec, err := m.GetSpec()
+	if err != nil {
+		return ""
+	}
+	if u, ok := spec.(commonV0.Unstructured); ok {
+		title, _ := u.Object["title"].(string)
+		return title
+	}
+	return ""
+}
+
+// remove deletes the tombstone of a dashboard that is created again
+func (s *TombstoneStore) remove(ctx context.Context, created runtime.Object) {
+	m, orgID, ok := s.meta(created)
+	if !ok {
+		return
+	}
+	if err := s.kv.Del(ctx, orgID, tombstoneNamespace, m.GetName()); err != nil {
+		s.log.Error("Failed to remove the tombstone of a created dashboard", "uid", m.GetName(), "orgId", orgID, "error", err)
+	}
+}
+
+// has is true when the tombstone of a dashboard is kept
+func (s *TombstoneStore) has(ctx context.Context, orgID int64, uid string) bool {
+	if !s.Enabled() || uid == "" {
+		return false
+	}
+	_, ok, err := s.kv.Get(ctx, orgID, tombstoneNamespace, uid)
+	return err == nil && ok
+}
+
+func (s *TombstoneStore) meta(obj runtime.Object) (utils.GrafanaMetaAccessor, int64, bool) {
+	if obj == nil {
+		return nil, 0, false
+	}
+	m, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, 0, false
+	}
+	info, err := claims.ParseNamespace(m.GetNamespace())
+	if err != nil {
+		return nil, 0, false
+	}
+	return m, info.OrgID, true
+}
+
+// List returns the tombstones of the org deleted after since, oldest first. The tombstones older than the retention
+// window are deleted.
+func (s *TombstoneStore) List(ctx context.Context, orgID int64, since time.Time) (DashboardTombstoneList, error) {
+	list := DashboardTombstoneList{
+		RetainedSince: s.now().UTC().Add(-s.retention),
+		Items:         []DashboardTombstone{},
+	}
+	all, err := s.kv.GetAll(ctx, orgID, tombstoneNamespace)
+	if err != nil {
+		return list, err
+	}
+	for uid, value := range all[orgID] {
+		t := DashboardTombstone{}
+		if err := json.Unmarshal([]byte(value), &t); err != nil {
+			s.log.Warn("Ignoring invalid dashboard tombstone", "uid", uid, "orgId", orgID, "error", err)
+			continue
+		}
+		if t.DeletedAt.Before(list.RetainedSince) {
+			if err := s.kv.Del(ctx, orgID, tombstoneNamespace, uid); err != nil {
+				s.log.Warn("Failed to delete an expired dashboard tombstone", "uid", uid, "orgId", orgID, "error", err)
+			}
+			continue
+		}
+		if t.DeletedAt.After(since) {
+			list.Items = append(list.Items, t)
+		}
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		a, b := list.Items[i], list.Items[j]
+		if !a.DeletedAt.Equal(b.DeletedAt) {
+			return a.DeletedAt.Before(b.DeletedAt)
+		}
+		return a.UID < b.UID
+	})
+	return list, nil
+}
+
+// tombstoneStorage records the dashboards deleted through the storage in the TombstoneStore
+type tombstoneStorage struct {
+	grafanarest.Storage
+	tombstones *TombstoneStore
+}
+
+// tombstoneWatchStorage keeps watch support of storages that implement it, the watches starting with the current
+// state also get a DELETED event per tombstone
+type tombstoneWatchStorage struct {
+	*tombstoneStorage
+	rest.Watcher
+}
+
+// WithTombstones records the dashboards deleted through the storage, other storages are returned unchanged
+func WithTombstones(store rest.Storage, tombstones *TombstoneStore) rest.Storage {
+	s, ok := store.(grafanarest.Storage)
+	if !ok || !tombstones.Enabled() {
+		return store
+	}
+	if w, ok := store.(rest.Watcher); ok {
+		return &tombstoneWatchStorage{tombstoneStorage: &tombstoneStorage{Storage: s, tombstones: tombstones}, Watcher: w}
+	}
+	return &tombstoneStorage{Storage: s, tombstones: tombstones}
+}
+
+func (s *tombstoneStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	created, err := s.Storage.Create(ctx, obj, createValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.tombstones.remove(ctx, created)
+	}
+	return created, err
+}
+
+func (s *tombstoneStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	updated, created, err := s.Storage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	if err == nil && created && (options == nil || len(options.DryRun) == 0) {
+		s.tombstones.remove(ctx, updated)
+	}
+	return updated, created, err
+}
+
+func (s *tombstoneStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	deleted, immediate, err := s.Storage.Delete(ctx, name, deleteValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.tombstones.record(ctx, deleted)
+	}
+	return deleted, immediate, err
+}
+
+func (s *tombstoneStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	deleted, err := s.Storage.DeleteCollection(ctx, deleteValidation, options, listOptions)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		_ = meta.EachListItem(deleted, func(obj runtime.Object) error {
+			s.tombstones.record(ctx, obj)
+			return nil
+		})
+	}
+	return deleted, err
+}
+
+// Watch sends a DELETED event for every tombstone of the namespace before the events of the storage, when the watch
+// starts with the current state of the dashboards: without a resource version, from "0", or with the initial events.
+func (s *tombstoneWatchStorage) Watch(ctx context.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	w, err := s.Watcher.Watch(ctx, options)
+	if err != nil || !watchesCurrentState(options) {
+		return w, err
+	}
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return w, nil
+	}
+	list, err := s.tombstones.List(ctx, info.OrgID, time.Time{})
+	if err != nil {
+		s.tombstones.log.Warn("Failed to list the dashboard tombstones of a watch", "orgId", info.OrgID, "error", err)
+		return w, nil
+	}
+	events := make([]watch.Event, 0, len(list.Items))
+	for _, t := range list.Items {
+		obj, err := s.tombstoneObject(info.Value, t)
+		if err != nil {
+			continue
+		}
+		events = append(events, watch.Event{Type: watch.Deleted, Object: obj})
+	}
+	if len(events) == 0 {
+		return w, nil
+	}
+	return newPrefixedWatch(events, w), nil
+}
+
+// tombstoneObject is the dashboard of a DELETED event, only its metadata is set
+func (s *tombstoneWatchStorage) tombstoneObject(namespace string, t DashboardTombstone) (runtime.Object, error) {
+	obj := s.New()
+	m, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	m.SetName(t.UID)
+	m.SetNamespace(namespace)
+	m.SetDeletionTimestamp(&metav1.Time{Time: t.DeletedAt})
+	if t.DeletedBy != "" {
+		m.SetUpdatedBy(t.DeletedBy)
+	}
+	return obj, nil
+}
+
+func watchesCurrentState(options *metainternalversion.ListOptions) bool {
+	if options == nil {
+		return true
+	}
+	if options.SendInitialEvents != nil {
+		return *options.SendInitialEvents
+	}
+	return options.ResourceVersion == "" || options.ResourceVersion == "0"
+}
+
+// prefixedWatch sends its events before the events of the watch it wraps
+type prefixedWatch struct {
+	inner  watch.Interface
+	result chan watch.Event
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func newPrefixedWatch(events []watch.Event, inner watch.Interface) *prefixedWatch {
+	w := &prefixedWatch{inner: inner, result: make(chan watch.Event), stop: make(chan struct{})}
+	go func() {
+		defer close(w.result)
+		for _, e := range events {
+			select {
+			case w.result <- e:
+			case <-w.stop:
+				return
+			}
+		}
+		for e := range inner.ResultChan() {
+			select {
+			case w.result <- e:
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return w
+}
+
+func (w *prefixedWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *prefixedWatch) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+		w.inner.Stop()
+	})
+}
@@ -0,0 +1,147 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/authlib/claims"
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestTombstoneStorage(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tombstones := NewTombstoneStore(kvstore.NewFakeKVStore(), 24*time.Hour)
+	tombstones.now = func() time.Time { return now }
+
+	current := &dashboardv0alpha1.Dashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "default"},
+		Spec:       commonV0.Unstructured{Object: map[string]any{"title": "Overview"}},
+	}
+	inner := watch.NewFake()
+	store := WithTombstones(&fakeTombstoneStorage{fakeAuditedStorage: fakeAuditedStorage{current: current}, watch: inner}, tombstones).(*tombstoneWatchStorage)
+
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{UserUID: "u1", OrgID: 1, FallbackType: claims.TypeUser})
+	ctx = k8srequest.WithNamespace(ctx, "default")
+
+	t.Run("records the deleted dashboards", func(t *testing.T) {
+		_, _, err := store.Delete(ctx, "abc", nil, &metav1.DeleteOptions{})
+		require.NoError(t, err)
+		list, err := tombstones.List(ctx, 1, time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, now.Add(-24*time.Hour), list.RetainedSince)
+		require.Equal(t, []DashboardTombstone{{UID: "abc", Title: "Overview", DeletedAt: now, DeletedBy: "user:u1"}}, list.Items)
+
+		list, err = tombstones.List(ctx, 1, now)
+		require.NoError(t, err)
+		require.Empty(t, list.Items, "only the deletions after since are listed")
+	})
+
+	t.Run("sends the tombstones to the watches of the current state", func(t *testing.T) {
+		w, err := store.Watch(ctx, &metainternalversion.ListOptions{})
+		require.NoError(t, err)
+		defer w.Stop()
+		e := <-w.ResultChan()
+		require.Equal(t, watch.Deleted, e.Type)
+		m, err := utils.MetaAccessor(e.Object)
+		require.NoError(t, err)
+		require.Equal(t, "abc", m.GetName())
+		require.Equal(t, "default", m.GetNamespace())
+		require.Equal(t, now, m.GetDeletionTimestamp().Time)
+		require.Equal(t, "user:u1", m.GetUpdatedBy())
+
+		go inner.Add(current.DeepCopy())
+		require.Equal(t, watch.Added, (<-w.ResultChan()).Type)
+
+		w, err = store.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "123"})
+		require.NoError(t, err)
+		require.Same(t, inner, w, "watches resuming from a version get the events of the storage")
+	})
+
+	t.Run("removes the tombstone when the dashboard is created again", func(t *testing.T) {
+		_, err := store.Create(ctx, current.DeepCopy(), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		list, err := tombstones.List(ctx, 1, time.Time{})
+		require.NoError(t, err)
+		require.Empty(t, list.Items)
+	})
+
+	t.Run("expires the tombstones after the retention", func(t *testing.T) {
+		_, _, err := store.Delete(ctx, "abc", nil, &metav1.DeleteOptions{})
+		require.NoError(t, err)
+		tombstones.now = func() time.Time { return now.Add(25 * time.Hour) }
+		t.Cleanup(func() { tombstones.now = func() time.Time { return now } })
+		list, err := tombstones.List(ctx, 1, time.Time{})
+		require.NoError(t, err)
+		require.Empty(t, list.Items)
+		keys, err := tombstones.kv.Keys(ctx, 1, tombstoneNamespace, "")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("is disabled without retention", func(t *testing.T) {
+		s := &fakeTombstoneStorage{}
+		require.Same(t, s, WithTombstones(s, NewTombstoneStore(kvstore.NewFakeKVStore(), 0)))
+	})
+}
+
+func TestTombstoneRoute(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tombstones := NewTombstoneStore(kvstore.NewFakeKVStore(), 24*time.Hour)
+	tombstones.now = func() time.Time { return now }
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{UserUID: "u1", OrgID: 1, FallbackType: claims.TypeUser})
+	tombstones.record(ctx, &dashboardv0alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "default"}})
+
+	serve := func(allowed bool, query string) *httptest.ResponseRecorder {
+		route := tombstones.APIRoutes(dashboardv0alpha1.DashboardResourceInfo, actest.FakeAccessControl{ExpectedEvaluate: allowed})[0]
+		req := httptest.NewRequest(http.MethodGet, "/deleted"+query, nil).WithContext(ctx)
+		req = mux.SetURLVars(req, map[string]string{"namespace": "default"})
+		rec := httptest.NewRecorder()
+		route.Handler(rec, req)
+		return rec
+	}
+
+	rec := serve(true, "?since=2024-01-01T00:00:00Z")
+	require.Equal(t, http.StatusOK, rec.Code)
+	list := DashboardTombstoneList{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Equal(t, "default", list.Namespace)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, "abc", list.Items[0].UID)
+
+	require.Equal(t, http.StatusBadRequest, serve(true, "?since=yesterday").Code)
+	require.Equal(t, http.StatusForbidden, serve(false, "").Code)
+}
+
+type fakeTombstoneStorage struct {
+	fakeAuditedStorage
+	watch watch.Interface
+}
+
+func (s *fakeTombstoneStorage) New() runtime.Object {
+	return &dashboardv0alpha1.Dashboard{}
+}
+
+func (s *fakeTombstoneStorage) Watch(_ context.Context, _ *metainternalversion.ListOptions) (watch.Interface, error) {
+	return s.watch, nil
+}
+
+var _ rest.Watcher = (*fakeTombstoneStorage)(nil)
@@ -1,11 +1,14 @@
 package v0alpha1
 
 import (
+	"context"
 	"errors"
+	"slices"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
@@ -17,16 +20,32 @@ import (
 	grafanaregistry "github.com/grafana/grafana/pkg/apiserver/registry/generic"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry/apis/dashboard"
 	"github.com/grafana/grafana/pkg/registry/apis/dashboard/legacy"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/shorturls"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/storage/legacysql"
 	"github.com/grafana/grafana/pkg/storage/unified/apistore"
@@ -36,6 +55,7 @@ import (
 var (
 	_ builder.APIGroupBuilder      = (*DashboardsAPIBuilder)(nil)
 	_ builder.OpenAPIPostProcessor = (*DashboardsAPIBuilder)(nil)
+	_ builder.APIGroupValidation   = (*DashboardsAPIBuilder)(nil)
 )
 
 // This is used just so wire has something unique to return
@@ -45,6 +65,41 @@ type DashboardsAPIBuilder struct {
 	accessControl accesscontrol.AccessControl
 	legacy        *dashboard.DashboardStorage
 	unified       resource.ResourceClient
+	annotations   annotations.Repository
+	permissions   accesscontrol.DashboardPermissionsService
+	hiddenUsers   map[string]struct{}
+	renderer      rendering.Service
+	public        publicdashboards.Service
+	query         query.Service
+	cfg           *setting.Cfg
+	provisioning  *dashboard.ProvisioningGuard
+	sizeLimit     *dashboard.SpecSizeLimit
+	links         *dashboard.LinkValidator
+	quotas        *dashboard.QuotaGuard
+	rateLimiter   *dashboard.NamespaceRateLimiter
+	auditor       *dashboard.DashboardAuditor
+	tombstones    *dashboard.TombstoneStore
+	views         *dashboard.DashboardViews
+	permTemplates *dashboard.PermissionTemplates
+	webhooks      *dashboard.DashboardWebhooks
+	dependencies  *dashboard.DependencyResolver
+	panels        *dashboard.PanelExtractor
+	versions      dashver.Service
+	dashboards    rest.Getter
+	bundles       *dashboard.BundleApplier
+	mover         *dashboard.DashboardMover
+	importer      *dashboard.DashboardImporter
+	copier        *dashboard.DashboardCopier
+	generator     *dashboard.DashboardGenerator
+	home          *dashboard.HomeDashboards
+	resolver      *dashboard.LinkResolver
+	libraryPanels *dashboard.LibraryPanelGarbageCollector
+	tags          *dashboard.TagManager
+	snapshots     *dashboard.SnapshotStore
+	legacySearch  *dashboard.LegacySearch
+	autocomplete  *dashboard.SearchAutocomplete
+	stars         star.Service
+	folders       folder.Service
 
 	log log.Logger
 	reg prometheus.Registerer
@@ -60,6 +115,29 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 	sql db.DB,
 	tracing *tracing.TracingService,
 	unified resource.ResourceClient,
+	folderService folder.Service,
+	libraryElements libraryelements.Service,
+	annotationsRepo annotations.Repository,
+	dashboardPermissions accesscontrol.DashboardPermissionsService,
+	renderService rendering.Service,
+	snapshotService dashboardsnapshots.Service,
+	starService star.Service,
+	publicDashboardService publicdashboards.Service,
+	queryService query.Service,
+	quotaService quota.Service,
+	dashboardVersions dashver.Service,
+	datasourceService datasources.DataSourceService,
+	rateLimiter *dashboard.NamespaceRateLimiter,
+	auditor *dashboard.DashboardAuditor,
+	pluginStore pluginstore.Store,
+	preferenceService pref.Service,
+	teamService team.Service,
+	libraryPanelGC *dashboard.LibraryPanelGarbageCollector,
+	tombstones *dashboard.TombstoneStore,
+	views *dashboard.DashboardViews,
+	folderPermissions accesscontrol.FolderPermissionsService,
+	shortURLService shorturls.Service,
+	webhooks *dashboard.DashboardWebhooks,
 ) *DashboardsAPIBuilder {
 	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) && !features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI) {
 		return nil // skip registration unless opting into experimental apis or dashboards in the k8s api
@@ -74,6 +152,39 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 		dashboardService: dashboardService,
 		accessControl:    accessControl,
 		unified:          unified,
+		annotations:      annotationsRepo,
+		permissions:      dashboardPermissions,
+		hiddenUsers:      cfg.HiddenUsers,
+		renderer:         renderService,
+		public:           publicDashboardService,
+		query:            queryService,
+		cfg:              cfg,
+		provisioning:     dashboard.NewProvisioningGuard(provisioning),
+		sizeLimit:        dashboard.NewSpecSizeLimit(cfg.DashboardMaxSpecSize),
+		links:            dashboard.NewLinkValidator(cfg),
+		quotas:           dashboard.NewQuotaGuard(cfg, quotaService, dashboardService),
+		rateLimiter:      rateLimiter,
+		auditor:          auditor,
+		tombstones:       tombstones,
+		views:            views,
+		permTemplates:    dashboard.NewPermissionTemplates(kvstore.ProvideService(sql), folderService, dashboardPermissions),
+		webhooks:         webhooks,
+		dependencies:     dashboard.NewDependencyResolver(datasourceService, libraryElements, pluginStore),
+		panels:           dashboard.NewPanelExtractor(libraryElements),
+		versions:         dashboardVersions,
+		bundles:          dashboard.NewBundleApplier(sql, features, cfg, folderService, dashboardService, libraryElements, accessControl, folderPermissions, dashboardPermissions),
+		mover:            dashboard.NewDashboardMover(sql, features, cfg, folderService, dashboardService),
+		importer:         dashboard.NewDashboardImporter(folderService, dashboardService, datasourceService),
+		copier:           dashboard.NewDashboardCopier(sql, folderService, dashboardService, datasourceService, libraryElements),
+		generator:        dashboard.NewDashboardGenerator(sql, folderService, dashboardService),
+		home:             dashboard.NewHomeDashboards(cfg, preferenceService, dashboardService, teamService, accessControl),
+		resolver:         dashboard.NewLinkResolver(sql, dashboardService, shortURLService, accessControl),
+		libraryPanels:    libraryPanelGC,
+		tags:             dashboard.NewTagManager(sql, features, cfg, unified, dashboardService),
+		autocomplete:     dashboard.NewSearchAutocomplete(unified, starService, folderService),
+		snapshots:        dashboard.NewSnapshotStore(snapshotService, accessControl, cfg),
+		stars:            starService,
+		folders:          folderService,
 
 		legacy: &dashboard.DashboardStorage{
 			Resource:       dashboardv0alpha1.DashboardResourceInfo,
@@ -83,6 +194,13 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 		},
 		reg: reg,
 	}
+	if features.IsEnabledGlobally(featuremgmt.FlagKubernetesLegacySearch) {
+		builder.legacySearch = dashboard.NewLegacySearch(unified, starService, folderService)
+	}
+	indexFields := append(dashboard.SearchIndexFields(), views.IndexFields()...)
+	if err := resource.RegisterIndexFields(dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind().Kind, indexFields...); err != nil {
+		builder.log.Error("failed to register the search index fields", "error", err)
+	}
 	apiregistration.RegisterAPI(builder)
 	return builder
 }
@@ -101,7 +219,18 @@ func (b *DashboardsAPIBuilder) GetDesiredDualWriterMode(dualWrite bool, modeMap
 }
 
 func (b *DashboardsAPIBuilder) InstallSchema(scheme *runtime.Scheme) error {
-	return dashboardv0alpha1.AddToScheme(scheme)
+	if err := dashboardv0alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+
+	// Snapshots and templates only exist in v0alpha1, so the same types are used as the internal representation
+	scheme.AddKnownTypes(schema.GroupVersion{Group: dashboardv0alpha1.GROUP, Version: runtime.APIVersionInternal},
+		&dashboardv0alpha1.DashboardSnapshot{},
+		&dashboardv0alpha1.DashboardSnapshotList{},
+		&dashboardv0alpha1.DashboardTemplate{},
+		&dashboardv0alpha1.DashboardTemplateList{},
+	)
+	return nil
 }
 
 func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupInfo, opts builder.APIGroupOptions) error {
@@ -163,6 +292,12 @@ func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver
 		}
 	}
 
+	// Record the changes of dashboards, including the ones made by the connectors below
+	storage[dash.StoragePath()] = dashboard.WithAudit(storage[dash.StoragePath()], b.auditor)
+	storage[dash.StoragePath()] = dashboard.WithTombstones(storage[dash.StoragePath()], b.tombstones)
+	storage[dash.StoragePath()] = dashboard.WithPermissionTemplates(storage[dash.StoragePath()], b.permTemplates)
+	storage[dash.StoragePath()] = dashboard.WithWebhooks(storage[dash.StoragePath()], b.webhooks)
+
 	// Register the DTO endpoint that will consolidate all dashboard bits
 	storage[dash.StoragePath("dto")], err = dashboard.NewDTOConnector(
 		storage[dash.StoragePath()],
@@ -170,6 +305,8 @@ func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver
 		b.legacy.Access,
 		b.unified,
 		b.accessControl,
+		b.folders,
+		b.views,
 		scheme,
 		func() runtime.Object { return &dashboardv0alpha1.DashboardWithAccessInfo{} },
 	)
@@ -177,24 +314,142 @@ func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver
 		return err
 	}
 
+	// Register the provisioning origin of a dashboard
+	storage[dash.StoragePath("provisioning-status")], err = dashboard.NewProvisioningStatusConnector(
+		storage[dash.StoragePath()],
+		b.provisioning,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardProvisioningStatus{} },
+	)
+	if err != nil {
+		return err
+	}
+	b.dashboards, _ = storage[dash.StoragePath()].(rest.Getter)
+
+	// Register the paged list of the saved versions of a dashboard
+	storage[dash.StoragePath("versions")] = dashboard.NewVersionsConnector(
+		b.dashboardService,
+		b.versions,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardVersionList{} },
+	)
+
+	// Register the annotations of a dashboard
+	storage[dash.StoragePath("annotations")] = dashboard.NewAnnotationsConnector(
+		b.dashboardService,
+		b.annotations,
+		b.accessControl,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardAnnotationList{} },
+	)
+
+	// Register the permissions of a dashboard
+	storage[dash.StoragePath("permissions")] = dashboard.NewPermissionsConnector(
+		b.dashboardService,
+		b.permissions,
+		b.accessControl,
+		b.hiddenUsers,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardPermissionList{} },
+	)
+
+	// Register the public sharing configuration of a dashboard
+	storage[dash.StoragePath("public")] = dashboard.NewPublicConnector(
+		b.dashboardService,
+		b.public,
+		b.accessControl,
+		b.cfg.PublicDashboardsEnabled,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardPublicConfig{} },
+	)
+
+	// Register the rendered image or PDF of a dashboard
+	storage[dash.StoragePath("render")] = dashboard.NewRenderConnector(
+		b.dashboardService,
+		b.renderer,
+		b.cfg,
+		func() runtime.Object { return &dashboardv0alpha1.Dashboard{} },
+	)
+
+	// Register the resolution of the template variables of a dashboard
+	storage[dash.StoragePath("variables")] = dashboard.NewVariablesConnector(
+		b.dashboardService,
+		b.query,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardResolvedVariables{} },
+	)
+
+	// Register the analysis of a dashboard spec for common problems
+	storage[dash.StoragePath("lint")] = dashboard.NewLintConnector(
+		b.dashboardService,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardLintReport{} },
+	)
+
 	// Requires hack in to resolve with no name:
 	// pkg/services/apiserver/builder/helper.go#L58
-	storage["search"], err = dashboard.NewSearchConnector(b.unified,
+	storage["search"], err = dashboard.NewSearchConnector(b.unified, b.stars, b.folders, b.rateLimiter, b.cfg.DashboardSlowSearchThreshold, b.reg,
 		func() runtime.Object { return &dashboardv0alpha1.DashboardWithAccessInfo{} }) // TODO... replace with a real model
 	if err != nil {
 		return err
 	}
 
+	// Register the data sources, library panels and plugins a dashboard depends on
+	storage[dash.StoragePath("dependencies")] = dashboard.NewDependenciesConnector(
+		b.dashboardService,
+		b.dependencies,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardDependencies{} },
+	)
+
+	// Register a single panel of a dashboard with the template variables it uses
+	storage[dash.StoragePath("panels")] = dashboard.NewPanelsConnector(
+		b.dashboardService,
+		b.panels,
+		func() runtime.Object { return &dashboardv0alpha1.DashboardPanel{} },
+	)
+
 	// Expose read only library panels
 	storage[dashboardv0alpha1.LibraryPanelResourceInfo.StoragePath()] = &dashboard.LibraryPanelStore{
 		Access:       b.legacy.Access,
 		ResourceInfo: dashboardv0alpha1.LibraryPanelResourceInfo,
 	}
 
+	// Dashboard snapshots, expired snapshots are removed by the SnapshotGarbageCollector
+	storage[dashboardv0alpha1.DashboardSnapshotResourceInfo.StoragePath()] = b.snapshots
+
+	// Starter dashboard templates published per namespace, they are kept in unified storage as they have no legacy table
+	templates, err := grafanaregistry.NewRegistryStore(scheme, dashboardv0alpha1.DashboardTemplateResourceInfo, optsGetter)
+	if err != nil {
+		return err
+	}
+	storage[dashboardv0alpha1.DashboardTemplateResourceInfo.StoragePath()] = templates
+	storage[dashboardv0alpha1.DashboardTemplateResourceInfo.StoragePath("instantiate")] = dashboard.NewTemplateInstantiateConnector(templates)
+
+	// Support conditional GET requests and the fields option of dashboards, the connectors above use the storage without them
+	storage[dash.StoragePath()] = dashboard.WithFields(dashboard.WithETag(storage[dash.StoragePath()]))
+
 	apiGroupInfo.VersionedResourcesStorageMap[dashboardv0alpha1.VERSION] = storage
 	return nil
 }
 
+// Validate rejects writes above the rate limit of the namespace, dashboards above the size limit or the quotas,
+// dashboards with links that are not allowed, patches setting invalid values in the spec, and API changes to
+// provisioned dashboards, they can only be changed in their provisioning source
+func (b *DashboardsAPIBuilder) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if err := b.rateLimiter.Validate(a); err != nil {
+		return err
+	}
+	if err := b.sizeLimit.Validate(a); err != nil {
+		return err
+	}
+	if err := b.quotas.Validate(ctx, a); err != nil {
+		return err
+	}
+	if err := b.links.Validate(ctx, a, b.dashboards); err != nil {
+		return err
+	}
+	if err := dashboard.ValidateSpecPatch(a); err != nil {
+		return err
+	}
+	if err := dashboard.ValidateTemplate(a); err != nil {
+		return err
+	}
+	return b.provisioning.Validate(ctx, a, b.dashboards)
+}
+
 func (b *DashboardsAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
 	return dashboardv0alpha1.GetOpenAPIDefinitions
 }
@@ -209,6 +464,8 @@ func (b *DashboardsAPIBuilder) PostProcessOpenAPI(oas *spec3.OpenAPI) (*spec3.Op
 	// Hide the ability to list or watch across all tenants
 	delete(oas.Paths.Paths, root+dashboardv0alpha1.DashboardResourceInfo.GroupResource().Resource)
 	delete(oas.Paths.Paths, root+"watch/"+dashboardv0alpha1.DashboardResourceInfo.GroupResource().Resource)
+	delete(oas.Paths.Paths, root+dashboardv0alpha1.DashboardSnapshotResourceInfo.GroupResource().Resource)
+	delete(oas.Paths.Paths, root+dashboardv0alpha1.DashboardTemplateResourceInfo.GroupResource().Resource)
 
 	// Resolve the empty name
 	sub := oas.Paths.Paths[root+"search/{name}"]
@@ -220,9 +477,33 @@ func (b *DashboardsAPIBuilder) PostProcessOpenAPI(oas *spec3.OpenAPI) (*spec3.Op
 	if sub != nil && sub.Get != nil {
 		sub.Get.Tags = []string{"API Discovery"} // sorts first in the list
 	}
+
+	dashboard.EnrichOpenAPI(oas, root)
 	return oas, nil
 }
 
 func (b *DashboardsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
-	return nil // no custom API routes
+	resource := dashboardv0alpha1.DashboardResourceInfo
+	routes := &builder.APIRoutes{
+		Namespace: slices.Concat(
+			b.bundles.APIRoutes(),
+			b.mover.APIRoutes(resource),
+			b.tags.APIRoutes(),
+			b.autocomplete.APIRoutes(),
+			b.quotas.APIRoutes(resource, b.accessControl),
+			b.importer.APIRoutes(resource, func() rest.Getter { return b.dashboards }),
+			b.copier.APIRoutes(resource),
+			b.generator.APIRoutes(resource),
+			b.home.APIRoutes(resource),
+			b.resolver.APIRoutes(resource),
+			b.libraryPanels.APIRoutes(resource),
+			b.tombstones.APIRoutes(resource, b.accessControl),
+			b.permTemplates.APIRoutes(resource, b.accessControl),
+			b.webhooks.APIRoutes(resource, b.accessControl),
+		),
+	}
+	if b.legacySearch != nil {
+		routes.Namespace = append(routes.Namespace, b.legacySearch.APIRoutes()...)
+	}
+	return routes
 }
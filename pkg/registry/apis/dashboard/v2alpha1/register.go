@@ -1,9 +1,12 @@
 package v2alpha1
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
@@ -15,16 +18,27 @@ import (
 	grafanaregistry "github.com/grafana/grafana/pkg/apiserver/registry/generic"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry/apis/dashboard"
 	"github.com/grafana/grafana/pkg/registry/apis/dashboard/legacy"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/storage/legacysql"
 	"github.com/grafana/grafana/pkg/storage/unified/apistore"
@@ -34,6 +48,7 @@ import (
 var (
 	_ builder.APIGroupBuilder      = (*DashboardsAPIBuilder)(nil)
 	_ builder.OpenAPIPostProcessor = (*DashboardsAPIBuilder)(nil)
+	_ builder.APIGroupValidation   = (*DashboardsAPIBuilder)(nil)
 )
 
 // This is used just so wire has something unique to return
@@ -43,6 +58,28 @@ type DashboardsAPIBuilder struct {
 	accessControl accesscontrol.AccessControl
 	legacy        *dashboard.DashboardStorage
 	unified       resource.ResourceClient
+	annotations   annotations.Repository
+	permissions   accesscontrol.DashboardPermissionsService
+	hiddenUsers   map[string]struct{}
+	renderer      rendering.Service
+	public        publicdashboards.Service
+	query         query.Service
+	cfg           *setting.Cfg
+	provisioning  *dashboard.ProvisioningGuard
+	sizeLimit     *dashboard.SpecSizeLimit
+	links         *dashboard.LinkValidator
+	quotas        *dashboard.QuotaGuard
+	rateLimiter   *dashboard.NamespaceRateLimiter
+	auditor       *dashboard.DashboardAuditor
+	tombstones    *dashboard.TombstoneStore
+	views         *dashboard.DashboardViews
+	permTemplates *dashboard.PermissionTemplates
+	webhooks      *dashboard.DashboardWebhooks
+	dependencies  *dashboard.DependencyResolver
+	panels        *dashboard.PanelExtractor
+	versions      dashver.Service
+	folders       folder.Service
+	dashboards    rest.Getter
 
 	log log.Logger
 	reg prometheus.Registerer
@@ -58,6 +95,22 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 	sql db.DB,
 	tracing *tracing.TracingService,
 	unified resource.ResourceClient,
+	annotationsRepo annotations.Repository,
+	dashboardPermissions accesscontrol.DashboardPermissionsService,
+	renderService rendering.Service,
+	publicDashboardService publicdashboards.Service,
+	queryService query.Service,
+	quotaService quota.Service,
+	dashboardVersions dashver.Service,
+	folderService folder.Service,
+	rateLimiter *dashboard.NamespaceRateLimiter,
+	auditor *dashboard.DashboardAuditor,
+	datasourceService datasources.DataSourceService,
+	libraryElements libraryelements.Service,
+	pluginStore pluginstore.Store,
+	tombstones *dashboard.TombstoneStore,
+	views *dashboard.DashboardViews,
+	webhooks *dashboard.DashboardWebhooks,
 ) *DashboardsAPIBuilder {
 	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) && !features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI) {
 		return nil // skip registration unless opting into experimental apis or dashboards in the k8s api
@@ -72,6 +125,27 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 		dashboardService: dashboardService,
 		accessControl:    accessControl,
 		unified:          unified,
+		annotations:      annotationsRepo,
+		permissions:      dashboardPermissions,
+		hiddenUsers:      cfg.HiddenUsers,
+		renderer:         renderService,
+		public:           publicDashboardService,
+		query:            queryService,
+		cfg:              cfg,
+		provisioning:     dashboard.NewProvisioningGuard(provisioning),
+		sizeLimit:        dashboard.NewSpecSizeLimit(cfg.DashboardMaxSpecSize),
+		links:            dashboard.NewLinkValidator(cfg),
+		quotas:           dashboard.NewQuotaGuard(cfg, quotaService, dashboardService),
+		rateLimiter:      rateLimiter,
+		auditor:          auditor,
+		tombstones:       tombstones,
+		views:            views,
+		permTemplates:    dashboard.NewPermissionTemplates(kvstore.ProvideService(sql), folderService, dashboardPermissions),
+		webhooks:         webhooks,
+		dependencies:     dashboard.NewDependencyResolver(datasourceService, libraryElements, pluginStore),
+		panels:           dashboard.NewPanelExtractor(libraryElements),
+		versions:         dashboardVersions,
+		folders:          folderService,
 
 		legacy: &dashboard.DashboardStorage{
 			Resource:       dashboardv2alpha1.DashboardResourceInfo,
@@ -157,6 +231,12 @@ func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver
 		}
 	}
 
+	// Record the changes of dashboards, including the ones made by the connectors below
+	storage[dash.StoragePath()] = dashboard.WithAudit(storage[dash.StoragePath()], b.auditor)
+	storage[dash.StoragePath()] = dashboard.WithTombstones(storage[dash.StoragePath()], b.tombstones)
+	storage[dash.StoragePath()] = dashboard.WithPermissionTemplates(storage[dash.StoragePath()], b.permTemplates)
+	storage[dash.StoragePath()] = dashboard.WithWebhooks(storage[dash.StoragePath()], b.webhooks)
+
 	// Register the DTO endpoint that will consolidate all dashboard bits
 	storage[dash.StoragePath("dto")], err = dashboard.NewDTOConnector(
 		storage[dash.StoragePath()],
@@ -164,6 +244,8 @@ func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver
 		b.legacy.Access,
 		b.unified,
 		b.accessControl,
+		b.folders,
+		b.views,
 		scheme,
 		func() runtime.Object { return &dashboardv2alpha1.DashboardWithAccessInfo{} },
 	)
@@ -171,16 +253,117 @@ func (b *DashboardsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver
 		return err
 	}
 
+	// Register the provisioning origin of a dashboard
+	storage[dash.StoragePath("provisioning-status")], err = dashboard.NewProvisioningStatusConnector(
+		storage[dash.StoragePath()],
+		b.provisioning,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardProvisioningStatus{} },
+	)
+	if err != nil {
+		return err
+	}
+	b.dashboards, _ = storage[dash.StoragePath()].(rest.Getter)
+
+	// Register the paged list of the saved versions of a dashboard
+	storage[dash.StoragePath("versions")] = dashboard.NewVersionsConnector(
+		b.dashboardService,
+		b.versions,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardVersionList{} },
+	)
+
+	// Register the annotations of a dashboard
+	storage[dash.StoragePath("annotations")] = dashboard.NewAnnotationsConnector(
+		b.dashboardService,
+		b.annotations,
+		b.accessControl,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardAnnotationList{} },
+	)
+
+	// Register the permissions of a dashboard
+	storage[dash.StoragePath("permissions")] = dashboard.NewPermissionsConnector(
+		b.dashboardService,
+		b.permissions,
+		b.accessControl,
+		b.hiddenUsers,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardPermissionList{} },
+	)
+
+	// Register the public sharing configuration of a dashboard
+	storage[dash.StoragePath("public")] = dashboard.NewPublicConnector(
+		b.dashboardService,
+		b.public,
+		b.accessControl,
+		b.cfg.PublicDashboardsEnabled,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardPublicConfig{} },
+	)
+
+	// Register the rendered image or PDF of a dashboard
+	storage[dash.StoragePath("render")] = dashboard.NewRenderConnector(
+		b.dashboardService,
+		b.renderer,
+		b.cfg,
+		func() runtime.Object { return &dashboardv2alpha1.Dashboard{} },
+	)
+
+	// Register the resolution of the template variables of a dashboard
+	storage[dash.StoragePath("variables")] = dashboard.NewVariablesConnector(
+		b.dashboardService,
+		b.query,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardResolvedVariables{} },
+	)
+
+	// Register the analysis of a dashboard spec for common problems
+	storage[dash.StoragePath("lint")] = dashboard.NewLintConnector(
+		b.dashboardService,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardLintReport{} },
+	)
+
+	// Register the data sources, library panels and plugins a dashboard depends on
+	storage[dash.StoragePath("dependencies")] = dashboard.NewDependenciesConnector(
+		b.dashboardService,
+		b.dependencies,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardDependencies{} },
+	)
+
+	// Register a single panel of a dashboard with the template variables it uses
+	storage[dash.StoragePath("panels")] = dashboard.NewPanelsConnector(
+		b.dashboardService,
+		b.panels,
+		func() runtime.Object { return &dashboardv2alpha1.DashboardPanel{} },
+	)
+
 	// Expose read only library panels
 	storage[dashboardv2alpha1.LibraryPanelResourceInfo.StoragePath()] = &dashboard.LibraryPanelStore{
 		Access:       b.legacy.Access,
 		ResourceInfo: dashboardv2alpha1.LibraryPanelResourceInfo,
 	}
 
+	// Support conditional GET requests and the fields option of dashboards, the connectors above use the storage without them
+	storage[dash.StoragePath()] = dashboard.WithFields(dashboard.WithETag(storage[dash.StoragePath()]))
+
 	apiGroupInfo.VersionedResourcesStorageMap[dashboardv2alpha1.VERSION] = storage
 	return nil
 }
 
+// Validate rejects writes above the rate limit of the namespace, dashboards above the size limit or the quotas,
+// dashboards with links that are not allowed, and API changes to provisioned dashboards, they can only be changed
+// in their provisioning source
+func (b *DashboardsAPIBuilder) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if err := b.rateLimiter.Validate(a); err != nil {
+		return err
+	}
+	if err := b.sizeLimit.Validate(a); err != nil {
+		return err
+	}
+	if err := b.quotas.Validate(ctx, a); err != nil {
+		return err
+	}
+	if err := b.links.Validate(ctx, a, b.dashboards); err != nil {
+		return err
+	}
+	return b.provisioning.Validate(ctx, a, b.dashboards)
+}
+
 func (b *DashboardsAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
 	return dashboardv2alpha1.GetOpenAPIDefinitions
 }
@@ -201,6 +384,8 @@ func (b *DashboardsAPIBuilder) PostProcessOpenAPI(oas *spec3.OpenAPI) (*spec3.Op
 	if sub != nil && sub.Get != nil {
 		sub.Get.Tags = []string{"API Discovery"} // sorts first in the list
 	}
+
+	dashboard.EnrichOpenAPI(oas, root)
 	return oas, nil
 }
 
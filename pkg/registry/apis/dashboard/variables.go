@@ -0,0 +1,474 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	variableAllValue = "$__all"
+	variableAllText  = "All"
+)
+
+// The same syntax as the frontend: $var, [[var]], [[var:format]], ${var} and ${var:format}
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.[^:^\}]+)?(?::([^\}]+))?\}`)
+
+// VariableOption is a value a template variable can take
+type VariableOption struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// ResolvedVariable is the value of a template variable after it was evaluated
+type ResolvedVariable struct {
+	Name    string           `json:"name"`
+	Type    string           `json:"type"`
+	Text    []string         `json:"text"`
+	Value   []string         `json:"value"`
+	Options []VariableOption `json:"options,omitempty"`
+
+	// Warning is set when the options of the variable could not be evaluated and its current value was kept
+	Warning string `json:"warning,omitempty"`
+}
+
+// templateVariable is a template variable as stored in templating.list of the dashboard spec
+type templateVariable struct {
+	Name       string
+	Type       string
+	Query      any
+	Datasource any
+	Regex      string
+	Sort       int
+	IncludeAll bool
+	AllValue   string
+	Current    []string
+	Options    []VariableOption
+}
+
+// variableQuerier runs the query of a query variable against its datasource and returns the options
+type variableQuerier func(ctx context.Context, datasource any, query map[string]any) ([]VariableOption, error)
+
+// readTemplateVariables reads the variables of a dashboard spec, in the order they are defined
+func readTemplateVariables(spec map[string]any) []templateVariable {
+	templating, _ := spec["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+	vars := make([]templateVariable, 0, len(list))
+	for _, item := range list {
+		raw, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		v := templateVariable{
+			Query:      raw["query"],
+			Datasource: raw["datasource"],
+		}
+		v.Name, _ = raw["name"].(string)
+		v.Type, _ = raw["type"].(string)
+		v.Regex, _ = raw["regex"].(string)
+		v.IncludeAll, _ = raw["includeAll"].(bool)
+		v.AllValue, _ = raw["allValue"].(string)
+		if s, ok := raw["sort"].(float64); ok {
+			v.Sort = int(s)
+		}
+		if current, ok := raw["current"].(map[string]any); ok {
+			v.Current = toStrings(current["value"])
+		}
+		if options, ok := raw["options"].([]any); ok {
+			for _, o := range options {
+				if option, ok := o.(map[string]any); ok {
+					text, _ := option["text"].(string)
+					value, _ := option["value"].(string)
+					v.Options = append(v.Options, VariableOption{Text: text, Value: value})
+				}
+			}
+		}
+		if v.Name != "" {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// resolveVariables evaluates the variables in order, so a variable can use the ones defined before it.
+// The requested values replace the current values saved in the dashboard.
+func resolveVariables(ctx context.Context, vars []templateVariable, requested map[string][]string, querier variableQuerier) ([]ResolvedVariable, error) {
+	resolved := make([]ResolvedVariable, 0, len(vars))
+	values := map[string]ResolvedVariable{}
+	for _, v := range vars {
+		res := ResolvedVariable{Name: v.Name, Type: v.Type}
+
+		switch v.Type {
+		case "constant":
+			value := fmt.Sprint(v.Query)
+			res.Text, res.Value = []string{value}, []string{value}
+			values[v.Name] = res
+			resolved = append(resolved, res)
+			continue
+		case "textbox":
+			if current := firstNonEmpty(requested[v.Name], v.Current); len(current) > 0 {
+				res.Text, res.Value = current, current
+			} else {
+				value := fmt.Sprint(v.Query)
+				res.Text, res.Value = []string{value}, []string{value}
+			}
+			values[v.Name] = res
+			resolved = append(resolved, res)
+			continue
+		case "custom", "interval":
+			query, _ := v.Query.(string)
+			res.Options = parseCustomOptions(interpolate(query, values))
+		case "query":
+			query, ok := v.Query.(map[string]any)
+			if !ok || querier == nil {
+				res.Options = v.Options
+				res.Warning = "the query of the variable can only be evaluated by the frontend, the saved options are used"
+				break
+			}
+			interpolated, _ := interpolateJSON(query, values).(map[string]any)
+			options, err := querier(ctx, interpolateJSON(v.Datasource, values), interpolated)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate variable %s: %w", v.Name, err)
+			}
+			options, err = filterVariableOptions(options, v.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex of variable %s: %w", v.Name, err)
+			}
+			res.Options = sortVariableOptions(options, v.Sort)
+		case "adhoc", "groupby":
+			// filters are applied by the datasources, there is nothing to interpolate
+			continue
+		default:
+			res.Options = v.Options
+		}
+
+		res.Text, res.Value = selectVariableValue(v, res.Options, requested[v.Name])
+		values[v.Name] = res
+		resolved = append(resolved, res)
+	}
+	return resolved, nil
+}
+
+// selectVariableValue returns the requested value, the saved value if it is still an option, or the first option
+func selectVariableValue(v templateVariable, options []VariableOption, requested []string) ([]string, []string) {
+	selected := requested
+	if len(selected) == 0 {
+		selected = v.Current
+		if len(options) > 0 && !(len(selected) == 1 && selected[0] == variableAllValue) && !hasAllOptions(options, selected) {
+			selected = nil
+		}
+	}
+	if len(selected) == 0 {
+		if v.IncludeAll {
+			selected = []string{variableAllValue}
+		} else if len(options) > 0 {
+			selected = []string{options[0].Value}
+		}
+	}
+
+	if len(selected) == 1 && selected[0] == variableAllValue {
+		if v.AllValue != "" {
+			return []string{variableAllText}, []string{v.AllValue}
+		}
+		value := make([]string, 0, len(options))
+		for _, o := range options {
+			value = append(value, o.Value)
+		}
+		return []string{variableAllText}, value
+	}
+
+	text := make([]string, 0, len(selected))
+	for _, value := range selected {
+		t := value
+		for _, o := range options {
+			if o.Value == value {
+				t = o.Text
+				break
+			}
+		}
+		text = append(text, t)
+	}
+	return text, selected
+}
+
+func hasAllOptions(options []VariableOption, values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		found := false
+		for _, o := range options {
+			if o.Value == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCustomOptions reads the comma separated values of custom and interval variables.
+// Commas can be escaped with a backslash, and "text : value" sets a different text.
+func parseCustomOptions(query string) []VariableOption {
+	options := []VariableOption{}
+	current := strings.Builder{}
+	flush := func() {
+		item := strings.TrimSpace(current.String())
+		current.Reset()
+		if item == "" {
+			return
+		}
+		text, value, ok := strings.Cut(item, " : ")
+		if !ok {
+			text, value = item, item
+		}
+		options = append(options, VariableOption{Text: strings.TrimSpace(text), Value: strings.TrimSpace(value)})
+	}
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && i+1 < len(query) && query[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case query[i] == ',':
+			flush()
+		default:
+			current.WriteByte(query[i])
+		}
+	}
+	flush()
+	return options
+}
+
+// filterVariableOptions keeps the options matching the regex of the variable. Like in the frontend, the named
+// groups text and value set the text and value of the option, otherwise the first group is used when there is one.
+func filterVariableOptions(options []VariableOption, expr string) ([]VariableOption, error) {
+	if expr == "" {
+		return dedupeVariableOptions(options), nil
+	}
+	// the frontend syntax is /regex/flags
+	if strings.HasPrefix(expr, "/") {
+		if end := strings.LastIndex(expr, "/"); end > 0 {
+			flags := expr[end+1:]
+			expr = expr[1:end]
+			if strings.Contains(flags, "i") {
+				expr = "(?i)" + expr
+			}
+		}
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]VariableOption, 0, len(options))
+	textIdx, valueIdx := re.SubexpIndex("text"), re.SubexpIndex("value")
+	for _, o := range options {
+		match := re.FindStringSubmatch(o.Text)
+		if match == nil {
+			continue
+		}
+		switch {
+		case textIdx > 0 || valueIdx > 0:
+			if valueIdx > 0 && match[valueIdx] != "" {
+				o.Value = match[valueIdx]
+			}
+			if textIdx > 0 && match[textIdx] != "" {
+				o.Text = match[textIdx]
+			} else {
+				o.Text = o.Value
+			}
+			if valueIdx <= 0 {
+				o.Value = o.Text
+			}
+		case len(match) > 1:
+			o.Text, o.Value = match[1], match[1]
+		}
+		out = append(out, o)
+	}
+	return dedupeVariableOptions(out), nil
+}
+
+func dedupeVariableOptions(options []VariableOption) []VariableOption {
+	seen := map[VariableOption]bool{}
+	out := make([]VariableOption, 0, len(options))
+	for _, o := range options {
+		if seen[o] {
+			continue
+		}
+		seen[o] = true
+		out = append(out, o)
+	}
+	return out
+}
+
+// sortVariableOptions applies the sort order of the variable: 1 and 2 alphabetical, 3 and 4 numerical,
+// 5 and 6 alphabetical case-insensitive, ascending and descending respectively
+func sortVariableOptions(options []VariableOption, order int) []VariableOption {
+	var less func(a, b string) bool
+	switch order {
+	case 1, 2:
+		less = func(a, b string) bool { return a < b }
+	case 3, 4:
+		less = func(a, b string) bool {
+			fa, errA := strconv.ParseFloat(a, 64)
+			fb, errB := strconv.ParseFloat(b, 64)
+			if errA != nil || errB != nil {
+				return errA == nil // numbers first
+			}
+			return fa < fb
+		}
+	case 5, 6:
+		less = func(a, b string) bool { return strings.ToLower(a) < strings.ToLower(b) }
+	default:
+		return options
+	}
+	desc := order%2 == 0
+	sort.SliceStable(options, func(i, j int) bool {
+		if desc {
+			return less(options[j].Text, options[i].Text)
+		}
+		return less(options[i].Text, options[j].Text)
+	})
+	return options
+}
+
+// interpolateJSON replaces the variables in all the strings of a JSON value
+func interpolateJSON(v any, values map[string]ResolvedVariable) any {
+	switch val := v.(type) {
+	case string:
+		return interpolate(val, values)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = interpolateJSON(item, values)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = interpolateJSON(item, values)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// interpolate replaces the variables in s, unknown variables are kept as they are
+func interpolate(s string, values map[string]ResolvedVariable) string {
+	if !strings.ContainsAny(s, "$[") {
+		return s
+	}
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[5]
+		}
+		v, ok := values[name]
+		if !ok {
+			return match
+		}
+		return formatVariable(v, format)
+	})
+}
+
+// formatVariable formats the value of a variable like the frontend does for the supported formats.
+// Multiple values without a format are formatted as a glob.
+func formatVariable(v ResolvedVariable, format string) string {
+	values := v.Value
+	switch format {
+	case "":
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	case "glob":
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	case "raw", "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "json":
+		out, _ := json.Marshal(values)
+		return string(out)
+	case "regex":
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			escaped = append(escaped, regexp.QuoteMeta(value))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quoteValues(values, "'", `\'`)
+	case "doublequote":
+		return quoteValues(values, `"`, `\"`)
+	case "sqlstring":
+		return quoteValues(values, "'", "''")
+	case "text":
+		return strings.Join(v.Text, " + ")
+	case "percentencode":
+		return url.QueryEscape(strings.Join(values, ","))
+	case "queryparam":
+		params := make([]string, 0, len(values))
+		for _, value := range values {
+			params = append(params, "var-"+url.QueryEscape(v.Name)+"="+url.QueryEscape(value))
+		}
+		return strings.Join(params, "&")
+	default:
+		return strings.Join(values, ",")
+	}
+}
+
+func quoteValues(values []string, quote, escaped string) string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		out = append(out, quote+strings.ReplaceAll(value, quote, escaped)+quote)
+	}
+	return strings.Join(out, ",")
+}
+
+// toStrings reads a single value or a list of values
+func toStrings(v any) []string {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{val}
+	case []string:
+		return val
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	default:
+		return []string{fmt.Sprint(val)}
+	}
+}
+
+func firstNonEmpty(values ...[]string) []string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return nil
+}
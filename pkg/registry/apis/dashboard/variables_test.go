@@ -0,0 +1,132 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	values := map[string]ResolvedVariable{
+		"host":   {Name: "host", Text: []string{"a", "b"}, Value: []string{"a", "b"}},
+		"region": {Name: "region", Text: []string{"EU"}, Value: []string{"eu"}},
+		"quoted": {Name: "quoted", Text: []string{"it's"}, Value: []string{"it's"}},
+	}
+
+	tests := map[string]string{
+		"up{region=\"$region\"}":         "up{region=\"eu\"}",
+		"${region}-[[region]]":           "eu-eu",
+		"$host":                          "{a,b}",
+		"${host:csv}":                    "a,b",
+		"${host:pipe}":                   "a|b",
+		"${host:regex}":                  "(a|b)",
+		"${host:json}":                   `["a","b"]`,
+		"${host:singlequote}":            "'a','b'",
+		"${quoted:sqlstring}":            "'it''s'",
+		"${region:text}":                 "EU",
+		"${host:queryparam}":             "var-host=a&var-host=b",
+		"[[host:pipe]]":                  "a|b",
+		"$unknown and ${unknown:csv}":    "$unknown and ${unknown:csv}",
+		"no variables":                   "no variables",
+		"${region}${region:doublequote}": `eu"eu"`,
+	}
+	for in, expected := range tests {
+		require.Equal(t, expected, interpolate(in, values), in)
+	}
+}
+
+func TestParseCustomOptions(t *testing.T) {
+	require.Equal(t, []VariableOption{
+		{Text: "a", Value: "a"},
+		{Text: "b,c", Value: "b,c"},
+		{Text: "Production", Value: "prod"},
+	}, parseCustomOptions(`a, b\,c ,Production : prod,`))
+}
+
+func TestFilterVariableOptions(t *testing.T) {
+	options := []VariableOption{
+		{Text: "server-1.eu", Value: "server-1.eu"},
+		{Text: "server-2.us", Value: "server-2.us"},
+		{Text: "server-1.eu", Value: "server-1.eu"},
+	}
+
+	filtered, err := filterVariableOptions(options, "/server-(.*)\\.eu/")
+	require.NoError(t, err)
+	require.Equal(t, []VariableOption{{Text: "1", Value: "1"}}, filtered)
+
+	filtered, err = filterVariableOptions(options, `(?P<value>server-\d)\.(?P<text>\w+)`)
+	require.NoError(t, err)
+	require.Equal(t, []VariableOption{
+		{Text: "eu", Value: "server-1"},
+		{Text: "us", Value: "server-2"},
+	}, filtered)
+
+	_, err = filterVariableOptions(options, "(")
+	require.Error(t, err)
+}
+
+func TestResolveVariables(t *testing.T) {
+	spec := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "Servers in $region",
+		"panels": [{"targets": [{"expr": "up{region=\"$region\", host=~\"${host:regex}\"}"}]}],
+		"templating": {"list": [
+			{"name": "env", "type": "constant", "query": "prod"},
+			{"name": "region", "type": "custom", "query": "Europe : eu,America : us", "current": {"text": "America", "value": "us"}},
+			{"name": "host", "type": "query", "includeAll": true, "sort": 2, "datasource": {"uid": "prom"},
+				"query": {"expr": "label_values(up{region=\"$region\", env=\"$env\"}, host)"},
+				"current": {"text": "All", "value": "$__all"}},
+			{"name": "filter", "type": "textbox", "query": "default"},
+			{"name": "legacy", "type": "query", "query": "label_values(host)", "current": {"text": "a", "value": "a"},
+				"options": [{"text": "a", "value": "a"}]}
+		]}
+	}`), &spec))
+
+	var queried map[string]any
+	querier := func(ctx context.Context, datasource any, q map[string]any) ([]VariableOption, error) {
+		require.Equal(t, map[string]any{"uid": "prom"}, datasource)
+		queried = q
+		return []VariableOption{{Text: "a", Value: "a"}, {Text: "b", Value: "b"}}, nil
+	}
+
+	resolved, err := resolveVariables(context.Background(), readTemplateVariables(spec), map[string][]string{"region": {"eu"}}, querier)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"expr": `label_values(up{region="eu", env="prod"}, host)`}, queried)
+	require.Equal(t, []ResolvedVariable{
+		{Name: "env", Type: "constant", Text: []string{"prod"}, Value: []string{"prod"}},
+		{Name: "region", Type: "custom", Text: []string{"Europe"}, Value: []string{"eu"}, Options: []VariableOption{
+			{Text: "Europe", Value: "eu"}, {Text: "America", Value: "us"},
+		}},
+		{Name: "host", Type: "query", Text: []string{"All"}, Value: []string{"b", "a"}, Options: []VariableOption{
+			{Text: "b", Value: "b"}, {Text: "a", Value: "a"},
+		}},
+		{Name: "filter", Type: "textbox", Text: []string{"default"}, Value: []string{"default"}},
+		{Name: "legacy", Type: "query", Text: []string{"a"}, Value: []string{"a"}, Options: []VariableOption{{Text: "a", Value: "a"}},
+			Warning: "the query of the variable can only be evaluated by the frontend, the saved options are used"},
+	}, resolved)
+
+	out := interpolateSpec(spec, resolved)
+	require.Equal(t, "Servers in eu", out["title"])
+	require.Equal(t, `up{region="eu", host=~"(b|a)"}`, out["panels"].([]any)[0].(map[string]any)["targets"].([]any)[0].(map[string]any)["expr"])
+	list := out["templating"].(map[string]any)["list"].([]any)
+	require.Equal(t, map[string]any{"text": "Europe", "value": "eu"}, list[1].(map[string]any)["current"])
+	// the dashboard itself is not changed
+	require.Equal(t, "Servers in $region", spec["title"])
+}
+
+func TestVariableOptionsFromFrames(t *testing.T) {
+	require.Equal(t, []VariableOption{
+		{Text: "a", Value: "1"},
+		{Text: "b", Value: "2"},
+		{Text: "c", Value: "c"},
+	}, variableOptionsFromFrames(data.Frames{
+		data.NewFrame("",
+			data.NewField("__text", nil, []string{"a", "b"}),
+			data.NewField("__value", nil, []int64{1, 2}),
+		),
+		data.NewFrame("", data.NewField("host", nil, []string{"c"})),
+	}))
+}
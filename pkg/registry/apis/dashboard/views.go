@@ -0,0 +1,342 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+const (
+	// viewsNamespace is the kvstore namespace of the views of dashboards, they are keyed by the UID of the dashboard
+	// in its org
+	viewsNamespace = "dashboards.views"
+	// viewsDays is the number of days the views are counted for
+	viewsDays = 30
+	// viewsFlushInterval is how often the recorded views are stored and the viewed dashboards indexed again
+	viewsFlushInterval = time.Minute
+
+	IndexFieldViewsLast30d = "views_last_30d"
+	IndexFieldLastViewedAt = "last_viewed_at"
+)
+
+// dashboardViewStats are the views of a dashboard
+type dashboardViewStats struct {
+	// Days are the number of views per UTC day, e.g. 2024-01-31, of the last 30 days
+	Days         map[string]int64 `json:"days"`
+	LastViewedAt time.Time        `json:"lastViewedAt"`
+}
+
+// add counts the views of other in s
+func (s *dashboardViewStats) add(other *dashboardViewStats) {
+	if s.Days == nil {
+		s.Days = make(map[string]int64, len(other.Days))
+	}
+	for day, count := range other.Days {
+		s.Days[day] += count
+	}
+	if other.LastViewedAt.After(s.LastViewedAt) {
+		s.LastViewedAt = other.LastViewedAt
+	}
+}
+
+// prune removes the days before the first day counted at now, it returns true when some were removed
+func (s *dashboardViewStats) prune(now time.Time) bool {
+	first := viewsFirstDay(now)
+	pruned := false
+	for day := range s.Days {
+		if day < first {
+			delete(s.Days, day)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// viewsSince sums the views of the days from the first day counted at now
+func (s *dashboardViewStats) viewsSince(now time.Time) int64 {
+	first := viewsFirstDay(now)
+	total := int64(0)
+	for day, count := range s.Days {
+		if day >= first {
+			total += count
+		}
+	}
+	return total
+}
+
+// viewsFirstDay is the first of the last 30 days at now, including the current day
+func viewsFirstDay(now time.Time) string {
+	return now.UTC().AddDate(0, 0, 1-viewsDays).Format(time.DateOnly)
+}
+
+// DashboardViews counts the views of dashboards loaded through the DTO, as the UI does, and indexes the number of
+// views of the last 30 days and the time of the last view of every dashboard, so the search can sort and filter
+// on them, e.g. to list the popular dashboards or the ones nobody looks at anymore. The views are kept in memory
+// and stored every minute, the viewed dashboards are then indexed again.
+type DashboardViews struct {
+	kv         kvstore.KVStore
+	admin      resource.IndexAdmin
+	features   featuremgmt.FeatureToggles
+	namespacer request.NamespaceMapper
+	now        func() time.Time
+	log        log.Logger
+
+	mu sync.Mutex
+	// stored are the stored views of the orgs whose dashboards were indexed, keyed by org and dashboard UID
+	stored map[int64]map[string]*dashboardViewStats
+	// pending are the views recorded since they were last stored
+	pending map[int64]map[string]*dashboardViewStats
+	// prunedDay is the day the views older than 30 days were last pruned from stored
+	prunedDay string
+}
+
+func ProvideDashboardViews(cfg *setting.Cfg, features featuremgmt.FeatureToggles, sql db.DB, unified resource.ResourceClient) *DashboardViews {
+	// the documents can only be refreshed when the index is in process, a remote index is refreshed when it is rebuilt
+	admin, _ := unified.(resource.IndexAdmin)
+	views := NewDashboardViews(kvstore.ProvideService(sql), admin, request.GetNamespaceMapper(cfg))
+	views.features = features
+	return views
+}
+
+func NewDashboardViews(kv kvstore.KVStore, admin resource.IndexAdmin, namespacer request.NamespaceMapper) *DashboardViews {
+	return &DashboardViews{
+		kv:         kv,
+		admin:      admin,
+		namespacer: namespacer,
+		now:        time.Now,
+		log:        log.New("grafana-apiserver.dashboards.views"),
+		stored:     map[int64]map[string]*dashboardViewStats{},
+		pending:    map[int64]map[string]*dashboardViewStats{},
+	}
+}
+
+// IsDisabled returns true when the dashboards API is not registered
+func (v *DashboardViews) IsDisabled() bool {
+	return v.features == nil || (!v.features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) &&
+		!v.features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI))
+}
+
+// Run stores the recorded views every minute, and once more when it is stopped
+func (v *DashboardViews) Run(ctx context.Context) error {
+	ticker := time.NewTicker(viewsFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			v.flush(ctx)
+		case <-ctx.Done():
+			v.flush(context.WithoutCancel(ctx))
+			return ctx.Err()
+		}
+	}
+}
+
+// Record counts a view of the dashboard, it is stored and indexed with the next flush
+func (v *DashboardViews) Record(orgID int64, uid string) {
+	now := v.now().UTC()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.pending[orgID] == nil {
+		v.pending[orgID] = map[string]*dashboardViewStats{}
+	}
+	stats, ok := v.pending[orgID][uid]
+	if !ok {
+		stats = &dashboardViewStats{Days: map[string]int64{}}
+		v.pending[orgID][uid] = stats
+	}
+	stats.Days[now.Format(time.DateOnly)]++
+	stats.LastViewedAt = now
+}
+
+// IndexFields are the fields of the search index with the views of the dashboards. Dashboards that were not viewed in
+// the last 30 days have 0 views, the last view is not indexed for the dashboards that were never viewed.
+func (v *DashboardViews) IndexFields() []resource.IndexField {
+	return []resource.IndexField{
+		{
+			Field: IndexFieldViewsLast30d,
+			Type:  "int64",
+			Usage: func(namespace string, name string) (any, bool) {
+				stats, ok := v.stats(namespace, name)
+				if !ok {
+					return int64(0), true
+				}
+				return stats.viewsSince(v.now()), true
+			},
+		},
+		{
+			Field: IndexFieldLastViewedAt,
+			Type:  "time",
+			Usage: func(namespace string, name string) (any, bool) {
+				stats, ok := v.stats(namespace, name)
+				if !ok || stats.LastViewedAt.IsZero() {
+					return nil, false
+				}
+				// the format of the dates of the index documents
+				return stats.LastViewedAt.UTC().Format("2006-01-02T15:04:05Z"), true
+			},
+		},
+	}
+}
+
+// stats returns the stored and pending views of a dashboard, the stored views of its org are loaded the first time
+func (v *DashboardViews) stats(namespace string, uid string) (dashboardViewStats, bool) {
+	info, err := claims.ParseNamespace(namespace)
+	if err != nil {
+		return dashboardViewStats{}, false
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	stored, ok := v.stored[info.OrgID]
+	if !ok {
+		stored, err = v.load(context.Background(), info.OrgID)
+		if err != nil {
+			v.log.Error("Failed to load the views of dashboards", "orgId", info.OrgID, "error", err)
+			return dashboardViewStats{}, false
+		}
+		v.stored[info.OrgID] = stored
+	}
+
+	stats := dashboardViewStats{}
+	s, found := stored[uid]
+	if found {
+		stats.add(s)
+	}
+	if p, ok := v.pending[info.OrgID][uid]; ok {
+		stats.add(p)
+		found = true
+	}
+	return stats, found
+}
+
+// load reads the stored views of the dashboards of an org
+func (v *DashboardViews) load(ctx context.Context, orgID int64) (map[string]*dashboardViewStats, error) {
+	all, err := v.kv.GetAll(ctx, orgID, viewsNamespace)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]*dashboardViewStats, len(all[orgID]))
+	for uid, value := range all[orgID] {
+		stats := &dashboardViewStats{}
+		if err := json.Unmarshal([]byte(value), stats); err != nil {
+			v.log.Warn("Ignoring invalid dashboard views", "uid", uid, "orgId", orgID, "error", err)
+			continue
+		}
+		stored[uid] = stats
+	}
+	return stored, nil
+}
+
+// flush adds the pending views to the stored ones and indexes the viewed dashboards again. Once a day, the dashboards
+// whose oldest views are not counted anymore are indexed again too. The views that fail to be stored are kept for
+// the next flush.
+func (v *DashboardViews) flush(ctx context.Context) {
+	now := v.now()
+	v.mu.Lock()
+	pending := v.pending
+	v.pending = map[int64]map[string]*dashboardViewStats{}
+	refresh := v.pruneStored(now)
+	v.mu.Unlock()
+
+	for orgID, dashboards := range pending {
+		for uid, views := range dashboards {
+			stats, err := v.store(ctx, orgID, uid, views, now)
+			v.mu.Lock()
+			if err != nil {
+				v.log.Error("Failed to store the views of a dashboard", "uid", uid, "orgId", orgID, "error", err)
+				if v.pending[orgID] == nil {
+					v.pending[orgID] = map[string]*dashboardViewStats{}
+				}
+				if p, ok := v.pending[orgID][uid]; ok {
+					views.add(p)
+				}
+				v.pending[orgID][uid] = views
+				v.mu.Unlock()
+				continue
+			}
+			if stored, ok := v.stored[orgID]; ok {
+				stored[uid] = stats
+			}
+			v.mu.Unlock()
+			refresh[orgID] = append(refresh[orgID], uid)
+		}
+	}
+
+	for orgID, uids := range refresh {
+		v.refresh(ctx, orgID, uids)
+	}
+}
+
+// store adds the views to the stored views of the dashboard, the views of other instances included
+func (v *DashboardViews) store(ctx context.Context, orgID int64, uid string, views *dashboardViewStats, now time.Time) (*dashboardViewStats, error) {
+	stats := &dashboardViewStats{}
+	value, ok, err := v.kv.Get(ctx, orgID, viewsNamespace, uid)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if err := json.Unmarshal([]byte(value), stats); err != nil {
+			v.log.Warn("Replacing invalid dashboard views", "uid", uid, "orgId", orgID, "error", err)
+			stats = &dashboardViewStats{}
+		}
+	}
+	stats.add(views)
+	stats.prune(now)
+	body, err := json.Marshal(stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, v.kv.Set(ctx, orgID, viewsNamespace, uid, string(body))
+}
+
+// pruneStored removes the days that are not counted anymore from the stored views once a day, and returns the
+// dashboards whose count changed. The mutex must be held.
+func (v *DashboardViews) pruneStored(now time.Time) map[int64][]string {
+	pruned := map[int64][]string{}
+	day := now.UTC().Format(time.DateOnly)
+	if day == v.prunedDay {
+		return pruned
+	}
+	v.prunedDay = day
+	for orgID, stored := range v.stored {
+		for uid, stats := range stored {
+			if stats.prune(now) {
+				pruned[orgID] = append(pruned[orgID], uid)
+			}
+		}
+	}
+	return pruned
+}
+
+// refresh indexes the dashboards again with their current views
+func (v *DashboardViews) refresh(ctx context.Context, orgID int64, uids []string) {
+	if v.admin == nil {
+		return
+	}
+	gr := dashboard.DashboardResourceInfo.GroupResource()
+	err := v.admin.RefreshDocuments(ctx, &resource.ResourceKey{
+		Namespace: v.namespacer(orgID),
+		Group:     gr.Group,
+		Resource:  gr.Resource,
+	}, uids)
+	if errors.Is(err, resource.ErrIndexAdminUnsupported) || errors.Is(err, resource.ErrIndexNotReady) {
+		// the views are indexed when the index is built
+		v.log.Debug("Dashboard views are not indexed", "orgId", orgID, "error", err)
+		return
+	}
+	if err != nil {
+		v.log.Warn("Failed to index the views of dashboards", "orgId", orgID, "dashboards", len(uids), "error", err)
+	}
+}
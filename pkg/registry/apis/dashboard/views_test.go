@@ -0,0 +1,123 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func TestDashboardViews(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	kv := kvstore.NewFakeKVStore()
+	admin := &fakeIndexAdmin{}
+	views := NewDashboardViews(kv, admin, request.GetNamespaceMapper(&setting.Cfg{}))
+	views.now = func() time.Time { return now }
+
+	fields := map[string]resource.IndexField{}
+	for _, f := range views.IndexFields() {
+		fields[f.Field] = f
+	}
+	value := func(field string, namespace string, uid string) any {
+		v, ok := fields[field].Usage(namespace, uid)
+		if !ok {
+			return nil
+		}
+		return v
+	}
+
+	t.Run("dashboards that were never viewed have no views", func(t *testing.T) {
+		require.Equal(t, int64(0), value(IndexFieldViewsLast30d, "default", "abc"))
+		require.Nil(t, value(IndexFieldLastViewedAt, "default", "abc"))
+	})
+
+	t.Run("recorded views are indexed before they are stored", func(t *testing.T) {
+		views.Record(1, "abc")
+		views.Record(1, "abc")
+		views.Record(2, "abc")
+		require.Equal(t, int64(2), value(IndexFieldViewsLast30d, "default", "abc"))
+		require.Equal(t, "2024-03-10T12:00:00Z", value(IndexFieldLastViewedAt, "default", "abc"))
+		require.Equal(t, int64(1), value(IndexFieldViewsLast30d, "org-2", "abc"))
+		require.Nil(t, value(IndexFieldLastViewedAt, "invalid", "abc"))
+	})
+
+	t.Run("flush stores the views and refreshes the viewed dashboards", func(t *testing.T) {
+		views.flush(context.Background())
+		require.ElementsMatch(t, []string{"default/abc", "org-2/abc"}, admin.refreshed)
+		require.Equal(t, "dashboard.grafana.app", admin.key.Group)
+		require.Equal(t, "dashboards", admin.key.Resource)
+
+		stored, ok, err := kv.Get(context.Background(), 1, viewsNamespace, "abc")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.JSONEq(t, `{"days":{"2024-03-10":2},"lastViewedAt":"2024-03-10T12:00:00Z"}`, stored)
+		require.Equal(t, int64(2), value(IndexFieldViewsLast30d, "default", "abc"))
+
+		// nothing was viewed since
+		admin.refreshed = nil
+		views.flush(context.Background())
+		require.Empty(t, admin.refreshed)
+	})
+
+	t.Run("views of other instances are added", func(t *testing.T) {
+		other := NewDashboardViews(kv, nil, request.GetNamespaceMapper(&setting.Cfg{}))
+		other.now = func() time.Time { return now.Add(time.Hour) }
+		other.Record(1, "abc")
+		other.flush(context.Background())
+
+		views.Record(1, "abc")
+		views.flush(context.Background())
+		require.Equal(t, int64(4), value(IndexFieldViewsLast30d, "default", "abc"))
+		require.Equal(t, "2024-03-10T13:00:00Z", value(IndexFieldLastViewedAt, "default", "abc"))
+	})
+
+	t.Run("views older than 30 days are not counted", func(t *testing.T) {
+		admin.refreshed = nil
+		views.now = func() time.Time { return now.AddDate(0, 0, 30) }
+		require.Equal(t, int64(0), value(IndexFieldViewsLast30d, "default", "abc"))
+		require.Equal(t, "2024-03-10T13:00:00Z", value(IndexFieldLastViewedAt, "default", "abc"))
+
+		// the dashboards whose count changed are indexed again once a day
+		views.flush(context.Background())
+		require.ElementsMatch(t, []string{"default/abc", "org-2/abc"}, admin.refreshed)
+		admin.refreshed = nil
+		views.flush(context.Background())
+		require.Empty(t, admin.refreshed)
+	})
+
+	t.Run("views that failed to be stored are kept", func(t *testing.T) {
+		failing := NewDashboardViews(&failingKVStore{KVStore: kvstore.NewFakeKVStore()}, nil, request.GetNamespaceMapper(&setting.Cfg{}))
+		failing.Record(1, "abc")
+		failing.flush(context.Background())
+		require.Equal(t, int64(1), failing.pending[1]["abc"].Days[failing.now().UTC().Format(time.DateOnly)])
+	})
+}
+
+type fakeIndexAdmin struct {
+	resource.IndexAdmin
+	key       *resource.ResourceKey
+	refreshed []string
+}
+
+func (a *fakeIndexAdmin) RefreshDocuments(_ context.Context, key *resource.ResourceKey, names []string) error {
+	a.key = key
+	for _, name := range names {
+		a.refreshed = append(a.refreshed, key.Namespace+"/"+name)
+	}
+	return nil
+}
+
+type failingKVStore struct {
+	kvstore.KVStore
+}
+
+func (kv *failingKVStore) Set(context.Context, int64, string, string, string) error {
+	return errors.New("database is locked")
+}
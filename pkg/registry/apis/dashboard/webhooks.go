@@ -0,0 +1,868 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/authlib/claims"
+	commonV0 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	secretskv "github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// webhookNamespace is the kvstore namespace of the webhooks, they are keyed by their UID in their org
+	webhookNamespace = "dashboards.webhooks"
+	// webhookDeliveryNamespace is the kvstore namespace of the recent deliveries, keyed by the UID of their webhook
+	// and their ID so the instances sending them never overwrite each other
+	webhookDeliveryNamespace = "dashboards.webhook-deliveries"
+	// webhookSecretType is the type of the secrets of the webhooks in the secrets kvstore, keyed by the UID of their
+	// webhook
+	webhookSecretType = "dashboard-webhook"
+
+	// maxWebhooks is the maximum number of webhooks of an org
+	maxWebhooks = 20
+	// webhookDeliveriesKept is the number of recent deliveries kept per webhook
+	webhookDeliveriesKept = 20
+	// maxPendingWebhookDeliveries is the number of deliveries sent at the same time, the other deliveries fail
+	maxPendingWebhookDeliveries = 1000
+	webhookMaxAttempts          = 3
+	webhookMaxRedirects         = 3
+	webhookTimeout              = 10 * time.Second
+
+	// the changes of dashboards sent to webhooks
+	WebhookEventCreate  = "create"
+	WebhookEventUpdate  = "update"
+	WebhookEventDelete  = "delete"
+	WebhookEventRestore = "restore"
+
+	// the headers of the webhook requests
+	WebhookEventHeader     = "X-Grafana-Event"
+	WebhookDeliveryHeader  = "X-Grafana-Delivery"
+	WebhookSignatureHeader = "X-Grafana-Signature"
+
+	// the status of the deliveries
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+var webhookEvents = []string{WebhookEventCreate, WebhookEventUpdate, WebhookEventDelete, WebhookEventRestore}
+
+// webhookDeniedPrefixes are the shared and reserved networks the webhooks can not be sent to, on top of the private,
+// loopback and link-local addresses
+var webhookDeniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+var (
+	ErrInvalidWebhook      = errutil.BadRequest("dashboards.webhooks.invalid")
+	ErrWebhookAccessDenied = errutil.Forbidden("dashboards.webhooks.forbidden", errutil.WithPublicMessage("You are not allowed to manage the dashboard webhooks"))
+	ErrWebhookNotFound     = errutil.NotFound("dashboards.webhooks.not-found", errutil.WithPublicMessage("Webhook not found"))
+
+	errWebhookStopped = errors.New("delivery canceled, the server is stopping")
+)
+
+// DashboardWebhook sends the changes made to the dashboards of a namespace through the dashboards API to an
+// external system
+type DashboardWebhook struct {
+	UID string `json:"uid"`
+	URL string `json:"url"`
+	// Events are the changes sent to the webhook, all of them when empty
+	Events []string `json:"events,omitempty"`
+	// HasSecret is true when the requests are signed, the secret is kept in the secrets kvstore and never returned
+	HasSecret bool      `json:"hasSecret"`
+	Disabled  bool      `json:"disabled,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
+
+// DashboardWebhookRequest creates a webhook, or replaces the webhook with the UID
+type DashboardWebhookRequest struct {
+	UID string `json:"uid,omitempty"`
+	URL string `json:"url"`
+	// Secret signs the requests, the secret of a replaced webhook is kept when it is not set and removed when it is empty
+	Secret   *string  `json:"secret,omitempty"`
+	Events   []string `json:"events,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
+}
+
+// DashboardWebhookEvent is the body of the requests sent to the webhooks. The versions are the generations of the
+// dashboard before and after the change, 0 when it did not exist.
+type DashboardWebhookEvent struct {
+	ID         string                `json:"id"`
+	Event      string                `json:"event"`
+	Timestamp  time.Time             `json:"timestamp"`
+	Namespace  string                `json:"namespace"`
+	UID        string                `json:"uid"`
+	Title      string                `json:"title,omitempty"`
+	FolderUID  string                `json:"folderUid,omitempty"`
+	Actor      string                `json:"actor,omitempty"`
+	OldVersion int64                 `json:"oldVersion"`
+	NewVersion int64                 `json:"newVersion"`
+	Diff       *DashboardDiffSummary `json:"diff,omitempty"`
+}
+
+// DashboardDiffSummary summarizes the changes of an update of a dashboard
+type DashboardDiffSummary struct {
+	// PreviousTitle is set when the title changed
+	PreviousTitle string `json:"previousTitle,omitempty"`
+	// Fields are the fields of the spec that were added, removed or changed
+	Fields        []string `json:"fields"`
+	PanelsAdded   int      `json:"panelsAdded"`
+	PanelsRemoved int      `json:"panelsRemoved"`
+	PanelsChanged int      `json:"panelsChanged"`
+}
+
+// DashboardWebhookDelivery is the status of the delivery of a change to a webhook
+type DashboardWebhookDelivery struct {
+	ID           string    `json:"id"`
+	Event        string    `json:"event"`
+	DashboardUID string    `json:"dashboardUid"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	StatusCode   int       `json:"statusCode,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// DashboardWebhooks keeps the webhooks of the orgs and sends them the changes made to the dashboards. The requests
+// are sent in the background and retried on network errors, 429 and 5xx responses. The changes of a dashboard can be
+// received out of order, the versions tell which change is the latest. The requests are never sent to private,
+// loopback or link-local addresses, including after a redirect.
+type DashboardWebhooks struct {
+	kv         kvstore.KVStore
+	secrets    secretskv.SecretsKVStore
+	tombstones *TombstoneStore
+	features   featuremgmt.FeatureToggles
+	client     *http.Client
+	// allowedAddress is false for the addresses the requests can not be sent to
+	allowedAddress func(addr netip.Addr) bool
+	// backoff are the waits before the retries
+	backoff []time.Duration
+	now     func() time.Time
+	log     log.Logger
+
+	pending chan struct{}
+	// ctx is the context of the requests, it is canceled when the service stops
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mu guards stopped, no delivery is started once the service stops
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func ProvideDashboardWebhooks(features featuremgmt.FeatureToggles, sql db.DB, secrets secretskv.SecretsKVStore, tombstones *TombstoneStore) *DashboardWebhooks {
+	webhooks := NewDashboardWebhooks(kvstore.ProvideService(sql), secrets, tombstones)
+	webhooks.features = features
+	return webhooks
+}
+
+// NewDashboardWebhooks returns the webhooks kept in kv, with their secrets kept in secrets. Without tombstones,
+// restored dashboards are sent as created.
+func NewDashboardWebhooks(kv kvstore.KVStore, secrets secretskv.SecretsKVStore, tombstones *TombstoneStore) *DashboardWebhooks {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &DashboardWebhooks{
+		kv:             kv,
+		secrets:        secrets,
+		tombstones:     tombstones,
+		allowedAddress: allowedWebhookAddress,
+		backoff:        []time.Duration{5 * time.Second, 30 * time.Second},
+		now:            time.Now,
+		log:            log.New("grafana-apiserver.dashboards.webhooks"),
+		pending:        make(chan struct{}, maxPendingWebhookDeliveries),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	w.client = w.newClient()
+	return w
+}
+
+// newClient returns a client that checks the addresses it connects to and the URLs it is redirected to, the proxy
+// of the environment is not used as the addresses it connects to could not be checked
+func (w *DashboardWebhooks) newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !w.allowedAddress(addr.Addr().Unmap()) {
+				return fmt.Errorf("webhook address %s is not allowed", addr.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= webhookMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", webhookMaxRedirects)
+			}
+			return w.validateURL(req.URL)
+		},
+	}
+}
+
+// allowedWebhookAddress is false for the private, loopback, link-local, multicast, unspecified and reserved addresses
+func allowedWebhookAddress(addr netip.Addr) bool {
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range webhookDeniedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateURL checks the scheme of the URL, and its host when it is an address. The addresses of the host names are
+// checked when connecting.
+func (w *DashboardWebhooks) validateURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q, expected an http or https url", u.Redacted())
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("webhook host %s is not allowed", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !w.allowedAddress(addr.Unmap()) {
+		return fmt.Errorf("webhook address %s is not allowed", addr)
+	}
+	return nil
+}
+
+// IsDisabled returns true when the dashboards API is not registered
+func (w *DashboardWebhooks) IsDisabled() bool {
+	return w.features == nil || (!w.features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) &&
+		!w.features.IsEnabledGlobally(featuremgmt.FlagKubernetesDashboardsAPI))
+}
+
+// Run waits for the service to stop, then cancels the pending deliveries and waits for them to be recorded as failed
+func (w *DashboardWebhooks) Run(ctx context.Context) error {
+	<-ctx.Done()
+	w.stop()
+	return ctx.Err()
+}
+
+func (w *DashboardWebhooks) stop() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+	w.cancel()
+	w.wg.Wait()
+}
+
+// start reserves a delivery, it is false once the service stops
+func (w *DashboardWebhooks) start() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return false
+	}
+	w.wg.Add(1)
+	return true
+}
+
+// List returns the webhooks of the org sorted by UID
+func (w *DashboardWebhooks) List(ctx context.Context, orgID int64) ([]DashboardWebhook, error) {
+	all, err := w.kv.GetAll(ctx, orgID, webhookNamespace)
+	if err != nil {
+		return nil, err
+	}
+	hooks := []DashboardWebhook{}
+	for uid, value := range all[orgID] {
+		h := DashboardWebhook{}
+		if err := json.Unmarshal([]byte(value), &h); err != nil {
+			w.log.Warn("Ignoring invalid dashboard webhook", "uid", uid, "orgId", orgID, "error", err)
+			continue
+		}
+		hooks = append(hooks, h)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].UID < hooks[j].UID })
+	return hooks, nil
+}
+
+func (w *DashboardWebhooks) get(ctx context.Context, orgID int64, uid string) (*DashboardWebhook, error) {
+	value, ok, err := w.kv.Get(ctx, orgID, webhookNamespace, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+	}
+	h := &DashboardWebhook{}
+	if err := json.Unmarshal([]byte(value), h); err != nil {
+		return nil, fmt.Errorf("invalid dashboard webhook %s: %w", uid, err)
+	}
+	return h, nil
+}
+
+// Save creates the webhook of the request, or replaces the webhook with its UID
+func (w *DashboardWebhooks) Save(ctx context.Context, user identity.Requester, req DashboardWebhookRequest) (*DashboardWebhook, error) {
+	if err := w.validateRequest(req); err != nil {
+		return nil, err
+	}
+	hook := &DashboardWebhook{}
+	if req.UID != "" {
+		current, err := w.get(ctx, user.GetOrgID(), req.UID)
+		if err != nil {
+			return nil, err
+		}
+		hook.UID, hook.HasSecret = current.UID, current.HasSecret
+	} else {
+		hooks, err := w.List(ctx, user.GetOrgID())
+		if err != nil {
+			return nil, err
+		}
+		if len(hooks) >= maxWebhooks {
+			return nil, ErrInvalidWebhook.Errorf("an org can not have more than %d webhooks", maxWebhooks)
+		}
+		hook.UID = util.GenerateShortUID()
+	}
+	if req.Secret != nil {
+		var err error
+		if *req.Secret == "" {
+			err = w.secrets.Del(ctx, user.GetOrgID(), hook.UID, webhookSecretType)
+		} else {
+			err = w.secrets.Set(ctx, user.GetOrgID(), hook.UID, webhookSecretType, *req.Secret)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save the secret of dashboard webhook %s: %w", hook.UID, err)
+		}
+		hook.HasSecret = *req.Secret != ""
+	}
+	hook.URL = req.URL
+	hook.Events = req.Events
+	hook.Disabled = req.Disabled
+	hook.UpdatedAt = w.now().UTC()
+	hook.UpdatedBy = user.GetUID()
+
+	value, err := json.Marshal(hook)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.kv.Set(ctx, user.GetOrgID(), webhookNamespace, hook.UID, string(value)); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (w *DashboardWebhooks) validateRequest(req DashboardWebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return ErrInvalidWebhook.Errorf("invalid url %q, expected an http or https url", req.URL)
+	}
+	if err := w.validateURL(u); err != nil {
+		return ErrInvalidWebhook.Errorf("%w", err)
+	}
+	for _, e := range req.Events {
+		if !slices.Contains(webhookEvents, e) {
+			return ErrInvalidWebhook.Errorf("invalid event %q, expected one of %v", e, webhookEvents)
+		}
+	}
+	return nil
+}
+
+// Delete removes the webhook, its secret and its deliveries
+func (w *DashboardWebhooks) Delete(ctx context.Context, user identity.Requester, uid string) error {
+	hook, err := w.get(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return err
+	}
+	if err := w.kv.Del(ctx, user.GetOrgID(), webhookNamespace, uid); err != nil {
+		return err
+	}
+	if hook.HasSecret {
+		if err := w.secrets.Del(ctx, user.GetOrgID(), uid, webhookSecretType); err != nil {
+			return err
+		}
+	}
+	keys, err := w.kv.Keys(ctx, user.GetOrgID(), webhookDeliveryNamespace, deliveryKeyPrefix(uid))
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := w.kv.Del(ctx, user.GetOrgID(), webhookDeliveryNamespace, key.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliveries returns the recent deliveries of the webhook, newest first
+func (w *DashboardWebhooks) Deliveries(ctx context.Context, orgID int64, uid string) ([]DashboardWebhookDelivery, error) {
+	if _, err := w.get(ctx, orgID, uid); err != nil {
+		return nil, err
+	}
+	return w.deliveries(ctx, orgID, uid)
+}
+
+func deliveryKeyPrefix(uid string) string {
+	return uid + "/"
+}
+
+func (w *DashboardWebhooks) deliveries(ctx context.Context, orgID int64, uid string) ([]DashboardWebhookDelivery, error) {
+	all, err := w.kv.GetAll(ctx, orgID, webhookDeliveryNamespace)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []DashboardWebhookDelivery{}
+	for key, value := range all[orgID] {
+		if !strings.HasPrefix(key, deliveryKeyPrefix(uid)) {
+			continue
+		}
+		d := DashboardWebhookDelivery{}
+		if err := json.Unmarshal([]byte(value), &d); err != nil {
+			w.log.Warn("Ignoring an invalid delivery of a dashboard webhook", "uid", uid, "orgId", orgID, "key", key, "error", err)
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	return deliveries, nil
+}
+
+// recordDelivery saves the status of a delivery. Every delivery has its own key, only the instance sending it
+// updates it.
+func (w *DashboardWebhooks) recordDelivery(ctx context.Context, orgID int64, uid string, delivery DashboardWebhookDelivery) {
+	value, err := json.Marshal(delivery)
+	if err == nil {
+		err = w.kv.Set(ctx, orgID, webhookDeliveryNamespace, deliveryKeyPrefix(uid)+delivery.ID, string(value))
+	}
+	if err != nil {
+		w.log.Error("Failed to record the delivery of a dashboard webhook", "uid", uid, "orgId", orgID, "delivery", delivery.ID, "error", err)
+	}
+}
+
+// pruneDeliveries removes the oldest deliveries of the webhook
+func (w *DashboardWebhooks) pruneDeliveries(ctx context.Context, orgID int64, uid string) {
+	deliveries, err := w.deliveries(ctx, orgID, uid)
+	if err != nil {
+		w.log.Warn("Failed to read the deliveries of a dashboard webhook", "uid", uid, "orgId", orgID, "error", err)
+		return
+	}
+	for _, d := range deliveries[min(len(deliveries), webhookDeliveriesKept):] {
+		if err := w.kv.Del(ctx, orgID, webhookDeliveryNamespace, deliveryKeyPrefix(uid)+d.ID); err != nil {
+			w.log.Warn("Failed to remove an old delivery of a dashboard webhook", "uid", uid, "orgId", orgID, "delivery", d.ID, "error", err)
+		}
+	}
+}
+
+// secret returns the secret signing the requests of the webhook
+func (w *DashboardWebhooks) secret(ctx context.Context, orgID int64, hook DashboardWebhook) (string, error) {
+	if !hook.HasSecret {
+		return "", nil
+	}
+	secret, ok, err := w.secrets.Get(ctx, orgID, hook.UID, webhookSecretType)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("the secret of dashboard webhook %s is missing", hook.UID)
+	}
+	return secret, nil
+}
+
+// notify sends a change of a dashboard to the webhooks of its org that subscribe to the event. The previous and the
+// new object are nil when the dashboard did not exist before or after the change.
+func (w *DashboardWebhooks) notify(ctx context.Context, event string, previous, current runtime.Object) {
+	obj := current
+	if obj == nil {
+		obj = previous
+	}
+	m, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return
+	}
+	info, err := claims.ParseNamespace(m.GetNamespace())
+	if err != nil {
+		return
+	}
+	hooks, err := w.List(ctx, info.OrgID)
+	if err != nil {
+		w.log.Error("Failed to read the dashboard webhooks", "orgId", info.OrgID, "error", err)
+		return
+	}
+	hooks = slices.DeleteFunc(hooks, func(h DashboardWebhook) bool {
+		return h.Disabled || (len(h.Events) > 0 && !slices.Contains(h.Events, event))
+	})
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload := DashboardWebhookEvent{
+		Event:      event,
+		Timestamp:  w.now().UTC(),
+		Namespace:  m.GetNamespace(),
+		UID:        m.GetName(),
+		Title:      tombstoneTitle(m),
+		FolderUID:  m.GetFolder(),
+		OldVersion: auditGeneration(previous),
+		NewVersion: auditGeneration(current),
+	}
+	if user, err := identity.GetRequester(ctx); err == nil {
+		payload.Actor = user.GetUID()
+	}
+	if event == WebhookEventUpdate {
+		payload.Diff = dashboardDiffSummary(previous, current)
+	}
+	// the deliveries are recorded after the request
+	ctx = context.WithoutCancel(ctx)
+	for _, hook := range hooks {
+		payload.ID = util.GenerateShortUID()
+		body, err := json.Marshal(payload)
+		if err != nil {
+			w.log.Error("Failed to encode a dashboard webhook event", "uid", payload.UID, "event", event, "error", err)
+			return
+		}
+		delivery := DashboardWebhookDelivery{
+			ID:           payload.ID,
+			Event:        event,
+			DashboardUID: payload.UID,
+			Status:       WebhookDeliveryPending,
+			CreatedAt:    payload.Timestamp,
+			UpdatedAt:    payload.Timestamp,
+		}
+		secret, err := w.secret(ctx, info.OrgID, hook)
+		if err != nil {
+			delivery.Status, delivery.Error = WebhookDeliveryFailed, err.Error()
+		} else if !w.start() {
+			delivery.Status, delivery.Error = WebhookDeliveryFailed, errWebhookStopped.Error()
+		}
+		if delivery.Status == WebhookDeliveryFailed {
+			w.recordDelivery(ctx, info.OrgID, hook.UID, delivery)
+			w.pruneDeliveries(ctx, info.OrgID, hook.UID)
+			continue
+		}
+		select {
+		case w.pending <- struct{}{}:
+			w.recordDelivery(ctx, info.OrgID, hook.UID, delivery)
+			go func(hook DashboardWebhook, delivery DashboardWebhookDelivery, body []byte) {
+				defer func() {
+					<-w.pending
+					w.wg.Done()
+				}()
+				w.deliver(ctx, info.OrgID, hook, secret, delivery, body)
+			}(hook, delivery, body)
+		default:
+			w.wg.Done()
+			delivery.Status, delivery.Error = WebhookDeliveryFailed, "too many pending deliveries"
+			w.recordDelivery(ctx, info.OrgID, hook.UID, delivery)
+		}
+		w.pruneDeliveries(ctx, info.OrgID, hook.UID)
+	}
+}
+
+// deliver sends the body to the webhook until it succeeds, fails with an error that is not retried, the attempts are
+// exhausted or the service stops. The deliveries are recorded with ctx, the requests are canceled when the service
+// stops.
+func (w *DashboardWebhooks) deliver(ctx context.Context, orgID int64, hook DashboardWebhook, secret string, delivery DashboardWebhookDelivery, body []byte) {
+	for {
+		delivery.Attempts++
+		code, retry, err := w.send(w.ctx, hook, secret, delivery, body)
+		delivery.StatusCode, delivery.Error, delivery.UpdatedAt = code, "", w.now().UTC()
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		switch {
+		case err == nil:
+			delivery.Status = WebhookDeliverySucceeded
+		case w.ctx.Err() != nil:
+			delivery.Status, delivery.Error = WebhookDeliveryFailed, errWebhookStopped.Error()
+		case !retry || delivery.Attempts >= webhookMaxAttempts:
+			delivery.Status = WebhookDeliveryFailed
+		}
+		w.recordDelivery(ctx, orgID, hook.UID, delivery)
+		if delivery.Status != WebhookDeliveryPending {
+			if delivery.Status == WebhookDeliveryFailed {
+				w.log.Warn("Failed to deliver a dashboard webhook", "uid", hook.UID, "orgId", orgID, "delivery", delivery.ID, "attempts", delivery.Attempts, "error", delivery.Error)
+			}
+			return
+		}
+		wait := w.backoff[min(delivery.Attempts, len(w.backoff))-1]
+		select {
+		case <-w.ctx.Done():
+			delivery.Status, delivery.Error, delivery.UpdatedAt = WebhookDeliveryFailed, errWebhookStopped.Error(), w.now().UTC()
+			w.recordDelivery(ctx, orgID, hook.UID, delivery)
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send posts the body to the webhook, retry is true when the request can succeed later
+func (w *DashboardWebhooks) send(ctx context.Context, hook DashboardWebhook, secret string, delivery DashboardWebhookDelivery, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+}
+
+// SignWebhookBody returns the signature of a webhook request, sent in the X-Grafana-Signature header: sha256= and the
+// hex encoded HMAC-SHA256 of the body with the secret of the webhook
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// redactWebhookURL returns the scheme and host of a webhook URL, its path, query and credentials often hold the
+// token authenticating the requests
+func redactWebhookURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if u.User != nil || strings.TrimPrefix(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		redacted += "/***"
+	}
+	return redacted
+}
+
+// dashboardDiffSummary compares the specs of two versions of a dashboard. The panels are matched by id, or by
+// element name in the v2 spec. The version and id of the spec change on every save, they are not compared.
+func dashboardDiffSummary(previous, current runtime.Object) *DashboardDiffSummary {
+	before, after := webhookSpec(previous), webhookSpec(current)
+	if before == nil || after == nil {
+		return nil
+	}
+	diff := &DashboardDiffSummary{Fields: []string{}}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			after[k] = nil
+		}
+	}
+	for k, v := range after {
+		if k != "version" && k != "id" && !reflect.DeepEqual(before[k], v) {
+			diff.Fields = append(diff.Fields, k)
+		}
+	}
+	sort.Strings(diff.Fields)
+	if title, _ := before["title"].(string); title != after["title"] {
+		diff.PreviousTitle = title
+	}
+
+	panelsBefore, panelsAfter := webhookPanels(before), webhookPanels(after)
+	for key, panel := range panelsAfter {
+		previous, ok := panelsBefore[key]
+		switch {
+		case !ok:
+			diff.PanelsAdded++
+		case !reflect.DeepEqual(previous, panel):
+			diff.PanelsChanged++
+		}
+	}
+	for key := range panelsBefore {
+		if _, ok := panelsAfter[key]; !ok {
+			diff.PanelsRemoved++
+		}
+	}
+	return diff
+}
+
+// webhookSpec returns the spec of a dashboard as a map, the spec of the dashboards before v2 is unstructured
+func webhookSpec(obj runtime.Object) map[string]any {
+	if obj == nil {
+		return nil
+	}
+	m, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil
+	}
+	spec, err := m.GetSpec()
+	if err != nil {
+		return nil
+	}
+	if u, ok := spec.(commonV0.Unstructured); ok {
+		return u.DeepCopy().Object
+	}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// webhookPanels returns the panels of a spec by id, including the panels nested in rows, or the elements of a v2 spec
+func webhookPanels(spec map[string]any) map[string]any {
+	if elements, ok := spec["elements"].(map[string]any); ok {
+		return elements
+	}
+	panels := map[string]any{}
+	walkPanels(spec["panels"], func(panel map[string]any) {
+		key := fmt.Sprint(panel["id"])
+		if panel["type"] == "row" {
+			// the panels of collapsed rows are compared on their own
+			panel = maps.Clone(panel)
+			delete(panel, "panels")
+		}
+		panels[key] = panel
+	})
+	return panels
+}
+
+// webhookStorage sends the changes made to dashboards through the storage to the DashboardWebhooks
+type webhookStorage struct {
+	grafanarest.Storage
+	webhooks *DashboardWebhooks
+}
+
+// webhookWatchStorage keeps watch support of storages that implement it
+type webhookWatchStorage struct {
+	*webhookStorage
+	rest.Watcher
+}
+
+// WithWebhooks sends the changes made to dashboards through the storage to the webhooks of their org, other storages
+// are returned unchanged. A dashboard created again while its tombstone is kept is sent as restored, so the storage
+// must be wrapped after WithTombstones.
+func WithWebhooks(store rest.Storage, webhooks *DashboardWebhooks) rest.Storage {
+	s, ok := store.(grafanarest.Storage)
+	if !ok || webhooks == nil {
+		return store
+	}
+	if w, ok := store.(rest.Watcher); ok {
+		return &webhookWatchStorage{webhookStorage: &webhookStorage{Storage: s, webhooks: webhooks}, Watcher: w}
+	}
+	return &webhookStorage{Storage: s, webhooks: webhooks}
+}
+
+// restored is true when the dashboard of the request namespace was deleted in the retention window of the tombstones
+func (s *webhookStorage) restored(ctx context.Context, name string) bool {
+	info, err := claims.ParseNamespace(k8srequest.NamespaceValue(ctx))
+	if err != nil {
+		return false
+	}
+	return s.webhooks.tombstones.has(ctx, info.OrgID, name)
+}
+
+func (s *webhookStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	event := WebhookEventCreate
+	if m, err := utils.MetaAccessor(obj); err == nil && s.restored(ctx, m.GetName()) {
+		event = WebhookEventRestore
+	}
+	created, err := s.Storage.Create(ctx, obj, createValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.webhooks.notify(ctx, event, nil, created)
+	}
+	return created, err
+}
+
+func (s *webhookStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	// the storage reads the current dashboard to validate the update, keep it to summarize the changes
+	var previous runtime.Object
+	validate := func(ctx context.Context, obj, old runtime.Object) error {
+		previous = old
+		if updateValidation != nil {
+			return updateValidation(ctx, obj, old)
+		}
+		return nil
+	}
+	restored := forceAllowCreate && s.restored(ctx, name)
+	updated, created, err := s.Storage.Update(ctx, name, objInfo, createValidation, validate, forceAllowCreate, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		switch {
+		case created && restored:
+			s.webhooks.notify(ctx, WebhookEventRestore, nil, updated)
+		case created:
+			s.webhooks.notify(ctx, WebhookEventCreate, nil, updated)
+		default:
+			s.webhooks.notify(ctx, WebhookEventUpdate, previous, updated)
+		}
+	}
+	return updated, created, err
+}
+
+func (s *webhookStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	deleted, immediate, err := s.Storage.Delete(ctx, name, deleteValidation, options)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		s.webhooks.notify(ctx, WebhookEventDelete, deleted, nil)
+	}
+	return deleted, immediate, err
+}
+
+func (s *webhookStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	deleted, err := s.Storage.DeleteCollection(ctx, deleteValidation, options, listOptions)
+	if err == nil && (options == nil || len(options.DryRun) == 0) {
+		_ = meta.EachListItem(deleted, func(obj runtime.Object) error {
+			s.webhooks.notify(ctx, WebhookEventDelete, obj, nil)
+			return nil
+		})
+	}
+	return deleted, err
+}
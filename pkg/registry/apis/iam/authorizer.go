@@ -3,6 +3,7 @@ package iam
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/grafana/authlib/authz"
 	"github.com/grafana/authlib/claims"
@@ -58,9 +59,39 @@ func newLegacyAuthorizer(ac accesscontrol.AccessControl, store legacy.LegacyIden
 		accesscontrol.ResourceAuthorizerOptions{
 			Resource: iamv0.TeamResourceInfo.GetName(),
 			Attr:     "id",
+			Resolver: teamResolver(store),
+		},
+		accesscontrol.ResourceAuthorizerOptions{
+			// The history of the roles of a team
+			Resource: iamv0.TeamResourceInfo.GetName() + "/rolehistory",
+			Mapping: map[string]string{
+				utils.VerbGet: accesscontrol.ActionTeamsRolesRead,
+			},
+			Resolver: teamResolver(store),
+		},
+		accesscontrol.ResourceAuthorizerOptions{
+			Resource: iamv0.TeamRoleResourceInfo.GetName(),
+			Attr:     "id",
+			Mapping: map[string]string{
+				utils.VerbGet:    accesscontrol.ActionTeamsRolesRead,
+				utils.VerbList:   accesscontrol.ActionTeamsRolesRead,
+				utils.VerbWatch:  accesscontrol.ActionTeamsRolesRead,
+				utils.VerbCreate: accesscontrol.ActionTeamsRolesAdd,
+				utils.VerbDelete: accesscontrol.ActionTeamsRolesRemove,
+			},
+			// The team of a new binding is in its spec, not in the request, so the store checks
+			// teams.roles:add on the team when it creates the binding.
+			Unchecked: map[string]bool{
+				utils.VerbCreate: true,
+			},
+			// The permissions on the bindings are the permissions on their team
 			Resolver: accesscontrol.ResourceResolverFunc(func(ctx context.Context, ns claims.NamespaceInfo, name string) ([]string, error) {
+				teamUID, _, ok := strings.Cut(name, iamv0.TeamRoleNameSeparator)
+				if !ok {
+					return nil, fmt.Errorf("invalid team role name %q", name)
+				}
 				res, err := store.GetTeamInternalID(ctx, ns, legacy.GetTeamInternalIDQuery{
-					UID: name,
+					UID: teamUID,
 				})
 				if err != nil {
 					return nil, err
@@ -68,7 +99,41 @@ func newLegacyAuthorizer(ac accesscontrol.AccessControl, store legacy.LegacyIden
 				return []string{fmt.Sprintf("teams:id:%d", res.ID)}, nil
 			}),
 		},
+		accesscontrol.ResourceAuthorizerOptions{
+			// The teams a role is assigned to, the store only lists the teams whose roles the requester can read
+			Resource: "roleteams",
+			Mapping: map[string]string{
+				utils.VerbGet: accesscontrol.ActionRolesRead,
+			},
+			Resolver: roleResolver,
+		},
+		accesscontrol.ResourceAuthorizerOptions{
+			// The history of the teams of a role, the store only lists the teams whose roles the requester can read
+			Resource: "rolehistory",
+			Mapping: map[string]string{
+				utils.VerbGet: accesscontrol.ActionRolesRead,
+			},
+			Resolver: roleResolver,
+		},
 	)
 
 	return gfauthorizer.NewResourceAuthorizer(client), client
 }
+
+// teamResolver resolves the scope of a team from its uid
+func teamResolver(store legacy.LegacyIdentityStore) accesscontrol.ResourceResolver {
+	return accesscontrol.ResourceResolverFunc(func(ctx context.Context, ns claims.NamespaceInfo, name string) ([]string, error) {
+		res, err := store.GetTeamInternalID(ctx, ns, legacy.GetTeamInternalIDQuery{
+			UID: name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []string{fmt.Sprintf("teams:id:%d", res.ID)}, nil
+	})
+}
+
+// roleResolver resolves the scope of a role from its uid
+var roleResolver = accesscontrol.ResourceResolverFunc(func(ctx context.Context, ns claims.NamespaceInfo, name string) ([]string, error) {
+	return []string{accesscontrol.ScopeRolesPrefix + name}, nil
+})
@@ -0,0 +1,63 @@
+package iam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/authlib/authz"
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// fakeIdentityStore has a team, team-1 with id 1
+type fakeIdentityStore struct {
+	legacy.LegacyIdentityStore
+}
+
+func (f *fakeIdentityStore) GetTeamInternalID(_ context.Context, _ claims.NamespaceInfo, query legacy.GetTeamInternalIDQuery) (*legacy.GetTeamInternalIDResult, error) {
+	if query.UID != "team-1" {
+		return nil, legacy.ErrTeamNotFound
+	}
+	return &legacy.GetTeamInternalIDResult{ID: 1}, nil
+}
+
+func TestLegacyAuthorizer(t *testing.T) {
+	ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	_, client := newLegacyAuthorizer(ac, &fakeIdentityStore{})
+	check := func(t *testing.T, permissions map[string][]string, req authz.CheckRequest) bool {
+		user := &identity.StaticRequester{OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}}
+		req.Namespace = "default"
+		res, err := client.Check(context.Background(), user, req)
+		require.NoError(t, err)
+		return res.Allowed
+	}
+
+	t.Run("the teams of a role need roles:read on the role", func(t *testing.T) {
+		req := authz.CheckRequest{Verb: utils.VerbGet, Resource: "roleteams", Name: "role-1"}
+		require.True(t, check(t, map[string][]string{accesscontrol.ActionRolesRead: {accesscontrol.ScopeRolesAll}}, req))
+		require.True(t, check(t, map[string][]string{accesscontrol.ActionRolesRead: {"roles:uid:role-1"}}, req))
+		require.False(t, check(t, map[string][]string{accesscontrol.ActionRolesRead: {"roles:uid:role-2"}}, req))
+		require.False(t, check(t, map[string][]string{accesscontrol.ActionTeamsRolesRead: {accesscontrol.ScopeTeamsAll}}, req))
+	})
+
+	t.Run("the history of a role needs roles:read on the role", func(t *testing.T) {
+		req := authz.CheckRequest{Verb: utils.VerbGet, Resource: "rolehistory", Name: "role-1"}
+		require.True(t, check(t, map[string][]string{accesscontrol.ActionRolesRead: {"roles:uid:role-1"}}, req))
+		require.False(t, check(t, map[string][]string{accesscontrol.ActionRolesRead: {"roles:uid:role-2"}}, req))
+	})
+
+	t.Run("the history of a team needs teams.roles:read on the team", func(t *testing.T) {
+		req := authz.CheckRequest{Verb: utils.VerbGet, Resource: "teams", Subresource: "rolehistory", Name: "team-1"}
+		require.True(t, check(t, map[string][]string{accesscontrol.ActionTeamsRolesRead: {"teams:id:1"}}, req))
+		require.False(t, check(t, map[string][]string{accesscontrol.ActionTeamsRolesRead: {"teams:id:2"}}, req))
+		require.False(t, check(t, map[string][]string{accesscontrol.ActionTeamsRead: {"teams:id:1"}}, req))
+	})
+}
@@ -0,0 +1,5 @@
+SELECT r.id, r.name
+FROM {{ .Ident .RoleTable }} as r
+WHERE (r.org_id = {{ .Arg .Query.OrgID }} OR r.org_id = 0)
+AND r.uid = {{ .Arg .Query.UID }}
+LIMIT 1;
@@ -0,0 +1,4 @@
+SELECT p.action, p.scope
+FROM {{ .Ident .PermissionTable }} as p
+WHERE p.role_id = {{ .Arg .Query.RoleID }}
+ORDER BY p.id;
@@ -0,0 +1,10 @@
+SELECT tr.id, t.uid, t.name
+  FROM {{ .Ident .TeamRoleTable }} as tr
+ INNER JOIN {{ .Ident .TeamTable }} as t ON tr.team_id = t.id
+ WHERE tr.org_id = {{ .Arg .Query.OrgID }}
+   AND tr.role_id = {{ .Arg .Query.RoleID }}
+{{ if .Query.Pagination.Continue }}
+   AND tr.id >= {{ .Arg .Query.Pagination.Continue }}
+{{ end }}
+ ORDER BY tr.id asc
+ LIMIT {{ .Arg .Query.Pagination.Limit }}
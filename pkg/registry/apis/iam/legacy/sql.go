@@ -26,6 +26,15 @@ type LegacyIdentityStore interface {
 	ListTeams(ctx context.Context, ns claims.NamespaceInfo, query ListTeamQuery) (*ListTeamResult, error)
 	ListTeamBindings(ctx context.Context, ns claims.NamespaceInfo, query ListTeamBindingsQuery) (*ListTeamBindingsResult, error)
 	ListTeamMembers(ctx context.Context, ns claims.NamespaceInfo, query ListTeamMembersQuery) (*ListTeamMembersResult, error)
+
+	GetRoleInternalID(ctx context.Context, ns claims.NamespaceInfo, query GetRoleInternalIDQuery) (*GetRoleInternalIDResult, error)
+	GetRolePermissions(ctx context.Context, ns claims.NamespaceInfo, query GetRolePermissionsQuery) (*GetRolePermissionsResult, error)
+	ListTeamRoles(ctx context.Context, ns claims.NamespaceInfo, query ListTeamRolesQuery) (*ListTeamRolesResult, error)
+	ListRoleTeams(ctx context.Context, ns claims.NamespaceInfo, query ListRoleTeamsQuery) (*ListRoleTeamsResult, error)
+	CreateTeamRole(ctx context.Context, ns claims.NamespaceInfo, cmd CreateTeamRoleCommand) (*TeamRole, error)
+	DeleteTeamRole(ctx context.Context, ns claims.NamespaceInfo, cmd DeleteTeamRoleCommand) error
+	SyncTeamRoles(ctx context.Context, ns claims.NamespaceInfo, cmd SyncTeamRolesCommand) (*SyncTeamRolesResult, error)
+	ListTeamRoleHistory(ctx context.Context, ns claims.NamespaceInfo, query ListTeamRoleHistoryQuery) (*ListTeamRoleHistoryResult, error)
 }
 
 var (
@@ -3,6 +3,7 @@ package legacy
 import (
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/grafana/grafana/pkg/registry/apis/iam/common"
 	"github.com/grafana/grafana/pkg/storage/legacysql"
@@ -54,6 +55,48 @@ func TestIdentityQueries(t *testing.T) {
 		return &v
 	}
 
+	listTeamRoles := func(q *ListTeamRolesQuery) sqltemplate.SQLTemplate {
+		v := newListTeamRoles(nodb, q)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
+	listRoleTeams := func(q *ListRoleTeamsQuery) sqltemplate.SQLTemplate {
+		v := newListRoleTeams(nodb, q)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
+	getRoleInternalID := func(q *GetRoleInternalIDQuery) sqltemplate.SQLTemplate {
+		v := newGetRoleInternalID(nodb, q)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
+	getRolePermissions := func(q *GetRolePermissionsQuery) sqltemplate.SQLTemplate {
+		v := newGetRolePermissions(nodb, q)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
+	teamRoleCmd := func(cmd *teamRoleCommand) sqltemplate.SQLTemplate {
+		v := newTeamRoleCommand(nodb, cmd)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
+	insertTeamRoleAudit := func(entry *TeamRoleAuditEntry) sqltemplate.SQLTemplate {
+		v := newInsertTeamRoleAudit(nodb, entry)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
+	listTeamRoleHistory := func(q *ListTeamRoleHistoryQuery) sqltemplate.SQLTemplate {
+		v := newListTeamRoleHistory(nodb, q)
+		v.SQLTemplate = mocks.NewTestingSQLTemplate()
+		return &v
+	}
+
 	mocks.CheckQuerySnapshots(t, mocks.TemplateTestSetup{
 		RootDir: "testdata",
 		Templates: map[*template.Template][]mocks.TemplateTestCase{
@@ -192,6 +235,118 @@ func TestIdentityQueries(t *testing.T) {
 					}),
 				},
 			},
+			sqlQueryTeamRolesTemplate: {
+				{
+					Name: "team_roles_page_1",
+					Data: listTeamRoles(&ListTeamRolesQuery{
+						OrgID:      1,
+						Pagination: common.Pagination{Limit: 5},
+					}),
+				},
+				{
+					Name: "team_roles_page_2",
+					Data: listTeamRoles(&ListTeamRolesQuery{
+						OrgID:      1,
+						Pagination: common.Pagination{Limit: 1, Continue: 2},
+					}),
+				},
+				{
+					Name: "team_roles_team_and_role",
+					Data: listTeamRoles(&ListTeamRolesQuery{
+						OrgID:      1,
+						TeamUID:    "team-1",
+						RoleUID:    "role-1",
+						Pagination: common.Pagination{Limit: 1},
+					}),
+				},
+			},
+			sqlQueryRoleTeamsTemplate: {
+				{
+					Name: "role_teams_page_1",
+					Data: listRoleTeams(&ListRoleTeamsQuery{
+						OrgID:      1,
+						RoleID:     2,
+						Pagination: common.Pagination{Limit: 5},
+					}),
+				},
+				{
+					Name: "role_teams_page_2",
+					Data: listRoleTeams(&ListRoleTeamsQuery{
+						OrgID:      1,
+						RoleID:     2,
+						Pagination: common.Pagination{Limit: 1, Continue: 3},
+					}),
+				},
+			},
+			sqlQueryRoleInternalIDTemplate: {
+				{
+					Name: "role_uid",
+					Data: getRoleInternalID(&GetRoleInternalIDQuery{
+						OrgID: 1,
+						UID:   "role-1",
+					}),
+				},
+			},
+			sqlQueryRolePermissionsTemplate: {
+				{
+					Name: "role_permissions",
+					Data: getRolePermissions(&GetRolePermissionsQuery{
+						RoleID: 1,
+					}),
+				},
+			},
+			sqlInsertTeamRoleTemplate: {
+				{
+					Name: "insert",
+					Data: teamRoleCmd(&teamRoleCommand{
+						OrgID:   1,
+						TeamID:  2,
+						RoleID:  3,
+						Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					}),
+				},
+			},
+			sqlDeleteTeamRoleTemplate: {
+				{
+					Name: "delete",
+					Data: teamRoleCmd(&teamRoleCommand{
+						OrgID:  1,
+						TeamID: 2,
+						RoleID: 3,
+					}),
+				},
+			},
+			sqlInsertTeamRoleAuditTemplate: {
+				{
+					Name: "insert",
+					Data: insertTeamRoleAudit(&TeamRoleAuditEntry{
+						OrgID:   1,
+						TeamUID: "team-1",
+						RoleUID: "role-1",
+						Action:  TeamRoleActionCreate,
+						Actor:   "user:u000000001",
+						Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					}),
+				},
+			},
+			sqlQueryTeamRoleHistoryTemplate: {
+				{
+					Name: "team_history_page_1",
+					Data: listTeamRoleHistory(&ListTeamRoleHistoryQuery{
+						OrgID:      1,
+						TeamUID:    "team-1",
+						Pagination: common.Pagination{Limit: 5},
+					}),
+				},
+				{
+					Name: "role_history_page_2",
+					Data: listTeamRoleHistory(&ListTeamRoleHistoryQuery{
+						OrgID:      1,
+						RoleUID:    "role-1",
+						Pagination: common.Pagination{Limit: 1, Continue: 3},
+					}),
+				},
+			},
 		},
 	})
 }
@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
 )
 
+var ErrTeamNotFound = errors.New("team not found")
+
 type GetTeamInternalIDQuery struct {
 	OrgID int64
 	UID   string
@@ -75,7 +77,7 @@ func (s *legacySQLStore) GetTeamInternalID(
 	}
 
 	if !rows.Next() {
-		return nil, errors.New("team not found")
+		return nil, ErrTeamNotFound
 	}
 
 	var id int64
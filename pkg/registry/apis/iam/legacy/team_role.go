@@ -0,0 +1,699 @@
+package legacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/common"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore/session"
+	"github.com/grafana/grafana/pkg/storage/legacysql"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+var (
+	ErrRoleNotFound     = errors.New("role not found")
+	ErrTeamRoleNotFound = errors.New("team role not found")
+	ErrTeamRoleExists   = errors.New("team role already exists")
+)
+
+type GetRoleInternalIDQuery struct {
+	OrgID int64
+	UID   string
+}
+
+type GetRoleInternalIDResult struct {
+	ID   int64
+	Name string
+}
+
+var sqlQueryRoleInternalIDTemplate = mustTemplate("role_internal_id.sql")
+
+func newGetRoleInternalID(sql *legacysql.LegacyDatabaseHelper, q *GetRoleInternalIDQuery) getRoleInternalIDQuery {
+	return getRoleInternalIDQuery{
+		SQLTemplate: sqltemplate.New(sql.DialectForDriver()),
+		RoleTable:   sql.Table("role"),
+		Query:       q,
+	}
+}
+
+type getRoleInternalIDQuery struct {
+	sqltemplate.SQLTemplate
+	RoleTable string
+	Query     *GetRoleInternalIDQuery
+}
+
+func (r getRoleInternalIDQuery) Validate() error { return nil }
+
+// GetRoleInternalID implements LegacyIdentityStore. Global roles are found in every org.
+func (s *legacySQLStore) GetRoleInternalID(
+	ctx context.Context,
+	ns claims.NamespaceInfo,
+	query GetRoleInternalIDQuery,
+) (*GetRoleInternalIDResult, error) {
+	query.OrgID = ns.OrgID
+	if query.OrgID == 0 {
+		return nil, fmt.Errorf("expected non zero org id")
+	}
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := newGetRoleInternalID(sql, &query)
+	q, err := sqltemplate.Execute(sqlQueryRoleInternalIDTemplate, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlQueryRoleInternalIDTemplate.Name(), err)
+	}
+
+	rows, err := sql.DB.GetSqlxSession().Query(ctx, q, req.GetArgs()...)
+	defer func() {
+		if rows != nil {
+			_ = rows.Close()
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		return nil, ErrRoleNotFound
+	}
+
+	res := &GetRoleInternalIDResult{}
+	if err := rows.Scan(&res.ID, &res.Name); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type GetRolePermissionsQuery struct {
+	RoleID int64
+}
+
+type GetRolePermissionsResult struct {
+	Permissions []accesscontrol.Permission
+}
+
+var sqlQueryRolePermissionsTemplate = mustTemplate("role_permissions_query.sql")
+
+func newGetRolePermissions(sql *legacysql.LegacyDatabaseHelper, q *GetRolePermissionsQuery) getRolePermissionsQuery {
+	return getRolePermissionsQuery{
+		SQLTemplate:     sqltemplate.New(sql.DialectForDriver()),
+		PermissionTable: sql.Table("permission"),
+		Query:           q,
+	}
+}
+
+type getRolePermissionsQuery struct {
+	sqltemplate.SQLTemplate
+	PermissionTable string
+	Query           *GetRolePermissionsQuery
+}
+
+func (r getRolePermissionsQuery) Validate() error { return nil }
+
+// GetRolePermissions implements LegacyIdentityStore. The role is found with GetRoleInternalID first.
+func (s *legacySQLStore) GetRolePermissions(
+	ctx context.Context,
+	ns claims.NamespaceInfo,
+	query GetRolePermissionsQuery,
+) (*GetRolePermissionsResult, error) {
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := newGetRolePermissions(sql, &query)
+	q, err := sqltemplate.Execute(sqlQueryRolePermissionsTemplate, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlQueryRolePermissionsTemplate.Name(), err)
+	}
+
+	rows, err := sql.DB.GetSqlxSession().Query(ctx, q, req.GetArgs()...)
+	defer func() {
+		if rows != nil {
+			_ = rows.Close()
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GetRolePermissionsResult{}
+	for rows.Next() {
+		p := accesscontrol.Permission{}
+		if err := rows.Scan(&p.Action, &p.Scope); err != nil {
+			return nil, err
+		}
+		res.Permissions = append(res.Permissions, p)
+	}
+
+	return res, rows.Err()
+}
+
+type TeamRole struct {
+	ID      int64
+	RoleID  int64
+	TeamUID string
+	RoleUID string
+	Created time.Time
+}
+
+type ListTeamRolesQuery struct {
+	OrgID int64
+	// TeamUID and RoleUID are optional filters
+	TeamUID string
+	RoleUID string
+
+	Pagination common.Pagination
+}
+
+type ListTeamRolesResult struct {
+	TeamRoles []TeamRole
+	Continue  int64
+	RV        int64
+}
+
+var sqlQueryTeamRolesTemplate = mustTemplate("team_roles_query.sql")
+
+type listTeamRolesQuery struct {
+	sqltemplate.SQLTemplate
+	Query         *ListTeamRolesQuery
+	TeamRoleTable string
+	TeamTable     string
+	RoleTable     string
+	// Managed roles hold the permissions granted on a single team, they are not role assignments
+	ManagedRolePattern string
+}
+
+func (r listTeamRolesQuery) Validate() error {
+	return nil // TODO
+}
+
+func newListTeamRoles(sql *legacysql.LegacyDatabaseHelper, q *ListTeamRolesQuery) listTeamRolesQuery {
+	return listTeamRolesQuery{
+		SQLTemplate:        sqltemplate.New(sql.DialectForDriver()),
+		TeamRoleTable:      sql.Table("team_role"),
+		TeamTable:          sql.Table("team"),
+		RoleTable:          sql.Table("role"),
+		ManagedRolePattern: accesscontrol.ManagedRolePrefix + "%",
+		Query:              q,
+	}
+}
+
+// ListTeamRoles implements LegacyIdentityStore.
+func (s *legacySQLStore) ListTeamRoles(ctx context.Context, ns claims.NamespaceInfo, query ListTeamRolesQuery) (*ListTeamRolesResult, error) {
+	// for continue
+	query.Pagination.Limit += 1
+	query.OrgID = ns.OrgID
+	if query.OrgID == 0 {
+		return nil, fmt.Errorf("expected non zero orgID")
+	}
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := queryTeamRoles(ctx, sql.DB.GetSqlxSession(), sql, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.TeamUID == "" && query.RoleUID == "" {
+		res.RV, err = sql.GetResourceVersion(ctx, "team_role", "created")
+	}
+
+	return res, err
+}
+
+// queryTeamRoles runs the list query with the querier, so it can be used inside a transaction.
+// The limit of the query must already include the extra row used for continue.
+func queryTeamRoles(ctx context.Context, db session.SessionQuerier, sql *legacysql.LegacyDatabaseHelper, query *ListTeamRolesQuery) (*ListTeamRolesResult, error) {
+	req := newListTeamRoles(sql, query)
+	q, err := sqltemplate.Execute(sqlQueryTeamRolesTemplate, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlQueryTeamRolesTemplate.Name(), err)
+	}
+
+	rows, err := db.Query(ctx, q, req.GetArgs()...)
+	defer func() {
+		if rows != nil {
+			_ = rows.Close()
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ListTeamRolesResult{}
+	for rows.Next() {
+		tr := TeamRole{}
+		err = rows.Scan(&tr.ID, &tr.RoleID, &tr.TeamUID, &tr.RoleUID, &tr.Created)
+		if err != nil {
+			return res, err
+		}
+
+		res.TeamRoles = append(res.TeamRoles, tr)
+		if len(res.TeamRoles) > int(query.Pagination.Limit)-1 {
+			res.TeamRoles = res.TeamRoles[0 : len(res.TeamRoles)-1]
+			res.Continue = tr.ID
+			break
+		}
+	}
+
+	return res, rows.Err()
+}
+
+type ListRoleTeamsQuery struct {
+	OrgID   int64
+	RoleUID string
+	// RoleID is resolved from RoleUID
+	RoleID int64
+
+	Pagination common.Pagination
+}
+
+// RoleTeam is a team a role is assigned to, ID is the id of the assignment
+type RoleTeam struct {
+	ID   int64
+	UID  string
+	Name string
+}
+
+type ListRoleTeamsResult struct {
+	Items    []RoleTeam
+	Continue int64
+}
+
+var sqlQueryRoleTeamsTemplate = mustTemplate("role_teams_query.sql")
+
+type listRoleTeamsQuery struct {
+	sqltemplate.SQLTemplate
+	Query         *ListRoleTeamsQuery
+	TeamRoleTable string
+	TeamTable     string
+}
+
+func (r listRoleTeamsQuery) Validate() error {
+	if r.Query.RoleID == 0 {
+		return fmt.Errorf("expected role id")
+	}
+	return nil
+}
+
+func newListRoleTeams(sql *legacysql.LegacyDatabaseHelper, q *ListRoleTeamsQuery) listRoleTeamsQuery {
+	return listRoleTeamsQuery{
+		SQLTemplate:   sqltemplate.New(sql.DialectForDriver()),
+		TeamRoleTable: sql.Table("team_role"),
+		TeamTable:     sql.Table("team"),
+		Query:         q,
+	}
+}
+
+// ListRoleTeams implements LegacyIdentityStore. It lists the teams a role is assigned to using the
+// team_role index on org and role, managed roles are not assigned and are not found.
+func (s *legacySQLStore) ListRoleTeams(ctx context.Context, ns claims.NamespaceInfo, query ListRoleTeamsQuery) (*ListRoleTeamsResult, error) {
+	// for continue
+	query.Pagination.Limit += 1
+	query.OrgID = ns.OrgID
+	if query.OrgID == 0 {
+		return nil, fmt.Errorf("expected non zero orgID")
+	}
+
+	role, err := s.GetRoleInternalID(ctx, ns, GetRoleInternalIDQuery{UID: query.RoleUID})
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(role.Name, accesscontrol.ManagedRolePrefix) {
+		return nil, ErrRoleNotFound
+	}
+	query.RoleID = role.ID
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := newListRoleTeams(sql, &query)
+	q, err := sqltemplate.Execute(sqlQueryRoleTeamsTemplate, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlQueryRoleTeamsTemplate.Name(), err)
+	}
+
+	rows, err := sql.DB.GetSqlxSession().Query(ctx, q, req.GetArgs()...)
+	defer func() {
+		if rows != nil {
+			_ = rows.Close()
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ListRoleTeamsResult{}
+	for rows.Next() {
+		t := RoleTeam{}
+		if err := rows.Scan(&t.ID, &t.UID, &t.Name); err != nil {
+			return res, err
+		}
+
+		res.Items = append(res.Items, t)
+		if len(res.Items) > int(query.Pagination.Limit)-1 {
+			res.Items = res.Items[0 : len(res.Items)-1]
+			res.Continue = t.ID
+			break
+		}
+	}
+
+	return res, rows.Err()
+}
+
+type CreateTeamRoleCommand struct {
+	TeamUID string
+	RoleUID string
+	// Actor is the uid of the identity making the change, recorded in the team role audit
+	Actor string
+}
+
+type DeleteTeamRoleCommand struct {
+	TeamUID string
+	RoleUID string
+	// Actor is the uid of the identity making the change, recorded in the team role audit
+	Actor string
+}
+
+// teamRoleCommand is the resolved form of a create or delete command
+type teamRoleCommand struct {
+	OrgID   int64
+	TeamID  int64
+	RoleID  int64
+	Created time.Time
+}
+
+var (
+	sqlInsertTeamRoleTemplate = mustTemplate("team_role_insert.sql")
+	sqlDeleteTeamRoleTemplate = mustTemplate("team_role_delete.sql")
+)
+
+type teamRoleCommandQuery struct {
+	sqltemplate.SQLTemplate
+	TeamRoleTable string
+	Command       *teamRoleCommand
+}
+
+func (r teamRoleCommandQuery) Validate() error {
+	if r.Command.TeamID == 0 || r.Command.RoleID == 0 {
+		return fmt.Errorf("expected team and role id")
+	}
+	return nil
+}
+
+func newTeamRoleCommand(sql *legacysql.LegacyDatabaseHelper, cmd *teamRoleCommand) teamRoleCommandQuery {
+	return teamRoleCommandQuery{
+		SQLTemplate:   sqltemplate.New(sql.DialectForDriver()),
+		TeamRoleTable: sql.Table("team_role"),
+		Command:       cmd,
+	}
+}
+
+// resolveTeamRole looks up the internal ids of the team and role of a binding
+func (s *legacySQLStore) resolveTeamRole(ctx context.Context, ns claims.NamespaceInfo, teamUID, roleUID string) (*teamRoleCommand, error) {
+	team, err := s.GetTeamInternalID(ctx, ns, GetTeamInternalIDQuery{UID: teamUID})
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.GetRoleInternalID(ctx, ns, GetRoleInternalIDQuery{UID: roleUID})
+	if err != nil {
+		return nil, err
+	}
+	return &teamRoleCommand{
+		OrgID:  ns.OrgID,
+		TeamID: team.ID,
+		RoleID: role.ID,
+	}, nil
+}
+
+// CreateTeamRole implements LegacyIdentityStore. The binding is recorded in the team role audit.
+func (s *legacySQLStore) CreateTeamRole(ctx context.Context, ns claims.NamespaceInfo, cmd CreateTeamRoleCommand) (*TeamRole, error) {
+	if ns.OrgID == 0 {
+		return nil, fmt.Errorf("expected non zero org id")
+	}
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.resolveTeamRole(ctx, ns, cmd.TeamUID, cmd.RoleUID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.ListTeamRoles(ctx, ns, ListTeamRolesQuery{
+		TeamUID:    cmd.TeamUID,
+		RoleUID:    cmd.RoleUID,
+		Pagination: common.Pagination{Limit: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing.TeamRoles) > 0 {
+		return nil, ErrTeamRoleExists
+	}
+
+	resolved.Created = time.Now()
+	req := newTeamRoleCommand(sql, resolved)
+	q, err := sqltemplate.Execute(sqlInsertTeamRoleTemplate, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlInsertTeamRoleTemplate.Name(), err)
+	}
+
+	var id int64
+	err = sql.DB.GetSqlxSession().WithTransaction(ctx, func(tx *session.SessionTx) error {
+		id, err = tx.ExecWithReturningId(ctx, q, req.GetArgs()...)
+		if err != nil {
+			return err
+		}
+		return insertTeamRoleAudit(ctx, tx, sql, &TeamRoleAuditEntry{
+			OrgID:   ns.OrgID,
+			TeamUID: cmd.TeamUID,
+			RoleUID: cmd.RoleUID,
+			Action:  TeamRoleActionCreate,
+			Actor:   cmd.Actor,
+			Created: resolved.Created,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TeamRole{
+		ID:      id,
+		RoleID:  resolved.RoleID,
+		TeamUID: cmd.TeamUID,
+		RoleUID: cmd.RoleUID,
+		Created: resolved.Created,
+	}, nil
+}
+
+// DeleteTeamRole implements LegacyIdentityStore. The removal is recorded in the team role audit.
+func (s *legacySQLStore) DeleteTeamRole(ctx context.Context, ns claims.NamespaceInfo, cmd DeleteTeamRoleCommand) error {
+	if ns.OrgID == 0 {
+		return fmt.Errorf("expected non zero org id")
+	}
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := s.resolveTeamRole(ctx, ns, cmd.TeamUID, cmd.RoleUID)
+	if err != nil {
+		if errors.Is(err, ErrTeamNotFound) || errors.Is(err, ErrRoleNotFound) {
+			return ErrTeamRoleNotFound
+		}
+		return err
+	}
+
+	req := newTeamRoleCommand(sql, resolved)
+	q, err := sqltemplate.Execute(sqlDeleteTeamRoleTemplate, req)
+	if err != nil {
+		return fmt.Errorf("execute template %q: %w", sqlDeleteTeamRoleTemplate.Name(), err)
+	}
+
+	return sql.DB.GetSqlxSession().WithTransaction(ctx, func(tx *session.SessionTx) error {
+		res, err := tx.Exec(ctx, q, req.GetArgs()...)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrTeamRoleNotFound
+		}
+		return insertTeamRoleAudit(ctx, tx, sql, &TeamRoleAuditEntry{
+			OrgID:   ns.OrgID,
+			TeamUID: cmd.TeamUID,
+			RoleUID: cmd.RoleUID,
+			Action:  TeamRoleActionDelete,
+			Actor:   cmd.Actor,
+			Created: time.Now(),
+		})
+	})
+}
+
+// MaxTeamRoleSync is the maximum number of roles a team can be synced to
+const MaxTeamRoleSync = 1000
+
+type SyncTeamRolesCommand struct {
+	TeamUID string
+	// RoleUIDs is the desired set of roles, managed roles are never added or removed
+	RoleUIDs []string
+	// Actor is the uid of the identity making the change, recorded in the team role audit
+	Actor string
+}
+
+type SyncTeamRolesResult struct {
+	Added     []string
+	Removed   []string
+	Unchanged []string
+}
+
+// SyncTeamRoles implements LegacyIdentityStore. It adds the missing roles and removes the extra
+// roles of a team in a single transaction, every added and removed role is recorded in the team role audit.
+func (s *legacySQLStore) SyncTeamRoles(ctx context.Context, ns claims.NamespaceInfo, cmd SyncTeamRolesCommand) (*SyncTeamRolesResult, error) {
+	if ns.OrgID == 0 {
+		return nil, fmt.Errorf("expected non zero org id")
+	}
+	if len(cmd.RoleUIDs) > MaxTeamRoleSync {
+		return nil, fmt.Errorf("expected at most %d roles", MaxTeamRoleSync)
+	}
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.GetTeamInternalID(ctx, ns, GetTeamInternalIDQuery{UID: cmd.TeamUID})
+	if err != nil {
+		return nil, err
+	}
+
+	desired := make(map[string]int64, len(cmd.RoleUIDs))
+	for _, uid := range cmd.RoleUIDs {
+		if _, ok := desired[uid]; ok {
+			continue
+		}
+		role, err := s.GetRoleInternalID(ctx, ns, GetRoleInternalIDQuery{UID: uid})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, uid)
+		}
+		desired[uid] = role.ID
+	}
+
+	res := &SyncTeamRolesResult{
+		Added:     []string{},
+		Removed:   []string{},
+		Unchanged: []string{},
+	}
+	err = sql.DB.GetSqlxSession().WithTransaction(ctx, func(tx *session.SessionTx) error {
+		current, err := queryTeamRoles(ctx, tx, sql, &ListTeamRolesQuery{
+			OrgID:   ns.OrgID,
+			TeamUID: cmd.TeamUID,
+			// one extra row to detect a team with more roles than we can sync
+			Pagination: common.Pagination{Limit: MaxTeamRoleSync + 1},
+		})
+		if err != nil {
+			return err
+		}
+		if current.Continue != 0 {
+			return fmt.Errorf("team has more than %d roles", MaxTeamRoleSync)
+		}
+
+		now := time.Now()
+		audit := func(roleUID string, action string) error {
+			return insertTeamRoleAudit(ctx, tx, sql, &TeamRoleAuditEntry{
+				OrgID:   ns.OrgID,
+				TeamUID: cmd.TeamUID,
+				RoleUID: roleUID,
+				Action:  action,
+				Actor:   cmd.Actor,
+				Created: now,
+			})
+		}
+
+		existing := make(map[string]bool, len(current.TeamRoles))
+		for _, tr := range current.TeamRoles {
+			existing[tr.RoleUID] = true
+			if _, ok := desired[tr.RoleUID]; ok {
+				res.Unchanged = append(res.Unchanged, tr.RoleUID)
+				continue
+			}
+			if err := execTeamRoleCommand(ctx, tx, sql, sqlDeleteTeamRoleTemplate, &teamRoleCommand{
+				OrgID:  ns.OrgID,
+				TeamID: team.ID,
+				RoleID: tr.RoleID,
+			}); err != nil {
+				return err
+			}
+			if err := audit(tr.RoleUID, TeamRoleActionDelete); err != nil {
+				return err
+			}
+			res.Removed = append(res.Removed, tr.RoleUID)
+		}
+
+		for uid, roleID := range desired {
+			if existing[uid] {
+				continue
+			}
+			if err := execTeamRoleCommand(ctx, tx, sql, sqlInsertTeamRoleTemplate, &teamRoleCommand{
+				OrgID:   ns.OrgID,
+				TeamID:  team.ID,
+				RoleID:  roleID,
+				Created: now,
+			}); err != nil {
+				return err
+			}
+			if err := audit(uid, TeamRoleActionCreate); err != nil {
+				return err
+			}
+			res.Added = append(res.Added, uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(res.Added)
+	sort.Strings(res.Removed)
+	sort.Strings(res.Unchanged)
+	return res, nil
+}
+
+func execTeamRoleCommand(ctx context.Context, tx *session.SessionTx, sql *legacysql.LegacyDatabaseHelper, tmpl *template.Template, cmd *teamRoleCommand) error {
+	req := newTeamRoleCommand(sql, cmd)
+	q, err := sqltemplate.Execute(tmpl, req)
+	if err != nil {
+		return fmt.Errorf("execute template %q: %w", tmpl.Name(), err)
+	}
+	_, err = tx.Exec(ctx, q, req.GetArgs()...)
+	return err
+}
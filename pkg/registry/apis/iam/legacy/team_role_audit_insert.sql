@@ -0,0 +1,4 @@
+INSERT INTO {{ .Ident .TeamRoleAuditTable }}
+  (org_id, team_uid, role_uid, action, actor, created)
+VALUES
+  ({{ .Arg .Entry.OrgID }}, {{ .Arg .Entry.TeamUID }}, {{ .Arg .Entry.RoleUID }}, {{ .Arg .Entry.Action }}, {{ .Arg .Entry.Actor }}, {{ .Arg .Entry.Created }})
@@ -0,0 +1,4 @@
+DELETE FROM {{ .Ident .TeamRoleTable }}
+ WHERE org_id = {{ .Arg .Command.OrgID }}
+   AND team_id = {{ .Arg .Command.TeamID }}
+   AND role_id = {{ .Arg .Command.RoleID }}
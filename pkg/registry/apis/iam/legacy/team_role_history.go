@@ -0,0 +1,152 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/common"
+	"github.com/grafana/grafana/pkg/services/sqlstore/session"
+	"github.com/grafana/grafana/pkg/storage/legacysql"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+// The actions recorded in the team role audit
+const (
+	TeamRoleActionCreate = "create"
+	TeamRoleActionDelete = "delete"
+)
+
+// TeamRoleAuditEntry records a role assigned to or removed from a team, Actor is the uid of the identity
+// that made the change
+type TeamRoleAuditEntry struct {
+	ID      int64
+	OrgID   int64
+	TeamUID string
+	RoleUID string
+	Action  string
+	Actor   string
+	Created time.Time
+}
+
+var sqlInsertTeamRoleAuditTemplate = mustTemplate("team_role_audit_insert.sql")
+
+type insertTeamRoleAuditQuery struct {
+	sqltemplate.SQLTemplate
+	TeamRoleAuditTable string
+	Entry              *TeamRoleAuditEntry
+}
+
+func (r insertTeamRoleAuditQuery) Validate() error {
+	if r.Entry.TeamUID == "" || r.Entry.RoleUID == "" {
+		return fmt.Errorf("expected team and role uid")
+	}
+	if r.Entry.Action != TeamRoleActionCreate && r.Entry.Action != TeamRoleActionDelete {
+		return fmt.Errorf("unexpected team role action %q", r.Entry.Action)
+	}
+	return nil
+}
+
+func newInsertTeamRoleAudit(sql *legacysql.LegacyDatabaseHelper, entry *TeamRoleAuditEntry) insertTeamRoleAuditQuery {
+	return insertTeamRoleAuditQuery{
+		SQLTemplate:        sqltemplate.New(sql.DialectForDriver()),
+		TeamRoleAuditTable: sql.Table("team_role_audit"),
+		Entry:              entry,
+	}
+}
+
+// insertTeamRoleAudit records a change of a team role in the transaction of the change
+func insertTeamRoleAudit(ctx context.Context, tx *session.SessionTx, sql *legacysql.LegacyDatabaseHelper, entry *TeamRoleAuditEntry) error {
+	req := newInsertTeamRoleAudit(sql, entry)
+	q, err := sqltemplate.Execute(sqlInsertTeamRoleAuditTemplate, req)
+	if err != nil {
+		return fmt.Errorf("execute template %q: %w", sqlInsertTeamRoleAuditTemplate.Name(), err)
+	}
+	_, err = tx.Exec(ctx, q, req.GetArgs()...)
+	return err
+}
+
+type ListTeamRoleHistoryQuery struct {
+	OrgID int64
+	// TeamUID and RoleUID are optional filters
+	TeamUID string
+	RoleUID string
+
+	Pagination common.Pagination
+}
+
+type ListTeamRoleHistoryResult struct {
+	Items    []TeamRoleAuditEntry
+	Continue int64
+}
+
+var sqlQueryTeamRoleHistoryTemplate = mustTemplate("team_role_history_query.sql")
+
+type listTeamRoleHistoryQuery struct {
+	sqltemplate.SQLTemplate
+	Query              *ListTeamRoleHistoryQuery
+	TeamRoleAuditTable string
+}
+
+func (r listTeamRoleHistoryQuery) Validate() error {
+	return nil
+}
+
+func newListTeamRoleHistory(sql *legacysql.LegacyDatabaseHelper, q *ListTeamRoleHistoryQuery) listTeamRoleHistoryQuery {
+	return listTeamRoleHistoryQuery{
+		SQLTemplate:        sqltemplate.New(sql.DialectForDriver()),
+		TeamRoleAuditTable: sql.Table("team_role_audit"),
+		Query:              q,
+	}
+}
+
+// ListTeamRoleHistory implements LegacyIdentityStore. It lists the changes of the team roles, oldest first.
+// The history of teams and roles that were deleted is kept.
+func (s *legacySQLStore) ListTeamRoleHistory(ctx context.Context, ns claims.NamespaceInfo, query ListTeamRoleHistoryQuery) (*ListTeamRoleHistoryResult, error) {
+	// for continue
+	query.Pagination.Limit += 1
+	query.OrgID = ns.OrgID
+	if query.OrgID == 0 {
+		return nil, fmt.Errorf("expected non zero orgID")
+	}
+
+	sql, err := s.sql(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := newListTeamRoleHistory(sql, &query)
+	q, err := sqltemplate.Execute(sqlQueryTeamRoleHistoryTemplate, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlQueryTeamRoleHistoryTemplate.Name(), err)
+	}
+
+	rows, err := sql.DB.GetSqlxSession().Query(ctx, q, req.GetArgs()...)
+	defer func() {
+		if rows != nil {
+			_ = rows.Close()
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ListTeamRoleHistoryResult{}
+	for rows.Next() {
+		e := TeamRoleAuditEntry{OrgID: query.OrgID}
+		if err := rows.Scan(&e.ID, &e.TeamUID, &e.RoleUID, &e.Action, &e.Actor, &e.Created); err != nil {
+			return res, err
+		}
+
+		res.Items = append(res.Items, e)
+		if len(res.Items) > int(query.Pagination.Limit)-1 {
+			res.Items = res.Items[0 : len(res.Items)-1]
+			res.Continue = e.ID
+			break
+		}
+	}
+
+	return res, rows.Err()
+}
@@ -0,0 +1,14 @@
+SELECT a.id, a.team_uid, a.role_uid, a.action, a.actor, a.created
+  FROM {{ .Ident .TeamRoleAuditTable }} as a
+ WHERE a.org_id = {{ .Arg .Query.OrgID }}
+{{ if .Query.TeamUID }}
+   AND a.team_uid = {{ .Arg .Query.TeamUID }}
+{{ end }}
+{{ if .Query.RoleUID }}
+   AND a.role_uid = {{ .Arg .Query.RoleUID }}
+{{ end }}
+{{ if .Query.Pagination.Continue }}
+   AND a.id >= {{ .Arg .Query.Pagination.Continue }}
+{{ end }}
+ ORDER BY a.id asc
+ LIMIT {{ .Arg .Query.Pagination.Limit }}
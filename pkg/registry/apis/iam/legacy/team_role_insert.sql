@@ -0,0 +1,4 @@
+INSERT INTO {{ .Ident .TeamRoleTable }}
+  (org_id, team_id, role_id, created)
+VALUES
+  ({{ .Arg .Command.OrgID }}, {{ .Arg .Command.TeamID }}, {{ .Arg .Command.RoleID }}, {{ .Arg .Command.Created }})
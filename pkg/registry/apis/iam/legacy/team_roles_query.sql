@@ -0,0 +1,17 @@
+SELECT tr.id, tr.role_id, t.uid as team_uid, r.uid as role_uid, tr.created
+  FROM {{ .Ident .TeamRoleTable }} as tr
+ INNER JOIN {{ .Ident .TeamTable }} as t ON tr.team_id = t.id
+ INNER JOIN {{ .Ident .RoleTable }} as r ON tr.role_id = r.id
+ WHERE tr.org_id = {{ .Arg .Query.OrgID }}
+   AND r.name NOT LIKE {{ .Arg .ManagedRolePattern }}
+{{ if .Query.TeamUID }}
+   AND t.uid = {{ .Arg .Query.TeamUID }}
+{{ end }}
+{{ if .Query.RoleUID }}
+   AND r.uid = {{ .Arg .Query.RoleUID }}
+{{ end }}
+{{ if .Query.Pagination.Continue }}
+   AND tr.id >= {{ .Arg .Query.Pagination.Continue }}
+{{ end }}
+ ORDER BY tr.id asc
+ LIMIT {{ .Arg .Query.Pagination.Limit }}
@@ -0,0 +1,5 @@
+SELECT r.id, r.name
+FROM `grafana`.`role` as r
+WHERE (r.org_id = 1 OR r.org_id = 0)
+AND r.uid = 'role-1'
+LIMIT 1;
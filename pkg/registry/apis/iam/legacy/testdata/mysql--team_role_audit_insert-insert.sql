@@ -0,0 +1,4 @@
+INSERT INTO `grafana`.`team_role_audit`
+  (org_id, team_uid, role_uid, action, actor, created)
+VALUES
+  (1, 'team-1', 'role-1', 'create', 'user:u000000001', '2024-01-01 00:00:00 +0000 UTC')
@@ -0,0 +1,4 @@
+INSERT INTO `grafana`.`team_role`
+  (org_id, team_id, role_id, created)
+VALUES
+  (1, 2, 3, '2024-01-01 00:00:00 +0000 UTC')
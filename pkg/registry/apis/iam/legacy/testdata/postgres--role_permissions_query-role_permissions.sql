@@ -0,0 +1,4 @@
+SELECT p.action, p.scope
+FROM "grafana"."permission" as p
+WHERE p.role_id = 1
+ORDER BY p.id;
@@ -0,0 +1,8 @@
+SELECT tr.id, tr.role_id, t.uid as team_uid, r.uid as role_uid, tr.created
+  FROM "grafana"."team_role" as tr
+ INNER JOIN "grafana"."team" as t ON tr.team_id = t.id
+ INNER JOIN "grafana"."role" as r ON tr.role_id = r.id
+ WHERE tr.org_id = 1
+   AND r.name NOT LIKE 'managed:%'
+ ORDER BY tr.id asc
+ LIMIT 5
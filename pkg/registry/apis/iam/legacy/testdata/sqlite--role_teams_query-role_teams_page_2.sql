@@ -0,0 +1,8 @@
+SELECT tr.id, t.uid, t.name
+  FROM "grafana"."team_role" as tr
+ INNER JOIN "grafana"."team" as t ON tr.team_id = t.id
+ WHERE tr.org_id = 1
+   AND tr.role_id = 2
+   AND tr.id >= 3
+ ORDER BY tr.id asc
+ LIMIT 1
@@ -0,0 +1,4 @@
+DELETE FROM "grafana"."team_role"
+ WHERE org_id = 1
+   AND team_id = 2
+   AND role_id = 3
@@ -0,0 +1,7 @@
+SELECT a.id, a.team_uid, a.role_uid, a.action, a.actor, a.created
+  FROM "grafana"."team_role_audit" as a
+ WHERE a.org_id = 1
+   AND a.role_uid = 'role-1'
+   AND a.id >= 3
+ ORDER BY a.id asc
+ LIMIT 1
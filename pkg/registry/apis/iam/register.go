@@ -33,6 +33,8 @@ type IdentityAccessManagementAPIBuilder struct {
 	store        legacy.LegacyIdentityStore
 	authorizer   authorizer.Authorizer
 	accessClient authz.AccessClient
+	// ac checks the permissions the legacy authorizer can not, not set with the admin only authorizer
+	ac accesscontrol.AccessControl
 
 	// Not set for multi-tenant deployment for now
 	sso ssosettings.Service
@@ -52,6 +54,7 @@ func RegisterAPIService(
 		sso:          ssoService,
 		authorizer:   authorizer,
 		accessClient: client,
+		ac:           ac,
 	}
 	apiregistration.RegisterAPI(builder)
 
@@ -97,10 +100,20 @@ func (b *IdentityAccessManagementAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *ge
 	teamResource := iamv0.TeamResourceInfo
 	storage[teamResource.StoragePath()] = team.NewLegacyStore(b.store, b.accessClient)
 	storage[teamResource.StoragePath("members")] = team.NewLegacyTeamMemberREST(b.store)
+	storage[teamResource.StoragePath("roles")] = team.NewLegacyTeamRoleSyncREST(b.store)
+	storage[teamResource.StoragePath("rolehistory")] = team.NewLegacyTeamRoleHistoryREST(b.store)
 
 	teamBindingResource := iamv0.TeamBindingResourceInfo
 	storage[teamBindingResource.StoragePath()] = team.NewLegacyBindingStore(b.store)
 
+	teamRoleResource := iamv0.TeamRoleResourceInfo
+	storage[teamRoleResource.StoragePath()] = team.NewLegacyTeamRoleStore(b.store, b.ac)
+
+	// The teams a role is assigned to, by role uid
+	storage["roleteams"] = team.NewLegacyRoleTeamREST(b.store, b.ac)
+	// The teams a role was assigned to and removed from, by role uid
+	storage["rolehistory"] = team.NewLegacyRoleHistoryREST(b.store, b.ac)
+
 	userResource := iamv0.UserResourceInfo
 	storage[userResource.StoragePath()] = user.NewLegacyStore(b.store, b.accessClient)
 	storage[userResource.StoragePath("teams")] = user.NewLegacyTeamMemberREST(b.store)
@@ -0,0 +1,162 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/authlib/claims"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/common"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+var (
+	_ rest.Storage         = (*LegacyTeamRoleHistoryREST)(nil)
+	_ rest.Scoper          = (*LegacyTeamRoleHistoryREST)(nil)
+	_ rest.StorageMetadata = (*LegacyTeamRoleHistoryREST)(nil)
+	_ rest.Connecter       = (*LegacyTeamRoleHistoryREST)(nil)
+)
+
+// NewLegacyTeamRoleHistoryREST lists the history of the roles of a team, the name is the uid of the team
+func NewLegacyTeamRoleHistoryREST(store legacy.LegacyIdentityStore) *LegacyTeamRoleHistoryREST {
+	return &LegacyTeamRoleHistoryREST{store: store}
+}
+
+// NewLegacyRoleHistoryREST lists the history of the teams of a role, the name is the uid of the role
+func NewLegacyRoleHistoryREST(store legacy.LegacyIdentityStore, ac accesscontrol.AccessControl) *LegacyTeamRoleHistoryREST {
+	return &LegacyTeamRoleHistoryREST{store: store, ac: ac, byRole: true}
+}
+
+// LegacyTeamRoleHistoryREST lists the roles assigned to and removed from a team, or the teams a role was
+// assigned to and removed from. The history is kept after the team or role is deleted.
+type LegacyTeamRoleHistoryREST struct {
+	store legacy.LegacyIdentityStore
+	// ac filters the history of a role to the teams whose roles the requester can read, nil when only grafana
+	// admins can use the API. The history of a team is authorized with the team.
+	ac     accesscontrol.AccessControl
+	byRole bool
+}
+
+// New implements rest.Storage.
+func (s *LegacyTeamRoleHistoryREST) New() runtime.Object {
+	return &iamv0.TeamRoleHistoryList{}
+}
+
+// Destroy implements rest.Storage.
+func (s *LegacyTeamRoleHistoryREST) Destroy() {}
+
+// NamespaceScoped implements rest.Scoper.
+func (s *LegacyTeamRoleHistoryREST) NamespaceScoped() bool {
+	return true
+}
+
+// ProducesMIMETypes implements rest.StorageMetadata.
+func (s *LegacyTeamRoleHistoryREST) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+// ProducesObject implements rest.StorageMetadata.
+func (s *LegacyTeamRoleHistoryREST) ProducesObject(verb string) interface{} {
+	return s.New()
+}
+
+// Connect implements rest.Connecter.
+func (s *LegacyTeamRoleHistoryREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	canRead := func(legacy.TeamRoleAuditEntry) (bool, error) { return true, nil }
+	if s.byRole && s.ac != nil {
+		requester, err := identity.GetRequester(ctx)
+		if err != nil {
+			return nil, err
+		}
+		canRead = s.teamRolesReader(ctx, ns, requester)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := legacy.ListTeamRoleHistoryQuery{
+			Pagination: common.PaginationFromListQuery(r.URL.Query()),
+		}
+		if s.byRole {
+			query.RoleUID = name
+		} else {
+			query.TeamUID = name
+		}
+
+		res, err := s.store.ListTeamRoleHistory(ctx, ns, query)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		list := &iamv0.TeamRoleHistoryList{Items: make([]iamv0.TeamRoleHistoryEntry, 0, len(res.Items))}
+
+		for _, e := range res.Items {
+			ok, err := canRead(e)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			if ok {
+				list.Items = append(list.Items, mapToTeamRoleHistoryEntry(e))
+			}
+		}
+
+		list.ListMeta.Continue = common.OptionalFormatInt(res.Continue)
+
+		responder.Object(http.StatusOK, list)
+	}), nil
+}
+
+// teamRolesReader returns whether the requester can read the roles of the team of an entry. The entries of deleted
+// teams can only be read with the action on all teams.
+func (s *LegacyTeamRoleHistoryREST) teamRolesReader(ctx context.Context, ns claims.NamespaceInfo, requester identity.Requester) func(legacy.TeamRoleAuditEntry) (bool, error) {
+	check := accesscontrol.Checker(requester, accesscontrol.ActionTeamsRolesRead)
+	teams := map[string]bool{}
+	return func(e legacy.TeamRoleAuditEntry) (bool, error) {
+		if ok, found := teams[e.TeamUID]; found {
+			return ok, nil
+		}
+		scope := accesscontrol.ScopeTeamsAll
+		team, err := s.store.GetTeamInternalID(ctx, ns, legacy.GetTeamInternalIDQuery{UID: e.TeamUID})
+		if err == nil {
+			scope = fmt.Sprintf("teams:id:%d", team.ID)
+		} else if !errors.Is(err, legacy.ErrTeamNotFound) {
+			return false, err
+		}
+		teams[e.TeamUID] = check(scope)
+		return teams[e.TeamUID], nil
+	}
+}
+
+// NewConnectOptions implements rest.Connecter.
+func (s *LegacyTeamRoleHistoryREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// ConnectMethods implements rest.Connecter.
+func (s *LegacyTeamRoleHistoryREST) ConnectMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func mapToTeamRoleHistoryEntry(e legacy.TeamRoleAuditEntry) iamv0.TeamRoleHistoryEntry {
+	return iamv0.TeamRoleHistoryEntry{
+		Action:    e.Action,
+		Team:      iamv0.TeamRef{Name: e.TeamUID},
+		Role:      iamv0.RoleRef{Name: e.RoleUID},
+		Actor:     e.Actor,
+		Timestamp: metav1.NewTime(e.Created),
+	}
+}
@@ -0,0 +1,75 @@
+package team
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// fakeRoleHistoryStore assigned role-1 to team-1 with id 1, team-2 with id 2 and team-3 that is deleted
+type fakeRoleHistoryStore struct {
+	legacy.LegacyIdentityStore
+}
+
+func (f *fakeRoleHistoryStore) GetTeamInternalID(_ context.Context, _ claims.NamespaceInfo, query legacy.GetTeamInternalIDQuery) (*legacy.GetTeamInternalIDResult, error) {
+	switch query.UID {
+	case "team-1":
+		return &legacy.GetTeamInternalIDResult{ID: 1}, nil
+	case "team-2":
+		return &legacy.GetTeamInternalIDResult{ID: 2}, nil
+	}
+	return nil, legacy.ErrTeamNotFound
+}
+
+func (f *fakeRoleHistoryStore) ListTeamRoleHistory(_ context.Context, _ claims.NamespaceInfo, query legacy.ListTeamRoleHistoryQuery) (*legacy.ListTeamRoleHistoryResult, error) {
+	return &legacy.ListTeamRoleHistoryResult{Items: []legacy.TeamRoleAuditEntry{
+		{TeamUID: "team-1", RoleUID: query.RoleUID, Action: "add"},
+		{TeamUID: "team-2", RoleUID: query.RoleUID, Action: "add"},
+		{TeamUID: "team-3", RoleUID: query.RoleUID, Action: "add"},
+		{TeamUID: "team-2", RoleUID: query.RoleUID, Action: "remove"},
+	}}, nil
+}
+
+func TestLegacyRoleHistoryREST(t *testing.T) {
+	ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	history := func(t *testing.T, scopes ...string) []string {
+		user := &identity.StaticRequester{OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+			accesscontrol.ActionRolesRead:      {accesscontrol.ScopeRolesAll},
+			accesscontrol.ActionTeamsRolesRead: scopes,
+		}}}
+		ctx := k8srequest.WithNamespace(identity.WithRequester(context.Background(), user), "default")
+
+		responder := &objectResponder{}
+		handler, err := NewLegacyRoleHistoryREST(&fakeRoleHistoryStore{}, ac).Connect(ctx, "role-1", nil, responder)
+		require.NoError(t, err)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NoError(t, responder.err)
+
+		entries := []string{}
+		for _, e := range responder.obj.(*iamv0.TeamRoleHistoryList).Items {
+			entries = append(entries, e.Team.Name+":"+e.Action)
+		}
+		return entries
+	}
+
+	t.Run("lists the history of the teams whose roles the requester can read", func(t *testing.T) {
+		require.Equal(t, []string{"team-2:add", "team-2:remove"}, history(t, "teams:id:2"))
+	})
+
+	t.Run("lists the history of the deleted teams with the action on all teams", func(t *testing.T) {
+		require.Equal(t, []string{"team-1:add", "team-2:add", "team-3:add", "team-2:remove"}, history(t, accesscontrol.ScopeTeamsAll))
+	})
+}
@@ -0,0 +1,123 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/common"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+var (
+	_ rest.Storage         = (*LegacyRoleTeamREST)(nil)
+	_ rest.Scoper          = (*LegacyRoleTeamREST)(nil)
+	_ rest.StorageMetadata = (*LegacyRoleTeamREST)(nil)
+	_ rest.Connecter       = (*LegacyRoleTeamREST)(nil)
+)
+
+func NewLegacyRoleTeamREST(store legacy.LegacyIdentityStore, ac accesscontrol.AccessControl) *LegacyRoleTeamREST {
+	return &LegacyRoleTeamREST{store, ac}
+}
+
+// LegacyRoleTeamREST lists the teams a role is assigned to, the name is the uid of the role
+type LegacyRoleTeamREST struct {
+	store legacy.LegacyIdentityStore
+	// ac filters the teams whose roles the requester can not read, nil when only grafana admins can use the API
+	ac accesscontrol.AccessControl
+}
+
+// New implements rest.Storage.
+func (s *LegacyRoleTeamREST) New() runtime.Object {
+	return &iamv0.RoleTeamList{}
+}
+
+// Destroy implements rest.Storage.
+func (s *LegacyRoleTeamREST) Destroy() {}
+
+// NamespaceScoped implements rest.Scoper.
+func (s *LegacyRoleTeamREST) NamespaceScoped() bool {
+	return true
+}
+
+// ProducesMIMETypes implements rest.StorageMetadata.
+func (s *LegacyRoleTeamREST) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+// ProducesObject implements rest.StorageMetadata.
+func (s *LegacyRoleTeamREST) ProducesObject(verb string) interface{} {
+	return s.New()
+}
+
+// Connect implements rest.Connecter.
+func (s *LegacyRoleTeamREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	canRead := func(legacy.RoleTeam) bool { return true }
+	if s.ac != nil {
+		requester, err := identity.GetRequester(ctx)
+		if err != nil {
+			return nil, err
+		}
+		check := accesscontrol.Checker(requester, accesscontrol.ActionTeamsRolesRead)
+		canRead = func(t legacy.RoleTeam) bool { return check(fmt.Sprintf("teams:id:%d", t.ID)) }
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := s.store.ListRoleTeams(ctx, ns, legacy.ListRoleTeamsQuery{
+			RoleUID:    name,
+			Pagination: common.PaginationFromListQuery(r.URL.Query()),
+		})
+		if err != nil {
+			if errors.Is(err, legacy.ErrRoleNotFound) {
+				responder.Error(roleResource.NewNotFound(name))
+				return
+			}
+			responder.Error(err)
+			return
+		}
+
+		list := &iamv0.RoleTeamList{Items: make([]iamv0.RoleTeam, 0, len(res.Items))}
+
+		for _, t := range res.Items {
+			if canRead(t) {
+				list.Items = append(list.Items, mapToRoleTeam(t))
+			}
+		}
+
+		list.ListMeta.Continue = common.OptionalFormatInt(res.Continue)
+
+		responder.Object(http.StatusOK, list)
+	}), nil
+}
+
+// NewConnectOptions implements rest.Connecter.
+func (s *LegacyRoleTeamREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// ConnectMethods implements rest.Connecter.
+func (s *LegacyRoleTeamREST) ConnectMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func mapToRoleTeam(t legacy.RoleTeam) iamv0.RoleTeam {
+	return iamv0.RoleTeam{
+		Title: t.Name,
+		TeamRef: iamv0.TeamRef{
+			Name: t.UID,
+		},
+	}
+}
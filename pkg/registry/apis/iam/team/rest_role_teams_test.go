@@ -0,0 +1,64 @@
+package team
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// fakeRoleTeamStore assigns role-1 to team-1 with id 1 and team-2 with id 2
+type fakeRoleTeamStore struct {
+	legacy.LegacyIdentityStore
+}
+
+func (f *fakeRoleTeamStore) ListRoleTeams(_ context.Context, _ claims.NamespaceInfo, query legacy.ListRoleTeamsQuery) (*legacy.ListRoleTeamsResult, error) {
+	return &legacy.ListRoleTeamsResult{Items: []legacy.RoleTeam{
+		{ID: 1, UID: "team-1", Name: "Team 1"},
+		{ID: 2, UID: "team-2", Name: "Team 2"},
+	}}, nil
+}
+
+// objectResponder records the object of a connect handler
+type objectResponder struct {
+	obj runtime.Object
+	err error
+}
+
+func (r *objectResponder) Object(statusCode int, obj runtime.Object) {
+	r.obj = obj
+}
+
+func (r *objectResponder) Error(err error) {
+	r.err = err
+}
+
+func TestLegacyRoleTeamREST(t *testing.T) {
+	ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	user := &identity.StaticRequester{OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		accesscontrol.ActionRolesRead:      {accesscontrol.ScopeRolesAll},
+		accesscontrol.ActionTeamsRolesRead: {"teams:id:2"},
+	}}}
+	ctx := k8srequest.WithNamespace(identity.WithRequester(context.Background(), user), "default")
+
+	responder := &objectResponder{}
+	handler, err := NewLegacyRoleTeamREST(&fakeRoleTeamStore{}, ac).Connect(ctx, "role-1", nil, responder)
+	require.NoError(t, err)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, responder.err)
+
+	require.Equal(t, []iamv0.RoleTeam{{Title: "Team 2", TeamRef: iamv0.TeamRef{Name: "team-2"}}}, responder.obj.(*iamv0.RoleTeamList).Items)
+}
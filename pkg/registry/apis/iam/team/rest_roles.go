@@ -0,0 +1,124 @@
+package team
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+var (
+	_ rest.Storage         = (*LegacyTeamRoleSyncREST)(nil)
+	_ rest.Scoper          = (*LegacyTeamRoleSyncREST)(nil)
+	_ rest.StorageMetadata = (*LegacyTeamRoleSyncREST)(nil)
+	_ rest.Connecter       = (*LegacyTeamRoleSyncREST)(nil)
+)
+
+func NewLegacyTeamRoleSyncREST(store legacy.LegacyIdentityStore) *LegacyTeamRoleSyncREST {
+	return &LegacyTeamRoleSyncREST{store}
+}
+
+// LegacyTeamRoleSyncREST replaces all roles of a team with the desired set in a single request,
+// this is used by jobs that sync teams from an external identity provider.
+type LegacyTeamRoleSyncREST struct {
+	store legacy.LegacyIdentityStore
+}
+
+// New implements rest.Storage.
+func (s *LegacyTeamRoleSyncREST) New() runtime.Object {
+	return &iamv0.TeamRoleSyncResult{}
+}
+
+// Destroy implements rest.Storage.
+func (s *LegacyTeamRoleSyncREST) Destroy() {}
+
+// NamespaceScoped implements rest.Scoper.
+func (s *LegacyTeamRoleSyncREST) NamespaceScoped() bool {
+	return true
+}
+
+// ProducesMIMETypes implements rest.StorageMetadata.
+func (s *LegacyTeamRoleSyncREST) ProducesMIMETypes(verb string) []string {
+	return []string{"application/json"}
+}
+
+// ProducesObject implements rest.StorageMetadata.
+func (s *LegacyTeamRoleSyncREST) ProducesObject(verb string) interface{} {
+	return s.New()
+}
+
+// Connect implements rest.Connecter.
+func (s *LegacyTeamRoleSyncREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// the team authorizer does not know about subresources, team writers must not be able to grant roles
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.GetIsGrafanaAdmin() {
+		return nil, apierrors.NewForbidden(resource.GroupResource(), name, errors.New("only grafana admins can sync team roles for now"))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := iamv0.TeamRoleSyncRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			responder.Error(apierrors.NewBadRequest(fmt.Sprintf("invalid team role sync request: %s", err)))
+			return
+		}
+		if len(body.Roles) > legacy.MaxTeamRoleSync {
+			responder.Error(apierrors.NewBadRequest(fmt.Sprintf("a team can be synced to at most %d roles", legacy.MaxTeamRoleSync)))
+			return
+		}
+
+		for _, uid := range body.Roles {
+			if _, err := validateAssignableRole(ctx, s.store, ns, uid); err != nil {
+				responder.Error(err)
+				return
+			}
+		}
+
+		res, err := s.store.SyncTeamRoles(ctx, ns, legacy.SyncTeamRolesCommand{
+			TeamUID:  name,
+			RoleUIDs: body.Roles,
+			Actor:    user.GetUID(),
+		})
+		if err != nil {
+			if errors.Is(err, legacy.ErrTeamNotFound) {
+				responder.Error(resource.NewNotFound(name))
+				return
+			}
+			responder.Error(err)
+			return
+		}
+
+		responder.Object(http.StatusOK, &iamv0.TeamRoleSyncResult{
+			Added:     res.Added,
+			Removed:   res.Removed,
+			Unchanged: res.Unchanged,
+		})
+	}), nil
+}
+
+// NewConnectOptions implements rest.Connecter.
+func (s *LegacyTeamRoleSyncREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// ConnectMethods implements rest.Connecter.
+func (s *LegacyTeamRoleSyncREST) ConnectMethods() []string {
+	return []string{http.MethodPut}
+}
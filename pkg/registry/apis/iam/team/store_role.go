@@ -0,0 +1,361 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/common"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+var roleResource = iamv0.TeamRoleResourceInfo
+
+var (
+	_ rest.Storage              = (*LegacyTeamRoleStore)(nil)
+	_ rest.Scoper               = (*LegacyTeamRoleStore)(nil)
+	_ rest.SingularNameProvider = (*LegacyTeamRoleStore)(nil)
+	_ rest.Getter               = (*LegacyTeamRoleStore)(nil)
+	_ rest.Lister               = (*LegacyTeamRoleStore)(nil)
+	_ rest.Creater              = (*LegacyTeamRoleStore)(nil)
+	_ rest.GracefulDeleter      = (*LegacyTeamRoleStore)(nil)
+)
+
+// Field selectors supported when listing team roles
+const (
+	teamRoleTeamField = "spec.team.name"
+	teamRoleRoleField = "spec.role.name"
+)
+
+func NewLegacyTeamRoleStore(store legacy.LegacyIdentityStore, ac accesscontrol.AccessControl) *LegacyTeamRoleStore {
+	return &LegacyTeamRoleStore{store, ac}
+}
+
+// LegacyTeamRoleStore assigns roles to teams using the team_role table.
+// A binding can not be updated, it is deleted and created again with a different role.
+type LegacyTeamRoleStore struct {
+	store legacy.LegacyIdentityStore
+	// ac checks the permissions of the requester on the bindings they create, nil when only grafana admins
+	// can use the API
+	ac accesscontrol.AccessControl
+}
+
+// Destroy implements rest.Storage.
+func (l *LegacyTeamRoleStore) Destroy() {}
+
+// New implements rest.Storage.
+func (l *LegacyTeamRoleStore) New() runtime.Object {
+	return roleResource.NewFunc()
+}
+
+// NewList implements rest.Lister.
+func (l *LegacyTeamRoleStore) NewList() runtime.Object {
+	return roleResource.NewListFunc()
+}
+
+// NamespaceScoped implements rest.Scoper.
+func (l *LegacyTeamRoleStore) NamespaceScoped() bool {
+	return true
+}
+
+// GetSingularName implements rest.SingularNameProvider.
+func (l *LegacyTeamRoleStore) GetSingularName() string {
+	return roleResource.GetSingularName()
+}
+
+// ConvertToTable implements rest.Lister.
+func (l *LegacyTeamRoleStore) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return roleResource.TableConverter().ConvertToTable(ctx, object, tableOptions)
+}
+
+// Get implements rest.Getter.
+func (l *LegacyTeamRoleStore) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	teamUID, roleUID, ok := strings.Cut(name, iamv0.TeamRoleNameSeparator)
+	if !ok {
+		return nil, roleResource.NewNotFound(name)
+	}
+
+	res, err := l.store.ListTeamRoles(ctx, ns, legacy.ListTeamRolesQuery{
+		TeamUID:    teamUID,
+		RoleUID:    roleUID,
+		Pagination: common.Pagination{Limit: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.TeamRoles) != 1 {
+		return nil, roleResource.NewNotFound(name)
+	}
+
+	obj := mapToTeamRoleObject(ns, res.TeamRoles[0])
+	return &obj, nil
+}
+
+// List implements rest.Lister. The list can be filtered by team or role with the
+// spec.team.name and spec.role.name field selectors.
+func (l *LegacyTeamRoleStore) List(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	query := legacy.ListTeamRolesQuery{
+		Pagination: common.PaginationFromListOptions(options),
+	}
+	if options != nil && options.FieldSelector != nil && !options.FieldSelector.Empty() {
+		for _, r := range options.FieldSelector.Requirements() {
+			value, ok := options.FieldSelector.RequiresExactMatch(r.Field)
+			if !ok {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("unsupported field selector %q", r.Field))
+			}
+			switch r.Field {
+			case teamRoleTeamField:
+				query.TeamUID = value
+			case teamRoleRoleField:
+				query.RoleUID = value
+			default:
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("unsupported field selector %q, expected %s or %s", r.Field, teamRoleTeamField, teamRoleRoleField))
+			}
+		}
+	}
+
+	res, err := l.store.ListTeamRoles(ctx, ns, query)
+	if err != nil {
+		return nil, err
+	}
+
+	list := iamv0.TeamRoleList{
+		Items: make([]iamv0.TeamRole, 0, len(res.TeamRoles)),
+	}
+
+	for _, tr := range res.TeamRoles {
+		list.Items = append(list.Items, mapToTeamRoleObject(ns, tr))
+	}
+
+	list.ListMeta.Continue = common.OptionalFormatInt(res.Continue)
+	list.ListMeta.ResourceVersion = common.OptionalFormatInt(res.RV)
+
+	return &list, nil
+}
+
+// Create implements rest.Creater.
+func (l *LegacyTeamRoleStore) Create(
+	ctx context.Context,
+	obj runtime.Object,
+	createValidation rest.ValidateObjectFunc,
+	options *metav1.CreateOptions,
+) (runtime.Object, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tr, ok := obj.(*iamv0.TeamRole)
+	if !ok {
+		return nil, errors.New("expected team role")
+	}
+
+	if err := l.validate(ctx, ns, tr); err != nil {
+		return nil, err
+	}
+
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := l.store.CreateTeamRole(ctx, ns, legacy.CreateTeamRoleCommand{
+		TeamUID: tr.Spec.Team.Name,
+		RoleUID: tr.Spec.Role.Name,
+		Actor:   actorUID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, legacy.ErrTeamRoleExists) {
+			return nil, apierrors.NewAlreadyExists(roleResource.GroupResource(), tr.Name)
+		}
+		return nil, err
+	}
+
+	out := mapToTeamRoleObject(ns, *created)
+	return &out, nil
+}
+
+// validate checks that the referenced team and role exist, and that the requester can assign the role to the team.
+func (l *LegacyTeamRoleStore) validate(ctx context.Context, ns claims.NamespaceInfo, tr *iamv0.TeamRole) error {
+	teamUID, roleUID := tr.Spec.Team.Name, tr.Spec.Role.Name
+	if teamUID == "" || roleUID == "" {
+		return apierrors.NewBadRequest("spec.team.name and spec.role.name are required")
+	}
+	if strings.Contains(teamUID, iamv0.TeamRoleNameSeparator) || strings.Contains(roleUID, iamv0.TeamRoleNameSeparator) {
+		return apierrors.NewBadRequest(fmt.Sprintf("team and role uids can not contain %q", iamv0.TeamRoleNameSeparator))
+	}
+
+	name := iamv0.TeamRoleName(teamUID, roleUID)
+	if tr.Name == "" {
+		tr.Name = name
+	} else if tr.Name != name {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected name %q for the team role", name))
+	}
+
+	team, err := l.store.GetTeamInternalID(ctx, ns, legacy.GetTeamInternalIDQuery{UID: teamUID})
+	if err != nil {
+		if errors.Is(err, legacy.ErrTeamNotFound) {
+			return apierrors.NewBadRequest(fmt.Sprintf("team %q not found", teamUID))
+		}
+		return err
+	}
+
+	role, err := validateAssignableRole(ctx, l.store, ns, roleUID)
+	if err != nil {
+		return err
+	}
+	return l.authorize(ctx, ns, tr, team.ID, role.ID)
+}
+
+// authorize checks that the requester can add roles to the team, and has all the permissions of the role, so a
+// binding can not give a team more permissions than the requester has.
+func (l *LegacyTeamRoleStore) authorize(ctx context.Context, ns claims.NamespaceInfo, tr *iamv0.TeamRole, teamID, roleID int64) error {
+	if l.ac == nil {
+		return nil
+	}
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return err
+	}
+
+	ok, err := l.ac.Evaluate(ctx, requester, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesAdd, fmt.Sprintf("teams:id:%d", teamID)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierrors.NewForbidden(roleResource.GroupResource(), tr.Name, fmt.Errorf("missing %s on team %q", accesscontrol.ActionTeamsRolesAdd, tr.Spec.Team.Name))
+	}
+
+	res, err := l.store.GetRolePermissions(ctx, ns, legacy.GetRolePermissionsQuery{RoleID: roleID})
+	if err != nil {
+		return err
+	}
+	evaluators := make([]accesscontrol.Evaluator, 0, len(res.Permissions))
+	for _, p := range res.Permissions {
+		if p.Scope == "" {
+			evaluators = append(evaluators, accesscontrol.EvalPermission(p.Action))
+			continue
+		}
+		evaluators = append(evaluators, accesscontrol.EvalPermission(p.Action, p.Scope))
+	}
+	ok, err = l.ac.Evaluate(ctx, requester, accesscontrol.EvalAll(evaluators...))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierrors.NewForbidden(roleResource.GroupResource(), tr.Name, fmt.Errorf("role %q has permissions the requester does not have", tr.Spec.Role.Name))
+	}
+	return nil
+}
+
+// validateAssignableRole checks that the role exists and can be assigned to a team, and returns it
+func validateAssignableRole(ctx context.Context, store legacy.LegacyIdentityStore, ns claims.NamespaceInfo, roleUID string) (*legacy.GetRoleInternalIDResult, error) {
+	role, err := store.GetRoleInternalID(ctx, ns, legacy.GetRoleInternalIDQuery{UID: roleUID})
+	if err != nil {
+		if errors.Is(err, legacy.ErrRoleNotFound) {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("role %q not found", roleUID))
+		}
+		return nil, err
+	}
+
+	// managed and basic roles are assigned by grafana and can not be bound through the api
+	if strings.HasPrefix(role.Name, accesscontrol.ManagedRolePrefix) || strings.HasPrefix(role.Name, accesscontrol.BasicRolePrefix) {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("role %q can not be assigned to a team", roleUID))
+	}
+	return role, nil
+}
+
+// Delete implements rest.GracefulDeleter.
+func (l *LegacyTeamRoleStore) Delete(
+	ctx context.Context,
+	name string,
+	deleteValidation rest.ValidateObjectFunc,
+	options *metav1.DeleteOptions,
+) (runtime.Object, bool, error) {
+	ns, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	old, err := l.Get(ctx, name, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if deleteValidation != nil {
+		if err := deleteValidation(ctx, old); err != nil {
+			return nil, false, err
+		}
+	}
+
+	tr := old.(*iamv0.TeamRole)
+	err = l.store.DeleteTeamRole(ctx, ns, legacy.DeleteTeamRoleCommand{
+		TeamUID: tr.Spec.Team.Name,
+		RoleUID: tr.Spec.Role.Name,
+		Actor:   actorUID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, legacy.ErrTeamRoleNotFound) {
+			return nil, false, roleResource.NewNotFound(name)
+		}
+		return nil, false, err
+	}
+
+	return old, true, nil
+}
+
+// actorUID is the uid of the identity making a change, e.g. user:u000000001, recorded in the team role audit
+func actorUID(ctx context.Context) string {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return ""
+	}
+	return requester.GetUID()
+}
+
+func mapToTeamRoleObject(ns claims.NamespaceInfo, tr legacy.TeamRole) iamv0.TeamRole {
+	name := iamv0.TeamRoleName(tr.TeamUID, tr.RoleUID)
+	return iamv0.TeamRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         ns.Value,
+			UID:               types.UID(strconv.FormatInt(tr.ID, 10)),
+			ResourceVersion:   strconv.FormatInt(tr.Created.UnixMilli(), 10),
+			CreationTimestamp: metav1.NewTime(tr.Created),
+		},
+		Spec: iamv0.TeamRoleSpec{
+			Team: iamv0.TeamRef{
+				Name: tr.TeamUID,
+			},
+			Role: iamv0.RoleRef{
+				Name: tr.RoleUID,
+			},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package team
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	iamv0 "github.com/grafana/grafana/pkg/apis/iam/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/iam/legacy"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// fakeTeamRoleStore has a team, team-1 with id 1, and a role, role-1 with id 2 that can write the dashboards
+type fakeTeamRoleStore struct {
+	legacy.LegacyIdentityStore
+	created []legacy.CreateTeamRoleCommand
+}
+
+func (f *fakeTeamRoleStore) GetTeamInternalID(_ context.Context, _ claims.NamespaceInfo, query legacy.GetTeamInternalIDQuery) (*legacy.GetTeamInternalIDResult, error) {
+	if query.UID != "team-1" {
+		return nil, legacy.ErrTeamNotFound
+	}
+	return &legacy.GetTeamInternalIDResult{ID: 1}, nil
+}
+
+func (f *fakeTeamRoleStore) GetRoleInternalID(_ context.Context, _ claims.NamespaceInfo, query legacy.GetRoleInternalIDQuery) (*legacy.GetRoleInternalIDResult, error) {
+	if query.UID != "role-1" {
+		return nil, legacy.ErrRoleNotFound
+	}
+	return &legacy.GetRoleInternalIDResult{ID: 2, Name: "custom:dashboard.writer"}, nil
+}
+
+func (f *fakeTeamRoleStore) GetRolePermissions(_ context.Context, _ claims.NamespaceInfo, query legacy.GetRolePermissionsQuery) (*legacy.GetRolePermissionsResult, error) {
+	return &legacy.GetRolePermissionsResult{Permissions: []accesscontrol.Permission{
+		{Action: "dashboards:read", Scope: "dashboards:*"},
+		{Action: "dashboards:write", Scope: "dashboards:*"},
+	}}, nil
+}
+
+func (f *fakeTeamRoleStore) CreateTeamRole(_ context.Context, _ claims.NamespaceInfo, cmd legacy.CreateTeamRoleCommand) (*legacy.TeamRole, error) {
+	f.created = append(f.created, cmd)
+	return &legacy.TeamRole{ID: 1, RoleID: 2, TeamUID: cmd.TeamUID, RoleUID: cmd.RoleUID, Created: time.Now()}, nil
+}
+
+func TestLegacyTeamRoleStoreCreate(t *testing.T) {
+	ac := acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	create := func(t *testing.T, permissions map[string][]string) (*fakeTeamRoleStore, error) {
+		store := &fakeTeamRoleStore{}
+		user := &identity.StaticRequester{OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}}
+		ctx := k8srequest.WithNamespace(identity.WithRequester(context.Background(), user), "default")
+		_, err := NewLegacyTeamRoleStore(store, ac).Create(ctx, &iamv0.TeamRole{
+			Spec: iamv0.TeamRoleSpec{Team: iamv0.TeamRef{Name: "team-1"}, Role: iamv0.RoleRef{Name: "role-1"}},
+		}, nil, nil)
+		return store, err
+	}
+
+	t.Run("assigns a role with the permissions of the requester", func(t *testing.T) {
+		store, err := create(t, map[string][]string{
+			accesscontrol.ActionTeamsRolesAdd: {"teams:id:1"},
+			"dashboards:read":                 {"dashboards:*"},
+			"dashboards:write":                {"dashboards:*"},
+		})
+		require.NoError(t, err)
+		require.Len(t, store.created, 1)
+	})
+
+	t.Run("rejects a role with permissions the requester does not have", func(t *testing.T) {
+		store, err := create(t, map[string][]string{
+			accesscontrol.ActionTeamsRolesAdd: {"teams:id:1"},
+			"dashboards:read":                 {"dashboards:*"},
+			"dashboards:write":                {"dashboards:uid:a"},
+		})
+		require.True(t, apierrors.IsForbidden(err), err)
+		require.ErrorContains(t, err, `role "role-1" has permissions the requester does not have`)
+		require.Empty(t, store.created)
+	})
+
+	t.Run("rejects a requester that can not add roles to the team", func(t *testing.T) {
+		store, err := create(t, map[string][]string{
+			accesscontrol.ActionTeamsRolesAdd: {"teams:id:2"},
+			"dashboards:read":                 {"dashboards:*"},
+			"dashboards:write":                {"dashboards:*"},
+		})
+		require.True(t, apierrors.IsForbidden(err), err)
+		require.Empty(t, store.created)
+	})
+}
@@ -2,6 +2,7 @@ package search
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -13,14 +14,20 @@ import (
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	common "k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/authlib/claims"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	request2 "github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/util/errhttp"
 )
 
 var _ builder.APIGroupBuilder = (*SearchAPIBuilder)(nil)
@@ -70,6 +77,57 @@ func (b *SearchAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions
 
 func (b *SearchAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 	return &builder.APIRoutes{
+		Root: []builder.APIRouteHandler{
+			{
+				Path: "search/readiness",
+				Spec: &spec3.PathProps{
+					Get: &spec3.Operation{
+						OperationProps: spec3.OperationProps{
+							Tags:        []string{"Search"},
+							Summary:     "Search index readiness",
+							Description: "Reports the progress of the search index warm-up for each namespace. Responds with 503 until the index is ready.",
+						},
+					},
+				},
+				Handler: b.handleReadiness,
+			},
+			{
+				Path: "search/reindex",
+				Spec: &spec3.PathProps{
+					Post: &spec3.Operation{
+						OperationProps: spec3.OperationProps{
+							Tags:        []string{"Search"},
+							Summary:     "Rebuild the search index",
+							Description: "Rebuilds the search index in the background. The current index is searched until the new one is built. Only Grafana admins can trigger a reindex.",
+							Parameters: []*spec3.Parameter{
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "namespace",
+										In:          "query",
+										Description: "Only rebuild the index of this namespace",
+										Schema:      spec.StringProperty(),
+									},
+								},
+							},
+						},
+					},
+				},
+				Handler: b.handleReindex,
+			},
+			{
+				Path: "search/stats",
+				Spec: &spec3.PathProps{
+					Get: &spec3.Operation{
+						OperationProps: spec3.OperationProps{
+							Tags:        []string{"Search"},
+							Summary:     "Search index statistics",
+							Description: "Reports the document count, size, last build time and pending updates of the search index. Only Grafana admins can read the statistics.",
+						},
+					},
+				},
+				Handler: b.handleStats,
+			},
+		},
 		Namespace: []builder.APIRouteHandler{
 			{
 				Path: "search",
@@ -148,3 +206,98 @@ func (b *SearchAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.API
 	apiGroupInfo.PrioritizedVersions = []schema.GroupVersion{b.GetGroupVersion()}
 	return nil
 }
+
+// handleReadiness reports the warm-up of the search index, so load balancers can wait for it before sending traffic
+func (b *SearchAPIBuilder) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	var readiness *resource.IndexReadiness
+	if reporter, ok := b.unified.(resource.IndexReadinessReporter); ok {
+		readiness = reporter.IndexReadiness()
+	}
+	if readiness == nil {
+		// the index is not in this process, its readiness is reported by the storage server
+		readiness = &resource.IndexReadiness{Ready: true, State: resource.IndexStateDisabled, Namespaces: []resource.NamespaceIndexReadiness{}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(readiness)
+}
+
+// handleReindex starts rebuilding the whole index, or the index of the namespace query parameter
+func (b *SearchAPIBuilder) handleReindex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	admin, err := b.indexAdmin(r)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	namespace := r.URL.Query().Get("namespace")
+	if namespace != "" {
+		if _, err := claims.ParseNamespace(namespace); err != nil {
+			errhttp.Write(ctx, errInvalidReindex.Errorf("invalid namespace: %w", err), w)
+			return
+		}
+	}
+
+	if err := admin.Reindex(ctx, namespace); err != nil {
+		errhttp.Write(ctx, indexAdminError(err), w)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (b *SearchAPIBuilder) handleStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	admin, err := b.indexAdmin(r)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	stats, err := admin.IndexStats(ctx)
+	if err != nil {
+		errhttp.Write(ctx, indexAdminError(err), w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+// indexAdmin returns the index of this process when the user is a Grafana admin
+func (b *SearchAPIBuilder) indexAdmin(r *http.Request) (resource.IndexAdmin, error) {
+	user, err := identity.GetRequester(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	if !user.GetIsGrafanaAdmin() {
+		return nil, errIndexAdminForbidden.Errorf("user is not a Grafana admin")
+	}
+	admin, ok := b.unified.(resource.IndexAdmin)
+	if !ok {
+		return nil, indexAdminError(resource.ErrIndexAdminUnsupported)
+	}
+	return admin, nil
+}
+
+var (
+	errInvalidReindex      = errutil.BadRequest("search.reindex.invalid")
+	errIndexAdminForbidden = errutil.Forbidden("search.admin.forbidden", errutil.WithPublicMessage("Only Grafana admins can manage the search index"))
+	errReindexInProgress   = errutil.Conflict("search.reindex.running", errutil.WithPublicMessage("A reindex is already running"))
+	errIndexNotReady       = errutil.Conflict("search.index.notReady", errutil.WithPublicMessage("The search index is still being built"))
+	errIndexAdminNotLocal  = errutil.NotImplemented("search.admin.unsupported", errutil.WithPublicMessage("The search index is not managed by this server"))
+)
+
+func indexAdminError(err error) error {
+	switch {
+	case errors.Is(err, resource.ErrReindexInProgress):
+		return errReindexInProgress.Errorf("%w", err)
+	case errors.Is(err, resource.ErrIndexNotReady):
+		return errIndexNotReady.Errorf("%w", err)
+	case errors.Is(err, resource.ErrIndexAdminUnsupported):
+		return errIndexAdminNotLocal.Errorf("%w", err)
+	}
+	return err
+}
@@ -32,6 +32,13 @@ var WireSet = wire.NewSet(
 
 	// Each must be added here *and* in the ServiceSink above
 	dashboardinternal.RegisterAPIService,
+	dashboardinternal.ProvideSnapshotGarbageCollector,
+	dashboardinternal.ProvideLibraryPanelGarbageCollector,
+	dashboardinternal.ProvideNamespaceRateLimiter,
+	dashboardinternal.ProvideDashboardAuditor,
+	dashboardinternal.ProvideTombstoneStore,
+	dashboardinternal.ProvideDashboardViews,
+	dashboardinternal.ProvideDashboardWebhooks,
 	dashboardv0alpha1.RegisterAPIService,
 	dashboardv1alpha1.RegisterAPIService,
 	dashboardv2alpha1.RegisterAPIService,
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/usagestats/statscollector"
 	"github.com/grafana/grafana/pkg/registry"
 	apiregistry "github.com/grafana/grafana/pkg/registry/apis"
+	dashboardinternal "github.com/grafana/grafana/pkg/registry/apis/dashboard"
 	appregistry "github.com/grafana/grafana/pkg/registry/apps"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl"
@@ -19,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/cloudmigration"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardversion/dashverimpl"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	ldapapi "github.com/grafana/grafana/pkg/services/ldap/api"
@@ -69,6 +71,11 @@ func ProvideBackgroundServiceRegistry(
 	pluginInstaller *plugininstaller.Service,
 	accessControl accesscontrol.Service,
 	appRegistry *appregistry.Service,
+	snapshotGC *dashboardinternal.SnapshotGarbageCollector,
+	libraryPanelGC *dashboardinternal.LibraryPanelGarbageCollector,
+	dashboardViews *dashboardinternal.DashboardViews,
+	dashboardWebhooks *dashboardinternal.DashboardWebhooks,
+	dashboardVersionsRetention *dashverimpl.RetentionCleaner,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ dashboardsnapshots.Service,
 	_ serviceaccounts.Service, _ *guardian.Provider,
@@ -114,6 +121,11 @@ func ProvideBackgroundServiceRegistry(
 		pluginInstaller,
 		accessControl,
 		appRegistry,
+		snapshotGC,
+		libraryPanelGC,
+		dashboardViews,
+		dashboardWebhooks,
+		dashboardVersionsRetention,
 	)
 }
 
@@ -27,7 +27,8 @@ func (r ResourceResolverFunc) Resolve(ctx context.Context, ns claims.NamespaceIn
 }
 
 type ResourceAuthorizerOptions struct {
-	// Resource is the resource name in plural.
+	// Resource is the resource name in plural. Options of a subresource are named resource/subresource, the
+	// requests of the subresources without options are authorized with the options of their resource.
 	Resource string
 	// Unchecked is used to skip authorization checks for specified verbs.
 	// This takes precedence over configured Mapping
@@ -90,6 +91,9 @@ func (c *LegacyAccessClient) Check(ctx context.Context, id claims.AuthInfo, req
 	}
 
 	opts, ok := c.opts[req.Resource]
+	if sub, found := c.opts[req.Resource+"/"+req.Subresource]; found && req.Subresource != "" {
+		opts, ok = sub, true
+	}
 	if !ok {
 		// For now we fallback to grafana admin if no options are found for resource.
 		if ident.GetIsGrafanaAdmin() {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/grafana/authlib/authz"
+	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
@@ -135,6 +136,43 @@ func TestLegacyAccessClient_Check(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, false, res.Allowed)
 	})
+
+	t.Run("should use the configuration of the subresource", func(t *testing.T) {
+		a := accesscontrol.NewLegacyAccessClient(ac, accesscontrol.ResourceAuthorizerOptions{
+			Resource: "teams",
+			Attr:     "id",
+		}, accesscontrol.ResourceAuthorizerOptions{
+			Resource: "teams/roles",
+			Mapping: map[string]string{
+				"get": "teams.roles:read",
+			},
+			Resolver: accesscontrol.ResourceResolverFunc(func(ctx context.Context, ns claims.NamespaceInfo, name string) ([]string, error) {
+				return []string{"teams:id:" + name}, nil
+			}),
+		})
+
+		ident := newIdent(accesscontrol.Permission{Action: "teams.roles:read", Scope: "teams:id:1"})
+
+		res, err := a.Check(context.Background(), ident, authz.CheckRequest{
+			Verb:        "get",
+			Namespace:   "default",
+			Resource:    "teams",
+			Subresource: "roles",
+			Name:        "1",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, true, res.Allowed)
+
+		res, err = a.Check(context.Background(), ident, authz.CheckRequest{
+			Verb:        "get",
+			Namespace:   "default",
+			Resource:    "teams",
+			Subresource: "members",
+			Name:        "1",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, false, res.Allowed, "the other subresources need the actions of the resource")
+	})
 }
 
 func newIdent(permissions ...accesscontrol.Permission) *identity.StaticRequester {
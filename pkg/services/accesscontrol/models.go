@@ -409,10 +409,20 @@ const (
 	ActionTeamsWrite            = "teams:write"
 	ActionTeamsPermissionsRead  = "teams.permissions:read"
 	ActionTeamsPermissionsWrite = "teams.permissions:write"
+	ActionTeamsRolesRead        = "teams.roles:read"
+	ActionTeamsRolesAdd         = "teams.roles:add"
+	ActionTeamsRolesRemove      = "teams.roles:remove"
 
 	// Team related scopes
 	ScopeTeamsAll = "teams:*"
 
+	// Role related actions
+	ActionRolesRead = "roles:read"
+
+	// Role related scopes
+	ScopeRolesAll    = "roles:*"
+	ScopeRolesPrefix = "roles:uid:"
+
 	// Annotations related actions
 	ActionAnnotationsCreate = "annotations:create"
 	ActionAnnotationsDelete = "annotations:delete"
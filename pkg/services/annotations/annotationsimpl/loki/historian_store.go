@@ -52,10 +52,12 @@ type lokiQueryClient interface {
 
 // LokiHistorianStore is a read store that queries Loki for alert state history.
 type LokiHistorianStore struct {
-	client    lokiQueryClient
-	db        db.DB
-	log       log.Logger
-	ruleStore RuleStore
+	client lokiQueryClient
+	// structuredMetadata reads the state history written with structured metadata too
+	structuredMetadata bool
+	db                 db.DB
+	log                log.Logger
+	ruleStore          RuleStore
 }
 
 func NewLokiHistorianStore(cfg setting.UnifiedAlertingStateHistorySettings, ft featuremgmt.FeatureToggles, db db.DB, ruleStore RuleStore, log log.Logger, tracer tracing.Tracer) *LokiHistorianStore {
@@ -69,10 +71,11 @@ func NewLokiHistorianStore(cfg setting.UnifiedAlertingStateHistorySettings, ft f
 	}
 
 	return &LokiHistorianStore{
-		client:    historian.NewLokiClient(lokiCfg, historian.NewRequester(), ngmetrics.NewHistorianMetrics(prometheus.DefaultRegisterer, subsystem), log, tracer),
-		db:        db,
-		log:       log,
-		ruleStore: ruleStore,
+		client:             historian.NewLokiClient(lokiCfg, historian.NewRequester(), ngmetrics.NewHistorianMetrics(prometheus.DefaultRegisterer, subsystem), log, tracer),
+		structuredMetadata: lokiCfg.StructuredMetadata,
+		db:                 db,
+		log:                log,
+		ruleStore:          ruleStore,
 	}
 }
 
@@ -104,7 +107,7 @@ func (r *LokiHistorianStore) Get(ctx context.Context, query annotations.ItemQuer
 	}
 
 	// No folders in the filter because it filter by Dashboard UID, and the request is already authorized.
-	logQL, err := historian.BuildLogQuery(buildHistoryQuery(&query, accessResources.Dashboards, rule.UID), nil, r.client.MaxQuerySize())
+	logQL, err := historian.BuildLogQuery(buildHistoryQuery(&query, accessResources.Dashboards, rule.UID), nil, r.client.MaxQuerySize(), r.structuredMetadata)
 	if err != nil {
 		grafanaErr := errutil.Error{}
 		if errors.As(err, &grafanaErr) {
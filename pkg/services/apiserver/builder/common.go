@@ -68,6 +68,12 @@ type APIGroupOptions struct {
 	StorageOptions   apistore.StorageOptionsRegister
 }
 
+// Builders that implement APIGroupRequestFilter wrap the handler of the requests, e.g. to instrument the requests
+// of their group. The handler is wrapped after the K8s chain, the request info and the user are in the context.
+type APIGroupRequestFilter interface {
+	FilterRequests(handler http.Handler) http.Handler
+}
+
 // Builders that implement OpenAPIPostProcessor are given a chance to modify the schema directly
 type OpenAPIPostProcessor interface {
 	PostProcessOpenAPI(*spec3.OpenAPI) (*spec3.OpenAPI, error)
@@ -78,18 +78,31 @@ func getDefaultBuildHandlerChainFunc(builders []APIGroupBuilder) BuildHandlerCha
 			panic(fmt.Sprintf("could not build the request handler for specified API builders: %s", err.Error()))
 		}
 
+		for _, b := range builders {
+			if f, ok := b.(APIGroupRequestFilter); ok {
+				requestHandler = f.FilterRequests(requestHandler)
+			}
+		}
+
 		// Needs to run last in request chain to function as expected, hence we register it first.
 		handler := filters.WithTracingHTTPLoggingAttributes(requestHandler)
 
 		// filters.WithRequester needs to be after the K8s chain because it depends on the K8s user in context
 		handler = filters.WithRequester(handler)
 
+		// filters.WithConditionalGet needs to be after the K8s chain so it can replace the default cache-control
+		handler = filters.WithConditionalGet(handler)
+
 		// Call DefaultBuildHandlerChain on the main entrypoint http.Handler
 		// See https://github.com/kubernetes/apiserver/blob/v0.28.0/pkg/server/config.go#L906
 		// DefaultBuildHandlerChain provides many things, notably CORS, HSTS, cache-control, authz and latency tracking
 		handler = genericapiserver.DefaultBuildHandlerChain(handler, c)
 
 		handler = filters.WithAcceptHeader(handler)
+		handler = filters.WithFields(handler)
+		handler = filters.WithIncludeObject(handler)
+		handler = filters.WithOrigin(handler)
+		handler = filters.WithContentEncoding(handler)
 		handler = filters.WithPathRewriters(handler, PathRewriters)
 		handler = k8stracing.WithTracing(handler, c.TracerProvider, "KubernetesAPI")
 		handler = filters.WithExtractJaegerTrace(handler)
@@ -45,6 +45,8 @@ func (o *ExtraOptions) ApplyTo(c *genericapiserver.RecommendedConfig) error {
 	logger := slog.New(handler)
 	if err := utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{
 		string(genericfeatures.APIServerTracing): false,
+		// gzip large responses for clients that accept it, dashboards can be several MB
+		string(genericfeatures.APIResponseCompression): true,
 	}); err != nil {
 		return err
 	}
@@ -32,6 +32,10 @@ const (
 	ActionDashboardsPermissionsRead  = "dashboards.permissions:read"
 	ActionDashboardsPermissionsWrite = "dashboards.permissions:write"
 	ActionDashboardsPublicWrite      = "dashboards.public:write"
+
+	ActionSnapshotsCreate = "snapshots:create"
+	ActionSnapshotsRead   = "snapshots:read"
+	ActionSnapshotsDelete = "snapshots:delete"
 )
 
 var (
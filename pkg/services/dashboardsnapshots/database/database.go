@@ -107,6 +107,30 @@ func (d *DashboardSnapshotStore) GetDashboardSnapshot(ctx context.Context, query
 	return queryResult, nil
 }
 
+// GetExpiredDashboardSnapshots returns the snapshots with old expiry dates, oldest first.
+// The dashboard is not loaded.
+func (d *DashboardSnapshotStore) GetExpiredDashboardSnapshots(ctx context.Context, query *dashboardsnapshots.GetExpiredDashboardSnapshotsQuery) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	var queryResult []*dashboardsnapshots.DashboardSnapshot
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var snapshots = make([]*dashboardsnapshots.DashboardSnapshot, 0)
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		sess.Table("dashboard_snapshot").
+			Cols("id", "name", "key", "delete_key", "org_id", "user_id", "external", "external_url", "external_delete_url", "expires", "created", "updated").
+			Where("expires < ?", time.Now()).
+			Asc("expires")
+
+		err := sess.Find(&snapshots)
+		queryResult = snapshots
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queryResult, nil
+}
+
 // SearchDashboardSnapshots returns a list of all snapshots for admins
 // for other roles, it returns snapshots created by the user
 func (d *DashboardSnapshotStore) SearchDashboardSnapshots(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotsQuery) (dashboardsnapshots.DashboardSnapshotsList, error) {
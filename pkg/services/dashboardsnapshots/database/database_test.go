@@ -199,6 +199,30 @@ func TestIntegrationDeleteExpiredSnapshots(t *testing.T) {
 	})
 }
 
+func TestIntegrationGetExpiredDashboardSnapshots(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	dashStore := NewStore(sqlstore)
+
+	createTestSnapshot(t, dashStore, "key1", 48000)
+	createTestSnapshot(t, dashStore, "key2", -600)
+	createTestSnapshot(t, dashStore, "key3", -1200)
+
+	expired, err := dashStore.GetExpiredDashboardSnapshots(context.Background(), &dashboardsnapshots.GetExpiredDashboardSnapshotsQuery{})
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+	require.Equal(t, "key3", expired[0].Key)
+	require.Equal(t, "deletekey3", expired[0].DeleteKey)
+	require.Equal(t, "key2", expired[1].Key)
+	require.Nil(t, expired[0].DashboardEncrypted)
+
+	expired, err = dashStore.GetExpiredDashboardSnapshots(context.Background(), &dashboardsnapshots.GetExpiredDashboardSnapshotsQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+}
+
 func createTestSnapshot(t *testing.T, dashStore *DashboardSnapshotStore, key string, expires int64) *dashboardsnapshots.DashboardSnapshot {
 	cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
 		Key:       key,
@@ -82,6 +82,11 @@ type GetDashboardSnapshotQuery struct {
 	DeleteKey string
 }
 
+// GetExpiredDashboardSnapshotsQuery returns expired snapshots without their dashboard
+type GetExpiredDashboardSnapshotsQuery struct {
+	Limit int
+}
+
 type DashboardSnapshotsList []*DashboardSnapshotDTO
 
 type GetDashboardSnapshotsQuery struct {
@@ -27,6 +27,7 @@ type Service interface {
 	DeleteDashboardSnapshot(context.Context, *DeleteDashboardSnapshotCommand) error
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
+	GetExpiredDashboardSnapshots(context.Context, *GetExpiredDashboardSnapshotsQuery) ([]*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
 	ValidateDashboardExists(context.Context, int64, string) error
 }
@@ -79,20 +80,12 @@ func CreateDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.S
 			return
 		}
 
-		resp, err := createExternalDashboardSnapshot(cmd, cfg.ExternalSnapshotURL)
-		if err != nil {
+		if err := PublishExternalDashboardSnapshot(&cmd, cfg.ExternalSnapshotURL); err != nil {
 			c.JsonApiErr(http.StatusInternalServerError, "Failed to create external snapshot", err)
 			return
 		}
 
-		snapshotUrl = resp.Url
-		cmd.Key = resp.Key
-		cmd.DeleteKey = resp.DeleteKey
-		cmd.ExternalURL = resp.Url
-		cmd.ExternalDeleteURL = resp.DeleteUrl
-		cmd.DashboardCreateCommand.Dashboard = &common.Unstructured{}
-
-		metrics.MApiDashboardSnapshotExternal.Inc()
+		snapshotUrl = cmd.ExternalURL
 	} else {
 		cmd.DashboardCreateCommand.Dashboard.SetNestedField(originalDashboardURL, "snapshot", "originalUrl")
 
@@ -167,6 +160,25 @@ func DeleteExternalDashboardSnapshot(externalUrl string) error {
 	return fmt.Errorf("unexpected response when deleting external snapshot, status code: %d", resp.StatusCode)
 }
 
+// PublishExternalDashboardSnapshot stores the snapshot on the external snapshot server.
+// The command is updated with the keys and URLs returned by the server, and the dashboard
+// is cleared so that it is not stored locally.
+func PublishExternalDashboardSnapshot(cmd *CreateDashboardSnapshotCommand, externalSnapshotUrl string) error {
+	resp, err := createExternalDashboardSnapshot(*cmd, externalSnapshotUrl)
+	if err != nil {
+		return err
+	}
+
+	cmd.Key = resp.Key
+	cmd.DeleteKey = resp.DeleteKey
+	cmd.ExternalURL = resp.Url
+	cmd.ExternalDeleteURL = resp.DeleteUrl
+	cmd.DashboardCreateCommand.Dashboard = &common.Unstructured{}
+
+	metrics.MApiDashboardSnapshotExternal.Inc()
+	return nil
+}
+
 func createExternalDashboardSnapshot(cmd CreateDashboardSnapshotCommand, externalSnapshotUrl string) (*CreateExternalSnapshotResponse, error) {
 	var createSnapshotResponse CreateExternalSnapshotResponse
 	message := map[string]any{
@@ -72,6 +72,10 @@ func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboard
 	return queryResult, err
 }
 
+func (s *ServiceImpl) GetExpiredDashboardSnapshots(ctx context.Context, query *dashboardsnapshots.GetExpiredDashboardSnapshotsQuery) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	return s.store.GetExpiredDashboardSnapshots(ctx, query)
+}
+
 func (s *ServiceImpl) DeleteDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotCommand) error {
 	return s.store.DeleteDashboardSnapshot(ctx, cmd)
 }
@@ -93,6 +93,32 @@ func (_m *MockService) GetDashboardSnapshot(_a0 context.Context, _a1 *GetDashboa
 	return r0, r1
 }
 
+// GetExpiredDashboardSnapshots provides a mock function with given fields: _a0, _a1
+func (_m *MockService) GetExpiredDashboardSnapshots(_a0 context.Context, _a1 *GetExpiredDashboardSnapshotsQuery) ([]*DashboardSnapshot, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*DashboardSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetExpiredDashboardSnapshotsQuery) ([]*DashboardSnapshot, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetExpiredDashboardSnapshotsQuery) []*DashboardSnapshot); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DashboardSnapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetExpiredDashboardSnapshotsQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SearchDashboardSnapshots provides a mock function with given fields: _a0, _a1
 func (_m *MockService) SearchDashboardSnapshots(_a0 context.Context, _a1 *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error) {
 	ret := _m.Called(_a0, _a1)
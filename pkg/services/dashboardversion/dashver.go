@@ -8,4 +8,6 @@ type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersionDTO, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) ([]*DashboardVersionDTO, error)
+	// UpdateLabel sets the label and the pinned flag of a version, and returns the updated version.
+	UpdateLabel(context.Context, *UpdateLabelCommand) (*DashboardVersionDTO, error)
 }
@@ -65,6 +65,11 @@ func (s *Service) Get(ctx context.Context, query *dashver.GetDashboardVersionQue
 }
 
 func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand) error {
+	if s.cfg.DashboardVersionsRetention.Enabled {
+		// the RetentionCleaner applies the retention of each organization instead
+		return nil
+	}
+
 	versionsToKeep := s.cfg.DashboardVersionsToKeep
 	if versionsToKeep < 1 {
 		versionsToKeep = 1
@@ -129,6 +134,30 @@ func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersions
 	return dtos, nil
 }
 
+// UpdateLabel sets the label and the pinned flag of a dashboard version. Pinned versions are not deleted by the
+// clean up, so the versions marked as known to be good can always be restored.
+func (s *Service) UpdateLabel(ctx context.Context, cmd *dashver.UpdateLabelCommand) (*dashver.DashboardVersionDTO, error) {
+	if cmd.Label != nil && len(*cmd.Label) > dashver.MaxLabelLength {
+		return nil, dashver.ErrDashboardVersionLabelTooLong
+	}
+	if cmd.DashboardID == 0 {
+		id, err := s.getDashIDMaybeEmpty(ctx, cmd.DashboardUID)
+		if err != nil {
+			return nil, err
+		}
+		cmd.DashboardID = id
+	}
+	if err := s.store.UpdateLabel(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  cmd.DashboardID,
+		DashboardUID: cmd.DashboardUID,
+		OrgID:        cmd.OrgID,
+		Version:      cmd.Version,
+	})
+}
+
 // getDashUIDMaybeEmpty is a helper function which takes a dashboardID and
 // returns the UID. If the dashboard is not found, it will return an empty
 // string.
@@ -3,7 +3,9 @@ package dashverimpl
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -61,6 +63,13 @@ func TestDeleteExpiredVersions(t *testing.T) {
 		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
 		require.NotNil(t, err)
 	})
+
+	t.Run("Don't delete anything when the retention cleaner is enabled", func(t *testing.T) {
+		cfg.DashboardVersionsRetention.Enabled = true
+		t.Cleanup(func() { cfg.DashboardVersionsRetention.Enabled = false })
+		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{})
+		require.Nil(t, err)
+	})
 }
 
 func TestListDashboardVersions(t *testing.T) {
@@ -146,11 +155,43 @@ func TestListDashboardVersions(t *testing.T) {
 	})
 }
 
+func TestUpdateDashboardVersionLabel(t *testing.T) {
+	t.Run("Label and pin a version", func(t *testing.T) {
+		dashboardVersionStore := newDashboardVersionStoreFake()
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboardService, log: log.NewNopLogger()}
+		dashboardVersionStore.ExpectedDashboardVersion = &dashver.DashboardVersion{
+			ID: 1, DashboardID: 42, Version: 3, Label: "last known good", Pinned: true, Data: simplejson.New(),
+		}
+
+		label, pinned := "last known good", true
+		cmd := &dashver.UpdateLabelCommand{DashboardID: 42, DashboardUID: "uid", OrgID: 1, Version: 3, Label: &label, Pinned: &pinned}
+		res, err := dashboardVersionService.UpdateLabel(context.Background(), cmd)
+		require.NoError(t, err)
+		require.Equal(t, cmd, dashboardVersionStore.ExpectedUpdateLabel)
+		require.Equal(t, "last known good", res.Label)
+		require.True(t, res.Pinned)
+		require.Equal(t, "uid", res.DashboardUID)
+	})
+
+	t.Run("Reject a label that is too long", func(t *testing.T) {
+		dashboardVersionStore := newDashboardVersionStoreFake()
+		dashboardVersionService := Service{store: dashboardVersionStore, log: log.NewNopLogger()}
+
+		label := strings.Repeat("a", dashver.MaxLabelLength+1)
+		_, err := dashboardVersionService.UpdateLabel(context.Background(), &dashver.UpdateLabelCommand{DashboardID: 42, DashboardUID: "uid", Version: 3, Label: &label})
+		require.ErrorIs(t, err, dashver.ErrDashboardVersionLabelTooLong)
+		require.Nil(t, dashboardVersionStore.ExpectedUpdateLabel)
+	})
+}
+
 type FakeDashboardVersionStore struct {
 	ExpectedDashboardVersion *dashver.DashboardVersion
 	ExptectedDeletedVersions int64
 	ExpectedVersions         []any
 	ExpectedListVersions     []*dashver.DashboardVersion
+	ExpectedOrgIDs           []int64
+	ExpectedUpdateLabel      *dashver.UpdateLabelCommand
 	ExpectedError            error
 }
 
@@ -173,3 +214,16 @@ func (f *FakeDashboardVersionStore) DeleteBatch(ctx context.Context, cmd *dashve
 func (f *FakeDashboardVersionStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error) {
 	return f.ExpectedListVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionStore) GetOrgIDs(ctx context.Context) ([]int64, error) {
+	return f.ExpectedOrgIDs, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) GetExpiredBatch(ctx context.Context, orgID int64, versionsToKeep int, createdBefore time.Time, limit int) ([]any, error) {
+	return f.ExpectedVersions, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) UpdateLabel(ctx context.Context, cmd *dashver.UpdateLabelCommand) error {
+	f.ExpectedUpdateLabel = cmd
+	return f.ExpectedError
+}
@@ -0,0 +1,134 @@
+package dashverimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type retentionMetrics struct {
+	deleted *prometheus.CounterVec
+	failed  *prometheus.CounterVec
+}
+
+func newRetentionMetrics(reg prometheus.Registerer) *retentionMetrics {
+	return &retentionMetrics{
+		deleted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboard_versions",
+			Name:      "retention_deleted_total",
+			Help:      "The total number of dashboard versions deleted by the retention cleaner.",
+		}, []string{"org"}),
+		failed: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "dashboard_versions",
+			Name:      "retention_failed_total",
+			Help:      "The total number of failed runs of the retention cleaner for an organization.",
+		}, []string{"org"}),
+	}
+}
+
+// RetentionCleaner periodically deletes the dashboard versions that the retention of their organization does not keep.
+// It replaces the clean up of the cleanup service, which keeps the same number of versions in every organization.
+type RetentionCleaner struct {
+	store   store
+	cfg     setting.DashboardVersionsRetentionSettings
+	clock   clock.Clock
+	metrics *retentionMetrics
+	log     log.Logger
+}
+
+func ProvideRetentionCleaner(cfg *setting.Cfg, db db.DB, reg prometheus.Registerer) *RetentionCleaner {
+	return &RetentionCleaner{
+		store: &sqlStore{
+			db:      db,
+			dialect: db.GetDialect(),
+		},
+		cfg:     cfg.DashboardVersionsRetention,
+		clock:   clock.New(),
+		metrics: newRetentionMetrics(reg),
+		log:     log.New("dashboard-version.retention"),
+	}
+}
+
+func (c *RetentionCleaner) IsDisabled() bool {
+	return !c.cfg.Enabled
+}
+
+// Run applies the retention to all organizations every interval until the context is cancelled.
+func (c *RetentionCleaner) Run(ctx context.Context) error {
+	c.log.Info("Starting dashboard versions retention", "interval", c.cfg.Interval)
+	ticker := c.clock.Ticker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.log.Info("Stopping dashboard versions retention")
+			return nil
+		case <-ticker.C:
+			c.runOnce(ctx)
+		}
+	}
+}
+
+func (c *RetentionCleaner) runOnce(ctx context.Context) {
+	orgIDs, err := c.store.GetOrgIDs(ctx)
+	if err != nil {
+		c.log.Error("Failed to fetch organizations with dashboards", "error", err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		deleted, err := c.Clean(ctx, orgID)
+		if err != nil {
+			c.metrics.failed.WithLabelValues(fmt.Sprint(orgID)).Inc()
+			c.log.Error("Failed to apply dashboard versions retention", "org", orgID, "error", err)
+			continue
+		}
+		if deleted > 0 {
+			c.log.Debug("Applied dashboard versions retention", "org", orgID, "deleted", deleted)
+		}
+	}
+}
+
+// Clean deletes the versions of the dashboards of the organization that its retention does not keep,
+// and returns the number of deleted versions.
+func (c *RetentionCleaner) Clean(ctx context.Context, orgID int64) (int64, error) {
+	retention := c.cfg.ForOrg(orgID)
+	var createdBefore time.Time
+	if retention.MaxAge > 0 {
+		createdBefore = c.clock.Now().Add(-retention.MaxAge)
+	}
+
+	var total int64
+	for ctx.Err() == nil {
+		ids, err := c.store.GetExpiredBatch(ctx, orgID, max(retention.VersionsToKeep, 1), createdBefore, c.cfg.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to find expired dashboard versions: %w", err)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		deleted, err := c.store.DeleteBatch(ctx, &dashver.DeleteExpiredVersionsCommand{}, ids)
+		total += deleted
+		c.metrics.deleted.WithLabelValues(fmt.Sprint(orgID)).Add(float64(deleted))
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired dashboard versions: %w", err)
+		}
+		if deleted == 0 {
+			return total, nil
+		}
+	}
+	return total, ctx.Err()
+}
@@ -0,0 +1,101 @@
+package dashverimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRetentionCleaner(t *testing.T) {
+	cfg := setting.DashboardVersionsRetentionSettings{
+		Enabled:   true,
+		Interval:  time.Hour,
+		BatchSize: 2,
+		Default:   setting.DashboardVersionsRetention{VersionsToKeep: 20},
+		OrgOverrides: map[int64]setting.DashboardVersionsRetention{
+			2: {VersionsToKeep: 5, MaxAge: 24 * time.Hour},
+		},
+	}
+	newCleaner := func(store *fakeRetentionStore) (*RetentionCleaner, *clock.Mock) {
+		mock := clock.NewMock()
+		return &RetentionCleaner{
+			store:   store,
+			cfg:     cfg,
+			clock:   mock,
+			metrics: newRetentionMetrics(prometheus.NewRegistry()),
+			log:     log.NewNopLogger(),
+		}, mock
+	}
+
+	t.Run("deletes the expired versions in batches", func(t *testing.T) {
+		store := &fakeRetentionStore{batches: [][]any{{1, 2}, {3}}}
+		cleaner, _ := newCleaner(store)
+
+		deleted, err := cleaner.Clean(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), deleted)
+		require.Equal(t, []any{1, 2, 3}, store.deleted)
+		require.Equal(t, 20, store.versionsToKeep)
+		require.True(t, store.createdBefore.IsZero())
+		require.Equal(t, float64(3), testutil.ToFloat64(cleaner.metrics.deleted.WithLabelValues("1")))
+	})
+
+	t.Run("applies the retention of the organization", func(t *testing.T) {
+		store := &fakeRetentionStore{}
+		cleaner, mock := newCleaner(store)
+		mock.Set(time.Date(2024, 11, 26, 12, 0, 0, 0, time.UTC))
+
+		_, err := cleaner.Clean(context.Background(), 2)
+		require.NoError(t, err)
+		require.Equal(t, 5, store.versionsToKeep)
+		require.Equal(t, time.Date(2024, 11, 25, 12, 0, 0, 0, time.UTC), store.createdBefore)
+	})
+
+	t.Run("counts the failures of an organization", func(t *testing.T) {
+		store := &fakeRetentionStore{orgIDs: []int64{1, 2}, err: errors.New("boom")}
+		cleaner, _ := newCleaner(store)
+
+		cleaner.runOnce(context.Background())
+		require.Equal(t, float64(1), testutil.ToFloat64(cleaner.metrics.failed.WithLabelValues("1")))
+		require.Equal(t, float64(1), testutil.ToFloat64(cleaner.metrics.failed.WithLabelValues("2")))
+	})
+}
+
+type fakeRetentionStore struct {
+	FakeDashboardVersionStore
+	orgIDs         []int64
+	batches        [][]any
+	deleted        []any
+	versionsToKeep int
+	createdBefore  time.Time
+	err            error
+}
+
+func (f *fakeRetentionStore) GetOrgIDs(ctx context.Context) ([]int64, error) {
+	return f.orgIDs, nil
+}
+
+func (f *fakeRetentionStore) GetExpiredBatch(ctx context.Context, orgID int64, versionsToKeep int, createdBefore time.Time, limit int) ([]any, error) {
+	f.versionsToKeep, f.createdBefore = versionsToKeep, createdBefore
+	if f.err != nil || len(f.batches) == 0 {
+		return nil, f.err
+	}
+	batch := f.batches[0]
+	f.batches = f.batches[1:]
+	return batch, nil
+}
+
+func (f *fakeRetentionStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, ids []any) (int64, error) {
+	f.deleted = append(f.deleted, ids...)
+	return int64(len(ids)), nil
+}
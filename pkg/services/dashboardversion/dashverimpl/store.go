@@ -2,6 +2,7 @@ package dashverimpl
 
 import (
 	"context"
+	"time"
 
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 )
@@ -11,4 +12,11 @@ type store interface {
 	GetBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, int, int) ([]any, error)
 	DeleteBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, []any) (int64, error)
 	List(context.Context, *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error)
+	// UpdateLabel sets the label and the pinned flag of a version of a dashboard of the organization.
+	UpdateLabel(context.Context, *dashver.UpdateLabelCommand) error
+	// GetOrgIDs returns the IDs of the organizations that have dashboards.
+	GetOrgIDs(ctx context.Context) ([]int64, error)
+	// GetExpiredBatch returns the IDs of up to limit versions of the dashboards of the organization that are not pinned,
+	// not among the versionsToKeep most recent versions of their dashboard and, unless it is zero, created before createdBefore.
+	GetExpiredBatch(ctx context.Context, orgID int64, versionsToKeep int, createdBefore time.Time, limit int) ([]any, error)
 }
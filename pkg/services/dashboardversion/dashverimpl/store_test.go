@@ -2,6 +2,7 @@ package dashverimpl
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
@@ -116,6 +117,131 @@ func testIntegrationGetDashboardVersion(t *testing.T, fn getStore) {
 		require.Nil(t, err)
 		assert.Equal(t, 2, len(res))
 	})
+
+	t.Run("Get the versions older than a version", func(t *testing.T) {
+		query := dashver.ListDashboardVersionsQuery{DashboardID: savedDash.ID, OrgID: 1, Limit: 1000, BeforeVersion: 2}
+		res, err := dashVerStore.List(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Equal(t, 1, len(res))
+		assert.Equal(t, 1, res[0].Version)
+	})
+
+	t.Run("Get the versions expired by a retention", func(t *testing.T) {
+		orgIDs, err := dashVerStore.GetOrgIDs(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, orgIDs)
+
+		// only the first version of the updated dashboard is not among the most recent ones
+		res, err := dashVerStore.GetExpiredBatch(context.Background(), 1, 1, time.Time{}, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(res))
+
+		res, err = dashVerStore.GetExpiredBatch(context.Background(), 1, 2, time.Time{}, 100)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+
+		// the version is younger than the maximum age
+		res, err = dashVerStore.GetExpiredBatch(context.Background(), 1, 1, time.Now().Add(-time.Hour), 100)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+
+		res, err = dashVerStore.GetExpiredBatch(context.Background(), 2, 1, time.Time{}, 100)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("Label and pin a version", func(t *testing.T) {
+		label, pinned := "last known good", true
+		err := dashVerStore.UpdateLabel(context.Background(), &dashver.UpdateLabelCommand{
+			DashboardID: savedDash.ID, OrgID: 1, Version: 1, Label: &label, Pinned: &pinned,
+		})
+		require.NoError(t, err)
+
+		res, err := dashVerStore.Get(context.Background(), &dashver.GetDashboardVersionQuery{DashboardID: savedDash.ID, OrgID: 1, Version: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "last known good", res.Label)
+		assert.True(t, res.Pinned)
+
+		// the fields that are not set are not changed
+		unpinned := false
+		err = dashVerStore.UpdateLabel(context.Background(), &dashver.UpdateLabelCommand{
+			DashboardID: savedDash.ID, OrgID: 1, Version: 2, Pinned: &unpinned,
+		})
+		require.NoError(t, err)
+		versions, err := dashVerStore.List(context.Background(), &dashver.ListDashboardVersionsQuery{DashboardID: savedDash.ID, OrgID: 1, Limit: 1000})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, "last known good", versions[1].Label)
+		assert.True(t, versions[1].Pinned)
+		assert.Empty(t, versions[0].Label)
+
+		err = dashVerStore.UpdateLabel(context.Background(), &dashver.UpdateLabelCommand{
+			DashboardID: savedDash.ID, OrgID: 2, Version: 1, Label: &label,
+		})
+		assert.ErrorIs(t, err, dashver.ErrDashboardVersionNotFound)
+	})
+
+	t.Run("Pinned versions are not expired", func(t *testing.T) {
+		res, err := dashVerStore.GetExpiredBatch(context.Background(), 1, 1, time.Time{}, 100)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+
+		res, err = dashVerStore.GetBatch(context.Background(), &dashver.DeleteExpiredVersionsCommand{}, 100, 1)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("Filter the versions by author, time range and message", func(t *testing.T) {
+		filtered := insertTestDashboard(t, ss, "test dash filters", 1, "", false, "diff-all")
+		created := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for i, v := range []struct {
+				createdBy int64
+				created   time.Time
+				message   string
+			}{
+				{createdBy: 20, created: created, message: "fix the cpu panel"},
+				{createdBy: 30, created: created.Add(24 * time.Hour), message: "add the memory panel"},
+				{createdBy: -1, created: created.Add(48 * time.Hour), message: "provisioned"},
+			} {
+				_, err := sess.Insert(&dashver.DashboardVersion{
+					DashboardID:   filtered.ID,
+					ParentVersion: i + 1,
+					Version:       i + 2,
+					Created:       v.created,
+					CreatedBy:     v.createdBy,
+					Message:       v.message,
+					Data:          filtered.Data,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		versions := func(query dashver.ListDashboardVersionsQuery) []int {
+			query.DashboardID, query.OrgID, query.Limit = filtered.ID, 1, 1000
+			res, err := dashVerStore.List(context.Background(), &query)
+			if errors.Is(err, dashver.ErrNoVersionsForDashboardID) {
+				return []int{}
+			}
+			require.NoError(t, err)
+			out := []int{}
+			for _, v := range res {
+				out = append(out, v.Version)
+			}
+			return out
+		}
+		assert.Equal(t, []int{3}, versions(dashver.ListDashboardVersionsQuery{CreatedBy: 30}))
+		assert.Equal(t, []int{4}, versions(dashver.ListDashboardVersionsQuery{CreatedBy: -1}))
+		assert.Equal(t, []int{3, 2}, versions(dashver.ListDashboardVersionsQuery{From: created, To: created.Add(24 * time.Hour)}))
+		assert.Equal(t, []int{3, 2}, versions(dashver.ListDashboardVersionsQuery{Message: "panel"}))
+		assert.Equal(t, []int{2}, versions(dashver.ListDashboardVersionsQuery{Message: "cpu", From: created}))
+		assert.Equal(t, []int{}, versions(dashver.ListDashboardVersionsQuery{Message: "cpu", CreatedBy: 30}))
+	})
 }
 
 func getDashboard(t *testing.T, sqlStore db.DB, dashboard *dashboards.Dashboard) error {
@@ -3,6 +3,7 @@ package dashverimpl
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
@@ -47,9 +48,10 @@ func (ss *sqlStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVers
 			) AS vtd
 			WHERE dashboard_version.dashboard_id=vtd.dashboard_id
 			AND version < vtd.min + vtd.count - ?
+			AND pinned = ?
 			LIMIT ?`
 
-		err := sess.SQL(versionIdsToDeleteQuery, versionsToKeep, perBatch).Find(&versionIds)
+		err := sess.SQL(versionIdsToDeleteQuery, versionsToKeep, ss.dialect.BooleanStr(false), perBatch).Find(&versionIds)
 		return err
 	})
 	return versionIds, err
@@ -74,7 +76,7 @@ func (ss *sqlStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredV
 func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error) {
 	var dashboardVersion []*dashver.DashboardVersion
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		err := sess.Table("dashboard_version").
+		sess.Table("dashboard_version").
 			Select(`dashboard_version.id,
 				dashboard_version.dashboard_id,
 				dashboard_version.parent_version,
@@ -83,10 +85,28 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 				dashboard_version.created,
 				dashboard_version.created_by,
 				dashboard_version.message,
-				dashboard_version.data`).
+				dashboard_version.data,
+				dashboard_version.label,
+				dashboard_version.pinned`).
 			Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
-			Where("dashboard_version.dashboard_id=? AND dashboard.org_id=?", query.DashboardID, query.OrgID).
-			OrderBy("dashboard_version.version DESC").
+			Where("dashboard_version.dashboard_id=? AND dashboard.org_id=?", query.DashboardID, query.OrgID)
+		if query.BeforeVersion > 0 {
+			sess.And("dashboard_version.version < ?", query.BeforeVersion)
+		}
+		if query.CreatedBy != 0 {
+			sess.And("dashboard_version.created_by = ?", query.CreatedBy)
+		}
+		// the times are compared in the format they are stored in, so a version saved at the bounds is included
+		if !query.From.IsZero() {
+			sess.And("dashboard_version.created >= ?", query.From.UTC().Format(time.DateTime))
+		}
+		if !query.To.IsZero() {
+			sess.And("dashboard_version.created <= ?", query.To.UTC().Format(time.DateTime))
+		}
+		if query.Message != "" {
+			sess.And("dashboard_version.message "+ss.dialect.LikeStr()+" ?", "%"+query.Message+"%")
+		}
+		err := sess.OrderBy("dashboard_version.version DESC").
 			Limit(query.Limit, query.Start).
 			Find(&dashboardVersion)
 		if err != nil {
@@ -103,3 +123,60 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 	}
 	return dashboardVersion, nil
 }
+
+func (ss *sqlStore) GetOrgIDs(ctx context.Context) ([]int64, error) {
+	var orgIDs []int64
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT DISTINCT org_id FROM dashboard").Find(&orgIDs)
+	})
+	return orgIDs, err
+}
+
+func (ss *sqlStore) GetExpiredBatch(ctx context.Context, orgID int64, versionsToKeep int, createdBefore time.Time, limit int) ([]any, error) {
+	var versionIDs []any
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := `SELECT dashboard_version.id
+			FROM dashboard_version
+			INNER JOIN dashboard ON dashboard.id = dashboard_version.dashboard_id
+			WHERE dashboard.org_id = ?
+			AND dashboard_version.pinned = ?
+			AND (
+				SELECT COUNT(*) FROM dashboard_version AS newer
+				WHERE newer.dashboard_id = dashboard_version.dashboard_id AND newer.version > dashboard_version.version
+			) >= ?`
+		args := []any{orgID, ss.dialect.BooleanStr(false), versionsToKeep}
+		if !createdBefore.IsZero() {
+			rawSQL += ` AND dashboard_version.created < ?`
+			args = append(args, createdBefore)
+		}
+		rawSQL += ` LIMIT ?`
+		args = append(args, limit)
+		return sess.SQL(rawSQL, args...).Find(&versionIDs)
+	})
+	return versionIDs, err
+}
+
+func (ss *sqlStore) UpdateLabel(ctx context.Context, cmd *dashver.UpdateLabelCommand) error {
+	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		// the version is read first so the dashboard must belong to the organization
+		var version dashver.DashboardVersion
+		has, err := sess.Where("dashboard_version.dashboard_id=? AND dashboard_version.version=? AND dashboard.org_id=?", cmd.DashboardID, cmd.Version, cmd.OrgID).
+			Join("INNER", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
+			Get(&version)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashver.ErrDashboardVersionNotFound
+		}
+
+		if cmd.Label != nil {
+			version.Label = *cmd.Label
+		}
+		if cmd.Pinned != nil {
+			version.Pinned = *cmd.Pinned
+		}
+		_, err = sess.Exec("UPDATE dashboard_version SET label = ?, pinned = ? WHERE id = ?", version.Label, version.Pinned, version.ID)
+		return err
+	})
+}
@@ -10,6 +10,9 @@ type FakeDashboardVersionService struct {
 	ExpectedDashboardVersion     *dashver.DashboardVersionDTO
 	ExpectedDashboardVersions    []*dashver.DashboardVersionDTO
 	ExpectedListDashboarVersions []*dashver.DashboardVersionDTO
+	ExpectedUpdatedVersion       *dashver.DashboardVersionDTO
+	UpdateLabelCommands          []*dashver.UpdateLabelCommand
+	ListQueries                  []*dashver.ListDashboardVersionsQuery
 	counter                      int
 	ExpectedError                error
 }
@@ -31,5 +34,11 @@ func (f *FakeDashboardVersionService) DeleteExpired(ctx context.Context, cmd *da
 }
 
 func (f *FakeDashboardVersionService) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
+	f.ListQueries = append(f.ListQueries, query)
 	return f.ExpectedListDashboarVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionService) UpdateLabel(ctx context.Context, cmd *dashver.UpdateLabelCommand) (*dashver.DashboardVersionDTO, error) {
+	f.UpdateLabelCommands = append(f.UpdateLabelCommands, cmd)
+	return f.ExpectedUpdatedVersion, f.ExpectedError
+}
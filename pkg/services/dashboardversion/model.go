@@ -8,10 +8,14 @@ import (
 )
 
 var (
-	ErrDashboardVersionNotFound = errors.New("dashboard version not found")
-	ErrNoVersionsForDashboardID = errors.New("no dashboard versions found for the given DashboardId")
+	ErrDashboardVersionNotFound     = errors.New("dashboard version not found")
+	ErrNoVersionsForDashboardID     = errors.New("no dashboard versions found for the given DashboardId")
+	ErrDashboardVersionLabelTooLong = errors.New("dashboard version label is too long")
 )
 
+// MaxLabelLength is the maximum length of the label of a dashboard version
+const MaxLabelLength = 190
+
 // DashboardVersion represents a dashboard version in the database. Ideally this
 // will be moved into dashverimpl and unexported, but there are a few test
 // fixtures that insert DashboardVersions directly into a database which must be
@@ -28,6 +32,10 @@ type DashboardVersion struct {
 
 	Message string           `json:"message" db:"message"`
 	Data    *simplejson.Json `json:"data" db:"data"`
+
+	// Label names the version, e.g. "last known good". Pinned versions are kept by the clean up.
+	Label  string `json:"label" db:"label"`
+	Pinned bool   `json:"pinned" db:"pinned"`
 }
 
 // ToDTO converts a DashboardVersion to a DashboardVersionDTO.
@@ -43,6 +51,8 @@ func (v *DashboardVersion) ToDTO(dashUid string) *DashboardVersionDTO {
 		CreatedBy:     v.CreatedBy,
 		Message:       v.Message,
 		Data:          v.Data,
+		Label:         v.Label,
+		Pinned:        v.Pinned,
 	}
 }
 
@@ -59,12 +69,32 @@ type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
 }
 
+// UpdateLabelCommand sets the label and the pinned flag of a dashboard version, the fields that are nil are not changed.
+type UpdateLabelCommand struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	Version      int
+	Label        *string
+	Pinned       *bool
+}
+
 type ListDashboardVersionsQuery struct {
 	DashboardID  int64
 	DashboardUID string
 	OrgID        int64
 	Limit        int
 	Start        int
+	// BeforeVersion only lists the versions older than it when set, it is used to page
+	// through the versions without skipping any when the dashboard is saved in between.
+	BeforeVersion int
+	// CreatedBy only lists the versions saved by the user when set, -1 for the versions saved by the provisioning.
+	CreatedBy int64
+	// From and To only list the versions saved in the time range when set, both included.
+	From time.Time
+	To   time.Time
+	// Message only lists the versions whose message contains it when set.
+	Message string
 }
 type DashboardVersionDTO struct {
 	ID            int64            `json:"id"`
@@ -77,6 +107,8 @@ type DashboardVersionDTO struct {
 	CreatedBy     int64            `json:"createdBy"`
 	Message       string           `json:"message"`
 	Data          *simplejson.Json `json:"data" db:"data"`
+	Label         string           `json:"label,omitempty"`
+	Pinned        bool             `json:"pinned,omitempty"`
 }
 
 // DashboardVersionMeta extends the DashboardVersionDTO with the names
@@ -93,4 +125,6 @@ type DashboardVersionMeta struct {
 	Message       string           `json:"message"`
 	Data          *simplejson.Json `json:"data"`
 	CreatedBy     string           `json:"createdBy"`
+	Label         string           `json:"label,omitempty"`
+	Pinned        bool             `json:"pinned,omitempty"`
 }
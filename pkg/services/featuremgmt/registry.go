@@ -1673,6 +1673,13 @@ var (
 			Owner:        grafanaAlertingSquad,
 			FrontendOnly: true,
 		},
+		{
+			Name:         "kubernetesLegacySearch",
+			Description:  "Serve the legacy search results from the dashboards API search, so the frontend can migrate to it gradually",
+			Stage:        FeatureStageExperimental,
+			Owner:        grafanaSearchAndStorageSquad,
+			HideFromDocs: true,
+		},
 	}
 )
 
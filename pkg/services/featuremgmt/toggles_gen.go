@@ -902,4 +902,8 @@ const (
 	// FlagAlertingNotificationsStepMode
 	// Enables simplified step mode in the notifications section
 	FlagAlertingNotificationsStepMode = "alertingNotificationsStepMode"
+
+	// FlagKubernetesLegacySearch
+	// Serve the legacy search results from the dashboards API search, so the frontend can migrate to it gradually
+	FlagKubernetesLegacySearch = "kubernetesLegacySearch"
 )
@@ -76,6 +76,11 @@ type API struct {
 	ConditionValidator   *eval.ConditionValidator
 	FeatureManager       featuremgmt.FeatureToggles
 	Historian            Historian
+	HistoryRetention     HistoryRetention
+	HistoryStream        HistoryStream
+	HistoryImporter      HistoryImporter
+	HistoryAnnotations   HistoryAnnotations
+	HistoryRuleVersions  HistoryRuleVersions
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
 
@@ -168,13 +173,21 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		xact:                api.TransactionManager,
 		// XXX: Used to flag recording rules, remove when FT is removed
 		featureManager: api.FeatureManager,
 	}), m)
 
 	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
-		logger: logger,
-		hist:   api.Historian,
+		logger:      logger,
+		hist:        api.Historian,
+		retention:   api.HistoryRetention,
+		stream:      api.HistoryStream,
+		importer:    api.HistoryImporter,
+		authz:       ruleAuthzService,
+		rules:       api.RuleStore,
+		annotations: api.HistoryAnnotations,
+		versions:    api.HistoryRuleVersions,
 	}), m)
 
 	api.RegisterNotificationsApiEndpoints(NewNotificationsApi(&NotificationSrv{
@@ -31,6 +31,7 @@ type ProvisioningSrv struct {
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
 	folderSvc           folder.Service
+	xact                provisioning.TransactionManager
 
 	// XXX: Used to flag recording rules, remove when FT is removed
 	featureManager featuremgmt.FeatureToggles
@@ -429,6 +430,13 @@ func (srv *ProvisioningSrv) RouteGetAlertRulesExport(c *contextmodel.ReqContext)
 	folderUIDs := c.QueryStrings("folderUid")
 	group := c.Query("group")
 	uid := c.Query("ruleUid")
+	matchers, err := getMatchersFromQuery(c.Req.Form)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if len(matchers) > 0 && (uid != "" || group != "") {
+		return ErrResp(http.StatusBadRequest, errors.New("matchers should not be specified when a single rule or group is requested"), "")
+	}
 	if uid != "" {
 		if group != "" || len(folderUIDs) > 0 {
 			return ErrResp(http.StatusBadRequest, errors.New("group and folder should not be specified when a single rule is requested"), "")
@@ -449,6 +457,7 @@ func (srv *ProvisioningSrv) RouteGetAlertRulesExport(c *contextmodel.ReqContext)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rules", err)
 	}
+	groupsWithFullpath = filterRuleGroupsByMatchers(groupsWithFullpath, matchers)
 	if len(groupsWithFullpath) == 0 {
 		return response.Empty(http.StatusNotFound)
 	}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RoutePostAlertRulesImport imports alert rule groups exported in provisioning file format, possibly from another Grafana instance.
+// Folders and data sources are mapped to the ones of the current organization according to the mappings of the request.
+// The import is rejected if it conflicts with existing rules. Otherwise, every imported group replaces the group with the same name in the target folder.
+// The groups are imported in a single transaction, a group that fails to be imported leaves all the groups unchanged.
+func (srv *ProvisioningSrv) RoutePostAlertRulesImport(c *contextmodel.ReqContext, body definitions.AlertRulesImport, dryRun bool) response.Response {
+	ctx := c.Req.Context()
+	result := definitions.AlertRulesImportResult{
+		DryRun: dryRun,
+		Groups: make([]definitions.AlertRuleGroupImportResult, 0, len(body.Groups)),
+	}
+
+	groups := make([]alerting_models.AlertRuleGroup, 0, len(body.Groups))
+	seenGroups := make(map[alerting_models.AlertRuleGroupKey]struct{}, len(body.Groups))
+	// newRules are the UIDs of the imported rules that do not exist yet. Replacing a group only updates the rules
+	// with a UID, so they are created before their group is replaced.
+	newRules := make(map[string]struct{})
+	for _, g := range body.Groups {
+		conflict := func(reason definitions.AlertRuleImportConflictReason, ruleUID, msg string) {
+			result.Conflicts = append(result.Conflicts, definitions.AlertRuleImportConflict{
+				Reason:  reason,
+				Folder:  g.Folder,
+				Group:   g.Name,
+				RuleUID: ruleUID,
+				Message: msg,
+			})
+		}
+
+		folderUID, ok := body.FolderMapping[g.Folder]
+		if !ok || folderUID == "" {
+			conflict(definitions.AlertRuleImportConflictFolderNotMapped, "", fmt.Sprintf("folder '%s' is not mapped to a folder of this organization", g.Folder))
+			continue
+		}
+		key := alerting_models.AlertRuleGroupKey{OrgID: c.SignedInUser.GetOrgID(), NamespaceUID: folderUID, RuleGroup: g.Name}
+		if _, ok := seenGroups[key]; ok {
+			conflict(definitions.AlertRuleImportConflictDuplicateGroup, "", fmt.Sprintf("group '%s' is imported into folder '%s' more than once", g.Name, folderUID))
+			continue
+		}
+		seenGroups[key] = struct{}{}
+
+		group, err := AlertRuleGroupFromAlertRuleGroupExport(g, folderUID, body.DatasourceMapping)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid group '%s' in folder '%s': %w", g.Name, g.Folder, err), "")
+		}
+
+		groupResult := definitions.AlertRuleGroupImportResult{
+			Folder:    g.Folder,
+			FolderUID: folderUID,
+			Name:      g.Name,
+			Rules:     make([]string, 0, len(group.Rules)),
+		}
+		imported := make(map[string]struct{}, len(group.Rules))
+		for i := range group.Rules {
+			if group.Rules[i].UID == "" {
+				group.Rules[i].UID = util.GenerateShortUID()
+			}
+			rule := group.Rules[i]
+			imported[rule.UID] = struct{}{}
+			groupResult.Rules = append(groupResult.Rules, rule.UID)
+
+			existing, _, err := srv.alertRules.GetAlertRule(ctx, c.SignedInUser, rule.UID)
+			if err != nil {
+				if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
+					newRules[rule.UID] = struct{}{}
+					continue
+				}
+				return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rule", err)
+			}
+			if existing.NamespaceUID != folderUID || existing.RuleGroup != g.Name {
+				conflict(definitions.AlertRuleImportConflictRuleUIDInUse, rule.UID,
+					fmt.Sprintf("rule UID is used by a rule of group '%s' in folder '%s'", existing.RuleGroup, existing.NamespaceUID))
+			}
+		}
+
+		current, err := srv.alertRules.GetRuleGroup(ctx, c.SignedInUser, folderUID, g.Name)
+		if err != nil {
+			if !errors.Is(err, alerting_models.ErrAlertRuleGroupNotFound) {
+				return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rule group", err)
+			}
+			groupResult.Created = true
+		}
+		for _, rule := range current.Rules {
+			if _, ok := imported[rule.UID]; !ok {
+				groupResult.Deleted = append(groupResult.Deleted, rule.UID)
+			}
+		}
+
+		groups = append(groups, group)
+		result.Groups = append(result.Groups, groupResult)
+	}
+
+	if len(result.Conflicts) > 0 {
+		return response.JSON(http.StatusConflict, result)
+	}
+	if dryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	provenance := determineProvenance(c)
+	// failed is the group being imported when the transaction fails
+	var failed alerting_models.AlertRuleGroup
+	err := srv.xact.InTransaction(ctx, func(ctx context.Context) error {
+		for _, group := range groups {
+			failed = group
+			for _, rule := range group.Rules {
+				if _, ok := newRules[rule.UID]; !ok {
+					continue
+				}
+				rule.OrgID = c.SignedInUser.GetOrgID()
+				if _, err := srv.alertRules.CreateAlertRule(ctx, c.SignedInUser, rule, alerting_models.Provenance(provenance)); err != nil {
+					return err
+				}
+			}
+			if err := srv.alertRules.ReplaceRuleGroup(ctx, c.SignedInUser, group, alerting_models.Provenance(provenance)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return importGroupErrResp(failed, err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func importGroupErrResp(group alerting_models.AlertRuleGroup, err error) response.Response {
+	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) || errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "failed to import group '%s' into folder '%s'", group.Title, group.FolderUID)
+	}
+	if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "failed to import group '%s' into folder '%s'", group.Title, group.FolderUID)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, fmt.Sprintf("failed to import group '%s' into folder '%s'", group.Title, group.FolderUID), err)
+}
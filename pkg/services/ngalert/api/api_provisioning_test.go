@@ -1517,6 +1517,136 @@ func TestProvisioningApi(t *testing.T) {
 	})
 }
 
+func TestProvisioningApiAlertRulesImport(t *testing.T) {
+	exportGroup := func(t *testing.T, sut ProvisioningSrv, rc contextmodel.ReqContext) definitions.AlertRuleGroupExport {
+		t.Helper()
+		group, err := sut.alertRules.GetAlertRuleGroupWithFolderFullpath(context.Background(), rc.SignedInUser, "folder-uid", "my-cool-group")
+		require.NoError(t, err)
+		e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath([]models.AlertRuleGroupWithFolderFullpath{group})
+		require.NoError(t, err)
+		require.Len(t, e.Groups, 1)
+		return e.Groups[0]
+	}
+
+	t.Run("reports folders that are not mapped", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		insertRule(t, sut, createTestAlertRule("rule", 1))
+
+		response := sut.RoutePostAlertRulesImport(&rc, definitions.AlertRulesImport{
+			Groups: []definitions.AlertRuleGroupExport{exportGroup(t, sut, rc)},
+		}, true)
+
+		require.Equal(t, 409, response.Status())
+		result := definitions.AlertRulesImportResult{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Conflicts, 1)
+		require.Equal(t, definitions.AlertRuleImportConflictFolderNotMapped, result.Conflicts[0].Reason)
+	})
+
+	t.Run("reports rule UIDs used in other groups", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		insertRule(t, sut, createTestAlertRule("rule", 1))
+
+		response := sut.RoutePostAlertRulesImport(&rc, definitions.AlertRulesImport{
+			Groups:        []definitions.AlertRuleGroupExport{exportGroup(t, sut, rc)},
+			FolderMapping: map[string]string{"Folder Title": "folder-uid2"},
+		}, false)
+
+		require.Equal(t, 409, response.Status())
+		result := definitions.AlertRulesImportResult{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Conflicts, 1)
+		require.Equal(t, definitions.AlertRuleImportConflictRuleUIDInUse, result.Conflicts[0].Reason)
+		require.Equal(t, "rule", result.Conflicts[0].RuleUID)
+	})
+
+	t.Run("imports groups into mapped folders", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		insertRule(t, sut, createTestAlertRule("rule", 1))
+		group := exportGroup(t, sut, rc)
+		group.Rules[0].UID = ""
+		group.Rules[0].Data[0].DatasourceUID = "source-ds"
+		group.Rules[0].Data[0].RelativeTimeRange = definitions.RelativeTimeRangeExport{FromSeconds: 600}
+		body := definitions.AlertRulesImport{
+			Groups:            []definitions.AlertRuleGroupExport{group},
+			FolderMapping:     map[string]string{"Folder Title": "folder-uid2"},
+			DatasourceMapping: map[string]string{"source-ds": "target-ds"},
+		}
+
+		t.Run("dry run does not change rules", func(t *testing.T) {
+			response := sut.RoutePostAlertRulesImport(&rc, body, true)
+
+			require.Equal(t, 200, response.Status())
+			result := definitions.AlertRulesImportResult{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.True(t, result.DryRun)
+			require.Len(t, result.Groups, 1)
+			require.True(t, result.Groups[0].Created)
+			require.Equal(t, 404, sut.RouteGetAlertRuleGroup(&rc, "folder-uid2", "my-cool-group").Status())
+		})
+
+		t.Run("import creates the group", func(t *testing.T) {
+			response := sut.RoutePostAlertRulesImport(&rc, body, false)
+
+			require.Equal(t, 200, response.Status())
+			imported, err := sut.alertRules.GetRuleGroup(context.Background(), rc.SignedInUser, "folder-uid2", "my-cool-group")
+			require.NoError(t, err)
+			require.Len(t, imported.Rules, 1)
+			require.NotEqual(t, "rule", imported.Rules[0].UID)
+			require.Equal(t, "target-ds", imported.Rules[0].Data[0].DatasourceUID)
+		})
+
+		t.Run("import keeps the UIDs of new rules", func(t *testing.T) {
+			body := body
+			body.Groups = []definitions.AlertRuleGroupExport{group}
+			body.Groups[0].Name = "imported-group"
+			body.Groups[0].Rules = []definitions.AlertRuleExport{group.Rules[0]}
+			body.Groups[0].Rules[0].UID = "imported-uid"
+			body.Groups[0].Rules[0].Title = "imported rule"
+			response := sut.RoutePostAlertRulesImport(&rc, body, false)
+
+			require.Equal(t, 200, response.Status())
+			imported, err := sut.alertRules.GetRuleGroup(context.Background(), rc.SignedInUser, "folder-uid2", "imported-group")
+			require.NoError(t, err)
+			require.Len(t, imported.Rules, 1)
+			require.Equal(t, "imported-uid", imported.Rules[0].UID)
+		})
+	})
+
+	t.Run("a group that fails to be imported leaves the other groups unchanged", func(t *testing.T) {
+		env := createTestEnv(t, testConfig)
+		env.xact = env.store.SQLStore
+		sut := createProvisioningSrvSutFromEnv(t, &env)
+		rc := createTestRequestCtx()
+		insertRule(t, sut, createTestAlertRule("rule", 1))
+		first := exportGroup(t, sut, rc)
+		first.Rules[0].Title = "renamed rule"
+		first.Rules[0].Data[0].RelativeTimeRange = definitions.RelativeTimeRangeExport{FromSeconds: 600}
+		// the new rule of the second group has the title of the rule of the first group in the same folder
+		second := exportGroup(t, sut, rc)
+		second.Name = "second-group"
+		second.Rules[0].UID = "new-rule"
+		second.Rules[0].Title = "renamed rule"
+		second.Rules[0].Data[0].RelativeTimeRange = definitions.RelativeTimeRangeExport{FromSeconds: 600}
+
+		response := sut.RoutePostAlertRulesImport(&rc, definitions.AlertRulesImport{
+			Groups:        []definitions.AlertRuleGroupExport{first, second},
+			FolderMapping: map[string]string{"Folder Title": "folder-uid"},
+		}, false)
+
+		require.Equal(t, 400, response.Status())
+		group, err := sut.alertRules.GetRuleGroup(context.Background(), rc.SignedInUser, "folder-uid", "my-cool-group")
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 1)
+		require.Equal(t, "rule", group.Rules[0].Title)
+		_, _, err = sut.alertRules.GetAlertRule(context.Background(), rc.SignedInUser, "new-rule")
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+}
+
 func TestProvisioningApiContactPointExport(t *testing.T) {
 	createTestEnv := func(t *testing.T, testConfig string) testEnvironment {
 		env := createTestEnv(t, testConfig)
@@ -1956,6 +2086,7 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		muteTimings:         provisioning.NewMuteTimingService(configStore, env.prov, env.xact, env.log, env.store),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.folderService, env.quotas, env.xact, 60, 10, 100, env.log, &provisioning.NotificationSettingsValidatorProviderFake{}, env.rulesAuthz),
 		folderSvc:           env.folderService,
+		xact:                env.xact,
 		featureManager:      env.features,
 	}
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/prometheus/alertmanager/pkg/labels"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 
@@ -53,6 +55,13 @@ func (srv RulerSrv) ExportRules(c *contextmodel.ReqContext) response.Response {
 	folderUIDs := c.QueryStrings("folderUid")
 	group := c.Query("group")
 	uid := c.Query("ruleUid")
+	matchers, err := getMatchersFromQuery(c.Req.Form)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if len(matchers) > 0 && (uid != "" || group != "") {
+		return ErrResp(http.StatusBadRequest, errors.New("matchers should not be specified when a single rule or group is requested"), "")
+	}
 
 	var groups []ngmodels.AlertRuleGroupWithFolderFullpath
 	if uid != "" {
@@ -81,13 +90,13 @@ func (srv RulerSrv) ExportRules(c *contextmodel.ReqContext) response.Response {
 		}
 		groups = []ngmodels.AlertRuleGroupWithFolderFullpath{rulesGroup}
 	} else {
-		var err error
 		groups, err = srv.getRulesWithFolderFullPathInFolders(c, folderUIDs)
 		if err != nil {
 			return errorToResponse(err)
 		}
 	}
 
+	groups = filterRuleGroupsByMatchers(groups, matchers)
 	if len(groups) == 0 {
 		return response.Empty(http.StatusNotFound)
 	}
@@ -175,3 +184,27 @@ func (srv RulerSrv) getRulesWithFolderFullPathInFolders(c *contextmodel.ReqConte
 	}
 	return result, nil
 }
+
+// filterRuleGroupsByMatchers keeps only rules with labels that match all matchers. Groups left without rules are dropped.
+func filterRuleGroupsByMatchers(groups []ngmodels.AlertRuleGroupWithFolderFullpath, matchers labels.Matchers) []ngmodels.AlertRuleGroupWithFolderFullpath {
+	if len(matchers) == 0 {
+		return groups
+	}
+	result := make([]ngmodels.AlertRuleGroupWithFolderFullpath, 0, len(groups))
+	for _, group := range groups {
+		rules := make([]ngmodels.AlertRule, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			if matchersMatch(matchers, rule.Labels) {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		filtered := *group.AlertRuleGroup
+		filtered.Rules = rules
+		group.AlertRuleGroup = &filtered
+		result = append(result, group)
+	}
+	return result
+}
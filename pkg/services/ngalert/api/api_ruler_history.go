@@ -2,29 +2,298 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 )
 
 type Historian interface {
 	Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
 }
 
+// HistoryRetention applies state history retention on demand.
+type HistoryRetention interface {
+	Compact(ctx context.Context, orgID int64, dryRun bool) (historian.CompactionResult, error)
+	PurgeDeletedRules(ctx context.Context, orgID int64, dryRun bool) (historian.PurgeResult, error)
+}
+
+// HistoryStream broadcasts state transitions to subscribers as they happen.
+type HistoryStream interface {
+	Subscribe(orgID int64, filter historian.StreamFilter) (<-chan historian.StreamEvent, func())
+}
+
+// HistoryAnnotations finds the dashboard annotations that are returned with the state history of the rules linked to the dashboard.
+type HistoryAnnotations interface {
+	Find(ctx context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error)
+}
+
+// HistoryRuleVersions reads the saved versions of alert rules, to split their state history by version.
+type HistoryRuleVersions interface {
+	GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]models.AlertRuleVersion, error)
+}
+
+// HistoryImporter imports state history recorded elsewhere into the state history backend.
+type HistoryImporter interface {
+	Import(ctx context.Context, source historian.ImportSource, opts historian.ImportOptions) (historian.ImportResult, error)
+	LokiSource(cfg historian.LokiConfig) historian.ImportSource
+}
+
 type HistorySrv struct {
-	logger log.Logger
-	hist   Historian
+	logger    log.Logger
+	hist      Historian
+	retention HistoryRetention
+	stream    HistoryStream
+	importer  HistoryImporter
+	authz     RuleAccessControlService
+	// rules and annotations are used to join the state history with the annotations of linked dashboards
+	rules       RuleStore
+	annotations HistoryAnnotations
+	// versions are used to split the state history of a rule by rule version
+	versions HistoryRuleVersions
 }
 
-const labelQueryPrefix = "labels_"
+const (
+	labelQueryPrefix = "labels_"
+
+	// stateHistoryStreamHeartbeat is the interval of comments sent to keep idle streams open through proxies.
+	stateHistoryStreamHeartbeat = 30 * time.Second
+
+	// stateHistorySummaryDefaultBuckets is the number of intervals of a summary when no interval is requested.
+	stateHistorySummaryDefaultBuckets = 100
+
+	// stateHistoryAnnotationsDefaultLimit is the number of annotations returned with the state history when no limit is requested.
+	stateHistoryAnnotationsDefaultLimit = 100
+
+	// stateHistoryMaxPageSize is the maximum number of transitions of a page of the state history.
+	stateHistoryMaxPageSize = 1000
+)
+
+// instanceFingerprintRegex matches the fingerprints of alert instances recorded in the state history.
+var instanceFingerprintRegex = regexp.MustCompile(`^[0-9a-f]{16}$`)
 
 func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) response.Response {
+	query := stateHistoryQueryFromRequest(c)
+	if query.PageSize < 0 || query.PageSize > stateHistoryMaxPageSize {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("pageSize must be between 0 and %d", stateHistoryMaxPageSize), "")
+	}
+	if !c.QueryBool("annotations") {
+		frame, err := srv.hist.Query(c.Req.Context(), query)
+		if err != nil {
+			return stateHistoryQueryError(err)
+		}
+		return response.JSON(http.StatusOK, frame)
+	}
+
+	linked, err := srv.linkedAnnotations(c.Req.Context(), query)
+	if err != nil {
+		if errors.Is(err, errInvalidAnnotationsQuery) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if errors.Is(err, models.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return errorToResponse(err)
+	}
+	frame, err := srv.hist.Query(c.Req.Context(), query)
+	if err != nil {
+		return stateHistoryQueryError(err)
+	}
+	return response.JSON(http.StatusOK, apimodels.StateHistoryWithAnnotations{
+		History:     frame,
+		Annotations: linked,
+	})
+}
+
+// stateHistoryQueryError returns the response of an error of the state history backend.
+func stateHistoryQueryError(err error) response.Response {
+	if errors.Is(err, historian.ErrInvalidContinueToken) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
+var errInvalidAnnotationsQuery = errors.New("annotations require a rule UID or a dashboard UID")
+
+// linkedAnnotations returns the annotations of the dashboard and panel of the state history query, either the ones
+// of the query, or the ones the rule of the query is linked to. Every annotation lists the rules linked to its panel.
+// Alert annotations are not returned, they are the state history itself when it is stored in annotations.
+func (srv *HistorySrv) linkedAnnotations(ctx context.Context, query models.HistoryQuery) ([]apimodels.StateHistoryAnnotation, error) {
+	if srv.rules == nil || srv.annotations == nil {
+		return nil, errors.New("annotations are not available")
+	}
+	if query.RuleUID == "" && query.DashboardUID == "" {
+		return nil, errInvalidAnnotationsQuery
+	}
+
+	var rules []*models.AlertRule
+	dashboardUID, panelID := query.DashboardUID, query.PanelID
+	if query.RuleUID != "" {
+		rule, err := srv.rules.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{OrgID: query.OrgID, UID: query.RuleUID})
+		if err != nil {
+			return nil, err
+		}
+		if err := srv.authz.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule); err != nil {
+			return nil, err
+		}
+		if rule.GetDashboardUID() == "" {
+			// the rule is not linked to a dashboard
+			return []apimodels.StateHistoryAnnotation{}, nil
+		}
+		if dashboardUID == "" {
+			dashboardUID = rule.GetDashboardUID()
+			if rule.PanelID != nil {
+				panelID = *rule.PanelID
+			}
+		}
+		rules = []*models.AlertRule{rule}
+	} else {
+		var err error
+		if rules, err = srv.dashboardRules(ctx, query, dashboardUID, panelID); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := int64(query.Limit)
+	if limit <= 0 {
+		limit = stateHistoryAnnotationsDefaultLimit
+	}
+	items, err := srv.annotations.Find(ctx, &annotations.ItemQuery{
+		OrgID:        query.OrgID,
+		DashboardUID: dashboardUID,
+		PanelID:      panelID,
+		From:         query.From.UnixMilli(),
+		To:           query.To.UnixMilli(),
+		Type:         "annotation",
+		Limit:        limit,
+		SignedInUser: query.SignedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]apimodels.StateHistoryAnnotation, 0, len(items))
+	for _, item := range items {
+		res = append(res, apimodels.StateHistoryAnnotation{
+			ID:           item.ID,
+			DashboardUID: dashboardUID,
+			PanelID:      item.PanelID,
+			Time:         item.Time,
+			TimeEnd:      item.TimeEnd,
+			Text:         item.Text,
+			Tags:         item.Tags,
+			RuleUIDs:     linkedRuleUIDs(rules, item.PanelID),
+		})
+	}
+	return res, nil
+}
+
+// dashboardRules returns the rules linked to the dashboard, or to its panel when panelID is set, that the user can access.
+func (srv *HistorySrv) dashboardRules(ctx context.Context, query models.HistoryQuery, dashboardUID string, panelID int64) ([]*models.AlertRule, error) {
+	all, err := srv.rules.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: query.OrgID, DashboardUID: dashboardUID, PanelID: panelID})
+	if err != nil {
+		return nil, err
+	}
+	var rules []*models.AlertRule
+	access := make(map[string]bool)
+	for _, rule := range all {
+		allowed, checked := access[rule.NamespaceUID]
+		if !checked {
+			err := srv.authz.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
+			if err != nil && !errors.Is(err, authz.ErrAuthorizationBase) {
+				return nil, err
+			}
+			allowed = err == nil
+			access[rule.NamespaceUID] = allowed
+		}
+		if allowed {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// linkedRuleUIDs returns the UIDs of the rules linked to the panel. Annotations of the whole dashboard have no panel,
+// they are linked to all rules of the dashboard.
+func linkedRuleUIDs(rules []*models.AlertRule, panelID int64) []string {
+	uids := []string{}
+	for _, rule := range rules {
+		if panelID == 0 || rule.PanelID == nil || *rule.PanelID == panelID {
+			uids = append(uids, rule.UID)
+		}
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+// RouteQueryInstanceStateHistory returns the state transitions of the alert instance with the given fingerprint.
+func (srv *HistorySrv) RouteQueryInstanceStateHistory(c *contextmodel.ReqContext, fingerprint string) response.Response {
+	if !instanceFingerprintRegex.MatchString(fingerprint) {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid fingerprint %q, expected 16 hexadecimal characters", fingerprint), "")
+	}
+	query := stateHistoryQueryFromRequest(c)
+	query.Fingerprint = fingerprint
+
+	frame, err := srv.hist.Query(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	transitions, err := historian.InstanceHistory(frame, fingerprint)
+	if err != nil {
+		if errors.Is(err, historian.ErrInstanceHistoryNotSupported) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to read the state history of the alert instance")
+	}
+	return response.JSON(http.StatusOK, toStateHistoryInstance(fingerprint, transitions))
+}
+
+func toStateHistoryInstance(fingerprint string, transitions []historian.InstanceTransition) apimodels.StateHistoryInstance {
+	res := apimodels.StateHistoryInstance{
+		Fingerprint: fingerprint,
+		Labels:      map[string]string{},
+		Transitions: make([]apimodels.StateHistoryInstanceTransition, 0, len(transitions)),
+	}
+	for _, t := range transitions {
+		if t.Labels != nil {
+			res.Labels = t.Labels
+		}
+		res.Transitions = append(res.Transitions, apimodels.StateHistoryInstanceTransition{
+			RuleUID:   t.RuleUID,
+			RuleTitle: t.RuleTitle,
+			Previous:  t.Previous,
+			Current:   t.Current,
+			Values:    t.Values,
+			Error:     t.Error,
+			Timestamp: t.Time,
+		})
+	}
+	return res
+}
+
+func stateHistoryQueryFromRequest(c *contextmodel.ReqContext) models.HistoryQuery {
 	from := c.QueryInt64("from")
 	to := c.QueryInt64("to")
 	limit := c.QueryInt("limit")
@@ -39,7 +308,7 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		}
 	}
 
-	query := models.HistoryQuery{
+	return models.HistoryQuery{
 		RuleUID:      ruleUID,
 		OrgID:        c.SignedInUser.GetOrgID(),
 		DashboardUID: dashUID,
@@ -48,11 +317,680 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		From:         time.Unix(from, 0),
 		To:           time.Unix(to, 0),
 		Limit:        limit,
+		PageSize:     c.QueryInt("pageSize"),
+		Continue:     c.Query("continue"),
 		Labels:       labels,
 	}
+}
+
+func (srv *HistorySrv) RouteSummarizeStateHistory(c *contextmodel.ReqContext) response.Response {
+	if c.Query("from") == "" || c.Query("to") == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("from and to are required"), "")
+	}
+	query := stateHistoryQueryFromRequest(c)
+
+	interval := time.Duration(c.QueryInt64("interval")) * time.Second
+	if interval == 0 {
+		interval = (query.To.Sub(query.From) / stateHistorySummaryDefaultBuckets).Truncate(time.Second)
+		if interval < time.Second {
+			interval = time.Second
+		}
+	}
+	summaryQuery := historian.SummaryQuery{
+		From:     query.From,
+		To:       query.To,
+		Interval: interval,
+		GroupBy:  c.Query("groupBy"),
+	}
+	if err := summaryQuery.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
 	frame, err := srv.hist.Query(c.Req.Context(), query)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	summary, err := historian.Summarize(frame, summaryQuery)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to summarize state history")
+	}
+	return response.JSON(http.StatusOK, toStateHistorySummary(summaryQuery, summary))
+}
+
+func toStateHistorySummary(q historian.SummaryQuery, s *historian.Summary) apimodels.StateHistorySummary {
+	res := apimodels.StateHistorySummary{
+		From:     q.From,
+		To:       q.To,
+		Interval: int64(q.Interval / time.Second),
+		GroupBy:  q.GroupBy,
+		Groups:   make([]apimodels.StateHistorySummaryGroup, 0, len(s.Groups)),
+	}
+	for _, g := range s.Groups {
+		group := apimodels.StateHistorySummaryGroup{
+			Key:     g.Key,
+			Total:   g.Total,
+			Buckets: make([]apimodels.StateHistorySummaryBucket, 0, len(g.Buckets)),
+		}
+		for _, b := range g.Buckets {
+			group.Buckets = append(group.Buckets, apimodels.StateHistorySummaryBucket{
+				Time:   b.Time,
+				Counts: b.Counts,
+			})
+		}
+		res.Groups = append(res.Groups, group)
+	}
+	return res
+}
+
+// stateHistoryUptimeGroupByRuleGroup aggregates the uptime of the rules by rule group.
+const stateHistoryUptimeGroupByRuleGroup = "ruleGroup"
+
+func (srv *HistorySrv) RouteUptimeStateHistory(c *contextmodel.ReqContext) response.Response {
+	if c.Query("from") == "" || c.Query("to") == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("from and to are required"), "")
+	}
+	query := stateHistoryQueryFromRequest(c)
+	uptimeQuery := historian.UptimeQuery{
+		From:   query.From,
+		To:     query.To,
+		NoData: historian.UptimeTreatment(c.Query("noData")),
+		Error:  historian.UptimeTreatment(c.Query("error")),
+	}
+	if err := uptimeQuery.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	uptimeQuery = uptimeQuery.WithDefaults()
+	groupBy := c.Query("groupBy")
+	if groupBy != "" && groupBy != historian.SummaryGroupByRule && groupBy != stateHistoryUptimeGroupByRuleGroup {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown groupBy %q, must be %s or %s", groupBy, historian.SummaryGroupByRule, stateHistoryUptimeGroupByRuleGroup), "")
+	}
+
+	frame, err := srv.hist.Query(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	uptimes, err := historian.Uptime(frame, uptimeQuery)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to compute the uptime of the rules")
+	}
+
+	res := apimodels.StateHistoryUptime{
+		From:    uptimeQuery.From,
+		To:      uptimeQuery.To,
+		NoData:  string(uptimeQuery.NoData),
+		Error:   string(uptimeQuery.Error),
+		GroupBy: groupBy,
+		Rules:   make([]apimodels.StateHistoryRuleUptime, 0, len(uptimes)),
+	}
+	for _, u := range uptimes {
+		res.Rules = append(res.Rules, apimodels.StateHistoryRuleUptime{
+			RuleUID:                  u.RuleUID,
+			StateHistoryUptimeValues: toStateHistoryUptimeValues(u),
+		})
+	}
+	if groupBy == stateHistoryUptimeGroupByRuleGroup {
+		res.Groups, err = srv.ruleGroupUptimes(c.Req.Context(), query.OrgID, uptimes)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to group the uptime of the rules")
+		}
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
+// ruleGroupUptimes sums the uptime of the rules of every rule group.
+// The rules that no longer exist are left out of the groups.
+func (srv *HistorySrv) ruleGroupUptimes(ctx context.Context, orgID int64, uptimes []historian.RuleUptime) ([]apimodels.StateHistoryGroupUptime, error) {
+	if srv.rules == nil {
+		return nil, errors.New("rule groups are not available")
+	}
+	res := []apimodels.StateHistoryGroupUptime{}
+	if len(uptimes) == 0 {
+		return res, nil
+	}
+	uids := make([]string, 0, len(uptimes))
+	for _, u := range uptimes {
+		uids = append(uids, u.RuleUID)
+	}
+	rules, err := srv.rules.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID, RuleUIDs: uids})
+	if err != nil {
+		return nil, err
+	}
+	groupOf := make(map[string]models.AlertRuleGroupKey, len(rules))
+	for _, rule := range rules {
+		groupOf[rule.UID] = rule.GetGroupKey()
+	}
+
+	sums := map[models.AlertRuleGroupKey]*historian.RuleUptime{}
+	ruleUIDs := map[models.AlertRuleGroupKey][]string{}
+	for _, u := range uptimes {
+		key, ok := groupOf[u.RuleUID]
+		if !ok {
+			continue
+		}
+		sum, ok := sums[key]
+		if !ok {
+			sum = &historian.RuleUptime{}
+			sums[key] = sum
+		}
+		sum.Up += u.Up
+		sum.Down += u.Down
+		sum.Ignored += u.Ignored
+		ruleUIDs[key] = append(ruleUIDs[key], u.RuleUID)
+	}
+	for key, sum := range sums {
+		res = append(res, apimodels.StateHistoryGroupUptime{
+			FolderUID:                key.NamespaceUID,
+			RuleGroup:                key.RuleGroup,
+			RuleUIDs:                 ruleUIDs[key],
+			StateHistoryUptimeValues: toStateHistoryUptimeValues(*sum),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].FolderUID != res[j].FolderUID {
+			return res[i].FolderUID < res[j].FolderUID
+		}
+		return res[i].RuleGroup < res[j].RuleGroup
+	})
+	return res, nil
+}
+
+func toStateHistoryUptimeValues(u historian.RuleUptime) apimodels.StateHistoryUptimeValues {
+	res := apimodels.StateHistoryUptimeValues{
+		UpSeconds:      u.Up.Seconds(),
+		DownSeconds:    u.Down.Seconds(),
+		IgnoredSeconds: u.Ignored.Seconds(),
+	}
+	if percent, ok := u.Percent(); ok {
+		res.Uptime = &percent
+	}
+	return res
+}
+
+// RouteRuleVersionsStateHistory splits the state history of a rule by the versions of the rule and counts the
+// transitions of every version. With a version, only that version is returned, with its state history.
+// RoutePanelStateHistory merges the state history of the rules linked to a dashboard panel into a single timeline
+// frame, with the state of every rule from each time a state changes.
+func (srv *HistorySrv) RoutePanelStateHistory(c *contextmodel.ReqContext) response.Response {
+	if c.Query("dashboardUID") == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("dashboardUID is required"), "")
+	}
+	if c.Query("from") == "" || c.Query("to") == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("from and to are required"), "")
+	}
+	if srv.rules == nil {
+		return ErrResp(http.StatusInternalServerError, errors.New("the rules of dashboards are not available"), "")
+	}
+	query := stateHistoryQueryFromRequest(c)
+	if !query.To.After(query.From) {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: the end of the time range must be after the start", historian.ErrInvalidTimelineQuery), "")
+	}
+
+	ctx := c.Req.Context()
+	rules, err := srv.dashboardRules(ctx, query, query.DashboardUID, query.PanelID)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Title != rules[j].Title {
+			return rules[i].Title < rules[j].Title
+		}
+		return rules[i].UID < rules[j].UID
+	})
+
+	timelineRules := make([]historian.TimelineRule, 0, len(rules))
+	histories := make(map[string]*data.Frame, len(rules))
+	for _, rule := range rules {
+		// the backends filter the state history by rule, the transitions of a frame are of a single rule
+		ruleQuery := query
+		ruleQuery.RuleUID, ruleQuery.DashboardUID, ruleQuery.PanelID = rule.UID, "", 0
+		ruleQuery.PageSize, ruleQuery.Continue = 0, ""
+		frame, err := srv.hist.Query(ctx, ruleQuery)
+		if err != nil {
+			return stateHistoryQueryError(err)
+		}
+		histories[rule.UID] = frame
+		timelineRules = append(timelineRules, historian.TimelineRule{UID: rule.UID, Title: rule.Title})
+	}
+	frame, err := historian.Timeline(histories, timelineRules, query.From, query.To)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to merge the state history of the rules of the panel")
+	}
 	return response.JSON(http.StatusOK, frame)
 }
+
+func (srv *HistorySrv) RouteRuleVersionsStateHistory(c *contextmodel.ReqContext) response.Response {
+	if srv.rules == nil || srv.versions == nil {
+		return ErrResp(http.StatusNotFound, errors.New("rule versions are not available"), "")
+	}
+	ruleUID := c.Query("ruleUID")
+	if ruleUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("ruleUID is required"), "")
+	}
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	rule, err := srv.rules.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{OrgID: orgID, UID: ruleUID})
+	if err != nil {
+		if errors.Is(err, models.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return errorToResponse(err)
+	}
+	if err := srv.authz.AuthorizeAccessInFolder(ctx, c.SignedInUser, rule); err != nil {
+		return errorToResponse(err)
+	}
+	versions, err := srv.versions.GetAlertRuleVersions(ctx, orgID, ruleUID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to read the versions of the rule")
+	}
+	versions = withCurrentRuleVersion(rule, versions)
+
+	from, to := versions[0].Created, time.Now()
+	if c.Query("from") != "" {
+		from = time.Unix(c.QueryInt64("from"), 0)
+	}
+	if c.Query("to") != "" {
+		to = time.Unix(c.QueryInt64("to"), 0)
+	}
+	windows, err := historian.RuleVersionWindows(versions, from, to)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	version := c.QueryInt64("version")
+	if version != 0 {
+		windows = slices.DeleteFunc(windows, func(w historian.RuleVersionWindow) bool { return w.Version != version })
+		if len(windows) == 0 {
+			return ErrResp(http.StatusNotFound, fmt.Errorf("version %d of the rule was not current during the time range", version), "")
+		}
+		from, to = windows[0].From, windows[0].To
+	}
+
+	frame, err := srv.hist.Query(ctx, models.HistoryQuery{
+		RuleUID:      ruleUID,
+		OrgID:        orgID,
+		SignedInUser: c.SignedInUser,
+		From:         from,
+		To:           to,
+		Limit:        c.QueryInt("limit"),
+	})
+	if err != nil {
+		return stateHistoryQueryError(err)
+	}
+	if err := historian.CountRuleVersionTransitions(frame, windows); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to split the state history by rule version")
+	}
+
+	res := apimodels.StateHistoryRuleVersions{
+		RuleUID:  ruleUID,
+		Versions: make([]apimodels.StateHistoryRuleVersion, 0, len(windows)),
+	}
+	for _, w := range windows {
+		res.Versions = append(res.Versions, apimodels.StateHistoryRuleVersion{
+			Version:            w.Version,
+			From:               w.From,
+			To:                 w.To,
+			Current:            w.Current,
+			Transitions:        w.Transitions,
+			TransitionsPerHour: w.TransitionsPerHour(),
+			Counts:             w.Counts,
+		})
+	}
+	if version != 0 {
+		res.History = frame
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
+// withCurrentRuleVersion adds the current version of the rule to its saved versions when it is missing,
+// e.g. for the rules that were not updated since the versions are saved.
+func withCurrentRuleVersion(rule *models.AlertRule, versions []models.AlertRuleVersion) []models.AlertRuleVersion {
+	if len(versions) > 0 && versions[len(versions)-1].Version == rule.Version {
+		return versions
+	}
+	return append(versions, models.AlertRuleVersion{Version: rule.Version, Created: rule.Updated})
+}
+
+// stateHistoryAlertsDefaultLookback is how far before the evaluation times the state transitions are read by the
+// queries of the ALERTS series when no lookback is requested.
+const stateHistoryAlertsDefaultLookback = 24 * time.Hour
+
+func (srv *HistorySrv) RouteQueryStateHistoryAlerts(c *contextmodel.ReqContext) response.Response {
+	ts := time.Now()
+	if v := c.Query("time"); v != "" {
+		var err error
+		if ts, err = parsePrometheusTime(v); err != nil {
+			return stateHistoryAlertsError(apiv1.ErrBadData, fmt.Errorf("invalid time: %w", err))
+		}
+	}
+	return srv.queryStateHistoryAlerts(c, historian.AlertsQuery{Start: ts, End: ts}, parser.ValueTypeVector)
+}
+
+func (srv *HistorySrv) RouteQueryRangeStateHistoryAlerts(c *contextmodel.ReqContext) response.Response {
+	start, err := parsePrometheusTime(c.Query("start"))
+	if err != nil {
+		return stateHistoryAlertsError(apiv1.ErrBadData, fmt.Errorf("invalid start: %w", err))
+	}
+	end, err := parsePrometheusTime(c.Query("end"))
+	if err != nil {
+		return stateHistoryAlertsError(apiv1.ErrBadData, fmt.Errorf("invalid end: %w", err))
+	}
+	step, err := parsePrometheusDuration(c.Query("step"))
+	if err != nil || step <= 0 {
+		return stateHistoryAlertsError(apiv1.ErrBadData, errors.New("invalid step: must be a positive duration"))
+	}
+	return srv.queryStateHistoryAlerts(c, historian.AlertsQuery{Start: start, End: end, Step: step}, parser.ValueTypeMatrix)
+}
+
+// queryStateHistoryAlerts computes the ALERTS series of the query from the state history read from the lookback
+// before the start of the query, and returns them as Prometheus returns the result of a query.
+func (srv *HistorySrv) queryStateHistoryAlerts(c *contextmodel.ReqContext, q historian.AlertsQuery, resultType parser.ValueType) response.Response {
+	var err error
+	if q.Matchers, err = historian.ParseAlertsSelector(c.Query("query")); err != nil {
+		return stateHistoryAlertsError(apiv1.ErrBadData, err)
+	}
+	if err := q.Validate(); err != nil {
+		return stateHistoryAlertsError(apiv1.ErrBadData, err)
+	}
+	lookback := stateHistoryAlertsDefaultLookback
+	if v := c.Query("lookback"); v != "" {
+		if lookback, err = parsePrometheusDuration(v); err != nil || lookback < 0 {
+			return stateHistoryAlertsError(apiv1.ErrBadData, errors.New("invalid lookback: must be a duration"))
+		}
+	}
+
+	frame, err := srv.hist.Query(c.Req.Context(), models.HistoryQuery{
+		RuleUID:      q.RuleUID(),
+		OrgID:        c.SignedInUser.GetOrgID(),
+		SignedInUser: c.SignedInUser,
+		From:         q.Start.Add(-lookback),
+		// the transitions at the end of the range are applied
+		To:    q.End.Add(time.Second),
+		Limit: c.QueryInt("limit"),
+	})
+	if err != nil {
+		return stateHistoryAlertsError(apiv1.ErrServer, err)
+	}
+	series, err := historian.Alerts(frame, q)
+	if err != nil {
+		return stateHistoryAlertsError(apiv1.ErrServer, err)
+	}
+
+	res := apimodels.StateHistoryAlertsResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "success"},
+		Data: &apimodels.StateHistoryAlertsData{
+			ResultType: string(resultType),
+			Result:     make([]apimodels.StateHistoryAlertsSeries, 0, len(series)),
+		},
+	}
+	for _, s := range series {
+		r := apimodels.StateHistoryAlertsSeries{Metric: s.Labels}
+		for _, sample := range s.Samples {
+			r.Values = append(r.Values, apimodels.StateHistoryAlertsSample{Time: sample.Time, Value: sample.Value})
+		}
+		if resultType == parser.ValueTypeVector {
+			r.Value, r.Values = &r.Values[0], nil
+		}
+		res.Data.Result = append(res.Data.Result, r)
+	}
+	return response.JSON(res.HTTPStatusCode(), res)
+}
+
+func stateHistoryAlertsError(errorType apiv1.ErrorType, err error) response.Response {
+	res := apimodels.StateHistoryAlertsResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "error", ErrorType: errorType, Error: err.Error()},
+	}
+	return response.JSON(res.HTTPStatusCode(), res)
+}
+
+// parsePrometheusTime parses a time as the Prometheus query API, a unix timestamp in seconds or RFC 3339.
+func parsePrometheusTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, errors.New("the time is required")
+	}
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.UnixMilli(int64(seconds * 1000)), nil
+	}
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+// parsePrometheusDuration parses a duration as the Prometheus query API, seconds or a duration like 5m.
+func parsePrometheusDuration(v string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := prommodel.ParseDuration(v)
+	return time.Duration(d), err
+}
+
+func (srv *HistorySrv) RouteCompactStateHistory(c *contextmodel.ReqContext) response.Response {
+	if srv.retention == nil {
+		return ErrResp(http.StatusNotFound, errors.New("state history retention is not enabled"), "")
+	}
+	res, err := srv.retention.Compact(c.Req.Context(), c.SignedInUser.GetOrgID(), c.QueryBool("dryRun"))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to apply state history retention")
+	}
+	return response.JSON(http.StatusOK, apimodels.StateHistoryCompaction{
+		OrgID:              res.OrgID,
+		DryRun:             res.DryRun,
+		ExpiredTransitions: res.ExpiredTransitions,
+		ExcessTransitions:  res.ExcessTransitions,
+		RulesCompacted:     res.RulesCompacted,
+	})
+}
+
+func (srv *HistorySrv) RoutePurgeStateHistory(c *contextmodel.ReqContext) response.Response {
+	if srv.retention == nil {
+		return ErrResp(http.StatusNotFound, errors.New("state history retention is not enabled"), "")
+	}
+	res, err := srv.retention.PurgeDeletedRules(c.Req.Context(), c.SignedInUser.GetOrgID(), c.QueryBool("dryRun"))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to purge state history of deleted rules")
+	}
+	rules := make([]apimodels.StateHistoryDeletedRule, 0, len(res.Rules))
+	for _, r := range res.Rules {
+		rules = append(rules, apimodels.StateHistoryDeletedRule{RuleID: r.RuleID, RuleUID: r.RuleUID, Transitions: r.Transitions})
+	}
+	return response.JSON(http.StatusOK, apimodels.StateHistoryPurge{
+		OrgID:       res.OrgID,
+		DryRun:      res.DryRun,
+		Rules:       rules,
+		Transitions: res.Transitions,
+	})
+}
+
+func (srv *HistorySrv) RouteImportStateHistory(c *contextmodel.ReqContext, body apimodels.StateHistoryImport) response.Response {
+	if srv.importer == nil {
+		return ErrResp(http.StatusNotFound, errors.New("state history import is not available"), "")
+	}
+	source, err := srv.importSource(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	opts := historian.ImportOptions{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		SourceOrgID: body.SourceOrgID,
+		RuleUIDs:    body.RuleUIDs,
+		DryRun:      body.DryRun,
+	}
+	if body.From > 0 {
+		opts.From = time.Unix(body.From, 0)
+	}
+	if body.To > 0 {
+		opts.To = time.Unix(body.To, 0)
+	}
+
+	res, err := srv.importer.Import(c.Req.Context(), source, opts)
+	if err != nil {
+		if errors.Is(err, historian.ErrInvalidImport) || errors.Is(err, historian.ErrImportNotSupported) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to import state history")
+	}
+	return response.JSON(http.StatusOK, apimodels.StateHistoryImportResult{
+		DryRun:       res.DryRun,
+		Imported:     res.Imported,
+		Skipped:      res.Skipped,
+		MissingRules: res.MissingRules,
+	})
+}
+
+// importSource returns the source of the state history of the import request, either a Loki instance or exported streams.
+func (srv *HistorySrv) importSource(body apimodels.StateHistoryImport) (historian.ImportSource, error) {
+	if body.Loki != nil && len(body.Streams) > 0 {
+		return nil, errors.New("either loki or streams must be set, not both")
+	}
+	if body.Loki != nil {
+		if body.Loki.URL == "" {
+			return nil, errors.New("the URL of the Loki instance is required")
+		}
+		u, err := url.Parse(body.Loki.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid Loki URL %q", body.Loki.URL)
+		}
+		return srv.importer.LokiSource(historian.LokiConfig{
+			ReadPathURL:       u,
+			TenantID:          body.Loki.TenantID,
+			BasicAuthUser:     body.Loki.BasicAuthUser,
+			BasicAuthPassword: body.Loki.BasicAuthPassword,
+		}), nil
+	}
+	if len(body.Streams) == 0 {
+		return nil, errors.New("either loki or streams must be set")
+	}
+
+	streams := make(historian.StreamsImportSource, 0, len(body.Streams))
+	for _, s := range body.Streams {
+		stream := historian.Stream{Stream: s.Stream, Values: make([]historian.Sample, 0, len(s.Values))}
+		for _, v := range s.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q: %w", v[0], err)
+			}
+			stream.Values = append(stream.Values, historian.Sample{T: time.Unix(0, ns), V: v[1]})
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+func (srv *HistorySrv) RouteStreamStateHistory(c *contextmodel.ReqContext) response.Response {
+	if srv.stream == nil {
+		return ErrResp(http.StatusNotFound, errors.New("state history stream is not available"), "")
+	}
+	matchers, err := getMatchersFromQuery(c.Req.URL.Query())
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid matcher")
+	}
+	ruleUID := c.Query("ruleUID")
+	return &stateHistoryStreamResponse{
+		srv: srv,
+		filter: func(e historian.StreamEvent) bool {
+			return (ruleUID == "" || e.RuleUID == ruleUID) && matchersMatch(matchers, e.Labels)
+		},
+	}
+}
+
+// stateHistoryStreamResponse writes state transitions to the client as Server-Sent Events until the request is cancelled.
+type stateHistoryStreamResponse struct {
+	srv    *HistorySrv
+	filter historian.StreamFilter
+}
+
+func (r *stateHistoryStreamResponse) Status() int {
+	return http.StatusOK
+}
+
+func (r *stateHistoryStreamResponse) Body() []byte {
+	return nil
+}
+
+func (r *stateHistoryStreamResponse) WriteTo(c *contextmodel.ReqContext) {
+	ctx := c.Req.Context()
+	events, unsubscribe := r.srv.stream.Subscribe(c.SignedInUser.GetOrgID(), r.filter)
+	defer unsubscribe()
+
+	header := c.Resp.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Resp.WriteHeader(http.StatusOK)
+	c.Resp.Flush()
+
+	heartbeat := time.NewTicker(stateHistoryStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	access := newFolderAccessCache(stateHistoryStreamHeartbeat, func(e historian.StreamEvent) error {
+		return r.srv.authz.AuthorizeAccessInFolder(ctx, c.SignedInUser, e)
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Resp, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			allowed, err := access.allowed(e)
+			if err != nil {
+				r.srv.logger.Error("Failed to authorize access to state transitions", "folder", e.NamespaceUID, "error", err)
+			}
+			if !allowed {
+				continue
+			}
+			b, err := json.Marshal(e.StateTransitionEvent())
+			if err != nil {
+				r.srv.logger.Error("Failed to encode state transition", "rule_uid", e.RuleUID, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Resp, "event: transition\ndata: %s\n\n", b); err != nil {
+				return
+			}
+		}
+		c.Resp.Flush()
+	}
+}
+
+// folderAccessCache keeps the results of the access checks of the folders of a stream for the ttl, so the access
+// revoked while the stream is open is applied. Errors other than authorization errors are not kept, the access is
+// checked again with the next event of the folder.
+type folderAccessCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	check   func(e historian.StreamEvent) error
+	folders map[string]folderAccess
+}
+
+type folderAccess struct {
+	allowed bool
+	expires time.Time
+}
+
+func newFolderAccessCache(ttl time.Duration, check func(e historian.StreamEvent) error) *folderAccessCache {
+	return &folderAccessCache{
+		ttl:     ttl,
+		now:     time.Now,
+		check:   check,
+		folders: make(map[string]folderAccess),
+	}
+}
+
+// allowed returns true when the user can access the folder of the event, and the error when the access could not be
+// checked
+func (a *folderAccessCache) allowed(e historian.StreamEvent) (bool, error) {
+	now := a.now()
+	if access, ok := a.folders[e.NamespaceUID]; ok && now.Before(access.expires) {
+		return access.allowed, nil
+	}
+	err := a.check(e)
+	if err != nil && !errors.Is(err, authz.ErrAuthorizationBase) {
+		delete(a.folders, e.NamespaceUID)
+		return false, err
+	}
+	a.folders[e.NamespaceUID] = folderAccess{allowed: err == nil, expires: now.Add(a.ttl)}
+	return err == nil, nil
+}
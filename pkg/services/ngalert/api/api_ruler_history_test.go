@@ -0,0 +1,463 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type fakeHistoryAnnotations struct {
+	query *annotations.ItemQuery
+	items []*annotations.ItemDTO
+}
+
+func (f *fakeHistoryAnnotations) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	f.query = query
+	return f.items, nil
+}
+
+func TestLinkedAnnotations(t *testing.T) {
+	orgID := int64(1)
+	gen := models.RuleGen
+	gen = gen.With(gen.WithOrgID(orgID), gen.WithNamespaceUID("folder-1"))
+	cpu := gen.With(gen.WithDashboardAndPanel(util.Pointer("dash-1"), util.Pointer(int64(1)))).GenerateRef()
+	mem := gen.With(gen.WithDashboardAndPanel(util.Pointer("dash-1"), util.Pointer(int64(2)))).GenerateRef()
+	unlinked := gen.With(gen.WithDashboardAndPanel(nil, nil)).GenerateRef()
+	hidden := gen.With(gen.WithNamespaceUID("folder-2"), gen.WithDashboardAndPanel(util.Pointer("dash-1"), util.Pointer(int64(2)))).GenerateRef()
+
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), cpu, mem, unlinked, hidden)
+	signedInUser := &user.SignedInUser{OrgID: orgID, Permissions: createPermissionsForRules([]*models.AlertRule{cpu}, orgID)}
+
+	newSrv := func(items ...*annotations.ItemDTO) (*HistorySrv, *fakeHistoryAnnotations) {
+		fake := &fakeHistoryAnnotations{items: items}
+		return &HistorySrv{
+			logger:      log.NewNopLogger(),
+			authz:       accesscontrol.NewRuleService(acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())),
+			rules:       ruleStore,
+			annotations: fake,
+		}, fake
+	}
+	from, to := time.Unix(100, 0), time.Unix(200, 0)
+
+	t.Run("requires a rule or a dashboard", func(t *testing.T) {
+		srv, _ := newSrv()
+		_, err := srv.linkedAnnotations(context.Background(), models.HistoryQuery{OrgID: orgID, SignedInUser: signedInUser})
+		require.ErrorIs(t, err, errInvalidAnnotationsQuery)
+	})
+
+	t.Run("finds the annotations of the panel of the rule", func(t *testing.T) {
+		srv, fake := newSrv(
+			&annotations.ItemDTO{ID: 1, PanelID: 1, Time: 150000, Text: "deploy", Tags: []string{"release"}},
+			&annotations.ItemDTO{ID: 2, Time: 160000, TimeEnd: 170000, Text: "maintenance"},
+		)
+		res, err := srv.linkedAnnotations(context.Background(), models.HistoryQuery{
+			OrgID: orgID, RuleUID: cpu.UID, From: from, To: to, SignedInUser: signedInUser,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "dash-1", fake.query.DashboardUID)
+		require.Equal(t, int64(1), fake.query.PanelID)
+		require.Equal(t, int64(100000), fake.query.From)
+		require.Equal(t, int64(200000), fake.query.To)
+		require.Equal(t, "annotation", fake.query.Type, "alert annotations are the state history itself")
+		require.Equal(t, int64(stateHistoryAnnotationsDefaultLimit), fake.query.Limit)
+
+		require.Len(t, res, 2)
+		require.Equal(t, []string{cpu.UID}, res[0].RuleUIDs)
+		require.Equal(t, "deploy", res[0].Text)
+		require.Equal(t, "dash-1", res[1].DashboardUID)
+		require.Equal(t, int64(170000), res[1].TimeEnd)
+	})
+
+	t.Run("rules that are not linked to a dashboard have no annotations", func(t *testing.T) {
+		srv, fake := newSrv(&annotations.ItemDTO{ID: 1})
+		perms := &user.SignedInUser{OrgID: orgID, Permissions: createPermissionsForRules([]*models.AlertRule{unlinked}, orgID)}
+		res, err := srv.linkedAnnotations(context.Background(), models.HistoryQuery{OrgID: orgID, RuleUID: unlinked.UID, SignedInUser: perms})
+		require.NoError(t, err)
+		require.Empty(t, res)
+		require.Nil(t, fake.query)
+	})
+
+	t.Run("links the annotations of a dashboard with the rules of its panels the user can access", func(t *testing.T) {
+		srv, _ := newSrv(
+			&annotations.ItemDTO{ID: 1, PanelID: 1},
+			&annotations.ItemDTO{ID: 2, PanelID: 2},
+			&annotations.ItemDTO{ID: 3},
+		)
+		perms := &user.SignedInUser{OrgID: orgID, Permissions: createPermissionsForRules([]*models.AlertRule{cpu, mem}, orgID)}
+		res, err := srv.linkedAnnotations(context.Background(), models.HistoryQuery{OrgID: orgID, DashboardUID: "dash-1", SignedInUser: perms})
+		require.NoError(t, err)
+
+		require.Len(t, res, 3)
+		require.Equal(t, []string{cpu.UID}, res[0].RuleUIDs)
+		require.Equal(t, []string{mem.UID}, res[1].RuleUIDs, "the rule in folder-2 is not visible")
+		require.ElementsMatch(t, []string{cpu.UID, mem.UID}, res[2].RuleUIDs)
+	})
+
+	t.Run("requires access to the rule", func(t *testing.T) {
+		srv, _ := newSrv()
+		_, err := srv.linkedAnnotations(context.Background(), models.HistoryQuery{OrgID: orgID, RuleUID: hidden.UID, SignedInUser: signedInUser})
+		require.ErrorIs(t, err, accesscontrol.ErrAuthorizationBase)
+	})
+}
+
+func TestRuleGroupUptimes(t *testing.T) {
+	orgID := int64(1)
+	gen := models.RuleGen
+	gen = gen.With(gen.WithOrgID(orgID), gen.WithNamespaceUID("folder-1"))
+	a := gen.With(gen.WithGroupName("group-1")).GenerateRef()
+	b := gen.With(gen.WithGroupName("group-1")).GenerateRef()
+	c := gen.With(gen.WithGroupName("group-2")).GenerateRef()
+
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), a, b, c)
+	srv := &HistorySrv{logger: log.NewNopLogger(), rules: ruleStore}
+
+	res, err := srv.ruleGroupUptimes(context.Background(), orgID, []historian.RuleUptime{
+		{RuleUID: a.UID, Up: 90 * time.Minute, Down: 10 * time.Minute},
+		{RuleUID: b.UID, Up: 70 * time.Minute, Down: 30 * time.Minute},
+		{RuleUID: c.UID, Ignored: time.Hour},
+		{RuleUID: "deleted", Down: time.Hour},
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+
+	require.Equal(t, "group-1", res[0].RuleGroup)
+	require.Equal(t, "folder-1", res[0].FolderUID)
+	require.ElementsMatch(t, []string{a.UID, b.UID}, res[0].RuleUIDs)
+	require.NotNil(t, res[0].Uptime)
+	require.InDelta(t, 80, *res[0].Uptime, 0.001)
+	require.Equal(t, float64(9600), res[0].UpSeconds)
+
+	require.Equal(t, "group-2", res[1].RuleGroup)
+	require.Nil(t, res[1].Uptime, "the time of the group was not counted")
+	require.Equal(t, float64(3600), res[1].IgnoredSeconds)
+}
+
+type fakeHistorian struct {
+	query models.HistoryQuery
+	frame *data.Frame
+}
+
+func (f *fakeHistorian) Query(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.query = query
+	return f.frame, nil
+}
+
+type fakeHistoryRuleVersions struct {
+	versions []models.AlertRuleVersion
+}
+
+func (f *fakeHistoryRuleVersions) GetAlertRuleVersions(_ context.Context, _ int64, _ string) ([]models.AlertRuleVersion, error) {
+	return f.versions, nil
+}
+
+func TestRouteRuleVersionsStateHistory(t *testing.T) {
+	orgID := int64(1)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	gen := models.RuleGen
+	rule := gen.With(gen.WithOrgID(orgID), gen.WithNamespaceUID("folder-1")).GenerateRef()
+	rule.Version, rule.Updated = 3, start.Add(2*time.Hour)
+	hidden := gen.With(gen.WithOrgID(orgID), gen.WithNamespaceUID("folder-2")).GenerateRef()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), rule, hidden)
+
+	newSrv := func() (*HistorySrv, *fakeHistorian) {
+		hist := &fakeHistorian{frame: data.NewFrame("states",
+			data.NewField("time", nil, []time.Time{start.Add(30 * time.Minute), start.Add(90 * time.Minute)}),
+			data.NewField("line", nil, []json.RawMessage{
+				json.RawMessage(`{"previous":"Normal","current":"Alerting"}`),
+				json.RawMessage(`{"previous":"Alerting","current":"Normal"}`),
+			}),
+		)}
+		return &HistorySrv{
+			logger: log.NewNopLogger(),
+			hist:   hist,
+			authz:  accesscontrol.NewRuleService(acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())),
+			rules:  ruleStore,
+			versions: &fakeHistoryRuleVersions{versions: []models.AlertRuleVersion{
+				{Version: 1, Created: start},
+				{Version: 2, Created: start.Add(time.Hour)},
+			}},
+		}, hist
+	}
+	request := func(ruleUID string, query map[string]string) *contextmodel.ReqContext {
+		c := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{rule}, orgID), nil)
+		c.Req.Form.Set("ruleUID", ruleUID)
+		for k, v := range query {
+			c.Req.Form.Set(k, v)
+		}
+		return c
+	}
+
+	t.Run("splits the state history by version", func(t *testing.T) {
+		srv, hist := newSrv()
+		resp := srv.RouteRuleVersionsStateHistory(request(rule.UID, nil))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var res apimodels.StateHistoryRuleVersions
+		require.NoError(t, json.Unmarshal(resp.Body(), &res))
+		require.Equal(t, start, hist.query.From.Truncate(time.Second), "the time range starts with the first version")
+		require.Len(t, res.Versions, 3, "the current version is added to the saved versions")
+		require.Equal(t, int64(1), res.Versions[0].Transitions)
+		require.Equal(t, int64(1), res.Versions[1].Transitions)
+		require.Equal(t, int64(3), res.Versions[2].Version)
+		require.True(t, res.Versions[2].Current)
+		require.Nil(t, res.History)
+	})
+
+	t.Run("returns the state history of a version", func(t *testing.T) {
+		srv, hist := newSrv()
+		resp := srv.RouteRuleVersionsStateHistory(request(rule.UID, map[string]string{"version": "2"}))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var res apimodels.StateHistoryRuleVersions
+		require.NoError(t, json.Unmarshal(resp.Body(), &res))
+		require.Len(t, res.Versions, 1)
+		require.NotNil(t, res.History)
+		require.Equal(t, start.Add(time.Hour), hist.query.From)
+		require.Equal(t, start.Add(2*time.Hour), hist.query.To)
+	})
+
+	t.Run("version out of the time range", func(t *testing.T) {
+		srv, _ := newSrv()
+		resp := srv.RouteRuleVersionsStateHistory(request(rule.UID, map[string]string{
+			"version": "1",
+			"from":    strconv.FormatInt(start.Add(time.Hour).Unix(), 10),
+		}))
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("requires a rule", func(t *testing.T) {
+		srv, _ := newSrv()
+		require.Equal(t, http.StatusBadRequest, srv.RouteRuleVersionsStateHistory(request("", nil)).Status())
+		require.Equal(t, http.StatusNotFound, srv.RouteRuleVersionsStateHistory(request("unknown", nil)).Status())
+	})
+
+	t.Run("requires access to the rule", func(t *testing.T) {
+		srv, _ := newSrv()
+		require.Equal(t, http.StatusForbidden, srv.RouteRuleVersionsStateHistory(request(hidden.UID, nil)).Status())
+	})
+}
+
+// fakeRuleHistorian returns the state history of the rule of the query
+type fakeRuleHistorian struct {
+	queries []models.HistoryQuery
+	frames  map[string]*data.Frame
+}
+
+func (f *fakeRuleHistorian) Query(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.queries = append(f.queries, query)
+	return f.frames[query.RuleUID], nil
+}
+
+func TestRoutePanelStateHistory(t *testing.T) {
+	orgID := int64(1)
+	start := time.Unix(1704067200, 0)
+	gen := models.RuleGen
+	gen = gen.With(gen.WithOrgID(orgID), gen.WithNamespaceUID("folder-1"), gen.WithDashboardAndPanel(util.Pointer("dash-1"), util.Pointer(int64(1))))
+	cpu := gen.With(gen.WithTitle("CPU")).GenerateRef()
+	mem := gen.With(gen.WithTitle("Memory")).GenerateRef()
+	other := gen.With(gen.WithDashboardAndPanel(util.Pointer("dash-1"), util.Pointer(int64(2)))).GenerateRef()
+	hidden := gen.With(gen.WithNamespaceUID("folder-2")).GenerateRef()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), cpu, mem, other, hidden)
+
+	lokiFrame := func(at time.Duration, previous, current string) *data.Frame {
+		line, err := json.Marshal(historian.LokiEntry{Previous: previous, Current: current, Fingerprint: "1"})
+		require.NoError(t, err)
+		return data.NewFrame("states",
+			data.NewField("time", nil, []time.Time{start.Add(at)}),
+			data.NewField("line", nil, []json.RawMessage{line}),
+		)
+	}
+	hist := &fakeRuleHistorian{frames: map[string]*data.Frame{
+		cpu.UID:    lokiFrame(10*time.Minute, "Normal", "Alerting"),
+		mem.UID:    lokiFrame(20*time.Minute, "Normal", "Pending"),
+		hidden.UID: lokiFrame(30*time.Minute, "Normal", "Alerting"),
+	}}
+	srv := &HistorySrv{
+		logger: log.NewNopLogger(),
+		hist:   hist,
+		authz:  accesscontrol.NewRuleService(acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())),
+		rules:  ruleStore,
+	}
+	request := func(query map[string]string) *contextmodel.ReqContext {
+		c := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{cpu, mem, other}, orgID), nil)
+		for k, v := range map[string]string{
+			"from": strconv.FormatInt(start.Unix(), 10),
+			"to":   strconv.FormatInt(start.Add(time.Hour).Unix(), 10),
+		} {
+			c.Req.Form.Set(k, v)
+		}
+		for k, v := range query {
+			c.Req.Form.Set(k, v)
+		}
+		return c
+	}
+
+	t.Run("merges the state history of the rules of the panel", func(t *testing.T) {
+		hist.queries = nil
+		resp := srv.RoutePanelStateHistory(request(map[string]string{"dashboardUID": "dash-1", "panelID": "1"}))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		frame := &data.Frame{}
+		require.NoError(t, json.Unmarshal(resp.Body(), frame))
+		require.Len(t, frame.Fields, 3, "the rules of the folders the user can not access are left out")
+		require.Equal(t, "CPU", frame.Fields[1].Name)
+		require.Equal(t, cpu.UID, frame.Fields[1].Labels[historian.TimelineRuleUIDLabel])
+		require.Equal(t, "Memory", frame.Fields[2].Name)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "Alerting", *frame.Fields[1].At(2).(*string))
+		require.Equal(t, "Pending", *frame.Fields[2].At(2).(*string))
+
+		require.Len(t, hist.queries, 2, "the state history is queried per rule")
+		for _, q := range hist.queries {
+			require.Empty(t, q.DashboardUID)
+		}
+	})
+
+	t.Run("merges the rules of the whole dashboard without a panel", func(t *testing.T) {
+		resp := srv.RoutePanelStateHistory(request(map[string]string{"dashboardUID": "dash-1"}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		frame := &data.Frame{}
+		require.NoError(t, json.Unmarshal(resp.Body(), frame))
+		require.Len(t, frame.Fields, 4)
+	})
+
+	t.Run("requires a dashboard and a time range", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, srv.RoutePanelStateHistory(request(nil)).Status())
+		require.Equal(t, http.StatusBadRequest, srv.RoutePanelStateHistory(request(map[string]string{
+			"dashboardUID": "dash-1",
+			"to":           strconv.FormatInt(start.Unix(), 10),
+		})).Status())
+	})
+}
+
+func TestRouteQueryStateHistoryAlerts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newSrv := func() (*HistorySrv, *fakeHistorian) {
+		hist := &fakeHistorian{frame: data.NewFrame("states",
+			data.NewField("time", nil, []time.Time{start.Add(10 * time.Minute), start.Add(30 * time.Minute)}),
+			data.NewField("line", nil, []json.RawMessage{
+				json.RawMessage(`{"ruleUID":"r1","fingerprint":"1","previous":"Normal","current":"Alerting","labels":{"alertname":"cpu"}}`),
+				json.RawMessage(`{"ruleUID":"r1","fingerprint":"1","previous":"Alerting","current":"Normal","labels":{"alertname":"cpu"}}`),
+			}),
+		)}
+		return &HistorySrv{logger: log.NewNopLogger(), hist: hist}, hist
+	}
+	request := func(query map[string]string) *contextmodel.ReqContext {
+		c := createRequestContextWithPerms(1, nil, nil)
+		for k, v := range query {
+			c.Req.Form.Set(k, v)
+		}
+		return c
+	}
+
+	t.Run("instant query", func(t *testing.T) {
+		srv, hist := newSrv()
+		resp := srv.RouteQueryStateHistoryAlerts(request(map[string]string{
+			"query": `ALERTS{__alert_rule_uid__="r1"}`,
+			"time":  strconv.FormatInt(start.Add(20*time.Minute).Unix(), 10),
+		}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"ALERTS","__alert_rule_uid__":"r1","alertname":"cpu","alertstate":"firing"},"value":[1704068400,"1"]}
+		]}}`, string(resp.Body()))
+		require.Equal(t, "r1", hist.query.RuleUID)
+		require.Equal(t, start.Add(20*time.Minute).Add(-stateHistoryAlertsDefaultLookback), hist.query.From.UTC())
+	})
+
+	t.Run("range query", func(t *testing.T) {
+		srv, hist := newSrv()
+		resp := srv.RouteQueryRangeStateHistoryAlerts(request(map[string]string{
+			"query":    `ALERTS_FOR_STATE`,
+			"start":    start.Format(time.RFC3339),
+			"end":      start.Add(30 * time.Minute).Format(time.RFC3339),
+			"step":     "10m",
+			"lookback": "1h",
+		}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"__name__":"ALERTS_FOR_STATE","__alert_rule_uid__":"r1","alertname":"cpu"},"values":[[1704067800,"1704067800"],[1704068400,"1704067800"]]}
+		]}}`, string(resp.Body()))
+		require.Equal(t, start.Add(-time.Hour), hist.query.From)
+	})
+
+	t.Run("invalid queries are bad data", func(t *testing.T) {
+		srv, _ := newSrv()
+		resp := srv.RouteQueryStateHistoryAlerts(request(map[string]string{"query": `rate(ALERTS[5m])`}))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		var res apimodels.StateHistoryAlertsResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &res))
+		require.Equal(t, "error", res.Status)
+		require.Equal(t, "bad_data", string(res.ErrorType))
+
+		resp = srv.RouteQueryRangeStateHistoryAlerts(request(map[string]string{"query": `ALERTS`, "start": "0", "end": "60", "step": "0"}))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+}
+
+func TestFolderAccessCache(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var result error
+	checks := 0
+	access := newFolderAccessCache(time.Minute, func(e historian.StreamEvent) error {
+		checks++
+		return result
+	})
+	access.now = func() time.Time { return now }
+	event := historian.StreamEvent{NamespaceUID: "folder-1"}
+
+	t.Run("does not keep the errors other than authorization errors", func(t *testing.T) {
+		result = errors.New("database is locked")
+		allowed, err := access.allowed(event)
+		require.Error(t, err)
+		require.False(t, allowed)
+
+		result = nil
+		allowed, err = access.allowed(event)
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Equal(t, 2, checks)
+	})
+
+	t.Run("keeps the results until they expire", func(t *testing.T) {
+		result = accesscontrol.ErrAuthorizationBase.Errorf("revoked")
+		allowed, err := access.allowed(event)
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Equal(t, 2, checks)
+
+		now = now.Add(time.Minute)
+		allowed, err = access.allowed(event)
+		require.NoError(t, err)
+		require.False(t, allowed)
+		allowed, err = access.allowed(event)
+		require.NoError(t, err)
+		require.False(t, allowed)
+		require.Equal(t, 3, checks)
+	})
+}
@@ -68,6 +68,29 @@ func (api *API) authorize(method, path string) web.Handler {
 	// Grafana rule state history paths
 	case http.MethodGet + "/api/v1/rules/history":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/instance/{Fingerprint}":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/stream":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/summary":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/uptime":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/panel":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/prometheus/api/v1/query":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/prometheus/api/v1/query_range":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rules/history/versions":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodPost + "/api/v1/rules/history/_compact":
+		return middleware.ReqOrgAdmin
+	case http.MethodPost + "/api/v1/rules/history/_purge":
+		return middleware.ReqOrgAdmin
+	case http.MethodPost + "/api/v1/rules/history/_import":
+		// reads the state history from any Loki instance reachable from the server
+		return middleware.ReqGrafanaAdmin
 
 	// Grafana receivers paths
 	case http.MethodGet + "/api/v1/notifications/receivers":
@@ -339,6 +362,18 @@ func (api *API) authorize(method, path string) web.Handler {
 				ac.EvalPermission(ac.ActionAlertingProvisioningSetStatus),
 			),
 		)
+	case http.MethodPost + "/api/v1/provisioning/alert-rules/import":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),
+			ac.EvalPermission(ac.ActionAlertingRulesProvisioningWrite),
+			ac.EvalAll(
+				ac.EvalPermission(ac.ActionAlertingRuleRead), // more granular permissions are enforced by the handler via "authorizeRuleChanges"
+				ac.EvalPermission(ac.ActionAlertingRuleCreate),
+				ac.EvalPermission(ac.ActionAlertingRuleUpdate),
+				ac.EvalPermission(dashboards.ActionFoldersRead),
+				ac.EvalPermission(ac.ActionAlertingProvisioningSetStatus),
+			),
+		)
 	case http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -270,6 +271,122 @@ func AlertQueryExportFromAlertQuery(query models.AlertQuery) (definitions.AlertQ
 	}, nil
 }
 
+// AlertRuleGroupFromAlertRuleGroupExport creates a models.AlertRuleGroup in the folder folderUID from definitions.AlertRuleGroupExport.
+// Data sources of the queries are replaced according to datasourceMapping.
+func AlertRuleGroupFromAlertRuleGroupExport(g definitions.AlertRuleGroupExport, folderUID string, datasourceMapping map[string]string) (models.AlertRuleGroup, error) {
+	result := models.AlertRuleGroup{
+		Title:     g.Name,
+		FolderUID: folderUID,
+		Interval:  int64(time.Duration(g.Interval).Seconds()),
+		Rules:     make([]models.AlertRule, 0, len(g.Rules)),
+	}
+	for i := range g.Rules {
+		rule, err := AlertRuleFromAlertRuleExport(g.Rules[i], datasourceMapping)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("invalid rule '%s': %w", g.Rules[i].Title, err)
+		}
+		rule.NamespaceUID = folderUID
+		rule.RuleGroup = g.Name
+		rule.IntervalSeconds = result.Interval
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, nil
+}
+
+// AlertRuleFromAlertRuleExport creates a models.AlertRule from definitions.AlertRuleExport.
+// Data sources of the queries are replaced according to datasourceMapping.
+func AlertRuleFromAlertRuleExport(r definitions.AlertRuleExport, datasourceMapping map[string]string) (models.AlertRule, error) {
+	data := make([]models.AlertQuery, 0, len(r.Data))
+	for i := range r.Data {
+		query, err := AlertQueryFromAlertQueryExport(r.Data[i], datasourceMapping)
+		if err != nil {
+			return models.AlertRule{}, err
+		}
+		data = append(data, query)
+	}
+	ns, err := NotificationSettingsFromAlertRuleNotificationSettingsExport(r.NotificationSettings)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	rule := models.AlertRule{
+		UID:                  r.UID,
+		Title:                r.Title,
+		Data:                 data,
+		DashboardUID:         r.DashboardUID,
+		PanelID:              r.PanelID,
+		NoDataState:          models.NoData,
+		ExecErrState:         models.ErrorErrState,
+		For:                  time.Duration(r.For),
+		IsPaused:             r.IsPaused,
+		NotificationSettings: ns,
+		Record:               ModelRecordFromAlertRuleRecordExport(r.Record),
+	}
+	if r.Condition != nil {
+		rule.Condition = *r.Condition
+	}
+	if r.NoDataState != nil {
+		rule.NoDataState = models.NoDataState(*r.NoDataState)
+	}
+	if r.ExecErrState != nil {
+		rule.ExecErrState = models.ExecutionErrorState(*r.ExecErrState)
+	}
+	if r.Annotations != nil {
+		rule.Annotations = *r.Annotations
+	}
+	if r.Labels != nil {
+		rule.Labels = *r.Labels
+	}
+
+	if rule.Type() == models.RuleTypeRecording {
+		models.ClearRecordingRuleIgnoredFields(&rule)
+	}
+	return rule, nil
+}
+
+// AlertQueryFromAlertQueryExport creates a models.AlertQuery from definitions.AlertQueryExport.
+// The data source is replaced if it is present in datasourceMapping.
+func AlertQueryFromAlertQueryExport(query definitions.AlertQueryExport, datasourceMapping map[string]string) (models.AlertQuery, error) {
+	dsUID := query.DatasourceUID
+	if mapped, ok := datasourceMapping[dsUID]; ok {
+		dsUID = mapped
+	}
+
+	mdl := query.Model
+	if ds, ok := mdl["datasource"].(map[string]any); ok && dsUID != query.DatasourceUID {
+		// Keep the data source reference of the query model in sync with the query.
+		updated := make(map[string]any, len(mdl))
+		for k, v := range mdl {
+			updated[k] = v
+		}
+		ref := make(map[string]any, len(ds))
+		for k, v := range ds {
+			ref[k] = v
+		}
+		ref["uid"] = dsUID
+		updated["datasource"] = ref
+		mdl = updated
+	}
+	raw, err := json.Marshal(mdl)
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+
+	result := models.AlertQuery{
+		RefID: query.RefID,
+		RelativeTimeRange: models.RelativeTimeRange{
+			From: models.Duration(time.Duration(query.RelativeTimeRange.FromSeconds) * time.Second),
+			To:   models.Duration(time.Duration(query.RelativeTimeRange.ToSeconds) * time.Second),
+		},
+		DatasourceUID: dsUID,
+		Model:         raw,
+	}
+	if query.QueryType != nil {
+		result.QueryType = *query.QueryType
+	}
+	return result, nil
+}
+
 // AlertingFileExportFromEmbeddedContactPoints creates a definitions.AlertingFileExport DTO from []definitions.EmbeddedContactPoint.
 func AlertingFileExportFromEmbeddedContactPoints(orgID int64, ecps []definitions.EmbeddedContactPoint) (definitions.AlertingFileExport, error) {
 	f := definitions.AlertingFileExport{APIVersion: 1}
@@ -494,6 +611,41 @@ func NotificationSettingsFromAlertRuleNotificationSettings(ns *definitions.Alert
 	}
 }
 
+// NotificationSettingsFromAlertRuleNotificationSettingsExport converts definitions.AlertRuleNotificationSettingsExport to []models.NotificationSettings
+func NotificationSettingsFromAlertRuleNotificationSettingsExport(ns *definitions.AlertRuleNotificationSettingsExport) ([]models.NotificationSettings, error) {
+	if ns == nil {
+		return nil, nil
+	}
+
+	parseIfNotNil := func(s *string) (*model.Duration, error) {
+		if s == nil {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(*s)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+
+	result := models.NotificationSettings{
+		Receiver:          ns.Receiver,
+		GroupBy:           ns.GroupBy,
+		MuteTimeIntervals: ns.MuteTimeIntervals,
+	}
+	var err error
+	if result.GroupWait, err = parseIfNotNil(ns.GroupWait); err != nil {
+		return nil, fmt.Errorf("invalid group_wait: %w", err)
+	}
+	if result.GroupInterval, err = parseIfNotNil(ns.GroupInterval); err != nil {
+		return nil, fmt.Errorf("invalid group_interval: %w", err)
+	}
+	if result.RepeatInterval, err = parseIfNotNil(ns.RepeatInterval); err != nil {
+		return nil, fmt.Errorf("invalid repeat_interval: %w", err)
+	}
+	return []models.NotificationSettings{result}, nil
+}
+
 func AlertRuleRecordExportFromRecord(r *models.Record) *definitions.AlertRuleRecordExport {
 	if r == nil {
 		return nil
@@ -504,6 +656,16 @@ func AlertRuleRecordExportFromRecord(r *models.Record) *definitions.AlertRuleRec
 	}
 }
 
+func ModelRecordFromAlertRuleRecordExport(r *definitions.AlertRuleRecordExport) *models.Record {
+	if r == nil {
+		return nil
+	}
+	return &models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
 func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
 	if r == nil {
 		return nil
@@ -53,7 +53,8 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApi, m *metri
 		group.Delete(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("ConfigurationApi")),
+			api.gate("ConfigurationApi", "RouteDeleteNGalertConfig"),
 			api.authorize(http.MethodDelete, "/api/v1/ngalert/admin_config"),
 			metrics.Instrument(
 				http.MethodDelete,
@@ -65,7 +66,8 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/ngalert/alertmanagers"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("ConfigurationApi")),
+			api.gate("ConfigurationApi", "RouteGetAlertmanagers"),
 			api.authorize(http.MethodGet, "/api/v1/ngalert/alertmanagers"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -77,7 +79,8 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("ConfigurationApi")),
+			api.gate("ConfigurationApi", "RouteGetNGalertConfig"),
 			api.authorize(http.MethodGet, "/api/v1/ngalert/admin_config"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -89,7 +92,8 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/ngalert"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("ConfigurationApi")),
+			api.gate("ConfigurationApi", "RouteGetStatus"),
 			api.authorize(http.MethodGet, "/api/v1/ngalert"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -101,7 +105,8 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApi, m *metri
 		group.Post(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("ConfigurationApi")),
+			api.gate("ConfigurationApi", "RoutePostNGalertConfig"),
 			api.authorize(http.MethodPost, "/api/v1/ngalert/admin_config"),
 			metrics.Instrument(
 				http.MethodPost,
@@ -14,23 +14,116 @@ import (
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 type HistoryApi interface {
+	RouteCompactStateHistory(*contextmodel.ReqContext) response.Response
 	RouteGetStateHistory(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryForInstance(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryPanel(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryRuleVersions(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryStream(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistorySummary(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryUptime(*contextmodel.ReqContext) response.Response
+	RouteImportStateHistory(*contextmodel.ReqContext) response.Response
+	RoutePurgeStateHistory(*contextmodel.ReqContext) response.Response
+	RouteQueryRangeStateHistoryAlerts(*contextmodel.ReqContext) response.Response
+	RouteQueryStateHistoryAlerts(*contextmodel.ReqContext) response.Response
 }
 
+func (f *HistoryApiHandler) RouteCompactStateHistory(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteCompactStateHistory(ctx)
+}
 func (f *HistoryApiHandler) RouteGetStateHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetStateHistory(ctx)
 }
+func (f *HistoryApiHandler) RouteGetStateHistoryForInstance(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	fingerprintParam := web.Params(ctx.Req)[":Fingerprint"]
+	return f.handleRouteGetStateHistoryForInstance(ctx, fingerprintParam)
+}
+func (f *HistoryApiHandler) RouteGetStateHistoryPanel(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryPanel(ctx)
+}
+func (f *HistoryApiHandler) RouteGetStateHistoryRuleVersions(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryRuleVersions(ctx)
+}
+func (f *HistoryApiHandler) RouteGetStateHistoryStream(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryStream(ctx)
+}
+func (f *HistoryApiHandler) RouteGetStateHistorySummary(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistorySummary(ctx)
+}
+func (f *HistoryApiHandler) RouteGetStateHistoryUptime(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryUptime(ctx)
+}
+func (f *HistoryApiHandler) RouteImportStateHistory(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.StateHistoryImport{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteImportStateHistory(ctx, conf)
+}
+func (f *HistoryApiHandler) RoutePurgeStateHistory(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRoutePurgeStateHistory(ctx)
+}
+func (f *HistoryApiHandler) RouteQueryRangeStateHistoryAlerts(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteQueryRangeStateHistoryAlerts(ctx)
+}
+func (f *HistoryApiHandler) RouteQueryStateHistoryAlerts(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteQueryStateHistoryAlerts(ctx)
+}
 
 func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/rules/history/_compact"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteCompactStateHistory"),
+			api.authorize(http.MethodPost, "/api/v1/rules/history/_compact"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/history/_compact",
+				api.Hooks.Wrap(srv.RouteCompactStateHistory),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/rules/history/_import"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteImportStateHistory"),
+			api.authorize(http.MethodPost, "/api/v1/rules/history/_import"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/history/_import",
+				api.Hooks.Wrap(srv.RouteImportStateHistory),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/rules/history/_purge"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RoutePurgeStateHistory"),
+			api.authorize(http.MethodPost, "/api/v1/rules/history/_purge"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/history/_purge",
+				api.Hooks.Wrap(srv.RoutePurgeStateHistory),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/rules/history"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistory"),
 			api.authorize(http.MethodGet, "/api/v1/rules/history"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -39,5 +132,109 @@ func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/instance/{Fingerprint}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistoryForInstance"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/instance/{Fingerprint}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/instance/{Fingerprint}",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryForInstance),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/panel"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistoryPanel"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/panel"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/panel",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryPanel),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/prometheus/api/v1/query"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteQueryStateHistoryAlerts"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/prometheus/api/v1/query"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/prometheus/api/v1/query",
+				api.Hooks.Wrap(srv.RouteQueryStateHistoryAlerts),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/prometheus/api/v1/query_range"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteQueryRangeStateHistoryAlerts"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/prometheus/api/v1/query_range"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/prometheus/api/v1/query_range",
+				api.Hooks.Wrap(srv.RouteQueryRangeStateHistoryAlerts),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/stream"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistoryStream"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/stream"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/stream",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryStream),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/summary"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistorySummary"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/summary"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/summary",
+				api.Hooks.Wrap(srv.RouteGetStateHistorySummary),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/uptime"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistoryUptime"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/uptime"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/uptime",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryUptime),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/versions"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(api.sloGroup("HistoryApi")),
+			api.gate("HistoryApi", "RouteGetStateHistoryRuleVersions"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/versions",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryRuleVersions),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
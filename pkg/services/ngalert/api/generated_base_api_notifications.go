@@ -47,7 +47,8 @@ func (api *API) RegisterNotificationsApiEndpoints(srv NotificationsApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/notifications/receivers/{Name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("NotificationsApi")),
+			api.gate("NotificationsApi", "RouteGetReceiver"),
 			api.authorize(http.MethodGet, "/api/v1/notifications/receivers/{Name}"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -59,7 +60,8 @@ func (api *API) RegisterNotificationsApiEndpoints(srv NotificationsApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/notifications/receivers"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("NotificationsApi")),
+			api.gate("NotificationsApi", "RouteGetReceivers"),
 			api.authorize(http.MethodGet, "/api/v1/notifications/receivers"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -71,7 +73,8 @@ func (api *API) RegisterNotificationsApiEndpoints(srv NotificationsApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/notifications/time-intervals/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("NotificationsApi")),
+			api.gate("NotificationsApi", "RouteNotificationsGetTimeInterval"),
 			api.authorize(http.MethodGet, "/api/v1/notifications/time-intervals/{name}"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -83,7 +86,8 @@ func (api *API) RegisterNotificationsApiEndpoints(srv NotificationsApi, m *metri
 		group.Get(
 			toMacaronPath("/api/v1/notifications/time-intervals"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("NotificationsApi")),
+			api.gate("NotificationsApi", "RouteNotificationsGetTimeIntervals"),
 			api.authorize(http.MethodGet, "/api/v1/notifications/time-intervals"),
 			metrics.Instrument(
 				http.MethodGet,
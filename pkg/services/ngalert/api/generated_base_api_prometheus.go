@@ -47,7 +47,8 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 		group.Get(
 			toMacaronPath("/api/prometheus/{DatasourceUID}/api/v1/alerts"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("PrometheusApi")),
+			api.gate("PrometheusApi", "RouteGetAlertStatuses"),
 			api.authorize(http.MethodGet, "/api/prometheus/{DatasourceUID}/api/v1/alerts"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -59,7 +60,8 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 		group.Get(
 			toMacaronPath("/api/prometheus/grafana/api/v1/alerts"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("PrometheusApi")),
+			api.gate("PrometheusApi", "RouteGetGrafanaAlertStatuses"),
 			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/alerts"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -71,7 +73,8 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 		group.Get(
 			toMacaronPath("/api/prometheus/grafana/api/v1/rules"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("PrometheusApi")),
+			api.gate("PrometheusApi", "RouteGetGrafanaRuleStatuses"),
 			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/rules"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -83,7 +86,8 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 		group.Get(
 			toMacaronPath("/api/prometheus/{DatasourceUID}/api/v1/rules"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("PrometheusApi")),
+			api.gate("PrometheusApi", "RouteGetRuleStatuses"),
 			api.authorize(http.MethodGet, "/api/prometheus/{DatasourceUID}/api/v1/rules"),
 			metrics.Instrument(
 				http.MethodGet,
@@ -66,7 +66,8 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 		group.Post(
 			toMacaronPath("/api/v1/rule/backtest"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("TestingApi")),
+			api.gate("TestingApi", "BacktestConfig"),
 			api.authorize(http.MethodPost, "/api/v1/rule/backtest"),
 			metrics.Instrument(
 				http.MethodPost,
@@ -78,7 +79,8 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("TestingApi")),
+			api.gate("TestingApi", "RouteEvalQueries"),
 			api.authorize(http.MethodPost, "/api/v1/eval"),
 			metrics.Instrument(
 				http.MethodPost,
@@ -90,7 +92,8 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{DatasourceUID}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("TestingApi")),
+			api.gate("TestingApi", "RouteTestRuleConfig"),
 			api.authorize(http.MethodPost, "/api/v1/rule/test/{DatasourceUID}"),
 			metrics.Instrument(
 				http.MethodPost,
@@ -102,7 +105,8 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/grafana"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
-			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			requestmeta.SetSLOGroup(api.sloGroup("TestingApi")),
+			api.gate("TestingApi", "RouteTestRuleGrafanaConfig"),
 			api.authorize(http.MethodPost, "/api/v1/rule/test/grafana"),
 			metrics.Instrument(
 				http.MethodPost,
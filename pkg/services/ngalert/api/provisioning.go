@@ -104,6 +104,10 @@ func (f *ProvisioningApiHandler) handleRoutePostAlertRule(ctx *contextmodel.ReqC
 	return f.svc.RoutePostAlertRule(ctx, ar)
 }
 
+func (f *ProvisioningApiHandler) handleRoutePostAlertRulesImport(ctx *contextmodel.ReqContext, body apimodels.AlertRulesImport) response.Response {
+	return f.svc.RoutePostAlertRulesImport(ctx, body, ctx.QueryBoolWithDefault("dryRun", false))
+}
+
 func (f *ProvisioningApiHandler) handleRoutePutAlertRule(ctx *contextmodel.ReqContext, ar apimodels.ProvisionedAlertRule, UID string) response.Response {
 	return f.svc.RoutePutAlertRule(ctx, ar, UID)
 }
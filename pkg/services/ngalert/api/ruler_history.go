@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 )
 
 type HistoryApiHandler struct {
@@ -18,3 +19,47 @@ func NewStateHistoryApi(svc *HistorySrv) *HistoryApiHandler {
 func (f *HistoryApiHandler) handleRouteGetStateHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.svc.RouteQueryStateHistory(ctx)
 }
+
+func (f *HistoryApiHandler) handleRouteGetStateHistoryForInstance(ctx *contextmodel.ReqContext, fingerprint string) response.Response {
+	return f.svc.RouteQueryInstanceStateHistory(ctx, fingerprint)
+}
+
+func (f *HistoryApiHandler) handleRouteCompactStateHistory(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteCompactStateHistory(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistoryStream(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteStreamStateHistory(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistorySummary(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteSummarizeStateHistory(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistoryPanel(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RoutePanelStateHistory(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistoryUptime(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteUptimeStateHistory(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistoryRuleVersions(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteRuleVersionsStateHistory(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteQueryStateHistoryAlerts(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteQueryStateHistoryAlerts(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteQueryRangeStateHistoryAlerts(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteQueryRangeStateHistoryAlerts(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteImportStateHistory(ctx *contextmodel.ReqContext, body apimodels.StateHistoryImport) response.Response {
+	return f.svc.RouteImportStateHistory(ctx, body)
+}
+
+func (f *HistoryApiHandler) handleRoutePurgeStateHistory(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RoutePurgeStateHistory(ctx)
+}
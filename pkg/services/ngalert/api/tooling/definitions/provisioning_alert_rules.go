@@ -51,6 +51,18 @@ import (
 //       200: AlertingFileExport
 //       404: description: Not found.
 
+// swagger:route POST /v1/provisioning/alert-rules/import provisioning stable RoutePostAlertRulesImport
+//
+// Import alert rule groups exported in provisioning file format, mapping folders and data sources to the ones of this organization.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRulesImportResult
+//       400: ValidationError
+//       409: AlertRulesImportResult
+
 // swagger:route POST /v1/provisioning/alert-rules provisioning stable RoutePostAlertRule
 //
 // Create a new alert rule.
@@ -97,6 +109,11 @@ type AlertRulesExportParameters struct {
 	// in:query
 	// required: false
 	RuleUID string `json:"ruleUid"`
+
+	// Label matchers in the same format as the matcher parameter of the Prometheus rules API. Only rules with labels that match all matchers are exported.
+	// in:query
+	// required: false
+	Matchers []string `json:"matcher"`
 }
 
 // swagger:parameters RouteGetAlertRule RoutePutAlertRule RouteDeleteAlertRule RouteGetAlertRuleExport
@@ -112,7 +129,69 @@ type AlertRulePayload struct {
 	Body ProvisionedAlertRule
 }
 
-// swagger:parameters RoutePostAlertRule RoutePutAlertRule RouteDeleteAlertRule RoutePutAlertRuleGroup
+// swagger:parameters RoutePostAlertRulesImport
+type AlertRulesImportParams struct {
+	// If true, the import is only validated and the result describes the changes it would make.
+	// in:query
+	// required:false
+	// default:false
+	DryRun bool `json:"dryRun"`
+
+	// in:body
+	Body AlertRulesImport
+}
+
+// AlertRulesImport is a document in provisioning file format, as returned by the export endpoints,
+// extended with the mapping of the folders and data sources it references.
+// swagger:model
+type AlertRulesImport struct {
+	APIVersion int64                  `json:"apiVersion,omitempty"`
+	Groups     []AlertRuleGroupExport `json:"groups"`
+	// Maps the folder of exported groups to the UID of a folder in this organization. Every folder of the document must be mapped.
+	FolderMapping map[string]string `json:"folderMapping,omitempty"`
+	// Maps the UID of data sources used by exported queries to the UID of a data source in this organization. Data sources that are not mapped are kept as is.
+	DatasourceMapping map[string]string `json:"datasourceMapping,omitempty"`
+}
+
+// AlertRulesImportResult describes the changes made, or that would be made in a dry run, by an import.
+// swagger:model
+type AlertRulesImportResult struct {
+	DryRun bool                         `json:"dryRun"`
+	Groups []AlertRuleGroupImportResult `json:"groups"`
+	// The import is rejected if any conflict is found.
+	Conflicts []AlertRuleImportConflict `json:"conflicts,omitempty"`
+}
+
+type AlertRuleGroupImportResult struct {
+	Folder    string `json:"folder"`
+	FolderUID string `json:"folderUid"`
+	Name      string `json:"name"`
+	// True if the group does not exist in this organization yet.
+	Created bool `json:"created"`
+	// UIDs of the imported rules.
+	Rules []string `json:"rules"`
+	// UIDs of rules of the existing group that are not part of the import and are deleted.
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// swagger:enum AlertRuleImportConflictReason
+type AlertRuleImportConflictReason string
+
+const (
+	AlertRuleImportConflictFolderNotMapped AlertRuleImportConflictReason = "folder_not_mapped"
+	AlertRuleImportConflictDuplicateGroup  AlertRuleImportConflictReason = "duplicate_group"
+	AlertRuleImportConflictRuleUIDInUse    AlertRuleImportConflictReason = "rule_uid_in_use"
+)
+
+type AlertRuleImportConflict struct {
+	Reason  AlertRuleImportConflictReason `json:"reason"`
+	Folder  string                        `json:"folder"`
+	Group   string                        `json:"group"`
+	RuleUID string                        `json:"ruleUid,omitempty"`
+	Message string                        `json:"message"`
+}
+
+// swagger:parameters RoutePostAlertRule RoutePutAlertRule RouteDeleteAlertRule RoutePutAlertRuleGroup RoutePostAlertRulesImport
 type AlertRuleHeaders struct {
 	// in:header
 	XDisableProvenance string `json:"X-Disable-Provenance"`
@@ -1,6 +1,12 @@
 package definitions
 
-import "github.com/grafana/grafana-plugin-sdk-go/data"
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
 
 // swagger:route GET /v1/rules/history history RouteGetStateHistory
 //
@@ -41,6 +47,15 @@ type StateHistoryParams struct {
 	// in:query
 	// required: false
 	Limit int `json:"limit"`
+	// Pages the history from the newest to the oldest transition, at most 1000 transitions per page.
+	// The token of the next page is the continue field of the custom metadata of the frame, it is empty on the last page.
+	// in:query
+	// required: false
+	PageSize int `json:"pageSize"`
+	// The token of the page to return, the first page when empty.
+	// in:query
+	// required: false
+	Continue string `json:"continue"`
 	// Filter by rule UID. Required the state history is configured to use annotations for storage.
 	// in:query
 	// required: false
@@ -50,4 +65,696 @@ type StateHistoryParams struct {
 	DashboardUID string
 	// Filter by dashboard's panel ID. Requires Dashboard UID to be specified.
 	PanelID int64
+	// Also return the annotations of the dashboard and panel of the query, or of the ones the rule is linked to.
+	// The response is a StateHistoryWithAnnotations. Requires a rule UID or a dashboard UID.
+	// in:query
+	// required: false
+	Annotations bool `json:"annotations"`
+}
+
+// swagger:model
+type StateHistoryWithAnnotations struct {
+	History     *data.Frame              `json:"history"`
+	Annotations []StateHistoryAnnotation `json:"annotations"`
+}
+
+// swagger:model
+type StateHistoryAnnotation struct {
+	ID           int64  `json:"id"`
+	DashboardUID string `json:"dashboardUID"`
+	PanelID      int64  `json:"panelId"`
+	// The time of the annotation in milliseconds.
+	Time int64 `json:"time"`
+	// The end time of region annotations in milliseconds.
+	TimeEnd int64    `json:"timeEnd"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+	// The UIDs of the rules linked to the panel of the annotation, or to the dashboard of annotations without a panel.
+	RuleUIDs []string `json:"ruleUIDs"`
+}
+
+// swagger:route GET /v1/rules/history/instance/{Fingerprint} history RouteGetStateHistoryForInstance
+//
+// Get the state history of an alert instance.
+//
+// Returns the state transitions of a single alert instance, identified by the fingerprint of its labels, ordered by time.
+// The transitions of all versions of the rule that produced the instance are returned.
+// Requires the state history to be stored in Loki.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryInstance
+//       400: ValidationError
+//       403: ForbiddenError
+//       500: Failure
+
+// swagger:parameters RouteGetStateHistoryForInstance
+type StateHistoryInstanceParams struct {
+	// The fingerprint of the alert instance.
+	// in:path
+	Fingerprint string
+	// The timestamp of the start point of the time range the history is obtained.
+	// in:query
+	// required: false
+	From int64 `json:"from"`
+	// The timestamp of the end point of the time range the history is obtained.
+	// in:query
+	// required: false
+	To int64 `json:"to"`
+	// Limits the number of state transitions that are returned.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+	// Filter by rule UID.
+	// in:query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+}
+
+// swagger:model
+type StateHistoryInstance struct {
+	Fingerprint string `json:"fingerprint"`
+	// The labels of the alert instance in its latest transition.
+	Labels      map[string]string                `json:"labels"`
+	Transitions []StateHistoryInstanceTransition `json:"transitions"`
+}
+
+// swagger:model
+type StateHistoryInstanceTransition struct {
+	RuleUID   string                 `json:"ruleUID"`
+	RuleTitle string                 `json:"ruleTitle"`
+	Previous  string                 `json:"previous"`
+	Current   string                 `json:"current"`
+	Values    map[string]interface{} `json:"values,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	// format: date-time
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// swagger:route GET /v1/rules/history/stream history RouteGetStateHistoryStream
+//
+// Stream state transitions.
+//
+// Streams alert state transitions as Server-Sent Events as soon as they happen.
+// Every event is a StateTransitionEvent encoded as JSON in the data field of an event of type 'transition'.
+// Only transitions of rules the user has access to are streamed. Transitions are dropped if the client does not keep up.
+//   Example: /v1/rules/history/stream?matcher={"Type":0,"Name":"team","Value":"ops"}
+//
+//     Produces:
+//     - text/event-stream
+//
+//     Responses:
+//       200: StateHistoryStream
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:parameters RouteGetStateHistoryStream
+type StateHistoryStreamParams struct {
+	// Filter by rule UID.
+	// in:query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+	// Filter by labels of the alert instance. Every matcher is a JSON encoded label matcher and all matchers must match.
+	// in:query
+	// required: false
+	Matchers []string `json:"matcher"`
+}
+
+// swagger:response StateHistoryStream
+type StateHistoryStream struct {
+	// in:body
+	Event StateTransitionEvent
+}
+
+// swagger:model
+type StateTransitionEvent struct {
+	RuleUID      string             `json:"ruleUID"`
+	RuleTitle    string             `json:"ruleTitle"`
+	RuleGroup    string             `json:"ruleGroup"`
+	FolderUID    string             `json:"folderUID"`
+	Labels       map[string]string  `json:"labels"`
+	Fingerprint  string             `json:"fingerprint"`
+	Previous     string             `json:"previous"`
+	Current      string             `json:"current"`
+	Values       map[string]float64 `json:"values,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	DashboardUID string             `json:"dashboardUID,omitempty"`
+	PanelID      int64              `json:"panelID,omitempty"`
+	// format: date-time
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// swagger:route POST /v1/rules/history/_compact history RouteCompactStateHistory
+//
+// Apply state history retention.
+//
+// Applies the configured state history retention to the state history of the current organization.
+// With dryRun the number of state transitions that would be deleted is reported without deleting anything.
+// Only available if the state history is stored in annotations and the retention job is enabled.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryCompaction
+//       403: ForbiddenError
+//       404: NotFound
+//       500: Failure
+
+// swagger:parameters RouteCompactStateHistory
+type CompactStateHistoryParams struct {
+	// Report the state transitions that would be deleted without deleting them.
+	// in:query
+	// required: false
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:model
+type StateHistoryCompaction struct {
+	OrgID  int64 `json:"orgId"`
+	DryRun bool  `json:"dryRun"`
+	// The number of state transitions older than the configured maximum age.
+	ExpiredTransitions int64 `json:"expiredTransitions"`
+	// The number of state transitions exceeding the maximum number of transitions per rule.
+	ExcessTransitions int64 `json:"excessTransitions"`
+	// The number of alert rules with more state transitions than allowed.
+	RulesCompacted int `json:"rulesCompacted"`
+}
+
+// swagger:route POST /v1/rules/history/_purge history RoutePurgeStateHistory
+//
+// Purge state history of deleted rules.
+//
+// Deletes the state history of the alert rules of the current organization that do not exist anymore,
+// once their last state transition is older than the configured grace period.
+// With dryRun the rules and the number of state transitions that would be deleted are reported without deleting anything.
+// Only available if the state history is stored in annotations and the retention job is enabled.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryPurge
+//       403: ForbiddenError
+//       404: NotFound
+//       500: Failure
+
+// swagger:parameters RoutePurgeStateHistory
+type PurgeStateHistoryParams struct {
+	// Report the state transitions that would be deleted without deleting them.
+	// in:query
+	// required: false
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:model
+type StateHistoryPurge struct {
+	OrgID  int64 `json:"orgId"`
+	DryRun bool  `json:"dryRun"`
+	// The deleted alert rules whose state history is older than the grace period.
+	Rules []StateHistoryDeletedRule `json:"rules"`
+	// The total number of state transitions of the deleted alert rules.
+	Transitions int64 `json:"transitions"`
+}
+
+// swagger:model
+type StateHistoryDeletedRule struct {
+	RuleID int64 `json:"ruleId"`
+	// The UID of the rule, empty if the state history was recorded before the UID was stored with it.
+	RuleUID string `json:"ruleUid,omitempty"`
+	// The number of state transitions of the rule.
+	Transitions int64 `json:"transitions"`
+}
+
+// swagger:route GET /v1/rules/history/summary history RouteGetStateHistorySummary
+//
+// Summarize state history.
+//
+// Counts the state transitions into every state per interval over the requested time range.
+// The transitions can be grouped by rule or by an instance label. It accepts the same filters as the state history query.
+// Grouping by label requires the state history to be stored in Loki.
+//   Example: /v1/rules/history/summary?from=1704067200&to=1704153600&interval=3600&groupBy=rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistorySummary
+//       400: ValidationError
+//       403: ForbiddenError
+//       500: Failure
+
+// swagger:parameters RouteGetStateHistorySummary
+type StateHistorySummaryParams struct {
+	// The timestamp of the start point of the time range.
+	// in:query
+	// required: true
+	From int64 `json:"from"`
+	// The timestamp of the end point of the time range.
+	// in:query
+	// required: true
+	To int64 `json:"to"`
+	// The size of the intervals in seconds. Defaults to a size that splits the time range into 100 intervals.
+	// in:query
+	// required: false
+	Interval int64 `json:"interval"`
+	// Group the transitions by 'rule' or by the value of the given instance label.
+	// in:query
+	// required: false
+	GroupBy string `json:"groupBy"`
+	// Filter by rule UID.
+	// in:query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+	// Limits the number of state transitions that are summarized.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistorySummary struct {
+	// format: date-time
+	From time.Time `json:"from"`
+	// format: date-time
+	To time.Time `json:"to"`
+	// The size of the intervals in seconds.
+	Interval int64                      `json:"interval"`
+	GroupBy  string                     `json:"groupBy,omitempty"`
+	Groups   []StateHistorySummaryGroup `json:"groups"`
+}
+
+// swagger:model
+type StateHistorySummaryGroup struct {
+	// The rule UID or label value of the group, empty if the summary is not grouped.
+	Key     string                      `json:"key"`
+	Total   map[string]int64            `json:"total"`
+	Buckets []StateHistorySummaryBucket `json:"buckets"`
+}
+
+// swagger:model
+type StateHistorySummaryBucket struct {
+	// The start of the interval.
+	// format: date-time
+	Time   time.Time        `json:"time"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// swagger:route GET /v1/rules/history/uptime history RouteGetStateHistoryUptime
+//
+// Compute the uptime of alert rules.
+//
+// Computes the percentage of time every rule spent in the Normal state over the requested time range, from its state transitions.
+// A rule is down while any of its instances is alerting. The time spent in NoData or Error can be counted as up, down, or be ignored.
+// The uptime can also be aggregated per rule group. It accepts the same filters as the state history query.
+//   Example: /v1/rules/history/uptime?from=1704067200&to=1706745600&noData=ignore&error=down&groupBy=ruleGroup
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryUptime
+//       400: ValidationError
+//       403: ForbiddenError
+//       500: Failure
+
+// swagger:parameters RouteGetStateHistoryUptime
+type StateHistoryUptimeParams struct {
+	// The timestamp of the start point of the time range.
+	// in:query
+	// required: true
+	From int64 `json:"from"`
+	// The timestamp of the end point of the time range.
+	// in:query
+	// required: true
+	To int64 `json:"to"`
+	// How the time in NoData is counted: 'up', 'down' or 'ignore'. Defaults to 'ignore'.
+	// in:query
+	// required: false
+	NoData string `json:"noData"`
+	// How the time in Error is counted: 'up', 'down' or 'ignore'. Defaults to 'down'.
+	// in:query
+	// required: false
+	Error string `json:"error"`
+	// Also aggregate the uptime of the rules by 'ruleGroup'.
+	// in:query
+	// required: false
+	GroupBy string `json:"groupBy"`
+	// Filter by rule UID.
+	// in:query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+	// Limits the number of state transitions the uptime is computed from.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryUptime struct {
+	// format: date-time
+	From time.Time `json:"from"`
+	// format: date-time
+	To      time.Time `json:"to"`
+	NoData  string    `json:"noData"`
+	Error   string    `json:"error"`
+	GroupBy string    `json:"groupBy,omitempty"`
+	// The rules with state transitions in the time range.
+	Rules []StateHistoryRuleUptime `json:"rules"`
+	// The rule groups of the rules, only when grouped by rule group.
+	Groups []StateHistoryGroupUptime `json:"groups,omitempty"`
+}
+
+// swagger:model
+type StateHistoryRuleUptime struct {
+	RuleUID string `json:"ruleUID"`
+	StateHistoryUptimeValues
+}
+
+// swagger:model
+type StateHistoryGroupUptime struct {
+	FolderUID string   `json:"folderUID"`
+	RuleGroup string   `json:"ruleGroup"`
+	RuleUIDs  []string `json:"ruleUIDs"`
+	StateHistoryUptimeValues
+}
+
+// swagger:model
+type StateHistoryUptimeValues struct {
+	// The percentage of the counted time the rules were up, absent when none of the time was counted.
+	Uptime *float64 `json:"uptime,omitempty"`
+	// The time in seconds the rules were up.
+	UpSeconds float64 `json:"upSeconds"`
+	// The time in seconds the rules were down.
+	DownSeconds float64 `json:"downSeconds"`
+	// The time in seconds that was not counted.
+	IgnoredSeconds float64 `json:"ignoredSeconds"`
+}
+
+// swagger:route GET /v1/rules/history/panel history RouteGetStateHistoryPanel
+//
+// Query the state history of a dashboard panel.
+//
+// Merges the state history of all alert rules linked to a dashboard panel into a single frame aligned on time, for the state timeline of the panel.
+// The frame has a time field and a field per rule, named after the rule title and labeled with its UID, with the state of the rule from that time on.
+// The state of a rule is the state of its most severe instance. Without a panel ID the rules of the whole dashboard are merged.
+//   Example: /v1/rules/history/panel?dashboardUID=abc&panelID=2&from=1704067200&to=1704153600
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistory
+//       400: ValidationError
+//       403: ForbiddenError
+//       500: Failure
+
+// swagger:parameters RouteGetStateHistoryPanel
+type StateHistoryPanelParams struct {
+	// The UID of the dashboard the rules are linked to.
+	// in:query
+	// required: true
+	DashboardUID string `json:"dashboardUID"`
+	// The ID of the panel the rules are linked to.
+	// in:query
+	// required: false
+	PanelID int64 `json:"panelID"`
+	// The timestamp of the start point of the time range.
+	// in:query
+	// required: true
+	From int64 `json:"from"`
+	// The timestamp of the end point of the time range.
+	// in:query
+	// required: true
+	To int64 `json:"to"`
+	// Limits the number of state transitions read per rule.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:route GET /v1/rules/history/versions history RouteGetStateHistoryRuleVersions
+//
+// Query the state history of an alert rule by rule version.
+//
+// Splits the state history of the rule by the versions of the rule, from the time each version was saved until the next one,
+// and counts the state transitions of every version so the flappiness of the versions can be compared.
+// With a version, only that version is returned, with the state history during which it was the current version.
+// The oldest versions are not returned once they are deleted to keep the configured number of versions.
+//   Example: /v1/rules/history/versions?ruleUID=abc&from=1704067200&to=1706745600
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryRuleVersions
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+//       500: Failure
+
+// swagger:parameters RouteGetStateHistoryRuleVersions
+type StateHistoryRuleVersionsParams struct {
+	// The UID of the rule.
+	// in:query
+	// required: true
+	RuleUID string `json:"ruleUID"`
+	// Only return this version of the rule, with its state history.
+	// in:query
+	// required: false
+	Version int64 `json:"version"`
+	// The timestamp of the start point of the time range. Defaults to the time the oldest version was saved.
+	// in:query
+	// required: false
+	From int64 `json:"from"`
+	// The timestamp of the end point of the time range. Defaults to now.
+	// in:query
+	// required: false
+	To int64 `json:"to"`
+	// Limits the number of state transitions that are read.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryRuleVersions struct {
+	RuleUID string `json:"ruleUID"`
+	// The versions of the rule that were current during the time range, from the oldest to the newest.
+	Versions []StateHistoryRuleVersion `json:"versions"`
+	// The state history of the requested version, only when a version is requested.
+	History *data.Frame `json:"history,omitempty"`
+}
+
+// swagger:model
+type StateHistoryRuleVersion struct {
+	Version int64 `json:"version"`
+	// The start of the time the version was current, within the time range.
+	// format: date-time
+	From time.Time `json:"from"`
+	// The end of the time the version was current, within the time range.
+	// format: date-time
+	To time.Time `json:"to"`
+	// True for the current version of the rule.
+	Current bool `json:"current"`
+	// The number of state transitions of the instances of the rule while the version was current.
+	Transitions int64 `json:"transitions"`
+	// The number of transitions per hour, to compare versions that were current for different durations.
+	TransitionsPerHour float64 `json:"transitionsPerHour"`
+	// The number of transitions into every state.
+	Counts map[string]int64 `json:"counts"`
+}
+
+// swagger:route POST /v1/rules/history/_import history RouteImportStateHistory
+//
+// Import state history.
+//
+// Imports alert state history recorded in an external Loki instance, or exported from the Loki query_range API,
+// into the state history backend of this instance, so state history is not lost when migrating between backends.
+// Transitions of rules that do not exist in the current organization are skipped. Only available to server admins.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryImportResult
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+//       500: Failure
+
+// swagger:parameters RouteImportStateHistory
+type ImportStateHistoryParams struct {
+	// in:body
+	Body StateHistoryImport
+}
+
+// swagger:model
+type StateHistoryImport struct {
+	// The Loki instance to read the state history from. Either loki or streams is required.
+	Loki *StateHistoryImportLoki `json:"loki,omitempty"`
+	// State history exported from Loki, the data.result array of a query_range response.
+	Streams []StateHistoryImportStream `json:"streams,omitempty"`
+	// The timestamp of the start point of the time range to import. Required when importing from Loki.
+	From int64 `json:"from,omitempty"`
+	// The timestamp of the end point of the time range to import. Defaults to now.
+	To int64 `json:"to,omitempty"`
+	// The ID of the organization the state history was recorded for in the source. Defaults to the current organization.
+	SourceOrgID int64 `json:"sourceOrgId,omitempty"`
+	// Maps the rule UIDs of the source to the UIDs of the rules in the current organization.
+	RuleUIDs map[string]string `json:"ruleUIDs,omitempty"`
+	// Read and map the state history without importing it.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// swagger:model
+type StateHistoryImportLoki struct {
+	URL               string `json:"url"`
+	TenantID          string `json:"tenantId,omitempty"`
+	BasicAuthUser     string `json:"basicAuthUser,omitempty"`
+	BasicAuthPassword string `json:"basicAuthPassword,omitempty"`
+}
+
+// swagger:model
+type StateHistoryImportStream struct {
+	Stream map[string]string `json:"stream"`
+	// Pairs of a timestamp in nanoseconds and a log line, both as strings.
+	Values [][2]string `json:"values"`
+}
+
+// swagger:model
+type StateHistoryImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// The number of state transitions imported, or that would be imported on a dry run.
+	Imported int `json:"imported"`
+	// The number of state transitions that could not be read or belong to missing rules.
+	Skipped int `json:"skipped"`
+	// The UIDs of the rules that do not exist in the current organization.
+	MissingRules []string `json:"missingRules"`
+}
+
+// swagger:route GET /v1/rules/history/prometheus/api/v1/query history RouteQueryStateHistoryAlerts
+//
+// Query the alert states as Prometheus ALERTS series at a single point in time.
+//
+// Computes the ALERTS and ALERTS_FOR_STATE series of the Grafana-managed alerts from the state history, in the shape of
+// the Prometheus instant query API, so tools written for the alerts of Prometheus can read them. The query is a series selector
+// of ALERTS or ALERTS_FOR_STATE, PromQL functions and operators are not supported. The series have the labels of the alert instances
+// and the __alert_rule_uid__ label with the UID of their rule. The alert instances without state transitions in the lookback
+// before the evaluation time are not returned.
+//   Example: /v1/rules/history/prometheus/api/v1/query?query=ALERTS{alertstate="firing"}&time=1704067200
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryAlertsResponse
+//       400: StateHistoryAlertsResponse
+//       403: ForbiddenError
+//       500: StateHistoryAlertsResponse
+
+// swagger:parameters RouteQueryStateHistoryAlerts
+type StateHistoryAlertsQueryParams struct {
+	// The series selector of ALERTS or ALERTS_FOR_STATE.
+	// in:query
+	// required: true
+	Query string `json:"query"`
+	// The evaluation time, a unix timestamp in seconds or RFC 3339. Defaults to now.
+	// in:query
+	// required: false
+	Time string `json:"time"`
+	// How far before the evaluation time the state transitions are read, a duration like 6h or seconds. Defaults to 24h.
+	// in:query
+	// required: false
+	Lookback string `json:"lookback"`
+	// Limits the number of state transitions the series are computed from.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:route GET /v1/rules/history/prometheus/api/v1/query_range history RouteQueryRangeStateHistoryAlerts
+//
+// Query the alert states as Prometheus ALERTS series over a range of time.
+//
+// Computes the ALERTS and ALERTS_FOR_STATE series of the Grafana-managed alerts from the state history, in the shape of
+// the Prometheus range query API. It accepts the same selectors as the instant query.
+//   Example: /v1/rules/history/prometheus/api/v1/query_range?query=ALERTS_FOR_STATE{alertname="cpu"}&start=1704067200&end=1704153600&step=5m
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryAlertsResponse
+//       400: StateHistoryAlertsResponse
+//       403: ForbiddenError
+//       500: StateHistoryAlertsResponse
+
+// swagger:parameters RouteQueryRangeStateHistoryAlerts
+type StateHistoryAlertsQueryRangeParams struct {
+	// The series selector of ALERTS or ALERTS_FOR_STATE.
+	// in:query
+	// required: true
+	Query string `json:"query"`
+	// The start of the time range, a unix timestamp in seconds or RFC 3339.
+	// in:query
+	// required: true
+	Start string `json:"start"`
+	// The end of the time range, a unix timestamp in seconds or RFC 3339.
+	// in:query
+	// required: true
+	End string `json:"end"`
+	// The interval between the evaluation times, a duration like 1m or seconds.
+	// in:query
+	// required: true
+	Step string `json:"step"`
+	// How far before the start the state transitions are read, a duration like 6h or seconds. Defaults to 24h.
+	// in:query
+	// required: false
+	Lookback string `json:"lookback"`
+	// Limits the number of state transitions the series are computed from.
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryAlertsResponse struct {
+	// in: body
+	DiscoveryBase
+	// in: body
+	Data *StateHistoryAlertsData `json:"data,omitempty"`
+}
+
+// swagger:model
+type StateHistoryAlertsData struct {
+	// vector for the instant queries, matrix for the range queries.
+	ResultType string                     `json:"resultType"`
+	Result     []StateHistoryAlertsSeries `json:"result"`
+}
+
+// swagger:model
+type StateHistoryAlertsSeries struct {
+	Metric map[string]string `json:"metric"`
+	// The sample of an instant query.
+	Value *StateHistoryAlertsSample `json:"value,omitempty"`
+	// The samples of a range query.
+	Values []StateHistoryAlertsSample `json:"values,omitempty"`
+}
+
+// StateHistoryAlertsSample is encoded as Prometheus encodes samples, [unix timestamp in seconds, "value"].
+// swagger:model
+type StateHistoryAlertsSample struct {
+	Time  time.Time
+	Value float64
+}
+
+func (s StateHistoryAlertsSample) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{float64(s.Time.UnixMilli()) / 1000, strconv.FormatFloat(s.Value, 'f', -1, 64)})
 }
@@ -16,6 +16,8 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
@@ -23,6 +25,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -40,6 +43,45 @@ func toMacaronPath(path string) string {
 	}))
 }
 
+// errRouteDisabled is returned by the operations disabled in [unified_alerting.routes], the operation and its gate are
+// in the extra payload of the response
+var errRouteDisabled = errutil.NotImplemented("alerting.routeDisabled").MustTemplate(
+	"The {{ .Public.operation }} operation of the Alerting API is disabled by gate {{ .Public.gate }}",
+	errutil.WithPublic("The {{ .Public.operation }} operation of the Alerting API is disabled"),
+)
+
+// routeFamily returns the family of the routes of a generated API, the lowercase name of the API without the Api suffix
+func routeFamily(classname string) string {
+	return strings.ToLower(strings.TrimSuffix(classname, "Api"))
+}
+
+// sloGroup returns the SLO group of the routes of a generated API, e.g. HistoryApi. The group is assigned to the
+// family of the routes, the lowercase name of the API without the Api suffix, in [unified_alerting.slo_groups].
+func (api *API) sloGroup(classname string) requestmeta.SLOGroup {
+	return api.Cfg.UnifiedAlerting.SLOGroup(routeFamily(classname))
+}
+
+// gate returns the middleware of an operation of a generated API, e.g. RoutePurgeStateHistory of HistoryApi, that
+// responds with 501 Not Implemented while the operation is disabled in [unified_alerting.routes]. An operation gated
+// by a feature toggle is enabled while the toggle is enabled for the request.
+func (api *API) gate(classname, operation string) web.Handler {
+	gate := api.Cfg.UnifiedAlerting.RouteGate(routeFamily(classname), operation)
+	disabled := func(c *contextmodel.ReqContext) {
+		c.WriteErr(errRouteDisabled.Build(errutil.TemplateData{Public: map[string]any{"operation": operation, "gate": gate}}))
+	}
+	switch gate {
+	case setting.AlertingRouteEnabled:
+		return func(*contextmodel.ReqContext) {}
+	case setting.AlertingRouteDisabled:
+		return disabled
+	}
+	return func(c *contextmodel.ReqContext) {
+		if api.FeatureManager == nil || !api.FeatureManager.IsEnabled(c.Req.Context(), gate) {
+			disabled(c)
+		}
+	}
+}
+
 func getDatasourceByUID(ctx *contextmodel.ReqContext, cache datasources.CacheService, expectedType apimodels.Backend) (*datasources.DataSource, error) {
 	datasourceUID := web.Params(ctx.Req)[":DatasourceUID"]
 	ds, err := cache.GetDatasourceByUID(ctx.Req.Context(), datasourceUID, ctx.SignedInUser, ctx.SkipDSCache)
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"encoding/json"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,13 +12,16 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/auth"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	models2 "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -41,6 +46,53 @@ func TestToMacaronPath(t *testing.T) {
 	}
 }
 
+func TestSLOGroup(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.SLOGroups = map[string]requestmeta.SLOGroup{"history": requestmeta.SLOGroupHighMedium}
+	api := &API{Cfg: cfg}
+
+	assert.Equal(t, requestmeta.SLOGroupHighMedium, api.sloGroup("HistoryApi"))
+	assert.Equal(t, requestmeta.SLOGroupHighSlow, api.sloGroup("RulerApi"))
+}
+
+func TestGate(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.RouteGates = map[string]string{
+		"history":                "alertingStateHistoryImport",
+		"RoutePurgeStateHistory": setting.AlertingRouteDisabled,
+		"RouteGetStateHistory":   setting.AlertingRouteEnabled,
+	}
+
+	serve := func(api *API, operation string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx := &contextmodel.ReqContext{
+			Context: &web.Context{
+				Req:  httptest.NewRequest(http.MethodGet, "/api/v1/rules/history", nil),
+				Resp: web.NewResponseWriter(http.MethodGet, recorder),
+			},
+			Logger: log.NewNopLogger(),
+		}
+		api.gate("HistoryApi", operation).(func(*contextmodel.ReqContext))(ctx)
+		return recorder
+	}
+
+	api := &API{Cfg: cfg, FeatureManager: featuremgmt.WithFeatures()}
+	recorder := serve(api, "RoutePurgeStateHistory")
+	require.Equal(t, http.StatusNotImplemented, recorder.Code)
+	body := map[string]any{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, "alerting.routeDisabled", body["messageId"])
+	assert.Equal(t, "The RoutePurgeStateHistory operation of the Alerting API is disabled", body["message"])
+	assert.Equal(t, map[string]any{"operation": "RoutePurgeStateHistory", "gate": "disabled"}, body["extra"])
+
+	assert.Equal(t, http.StatusOK, serve(api, "RouteGetStateHistory").Code)
+	assert.Equal(t, http.StatusNotImplemented, serve(api, "RouteImportStateHistory").Code)
+
+	api.FeatureManager = featuremgmt.WithFeatures("alertingStateHistoryImport")
+	assert.Equal(t, http.StatusOK, serve(api, "RouteImportStateHistory").Code)
+	assert.Equal(t, http.StatusNotImplemented, serve(api, "RoutePurgeStateHistory").Code)
+}
+
 func TestAlertingProxy_createProxyContext(t *testing.T) {
 	ctx := &contextmodel.ReqContext{
 		Context: &web.Context{
@@ -19,7 +19,7 @@ func NewAPIMetrics(r prometheus.Registerer) *API {
 				Help:      "Histogram of requests to the Alerting API",
 				Buckets:   prometheus.DefBuckets,
 			},
-			[]string{"method", "route", "status_code", "backend"},
+			[]string{"method", "route", "status_code", "backend", "slo_group"},
 		),
 	}
 }
@@ -14,6 +14,17 @@ type Historian struct {
 	WritesFailed      *prometheus.CounterVec
 	WriteDuration     *instrument.HistogramCollector
 	BytesWritten      prometheus.Counter
+	RetentionDeleted  *prometheus.CounterVec
+	RetentionFailed   *prometheus.CounterVec
+	WebhookDelivered  *prometheus.CounterVec
+	WebhookFailed     *prometheus.CounterVec
+	WebhookDropped    *prometheus.CounterVec
+	WebhookRetries    *prometheus.CounterVec
+	OTLPExported      *prometheus.CounterVec
+	OTLPFailed        *prometheus.CounterVec
+	OTLPDropped       *prometheus.CounterVec
+	OTLPRetries       *prometheus.CounterVec
+	LabelsDropped     *prometheus.CounterVec
 }
 
 func NewHistorianMetrics(r prometheus.Registerer, subsystem string) *Historian {
@@ -61,5 +72,71 @@ func NewHistorianMetrics(r prometheus.Registerer, subsystem string) *Historian {
 			Name:      "state_history_writes_bytes_total",
 			Help:      "The total number of bytes sent within a batch to the state history store. Only valid when using the Loki store.",
 		}),
+		RetentionDeleted: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_retention_deleted_total",
+			Help:      "The total number of state history records deleted by the retention job. Only valid when using the annotations store.",
+		}, []string{"org", "reason"}),
+		RetentionFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_retention_failed_total",
+			Help:      "The total number of failed runs of the state history retention job.",
+		}, []string{"org"}),
+		WebhookDelivered: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_webhook_delivered_total",
+			Help:      "The total number of batches of state transitions delivered to webhooks.",
+		}, []string{"org"}),
+		WebhookFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_webhook_failed_total",
+			Help:      "The total number of batches of state transitions that could not be delivered to webhooks after all attempts.",
+		}, []string{"org"}),
+		WebhookDropped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_webhook_dropped_total",
+			Help:      "The total number of batches of state transitions dropped because the webhook queue was full.",
+		}, []string{"org"}),
+		WebhookRetries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_webhook_retries_total",
+			Help:      "The total number of retried deliveries of state transitions to webhooks.",
+		}, []string{"org"}),
+		OTLPExported: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_otlp_exported_total",
+			Help:      "The total number of batches of state transitions exported to the OTLP collector.",
+		}, []string{"org"}),
+		OTLPFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_otlp_failed_total",
+			Help:      "The total number of batches of state transitions that could not be exported to the OTLP collector after all attempts.",
+		}, []string{"org"}),
+		OTLPDropped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_otlp_dropped_total",
+			Help:      "The total number of batches of state transitions dropped because the OTLP export queue was full.",
+		}, []string{"org"}),
+		OTLPRetries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_otlp_retries_total",
+			Help:      "The total number of retried exports of state transitions to the OTLP collector.",
+		}, []string{"org"}),
+		LabelsDropped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_labels_dropped_total",
+			Help:      "The total number of instance labels not written to the state history store, because they are sensitive or over the limit of labels. Only valid when using the Loki store.",
+		}, []string{"org", "reason"}),
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/web"
@@ -75,6 +76,8 @@ func Instrument(
 			"route":       normalizedPath,
 			"status_code": fmt.Sprint(res.Status()),
 			"backend":     backend,
+			// the SLO group is set by the middlewares of the route, see [unified_alerting.slo_groups]
+			"slo_group": string(requestmeta.GetRequestMetaData(c.Req.Context()).SLOGroup),
 		}
 		res.WriteTo(c)
 		metrics.RequestDuration.With(ls).Observe(time.Since(start).Seconds())
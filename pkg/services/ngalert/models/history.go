@@ -13,8 +13,22 @@ type HistoryQuery struct {
 	DashboardUID string
 	PanelID      int64
 	Labels       map[string]string
-	From         time.Time
-	To           time.Time
-	Limit        int
+	// Fingerprint filters the history of a single alert instance, a hash of its labels.
+	Fingerprint string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	// PageSize pages the history from the newest to the oldest transition, the backends return the token of the
+	// next page in the metadata of the frame. The history is not paged when it is zero.
+	PageSize int
+	// Continue is the token of the page to return, the first page when it is empty.
+	Continue     string
 	SignedInUser identity.Requester
 }
+
+// AlertRuleVersion is a saved version of an alert rule. It was the current version of the rule from Created until
+// the next version was saved.
+type AlertRuleVersion struct {
+	Version int64
+	Created time.Time
+}
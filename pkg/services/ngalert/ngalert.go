@@ -140,6 +140,9 @@ type AlertNG struct {
 	RecordingWriter     schedule.RecordingWriter
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historyRetention    *historian.AnnotationRetention
+	historyWebhooks     *historian.WebhookBackend
+	historyOTLP         *historian.OTLPBackend
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	Api                 *api.API
@@ -398,6 +401,32 @@ func (ng *AlertNG) init() error {
 	if err != nil {
 		return err
 	}
+	// State transitions are always broadcast to stream subscribers, regardless of the configured history backend.
+	historyStream := historian.NewStreamBackend(historian.DefaultStreamBufferSize, log.New("ngalert.state.historian", "backend", "stream"))
+	secondaries := []historian.Backend{historyStream}
+	if ng.Cfg.UnifiedAlerting.StateHistory.Webhooks.Enabled {
+		ng.historyWebhooks = historian.NewWebhookBackend(ng.Cfg.UnifiedAlerting.StateHistory.Webhooks, historian.NewRequester(), ng.Metrics.GetHistorianMetrics(), log.New("ngalert.state.historian", "backend", "webhook"))
+		secondaries = append(secondaries, ng.historyWebhooks)
+	}
+	if ng.Cfg.UnifiedAlerting.StateHistory.OTLP.Enabled {
+		ng.historyOTLP = historian.NewOTLPBackend(ng.Cfg.UnifiedAlerting.StateHistory.OTLP, historian.NewRequester(), ng.Metrics.GetHistorianMetrics(), log.New("ngalert.state.historian", "backend", "otlp"))
+		secondaries = append(secondaries, ng.historyOTLP)
+	}
+	history = historian.NewMultipleBackend(history, secondaries...)
+	var historyRetention api.HistoryRetention
+	if usesAnnotationsHistorian(ng.Cfg.UnifiedAlerting.StateHistory) && ng.Cfg.UnifiedAlerting.StateHistory.Retention.Enabled {
+		ng.historyRetention = historian.NewAnnotationRetention(
+			historian.NewAnnotationRetentionStore(ng.SQLStore),
+			ng.Cfg.UnifiedAlerting.StateHistory.Retention,
+			ng.Metrics.GetHistorianMetrics(),
+			log.New("ngalert.state.historian.retention"),
+		)
+		historyRetention = ng.historyRetention
+	}
+	var historyImporter api.HistoryImporter
+	if target, ok := history.(historian.Importer); ok {
+		historyImporter = historian.NewStateHistoryImporter(target, ng.store, ng.Metrics.GetHistorianMetrics(), log.New("ngalert.state.historian.import"), ng.tracer)
+	}
 	cfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
@@ -494,6 +523,11 @@ func (ng *AlertNG) init() error {
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		HistoryRetention:     historyRetention,
+		HistoryStream:        historyStream,
+		HistoryImporter:      historyImporter,
+		HistoryAnnotations:   ng.annotationsRepo,
+		HistoryRuleVersions:  ng.store,
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 	}
@@ -541,6 +575,21 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	if ng.historyRetention != nil {
+		children.Go(func() error {
+			return ng.historyRetention.Run(subCtx)
+		})
+	}
+	if ng.historyWebhooks != nil {
+		children.Go(func() error {
+			return ng.historyWebhooks.Run(subCtx)
+		})
+	}
+	if ng.historyOTLP != nil {
+		children.Go(func() error {
+			return ng.historyOTLP.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -640,9 +689,50 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		return backend, nil
 	}
 
+	if backend == historian.BackendTypeElasticsearch {
+		ecfg, err := historian.NewElasticsearchConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid elasticsearch configuration: %w", err)
+		}
+		req := historian.NewRequester()
+		elasticsearchBackendLogger := log.New("ngalert.state.historian", "backend", "elasticsearch")
+		backend := historian.NewElasticsearchBackend(elasticsearchBackendLogger, ecfg, req, met, tracer, rs, ac)
+
+		testConnCtx, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
+		defer cancelFunc()
+		if err := backend.TestConnection(testConnCtx); err != nil {
+			l.Error("Failed to communicate with configured Elasticsearch backend, state history may not be persisted", "error", err)
+		} else if err := backend.EnsureIndexTemplate(testConnCtx); err != nil {
+			l.Error("Failed to install the index template of the state history in Elasticsearch", "error", err)
+		}
+		return backend, nil
+	}
+
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
 
+// usesAnnotationsHistorian returns true if state history is written to annotations.
+func usesAnnotationsHistorian(cfg setting.UnifiedAlertingStateHistorySettings) bool {
+	if !cfg.Enabled {
+		return false
+	}
+	backend, _ := historian.ParseBackendType(cfg.Backend)
+	switch backend {
+	case historian.BackendTypeAnnotations:
+		return true
+	case historian.BackendTypeMultiple:
+		if b, _ := historian.ParseBackendType(cfg.MultiPrimary); b == historian.BackendTypeAnnotations {
+			return true
+		}
+		for _, sec := range cfg.MultiSecondaries {
+			if b, _ := historian.ParseBackendType(sec); b == historian.BackendTypeAnnotations {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ApplyStateHistoryFeatureToggles edits state history configuration to comply with currently active feature toggles.
 func ApplyStateHistoryFeatureToggles(cfg *setting.UnifiedAlertingStateHistorySettings, ft featuremgmt.FeatureToggles, logger log.Logger) {
 	backend, _ := historian.ParseBackendType(cfg.Backend)
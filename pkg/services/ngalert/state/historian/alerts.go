@@ -0,0 +1,282 @@
+package historian
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheus "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	// AlertsMetric is the name of the series of the pending and firing alerts, as the ALERTS series of Prometheus.
+	// The value of a sample is always 1, the state is in the alertstate label.
+	AlertsMetric = "ALERTS"
+	// AlertsForStateMetric is the name of the series of the time the active alerts became active, as the
+	// ALERTS_FOR_STATE series of Prometheus. The value of a sample is the unix timestamp in seconds.
+	AlertsForStateMetric = "ALERTS_FOR_STATE"
+
+	alertStateLabel   = "alertstate"
+	alertStatePending = "pending"
+	alertStateFiring  = "firing"
+
+	// MaxAlertsPoints is the maximum number of evaluation timestamps of a query, the same as Prometheus.
+	MaxAlertsPoints = 11000
+)
+
+var ErrInvalidAlertsQuery = errors.New("invalid alerts query")
+
+// AlertsQuery selects the series of ALERTS or ALERTS_FOR_STATE at every Step from Start to End.
+// An instant query has the same Start and End, and no Step.
+type AlertsQuery struct {
+	Matchers []*labels.Matcher
+	Start    time.Time
+	End      time.Time
+	Step     time.Duration
+}
+
+// ParseAlertsSelector parses a series selector of ALERTS or ALERTS_FOR_STATE, e.g. ALERTS{alertstate="firing"}.
+func ParseAlertsSelector(selector string) ([]*labels.Matcher, error) {
+	matchers, err := parser.ParseMetricSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertsQuery, err)
+	}
+	for _, m := range matchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual && (m.Value == AlertsMetric || m.Value == AlertsForStateMetric) {
+			return matchers, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: only the series of %s and %s can be selected", ErrInvalidAlertsQuery, AlertsMetric, AlertsForStateMetric)
+}
+
+// Validate checks the time range and the number of evaluation timestamps of the query.
+func (q AlertsQuery) Validate() error {
+	if q.End.Before(q.Start) {
+		return fmt.Errorf("%w: the end of the time range must not be before the start", ErrInvalidAlertsQuery)
+	}
+	if q.Step < 0 || (q.Step == 0 && !q.End.Equal(q.Start)) {
+		return fmt.Errorf("%w: the step must be positive", ErrInvalidAlertsQuery)
+	}
+	if q.Step > 0 && int64(q.End.Sub(q.Start)/q.Step) >= MaxAlertsPoints {
+		return fmt.Errorf("%w: the time range can be split into at most %d steps", ErrInvalidAlertsQuery, MaxAlertsPoints)
+	}
+	return nil
+}
+
+// RuleUID returns the rule UID the query selects with an equality matcher, so the history of the other rules is not read.
+func (q AlertsQuery) RuleUID() string {
+	for _, m := range q.Matchers {
+		if m.Name == models.RuleUIDLabel && m.Type == labels.MatchEqual {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+func (q AlertsQuery) timestamps() []time.Time {
+	if q.Step == 0 {
+		return []time.Time{q.Start}
+	}
+	res := make([]time.Time, 0, q.End.Sub(q.Start)/q.Step+1)
+	for ts := q.Start; !ts.After(q.End); ts = ts.Add(q.Step) {
+		res = append(res, ts)
+	}
+	return res
+}
+
+// AlertsSeries is a series of ALERTS or ALERTS_FOR_STATE.
+type AlertsSeries struct {
+	Labels  map[string]string
+	Samples []AlertsSample
+}
+
+// AlertsSample is the value of a series at an evaluation timestamp.
+type AlertsSample struct {
+	Time  time.Time
+	Value float64
+}
+
+// alertsInstance is the state of an alert instance, walked through its transitions ordered by time.
+type alertsInstance struct {
+	labels      map[string]string
+	transitions []summaryTransition
+	next        int
+	// state is the state before the next transition, known is false when it can not be parsed
+	state eval.State
+	known bool
+	// activeAt is the time the instance became pending or firing, zero when it is not known
+	activeAt time.Time
+}
+
+// Alerts computes the series of a state history frame as returned by any of the history backends, as Prometheus
+// computes the ALERTS and ALERTS_FOR_STATE series of its alerting rules. An alert instance is pending or firing at
+// a timestamp when its last transition before the timestamp was into Pending or Alerting, or when its first
+// transition after it was out of these states. The instances without transitions in the frame are not returned,
+// the history does not tell their state. The time an instance became active is not known when the frame starts
+// while it is active, its ALERTS_FOR_STATE samples are left out until it is active again.
+func Alerts(frame *data.Frame, q AlertsQuery) ([]AlertsSeries, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	transitions, err := readTransitions(frame)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].time.Before(transitions[j].time)
+	})
+
+	instances := map[string]*alertsInstance{}
+	keys := []string{}
+	for _, t := range transitions {
+		key := t.ruleUID + "/" + t.instance
+		inst, ok := instances[key]
+		if !ok {
+			inst = &alertsInstance{}
+			inst.state, inst.known = parseAlertsState(t.previous)
+			instances[key] = inst
+			keys = append(keys, key)
+		}
+		// the labels of the last transition are the current labels of the instance
+		inst.labels = alertsLabels(t)
+		inst.transitions = append(inst.transitions, t)
+	}
+	sort.Strings(keys)
+
+	series := map[string]*AlertsSeries{}
+	for _, ts := range q.timestamps() {
+		for _, key := range keys {
+			inst := instances[key]
+			inst.advance(ts)
+			if !inst.active() {
+				continue
+			}
+			addAlertsSample(series, q.Matchers, alertsSeriesLabels(AlertsMetric, inst.labels, inst.state), AlertsSample{Time: ts, Value: 1})
+			if !inst.activeAt.IsZero() {
+				addAlertsSample(series, q.Matchers, alertsSeriesLabels(AlertsForStateMetric, inst.labels, inst.state), AlertsSample{Time: ts, Value: float64(inst.activeAt.UnixMilli()) / 1000})
+			}
+		}
+	}
+
+	res := make([]AlertsSeries, 0, len(series))
+	for _, s := range series {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return labelsKey(res[i].Labels) < labelsKey(res[j].Labels)
+	})
+	return res, nil
+}
+
+// advance applies the transitions of the instance up to the timestamp.
+func (inst *alertsInstance) advance(ts time.Time) {
+	for ; inst.next < len(inst.transitions) && !inst.transitions[inst.next].time.After(ts); inst.next++ {
+		t := inst.transitions[inst.next]
+		wasActive, wasKnown := inst.active(), inst.known
+		inst.state, inst.known = parseAlertsState(t.state)
+		switch {
+		case !inst.active():
+			inst.activeAt = time.Time{}
+		case !wasActive && wasKnown:
+			inst.activeAt = t.time
+		}
+	}
+}
+
+// active returns true when the instance is pending or firing.
+func (inst *alertsInstance) active() bool {
+	return inst.known && (inst.state == eval.Pending || inst.state == eval.Alerting)
+}
+
+func parseAlertsState(formatted string) (eval.State, bool) {
+	if formatted == "" {
+		return eval.Normal, false
+	}
+	s, _, err := state.ParseFormattedState(formatted)
+	return s, err == nil
+}
+
+// alertsLabels returns the labels of the instance of a transition with the UID of its rule. The annotation backend
+// does not store the labels, they are read from the text of the annotation.
+func alertsLabels(t summaryTransition) map[string]string {
+	res := make(map[string]string, len(t.labels)+1)
+	if t.labels != nil {
+		for k, v := range t.labels {
+			res[k] = v
+		}
+	} else {
+		for k, v := range annotationLabels(t.instance) {
+			res[k] = v
+		}
+	}
+	if t.ruleUID != "" {
+		res[models.RuleUIDLabel] = t.ruleUID
+	}
+	return res
+}
+
+// annotationLabels parses the labels of the instance of an annotation, "title {a=1, b=2}", the title is the alertname.
+func annotationLabels(instance string) map[string]string {
+	res := map[string]string{}
+	title, rest, found := strings.Cut(instance, " {")
+	if title != "" {
+		res[prometheus.AlertNameLabel] = title
+	}
+	if !found {
+		return res
+	}
+	for _, pair := range strings.Split(strings.TrimSuffix(rest, "}"), ", ") {
+		if k, v, ok := strings.Cut(pair, "="); ok && k != "" {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func alertsSeriesLabels(metric string, instance map[string]string, s eval.State) map[string]string {
+	res := make(map[string]string, len(instance)+2)
+	for k, v := range instance {
+		res[k] = v
+	}
+	res[labels.MetricName] = metric
+	if metric == AlertsMetric {
+		res[alertStateLabel] = alertStatePending
+		if s == eval.Alerting {
+			res[alertStateLabel] = alertStateFiring
+		}
+	}
+	return res
+}
+
+// addAlertsSample adds the sample to its series when the series matches all the matchers.
+// The instances with the same labels are a single series, only the first sample at a timestamp is kept.
+func addAlertsSample(series map[string]*AlertsSeries, matchers []*labels.Matcher, lbs map[string]string, sample AlertsSample) {
+	for _, m := range matchers {
+		if !m.Matches(lbs[m.Name]) {
+			return
+		}
+	}
+	key := labelsKey(lbs)
+	s, ok := series[key]
+	if !ok {
+		s = &AlertsSeries{Labels: lbs}
+		series[key] = s
+	}
+	if n := len(s.Samples); n > 0 && s.Samples[n-1].Time.Equal(sample.Time) {
+		return
+	}
+	s.Samples = append(s.Samples, sample)
+}
+
+func labelsKey(lbs map[string]string) string {
+	return labels.FromMap(lbs).String()
+}
@@ -0,0 +1,105 @@
+package historian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlerts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return start.Add(time.Duration(m) * time.Minute) }
+
+	type entry struct {
+		time time.Time
+		LokiEntry
+	}
+	cpu := map[string]string{"alertname": "cpu", "instance": "a"}
+	disk := map[string]string{"alertname": "disk"}
+	entries := []entry{
+		{at(10), LokiEntry{RuleUID: "r1", Fingerprint: "1", Previous: "Normal", Current: "Pending", InstanceLabels: cpu}},
+		{at(20), LokiEntry{RuleUID: "r1", Fingerprint: "1", Previous: "Pending", Current: "Alerting", InstanceLabels: cpu}},
+		{at(40), LokiEntry{RuleUID: "r1", Fingerprint: "1", Previous: "Alerting", Current: "Normal", InstanceLabels: cpu}},
+		// firing before the history starts
+		{at(30), LokiEntry{RuleUID: "r2", Fingerprint: "2", Previous: "Alerting", Current: "Normal (NoData)", InstanceLabels: disk}},
+	}
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		line, err := json.Marshal(e.LokiEntry)
+		require.NoError(t, err)
+		times = append(times, e.time)
+		lines = append(lines, line)
+	}
+	frame := data.NewFrame("states",
+		data.NewField(dfTime, nil, times),
+		data.NewField(dfLine, nil, lines),
+	)
+	selector := func(t *testing.T, s string) AlertsQuery {
+		matchers, err := ParseAlertsSelector(s)
+		require.NoError(t, err)
+		return AlertsQuery{Matchers: matchers}
+	}
+
+	t.Run("instant query of the firing alerts", func(t *testing.T) {
+		q := selector(t, `ALERTS{alertstate="firing"}`)
+		q.Start, q.End = at(25), at(25)
+		res, err := Alerts(frame, q)
+		require.NoError(t, err)
+		require.Equal(t, []AlertsSeries{
+			{
+				Labels:  map[string]string{"__name__": "ALERTS", "alertstate": "firing", "alertname": "cpu", "instance": "a", "__alert_rule_uid__": "r1"},
+				Samples: []AlertsSample{{Time: at(25), Value: 1}},
+			},
+			{
+				Labels:  map[string]string{"__name__": "ALERTS", "alertstate": "firing", "alertname": "disk", "__alert_rule_uid__": "r2"},
+				Samples: []AlertsSample{{Time: at(25), Value: 1}},
+			},
+		}, res)
+	})
+
+	t.Run("range query of the pending and firing alerts", func(t *testing.T) {
+		q := selector(t, `ALERTS{alertname="cpu"}`)
+		q.Start, q.End, q.Step = at(0), at(50), 10*time.Minute
+		res, err := Alerts(frame, q)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "firing", res[0].Labels["alertstate"])
+		require.Equal(t, []AlertsSample{{Time: at(20), Value: 1}, {Time: at(30), Value: 1}}, res[0].Samples)
+		require.Equal(t, "pending", res[1].Labels["alertstate"])
+		require.Equal(t, []AlertsSample{{Time: at(10), Value: 1}}, res[1].Samples)
+	})
+
+	t.Run("ALERTS_FOR_STATE is the time the alerts became active", func(t *testing.T) {
+		q := selector(t, `ALERTS_FOR_STATE`)
+		q.Start, q.End, q.Step = at(0), at(50), 10*time.Minute
+		res, err := Alerts(frame, q)
+		require.NoError(t, err)
+		require.Len(t, res, 1, "the time the disk alert became active is not known")
+		require.Equal(t, map[string]string{"__name__": "ALERTS_FOR_STATE", "alertname": "cpu", "instance": "a", "__alert_rule_uid__": "r1"}, res[0].Labels)
+		activeAt := float64(at(10).Unix())
+		require.Equal(t, []AlertsSample{{Time: at(10), Value: activeAt}, {Time: at(20), Value: activeAt}, {Time: at(30), Value: activeAt}}, res[0].Samples)
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		_, err := ParseAlertsSelector(`up{job="grafana"}`)
+		require.ErrorIs(t, err, ErrInvalidAlertsQuery)
+		_, err = ParseAlertsSelector(`ALERTS{`)
+		require.ErrorIs(t, err, ErrInvalidAlertsQuery)
+
+		q := selector(t, `ALERTS`)
+		q.Start, q.End = at(10), at(0)
+		_, err = Alerts(frame, q)
+		require.ErrorIs(t, err, ErrInvalidAlertsQuery)
+		q.Start, q.End, q.Step = at(0), at(0).Add(MaxAlertsPoints*time.Second), time.Second
+		_, err = Alerts(frame, q)
+		require.ErrorIs(t, err, ErrInvalidAlertsQuery)
+	})
+
+	t.Run("labels of the annotation backend", func(t *testing.T) {
+		require.Equal(t, map[string]string{"alertname": "cpu", "instance": "a", "job": "node"}, annotationLabels("cpu {instance=a, job=node}"))
+	})
+}
@@ -142,10 +142,31 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 		To:           query.To.UnixMilli(),
 		SignedInUser: query.SignedInUser,
 	}
+	cursor, err := parseContinueToken(query.Continue)
+	if err != nil {
+		return nil, err
+	}
+	if query.PageSize > 0 {
+		// the annotations are found from the newest to the oldest, the page starts at the time of the cursor
+		if cursor != nil {
+			q.To = min(q.To, time.Unix(0, cursor.T).UnixMilli())
+		}
+		q.Limit = int64(cursor.limit(query.PageSize))
+	}
 	items, err := h.store.Find(ctx, &q)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query annotations for state history: %w", err)
 	}
+	var next *historyCursor
+	if query.PageSize > 0 {
+		times := make([]int64, len(items))
+		for i, item := range items {
+			times[i] = time.UnixMilli(item.Time).UnixNano()
+		}
+		var start, end int
+		start, end, next = cursor.page(times, query.PageSize)
+		items = items[start:end]
+	}
 
 	frame := data.NewFrame("states")
 
@@ -193,6 +214,9 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 	frame.Fields = append(frame.Fields, data.NewField("prev", lbls, prevStates))
 	frame.Fields = append(frame.Fields, data.NewField("next", lbls, nextStates))
 	frame.Fields = append(frame.Fields, data.NewField("data", lbls, values))
+	if query.PageSize > 0 {
+		setContinueToken(frame, next)
+	}
 
 	return frame, nil
 }
@@ -206,6 +230,7 @@ func buildAnnotations(rule history_model.RuleMeta, states []state.StateTransitio
 		logger.Debug("Alert state changed creating annotation", "newState", state.Formatted(), "oldState", state.PreviousFormatted())
 
 		annotationText, annotationData := BuildAnnotationTextAndData(rule, state.State)
+		setAnnotationRuleUID(annotationData, rule)
 
 		item := annotations.Item{
 			AlertID:   rule.ID,
@@ -222,6 +247,14 @@ func buildAnnotations(rule history_model.RuleMeta, states []state.StateTransitio
 	return items
 }
 
+// setAnnotationRuleUID records the UID of the rule in the data of an annotation, the annotations only reference the
+// rule by its ID that can not be resolved to the UID anymore once the rule is deleted.
+func setAnnotationRuleUID(data *simplejson.Json, rule history_model.RuleMeta) {
+	if rule.UID != "" {
+		data.Set(annotationDataRuleUID, rule.UID)
+	}
+}
+
 func BuildAnnotationTextAndData(rule history_model.RuleMeta, currentState *state.State) (string, *simplejson.Json) {
 	jsonData := simplejson.New()
 	var value string
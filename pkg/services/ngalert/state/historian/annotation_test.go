@@ -97,6 +97,32 @@ func TestAnnotationHistorian(t *testing.T) {
 		require.Equal(t, now.Add(-10*time.Second).UnixMilli(), query.From)
 	})
 
+	t.Run("paged annotation queries start at the continue token", func(t *testing.T) {
+		store := &interceptingAnnotationStore{}
+		anns := createTestAnnotationSutWithStore(t, store)
+		now := time.Now().UTC()
+		cursor := &historyCursor{T: now.Add(-time.Second).Truncate(time.Millisecond).UnixNano(), Skip: 2}
+
+		q := models.HistoryQuery{
+			RuleUID:  "my-rule",
+			OrgID:    1,
+			From:     now.Add(-10 * time.Second),
+			To:       now,
+			PageSize: 10,
+			Continue: cursor.token(),
+		}
+		frame, err := anns.Query(context.Background(), q)
+
+		require.NoError(t, err)
+		require.Equal(t, now.Add(-time.Second).UnixMilli(), store.lastQuery.To)
+		require.Equal(t, int64(13), store.lastQuery.Limit)
+		require.Equal(t, HistoryPageMeta{}, frame.Meta.Custom)
+
+		q.Continue = "invalid"
+		_, err = anns.Query(context.Background(), q)
+		require.ErrorIs(t, err, ErrInvalidContinueToken)
+	})
+
 	t.Run("writing state transitions as annotations succeeds", func(t *testing.T) {
 		anns := createTestAnnotationBackendSut(t)
 		rule := createTestRule()
@@ -220,6 +246,19 @@ func TestBuildAnnotations(t *testing.T) {
 		require.JSONEq(t, `{"values": null}`, j)
 	})
 
+	t.Run("data contains the rule UID", func(t *testing.T) {
+		logger := log.NewNopLogger()
+		rule := history_model.RuleMeta{ID: 1, UID: "rule-uid"}
+		states := []state.StateTransition{makeStateTransition()}
+		states[0].State.Values = nil
+
+		items := buildAnnotations(rule, states, logger)
+
+		require.Len(t, items, 1)
+		j := assertValidJSON(t, items[0].Data)
+		require.JSONEq(t, `{"values": null, "ruleUID": "rule-uid"}`, j)
+	})
+
 	t.Run("data approximately contains expected values", func(t *testing.T) {
 		logger := log.NewNopLogger()
 		rule := history_model.RuleMeta{}
@@ -14,20 +14,22 @@ func (bt BackendType) String() string {
 }
 
 const (
-	BackendTypeAnnotations BackendType = "annotations"
-	BackendTypeLoki        BackendType = "loki"
-	BackendTypeMultiple    BackendType = "multiple"
-	BackendTypeNoop        BackendType = "noop"
+	BackendTypeAnnotations   BackendType = "annotations"
+	BackendTypeLoki          BackendType = "loki"
+	BackendTypeElasticsearch BackendType = "elasticsearch"
+	BackendTypeMultiple      BackendType = "multiple"
+	BackendTypeNoop          BackendType = "noop"
 )
 
 func ParseBackendType(s string) (BackendType, error) {
 	norm := strings.ToLower(strings.TrimSpace(s))
 
 	types := map[BackendType]struct{}{
-		BackendTypeAnnotations: {},
-		BackendTypeLoki:        {},
-		BackendTypeMultiple:    {},
-		BackendTypeNoop:        {},
+		BackendTypeAnnotations:   {},
+		BackendTypeLoki:          {},
+		BackendTypeElasticsearch: {},
+		BackendTypeMultiple:      {},
+		BackendTypeNoop:          {},
 	}
 	p := BackendType(norm)
 	if _, ok := types[p]; !ok {
@@ -0,0 +1,235 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+type elasticsearchClient interface {
+	Ping(context.Context) error
+	PutIndexTemplate(context.Context) error
+	Bulk(context.Context, []ElasticsearchDocument) error
+	Search(ctx context.Context, query map[string]any) ([]ElasticsearchDocument, error)
+}
+
+// ElasticsearchDocument is a state transition stored in Elasticsearch. It holds the same data as a line of the
+// Loki backend, along with the labels of the stream the line would be written to.
+type ElasticsearchDocument struct {
+	Timestamp time.Time         `json:"@timestamp"`
+	Stream    map[string]string `json:"stream"`
+	LokiEntry
+}
+
+// ElasticsearchBackend is a state.Historian that records state history to an external Elasticsearch cluster.
+type ElasticsearchBackend struct {
+	client         elasticsearchClient
+	externalLabels map[string]string
+	clock          clock.Clock
+	metrics        *metrics.Historian
+	log            log.Logger
+	ac             AccessControl
+	ruleStore      RuleStore
+}
+
+func NewElasticsearchBackend(logger log.Logger, cfg ElasticsearchConfig, req client.Requester, metrics *metrics.Historian, tracer tracing.Tracer, ruleStore RuleStore, ac AccessControl) *ElasticsearchBackend {
+	return &ElasticsearchBackend{
+		client:         NewElasticsearchClient(cfg, req, metrics, logger, tracer),
+		externalLabels: cfg.ExternalLabels,
+		clock:          clock.New(),
+		metrics:        metrics,
+		log:            logger,
+		ac:             ac,
+		ruleStore:      ruleStore,
+	}
+}
+
+func (h *ElasticsearchBackend) TestConnection(ctx context.Context) error {
+	return h.client.Ping(ctx)
+}
+
+// EnsureIndexTemplate installs the index template of the state history, so that new indices get its mappings.
+// Indices created before the template was installed keep the mappings Elasticsearch inferred for them.
+func (h *ElasticsearchBackend) EnsureIndexTemplate(ctx context.Context) error {
+	return h.client.PutIndexTemplate(ctx)
+}
+
+// Record writes a number of state transitions for a given rule to an external Elasticsearch cluster.
+func (h *ElasticsearchBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	docs := statesToDocuments(rule, states, h.externalLabels)
+
+	errCh := make(chan error, 1)
+	if len(docs) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it, as the Loki backend does.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+		logger.Debug("Saving state history batch", "samples", len(docs))
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, BackendTypeElasticsearch.String()).Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(docs)))
+
+		if err := h.client.Bulk(ctx, docs); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, BackendTypeElasticsearch.String()).Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(docs)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch", "samples", len(docs))
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from an external Elasticsearch cluster and formats the results into a dataframe
+// of the same shape as the one of the Loki backend.
+func (h *ElasticsearchBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := folderUIDsForFilter(ctx, h.ac, h.ruleStore, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.From.After(query.To) {
+		return nil, fmt.Errorf("start time cannot be after end time")
+	}
+
+	docs, err := h.client.Search(ctx, BuildElasticsearchQuery(query, uids))
+	if err != nil {
+		return nil, err
+	}
+	return documentsToFrame(docs)
+}
+
+func statesToDocuments(rule history_model.RuleMeta, states []state.StateTransition, externalLabels map[string]string) []ElasticsearchDocument {
+	labels := streamLabels(rule, externalLabels, false)
+	docs := make([]ElasticsearchDocument, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+		docs = append(docs, ElasticsearchDocument{
+			Timestamp: state.State.LastEvaluationTime,
+			Stream:    labels,
+			LokiEntry: newLokiEntry(rule, state),
+		})
+	}
+	return docs
+}
+
+// BuildElasticsearchQuery converts models.HistoryQuery and a list of folder UIDs to the body of a search request.
+// The most recent transitions are returned first, so the limit keeps the latest ones.
+func BuildElasticsearchQuery(query models.HistoryQuery, folderUIDs []string) map[string]any {
+	filters := []any{
+		term("stream."+OrgIDLabel, fmt.Sprint(query.OrgID)),
+		term("stream."+StateHistoryLabelKey, StateHistoryLabelValue),
+		map[string]any{"range": map[string]any{"@timestamp": map[string]any{
+			"gte": query.From.UTC().Format(time.RFC3339Nano),
+			"lte": query.To.UTC().Format(time.RFC3339Nano),
+		}}},
+	}
+	if len(folderUIDs) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"stream." + FolderUIDLabel: folderUIDs}})
+	}
+	if query.RuleUID != "" {
+		filters = append(filters, term("ruleUID", query.RuleUID))
+	}
+	if query.DashboardUID != "" {
+		filters = append(filters, term("dashboardUID", query.DashboardUID))
+	}
+	if query.PanelID != 0 {
+		filters = append(filters, term("panelID", query.PanelID))
+	}
+	if query.Fingerprint != "" {
+		filters = append(filters, term("fingerprint", query.Fingerprint))
+	}
+	// Ensure that all queries we build are deterministic.
+	labelKeys := make([]string, 0, len(query.Labels))
+	for k := range query.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		filters = append(filters, term("labels."+k, query.Labels[k]))
+	}
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+	return map[string]any{
+		"size": limit,
+		"sort": []any{map[string]any{"@timestamp": map[string]any{"order": "desc"}}},
+		"query": map[string]any{
+			"bool": map[string]any{"filter": filters},
+		},
+	}
+}
+
+func term(field string, value any) map[string]any {
+	return map[string]any{"term": map[string]any{field: value}}
+}
+
+// documentsToFrame puts the documents in a single history sorted by timestamp, like the Loki backend.
+func documentsToFrame(docs []ElasticsearchDocument) (*data.Frame, error) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Timestamp.Before(docs[j].Timestamp)
+	})
+
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+	times := make([]time.Time, 0, len(docs))
+	lines := make([]json.RawMessage, 0, len(docs))
+	labels := make([]json.RawMessage, 0, len(docs))
+	for _, doc := range docs {
+		line, err := json.Marshal(doc.LokiEntry)
+		if err != nil {
+			return nil, fmt.Errorf("a document was in an invalid format: %w", err)
+		}
+		lblsJson, err := json.Marshal(doc.Stream)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+		times = append(times, doc.Timestamp)
+		lines = append(lines, line)
+		labels = append(labels, lblsJson)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
@@ -0,0 +1,277 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// elasticsearchIndexTemplateVersion is the version of the index template managed by Grafana.
+// It must be incremented when the mappings of the template change.
+const elasticsearchIndexTemplateVersion = 1
+
+// Index names must be lowercase and cannot contain the characters Elasticsearch reserves for index expressions.
+var elasticsearchIndexPrefixRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+type ElasticsearchConfig struct {
+	URL               *url.URL
+	BasicAuthUser     string
+	BasicAuthPassword string
+	APIKey            string
+	IndexPrefix       string
+	ExternalLabels    map[string]string
+}
+
+func NewElasticsearchConfig(cfg setting.UnifiedAlertingStateHistorySettings) (ElasticsearchConfig, error) {
+	if cfg.ElasticsearchURL == "" {
+		return ElasticsearchConfig{}, fmt.Errorf("the URL of Elasticsearch must be provided")
+	}
+	u, err := url.Parse(cfg.ElasticsearchURL)
+	if err != nil {
+		return ElasticsearchConfig{}, fmt.Errorf("failed to parse elasticsearch URL: %w", err)
+	}
+	if !elasticsearchIndexPrefixRegex.MatchString(cfg.ElasticsearchIndexPrefix) {
+		return ElasticsearchConfig{}, fmt.Errorf("invalid elasticsearch index prefix %q: it must be lowercase and only contain letters, digits, '.', '_' and '-'", cfg.ElasticsearchIndexPrefix)
+	}
+	return ElasticsearchConfig{
+		URL:               u,
+		BasicAuthUser:     cfg.ElasticsearchBasicAuthUsername,
+		BasicAuthPassword: cfg.ElasticsearchBasicAuthPassword,
+		APIKey:            cfg.ElasticsearchAPIKey,
+		IndexPrefix:       cfg.ElasticsearchIndexPrefix,
+		ExternalLabels:    cfg.ExternalLabels,
+	}, nil
+}
+
+type HttpElasticsearchClient struct {
+	client  client.Requester
+	cfg     ElasticsearchConfig
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewElasticsearchClient(cfg ElasticsearchConfig, req client.Requester, metrics *metrics.Historian, logger log.Logger, tracer tracing.Tracer) *HttpElasticsearchClient {
+	tc := client.NewTimedClient(req, metrics.WriteDuration)
+	trc := client.NewTracedClient(tc, tracer, "ngalert.historian.client")
+	return &HttpElasticsearchClient{
+		client:  trc,
+		cfg:     cfg,
+		metrics: metrics,
+		log:     logger.New("protocol", "http"),
+	}
+}
+
+// Ping checks that the cluster is reachable and the credentials are accepted.
+func (c *HttpElasticsearchClient) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodGet, c.cfg.URL.JoinPath("/"), nil, "")
+	if err != nil {
+		return fmt.Errorf("ping request to elasticsearch failed: %w", err)
+	}
+	c.log.FromContext(ctx).Debug("Ping request to Elasticsearch succeeded")
+	return nil
+}
+
+// PutIndexTemplate creates or replaces the index template that applies the mappings of state history documents
+// to the indices of the state history.
+func (c *HttpElasticsearchClient) PutIndexTemplate(ctx context.Context) error {
+	body, err := json.Marshal(elasticsearchIndexTemplate(c.cfg.IndexPrefix))
+	if err != nil {
+		return err
+	}
+	uri := c.cfg.URL.JoinPath("/_index_template", c.cfg.IndexPrefix)
+	if _, err := c.do(ctx, http.MethodPut, uri, body, "application/json"); err != nil {
+		return fmt.Errorf("failed to put index template: %w", err)
+	}
+	return nil
+}
+
+// Bulk indexes the documents in the index of the day of their timestamp.
+func (c *HttpElasticsearchClient) Bulk(ctx context.Context, docs []ElasticsearchDocument) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range docs {
+		action := map[string]any{"create": map[string]string{"_index": elasticsearchIndexName(c.cfg.IndexPrefix, doc)}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	c.metrics.BytesWritten.Add(float64(buf.Len()))
+	data, err := c.do(ctx, http.MethodPost, c.cfg.URL.JoinPath("/_bulk"), buf.Bytes(), "application/x-ndjson")
+	if err != nil {
+		return err
+	}
+
+	// The bulk API responds with 200 even if some documents were rejected.
+	res := bulkResponse{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return fmt.Errorf("error parsing bulk response: %w", err)
+	}
+	if !res.Errors {
+		return nil
+	}
+	failed := 0
+	var reason string
+	for _, item := range res.Items {
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			if failed == 0 {
+				reason = fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason)
+			}
+			failed++
+		}
+	}
+	return fmt.Errorf("elasticsearch rejected %d of %d documents, first error: %s", failed, len(docs), reason)
+}
+
+// Search returns the documents of the state history that match the query, most recent first.
+func (c *HttpElasticsearchClient) Search(ctx context.Context, query map[string]any) ([]ElasticsearchDocument, error) {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	uri := c.cfg.URL.JoinPath("/", c.cfg.IndexPrefix+"-*", "_search")
+	values := url.Values{}
+	// The indices of the state history are created on the first write of the day.
+	values.Set("ignore_unavailable", "true")
+	values.Set("allow_no_indices", "true")
+	uri.RawQuery = values.Encode()
+
+	c.log.FromContext(ctx).Debug("Sending search request", "query", string(body))
+	data, err := c.do(ctx, http.MethodPost, uri, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	res := searchResponse{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("error parsing search response: %w", err)
+	}
+	docs := make([]ElasticsearchDocument, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		docs = append(docs, hit.Source)
+	}
+	return docs, nil
+}
+
+func (c *HttpElasticsearchClient) do(ctx context.Context, method string, uri *url.URL, body []byte, contentType string) ([]byte, error) {
+	log := c.log.FromContext(ctx)
+	req, err := http.NewRequestWithContext(ctx, method, uri.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.setAuthHeaders(req)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading request response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		if len(data) > 0 {
+			log.Error("Error response from Elasticsearch", "response", string(data), "status", res.StatusCode)
+		} else {
+			log.Error("Error response from Elasticsearch with an empty body", "status", res.StatusCode)
+		}
+		return nil, fmt.Errorf("received a non-200 response from elasticsearch, status: %d", res.StatusCode)
+	}
+	return data, nil
+}
+
+func (c *HttpElasticsearchClient) setAuthHeaders(req *http.Request) {
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.cfg.APIKey)
+		return
+	}
+	if c.cfg.BasicAuthUser != "" || c.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(c.cfg.BasicAuthUser, c.cfg.BasicAuthPassword)
+	}
+}
+
+// elasticsearchIndexName returns the name of the daily index the document is written to.
+// Daily indices let operators expire the state history with index lifecycle policies.
+func elasticsearchIndexName(prefix string, doc ElasticsearchDocument) string {
+	return prefix + "-" + doc.Timestamp.UTC().Format("2006.01.02")
+}
+
+// elasticsearchIndexTemplate returns the index template of the indices of the state history.
+// Labels are arbitrary, so they are mapped as flattened fields to not create a field for every label,
+// and values are stored without being indexed.
+func elasticsearchIndexTemplate(prefix string) map[string]any {
+	keyword := map[string]any{"type": "keyword", "ignore_above": 1024}
+	return map[string]any{
+		"index_patterns": []string{prefix + "-*"},
+		"_meta": map[string]any{
+			"managed_by": "grafana",
+			"version":    elasticsearchIndexTemplateVersion,
+		},
+		"template": map[string]any{
+			"mappings": map[string]any{
+				"dynamic": false,
+				"properties": map[string]any{
+					"@timestamp":    map[string]any{"type": "date_nanos"},
+					"stream":        map[string]any{"type": "flattened"},
+					"labels":        map[string]any{"type": "flattened"},
+					"schemaVersion": map[string]any{"type": "integer"},
+					"previous":      keyword,
+					"current":       keyword,
+					"error":         map[string]any{"type": "text"},
+					"values":        map[string]any{"type": "object", "enabled": false},
+					"condition":     keyword,
+					"dashboardUID":  keyword,
+					"panelID":       map[string]any{"type": "long"},
+					"fingerprint":   keyword,
+					"ruleTitle":     keyword,
+					"ruleID":        map[string]any{"type": "long"},
+					"ruleUID":       keyword,
+				},
+			},
+		},
+	}
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error,omitempty"`
+	} `json:"items"`
+}
+
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source ElasticsearchDocument `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
@@ -0,0 +1,95 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestElasticsearchConfig(t *testing.T) {
+	t.Run("requires a URL", func(t *testing.T) {
+		_, err := NewElasticsearchConfig(setting.UnifiedAlertingStateHistorySettings{ElasticsearchIndexPrefix: "grafana-state-history"})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects invalid index prefixes", func(t *testing.T) {
+		for _, prefix := range []string{"", "Grafana", "grafana-*", "-grafana", "a,b"} {
+			_, err := NewElasticsearchConfig(setting.UnifiedAlertingStateHistorySettings{
+				ElasticsearchURL:         "http://localhost:9200",
+				ElasticsearchIndexPrefix: prefix,
+			})
+			require.Error(t, err, prefix)
+		}
+	})
+
+	t.Run("captures settings", func(t *testing.T) {
+		res, err := NewElasticsearchConfig(setting.UnifiedAlertingStateHistorySettings{
+			ElasticsearchURL:               "http://localhost:9200",
+			ElasticsearchBasicAuthUsername: "user",
+			ElasticsearchBasicAuthPassword: "pass",
+			ElasticsearchIndexPrefix:       "alerts",
+			ExternalLabels:                 map[string]string{"a": "b"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:9200", res.URL.String())
+		require.Equal(t, "user", res.BasicAuthUser)
+		require.Equal(t, "pass", res.BasicAuthPassword)
+		require.Equal(t, "alerts", res.IndexPrefix)
+		require.Equal(t, map[string]string{"a": "b"}, res.ExternalLabels)
+	})
+}
+
+func TestElasticsearchHTTPClient(t *testing.T) {
+	newClient := func(cfg ElasticsearchConfig, req *fakeRequester) *HttpElasticsearchClient {
+		u, _ := url.Parse("http://some.url")
+		cfg.URL = u
+		cfg.IndexPrefix = "grafana-state-history"
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		return NewElasticsearchClient(cfg, req, met, log.NewNopLogger(), tracing.InitializeTracerForTest())
+	}
+
+	t.Run("puts the index template of the prefix", func(t *testing.T) {
+		req := NewFakeRequester()
+		err := newClient(ElasticsearchConfig{}, req).PutIndexTemplate(context.Background())
+		require.NoError(t, err)
+
+		require.Equal(t, http.MethodPut, req.lastRequest.Method)
+		require.Equal(t, "/_index_template/grafana-state-history", req.lastRequest.URL.Path)
+		var template map[string]any
+		require.NoError(t, json.Unmarshal(readBody(t, req.lastRequest), &template))
+		require.Equal(t, []any{"grafana-state-history-*"}, template["index_patterns"])
+	})
+
+	t.Run("prefers the API key to basic auth", func(t *testing.T) {
+		req := NewFakeRequester()
+		err := newClient(ElasticsearchConfig{APIKey: "key", BasicAuthUser: "user"}, req).Ping(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "ApiKey key", req.lastRequest.Header.Get("Authorization"))
+	})
+
+	t.Run("uses basic auth", func(t *testing.T) {
+		req := NewFakeRequester()
+		err := newClient(ElasticsearchConfig{BasicAuthUser: "user", BasicAuthPassword: "pass"}, req).Ping(context.Background())
+		require.NoError(t, err)
+		user, pass, ok := req.lastRequest.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+	})
+
+	t.Run("fails on non-200 responses", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(badResponse()) //nolint:bodyclose
+		err := newClient(ElasticsearchConfig{}, req).Ping(context.Background())
+		require.Error(t, err)
+	})
+}
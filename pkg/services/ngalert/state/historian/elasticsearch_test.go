@@ -0,0 +1,188 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestElasticsearchRecordStates(t *testing.T) {
+	t.Run("writes state transitions to the daily index", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(okResponse(`{"errors":false,"items":[]}`))
+		es := createTestElasticsearchBackend(t, req, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+		rule := createTestRule()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b"},
+			LastEvaluationTime: time.Date(2024, 11, 26, 12, 0, 0, 0, time.UTC),
+		})
+
+		err := <-es.Record(context.Background(), rule, states)
+
+		require.NoError(t, err)
+		require.Equal(t, "/_bulk", req.lastRequest.URL.Path)
+		require.Equal(t, "application/x-ndjson", req.lastRequest.Header.Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(string(readBody(t, req.lastRequest))), "\n")
+		require.Len(t, lines, 2)
+		require.JSONEq(t, `{"create":{"_index":"grafana-state-history-2024.11.26"}}`, lines[0])
+		var doc ElasticsearchDocument
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &doc))
+		require.Equal(t, "rule-uid", doc.RuleUID)
+		require.Equal(t, "Alerting", doc.Current)
+		require.Equal(t, map[string]string{"a": "b"}, doc.InstanceLabels)
+		require.Equal(t, "1", doc.Stream[OrgIDLabel])
+		require.Equal(t, "my-folder", doc.Stream[FolderUIDLabel])
+		require.Equal(t, "externalLabelValue", doc.Stream["externalLabelKey"])
+	})
+
+	t.Run("elides request if nothing to send", func(t *testing.T) {
+		req := NewFakeRequester()
+		es := createTestElasticsearchBackend(t, req, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+		err := <-es.Record(context.Background(), createTestRule(), []state.StateTransition{})
+
+		require.NoError(t, err)
+		require.Nil(t, req.lastRequest)
+	})
+
+	t.Run("fails and counts rejected documents", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
+		req := NewFakeRequester().WithResponse(okResponse(`{"errors":true,"items":[{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+		es := createTestElasticsearchBackend(t, req, met)
+		states := singleFromNormal(&state.State{State: eval.Alerting})
+
+		err := <-es.Record(context.Background(), createTestRule(), states)
+
+		require.ErrorContains(t, err, "mapper_parsing_exception")
+		exp := bytes.NewBufferString(`
+# HELP grafana_alerting_state_history_writes_failed_total The total number of failed writes of state history batches.
+# TYPE grafana_alerting_state_history_writes_failed_total counter
+grafana_alerting_state_history_writes_failed_total{backend="elasticsearch",org="1"} 1
+`)
+		require.NoError(t, testutil.GatherAndCompare(reg, exp, "grafana_alerting_state_history_writes_failed_total"))
+	})
+}
+
+func TestElasticsearchQuery(t *testing.T) {
+	t.Run("returns the documents in a single history sorted by time", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(okResponse(`{"hits":{"hits":[
+			{"_source":{"@timestamp":"2024-11-26T12:01:00Z","stream":{"orgID":"1","folderUID":"f"},"ruleUID":"r","current":"Normal","labels":{"a":"b"},"fingerprint":"fp"}},
+			{"_source":{"@timestamp":"2024-11-26T12:00:00Z","stream":{"orgID":"1","folderUID":"f"},"ruleUID":"r","current":"Alerting","labels":{"a":"b"},"fingerprint":"fp"}}
+		]}}`))
+		es := createTestElasticsearchBackend(t, req, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+		frame, err := es.Query(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "r"})
+		require.NoError(t, err)
+		require.Equal(t, "/grafana-state-history-*/_search", req.lastRequest.URL.Path)
+		require.Equal(t, "true", req.lastRequest.URL.Query().Get("ignore_unavailable"))
+
+		require.Equal(t, 2, frame.Rows())
+		times, _ := frame.FieldByName(dfTime)
+		require.Equal(t, time.Date(2024, 11, 26, 12, 0, 0, 0, time.UTC), times.At(0).(time.Time).UTC())
+
+		history, err := InstanceHistory(frame, "fp")
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, "Alerting", history[0].Current)
+		require.Equal(t, "Normal", history[1].Current)
+	})
+}
+
+func TestBuildElasticsearchQuery(t *testing.T) {
+	from := time.Date(2024, 11, 26, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	t.Run("filters by org and time range", func(t *testing.T) {
+		q := BuildElasticsearchQuery(models.HistoryQuery{OrgID: 1, From: from, To: to}, nil)
+		require.JSONEq(t, `{
+			"size": 1000,
+			"sort": [{"@timestamp": {"order": "desc"}}],
+			"query": {"bool": {"filter": [
+				{"term": {"stream.orgID": "1"}},
+				{"term": {"stream.from": "state-history"}},
+				{"range": {"@timestamp": {"gte": "2024-11-26T00:00:00Z", "lte": "2024-11-26T01:00:00Z"}}}
+			]}}
+		}`, string(toJson(q)))
+	})
+
+	t.Run("filters by folders, rule, dashboard, panel, fingerprint and labels", func(t *testing.T) {
+		q := BuildElasticsearchQuery(models.HistoryQuery{
+			OrgID:        1,
+			From:         from,
+			To:           to,
+			RuleUID:      "rule",
+			DashboardUID: "dash",
+			PanelID:      5,
+			Fingerprint:  "fp",
+			Labels:       map[string]string{"b": "2", "a.b": "1"},
+			Limit:        10000,
+		}, []string{"f1", "f2"})
+		require.JSONEq(t, `{
+			"size": 5000,
+			"sort": [{"@timestamp": {"order": "desc"}}],
+			"query": {"bool": {"filter": [
+				{"term": {"stream.orgID": "1"}},
+				{"term": {"stream.from": "state-history"}},
+				{"range": {"@timestamp": {"gte": "2024-11-26T00:00:00Z", "lte": "2024-11-26T01:00:00Z"}}},
+				{"terms": {"stream.folderUID": ["f1", "f2"]}},
+				{"term": {"ruleUID": "rule"}},
+				{"term": {"dashboardUID": "dash"}},
+				{"term": {"panelID": 5}},
+				{"term": {"fingerprint": "fp"}},
+				{"term": {"labels.a.b": "1"}},
+				{"term": {"labels.b": "2"}}
+			]}}
+		}`, string(toJson(q)))
+	})
+}
+
+func createTestElasticsearchBackend(t *testing.T, req client.Requester, met *metrics.Historian) *ElasticsearchBackend {
+	u, _ := url.Parse("http://some.url")
+	cfg := ElasticsearchConfig{
+		URL:            u,
+		IndexPrefix:    "grafana-state-history",
+		ExternalLabels: map[string]string{"externalLabelKey": "externalLabelValue"},
+	}
+	logger := log.New("ngalert.state.historian", "backend", "elasticsearch")
+	rules := fakes.NewRuleStore(t)
+	ac := &acfakes.FakeRuleService{
+		CanReadAllRulesFunc: func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		},
+	}
+	return NewElasticsearchBackend(logger, cfg, req, met, tracing.InitializeTracerForTest(), rules, ac)
+}
+
+func okResponse(body string) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Header:        make(http.Header, 0),
+	}
+}
@@ -17,9 +17,22 @@ import (
 type JsonEncoder struct{}
 
 func (e JsonEncoder) encode(s []Stream) ([]byte, error) {
+	streams := make([]any, 0, len(s))
+	for _, str := range s {
+		if len(str.Metadata) == 0 {
+			streams = append(streams, str)
+			continue
+		}
+		// the structured metadata is the third value of the entries
+		values := make([][3]any, 0, len(str.Values))
+		for _, sample := range str.Values {
+			values = append(values, [3]any{fmt.Sprintf("%d", sample.T.UnixNano()), sample.V, str.Metadata})
+		}
+		streams = append(streams, map[string]any{"stream": str.Stream, "values": values})
+	}
 	body := struct {
-		Streams []Stream `json:"streams"`
-	}{Streams: s}
+		Streams []any `json:"streams"`
+	}{Streams: streams}
 	enc, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize Loki payload: %w", err)
@@ -42,10 +55,12 @@ func (e SnappyProtoEncoder) encode(s []Stream) ([]byte, error) {
 
 	for _, str := range s {
 		entries := make([]logproto.Entry, 0, len(str.Values))
+		metadata := structuredMetadata(str.Metadata)
 		for _, sample := range str.Values {
 			entries = append(entries, logproto.Entry{
-				Timestamp: sample.T,
-				Line:      sample.V,
+				Timestamp:          sample.T,
+				Line:               sample.V,
+				StructuredMetadata: metadata,
 			})
 		}
 		body.Streams = append(body.Streams, logproto.Stream{
@@ -70,6 +85,19 @@ func (e SnappyProtoEncoder) headers() map[string]string {
 	}
 }
 
+// structuredMetadata returns the metadata of a stream sorted by name, nil when it has none
+func structuredMetadata(metadata map[string]string) []logproto.LabelAdapter {
+	if len(metadata) == 0 {
+		return nil
+	}
+	res := make([]logproto.LabelAdapter, 0, len(metadata))
+	for name, value := range metadata {
+		res = append(res, logproto.LabelAdapter{Name: name, Value: value})
+	}
+	slices.SortFunc(res, func(a, b logproto.LabelAdapter) int { return strings.Compare(a.Name, b.Name) })
+	return res
+}
+
 // Copied from promtail.
 // Modified slightly to work in terms of plain map[string]string to avoid some unnecessary copies and type casts.
 // TODO: pkg/components/loki/lokihttp/batch.go contains an older (loki 2.7.4 released) version of this.
@@ -0,0 +1,399 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+const (
+	// importBatchSize is the maximum number of transitions written to the backend at once.
+	importBatchSize = 1000
+
+	// importQueryLength is the time range of a query to the Loki instance imported from, unless configured otherwise.
+	importQueryLength = 24 * time.Hour
+)
+
+var (
+	// ErrImportNotSupported is returned when the configured backend can not store imported state history.
+	ErrImportNotSupported = errors.New("the configured state history backend does not support importing state history")
+	ErrInvalidImport      = errors.New("invalid state history import")
+)
+
+// ImportedTransition is a state transition read from another state history backend.
+type ImportedTransition struct {
+	Time  time.Time
+	Entry LokiEntry
+}
+
+// Importer is implemented by the state history backends that imported state transitions can be written to.
+type Importer interface {
+	// Import writes the transitions of a rule, they are sorted by time.
+	Import(ctx context.Context, rule history_model.RuleMeta, transitions []ImportedTransition) error
+}
+
+// ImportSource reads the state history of an organization to import.
+type ImportSource interface {
+	Streams(ctx context.Context, orgID int64, from, to time.Time) ([]Stream, error)
+}
+
+// ImportOptions describes which state history is imported and how it is mapped to this instance.
+type ImportOptions struct {
+	// OrgID is the organization the state history is imported into.
+	OrgID int64
+	// SourceOrgID is the organization the state history was recorded for in the source, it defaults to OrgID.
+	SourceOrgID int64
+	From        time.Time
+	To          time.Time
+	// RuleUIDs maps the rule UIDs of the source to the UIDs of the rules of this instance.
+	// Rules that are not in the map are expected to have the same UID.
+	RuleUIDs map[string]string
+	// DryRun reads and maps the state history without writing it.
+	DryRun bool
+}
+
+// ImportResult describes the outcome of an import.
+type ImportResult struct {
+	DryRun bool
+	// Imported is the number of state transitions that were written, or would be written on a dry run.
+	Imported int
+	// Skipped is the number of state transitions that could not be read or belong to missing rules.
+	Skipped int
+	// MissingRules are the UIDs of the rules that do not exist in the organization, after mapping.
+	MissingRules []string
+}
+
+// StateHistoryImporter imports state history recorded by another backend into the configured backend,
+// so state history is not lost when switching backends.
+type StateHistoryImporter struct {
+	target  Importer
+	rules   RuleStore
+	metrics *metrics.Historian
+	log     log.Logger
+	tracer  tracing.Tracer
+}
+
+func NewStateHistoryImporter(target Importer, rules RuleStore, metrics *metrics.Historian, logger log.Logger, tracer tracing.Tracer) *StateHistoryImporter {
+	return &StateHistoryImporter{
+		target:  target,
+		rules:   rules,
+		metrics: metrics,
+		log:     logger,
+		tracer:  tracer,
+	}
+}
+
+// LokiSource returns a source that reads the state history from the Loki instance of the configuration.
+func (i *StateHistoryImporter) LokiSource(cfg LokiConfig) ImportSource {
+	if cfg.WritePathURL == nil {
+		cfg.WritePathURL = cfg.ReadPathURL
+	}
+	if cfg.Encoder == nil {
+		cfg.Encoder = SnappyProtoEncoder{}
+	}
+	if cfg.MaxQueryLength <= 0 {
+		cfg.MaxQueryLength = importQueryLength
+	}
+	return NewLokiImportSource(cfg, NewRequester(), i.metrics, i.log, i.tracer)
+}
+
+// Import reads the state history from the source and writes it to the backend. Transitions of rules that do not exist
+// in the organization are skipped. Importing the same state history twice is not detected and creates duplicates.
+func (i *StateHistoryImporter) Import(ctx context.Context, source ImportSource, opts ImportOptions) (ImportResult, error) {
+	if i.target == nil {
+		return ImportResult{}, ErrImportNotSupported
+	}
+	if opts.SourceOrgID == 0 {
+		opts.SourceOrgID = opts.OrgID
+	}
+	if !opts.To.IsZero() && !opts.To.After(opts.From) {
+		return ImportResult{}, fmt.Errorf("%w: the end of the time range must be after the start", ErrInvalidImport)
+	}
+	logger := i.log.FromContext(ctx)
+
+	streams, err := source.Streams(ctx, opts.SourceOrgID, opts.From, opts.To)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to read state history: %w", err)
+	}
+
+	result := ImportResult{DryRun: opts.DryRun, MissingRules: []string{}}
+	byRule := map[string][]ImportedTransition{}
+	for _, s := range streams {
+		if org, ok := s.Stream[OrgIDLabel]; ok && org != fmt.Sprint(opts.SourceOrgID) {
+			continue
+		}
+		for _, sample := range s.Values {
+			var entry LokiEntry
+			if err := json.Unmarshal([]byte(sample.V), &entry); err != nil || entry.RuleUID == "" {
+				logger.Debug("Skipping state history entry that can not be read", "time", sample.T, "error", err)
+				result.Skipped++
+				continue
+			}
+			uid := entry.RuleUID
+			if mapped, ok := opts.RuleUIDs[uid]; ok {
+				uid = mapped
+			}
+			byRule[uid] = append(byRule[uid], ImportedTransition{Time: sample.T, Entry: entry})
+		}
+	}
+
+	uids := make([]string, 0, len(byRule))
+	for uid := range byRule {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		transitions := byRule[uid]
+		rule, err := i.rules.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{UID: uid, OrgID: opts.OrgID})
+		if err != nil && !errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return result, fmt.Errorf("failed to fetch alert rule %s: %w", uid, err)
+		}
+		if rule == nil {
+			result.MissingRules = append(result.MissingRules, uid)
+			result.Skipped += len(transitions)
+			continue
+		}
+
+		meta := history_model.NewRuleMeta(rule, logger)
+		for idx := range transitions {
+			e := &transitions[idx].Entry
+			e.RuleID = meta.ID
+			e.RuleUID = meta.UID
+			e.RuleTitle = meta.Title
+			e.DashboardUID = meta.DashboardUID
+			e.PanelID = meta.PanelID
+		}
+		sort.SliceStable(transitions, func(a, b int) bool { return transitions[a].Time.Before(transitions[b].Time) })
+
+		if !opts.DryRun {
+			for start := 0; start < len(transitions); start += importBatchSize {
+				end := min(start+importBatchSize, len(transitions))
+				if err := i.target.Import(ctx, meta, transitions[start:end]); err != nil {
+					return result, fmt.Errorf("failed to import state history of rule %s: %w", uid, err)
+				}
+				result.Imported += end - start
+			}
+		} else {
+			result.Imported += len(transitions)
+		}
+		logger.Debug("Imported state history of rule", "rule_uid", uid, "transitions", len(transitions), "dry_run", opts.DryRun)
+	}
+
+	logger.Info("Imported state history", "org_id", opts.OrgID, "imported", result.Imported, "skipped", result.Skipped, "missing_rules", len(result.MissingRules), "dry_run", opts.DryRun)
+	return result, nil
+}
+
+// StreamsImportSource is state history exported from Loki, in the format of the result of the query_range API.
+type StreamsImportSource []Stream
+
+func (s StreamsImportSource) Streams(_ context.Context, _ int64, from, to time.Time) ([]Stream, error) {
+	out := make([]Stream, 0, len(s))
+	for _, stream := range s {
+		values := make([]Sample, 0, len(stream.Values))
+		for _, sample := range stream.Values {
+			if sample.T.Before(from) || (!to.IsZero() && !sample.T.Before(to)) {
+				continue
+			}
+			values = append(values, sample)
+		}
+		out = append(out, Stream{Stream: stream.Stream, Values: values})
+	}
+	return out, nil
+}
+
+// LokiImportSource reads the state history from an external Loki instance.
+type LokiImportSource struct {
+	client         remoteLokiClient
+	maxQueryLength time.Duration
+	clock          func() time.Time
+}
+
+func NewLokiImportSource(cfg LokiConfig, req client.Requester, metrics *metrics.Historian, logger log.Logger, tracer tracing.Tracer) *LokiImportSource {
+	return &LokiImportSource{
+		client:         NewLokiClient(cfg, req, metrics, logger, tracer),
+		maxQueryLength: cfg.MaxQueryLength,
+		clock:          time.Now,
+	}
+}
+
+// Streams queries the state history of the organization in windows of the maximum query length, every window is read
+// backwards page by page because Loki returns the newest entries first.
+func (s *LokiImportSource) Streams(ctx context.Context, orgID int64, from, to time.Time) ([]Stream, error) {
+	if to.IsZero() {
+		to = s.clock()
+	}
+	if from.IsZero() {
+		return nil, fmt.Errorf("%w: the start of the time range is required to import from Loki", ErrInvalidImport)
+	}
+	window := s.maxQueryLength
+	if window <= 0 {
+		window = to.Sub(from)
+	}
+	// the org of the source may be a label of the streams or structured metadata of the entries
+	logQL := fmt.Sprintf(`{%s=%q}`, StateHistoryLabelKey, StateHistoryLabelValue) + orgFilter(orgID)
+
+	streams := map[string]*Stream{}
+	keys := []string{}
+	seen := map[string]struct{}{}
+	for windowEnd := to; windowEnd.After(from); windowEnd = windowEnd.Add(-window) {
+		windowStart := windowEnd.Add(-window)
+		if windowStart.Before(from) {
+			windowStart = from
+		}
+		end := windowEnd.UnixNano()
+		for {
+			res, err := s.client.RangeQuery(ctx, logQL, windowStart.UnixNano(), end, maximumPageSize)
+			if err != nil {
+				return nil, err
+			}
+			count := 0
+			oldest := end
+			for _, stream := range res.Data.Result {
+				key := data.Labels(stream.Stream).String()
+				target, ok := streams[key]
+				if !ok {
+					target = &Stream{Stream: stream.Stream}
+					streams[key] = target
+					keys = append(keys, key)
+				}
+				for _, sample := range stream.Values {
+					count++
+					if ts := sample.T.UnixNano(); ts < oldest {
+						oldest = ts
+					}
+					// pages overlap at their boundary
+					id := fmt.Sprintf("%s\x00%d\x00%s", key, sample.T.UnixNano(), sample.V)
+					if _, ok := seen[id]; ok {
+						continue
+					}
+					seen[id] = struct{}{}
+					target.Values = append(target.Values, sample)
+				}
+			}
+			if count < maximumPageSize || oldest >= end {
+				break
+			}
+			end = oldest
+		}
+	}
+
+	out := make([]Stream, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, *streams[key])
+	}
+	return out, nil
+}
+
+// Import writes imported state transitions of a rule to Loki, with the same stream labels as the recorded transitions.
+func (h *RemoteLokiBackend) Import(ctx context.Context, rule history_model.RuleMeta, transitions []ImportedTransition) error {
+	stream := Stream{
+		Stream: streamLabels(rule, h.externalLabels, h.structuredMetadata),
+		Values: make([]Sample, 0, len(transitions)),
+	}
+	if h.structuredMetadata {
+		stream.Metadata = entryMetadata(rule)
+	}
+	for _, t := range transitions {
+		line, err := json.Marshal(t.Entry)
+		if err != nil {
+			return fmt.Errorf("failed to construct history record: %w", err)
+		}
+		stream.Values = append(stream.Values, Sample{T: t.Time, V: string(line)})
+	}
+	return h.recordStreams(ctx, stream, h.log.FromContext(ctx))
+}
+
+// Import writes imported state transitions of a rule as annotations, like the annotations created for recorded transitions.
+func (h *AnnotationBackend) Import(ctx context.Context, rule history_model.RuleMeta, transitions []ImportedTransition) error {
+	logger := h.log.FromContext(ctx)
+	items := make([]annotations.Item, 0, len(transitions))
+	for _, t := range transitions {
+		text, jsonData := annotationTextAndDataFromEntry(rule, t.Entry)
+		setAnnotationRuleUID(jsonData, rule)
+		items = append(items, annotations.Item{
+			AlertID:   rule.ID,
+			OrgID:     rule.OrgID,
+			PrevState: t.Entry.Previous,
+			NewState:  t.Entry.Current,
+			Text:      text,
+			Data:      jsonData,
+			Epoch:     t.Time.UnixMilli(),
+		})
+	}
+	return h.store.Save(ctx, parsePanelKey(rule, logger), items, rule.OrgID, logger)
+}
+
+// annotationTextAndDataFromEntry is the equivalent of BuildAnnotationTextAndData for a state history entry.
+func annotationTextAndDataFromEntry(rule history_model.RuleMeta, entry LokiEntry) (string, *simplejson.Json) {
+	jsonData := simplejson.New()
+	var value string
+
+	switch {
+	case strings.HasPrefix(entry.Current, eval.Error.String()):
+		jsonData.Set("error", entry.Error)
+		value = "Error"
+	case strings.HasPrefix(entry.Current, eval.NoData.String()):
+		jsonData.Set("noData", true)
+		value = "No data"
+	default:
+		values := map[string]any{}
+		if entry.Values != nil {
+			values, _ = entry.Values.Map()
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		formatted := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if f, ok := values[k].(float64); ok {
+				formatted = append(formatted, fmt.Sprintf("%s=%f", k, f))
+			} else {
+				formatted = append(formatted, fmt.Sprintf("%s=%v", k, values[k]))
+			}
+		}
+		jsonData.Set("values", entry.Values)
+		value = strings.Join(formatted, ", ")
+	}
+
+	labels := removePrivateLabels(entry.InstanceLabels)
+	return fmt.Sprintf("%s {%s} - %s", rule.Title, labels.String(), value), jsonData
+}
+
+// Import writes the imported transitions to all backends that support it, the first error is returned.
+func (h *MultipleBackend) Import(ctx context.Context, rule history_model.RuleMeta, transitions []ImportedTransition) error {
+	supported := false
+	for _, b := range append([]Backend{h.primary}, h.secondaries...) {
+		importer, ok := b.(Importer)
+		if !ok {
+			continue
+		}
+		supported = true
+		if err := importer.Import(ctx, rule, transitions); err != nil {
+			return err
+		}
+	}
+	if !supported {
+		return ErrImportNotSupported
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestStateHistoryImporter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	entry := func(ruleUID, current string) string {
+		b, err := json.Marshal(LokiEntry{SchemaVersion: 1, RuleUID: ruleUID, RuleTitle: "old title", Previous: "Normal", Current: current})
+		require.NoError(t, err)
+		return string(b)
+	}
+	source := StreamsImportSource{
+		{
+			Stream: map[string]string{OrgIDLabel: "5", StateHistoryLabelKey: StateHistoryLabelValue},
+			Values: []Sample{
+				{T: now.Add(2 * time.Minute), V: entry("old-uid", "Normal")},
+				{T: now, V: entry("old-uid", "Alerting")},
+				{T: now.Add(time.Minute), V: entry("same-uid", "Pending")},
+				{T: now.Add(time.Minute), V: entry("deleted-uid", "Alerting")},
+				{T: now.Add(time.Minute), V: "not json"},
+			},
+		},
+		{
+			Stream: map[string]string{OrgIDLabel: "6", StateHistoryLabelKey: StateHistoryLabelValue},
+			Values: []Sample{{T: now, V: entry("same-uid", "Alerting")}},
+		},
+	}
+
+	newImporter := func(t *testing.T) (*StateHistoryImporter, *fakeImporter) {
+		rules := fakes.NewRuleStore(t)
+		rules.Rules[1] = []*models.AlertRule{
+			models.RuleGen.With(models.RuleMuts.WithOrgID(1), withUID("new-uid"), models.RuleMuts.WithTitle("new title")).GenerateRef(),
+			models.RuleGen.With(models.RuleMuts.WithOrgID(1), withUID("same-uid")).GenerateRef(),
+		}
+		target := &fakeImporter{}
+		return NewStateHistoryImporter(target, rules, nil, log.NewNopLogger(), nil), target
+	}
+
+	t.Run("imports the transitions of existing rules", func(t *testing.T) {
+		importer, target := newImporter(t)
+
+		res, err := importer.Import(context.Background(), source, ImportOptions{
+			OrgID:       1,
+			SourceOrgID: 5,
+			RuleUIDs:    map[string]string{"old-uid": "new-uid"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, ImportResult{Imported: 3, Skipped: 2, MissingRules: []string{"deleted-uid"}}, res)
+
+		require.Len(t, target.imported, 2)
+		imported := target.imported["new-uid"]
+		require.Len(t, imported, 2)
+		require.Equal(t, now, imported[0].Time)
+		require.Equal(t, "Alerting", imported[0].Entry.Current)
+		require.Equal(t, "new-uid", imported[0].Entry.RuleUID)
+		require.Equal(t, "new title", imported[0].Entry.RuleTitle)
+		require.Equal(t, now.Add(2*time.Minute), imported[1].Time)
+		require.Len(t, target.imported["same-uid"], 1)
+	})
+
+	t.Run("does not write on a dry run", func(t *testing.T) {
+		importer, target := newImporter(t)
+
+		res, err := importer.Import(context.Background(), source, ImportOptions{OrgID: 1, SourceOrgID: 5, DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, ImportResult{DryRun: true, Imported: 1, Skipped: 4, MissingRules: []string{"deleted-uid", "old-uid"}}, res)
+		require.Empty(t, target.imported)
+	})
+
+	t.Run("filters by time range", func(t *testing.T) {
+		importer, target := newImporter(t)
+
+		res, err := importer.Import(context.Background(), source, ImportOptions{
+			OrgID:       1,
+			SourceOrgID: 5,
+			RuleUIDs:    map[string]string{"old-uid": "new-uid"},
+			From:        now.Add(time.Minute),
+			To:          now.Add(2 * time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Imported)
+		require.Len(t, target.imported["same-uid"], 1)
+	})
+
+	t.Run("rejects invalid time ranges", func(t *testing.T) {
+		importer, _ := newImporter(t)
+
+		_, err := importer.Import(context.Background(), source, ImportOptions{OrgID: 1, From: now, To: now.Add(-time.Minute)})
+		require.ErrorIs(t, err, ErrInvalidImport)
+	})
+
+	t.Run("fails if the backend does not support importing", func(t *testing.T) {
+		importer := NewStateHistoryImporter(nil, fakes.NewRuleStore(t), nil, log.NewNopLogger(), nil)
+
+		_, err := importer.Import(context.Background(), source, ImportOptions{OrgID: 1})
+		require.ErrorIs(t, err, ErrImportNotSupported)
+
+		err = NewMultipleBackend(NewNopHistorian()).Import(context.Background(), history_model.RuleMeta{}, nil)
+		require.ErrorIs(t, err, ErrImportNotSupported)
+	})
+}
+
+func TestLokiImportSource(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	total := maximumPageSize + 1000
+	client := &pagingLokiClient{}
+	for i := 0; i < total; i++ {
+		client.samples = append(client.samples, Sample{T: start.Add(time.Duration(i) * time.Second), V: fmt.Sprint(i)})
+	}
+	source := &LokiImportSource{client: client, maxQueryLength: 2 * time.Hour, clock: time.Now}
+
+	streams, err := source.Streams(context.Background(), 1, start, start.Add(time.Duration(total)*time.Second))
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	require.Len(t, streams[0].Values, total)
+	// the newest page is full, the rest of the window is read with a second query
+	require.Equal(t, 2, client.queries)
+	require.Equal(t, `{from="state-history"} | orgID="1"`, client.logQL, "the org is a label or structured metadata of the source")
+
+	t.Run("requires the start of the time range", func(t *testing.T) {
+		_, err := source.Streams(context.Background(), 1, time.Time{}, start)
+		require.ErrorIs(t, err, ErrInvalidImport)
+	})
+}
+
+type fakeImporter struct {
+	imported map[string][]ImportedTransition
+}
+
+func (f *fakeImporter) Import(_ context.Context, rule history_model.RuleMeta, transitions []ImportedTransition) error {
+	if f.imported == nil {
+		f.imported = map[string][]ImportedTransition{}
+	}
+	f.imported[rule.UID] = append(f.imported[rule.UID], transitions...)
+	return nil
+}
+
+// pagingLokiClient returns the newest samples of the queried range first, like Loki.
+type pagingLokiClient struct {
+	samples []Sample
+	queries int
+	logQL   string
+}
+
+func (c *pagingLokiClient) Ping(context.Context) error { return nil }
+
+func (c *pagingLokiClient) Push(context.Context, []Stream) error { return nil }
+
+func (c *pagingLokiClient) MaxQuerySize() int { return 0 }
+
+func (c *pagingLokiClient) RangeQuery(_ context.Context, logQL string, start, end, limit int64) (QueryRes, error) {
+	c.queries++
+	c.logQL = logQL
+	values := []Sample{}
+	for i := len(c.samples) - 1; i >= 0 && int64(len(values)) < limit; i-- {
+		ts := c.samples[i].T.UnixNano()
+		if ts >= start && ts <= end {
+			values = append(values, c.samples[i])
+		}
+	}
+	return QueryRes{Data: QueryData{Result: []Stream{{Stream: map[string]string{OrgIDLabel: "1"}, Values: values}}}}, nil
+}
@@ -0,0 +1,79 @@
+package historian
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ErrInstanceHistoryNotSupported is returned when the state history backend does not record the fingerprint of alert instances.
+var ErrInstanceHistoryNotSupported = errors.New("the state history of alert instances requires the state history to be stored in Loki or Elasticsearch")
+
+// InstanceTransition is a state transition of a single alert instance.
+type InstanceTransition struct {
+	Time      time.Time
+	RuleUID   string
+	RuleTitle string
+	Previous  string
+	Current   string
+	Error     string
+	Values    map[string]any
+	Labels    map[string]string
+}
+
+// InstanceHistory reads the transitions of the alert instance with the given fingerprint from a state history frame
+// of the Loki or Elasticsearch backend, ordered by time. The fingerprint only depends on the labels of the instance, so the transitions
+// span all versions of the rule that produced the same labels.
+func InstanceHistory(frame *data.Frame, fingerprint string) ([]InstanceTransition, error) {
+	if frame == nil || len(frame.Fields) == 0 {
+		return []InstanceTransition{}, nil
+	}
+	timeField, _ := frame.FieldByName(dfTime)
+	lineField, _ := frame.FieldByName(dfLine)
+	if timeField == nil || lineField == nil {
+		return nil, ErrInstanceHistoryNotSupported
+	}
+
+	out := []InstanceTransition{}
+	for i := 0; i < timeField.Len(); i++ {
+		ts, ok := timeField.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of %s field", dfTime)
+		}
+		line, ok := lineField.At(i).(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of %s field", dfLine)
+		}
+		var entry LokiEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		if entry.Fingerprint != fingerprint {
+			continue
+		}
+
+		t := InstanceTransition{
+			Time:      ts,
+			RuleUID:   entry.RuleUID,
+			RuleTitle: entry.RuleTitle,
+			Previous:  entry.Previous,
+			Current:   entry.Current,
+			Error:     entry.Error,
+			Labels:    entry.InstanceLabels,
+		}
+		if entry.Values != nil {
+			if values, err := entry.Values.Map(); err == nil && len(values) > 0 {
+				t.Values = values
+			}
+		}
+		out = append(out, t)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Time.Before(out[j].Time)
+	})
+	return out, nil
+}
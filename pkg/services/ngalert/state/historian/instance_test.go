@@ -0,0 +1,65 @@
+package historian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestInstanceHistory(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	labels := map[string]string{"alertname": "cpu", "team": "ops"}
+	entries := []struct {
+		time  time.Time
+		entry LokiEntry
+	}{
+		{from.Add(2 * time.Minute), LokiEntry{Fingerprint: "a", RuleUID: "r", RuleTitle: "CPU high", Previous: "Pending", Current: "Alerting", Values: simplejson.NewFromAny(map[string]any{"A": 95.5}), InstanceLabels: labels}},
+		{from.Add(time.Minute), LokiEntry{Fingerprint: "b", RuleUID: "r", Current: "Alerting"}},
+		{from, LokiEntry{Fingerprint: "a", RuleUID: "r", RuleTitle: "CPU", Previous: "Normal", Current: "Pending", InstanceLabels: labels}},
+	}
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		line, err := json.Marshal(e.entry)
+		require.NoError(t, err)
+		times = append(times, e.time)
+		lines = append(lines, line)
+	}
+	frame := data.NewFrame("states",
+		data.NewField(dfTime, nil, times),
+		data.NewField(dfLine, nil, lines),
+	)
+
+	t.Run("returns the transitions of the instance across rule versions", func(t *testing.T) {
+		res, err := InstanceHistory(frame, "a")
+		require.NoError(t, err)
+		require.Equal(t, []InstanceTransition{
+			{Time: from, RuleUID: "r", RuleTitle: "CPU", Previous: "Normal", Current: "Pending", Labels: labels},
+			{Time: from.Add(2 * time.Minute), RuleUID: "r", RuleTitle: "CPU high", Previous: "Pending", Current: "Alerting", Values: map[string]any{"A": json.Number("95.5")}, Labels: labels},
+		}, res)
+	})
+
+	t.Run("returns no transitions for unknown instances", func(t *testing.T) {
+		res, err := InstanceHistory(frame, "c")
+		require.NoError(t, err)
+		require.Empty(t, res)
+
+		res, err = InstanceHistory(nil, "a")
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("requires the Loki frame format", func(t *testing.T) {
+		annotations := data.NewFrame("states",
+			data.NewField(dfTime, nil, []time.Time{from}),
+			data.NewField(dfAnnotationNext, nil, []string{"Alerting"}),
+		)
+		_, err := InstanceHistory(annotations, "a")
+		require.ErrorIs(t, err, ErrInstanceHistoryNotSupported)
+	})
+}
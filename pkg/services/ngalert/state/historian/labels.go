@@ -0,0 +1,121 @@
+package historian
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheus "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	labelsDroppedReasonSensitive = "sensitive"
+	labelsDroppedReasonLimit     = "limit"
+)
+
+// keptLabels are kept first when the labels of a transition are over the limit
+var keptLabels = []string{prometheus.AlertNameLabel, models.FolderTitleLabel}
+
+// labelGuard limits the instance labels written to the state history: sensitive labels are dropped or hashed,
+// and the number of labels of a transition is capped.
+type labelGuard struct {
+	maxLabels int
+	drop      map[string]struct{}
+	hash      map[string]struct{}
+	salt      string
+	metrics   *metrics.Historian
+}
+
+// newLabelGuard returns the guard of the settings, or nil when the settings do not limit the labels.
+func newLabelGuard(cfg setting.UnifiedAlertingStateHistoryLabelSettings, metrics *metrics.Historian) *labelGuard {
+	if cfg.MaxLabels <= 0 && len(cfg.DropLabels) == 0 && len(cfg.HashLabels) == 0 {
+		return nil
+	}
+	g := &labelGuard{
+		maxLabels: cfg.MaxLabels,
+		drop:      make(map[string]struct{}, len(cfg.DropLabels)),
+		hash:      make(map[string]struct{}, len(cfg.HashLabels)),
+		salt:      cfg.HashSalt,
+		metrics:   metrics,
+	}
+	for _, l := range cfg.DropLabels {
+		g.drop[l] = struct{}{}
+	}
+	for _, l := range cfg.HashLabels {
+		g.hash[l] = struct{}{}
+	}
+	return g
+}
+
+// apply returns the labels to write for a transition of the org. The labels are not modified.
+// A nil guard returns the labels unchanged.
+func (g *labelGuard) apply(orgID int64, labels data.Labels) data.Labels {
+	if g == nil {
+		return labels
+	}
+
+	result := make(data.Labels, len(labels))
+	sensitive := 0
+	for k, v := range labels {
+		if _, ok := g.drop[k]; ok {
+			sensitive++
+			continue
+		}
+		if _, ok := g.hash[k]; ok {
+			v = g.hashValue(v)
+		}
+		result[k] = v
+	}
+
+	over := 0
+	if g.maxLabels > 0 && len(result) > g.maxLabels {
+		over = len(result) - g.maxLabels
+		result = capLabels(result, g.maxLabels)
+	}
+
+	if g.metrics != nil {
+		org := fmt.Sprint(orgID)
+		if sensitive > 0 {
+			g.metrics.LabelsDropped.WithLabelValues(org, labelsDroppedReasonSensitive).Add(float64(sensitive))
+		}
+		if over > 0 {
+			g.metrics.LabelsDropped.WithLabelValues(org, labelsDroppedReasonLimit).Add(float64(over))
+		}
+	}
+	return result
+}
+
+func (g *labelGuard) hashValue(v string) string {
+	sum := sha256.Sum256([]byte(g.salt + v))
+	return hex.EncodeToString(sum[:])
+}
+
+// capLabels returns max labels of the labels, keptLabels first and then the labels in alphabetical order,
+// so the same label set is always capped the same way.
+func capLabels(labels data.Labels, max int) data.Labels {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(data.Labels, max)
+	for _, k := range keptLabels {
+		if v, ok := labels[k]; ok && len(result) < max {
+			result[k] = v
+		}
+	}
+	for _, k := range keys {
+		if len(result) == max {
+			break
+		}
+		result[k] = labels[k]
+	}
+	return result
+}
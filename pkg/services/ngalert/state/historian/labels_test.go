@@ -0,0 +1,76 @@
+package historian
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLabelGuard(t *testing.T) {
+	t.Run("nil without limits", func(t *testing.T) {
+		g := newLabelGuard(setting.UnifiedAlertingStateHistoryLabelSettings{}, nil)
+		require.Nil(t, g)
+		labels := data.Labels{"a": "b"}
+		require.Equal(t, labels, g.apply(1, labels))
+	})
+
+	t.Run("drops and hashes sensitive labels", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		g := newLabelGuard(setting.UnifiedAlertingStateHistoryLabelSettings{
+			DropLabels: []string{"email"},
+			HashLabels: []string{"user"},
+			HashSalt:   "salt",
+		}, met)
+
+		labels := data.Labels{"alertname": "test", "email": "a@example.com", "user": "alice"}
+		res := g.apply(1, labels)
+
+		sum := sha256.Sum256([]byte("saltalice"))
+		require.Equal(t, data.Labels{"alertname": "test", "user": hex.EncodeToString(sum[:])}, res)
+		require.Equal(t, "alice", labels["user"], "the labels of the transition must not be modified")
+		require.Equal(t, 1.0, testutil.ToFloat64(met.LabelsDropped.WithLabelValues("1", labelsDroppedReasonSensitive)))
+	})
+
+	t.Run("caps the number of labels", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		g := newLabelGuard(setting.UnifiedAlertingStateHistoryLabelSettings{MaxLabels: 3}, met)
+
+		res := g.apply(2, data.Labels{
+			"zone":           "eu",
+			"alertname":      "test",
+			"instance":       "a",
+			"grafana_folder": "folder",
+			"b":              "b",
+		})
+
+		require.Equal(t, data.Labels{"alertname": "test", "grafana_folder": "folder", "b": "b"}, res)
+		require.Equal(t, 2.0, testutil.ToFloat64(met.LabelsDropped.WithLabelValues("2", labelsDroppedReasonLimit)))
+	})
+}
+
+func TestStatesToStreamLabelGuard(t *testing.T) {
+	rule := createTestRule()
+	labels := data.Labels{"alertname": "test", "email": "a@example.com", "host": "a"}
+	states := singleFromNormal(&state.State{State: eval.Alerting, Labels: labels})
+	g := newLabelGuard(setting.UnifiedAlertingStateHistoryLabelSettings{DropLabels: []string{"email"}}, nil)
+
+	stream := statesToStream(rule, states, nil, g, false, log.NewNopLogger())
+	require.Len(t, stream.Values, 1)
+
+	entry := LokiEntry{}
+	require.NoError(t, json.Unmarshal([]byte(stream.Values[0].V), &entry))
+	require.Equal(t, map[string]string{"alertname": "test", "host": "a"}, entry.InstanceLabels)
+	require.Equal(t, labelFingerprint(labels), entry.Fingerprint)
+}
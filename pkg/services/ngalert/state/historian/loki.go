@@ -76,22 +76,27 @@ type remoteLokiClient interface {
 type RemoteLokiBackend struct {
 	client         remoteLokiClient
 	externalLabels map[string]string
-	clock          clock.Clock
-	metrics        *metrics.Historian
-	log            log.Logger
-	ac             AccessControl
-	ruleStore      RuleStore
+	// structuredMetadata writes the org, the group and the UID of the rule as structured metadata of the entries
+	structuredMetadata bool
+	labelGuard         *labelGuard
+	clock              clock.Clock
+	metrics            *metrics.Historian
+	log                log.Logger
+	ac                 AccessControl
+	ruleStore          RuleStore
 }
 
 func NewRemoteLokiBackend(logger log.Logger, cfg LokiConfig, req client.Requester, metrics *metrics.Historian, tracer tracing.Tracer, ruleStore RuleStore, ac AccessControl) *RemoteLokiBackend {
 	return &RemoteLokiBackend{
-		client:         NewLokiClient(cfg, req, metrics, logger, tracer),
-		externalLabels: cfg.ExternalLabels,
-		clock:          clock.New(),
-		metrics:        metrics,
-		log:            logger,
-		ac:             ac,
-		ruleStore:      ruleStore,
+		client:             NewLokiClient(cfg, req, metrics, logger, tracer),
+		externalLabels:     cfg.ExternalLabels,
+		structuredMetadata: cfg.StructuredMetadata,
+		labelGuard:         newLabelGuard(cfg.Labels, metrics),
+		clock:              clock.New(),
+		metrics:            metrics,
+		log:                logger,
+		ac:                 ac,
+		ruleStore:          ruleStore,
 	}
 }
 
@@ -102,7 +107,7 @@ func (h *RemoteLokiBackend) TestConnection(ctx context.Context) error {
 // Record writes a number of state transitions for a given rule to an external Loki instance.
 func (h *RemoteLokiBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
 	logger := h.log.FromContext(ctx)
-	logStream := StatesToStream(rule, states, h.externalLabels, logger)
+	logStream := statesToStream(rule, states, h.externalLabels, h.labelGuard, h.structuredMetadata, logger)
 
 	errCh := make(chan error, 1)
 	if len(logStream.Values) == 0 {
@@ -146,7 +151,7 @@ func (h *RemoteLokiBackend) Query(ctx context.Context, query models.HistoryQuery
 		return nil, err
 	}
 
-	queries, err := BuildLogQuery(query, uids, h.client.MaxQuerySize())
+	queries, err := BuildLogQuery(query, uids, h.client.MaxQuerySize(), h.structuredMetadata)
 	if err != nil {
 		return nil, err
 	}
@@ -161,26 +166,46 @@ func (h *RemoteLokiBackend) Query(ctx context.Context, query models.HistoryQuery
 	if query.From.IsZero() {
 		query.From = now.Add(-defaultQueryRange)
 	}
+	limit := query.Limit
+	cursor, err := parseContinueToken(query.Continue)
+	if err != nil {
+		return nil, err
+	}
+	if query.PageSize > 0 {
+		// the page starts at the time of the cursor, the range query end is exclusive
+		if cursor != nil && cursor.T < query.To.UnixNano() {
+			query.To = time.Unix(0, cursor.T+1)
+		}
+		limit = cursor.limit(query.PageSize)
+	}
+
 	var res []Stream
 	for _, logQL := range queries {
 		// Timestamps are expected in RFC3339Nano.
 		// Apply user-defined limit to every request. Multiple batches is a very rare case, and therefore we can tolerate getting more data than needed.
 		// The limit can be applied after all results are merged
-		r, err := h.client.RangeQuery(ctx, logQL, query.From.UnixNano(), query.To.UnixNano(), int64(query.Limit))
+		r, err := h.client.RangeQuery(ctx, logQL, query.From.UnixNano(), query.To.UnixNano(), int64(limit))
 		if err != nil {
 			return nil, err
 		}
 		res = append(res, r.Data.Result...)
 	}
-	return merge(res, uids)
+	if query.PageSize <= 0 {
+		return merge(res, uids)
+	}
+
+	res, next := pageStreams(res, uids, cursor, query.PageSize)
+	frame, err := merge(res, uids)
+	if err != nil {
+		return nil, err
+	}
+	setContinueToken(frame, next)
+	return frame, nil
 }
 
 // merge will put all the results in one array sorted by timestamp.
 func merge(res []Stream, folderUIDToFilter []string) (*data.Frame, error) {
-	filterByFolderUIDMap := make(map[string]struct{}, len(folderUIDToFilter))
-	for _, uid := range folderUIDToFilter {
-		filterByFolderUIDMap[uid] = struct{}{}
-	}
+	filterByFolderUIDMap := folderFilter(folderUIDToFilter)
 
 	// Find the total number of elements in all arrays.
 	totalLen := 0
@@ -216,15 +241,8 @@ func merge(res []Stream, folderUIDToFilter []string) (*data.Frame, error) {
 				continue
 			}
 			// check if stream should be in the results
-			if len(filterByFolderUIDMap) > 0 {
-				folderLbl, ok := stream.Stream[FolderUIDLabel]
-				if !ok {
-					continue // skip entries without folder UID, only if needs filtering
-				}
-				_, ok = filterByFolderUIDMap[folderLbl]
-				if !ok {
-					continue
-				}
+			if !streamInFolders(stream, filterByFolderUIDMap) {
+				continue
 			}
 
 			curTime := stream.Values[pointers[i]].T.UnixNano()
@@ -269,13 +287,36 @@ func merge(res []Stream, folderUIDToFilter []string) (*data.Frame, error) {
 	return frame, nil
 }
 
+func folderFilter(folderUIDs []string) map[string]struct{} {
+	folders := make(map[string]struct{}, len(folderUIDs))
+	for _, uid := range folderUIDs {
+		folders[uid] = struct{}{}
+	}
+	return folders
+}
+
+// streamInFolders returns whether the stream is in one of the folders, all streams are when there are no folders.
+func streamInFolders(stream Stream, folders map[string]struct{}) bool {
+	if len(folders) == 0 {
+		return true
+	}
+	// skip entries without folder UID, only if needs filtering
+	folderLbl, ok := stream.Stream[FolderUIDLabel]
+	if !ok {
+		return false
+	}
+	_, ok = folders[folderLbl]
+	return ok
+}
+
 func StatesToStream(rule history_model.RuleMeta, states []state.StateTransition, externalLabels map[string]string, logger log.Logger) Stream {
-	labels := mergeLabels(make(map[string]string), externalLabels)
-	// System-defined labels take precedence over user-defined external labels.
-	labels[StateHistoryLabelKey] = StateHistoryLabelValue
-	labels[OrgIDLabel] = fmt.Sprint(rule.OrgID)
-	labels[GroupLabel] = fmt.Sprint(rule.Group)
-	labels[FolderUIDLabel] = fmt.Sprint(rule.NamespaceUID)
+	return statesToStream(rule, states, externalLabels, nil, false, logger)
+}
+
+// statesToStream returns the stream of the transitions of the rule, with the instance labels limited by the guard.
+// With structured metadata, the rule is identified by the metadata of the entries rather than the stream labels.
+func statesToStream(rule history_model.RuleMeta, states []state.StateTransition, externalLabels map[string]string, guard *labelGuard, structuredMetadata bool, logger log.Logger) Stream {
+	labels := streamLabels(rule, externalLabels, structuredMetadata)
 
 	samples := make([]Sample, 0, len(states))
 	for _, state := range states {
@@ -283,25 +324,9 @@ func StatesToStream(rule history_model.RuleMeta, states []state.StateTransition,
 			continue
 		}
 
-		sanitizedLabels := removePrivateLabels(state.Labels)
-		entry := LokiEntry{
-			SchemaVersion:  1,
-			Previous:       state.PreviousFormatted(),
-			Current:        state.Formatted(),
-			Values:         valuesAsDataBlob(state.State),
-			Condition:      rule.Condition,
-			DashboardUID:   rule.DashboardUID,
-			PanelID:        rule.PanelID,
-			Fingerprint:    labelFingerprint(sanitizedLabels),
-			RuleTitle:      rule.Title,
-			RuleID:         rule.ID,
-			RuleUID:        rule.UID,
-			InstanceLabels: sanitizedLabels,
-		}
-		if state.State.State == eval.Error {
-			entry.Error = state.Error.Error()
-		}
-
+		// the fingerprint is of all the labels, instances stay apart when their labels are limited
+		entry := newLokiEntry(rule, state)
+		entry.InstanceLabels = guard.apply(rule.OrgID, entry.InstanceLabels)
 		jsn, err := json.Marshal(entry)
 		if err != nil {
 			logger.Error("Failed to construct history record for state, skipping", "error", err)
@@ -315,10 +340,62 @@ func StatesToStream(rule history_model.RuleMeta, states []state.StateTransition,
 		})
 	}
 
-	return Stream{
+	stream := Stream{
 		Stream: labels,
 		Values: samples,
 	}
+	if structuredMetadata {
+		stream.Metadata = entryMetadata(rule)
+	}
+	return stream
+}
+
+// newLokiEntry returns the history entry of a state transition of the rule.
+func newLokiEntry(rule history_model.RuleMeta, state state.StateTransition) LokiEntry {
+	sanitizedLabels := removePrivateLabels(state.Labels)
+	entry := LokiEntry{
+		SchemaVersion:  1,
+		Previous:       state.PreviousFormatted(),
+		Current:        state.Formatted(),
+		Values:         valuesAsDataBlob(state.State),
+		Condition:      rule.Condition,
+		DashboardUID:   rule.DashboardUID,
+		PanelID:        rule.PanelID,
+		Fingerprint:    labelFingerprint(sanitizedLabels),
+		RuleTitle:      rule.Title,
+		RuleID:         rule.ID,
+		RuleUID:        rule.UID,
+		InstanceLabels: sanitizedLabels,
+	}
+	if state.State.State == eval.Error {
+		entry.Error = state.Error.Error()
+	}
+	return entry
+}
+
+// streamLabels returns the labels of the stream the state history of a rule is written to. With structured metadata,
+// the org and the group are in the metadata of the entries instead, see entryMetadata.
+func streamLabels(rule history_model.RuleMeta, externalLabels map[string]string, structuredMetadata bool) map[string]string {
+	labels := mergeLabels(make(map[string]string), externalLabels)
+	// System-defined labels take precedence over user-defined external labels.
+	labels[StateHistoryLabelKey] = StateHistoryLabelValue
+	if !structuredMetadata {
+		labels[OrgIDLabel] = fmt.Sprint(rule.OrgID)
+		labels[GroupLabel] = fmt.Sprint(rule.Group)
+	}
+	labels[FolderUIDLabel] = fmt.Sprint(rule.NamespaceUID)
+	return labels
+}
+
+// entryMetadata returns the structured metadata of the entries of the state history of a rule. As labels, every rule
+// group would be a stream. Loki returns the metadata in the labels of the streams of the query results, so the
+// entries are read the same as the ones written with the labels.
+func entryMetadata(rule history_model.RuleMeta) map[string]string {
+	return map[string]string{
+		OrgIDLabel:   fmt.Sprint(rule.OrgID),
+		GroupLabel:   rule.Group,
+		RuleUIDLabel: rule.UID,
+	}
 }
 
 func (h *RemoteLokiBackend) recordStreams(ctx context.Context, stream Stream, logger log.Logger) error {
@@ -370,7 +447,9 @@ func jsonifyRow(line string) (json.RawMessage, error) {
 // BuildLogQuery converts models.HistoryQuery and a list of folder UIDs to Loki queries.
 // It can return multiple queries if the list of folder UIDs is too big to fit into single query.
 // If there is a folder UID long enough to exceed a query size it returns ErrQueryTooLong.
-func BuildLogQuery(query models.HistoryQuery, folderUIDs []string, maxQuerySize int) ([]string, error) {
+// With structured metadata, the org is filtered after the stream selector, the filter matches both the stream labels
+// of the state history written before and the structured metadata.
+func BuildLogQuery(query models.HistoryQuery, folderUIDs []string, maxQuerySize int, structuredMetadata bool) ([]string, error) {
 	// first build tail of the query (if exists) to know what remaining capacity we have for folders
 	tail, err := buildQueryTail(query)
 	if err != nil {
@@ -378,6 +457,10 @@ func BuildLogQuery(query models.HistoryQuery, folderUIDs []string, maxQuerySize
 	}
 	// build the base selectors. skip the closing bracket because we will append folders below. Closing bracket will be added at the end
 	head := fmt.Sprintf(`{%s="%d",%s=%q`, OrgIDLabel, query.OrgID, StateHistoryLabelKey, StateHistoryLabelValue)
+	if structuredMetadata {
+		head = fmt.Sprintf(`{%s=%q`, StateHistoryLabelKey, StateHistoryLabelValue)
+		tail = orgFilter(query.OrgID) + tail
+	}
 
 	// check if system-defined + user-defined query parameters do not exceed maximum size
 	baseQuerySize := len(head) + 1 + len(tail) // 1 stands for closing bracket
@@ -438,6 +521,11 @@ func BuildLogQuery(query models.HistoryQuery, folderUIDs []string, maxQuerySize
 	return result, nil
 }
 
+// orgFilter is the filter of the entries of the org, in the labels of the streams or in the structured metadata
+func orgFilter(orgID int64) string {
+	return fmt.Sprintf(` | %s="%d"`, OrgIDLabel, orgID)
+}
+
 func buildQueryTail(query models.HistoryQuery) (string, error) {
 	if !queryHasLogFilters(query) {
 		return "", nil
@@ -463,6 +551,13 @@ func buildQueryTail(query models.HistoryQuery) (string, error) {
 		b.WriteString(" | panelID=")
 		b.WriteString(strconv.FormatInt(query.PanelID, 10))
 	}
+	if query.Fingerprint != "" {
+		b.WriteString(" | fingerprint=")
+		_, err := fmt.Fprintf(&b, "%q", query.Fingerprint)
+		if err != nil {
+			return "", err
+		}
+	}
 
 	requiredSize := 0
 	labelKeys := make([]string, 0, len(query.Labels))
@@ -489,11 +584,18 @@ func queryHasLogFilters(query models.HistoryQuery) bool {
 	return query.RuleUID != "" ||
 		query.DashboardUID != "" ||
 		query.PanelID != 0 ||
+		query.Fingerprint != "" ||
 		len(query.Labels) > 0
 }
 
 func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query models.HistoryQuery) ([]string, error) {
-	bypass, err := h.ac.CanReadAllRules(ctx, query.SignedInUser)
+	return folderUIDsForFilter(ctx, h.ac, h.ruleStore, query)
+}
+
+// folderUIDsForFilter returns the UIDs of the folders the user can read the state history of. It returns no folders
+// if the user can read the history of all rules or the query is filtered by a rule the user can read.
+func folderUIDsForFilter(ctx context.Context, ac AccessControl, ruleStore RuleStore, query models.HistoryQuery) ([]string, error) {
+	bypass, err := ac.CanReadAllRules(ctx, query.SignedInUser)
 	if err != nil {
 		return nil, err
 	}
@@ -502,7 +604,7 @@ func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query mo
 	}
 	// if there is a filter by rule UID, find that rule UID and make sure that user has access to it.
 	if query.RuleUID != "" {
-		rule, err := h.ruleStore.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{
+		rule, err := ruleStore.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{
 			UID:   query.RuleUID,
 			OrgID: query.OrgID,
 		})
@@ -512,17 +614,17 @@ func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query mo
 		if rule == nil {
 			return nil, models.ErrAlertRuleNotFound
 		}
-		return nil, h.ac.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
+		return nil, ac.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
 	}
 	// if no filter, then we need to get all namespaces user has access to
-	folders, err := h.ruleStore.GetUserVisibleNamespaces(ctx, query.OrgID, query.SignedInUser)
+	folders, err := ruleStore.GetUserVisibleNamespaces(ctx, query.OrgID, query.SignedInUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch folders that user can access: %w", err)
 	}
 	uids := make([]string, 0, len(folders))
 	// now keep only UIDs of folder in which user can read rules.
 	for _, f := range folders {
-		hasAccess, err := h.ac.HasAccessInFolder(ctx, query.SignedInUser, models.Namespace(*f))
+		hasAccess, err := ac.HasAccessInFolder(ctx, query.SignedInUser, models.Namespace(*f))
 		if err != nil {
 			return nil, err
 		}
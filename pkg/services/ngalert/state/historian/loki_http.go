@@ -43,6 +43,10 @@ type LokiConfig struct {
 	Encoder           encoder
 	MaxQueryLength    time.Duration
 	MaxQuerySize      int
+	// StructuredMetadata writes the org, the group and the UID of the rule as structured metadata of the entries.
+	StructuredMetadata bool
+	// Labels limits the instance labels written to Loki.
+	Labels setting.UnifiedAlertingStateHistoryLabelSettings
 }
 
 func NewLokiConfig(cfg setting.UnifiedAlertingStateHistorySettings) (LokiConfig, error) {
@@ -71,14 +75,16 @@ func NewLokiConfig(cfg setting.UnifiedAlertingStateHistorySettings) (LokiConfig,
 	}
 
 	return LokiConfig{
-		ReadPathURL:       readURL,
-		WritePathURL:      writeURL,
-		BasicAuthUser:     cfg.LokiBasicAuthUsername,
-		BasicAuthPassword: cfg.LokiBasicAuthPassword,
-		TenantID:          cfg.LokiTenantID,
-		ExternalLabels:    cfg.ExternalLabels,
-		MaxQueryLength:    cfg.LokiMaxQueryLength,
-		MaxQuerySize:      cfg.LokiMaxQuerySize,
+		ReadPathURL:        readURL,
+		WritePathURL:       writeURL,
+		BasicAuthUser:      cfg.LokiBasicAuthUsername,
+		BasicAuthPassword:  cfg.LokiBasicAuthPassword,
+		TenantID:           cfg.LokiTenantID,
+		ExternalLabels:     cfg.ExternalLabels,
+		MaxQueryLength:     cfg.LokiMaxQueryLength,
+		MaxQuerySize:       cfg.LokiMaxQuerySize,
+		StructuredMetadata: cfg.LokiStructuredMetadata,
+		Labels:             cfg.Labels,
 		// Snappy-compressed protobuf is the default, same goes for Promtail.
 		Encoder: SnappyProtoEncoder{},
 	}, nil
@@ -150,6 +156,9 @@ func (c *HttpLokiClient) Ping(ctx context.Context) error {
 type Stream struct {
 	Stream map[string]string `json:"stream"`
 	Values []Sample          `json:"values"`
+	// Metadata is the structured metadata of all the entries when the stream is written. The query results return
+	// it in the labels of the streams instead.
+	Metadata map[string]string `json:"-"`
 }
 
 type Sample struct {
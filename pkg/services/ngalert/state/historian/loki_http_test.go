@@ -133,6 +133,26 @@ func TestLokiHTTPClient(t *testing.T) {
 		require.JSONEq(t, exp, sent)
 	})
 
+	t.Run("push formats structured metadata", func(t *testing.T) {
+		req := NewFakeRequester()
+		client := createTestLokiClient(req)
+		now := time.Now().UTC()
+		data := []Stream{
+			{
+				Stream:   map[string]string{"from": "state-history"},
+				Values:   []Sample{{T: now, V: "some line"}},
+				Metadata: map[string]string{"orgID": "1"},
+			},
+		}
+
+		err := client.Push(context.Background(), data)
+
+		require.NoError(t, err)
+		sent := reqBody(t, req.lastRequest)
+		exp := fmt.Sprintf(`{"streams": [{"stream": {"from": "state-history"}, "values": [["%d", "some line", {"orgID": "1"}]]}]}`, now.UnixNano())
+		require.JSONEq(t, exp, sent)
+	})
+
 	t.Run("range query", func(t *testing.T) {
 		t.Run("passes along page size", func(t *testing.T) {
 			req := NewFakeRequester().WithResponse(&http.Response{
@@ -86,6 +86,28 @@ func TestRemoteLokiBackend(t *testing.T) {
 				"orgID":              fmt.Sprint(rule.OrgID),
 			}
 			require.Equal(t, exp, res.Stream)
+			require.Empty(t, res.Metadata)
+		})
+
+		t.Run("writes the rule as structured metadata", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{
+				State:  eval.Alerting,
+				Labels: data.Labels{"a": "b"},
+			})
+
+			res := statesToStream(rule, states, nil, nil, true, log.NewNopLogger())
+
+			require.Equal(t, map[string]string{
+				StateHistoryLabelKey: StateHistoryLabelValue,
+				"folderUID":          rule.NamespaceUID,
+			}, res.Stream)
+			require.Equal(t, map[string]string{
+				"group":   rule.Group,
+				"orgID":   fmt.Sprint(rule.OrgID),
+				"ruleUID": rule.UID,
+			}, res.Metadata)
+			_ = requireSingleEntry(t, res)
 		})
 
 		t.Run("excludes private labels", func(t *testing.T) {
@@ -210,8 +232,10 @@ func TestBuildLogQuery(t *testing.T) {
 		name       string
 		query      models.HistoryQuery
 		folderUIDs []string
-		exp        []string
-		expErr     error
+		// structuredMetadata filters the org in the labels or the structured metadata
+		structuredMetadata bool
+		exp                []string
+		expErr             error
 	}{
 		{
 			name:  "default includes state history label and orgID label",
@@ -241,6 +265,14 @@ func TestBuildLogQuery(t *testing.T) {
 			},
 			exp: []string{`{orgID="123",from="state-history"} | json | dashboardUID="dash-uid"`},
 		},
+		{
+			name: "filters fingerprint in log line",
+			query: models.HistoryQuery{
+				OrgID:       123,
+				Fingerprint: "0123456789abcdef",
+			},
+			exp: []string{`{orgID="123",from="state-history"} | json | fingerprint="0123456789abcdef"`},
+		},
 		{
 			name: "filters panelID in log line",
 			query: models.HistoryQuery{
@@ -316,6 +348,16 @@ func TestBuildLogQuery(t *testing.T) {
 				`{orgID="123",from="state-history",folderUID=~` + "`folder-!!!!!!!!!!!!!`" + `} | json | labels_customlabel="customvalue"`,
 			},
 		},
+		{
+			name: "filters the org after the selector with structured metadata",
+			query: models.HistoryQuery{
+				OrgID:   123,
+				RuleUID: "rule-uid",
+			},
+			folderUIDs:         []string{"folder-1"},
+			structuredMetadata: true,
+			exp:                []string{`{from="state-history",folderUID=~` + "`folder-1`" + `} | orgID="123" | json | ruleUID="rule-uid"`},
+		},
 		{
 			name: "should fail if a single folder UID is too long",
 			query: models.HistoryQuery{
@@ -331,7 +373,7 @@ func TestBuildLogQuery(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			res, err := BuildLogQuery(tc.query, tc.folderUIDs, maxQuerySize)
+			res, err := BuildLogQuery(tc.query, tc.folderUIDs, maxQuerySize, tc.structuredMetadata)
 			if tc.expErr != nil {
 				require.ErrorIs(t, err, tc.expErr)
 				return
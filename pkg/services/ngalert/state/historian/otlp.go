@@ -0,0 +1,297 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// otlpScopeName is the instrumentation scope of the exported log records
+	otlpScopeName = "grafana.alerting.state_history"
+
+	// Severity numbers of the OpenTelemetry log data model
+	otlpSeverityInfo  = 9
+	otlpSeverityWarn  = 13
+	otlpSeverityError = 17
+)
+
+var errOTLPQueryNotSupported = errors.New("the OTLP state history exporter does not support queries")
+
+// The types below are the parts of the JSON encoding of the OTLP logs export request that are used.
+// 64 bit integers are encoded as strings, as required by the encoding.
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string        `json:"stringValue,omitempty"`
+	IntValue    *string        `json:"intValue,omitempty"`
+	DoubleValue *float64       `json:"doubleValue,omitempty"`
+	KvlistValue *otlpKeyValues `json:"kvlistValue,omitempty"`
+}
+
+type otlpKeyValues struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+type otlpExport struct {
+	orgID int64
+	body  []byte
+}
+
+// OTLPBackend is a state history backend that exports state transitions as OpenTelemetry log records to an OTLP/HTTP
+// collector, so observability pipelines can analyze alert behavior alongside traces and logs. It does not store
+// anything, so it is meant to be used as a secondary of MultipleBackend.
+// Batches are queued and exported in the background by Run, with retries for failed requests.
+type OTLPBackend struct {
+	cfg     setting.UnifiedAlertingStateHistoryOTLPSettings
+	client  client.Requester
+	queue   chan otlpExport
+	metrics *metrics.Historian
+	log     log.Logger
+	wait    func(ctx context.Context, d time.Duration) error
+	now     func() time.Time
+}
+
+func NewOTLPBackend(cfg setting.UnifiedAlertingStateHistoryOTLPSettings, req client.Requester, metrics *metrics.Historian, logger log.Logger) *OTLPBackend {
+	return &OTLPBackend{
+		cfg:     cfg,
+		client:  client.NewTimedClient(req, metrics.WriteDuration),
+		queue:   make(chan otlpExport, cfg.QueueSize),
+		metrics: metrics,
+		log:     logger,
+		wait:    waitContext,
+		now:     time.Now,
+	}
+}
+
+// Record implements state.Historian. It queues the transitions as a batch of log records without blocking.
+func (b *OTLPBackend) Record(_ context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	errCh := make(chan error)
+	close(errCh)
+
+	observed := strconv.FormatInt(b.now().UnixNano(), 10)
+	var records []otlpLogRecord
+	for _, t := range states {
+		if shouldRecord(t) {
+			records = append(records, newOTLPLogRecord(rule, t, observed))
+		}
+	}
+	if len(records) == 0 {
+		return errCh
+	}
+	body, err := json.Marshal(otlpLogsRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource:  otlpResource{Attributes: []otlpKeyValue{otlpString("service.name", "grafana")}},
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}, LogRecords: records}},
+	}}})
+	if err != nil {
+		b.log.Error("Failed to encode state transitions as OTLP logs", "org", rule.OrgID, "rule_uid", rule.UID, "error", err)
+		return errCh
+	}
+
+	select {
+	case b.queue <- otlpExport{orgID: rule.OrgID, body: body}:
+	default:
+		b.metrics.OTLPDropped.WithLabelValues(strconv.FormatInt(rule.OrgID, 10)).Inc()
+		b.log.Warn("Dropping state transitions, the OTLP export queue is full", "org", rule.OrgID, "rule_uid", rule.UID)
+	}
+	return errCh
+}
+
+// Query implements Backend. The collector is not queried, so the exporter cannot be used as a primary.
+func (b *OTLPBackend) Query(_ context.Context, _ models.HistoryQuery) (*data.Frame, error) {
+	return nil, errOTLPQueryNotSupported
+}
+
+// Run exports the queued batches until the context is cancelled. Batches are exported one at a time, in the order
+// of the transitions.
+func (b *OTLPBackend) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-b.queue:
+			b.export(ctx, e)
+		}
+	}
+}
+
+// export posts a batch, failed requests are retried with an exponential backoff.
+// Requests rejected with a client error other than 429 Too Many Requests are not retried.
+func (b *OTLPBackend) export(ctx context.Context, e otlpExport) {
+	org := strconv.FormatInt(e.orgID, 10)
+	backoff := b.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := b.send(ctx, e)
+		if err == nil {
+			b.metrics.OTLPExported.WithLabelValues(org).Inc()
+			return
+		}
+		if !retry || attempt >= b.cfg.MaxAttempts {
+			b.metrics.OTLPFailed.WithLabelValues(org).Inc()
+			b.log.Error("Failed to export state transitions to the OTLP collector", "org", e.orgID, "attempts", attempt, "error", err)
+			return
+		}
+		b.metrics.OTLPRetries.WithLabelValues(org).Inc()
+		b.log.Debug("Retrying the export of state transitions to the OTLP collector", "org", e.orgID, "backoff", backoff, "error", err)
+		if b.wait(ctx, backoff) != nil {
+			b.metrics.OTLPFailed.WithLabelValues(org).Inc()
+			return
+		}
+		backoff *= 2
+	}
+}
+
+func (b *OTLPBackend) send(ctx context.Context, e otlpExport) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.cfg.Endpoint, bytes.NewReader(e.body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range b.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("OTLP collector responded with status %d", res.StatusCode)
+}
+
+// newOTLPLogRecord returns the log record of a transition. The rule, the states and the labels of the instance are
+// attributes of the record, the labels are a single map attribute so they cannot collide with the other attributes.
+func newOTLPLogRecord(rule history_model.RuleMeta, t state.StateTransition, observed string) otlpLogRecord {
+	e := newStreamEvent(rule, t)
+	severity, severityText := otlpSeverityInfo, "INFO"
+	switch t.State.State {
+	case eval.Alerting:
+		severity, severityText = otlpSeverityWarn, "WARN"
+	case eval.Error:
+		severity, severityText = otlpSeverityError, "ERROR"
+	}
+
+	attrs := []otlpKeyValue{
+		otlpInt("grafana.org.id", e.OrgID),
+		otlpString("grafana.alert.rule.uid", e.RuleUID),
+		otlpString("grafana.alert.rule.title", e.RuleTitle),
+		otlpString("grafana.alert.rule.group", e.RuleGroup),
+		otlpString("grafana.alert.rule.folder.uid", e.NamespaceUID),
+		otlpString("grafana.alert.state.previous", e.PreviousState),
+		otlpString("grafana.alert.state.current", e.CurrentState),
+		otlpString("grafana.alert.fingerprint", e.Fingerprint),
+		otlpStrings("grafana.alert.labels", e.Labels),
+	}
+	if len(e.Values) > 0 {
+		values := make([]otlpKeyValue, 0, len(e.Values))
+		for _, k := range sortedKeys(e.Values) {
+			v := e.Values[k]
+			values = append(values, otlpKeyValue{Key: k, Value: otlpAnyValue{DoubleValue: &v}})
+		}
+		attrs = append(attrs, otlpKeyValue{Key: "grafana.alert.values", Value: otlpAnyValue{KvlistValue: &otlpKeyValues{Values: values}}})
+	}
+	if e.Error != "" {
+		attrs = append(attrs, otlpString("grafana.alert.error", e.Error))
+	}
+	if e.DashboardUID != "" {
+		attrs = append(attrs, otlpString("grafana.dashboard.uid", e.DashboardUID), otlpInt("grafana.panel.id", e.PanelID))
+	}
+
+	body := fmt.Sprintf("%s: %s -> %s", e.RuleTitle, e.PreviousState, e.CurrentState)
+	return otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(e.Timestamp.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       severity,
+		SeverityText:         severityText,
+		Body:                 otlpAnyValue{StringValue: &body},
+		Attributes:           attrs,
+	}
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
+
+func otlpInt(key string, value int64) otlpKeyValue {
+	v := strconv.FormatInt(value, 10)
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{IntValue: &v}}
+}
+
+func otlpStrings(key string, m map[string]string) otlpKeyValue {
+	values := make([]otlpKeyValue, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		values = append(values, otlpString(k, m[k]))
+	}
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{KvlistValue: &otlpKeyValues{Values: values}}}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
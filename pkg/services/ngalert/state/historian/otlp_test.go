@@ -0,0 +1,142 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestOTLPBackend(t *testing.T) {
+	rule := history_model.RuleMeta{OrgID: 1, UID: "rule", Title: "Rule", Group: "group", NamespaceUID: "folder"}
+	evaluated := time.Unix(100, 0)
+	transitions := []state.StateTransition{
+		{State: &state.State{State: eval.Alerting, Labels: data.Labels{"team": "ops"}, LastEvaluationTime: evaluated, Values: map[string]float64{"A": 1.5}}, PreviousState: eval.Normal},
+		{State: &state.State{State: eval.Alerting, Labels: data.Labels{"team": "dev"}}, PreviousState: eval.Alerting},
+	}
+
+	type request struct {
+		header  http.Header
+		payload otlpLogsRequest
+	}
+	newServer := func(statuses ...int) (*httptest.Server, func() []request) {
+		var mtx sync.Mutex
+		var requests []request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			var p otlpLogsRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			requests = append(requests, request{header: r.Header, payload: p})
+			status := http.StatusOK
+			if len(requests) <= len(statuses) {
+				status = statuses[len(requests)-1]
+			}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, func() []request {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return requests
+		}
+	}
+	newBackend := func(url string) (*OTLPBackend, *metrics.Historian) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		b := NewOTLPBackend(setting.UnifiedAlertingStateHistoryOTLPSettings{
+			Enabled:     true,
+			Endpoint:    url,
+			Headers:     map[string]string{"Authorization": "Bearer token"},
+			Timeout:     time.Second,
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+			QueueSize:   1,
+		}, NewRequester(), met, log.NewNopLogger())
+		b.wait = func(context.Context, time.Duration) error { return nil }
+		b.now = func() time.Time { return time.Unix(200, 0) }
+		return b, met
+	}
+
+	t.Run("exports the transitions as log records", func(t *testing.T) {
+		srv, requests := newServer()
+		b, met := newBackend(srv.URL)
+
+		require.NoError(t, <-b.Record(context.Background(), rule, transitions))
+		b.export(context.Background(), <-b.queue)
+
+		require.Len(t, requests(), 1)
+		r := requests()[0]
+		require.Equal(t, "Bearer token", r.header.Get("Authorization"))
+		require.Equal(t, "application/json", r.header.Get("Content-Type"))
+		require.Len(t, r.payload.ResourceLogs, 1)
+		require.Equal(t, otlpScopeName, r.payload.ResourceLogs[0].ScopeLogs[0].Scope.Name)
+		records := r.payload.ResourceLogs[0].ScopeLogs[0].LogRecords
+		require.Len(t, records, 1, "unchanged states are not exported")
+
+		record := records[0]
+		require.Equal(t, "100000000000", record.TimeUnixNano)
+		require.Equal(t, "200000000000", record.ObservedTimeUnixNano)
+		require.Equal(t, otlpSeverityWarn, record.SeverityNumber)
+		require.Equal(t, "Rule: Normal -> Alerting", *record.Body.StringValue)
+
+		attrs := make(map[string]otlpAnyValue, len(record.Attributes))
+		for _, kv := range record.Attributes {
+			attrs[kv.Key] = kv.Value
+		}
+		require.Equal(t, "1", *attrs["grafana.org.id"].IntValue)
+		require.Equal(t, "rule", *attrs["grafana.alert.rule.uid"].StringValue)
+		require.Equal(t, "folder", *attrs["grafana.alert.rule.folder.uid"].StringValue)
+		require.Equal(t, "Normal", *attrs["grafana.alert.state.previous"].StringValue)
+		require.Equal(t, "Alerting", *attrs["grafana.alert.state.current"].StringValue)
+		require.Equal(t, []otlpKeyValue{otlpString("team", "ops")}, attrs["grafana.alert.labels"].KvlistValue.Values)
+		require.Equal(t, 1.5, *attrs["grafana.alert.values"].KvlistValue.Values[0].Value.DoubleValue)
+		require.Equal(t, 1.0, testutil.ToFloat64(met.OTLPExported.WithLabelValues("1")))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		srv, requests := newServer(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		b, met := newBackend(srv.URL)
+
+		<-b.Record(context.Background(), rule, transitions)
+		b.export(context.Background(), <-b.queue)
+
+		require.Len(t, requests(), 3)
+		require.Equal(t, 2.0, testutil.ToFloat64(met.OTLPRetries.WithLabelValues("1")))
+		require.Equal(t, 1.0, testutil.ToFloat64(met.OTLPExported.WithLabelValues("1")))
+	})
+
+	t.Run("gives up on client errors", func(t *testing.T) {
+		srv, requests := newServer(http.StatusBadRequest)
+		b, met := newBackend(srv.URL)
+
+		<-b.Record(context.Background(), rule, transitions)
+		b.export(context.Background(), <-b.queue)
+
+		require.Len(t, requests(), 1)
+		require.Equal(t, 1.0, testutil.ToFloat64(met.OTLPFailed.WithLabelValues("1")))
+	})
+
+	t.Run("drops batches when the queue is full", func(t *testing.T) {
+		b, met := newBackend("http://localhost")
+
+		<-b.Record(context.Background(), rule, transitions)
+		<-b.Record(context.Background(), rule, transitions)
+		require.Len(t, b.queue, 1)
+		require.Equal(t, 1.0, testutil.ToFloat64(met.OTLPDropped.WithLabelValues("1")))
+	})
+}
@@ -0,0 +1,141 @@
+package historian
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ErrInvalidContinueToken is returned when the continue token of a state history query cannot be parsed.
+var ErrInvalidContinueToken = errors.New("invalid continue token")
+
+// HistoryPageMeta is the custom metadata of a page of the state history. Continue is the token of the page of older
+// transitions, it is empty on the last page.
+type HistoryPageMeta struct {
+	Continue string `json:"continue,omitempty"`
+}
+
+// historyCursor is the position of a page in a history ordered from the newest to the oldest transition: the page
+// starts at the transitions of time T, after the first Skip transitions of that time.
+type historyCursor struct {
+	// T is the time of the last transition of the previous page, in nanoseconds.
+	T    int64 `json:"t"`
+	Skip int   `json:"s"`
+}
+
+// parseContinueToken returns the cursor of the token, or nil for an empty token.
+func parseContinueToken(token string) (*historyCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidContinueToken, err)
+	}
+	c := &historyCursor{}
+	if err := json.Unmarshal(raw, c); err != nil || c.Skip < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidContinueToken, token)
+	}
+	return c, nil
+}
+
+func (c *historyCursor) token() string {
+	if c == nil {
+		return ""
+	}
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// limit returns the number of transitions to read for a page of the given size: the ones skipped at the time of
+// the cursor, and one more to know whether there is a next page.
+func (c *historyCursor) limit(size int) int {
+	if c == nil {
+		return size + 1
+	}
+	return size + c.Skip + 1
+}
+
+// page returns the transitions of the page in times, ordered from the newest to the oldest, as the range
+// [start, end), and the cursor of the next page, nil on the last page.
+func (c *historyCursor) page(times []int64, size int) (int, int, *historyCursor) {
+	start := 0
+	if c != nil {
+		for start < len(times) && times[start] > c.T {
+			start++
+		}
+		for skipped := 0; start < len(times) && times[start] == c.T && skipped < c.Skip; skipped++ {
+			start++
+		}
+	}
+	end := min(start+size, len(times))
+	if end == len(times) || end == start {
+		return start, end, nil
+	}
+
+	next := &historyCursor{T: times[end-1]}
+	if c != nil && c.T == next.T {
+		next.Skip = c.Skip
+	}
+	for i := start; i < end; i++ {
+		if times[i] == next.T {
+			next.Skip++
+		}
+	}
+	return start, end, next
+}
+
+// pageStreams returns the transitions of the page of the streams, and the cursor of the next page. The streams of
+// the folders that are filtered out are skipped.
+func pageStreams(streams []Stream, folderUIDs []string, c *historyCursor, size int) ([]Stream, *historyCursor) {
+	folders := folderFilter(folderUIDs)
+	type position struct{ stream, sample int }
+	positions := make([]position, 0)
+	for i, stream := range streams {
+		if !streamInFolders(stream, folders) {
+			continue
+		}
+		for j := range stream.Values {
+			positions = append(positions, position{stream: i, sample: j})
+		}
+	}
+	at := func(p position) int64 { return streams[p.stream].Values[p.sample].T.UnixNano() }
+	sort.SliceStable(positions, func(i, j int) bool { return at(positions[i]) > at(positions[j]) })
+
+	times := make([]int64, len(positions))
+	for i, p := range positions {
+		times[i] = at(p)
+	}
+	start, end, next := c.page(times, size)
+
+	selected := make([]map[int]bool, len(streams))
+	for _, p := range positions[start:end] {
+		if selected[p.stream] == nil {
+			selected[p.stream] = map[int]bool{}
+		}
+		selected[p.stream][p.sample] = true
+	}
+	result := make([]Stream, 0, len(streams))
+	for i, stream := range streams {
+		if selected[i] == nil {
+			continue
+		}
+		values := make([]Sample, 0, len(selected[i]))
+		for j, sample := range stream.Values {
+			if selected[i][j] {
+				values = append(values, sample)
+			}
+		}
+		result = append(result, Stream{Stream: stream.Stream, Values: values})
+	}
+	return result, next
+}
+
+// setContinueToken sets the token of the next page in the metadata of the frame.
+func setContinueToken(frame *data.Frame, next *historyCursor) {
+	frame.SetMeta(&data.FrameMeta{Custom: HistoryPageMeta{Continue: next.token()}})
+}
@@ -0,0 +1,77 @@
+package historian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryCursor(t *testing.T) {
+	// newest first, with transitions at the same time across pages
+	times := []int64{9, 8, 8, 8, 5, 3}
+
+	t.Run("pages the history", func(t *testing.T) {
+		var cursor *historyCursor
+		pages := [][]int64{}
+		for {
+			start, end, next := cursor.page(times, 2)
+			pages = append(pages, times[start:end])
+			if next == nil {
+				break
+			}
+			parsed, err := parseContinueToken(next.token())
+			require.NoError(t, err)
+			require.Equal(t, next, parsed)
+			cursor = parsed
+		}
+		require.Equal(t, [][]int64{{9, 8}, {8, 8}, {5, 3}}, pages)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		start, end, next := (&historyCursor{T: 5}).page(times, 10)
+		require.Equal(t, []int64{5, 3}, times[start:end])
+		require.Nil(t, next)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		cursor, err := parseContinueToken("")
+		require.NoError(t, err)
+		require.Nil(t, cursor)
+		for _, token := range []string{"%%%", "bm90IGpzb24", (&historyCursor{T: 1, Skip: -1}).token()} {
+			_, err := parseContinueToken(token)
+			require.ErrorIs(t, err, ErrInvalidContinueToken, token)
+		}
+	})
+}
+
+func TestPageStreams(t *testing.T) {
+	sample := func(ts int64, v string) Sample { return Sample{T: time.Unix(0, ts), V: v} }
+	streams := []Stream{
+		{Stream: map[string]string{FolderUIDLabel: "a"}, Values: []Sample{sample(1, "a1"), sample(4, "a4"), sample(6, "a6")}},
+		{Stream: map[string]string{FolderUIDLabel: "b"}, Values: []Sample{sample(2, "b2"), sample(5, "b5")}},
+		{Stream: map[string]string{FolderUIDLabel: "c"}, Values: []Sample{sample(7, "c7")}},
+	}
+
+	page, next := pageStreams(streams, []string{"a", "b"}, nil, 3)
+	require.Equal(t, []Stream{
+		{Stream: map[string]string{FolderUIDLabel: "a"}, Values: []Sample{sample(4, "a4"), sample(6, "a6")}},
+		{Stream: map[string]string{FolderUIDLabel: "b"}, Values: []Sample{sample(5, "b5")}},
+	}, page)
+	require.Equal(t, &historyCursor{T: 4, Skip: 1}, next)
+
+	page, next = pageStreams(streams, []string{"a", "b"}, next, 3)
+	require.Equal(t, []Stream{
+		{Stream: map[string]string{FolderUIDLabel: "a"}, Values: []Sample{sample(1, "a1")}},
+		{Stream: map[string]string{FolderUIDLabel: "b"}, Values: []Sample{sample(2, "b2")}},
+	}, page)
+	require.Nil(t, next)
+
+	frame := data.NewFrame("states")
+	setContinueToken(frame, &historyCursor{T: 4, Skip: 1})
+	token := frame.Meta.Custom.(HistoryPageMeta).Continue
+	cursor, err := parseContinueToken(token)
+	require.NoError(t, err)
+	require.Equal(t, &historyCursor{T: 4, Skip: 1}, cursor)
+}
@@ -0,0 +1,251 @@
+package historian
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	retentionReasonMaxAge         = "max_age"
+	retentionReasonMaxTransitions = "max_transitions"
+	retentionReasonDeletedRule    = "deleted_rule"
+)
+
+// RetentionStore is the storage used by the state history retention job.
+type RetentionStore interface {
+	// GetOrgIDs returns IDs of all organizations that have alert state history.
+	GetOrgIDs(ctx context.Context) ([]int64, error)
+	// CountOlderThan returns the number of state transitions of the organization that happened before the given time.
+	CountOlderThan(ctx context.Context, orgID int64, before time.Time) (int64, error)
+	// DeleteOlderThan deletes up to limit state transitions of the organization that happened before the given time.
+	DeleteOlderThan(ctx context.Context, orgID int64, before time.Time, limit int) (int64, error)
+	// GetRuleTransitionCounts returns the number of state transitions per alert rule ID for rules that have more than limit transitions.
+	GetRuleTransitionCounts(ctx context.Context, orgID int64, limit int64) (map[int64]int64, error)
+	// DeleteOldestForRule deletes up to limit state transitions of the rule, keeping the newest keep transitions.
+	DeleteOldestForRule(ctx context.Context, orgID, ruleID, keep int64, limit int) (int64, error)
+	// GetDeletedRuleTransitionCounts returns the number of state transitions per alert rule ID for rules that do not exist
+	// anymore and whose last transition happened before the given time.
+	GetDeletedRuleTransitionCounts(ctx context.Context, orgID int64, before time.Time) (map[int64]int64, error)
+	// GetRuleUID returns the UID of the alert rule recorded in its newest state transition, or an empty string if it is not recorded.
+	GetRuleUID(ctx context.Context, orgID, ruleID int64) (string, error)
+}
+
+// CompactionResult describes the outcome of a single run of the retention job for an organization.
+type CompactionResult struct {
+	OrgID  int64
+	DryRun bool
+	// ExpiredTransitions is the number of transitions that are older than the configured maximum age.
+	ExpiredTransitions int64
+	// ExcessTransitions is the number of transitions that exceed the maximum number of transitions per rule.
+	ExcessTransitions int64
+	// RulesCompacted is the number of alert rules that had more transitions than allowed.
+	RulesCompacted int
+}
+
+// DeletedRuleHistory describes the state history of a deleted alert rule.
+type DeletedRuleHistory struct {
+	RuleID int64
+	// RuleUID is empty if the history was recorded before the UID of the rule was stored with it.
+	RuleUID     string
+	Transitions int64
+}
+
+// PurgeResult describes the outcome of purging the state history of deleted alert rules for an organization.
+type PurgeResult struct {
+	OrgID  int64
+	DryRun bool
+	// Rules are the deleted alert rules whose state history is older than the grace period, sorted by rule ID.
+	Rules []DeletedRuleHistory
+	// Transitions is the total number of state transitions of the rules.
+	Transitions int64
+}
+
+// AnnotationRetention prunes and compacts alert state history stored in annotations according to per-organization retention settings.
+type AnnotationRetention struct {
+	store   RetentionStore
+	cfg     setting.UnifiedAlertingStateHistoryRetentionSettings
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewAnnotationRetention(store RetentionStore, cfg setting.UnifiedAlertingStateHistoryRetentionSettings, metrics *metrics.Historian, logger log.Logger) *AnnotationRetention {
+	return &AnnotationRetention{
+		store:   store,
+		cfg:     cfg,
+		clock:   clock.New(),
+		metrics: metrics,
+		log:     logger,
+	}
+}
+
+// Run periodically applies retention to all organizations until the context is cancelled.
+func (r *AnnotationRetention) Run(ctx context.Context) error {
+	if !r.cfg.Enabled {
+		return nil
+	}
+	r.log.Info("Starting state history retention job", "interval", r.cfg.Interval)
+	ticker := r.clock.Ticker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping state history retention job")
+			return nil
+		case <-ticker.C:
+			r.runOnce(ctx)
+		}
+	}
+}
+
+func (r *AnnotationRetention) runOnce(ctx context.Context) {
+	orgIDs, err := r.store.GetOrgIDs(ctx)
+	if err != nil {
+		r.log.Error("Failed to fetch organizations with state history", "error", err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		res, err := r.Compact(ctx, orgID, false)
+		if err != nil {
+			r.metrics.RetentionFailed.WithLabelValues(fmt.Sprint(orgID)).Inc()
+			r.log.Error("Failed to apply state history retention", "org", orgID, "error", err)
+			continue
+		}
+		if res.ExpiredTransitions > 0 || res.ExcessTransitions > 0 {
+			r.log.Debug("Applied state history retention", "org", orgID, "expired", res.ExpiredTransitions, "excess", res.ExcessTransitions, "rules", res.RulesCompacted)
+		}
+		purged, err := r.PurgeDeletedRules(ctx, orgID, false)
+		if err != nil {
+			r.metrics.RetentionFailed.WithLabelValues(fmt.Sprint(orgID)).Inc()
+			r.log.Error("Failed to purge state history of deleted rules", "org", orgID, "error", err)
+			continue
+		}
+		if purged.Transitions > 0 {
+			r.log.Debug("Purged state history of deleted rules", "org", orgID, "transitions", purged.Transitions, "rules", len(purged.Rules))
+		}
+	}
+}
+
+// Compact applies the retention configured for the organization to its state history.
+// If dryRun is true, nothing is deleted and the result contains the number of transitions that would have been deleted.
+func (r *AnnotationRetention) Compact(ctx context.Context, orgID int64, dryRun bool) (CompactionResult, error) {
+	result := CompactionResult{OrgID: orgID, DryRun: dryRun}
+	retention := r.cfg.ForOrg(orgID)
+	if retention.IsZero() {
+		return result, nil
+	}
+	org := fmt.Sprint(orgID)
+
+	if retention.MaxAge > 0 {
+		before := r.clock.Now().Add(-retention.MaxAge)
+		if dryRun {
+			count, err := r.store.CountOlderThan(ctx, orgID, before)
+			if err != nil {
+				return result, fmt.Errorf("failed to count expired state history: %w", err)
+			}
+			result.ExpiredTransitions = count
+		} else {
+			affected, err := untilDone(ctx, func() (int64, error) {
+				return r.store.DeleteOlderThan(ctx, orgID, before, r.cfg.BatchSize)
+			})
+			result.ExpiredTransitions = affected
+			r.metrics.RetentionDeleted.WithLabelValues(org, retentionReasonMaxAge).Add(float64(affected))
+			if err != nil {
+				return result, fmt.Errorf("failed to delete expired state history: %w", err)
+			}
+		}
+	}
+
+	if retention.MaxTransitionsPerRule > 0 {
+		counts, err := r.store.GetRuleTransitionCounts(ctx, orgID, retention.MaxTransitionsPerRule)
+		if err != nil {
+			return result, fmt.Errorf("failed to count state history per rule: %w", err)
+		}
+		result.RulesCompacted = len(counts)
+		for ruleID, count := range counts {
+			if dryRun {
+				result.ExcessTransitions += count - retention.MaxTransitionsPerRule
+				continue
+			}
+			affected, err := untilDone(ctx, func() (int64, error) {
+				return r.store.DeleteOldestForRule(ctx, orgID, ruleID, retention.MaxTransitionsPerRule, r.cfg.BatchSize)
+			})
+			result.ExcessTransitions += affected
+			r.metrics.RetentionDeleted.WithLabelValues(org, retentionReasonMaxTransitions).Add(float64(affected))
+			if err != nil {
+				return result, fmt.Errorf("failed to compact state history of rule %d: %w", ruleID, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// PurgeDeletedRules deletes the state history of the alert rules of the organization that do not exist anymore, once
+// their last state transition is older than the grace period configured for the organization.
+// If dryRun is true, nothing is deleted and the result lists the rules whose state history would have been deleted.
+func (r *AnnotationRetention) PurgeDeletedRules(ctx context.Context, orgID int64, dryRun bool) (PurgeResult, error) {
+	result := PurgeResult{OrgID: orgID, DryRun: dryRun, Rules: []DeletedRuleHistory{}}
+	gracePeriod := r.cfg.ForOrg(orgID).DeletedRulesGracePeriod
+	if gracePeriod <= 0 {
+		return result, nil
+	}
+	counts, err := r.store.GetDeletedRuleTransitionCounts(ctx, orgID, r.clock.Now().Add(-gracePeriod))
+	if err != nil {
+		return result, fmt.Errorf("failed to find the state history of deleted rules: %w", err)
+	}
+	ruleIDs := make([]int64, 0, len(counts))
+	for ruleID := range counts {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	slices.Sort(ruleIDs)
+
+	org := fmt.Sprint(orgID)
+	for _, ruleID := range ruleIDs {
+		uid, err := r.store.GetRuleUID(ctx, orgID, ruleID)
+		if err != nil {
+			return result, fmt.Errorf("failed to read the state history of deleted rule %d: %w", ruleID, err)
+		}
+		rule := DeletedRuleHistory{RuleID: ruleID, RuleUID: uid, Transitions: counts[ruleID]}
+		if !dryRun {
+			affected, err := untilDone(ctx, func() (int64, error) {
+				return r.store.DeleteOldestForRule(ctx, orgID, ruleID, 0, r.cfg.BatchSize)
+			})
+			rule.Transitions = affected
+			r.metrics.RetentionDeleted.WithLabelValues(org, retentionReasonDeletedRule).Add(float64(affected))
+			if err != nil {
+				return result, fmt.Errorf("failed to purge state history of deleted rule %d: %w", ruleID, err)
+			}
+		}
+		result.Rules = append(result.Rules, rule)
+		result.Transitions += rule.Transitions
+	}
+	return result, nil
+}
+
+// untilDone repeatedly runs the batch until it deletes nothing, the context is cancelled or an error occurs.
+func untilDone(ctx context.Context, batch func() (int64, error)) (int64, error) {
+	var total int64
+	for {
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		default:
+			affected, err := batch()
+			total += affected
+			if err != nil || affected == 0 {
+				return total, err
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// Annotations created by alert rules are the only ones that have alert_id set.
+const alertAnnotationsCondition = "alert_id <> 0"
+
+// annotationDataRuleUID is the key of the UID of the alert rule in the data of its annotations.
+const annotationDataRuleUID = "ruleUID"
+
+// AnnotationRetentionStore implements RetentionStore on top of the annotation table.
+// Orphaned rows of the annotation_tag table are removed by the regular annotation cleanup job.
+type AnnotationRetentionStore struct {
+	db db.DB
+}
+
+func NewAnnotationRetentionStore(db db.DB) *AnnotationRetentionStore {
+	return &AnnotationRetentionStore{db: db}
+}
+
+func (s *AnnotationRetentionStore) GetOrgIDs(ctx context.Context) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT DISTINCT org_id FROM annotation WHERE " + alertAnnotationsCondition).Find(&ids)
+	})
+	return ids, err
+}
+
+func (s *AnnotationRetentionStore) CountOlderThan(ctx context.Context, orgID int64, before time.Time) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT COUNT(*) FROM annotation WHERE org_id = ? AND "+alertAnnotationsCondition+" AND epoch < ?", orgID, before.UnixMilli()).Get(&count)
+		return err
+	})
+	return count, err
+}
+
+func (s *AnnotationRetentionStore) DeleteOlderThan(ctx context.Context, orgID int64, before time.Time, limit int) (int64, error) {
+	// Loading the IDs first avoids deadlocks with concurrent inserts on MySQL, see the annotation cleanup job.
+	sql := fmt.Sprintf("SELECT id FROM annotation WHERE org_id = ? AND %s AND epoch < ? ORDER BY id %s", alertAnnotationsCondition, s.db.GetDialect().Limit(int64(limit)))
+	ids, err := s.fetchIDs(ctx, sql, orgID, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return s.deleteByIDs(ctx, ids)
+}
+
+func (s *AnnotationRetentionStore) GetRuleTransitionCounts(ctx context.Context, orgID int64, limit int64) (map[int64]int64, error) {
+	type ruleCount struct {
+		AlertID int64 `xorm:"alert_id"`
+		Count   int64 `xorm:"count"`
+	}
+	rows := make([]ruleCount, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT alert_id, COUNT(*) AS count FROM annotation WHERE org_id = ? AND "+alertAnnotationsCondition+" GROUP BY alert_id HAVING COUNT(*) > ?", orgID, limit).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		result[row.AlertID] = row.Count
+	}
+	return result, nil
+}
+
+func (s *AnnotationRetentionStore) DeleteOldestForRule(ctx context.Context, orgID, ruleID, keep int64, limit int) (int64, error) {
+	sql := fmt.Sprintf("SELECT id FROM annotation WHERE org_id = ? AND alert_id = ? ORDER BY epoch DESC, id DESC %s", s.db.GetDialect().LimitOffset(int64(limit), keep))
+	ids, err := s.fetchIDs(ctx, sql, orgID, ruleID)
+	if err != nil {
+		return 0, err
+	}
+	return s.deleteByIDs(ctx, ids)
+}
+
+func (s *AnnotationRetentionStore) GetDeletedRuleTransitionCounts(ctx context.Context, orgID int64, before time.Time) (map[int64]int64, error) {
+	type ruleCount struct {
+		AlertID int64 `xorm:"alert_id"`
+		Count   int64 `xorm:"count"`
+	}
+	rows := make([]ruleCount, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT alert_id, COUNT(*) AS count FROM annotation WHERE org_id = ? AND "+alertAnnotationsCondition+
+			" AND NOT EXISTS (SELECT 1 FROM alert_rule WHERE alert_rule.id = annotation.alert_id)"+
+			" GROUP BY alert_id HAVING MAX(epoch) < ?", orgID, before.UnixMilli()).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		result[row.AlertID] = row.Count
+	}
+	return result, nil
+}
+
+func (s *AnnotationRetentionStore) GetRuleUID(ctx context.Context, orgID, ruleID int64) (string, error) {
+	var data string
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := fmt.Sprintf("SELECT data FROM annotation WHERE org_id = ? AND alert_id = ? ORDER BY epoch DESC, id DESC %s", s.db.GetDialect().Limit(1))
+		_, err := sess.SQL(sql, orgID, ruleID).Get(&data)
+		return err
+	})
+	if err != nil || data == "" {
+		return "", err
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+		// the UID is only informative, the history of the rule can be purged without it
+		return "", nil
+	}
+	uid, _ := parsed[annotationDataRuleUID].(string)
+	return uid, nil
+}
+
+func (s *AnnotationRetentionStore) fetchIDs(ctx context.Context, sql string, args ...any) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(sql, args...).Find(&ids)
+	})
+	return ids, err
+}
+
+func (s *AnnotationRetentionStore) deleteByIDs(ctx context.Context, ids []int64) (int64, error) {
+	// SQLite has a parameter limit of 999.
+	const maxParameters = 999
+	var total int64
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), maxParameters)]
+		ids = ids[len(chunk):]
+
+		args := make([]any, 0, len(chunk)+1)
+		args = append(args, "DELETE FROM annotation WHERE id IN (?"+strings.Repeat(",?", len(chunk)-1)+")")
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			res, err := sess.Exec(args...)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			total += affected
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
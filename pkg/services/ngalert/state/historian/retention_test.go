@@ -0,0 +1,316 @@
+package historian
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestAnnotationRetention_Compact(t *testing.T) {
+	now := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+	cfg := setting.UnifiedAlertingStateHistoryRetentionSettings{
+		Enabled:   true,
+		Interval:  time.Hour,
+		BatchSize: 2,
+		Default: setting.StateHistoryRetention{
+			MaxAge: 10 * 24 * time.Hour,
+		},
+		OrgOverrides: map[int64]setting.StateHistoryRetention{
+			2: {MaxTransitionsPerRule: 2},
+			3: {},
+		},
+	}
+
+	newStore := func() *fakeRetentionStore {
+		s := &fakeRetentionStore{}
+		for i := 0; i < 5; i++ {
+			s.add(1, 1, now.Add(-time.Duration(i)*5*24*time.Hour))
+			s.add(2, 1, now.Add(-time.Duration(i)*time.Hour))
+			s.add(2, 2, now.Add(-time.Duration(i)*time.Hour))
+			s.add(3, 1, now.Add(-time.Duration(i)*30*24*time.Hour))
+		}
+		return s
+	}
+
+	newRetention := func(store RetentionStore) (*AnnotationRetention, *metrics.Historian) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		r := NewAnnotationRetention(store, cfg, met, log.NewNopLogger())
+		mock := clock.NewMock()
+		mock.Set(now)
+		r.clock = mock
+		return r, met
+	}
+
+	t.Run("should delete transitions older than max age", func(t *testing.T) {
+		store := newStore()
+		r, met := newRetention(store)
+
+		res, err := r.Compact(context.Background(), 1, false)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), res.ExpiredTransitions)
+		require.Equal(t, 3, store.count(1))
+		require.Equal(t, float64(2), testutil.ToFloat64(met.RetentionDeleted.WithLabelValues("1", retentionReasonMaxAge)))
+	})
+
+	t.Run("should keep newest transitions per rule", func(t *testing.T) {
+		store := newStore()
+		r, met := newRetention(store)
+
+		res, err := r.Compact(context.Background(), 2, false)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), res.ExcessTransitions)
+		require.Equal(t, 2, res.RulesCompacted)
+		require.Equal(t, 4, store.count(2))
+		for _, a := range store.rows {
+			if a.orgID == 2 {
+				require.True(t, a.epoch.After(now.Add(-2*time.Hour)))
+			}
+		}
+		require.Equal(t, float64(6), testutil.ToFloat64(met.RetentionDeleted.WithLabelValues("2", retentionReasonMaxTransitions)))
+	})
+
+	t.Run("should not delete anything in dry run", func(t *testing.T) {
+		store := newStore()
+		r, _ := newRetention(store)
+
+		res, err := r.Compact(context.Background(), 1, true)
+		require.NoError(t, err)
+		require.True(t, res.DryRun)
+		require.Equal(t, int64(2), res.ExpiredTransitions)
+
+		res, err = r.Compact(context.Background(), 2, true)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), res.ExcessTransitions)
+		require.Len(t, store.rows, 20)
+	})
+
+	t.Run("should keep everything if retention is not configured for org", func(t *testing.T) {
+		store := newStore()
+		r, _ := newRetention(store)
+
+		res, err := r.Compact(context.Background(), 3, false)
+		require.NoError(t, err)
+		require.Zero(t, res.ExpiredTransitions)
+		require.Equal(t, 5, store.count(3))
+	})
+}
+
+func TestAnnotationRetention_PurgeDeletedRules(t *testing.T) {
+	now := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+	cfg := setting.UnifiedAlertingStateHistoryRetentionSettings{
+		Enabled:   true,
+		Interval:  time.Hour,
+		BatchSize: 2,
+		Default: setting.StateHistoryRetention{
+			DeletedRulesGracePeriod: 24 * time.Hour,
+		},
+		OrgOverrides: map[int64]setting.StateHistoryRetention{
+			2: {},
+		},
+	}
+
+	newStore := func() *fakeRetentionStore {
+		s := &fakeRetentionStore{
+			deleted: map[int64]bool{2: true, 3: true, 5: true},
+			uids:    map[int64]string{2: "rule-2"},
+		}
+		for i := 0; i < 3; i++ {
+			// rule 1 exists, rule 2 and 3 were deleted more than a day ago
+			s.add(1, 1, now.Add(-time.Duration(i+2)*24*time.Hour))
+			s.add(1, 2, now.Add(-time.Duration(i+2)*24*time.Hour))
+			s.add(1, 3, now.Add(-time.Duration(i+2)*24*time.Hour))
+			// rule 4 was deleted less than a day ago
+			s.add(1, 4, now.Add(-time.Duration(i)*time.Hour))
+			s.add(2, 5, now.Add(-time.Duration(i+2)*24*time.Hour))
+		}
+		s.deleted[4] = true
+		return s
+	}
+
+	newRetention := func(store RetentionStore) (*AnnotationRetention, *metrics.Historian) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		r := NewAnnotationRetention(store, cfg, met, log.NewNopLogger())
+		mock := clock.NewMock()
+		mock.Set(now)
+		r.clock = mock
+		return r, met
+	}
+
+	t.Run("should delete the history of rules deleted before the grace period", func(t *testing.T) {
+		store := newStore()
+		r, met := newRetention(store)
+
+		res, err := r.PurgeDeletedRules(context.Background(), 1, false)
+		require.NoError(t, err)
+		require.Equal(t, []DeletedRuleHistory{
+			{RuleID: 2, RuleUID: "rule-2", Transitions: 3},
+			{RuleID: 3, Transitions: 3},
+		}, res.Rules)
+		require.Equal(t, int64(6), res.Transitions)
+		require.Equal(t, 6, store.count(1))
+		for _, a := range store.rows {
+			require.NotContains(t, []int64{2, 3}, a.ruleID)
+		}
+		require.Equal(t, float64(6), testutil.ToFloat64(met.RetentionDeleted.WithLabelValues("1", retentionReasonDeletedRule)))
+	})
+
+	t.Run("should not delete anything in dry run", func(t *testing.T) {
+		store := newStore()
+		r, _ := newRetention(store)
+
+		res, err := r.PurgeDeletedRules(context.Background(), 1, true)
+		require.NoError(t, err)
+		require.True(t, res.DryRun)
+		require.Len(t, res.Rules, 2)
+		require.Equal(t, int64(6), res.Transitions)
+		require.Len(t, store.rows, 15)
+	})
+
+	t.Run("should keep everything if the grace period is not configured for org", func(t *testing.T) {
+		store := newStore()
+		r, _ := newRetention(store)
+
+		res, err := r.PurgeDeletedRules(context.Background(), 2, false)
+		require.NoError(t, err)
+		require.Empty(t, res.Rules)
+		require.Equal(t, 3, store.count(2))
+	})
+}
+
+type fakeRetentionAnnotation struct {
+	id     int64
+	orgID  int64
+	ruleID int64
+	epoch  time.Time
+}
+
+type fakeRetentionStore struct {
+	rows    []fakeRetentionAnnotation
+	deleted map[int64]bool
+	uids    map[int64]string
+}
+
+func (s *fakeRetentionStore) add(orgID, ruleID int64, epoch time.Time) {
+	s.rows = append(s.rows, fakeRetentionAnnotation{id: int64(len(s.rows) + 1), orgID: orgID, ruleID: ruleID, epoch: epoch})
+}
+
+func (s *fakeRetentionStore) count(orgID int64) int {
+	c := 0
+	for _, a := range s.rows {
+		if a.orgID == orgID {
+			c++
+		}
+	}
+	return c
+}
+
+func (s *fakeRetentionStore) deleteWhere(limit int, match func(a fakeRetentionAnnotation) bool) int64 {
+	var deleted int64
+	kept := s.rows[:0]
+	for _, a := range s.rows {
+		if deleted < int64(limit) && match(a) {
+			deleted++
+			continue
+		}
+		kept = append(kept, a)
+	}
+	s.rows = kept
+	return deleted
+}
+
+func (s *fakeRetentionStore) GetOrgIDs(_ context.Context) ([]int64, error) {
+	seen := map[int64]struct{}{}
+	var ids []int64
+	for _, a := range s.rows {
+		if _, ok := seen[a.orgID]; !ok {
+			seen[a.orgID] = struct{}{}
+			ids = append(ids, a.orgID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *fakeRetentionStore) CountOlderThan(_ context.Context, orgID int64, before time.Time) (int64, error) {
+	var c int64
+	for _, a := range s.rows {
+		if a.orgID == orgID && a.epoch.Before(before) {
+			c++
+		}
+	}
+	return c, nil
+}
+
+func (s *fakeRetentionStore) DeleteOlderThan(_ context.Context, orgID int64, before time.Time, limit int) (int64, error) {
+	return s.deleteWhere(limit, func(a fakeRetentionAnnotation) bool {
+		return a.orgID == orgID && a.epoch.Before(before)
+	}), nil
+}
+
+func (s *fakeRetentionStore) GetRuleTransitionCounts(_ context.Context, orgID int64, limit int64) (map[int64]int64, error) {
+	counts := map[int64]int64{}
+	for _, a := range s.rows {
+		if a.orgID == orgID {
+			counts[a.ruleID]++
+		}
+	}
+	for ruleID, c := range counts {
+		if c <= limit {
+			delete(counts, ruleID)
+		}
+	}
+	return counts, nil
+}
+
+func (s *fakeRetentionStore) DeleteOldestForRule(_ context.Context, orgID, ruleID, keep int64, limit int) (int64, error) {
+	var rule []fakeRetentionAnnotation
+	for _, a := range s.rows {
+		if a.orgID == orgID && a.ruleID == ruleID {
+			rule = append(rule, a)
+		}
+	}
+	if int64(len(rule)) <= keep {
+		return 0, nil
+	}
+	sort.Slice(rule, func(i, j int) bool { return rule[i].epoch.After(rule[j].epoch) })
+	toDelete := map[int64]struct{}{}
+	for _, a := range rule[keep:] {
+		toDelete[a.id] = struct{}{}
+	}
+	return s.deleteWhere(limit, func(a fakeRetentionAnnotation) bool {
+		_, ok := toDelete[a.id]
+		return ok
+	}), nil
+}
+
+func (s *fakeRetentionStore) GetDeletedRuleTransitionCounts(_ context.Context, orgID int64, before time.Time) (map[int64]int64, error) {
+	counts := map[int64]int64{}
+	last := map[int64]time.Time{}
+	for _, a := range s.rows {
+		if a.orgID == orgID && s.deleted[a.ruleID] {
+			counts[a.ruleID]++
+			if a.epoch.After(last[a.ruleID]) {
+				last[a.ruleID] = a.epoch
+			}
+		}
+	}
+	for ruleID := range counts {
+		if !last[ruleID].Before(before) {
+			delete(counts, ruleID)
+		}
+	}
+	return counts, nil
+}
+
+func (s *fakeRetentionStore) GetRuleUID(_ context.Context, _ int64, ruleID int64) (string, error) {
+	return s.uids[ruleID], nil
+}
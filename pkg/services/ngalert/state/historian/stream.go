@@ -0,0 +1,178 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+// DefaultStreamBufferSize is the number of state transitions buffered for each subscriber of the stream.
+const DefaultStreamBufferSize = 256
+
+var errStreamQueryNotSupported = errors.New("state history stream does not support queries")
+
+// StreamEvent is a single state transition emitted by the state history stream.
+type StreamEvent struct {
+	OrgID         int64
+	RuleUID       string
+	RuleTitle     string
+	RuleGroup     string
+	NamespaceUID  string
+	Labels        data.Labels
+	PreviousState string
+	CurrentState  string
+	Values        map[string]float64
+	Error         string
+	Timestamp     time.Time
+	DashboardUID  string
+	PanelID       int64
+	Fingerprint   string
+}
+
+// GetNamespaceUID implements models.Namespaced.
+func (e StreamEvent) GetNamespaceUID() string {
+	return e.NamespaceUID
+}
+
+// StateTransitionEvent returns the event in the format of the state history API.
+func (e StreamEvent) StateTransitionEvent() apimodels.StateTransitionEvent {
+	return apimodels.StateTransitionEvent{
+		RuleUID:      e.RuleUID,
+		RuleTitle:    e.RuleTitle,
+		RuleGroup:    e.RuleGroup,
+		FolderUID:    e.NamespaceUID,
+		Labels:       e.Labels,
+		Fingerprint:  e.Fingerprint,
+		Previous:     e.PreviousState,
+		Current:      e.CurrentState,
+		Values:       e.Values,
+		Error:        e.Error,
+		DashboardUID: e.DashboardUID,
+		PanelID:      e.PanelID,
+		Timestamp:    e.Timestamp,
+	}
+}
+
+// StreamFilter decides whether a state transition is delivered to a subscriber.
+type StreamFilter func(StreamEvent) bool
+
+type streamSubscriber struct {
+	orgID  int64
+	filter StreamFilter
+	events chan StreamEvent
+}
+
+// StreamBackend is a state history backend that broadcasts state transitions to subscribers in real time.
+// It does not store anything, so it is meant to be used as a secondary of MultipleBackend.
+// Delivery is best effort: transitions are dropped for subscribers that do not keep up.
+type StreamBackend struct {
+	mtx         sync.RWMutex
+	subscribers map[*streamSubscriber]struct{}
+	bufferSize  int
+	log         log.Logger
+}
+
+func NewStreamBackend(bufferSize int, logger log.Logger) *StreamBackend {
+	if bufferSize <= 0 {
+		bufferSize = DefaultStreamBufferSize
+	}
+	return &StreamBackend{
+		subscribers: make(map[*streamSubscriber]struct{}),
+		bufferSize:  bufferSize,
+		log:         logger,
+	}
+}
+
+// Subscribe registers a subscriber for the state transitions of the organization that pass the filter.
+// The returned function must be called to unsubscribe, after which the channel is closed.
+func (s *StreamBackend) Subscribe(orgID int64, filter StreamFilter) (<-chan StreamEvent, func()) {
+	sub := &streamSubscriber{
+		orgID:  orgID,
+		filter: filter,
+		events: make(chan StreamEvent, s.bufferSize),
+	}
+	s.mtx.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mtx.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			s.mtx.Lock()
+			delete(s.subscribers, sub)
+			s.mtx.Unlock()
+			close(sub.events)
+		})
+	}
+}
+
+// Record implements state.Historian. It never blocks on subscribers.
+func (s *StreamBackend) Record(_ context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	errCh := make(chan error)
+	close(errCh)
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if len(s.subscribers) == 0 {
+		return errCh
+	}
+
+	for _, t := range states {
+		if !shouldRecord(t) {
+			continue
+		}
+		event := newStreamEvent(rule, t)
+		for sub := range s.subscribers {
+			if sub.orgID != event.OrgID || (sub.filter != nil && !sub.filter(event)) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				s.log.Debug("Dropping state transition for slow subscriber", "org", event.OrgID, "rule_uid", event.RuleUID)
+			}
+		}
+	}
+	return errCh
+}
+
+// Query implements Backend. The stream keeps no history, so it cannot be used as a primary.
+func (s *StreamBackend) Query(_ context.Context, _ models.HistoryQuery) (*data.Frame, error) {
+	return nil, errStreamQueryNotSupported
+}
+
+func newStreamEvent(rule history_model.RuleMeta, t state.StateTransition) StreamEvent {
+	event := StreamEvent{
+		OrgID:         rule.OrgID,
+		RuleUID:       rule.UID,
+		RuleTitle:     rule.Title,
+		RuleGroup:     rule.Group,
+		NamespaceUID:  rule.NamespaceUID,
+		Labels:        removePrivateLabels(t.Labels),
+		PreviousState: state.FormatStateAndReason(t.PreviousState, t.PreviousStateReason),
+		CurrentState:  t.Formatted(),
+		Timestamp:     t.LastEvaluationTime,
+		DashboardUID:  rule.DashboardUID,
+		PanelID:       rule.PanelID,
+		Fingerprint:   labelFingerprint(t.Labels),
+	}
+	if len(t.Values) > 0 {
+		event.Values = make(map[string]float64, len(t.Values))
+		for k, v := range t.Values {
+			event.Values[k] = v
+		}
+	}
+	if t.Error != nil {
+		event.Error = t.Error.Error()
+	}
+	return event
+}
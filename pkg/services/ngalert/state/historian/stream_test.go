@@ -0,0 +1,87 @@
+package historian
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+func TestStreamBackend(t *testing.T) {
+	rule := history_model.RuleMeta{OrgID: 1, UID: "rule", Title: "Rule", Group: "group", NamespaceUID: "folder"}
+	transition := func(labels data.Labels, from, to eval.State) state.StateTransition {
+		return state.StateTransition{
+			State:         &state.State{State: to, Labels: labels},
+			PreviousState: from,
+		}
+	}
+
+	t.Run("broadcasts transitions to subscribers of the organization", func(t *testing.T) {
+		s := NewStreamBackend(10, log.NewNopLogger())
+		events, unsubscribe := s.Subscribe(1, nil)
+		defer unsubscribe()
+		other, unsubscribeOther := s.Subscribe(2, nil)
+		defer unsubscribeOther()
+
+		err := <-s.Record(context.Background(), rule, []state.StateTransition{
+			transition(data.Labels{"team": "ops", "__private__": "x"}, eval.Normal, eval.Alerting),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, events, 1)
+		e := <-events
+		require.Equal(t, "rule", e.RuleUID)
+		require.Equal(t, "folder", e.GetNamespaceUID())
+		require.Equal(t, "Normal", e.PreviousState)
+		require.Equal(t, "Alerting", e.CurrentState)
+		require.Equal(t, data.Labels{"team": "ops"}, e.Labels)
+		require.Empty(t, other)
+	})
+
+	t.Run("skips unchanged states and filtered transitions", func(t *testing.T) {
+		s := NewStreamBackend(10, log.NewNopLogger())
+		events, unsubscribe := s.Subscribe(1, func(e StreamEvent) bool {
+			return e.Labels["team"] == "ops"
+		})
+		defer unsubscribe()
+
+		<-s.Record(context.Background(), rule, []state.StateTransition{
+			transition(data.Labels{"team": "ops"}, eval.Alerting, eval.Alerting),
+			transition(data.Labels{"team": "dev"}, eval.Normal, eval.Alerting),
+			transition(data.Labels{"team": "ops"}, eval.Alerting, eval.Normal),
+		})
+
+		require.Len(t, events, 1)
+		require.Equal(t, "Normal", (<-events).CurrentState)
+	})
+
+	t.Run("drops transitions for slow subscribers", func(t *testing.T) {
+		s := NewStreamBackend(1, log.NewNopLogger())
+		events, unsubscribe := s.Subscribe(1, nil)
+		defer unsubscribe()
+
+		<-s.Record(context.Background(), rule, []state.StateTransition{
+			transition(data.Labels{"a": "1"}, eval.Normal, eval.Alerting),
+			transition(data.Labels{"a": "2"}, eval.Normal, eval.Alerting),
+		})
+
+		require.Len(t, events, 1)
+	})
+
+	t.Run("closes channel on unsubscribe", func(t *testing.T) {
+		s := NewStreamBackend(1, log.NewNopLogger())
+		events, unsubscribe := s.Subscribe(1, nil)
+		unsubscribe()
+		unsubscribe()
+
+		_, ok := <-events
+		require.False(t, ok)
+		<-s.Record(context.Background(), rule, []state.StateTransition{transition(nil, eval.Normal, eval.Alerting)})
+	})
+}
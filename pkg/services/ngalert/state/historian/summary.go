@@ -0,0 +1,253 @@
+package historian
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	// SummaryGroupByRule groups the summary of state transitions by alert rule UID.
+	SummaryGroupByRule = "rule"
+	// MaxSummaryBuckets is the maximum number of intervals a summary can be split into.
+	MaxSummaryBuckets = 1000
+
+	// dfAnnotationNext is the field with the new state in frames returned by the annotation backend
+	dfAnnotationNext = "next"
+	dfAnnotationPrev = "prev"
+	dfAnnotationText = "text"
+)
+
+var ErrInvalidSummaryQuery = errors.New("invalid state history summary query")
+
+// SummaryQuery describes how state transitions are aggregated.
+type SummaryQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+	// GroupBy is empty, SummaryGroupByRule or the name of an instance label.
+	GroupBy string
+}
+
+// Validate checks the time range and the number of buckets of the query.
+func (q SummaryQuery) Validate() error {
+	if !q.To.After(q.From) {
+		return fmt.Errorf("%w: the end of the time range must be after the start", ErrInvalidSummaryQuery)
+	}
+	if q.Interval <= 0 {
+		return fmt.Errorf("%w: the interval must be positive", ErrInvalidSummaryQuery)
+	}
+	if q.buckets() > MaxSummaryBuckets {
+		return fmt.Errorf("%w: the time range can be split into at most %d intervals", ErrInvalidSummaryQuery, MaxSummaryBuckets)
+	}
+	return nil
+}
+
+func (q SummaryQuery) buckets() int64 {
+	d := q.To.Sub(q.From)
+	n := int64(d / q.Interval)
+	if d%q.Interval != 0 {
+		n++
+	}
+	return n
+}
+
+// Summary is the number of transitions into every state, per interval.
+type Summary struct {
+	Groups []SummaryGroup
+}
+
+// SummaryGroup is the summary of the transitions of a single rule or label value.
+type SummaryGroup struct {
+	// Key is the rule UID or label value of the group. It is empty when the summary is not grouped,
+	// or for the transitions that do not have the label.
+	Key     string
+	Buckets []SummaryBucket
+	Total   map[string]int64
+}
+
+// SummaryBucket counts the transitions into every state that happened in [Time, Time+Interval).
+type SummaryBucket struct {
+	Time   time.Time
+	Counts map[string]int64
+}
+
+type summaryTransition struct {
+	time time.Time
+	// state and previous are formatted states, with their reason
+	state    string
+	previous string
+	ruleUID  string
+	labels   map[string]string
+	// instance identifies the alert instance of the transition within its rule
+	instance string
+}
+
+// Summarize aggregates a state history frame as returned by any of the history backends.
+// Transitions outside of the time range of the query are ignored.
+func Summarize(frame *data.Frame, q SummaryQuery) (*Summary, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	transitions, err := readTransitions(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := q.buckets()
+	groups := map[string]*SummaryGroup{}
+	for _, t := range transitions {
+		if t.time.Before(q.From) || !t.time.Before(q.To) {
+			continue
+		}
+		key := ""
+		switch q.GroupBy {
+		case "":
+		case SummaryGroupByRule:
+			key = t.ruleUID
+		default:
+			key = t.labels[q.GroupBy]
+		}
+
+		g, ok := groups[key]
+		if !ok {
+			g = newSummaryGroup(key, q, buckets)
+			groups[key] = g
+		}
+		idx := int64(t.time.Sub(q.From) / q.Interval)
+		s := summaryState(t.state)
+		g.Buckets[idx].Counts[s]++
+		g.Total[s]++
+	}
+
+	res := &Summary{Groups: make([]SummaryGroup, 0, len(groups))}
+	for _, g := range groups {
+		res.Groups = append(res.Groups, *g)
+	}
+	sort.Slice(res.Groups, func(i, j int) bool {
+		return res.Groups[i].Key < res.Groups[j].Key
+	})
+	return res, nil
+}
+
+func newSummaryGroup(key string, q SummaryQuery, buckets int64) *SummaryGroup {
+	g := &SummaryGroup{
+		Key:     key,
+		Buckets: make([]SummaryBucket, 0, buckets),
+		Total:   map[string]int64{},
+	}
+	for i := int64(0); i < buckets; i++ {
+		g.Buckets = append(g.Buckets, SummaryBucket{
+			Time:   q.From.Add(time.Duration(i) * q.Interval),
+			Counts: map[string]int64{},
+		})
+	}
+	return g
+}
+
+// readTransitions reads the transitions of a frame built by the Loki backend (time, line, labels)
+// or by the annotation backend (time, text, prev, next, data).
+func readTransitions(frame *data.Frame) ([]summaryTransition, error) {
+	if frame == nil || len(frame.Fields) == 0 {
+		return nil, nil
+	}
+
+	timeField, _ := frame.FieldByName(dfTime)
+	if timeField == nil {
+		return nil, fmt.Errorf("state history frame has no %s field", dfTime)
+	}
+
+	if lineField, _ := frame.FieldByName(dfLine); lineField != nil {
+		return readLokiTransitions(timeField, lineField)
+	}
+	if nextField, _ := frame.FieldByName(dfAnnotationNext); nextField != nil {
+		prevField, _ := frame.FieldByName(dfAnnotationPrev)
+		textField, _ := frame.FieldByName(dfAnnotationText)
+		return readAnnotationTransitions(timeField, nextField, prevField, textField)
+	}
+	return nil, errors.New("unknown state history frame format")
+}
+
+func readLokiTransitions(timeField, lineField *data.Field) ([]summaryTransition, error) {
+	out := make([]summaryTransition, 0, timeField.Len())
+	for i := 0; i < timeField.Len(); i++ {
+		ts, ok := timeField.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of %s field", dfTime)
+		}
+		line, ok := lineField.At(i).(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of %s field", dfLine)
+		}
+		var entry LokiEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		out = append(out, summaryTransition{
+			time:     ts,
+			state:    entry.Current,
+			previous: entry.Previous,
+			ruleUID:  entry.RuleUID,
+			labels:   entry.InstanceLabels,
+			instance: entry.Fingerprint,
+		})
+	}
+	return out, nil
+}
+
+// readAnnotationTransitions reads a frame of the annotation backend. It only holds the history of a single rule,
+// and the instance labels are not stored in a structured way so grouping by label is not supported.
+// The instances are told apart by the rule title and labels the text of the annotations starts with.
+func readAnnotationTransitions(timeField, nextField, prevField, textField *data.Field) ([]summaryTransition, error) {
+	ruleUID := timeField.Labels["ruleUID"]
+	out := make([]summaryTransition, 0, timeField.Len())
+	for i := 0; i < timeField.Len(); i++ {
+		ts, ok := timeField.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of %s field", dfTime)
+		}
+		next, ok := nextField.At(i).(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of %s field", dfAnnotationNext)
+		}
+		t := summaryTransition{
+			time:    ts,
+			state:   next,
+			ruleUID: ruleUID,
+		}
+		if prevField != nil {
+			t.previous, _ = prevField.At(i).(string)
+		}
+		if textField != nil {
+			text, _ := textField.At(i).(string)
+			t.instance = annotationInstance(text)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// annotationInstance drops the values from the text of an annotation built by BuildAnnotationTextAndData
+func annotationInstance(text string) string {
+	if i := strings.Index(text, "} - "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// summaryState drops the reason of a formatted state, e.g. "Normal (NoData)" is counted as Normal.
+func summaryState(formatted string) string {
+	s, _, err := state.ParseFormattedState(formatted)
+	if err != nil {
+		return formatted
+	}
+	return s.String()
+}
@@ -0,0 +1,102 @@
+package historian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lokiFrame := func(entries map[time.Time]LokiEntry) *data.Frame {
+		times := make([]time.Time, 0, len(entries))
+		lines := make([]json.RawMessage, 0, len(entries))
+		for ts, entry := range entries {
+			line, err := json.Marshal(entry)
+			require.NoError(t, err)
+			times = append(times, ts)
+			lines = append(lines, line)
+		}
+		return data.NewFrame("states",
+			data.NewField(dfTime, nil, times),
+			data.NewField(dfLine, nil, lines),
+		)
+	}
+
+	frame := lokiFrame(map[time.Time]LokiEntry{
+		from:                       {Current: "Alerting", RuleUID: "a", InstanceLabels: map[string]string{"team": "ops"}},
+		from.Add(10 * time.Minute): {Current: "Normal (NoData)", RuleUID: "a", InstanceLabels: map[string]string{"team": "ops"}},
+		from.Add(70 * time.Minute): {Current: "Alerting", RuleUID: "b", InstanceLabels: map[string]string{"team": "dev"}},
+		from.Add(90 * time.Minute): {Current: "Pending", RuleUID: "b"},
+		// outside of the time range
+		from.Add(2 * time.Hour): {Current: "Alerting", RuleUID: "a"},
+	})
+	q := SummaryQuery{From: from, To: from.Add(2 * time.Hour), Interval: time.Hour}
+
+	t.Run("counts transitions per state and interval", func(t *testing.T) {
+		res, err := Summarize(frame, q)
+		require.NoError(t, err)
+		require.Len(t, res.Groups, 1)
+
+		g := res.Groups[0]
+		require.Equal(t, "", g.Key)
+		require.Equal(t, map[string]int64{"Alerting": 2, "Normal": 1, "Pending": 1}, g.Total)
+		require.Equal(t, []SummaryBucket{
+			{Time: from, Counts: map[string]int64{"Alerting": 1, "Normal": 1}},
+			{Time: from.Add(time.Hour), Counts: map[string]int64{"Alerting": 1, "Pending": 1}},
+		}, g.Buckets)
+	})
+
+	t.Run("groups by rule", func(t *testing.T) {
+		q := q
+		q.GroupBy = SummaryGroupByRule
+		res, err := Summarize(frame, q)
+		require.NoError(t, err)
+		require.Len(t, res.Groups, 2)
+		require.Equal(t, "a", res.Groups[0].Key)
+		require.Equal(t, map[string]int64{"Alerting": 1, "Normal": 1}, res.Groups[0].Total)
+		require.Equal(t, "b", res.Groups[1].Key)
+		require.Equal(t, map[string]int64{"Alerting": 1, "Pending": 1}, res.Groups[1].Total)
+	})
+
+	t.Run("groups by label", func(t *testing.T) {
+		q := q
+		q.GroupBy = "team"
+		res, err := Summarize(frame, q)
+		require.NoError(t, err)
+		keys := make([]string, 0, len(res.Groups))
+		for _, g := range res.Groups {
+			keys = append(keys, g.Key)
+		}
+		require.Equal(t, []string{"", "dev", "ops"}, keys, "transitions without the label are grouped under an empty key")
+	})
+
+	t.Run("reads annotation frames", func(t *testing.T) {
+		lbls := data.Labels{"from": "state-history", "ruleUID": "a"}
+		frame := data.NewFrame("states",
+			data.NewField("time", lbls, []time.Time{from, from.Add(time.Minute)}),
+			data.NewField("text", lbls, []string{"", ""}),
+			data.NewField("prev", lbls, []string{"Normal", "Alerting"}),
+			data.NewField("next", lbls, []string{"Alerting", "Normal"}),
+			data.NewField("data", lbls, []string{"{}", "{}"}),
+		)
+		q := q
+		q.GroupBy = SummaryGroupByRule
+		res, err := Summarize(frame, q)
+		require.NoError(t, err)
+		require.Len(t, res.Groups, 1)
+		require.Equal(t, "a", res.Groups[0].Key)
+		require.Equal(t, map[string]int64{"Alerting": 1, "Normal": 1}, res.Groups[0].Total)
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		_, err := Summarize(frame, SummaryQuery{From: from, To: from, Interval: time.Hour})
+		require.ErrorIs(t, err, ErrInvalidSummaryQuery)
+
+		_, err = Summarize(frame, SummaryQuery{From: from, To: from.Add(time.Hour), Interval: time.Millisecond})
+		require.ErrorIs(t, err, ErrInvalidSummaryQuery)
+	})
+}
@@ -0,0 +1,149 @@
+package historian
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// TimelineRuleUIDLabel is the label of the field of a rule in a timeline with the UID of the rule.
+const TimelineRuleUIDLabel = "rule_uid"
+
+var ErrInvalidTimelineQuery = errors.New("invalid state history timeline query")
+
+// TimelineRule is a rule of a timeline, its field is named after its title.
+type TimelineRule struct {
+	UID   string
+	Title string
+}
+
+// timelineSeverity orders the states of the instances of a rule, the state of the rule is the most severe one.
+var timelineSeverity = map[eval.State]int{
+	eval.Normal:   1,
+	eval.NoData:   2,
+	eval.Error:    3,
+	eval.Pending:  4,
+	eval.Alerting: 5,
+}
+
+type timelineTransition struct {
+	summaryTransition
+	rule int
+}
+
+// Timeline merges the state histories of the rules, by rule UID, into a single frame aligned on the times of their
+// transitions, e.g. to show the rules of a dashboard panel in a state timeline. The frame has a time field and a
+// string field per rule, in the order of the rules, with the state of the rule from that time on. The first row is at
+// the start of the time range, then there is a row every time the state of a rule changes.
+// The state of a rule is the state of its most severe instance: Alerting, Pending, Error, NoData and then Normal. The
+// state of an instance at the start of the time range is the previous state of its first transition, and the state
+// of a rule is NULL while the history does not tell it, e.g. when it has no transitions.
+func Timeline(histories map[string]*data.Frame, rules []TimelineRule, from, to time.Time) (*data.Frame, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: the end of the time range must be after the start", ErrInvalidTimelineQuery)
+	}
+
+	var transitions []timelineTransition
+	// instances are the states of the instances of every rule
+	instances := make([]map[string]eval.State, len(rules))
+	for i, rule := range rules {
+		instances[i] = map[string]eval.State{}
+		ruleTransitions, err := readTransitions(histories[rule.UID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the state history of rule %s: %w", rule.UID, err)
+		}
+		sort.SliceStable(ruleTransitions, func(a, b int) bool {
+			return ruleTransitions[a].time.Before(ruleTransitions[b].time)
+		})
+		for _, t := range ruleTransitions {
+			if !t.time.Before(to) {
+				continue
+			}
+			if _, ok := instances[i][t.instance]; !ok {
+				if s, _, err := state.ParseFormattedState(t.previous); err == nil {
+					instances[i][t.instance] = s
+				}
+			}
+			transitions = append(transitions, timelineTransition{summaryTransition: t, rule: i})
+		}
+	}
+	sort.SliceStable(transitions, func(a, b int) bool {
+		return transitions[a].time.Before(transitions[b].time)
+	})
+
+	times := []time.Time{}
+	values := make([][]*string, len(rules))
+	// appendRow adds a row with the current states of the rules, unless none of them changed since the last row
+	appendRow := func(at time.Time) {
+		changed := len(times) == 0
+		row := make([]*string, len(rules))
+		for i := range rules {
+			row[i] = ruleState(instances[i])
+			if !changed {
+				last := values[i][len(values[i])-1]
+				changed = (last == nil) != (row[i] == nil) || (last != nil && *last != *row[i])
+			}
+		}
+		if !changed {
+			return
+		}
+		times = append(times, at)
+		for i := range rules {
+			values[i] = append(values[i], row[i])
+		}
+	}
+
+	started := false
+	for i := 0; i < len(transitions); {
+		at := transitions[i].time
+		if !at.Before(from) && !started {
+			appendRow(from)
+			started = true
+		}
+		// the transitions at the same time make a single row
+		for ; i < len(transitions) && transitions[i].time.Equal(at); i++ {
+			t := transitions[i]
+			if s, _, err := state.ParseFormattedState(t.state); err == nil {
+				instances[t.rule][t.instance] = s
+			}
+		}
+		if started {
+			appendRow(at)
+		}
+	}
+	if !started {
+		appendRow(from)
+	}
+
+	frame := data.NewFrame("", data.NewField(dfTime, nil, times))
+	for i, rule := range rules {
+		name := rule.Title
+		if name == "" {
+			name = rule.UID
+		}
+		frame.Fields = append(frame.Fields, data.NewField(name, data.Labels{TimelineRuleUIDLabel: rule.UID}, values[i]))
+	}
+	return frame, nil
+}
+
+// ruleState returns the state of the most severe instance, NULL when the state of no instance is known
+func ruleState(instances map[string]eval.State) *string {
+	severity := 0
+	var res eval.State
+	for _, s := range instances {
+		if timelineSeverity[s] > severity {
+			severity, res = timelineSeverity[s], s
+		}
+	}
+	if severity == 0 {
+		return nil
+	}
+	name := res.String()
+	return &name
+}
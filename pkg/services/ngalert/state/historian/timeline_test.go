@@ -0,0 +1,87 @@
+package historian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type entry struct {
+		at    time.Duration
+		entry LokiEntry
+	}
+	lokiFrame := func(entries ...entry) *data.Frame {
+		times := make([]time.Time, 0, len(entries))
+		lines := make([]json.RawMessage, 0, len(entries))
+		for _, e := range entries {
+			line, err := json.Marshal(e.entry)
+			require.NoError(t, err)
+			times = append(times, from.Add(e.at))
+			lines = append(lines, line)
+		}
+		return data.NewFrame("states",
+			data.NewField(dfTime, nil, times),
+			data.NewField(dfLine, nil, lines),
+		)
+	}
+	str := func(s string) *string { return &s }
+	rules := []TimelineRule{{UID: "a", Title: "CPU"}, {UID: "b", Title: "Memory"}, {UID: "c"}}
+	histories := map[string]*data.Frame{
+		"a": lokiFrame(
+			entry{-time.Minute, LokiEntry{Previous: "Normal", Current: "Pending", Fingerprint: "h1"}},
+			entry{5 * time.Minute, LokiEntry{Previous: "Pending", Current: "Alerting", Fingerprint: "h1"}},
+			entry{5 * time.Minute, LokiEntry{Previous: "Normal", Current: "Normal (NoData)", Fingerprint: "h2"}},
+			entry{20 * time.Minute, LokiEntry{Previous: "Alerting", Current: "Normal", Fingerprint: "h1"}},
+			// outside of the time range
+			entry{time.Hour, LokiEntry{Previous: "Normal", Current: "Alerting", Fingerprint: "h1"}},
+		),
+		"b": lokiFrame(
+			entry{10 * time.Minute, LokiEntry{Previous: "Normal", Current: "Error"}},
+			entry{20 * time.Minute, LokiEntry{Previous: "Error", Current: "Normal"}},
+		),
+	}
+
+	t.Run("aligns the states of the rules on their transitions", func(t *testing.T) {
+		frame, err := Timeline(histories, rules, from, from.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 4)
+
+		times := frame.Fields[0]
+		require.Equal(t, 4, times.Len())
+		require.Equal(t, []time.Time{from, from.Add(5 * time.Minute), from.Add(10 * time.Minute), from.Add(20 * time.Minute)},
+			[]time.Time{times.At(0).(time.Time), times.At(1).(time.Time), times.At(2).(time.Time), times.At(3).(time.Time)})
+
+		cpu := frame.Fields[1]
+		require.Equal(t, "CPU", cpu.Name)
+		require.Equal(t, data.Labels{TimelineRuleUIDLabel: "a"}, cpu.Labels)
+		require.Equal(t, []*string{str("Pending"), str("Alerting"), str("Alerting"), str("Normal")},
+			[]*string{cpu.At(0).(*string), cpu.At(1).(*string), cpu.At(2).(*string), cpu.At(3).(*string)})
+
+		memory := frame.Fields[2]
+		require.Equal(t, []*string{str("Normal"), str("Normal"), str("Error"), str("Normal")},
+			[]*string{memory.At(0).(*string), memory.At(1).(*string), memory.At(2).(*string), memory.At(3).(*string)},
+			"the state before the first transition is its previous state")
+
+		none := frame.Fields[3]
+		require.Equal(t, "c", none.Name, "the field is named after the UID of a rule without a title")
+		require.Nil(t, none.At(0).(*string), "the state of a rule without history is unknown")
+	})
+
+	t.Run("a row at the start of the time range without transitions", func(t *testing.T) {
+		frame, err := Timeline(histories, rules, from.Add(30*time.Minute), from.Add(40*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, from.Add(30*time.Minute), frame.Fields[0].At(0))
+		require.Equal(t, "Normal", *frame.Fields[1].At(0).(*string))
+	})
+
+	t.Run("invalid time range", func(t *testing.T) {
+		_, err := Timeline(histories, rules, from, from)
+		require.ErrorIs(t, err, ErrInvalidTimelineQuery)
+	})
+}
@@ -0,0 +1,207 @@
+package historian
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// UptimeTreatment is how the time spent in the NoData or Error state is counted by Uptime.
+type UptimeTreatment string
+
+const (
+	// UptimeUp counts the time as up, like the Normal state.
+	UptimeUp UptimeTreatment = "up"
+	// UptimeDown counts the time as down, like the Alerting state.
+	UptimeDown UptimeTreatment = "down"
+	// UptimeIgnore leaves the time out of the uptime.
+	UptimeIgnore UptimeTreatment = "ignore"
+
+	// DefaultUptimeNoData and DefaultUptimeError are the treatments used when the query does not set them.
+	DefaultUptimeNoData = UptimeIgnore
+	DefaultUptimeError  = UptimeDown
+)
+
+var ErrInvalidUptimeQuery = errors.New("invalid state history uptime query")
+
+// UptimeQuery describes the time range of an uptime computation and how NoData and Error are counted.
+// The treatments apply to the NoData and Error states, and to the states with the NoData or Error reason,
+// e.g. "Normal (NoData)" when the rule maps no data to Normal.
+type UptimeQuery struct {
+	From   time.Time
+	To     time.Time
+	NoData UptimeTreatment
+	Error  UptimeTreatment
+}
+
+// Validate checks the time range and the treatments of the query.
+func (q UptimeQuery) Validate() error {
+	if !q.To.After(q.From) {
+		return fmt.Errorf("%w: the end of the time range must be after the start", ErrInvalidUptimeQuery)
+	}
+	for _, t := range []UptimeTreatment{q.NoData, q.Error} {
+		switch t {
+		case "", UptimeUp, UptimeDown, UptimeIgnore:
+		default:
+			return fmt.Errorf("%w: unknown treatment %q, must be one of %s, %s or %s", ErrInvalidUptimeQuery, t, UptimeUp, UptimeDown, UptimeIgnore)
+		}
+	}
+	return nil
+}
+
+// WithDefaults returns the query with the default treatments for the ones that are not set.
+func (q UptimeQuery) WithDefaults() UptimeQuery {
+	if q.NoData == "" {
+		q.NoData = DefaultUptimeNoData
+	}
+	if q.Error == "" {
+		q.Error = DefaultUptimeError
+	}
+	return q
+}
+
+// RuleUptime is the time an alert rule spent up and down over the time range.
+// A rule is down while any of its instances is down, and up while the others are up.
+type RuleUptime struct {
+	RuleUID string
+	Up      time.Duration
+	Down    time.Duration
+	// Ignored is the time all the instances of the rule were in a state that is not counted.
+	Ignored time.Duration
+}
+
+// Percent returns the percentage of the counted time the rule was up.
+// It returns false when none of the time range was counted.
+func (u RuleUptime) Percent() (float64, bool) {
+	return uptimePercent(u.Up, u.Down)
+}
+
+func uptimePercent(up, down time.Duration) (float64, bool) {
+	if up+down == 0 {
+		return 0, false
+	}
+	return float64(up) / float64(up+down) * 100, true
+}
+
+type uptimeCategory int
+
+const (
+	uptimeIgnored uptimeCategory = iota
+	uptimeUp
+	uptimeDown
+)
+
+// Uptime computes the time every rule of a state history frame spent up and down over the time range of the query.
+// The state of an instance at the start of the time range is the previous state of its first transition.
+// Rules without transitions are not returned, the history does not tell their state.
+func Uptime(frame *data.Frame, q UptimeQuery) ([]RuleUptime, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	q = q.WithDefaults()
+	transitions, err := readTransitions(frame)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].time.Before(transitions[j].time)
+	})
+
+	byRule := map[string][]summaryTransition{}
+	for _, t := range transitions {
+		if t.time.Before(q.To) {
+			byRule[t.ruleUID] = append(byRule[t.ruleUID], t)
+		}
+	}
+
+	res := make([]RuleUptime, 0, len(byRule))
+	for ruleUID, transitions := range byRule {
+		res = append(res, q.ruleUptime(ruleUID, transitions))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].RuleUID < res[j].RuleUID
+	})
+	return res, nil
+}
+
+// ruleUptime walks the transitions of a rule ordered by time.
+func (q UptimeQuery) ruleUptime(ruleUID string, transitions []summaryTransition) RuleUptime {
+	instances := map[string]uptimeCategory{}
+	for _, t := range transitions {
+		if _, ok := instances[t.instance]; !ok {
+			instances[t.instance] = q.category(t.previous)
+		}
+	}
+
+	res := RuleUptime{RuleUID: ruleUID}
+	add := func(d time.Duration) {
+		if d <= 0 {
+			return
+		}
+		switch ruleCategory(instances) {
+		case uptimeUp:
+			res.Up += d
+		case uptimeDown:
+			res.Down += d
+		default:
+			res.Ignored += d
+		}
+	}
+	cursor := q.From
+	for _, t := range transitions {
+		if t.time.After(cursor) {
+			add(t.time.Sub(cursor))
+			cursor = t.time
+		}
+		instances[t.instance] = q.category(t.state)
+	}
+	add(q.To.Sub(cursor))
+	return res
+}
+
+func ruleCategory(instances map[string]uptimeCategory) uptimeCategory {
+	res := uptimeIgnored
+	for _, c := range instances {
+		if c > res {
+			res = c
+		}
+	}
+	return res
+}
+
+// category returns how the time in a formatted state is counted.
+// Pending is counted as up, the rule does not fire yet.
+func (q UptimeQuery) category(formatted string) uptimeCategory {
+	s, reason, err := state.ParseFormattedState(formatted)
+	if err != nil {
+		return uptimeIgnored
+	}
+	switch {
+	case s == eval.NoData || reason == models.StateReasonNoData:
+		return treatmentCategory(q.NoData)
+	case s == eval.Error || reason == models.StateReasonError:
+		return treatmentCategory(q.Error)
+	case s == eval.Alerting:
+		return uptimeDown
+	default:
+		return uptimeUp
+	}
+}
+
+func treatmentCategory(t UptimeTreatment) uptimeCategory {
+	switch t {
+	case UptimeUp:
+		return uptimeUp
+	case UptimeDown:
+		return uptimeDown
+	default:
+		return uptimeIgnored
+	}
+}
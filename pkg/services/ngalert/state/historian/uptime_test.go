@@ -0,0 +1,94 @@
+package historian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUptime(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return from.Add(time.Duration(m) * time.Minute) }
+
+	type entry struct {
+		time time.Time
+		LokiEntry
+	}
+	entries := []entry{
+		{at(10), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Normal", Current: "Alerting"}},
+		{at(20), LokiEntry{RuleUID: "b", Fingerprint: "3", Previous: "Error", Current: "Normal"}},
+		{at(30), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Alerting", Current: "Normal"}},
+		{at(50), LokiEntry{RuleUID: "a", Fingerprint: "2", Previous: "Normal", Current: "NoData"}},
+		{at(60), LokiEntry{RuleUID: "a", Fingerprint: "2", Previous: "NoData", Current: "Normal"}},
+		{at(0), LokiEntry{RuleUID: "c", Fingerprint: "4", Previous: "Alerting (NoData)", Current: "Normal (NoData)"}},
+		// outside of the time range
+		{at(100), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Normal", Current: "Alerting"}},
+	}
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		line, err := json.Marshal(e.LokiEntry)
+		require.NoError(t, err)
+		times = append(times, e.time)
+		lines = append(lines, line)
+	}
+	frame := data.NewFrame("states",
+		data.NewField(dfTime, nil, times),
+		data.NewField(dfLine, nil, lines),
+	)
+	q := UptimeQuery{From: from, To: at(100)}
+
+	t.Run("rules are down while any instance is alerting", func(t *testing.T) {
+		res, err := Uptime(frame, q)
+		require.NoError(t, err)
+		require.Equal(t, []RuleUptime{
+			{RuleUID: "a", Up: 80 * time.Minute, Down: 20 * time.Minute},
+			{RuleUID: "b", Up: 80 * time.Minute, Down: 20 * time.Minute},
+			{RuleUID: "c", Ignored: 100 * time.Minute},
+		}, res)
+
+		percent, ok := res[0].Percent()
+		require.True(t, ok)
+		require.InDelta(t, 80, percent, 0.001)
+		_, ok = res[2].Percent()
+		require.False(t, ok, "rules that were only in NoData have no uptime")
+	})
+
+	t.Run("NoData and Error can be counted as up or down", func(t *testing.T) {
+		q := q
+		q.NoData = UptimeDown
+		q.Error = UptimeIgnore
+		res, err := Uptime(frame, q)
+		require.NoError(t, err)
+		require.Equal(t, []RuleUptime{
+			{RuleUID: "a", Up: 70 * time.Minute, Down: 30 * time.Minute},
+			{RuleUID: "b", Up: 80 * time.Minute, Ignored: 20 * time.Minute},
+			{RuleUID: "c", Down: 100 * time.Minute},
+		}, res)
+	})
+
+	t.Run("reads annotation frames", func(t *testing.T) {
+		lbls := data.Labels{"from": "state-history", "ruleUID": "a"}
+		frame := data.NewFrame("states",
+			data.NewField("time", lbls, []time.Time{at(10), at(20), at(40)}),
+			data.NewField("text", lbls, []string{"cpu {instance=a} - B=1.000000", "cpu {instance=b} - B=1.000000", "cpu {instance=a} - B=0.000000"}),
+			data.NewField("prev", lbls, []string{"Normal", "Normal", "Alerting"}),
+			data.NewField("next", lbls, []string{"Alerting", "Alerting", "Normal"}),
+			data.NewField("data", lbls, []string{"{}", "{}", "{}"}),
+		)
+		res, err := Uptime(frame, q)
+		require.NoError(t, err)
+		require.Equal(t, []RuleUptime{{RuleUID: "a", Up: 10 * time.Minute, Down: 90 * time.Minute}}, res)
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		_, err := Uptime(frame, UptimeQuery{From: from, To: from})
+		require.ErrorIs(t, err, ErrInvalidUptimeQuery)
+
+		_, err = Uptime(frame, UptimeQuery{From: from, To: at(1), NoData: "alerting"})
+		require.ErrorIs(t, err, ErrInvalidUptimeQuery)
+	})
+}
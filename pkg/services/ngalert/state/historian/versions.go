@@ -0,0 +1,81 @@
+package historian
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var ErrInvalidRuleVersionQuery = errors.New("invalid rule version history query")
+
+// RuleVersionWindow is the part of the state history of a rule during which a version of the rule was the current one.
+type RuleVersionWindow struct {
+	Version int64
+	// From is the time the version was saved, or the start of the time range when it was saved before.
+	From time.Time
+	// To is the time the next version was saved, or the end of the time range for the current version.
+	To time.Time
+	// Current is true for the current version of the rule.
+	Current bool
+	// Transitions is the number of state transitions of the instances of the rule during the window.
+	Transitions int64
+	// Counts is the number of transitions into every state, without their reason.
+	Counts map[string]int64
+}
+
+// TransitionsPerHour is the rate of the transitions of the window, to compare the flappiness of the versions of
+// the rule whatever the time they were current.
+func (w RuleVersionWindow) TransitionsPerHour() float64 {
+	d := w.To.Sub(w.From)
+	if d <= 0 {
+		return 0
+	}
+	return float64(w.Transitions) / d.Hours()
+}
+
+// RuleVersionWindows returns the time ranges during which each version of a rule was current, within [from, to).
+// The versions are ordered from the oldest to the newest, the last one is the current version. The versions that
+// were replaced before the start of the time range are left out.
+func RuleVersionWindows(versions []models.AlertRuleVersion, from, to time.Time) ([]RuleVersionWindow, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: the end of the time range must be after the start", ErrInvalidRuleVersionQuery)
+	}
+	windows := make([]RuleVersionWindow, 0, len(versions))
+	for i, v := range versions {
+		w := RuleVersionWindow{Version: v.Version, From: v.Created, To: to, Current: i == len(versions)-1, Counts: map[string]int64{}}
+		if !w.Current && versions[i+1].Created.Before(to) {
+			w.To = versions[i+1].Created
+		}
+		if w.From.Before(from) {
+			w.From = from
+		}
+		if !w.To.After(w.From) {
+			continue
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// CountRuleVersionTransitions counts the transitions of a state history frame, as returned by any of the history
+// backends, into the windows of the versions of the rule.
+func CountRuleVersionTransitions(frame *data.Frame, windows []RuleVersionWindow) error {
+	transitions, err := readTransitions(frame)
+	if err != nil {
+		return err
+	}
+	for _, t := range transitions {
+		i := sort.Search(len(windows), func(i int) bool { return windows[i].To.After(t.time) })
+		if i == len(windows) || t.time.Before(windows[i].From) {
+			continue
+		}
+		windows[i].Transitions++
+		windows[i].Counts[summaryState(t.state)]++
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package historian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRuleVersionWindows(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return from.Add(time.Duration(m) * time.Minute) }
+	versions := []models.AlertRuleVersion{
+		{Version: 1, Created: at(-120)},
+		{Version: 2, Created: at(-60)},
+		{Version: 3, Created: at(60)},
+		{Version: 4, Created: at(120)},
+	}
+
+	windows, err := RuleVersionWindows(versions, from, at(180))
+	require.NoError(t, err)
+	empty := map[string]int64{}
+	require.Equal(t, []RuleVersionWindow{
+		{Version: 2, From: from, To: at(60), Counts: empty},
+		{Version: 3, From: at(60), To: at(120), Counts: empty},
+		{Version: 4, From: at(120), To: at(180), Current: true, Counts: empty},
+	}, windows, "version 1 was replaced before the time range")
+
+	t.Run("counts the transitions of every version", func(t *testing.T) {
+		entries := []struct {
+			time time.Time
+			LokiEntry
+		}{
+			{at(10), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Normal", Current: "Alerting"}},
+			{at(20), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Alerting", Current: "Normal"}},
+			{at(30), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Normal", Current: "Alerting"}},
+			{at(40), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Alerting", Current: "Normal (NoData)"}},
+			{at(60), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Normal", Current: "Alerting"}},
+			{at(200), LokiEntry{RuleUID: "a", Fingerprint: "1", Previous: "Alerting", Current: "Normal"}},
+		}
+		times := make([]time.Time, 0, len(entries))
+		lines := make([]json.RawMessage, 0, len(entries))
+		for _, e := range entries {
+			line, err := json.Marshal(e.LokiEntry)
+			require.NoError(t, err)
+			times = append(times, e.time)
+			lines = append(lines, line)
+		}
+		frame := data.NewFrame("states", data.NewField(dfTime, nil, times), data.NewField(dfLine, nil, lines))
+
+		windows, err := RuleVersionWindows(versions, from, at(180))
+		require.NoError(t, err)
+		require.NoError(t, CountRuleVersionTransitions(frame, windows))
+
+		require.Equal(t, int64(4), windows[0].Transitions)
+		require.Equal(t, map[string]int64{"Alerting": 2, "Normal": 2}, windows[0].Counts)
+		require.InDelta(t, 4, windows[0].TransitionsPerHour(), 0.001)
+		require.Equal(t, int64(1), windows[1].Transitions, "the transition at the time of the update is counted in the new version")
+		require.Zero(t, windows[2].Transitions, "transitions after the time range are ignored")
+	})
+
+	t.Run("invalid time range", func(t *testing.T) {
+		_, err := RuleVersionWindows(versions, from, from)
+		require.ErrorIs(t, err, ErrInvalidRuleVersionQuery)
+	})
+}
@@ -0,0 +1,192 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// webhookWorkers is the number of batches delivered concurrently, so a slow webhook does not delay the others
+const webhookWorkers = 4
+
+var errWebhookQueryNotSupported = errors.New("state history webhooks do not support queries")
+
+// WebhookPayload is the body posted to the state history webhooks.
+// The events have the format of the state history API.
+type WebhookPayload struct {
+	OrgID  int64                            `json:"orgId"`
+	Events []apimodels.StateTransitionEvent `json:"events"`
+}
+
+type webhookDelivery struct {
+	orgID   int64
+	webhook setting.StateHistoryWebhook
+	body    []byte
+}
+
+// WebhookBackend is a state history backend that posts state transitions to the webhooks of their organization.
+// It does not store anything, so it is meant to be used as a secondary of MultipleBackend.
+// Batches are queued and delivered in the background by Run, with retries for failed requests.
+type WebhookBackend struct {
+	cfg     setting.UnifiedAlertingStateHistoryWebhookSettings
+	client  client.Requester
+	queue   chan webhookDelivery
+	metrics *metrics.Historian
+	log     log.Logger
+	wait    func(ctx context.Context, d time.Duration) error
+}
+
+func NewWebhookBackend(cfg setting.UnifiedAlertingStateHistoryWebhookSettings, req client.Requester, metrics *metrics.Historian, logger log.Logger) *WebhookBackend {
+	return &WebhookBackend{
+		cfg:     cfg,
+		client:  client.NewTimedClient(req, metrics.WriteDuration),
+		queue:   make(chan webhookDelivery, cfg.QueueSize),
+		metrics: metrics,
+		log:     logger,
+		wait:    waitContext,
+	}
+}
+
+// Record implements state.Historian. It queues the transitions for every webhook of the organization without blocking.
+func (b *WebhookBackend) Record(_ context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	errCh := make(chan error)
+	close(errCh)
+
+	webhooks := b.cfg.OrgWebhooks[rule.OrgID]
+	if len(webhooks) == 0 {
+		return errCh
+	}
+	payload := WebhookPayload{OrgID: rule.OrgID}
+	for _, t := range states {
+		if shouldRecord(t) {
+			payload.Events = append(payload.Events, newStreamEvent(rule, t).StateTransitionEvent())
+		}
+	}
+	if len(payload.Events) == 0 {
+		return errCh
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		b.log.Error("Failed to encode state transitions for webhooks", "org", rule.OrgID, "rule_uid", rule.UID, "error", err)
+		return errCh
+	}
+
+	org := strconv.FormatInt(rule.OrgID, 10)
+	for _, w := range webhooks {
+		select {
+		case b.queue <- webhookDelivery{orgID: rule.OrgID, webhook: w, body: body}:
+		default:
+			b.metrics.WebhookDropped.WithLabelValues(org).Inc()
+			b.log.Warn("Dropping state transitions, the webhook queue is full", "org", rule.OrgID, "rule_uid", rule.UID, "url", w.URL)
+		}
+	}
+	return errCh
+}
+
+// Query implements Backend. The webhooks keep no history, so they cannot be used as a primary.
+func (b *WebhookBackend) Query(_ context.Context, _ models.HistoryQuery) (*data.Frame, error) {
+	return nil, errWebhookQueryNotSupported
+}
+
+// Run delivers the queued batches until the context is cancelled.
+func (b *WebhookBackend) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < webhookWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-b.queue:
+					b.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver posts a batch, failed requests are retried with an exponential backoff.
+// Requests rejected with a client error other than 429 Too Many Requests are not retried.
+func (b *WebhookBackend) deliver(ctx context.Context, d webhookDelivery) {
+	org := strconv.FormatInt(d.orgID, 10)
+	backoff := b.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := b.send(ctx, d)
+		if err == nil {
+			b.metrics.WebhookDelivered.WithLabelValues(org).Inc()
+			return
+		}
+		if !retry || attempt >= b.cfg.MaxAttempts {
+			b.metrics.WebhookFailed.WithLabelValues(org).Inc()
+			b.log.Error("Failed to deliver state transitions to webhook", "org", d.orgID, "url", d.webhook.URL, "attempts", attempt, "error", err)
+			return
+		}
+		b.metrics.WebhookRetries.WithLabelValues(org).Inc()
+		b.log.Debug("Retrying the delivery of state transitions to webhook", "org", d.orgID, "url", d.webhook.URL, "backoff", backoff, "error", err)
+		if b.wait(ctx, backoff) != nil {
+			b.metrics.WebhookFailed.WithLabelValues(org).Inc()
+			return
+		}
+		backoff *= 2
+	}
+}
+
+func (b *WebhookBackend) send(ctx context.Context, d webhookDelivery) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.webhook.Authorization != "" {
+		req.Header.Set("Authorization", d.webhook.Authorization)
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+}
+
+func waitContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestWebhookBackend(t *testing.T) {
+	rule := history_model.RuleMeta{OrgID: 1, UID: "rule", Title: "Rule", Group: "group", NamespaceUID: "folder"}
+	transitions := []state.StateTransition{
+		{State: &state.State{State: eval.Alerting, Labels: data.Labels{"team": "ops"}}, PreviousState: eval.Normal},
+		{State: &state.State{State: eval.Alerting, Labels: data.Labels{"team": "dev"}}, PreviousState: eval.Alerting},
+	}
+
+	type request struct {
+		authorization string
+		payload       WebhookPayload
+	}
+	newServer := func(statuses ...int) (*httptest.Server, func() []request) {
+		var mtx sync.Mutex
+		var requests []request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			var p WebhookPayload
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			requests = append(requests, request{authorization: r.Header.Get("Authorization"), payload: p})
+			status := http.StatusOK
+			if len(requests) <= len(statuses) {
+				status = statuses[len(requests)-1]
+			}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, func() []request {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return requests
+		}
+	}
+	newBackend := func(url string) (*WebhookBackend, *metrics.Historian) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		b := NewWebhookBackend(setting.UnifiedAlertingStateHistoryWebhookSettings{
+			Enabled:     true,
+			Timeout:     time.Second,
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+			QueueSize:   1,
+			OrgWebhooks: map[int64][]setting.StateHistoryWebhook{1: {{URL: url, Authorization: "Bearer token"}}},
+		}, NewRequester(), met, log.NewNopLogger())
+		b.wait = func(context.Context, time.Duration) error { return nil }
+		return b, met
+	}
+
+	t.Run("posts the transitions in the format of the history API", func(t *testing.T) {
+		srv, requests := newServer()
+		b, met := newBackend(srv.URL)
+
+		require.NoError(t, <-b.Record(context.Background(), rule, transitions))
+		b.deliver(context.Background(), <-b.queue)
+
+		require.Len(t, requests(), 1)
+		r := requests()[0]
+		require.Equal(t, "Bearer token", r.authorization)
+		require.Equal(t, int64(1), r.payload.OrgID)
+		require.Len(t, r.payload.Events, 1, "unchanged states are not posted")
+		require.Equal(t, "rule", r.payload.Events[0].RuleUID)
+		require.Equal(t, "folder", r.payload.Events[0].FolderUID)
+		require.Equal(t, "Normal", r.payload.Events[0].Previous)
+		require.Equal(t, "Alerting", r.payload.Events[0].Current)
+		require.Equal(t, 1.0, testutil.ToFloat64(met.WebhookDelivered.WithLabelValues("1")))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		srv, requests := newServer(http.StatusBadGateway, http.StatusTooManyRequests)
+		b, met := newBackend(srv.URL)
+
+		<-b.Record(context.Background(), rule, transitions)
+		b.deliver(context.Background(), <-b.queue)
+
+		require.Len(t, requests(), 3)
+		require.Equal(t, 2.0, testutil.ToFloat64(met.WebhookRetries.WithLabelValues("1")))
+		require.Equal(t, 1.0, testutil.ToFloat64(met.WebhookDelivered.WithLabelValues("1")))
+	})
+
+	t.Run("gives up after the last attempt and on client errors", func(t *testing.T) {
+		srv, requests := newServer(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusBadRequest)
+		b, met := newBackend(srv.URL)
+
+		<-b.Record(context.Background(), rule, transitions)
+		b.deliver(context.Background(), <-b.queue)
+		require.Len(t, requests(), 3)
+
+		<-b.Record(context.Background(), rule, transitions)
+		b.deliver(context.Background(), <-b.queue)
+		require.Len(t, requests(), 4)
+		require.Equal(t, 2.0, testutil.ToFloat64(met.WebhookFailed.WithLabelValues("1")))
+	})
+
+	t.Run("drops batches when the queue is full", func(t *testing.T) {
+		b, met := newBackend("http://localhost")
+
+		<-b.Record(context.Background(), rule, transitions)
+		<-b.Record(context.Background(), rule, transitions)
+		require.Len(t, b.queue, 1)
+		require.Equal(t, 1.0, testutil.ToFloat64(met.WebhookDropped.WithLabelValues("1")))
+	})
+
+	t.Run("ignores organizations without webhooks", func(t *testing.T) {
+		b, _ := newBackend("http://localhost")
+
+		<-b.Record(context.Background(), history_model.RuleMeta{OrgID: 2, UID: "rule"}, transitions)
+		require.Empty(t, b.queue)
+	})
+}
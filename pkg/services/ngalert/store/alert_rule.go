@@ -117,6 +117,24 @@ func (st DBstore) GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAler
 	return result, err
 }
 
+// GetAlertRuleVersions returns the saved versions of the alert rule, from the oldest to the newest.
+// The oldest versions are missing once they are deleted to keep at most the configured number of versions.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]ngmodels.AlertRuleVersion, error) {
+	var rows []alertRuleVersion
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table(alertRuleVersion{}).Cols("version", "created").
+			Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Asc("id").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]ngmodels.AlertRuleVersion, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, ngmodels.AlertRuleVersion{Version: row.Version, Created: row.Created})
+	}
+	return versions, nil
+}
+
 // GetRuleByID retrieves models.AlertRule by ID.
 // It returns models.ErrAlertRuleNotFound if no alert rule is found for the provided ID.
 func (st DBstore) GetRuleByID(ctx context.Context, query ngmodels.GetAlertRuleByIDQuery) (result *ngmodels.AlertRule, err error) {
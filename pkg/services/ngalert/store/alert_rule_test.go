@@ -1655,6 +1655,38 @@ func TestIntegration_AlertRuleVersionsCleanup(t *testing.T) {
 	})
 }
 
+func TestIntegration_GetAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting = setting.UnifiedAlertingSettings{BaseInterval: time.Second, RuleVersionRecordLimit: 10}
+	sqlStore := db.InitTestDB(t)
+	folderService := setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures())
+	store := createTestStore(sqlStore, folderService, &logtest.Fake{}, cfg.UnifiedAlerting, &fakeBus{})
+	generator := models.RuleGen.With(models.RuleMuts.WithIntervalMatching(store.Cfg.BaseInterval), models.RuleMuts.WithUniqueOrgID())
+
+	rule := createRule(t, store, generator)
+	versions, err := store.GetAlertRuleVersions(context.Background(), rule.OrgID, rule.UID)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	for i := 0; i < 2; i++ {
+		updated := models.CopyRule(rule)
+		updated.Title = util.GenerateShortUID()
+		require.NoError(t, store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: rule, New: *updated}}))
+		rule, err = store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID})
+		require.NoError(t, err)
+	}
+
+	versions, err = store.GetAlertRuleVersions(context.Background(), rule.OrgID, rule.UID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, rule.Version-1, versions[0].Version)
+	require.Equal(t, rule.Version, versions[1].Version)
+	require.False(t, versions[1].Created.Before(versions[0].Created))
+}
+
 func createTestStore(
 	sqlStore db.DB,
 	folderService folder.Service,
@@ -625,6 +625,39 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 	})
 }
 
+func TestIntegrationUpdateAccessToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+	savedPublicDashboard := insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, PublicShareType)
+
+	affectedRows, err := publicdashboardStore.UpdateAccessToken(context.Background(), SavePublicDashboardCommand{
+		PublicDashboard: PublicDashboard{
+			Uid:         savedPublicDashboard.Uid,
+			AccessToken: "rotatedtoken",
+			UpdatedBy:   7,
+			UpdatedAt:   time.Now(),
+		},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, affectedRows)
+
+	pubdash, err := publicdashboardStore.Find(context.Background(), savedPublicDashboard.Uid)
+	require.NoError(t, err)
+	assert.Equal(t, "rotatedtoken", pubdash.AccessToken)
+	assert.EqualValues(t, 7, pubdash.UpdatedBy)
+	assert.Equal(t, savedPublicDashboard.IsEnabled, pubdash.IsEnabled)
+
+	previous, err := publicdashboardStore.FindByAccessToken(context.Background(), savedPublicDashboard.AccessToken)
+	require.NoError(t, err)
+	assert.Nil(t, previous)
+}
+
 func TestIntegrationGetOrgIdByAccessToken(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -499,6 +499,32 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RotateAccessToken provides a mock function with given fields: ctx, u, uid, dashboardUid
+func (_m *FakePublicDashboardService) RotateAccessToken(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) (*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, u, uid, dashboardUid)
+
+	var r0 *models.PublicDashboard
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) (*models.PublicDashboard, error)); ok {
+		return rf(ctx, u, uid, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) *models.PublicDashboard); ok {
+		r0 = rf(ctx, u, uid, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboard)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string) error); ok {
+		r1 = rf(ctx, u, uid, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
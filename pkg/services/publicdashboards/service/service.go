@@ -322,6 +322,53 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	return newPubdash, nil
 }
 
+// RotateAccessToken replaces the access token of a public dashboard, links with the previous token stop working
+func (pd *PublicDashboardServiceImpl) RotateAccessToken(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) (*PublicDashboard, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RotateAccessToken")
+	defer span.End()
+
+	existingPubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("RotateAccessToken: failed to find public dashboard by uid: %s: %w", uid, err)
+	} else if existingPubdash == nil {
+		return nil, ErrPublicDashboardNotFound.Errorf("RotateAccessToken: public dashboard not found by uid: %s", uid)
+	}
+
+	// validate the public dashboard belongs to the dashboard
+	if existingPubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("RotateAccessToken: the public dashboard does not belong to the dashboard")
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := SavePublicDashboardCommand{
+		PublicDashboard: PublicDashboard{
+			Uid:         existingPubdash.Uid,
+			AccessToken: accessToken,
+			UpdatedBy:   u.UserID,
+			UpdatedAt:   time.Now(),
+		},
+	}
+	affectedRows, err := pd.store.UpdateAccessToken(ctx, cmd)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("RotateAccessToken: failed to update public dashboard: %w", err)
+	}
+	if affectedRows == 0 {
+		return nil, ErrPublicDashboardNotFound.Errorf("RotateAccessToken: failed to update public dashboard not found by uid: %s", uid)
+	}
+
+	newPubdash, err := pd.store.Find(ctx, existingPubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("RotateAccessToken: failed to find public dashboard by uid: %s: %w", existingPubdash.Uid, err)
+	}
+
+	pd.log.Info("Public dashboard access token rotated", "publicDashboardUid", newPubdash.Uid, "dashboardUid", newPubdash.DashboardUid, "user", u.Login)
+	return newPubdash, nil
+}
+
 // NewPublicDashboardUid Generates a unique uid to create a public dashboard. Will make 3 attempts and fail if it cannot find an unused uid
 func (pd *PublicDashboardServiceImpl) NewPublicDashboardUid(ctx context.Context) (string, error) {
 	ctx, span := tracer.Start(ctx, "publicdashboards.NewPublicDashboardUid")
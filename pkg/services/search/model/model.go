@@ -63,23 +63,30 @@ const (
 )
 
 type Hit struct {
-	ID                    int64      `json:"id"`
-	UID                   string     `json:"uid"`
-	Title                 string     `json:"title"`
-	URI                   string     `json:"uri"`
-	URL                   string     `json:"url"`
-	Slug                  string     `json:"slug"`
-	Type                  HitType    `json:"type"`
-	Tags                  []string   `json:"tags"`
-	IsStarred             bool       `json:"isStarred"`
-	FolderID              int64      `json:"folderId,omitempty"` // Deprecated: use FolderUID instead
-	FolderUID             string     `json:"folderUid,omitempty"`
-	FolderTitle           string     `json:"folderTitle,omitempty"`
-	FolderURL             string     `json:"folderUrl,omitempty"`
-	SortMeta              int64      `json:"sortMeta"`
-	SortMetaName          string     `json:"sortMetaName,omitempty"`
-	IsDeleted             bool       `json:"isDeleted"`
-	PermanentlyDeleteDate *time.Time `json:"permanentlyDeleteDate,omitempty"`
+	ID                    int64       `json:"id"`
+	UID                   string      `json:"uid"`
+	Title                 string      `json:"title"`
+	URI                   string      `json:"uri"`
+	URL                   string      `json:"url"`
+	Slug                  string      `json:"slug"`
+	Type                  HitType     `json:"type"`
+	Tags                  []string    `json:"tags"`
+	IsStarred             bool        `json:"isStarred"`
+	FolderID              int64       `json:"folderId,omitempty"` // Deprecated: use FolderUID instead
+	FolderUID             string      `json:"folderUid,omitempty"`
+	FolderTitle           string      `json:"folderTitle,omitempty"`
+	FolderURL             string      `json:"folderUrl,omitempty"`
+	FolderPath            []HitFolder `json:"folderPath,omitempty"`
+	SortMeta              int64       `json:"sortMeta"`
+	SortMetaName          string      `json:"sortMetaName,omitempty"`
+	IsDeleted             bool        `json:"isDeleted"`
+	PermanentlyDeleteDate *time.Time  `json:"permanentlyDeleteDate,omitempty"`
+}
+
+// HitFolder is a folder of the path of a hit, from the root folder to the folder of the hit.
+type HitFolder struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
 }
 
 type HitList []*Hit
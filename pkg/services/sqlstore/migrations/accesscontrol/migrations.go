@@ -210,4 +210,32 @@ func AddMigration(mg *migrator.Migrator) {
 		Type: migrator.UniqueIndex,
 		Cols: []string{"org_id", "user_id", "role_id"},
 	}))
+
+	mg.AddMigration("add team_role org ID, role ID index", migrator.NewAddIndexMigration(teamRoleV1, &migrator.Index{
+		Cols: []string{"org_id", "role_id"},
+	}))
+
+	// the audit keeps the uids of the team and role, the history remains when they are deleted
+	teamRoleAuditV1 := migrator.Table{
+		Name: "team_role_audit",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt},
+			{Name: "team_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "role_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "action", Type: migrator.DB_Varchar, Length: 16, Nullable: false},
+			{Name: "actor", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "team_uid"}},
+			{Cols: []string{"org_id", "role_uid"}},
+		},
+	}
+
+	mg.AddMigration("create team role audit table", migrator.NewAddTableMigration(teamRoleAuditV1))
+
+	//-------  indexes ------------------
+	mg.AddMigration("add index team_role_audit.org_id_team_uid", migrator.NewAddIndexMigration(teamRoleAuditV1, teamRoleAuditV1.Indices[0]))
+	mg.AddMigration("add index team_role_audit.org_id_role_uid", migrator.NewAddIndexMigration(teamRoleAuditV1, teamRoleAuditV1.Indices[1]))
 }
@@ -56,4 +56,12 @@ FROM dashboard;`
 	// change column type of dashboard_version.data
 	mg.AddMigration("alter dashboard_version.data to mediumtext v1", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_version MODIFY data MEDIUMTEXT;"))
+
+	// versions can be labeled and pinned, pinned versions are never deleted by the clean up
+	mg.AddMigration("Add column label in dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "label", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+	mg.AddMigration("Add column pinned in dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "pinned", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
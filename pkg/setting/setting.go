@@ -14,6 +14,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -219,9 +220,20 @@ type Cfg struct {
 	MetricsGrafanaEnvironmentInfo    map[string]string
 
 	// Dashboards
-	DashboardVersionsToKeep  int
-	MinRefreshInterval       string
-	DefaultHomeDashboardPath string
+	DashboardVersionsToKeep    int
+	DashboardVersionsRetention DashboardVersionsRetentionSettings
+	MinRefreshInterval         string
+	DefaultHomeDashboardPath   string
+	DashboardMaxSpecSize       int64
+	DashboardRateLimit         DashboardRateLimitSettings
+	DashboardLibraryPanelGC    DashboardLibraryPanelGCSettings
+	DashboardLinkValidation    DashboardLinkValidationSettings
+	// DashboardAuditLogPath is the file the changes of dashboards made through the dashboards API are appended to
+	DashboardAuditLogPath string
+	// DashboardSlowSearchThreshold is the duration above which searches of the dashboards API are logged, 0 disables the log
+	DashboardSlowSearchThreshold time.Duration
+	// DashboardTombstoneRetention is the duration deleted dashboards are listed for, 0 disables the listing
+	DashboardTombstoneRetention time.Duration
 
 	// Auth
 	LoginCookieName               string
@@ -416,6 +428,23 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// SQLExpressionsAllowedStatements are the statement types SQL expressions can run in every org.
+	SQLExpressionsAllowedStatements []string
+	// SQLExpressionsOrgAllowedStatements are additional statement types SQL expressions can run, keyed by org ID.
+	SQLExpressionsOrgAllowedStatements map[int64][]string
+	// SQLExpressionsSpillThreshold is the number of rows of a table SQL expressions keep in memory before writing
+	// the others to a temporary file in SQLExpressionsSpillDir. Zero keeps all the rows in memory.
+	SQLExpressionsSpillThreshold int
+	SQLExpressionsSpillDir       string
+	// SQLExpressionsEnabledOrgs are the orgs SQL expressions can run in, all orgs when empty.
+	// SQLExpressionsDisabledOrgs are orgs they cannot run in, even when they are enabled.
+	SQLExpressionsEnabledOrgs  []int64
+	SQLExpressionsDisabledOrgs []int64
+	// SQLExpressionsMaxJoins and SQLExpressionsMaxSubqueryDepth limit the complexity of the statements of SQL
+	// expressions, and SQLExpressionsTimeout the duration of their execution. Zero values are unlimited.
+	SQLExpressionsMaxJoins         int
+	SQLExpressionsMaxSubqueryDepth int
+	SQLExpressionsTimeout          time.Duration
 
 	ImageUploadProvider string
 
@@ -751,9 +780,69 @@ func (cfg *Cfg) readAnnotationSettings() error {
 	return nil
 }
 
-func (cfg *Cfg) readExpressionsSettings() {
+func (cfg *Cfg) readExpressionsSettings() error {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.SQLExpressionsAllowedStatements = util.SplitString(expressions.Key("sql_allowed_statements").MustString("SELECT WITH"))
+	cfg.SQLExpressionsSpillThreshold = expressions.Key("sql_spill_threshold_rows").MustInt(0)
+	if cfg.SQLExpressionsSpillThreshold < 0 {
+		return fmt.Errorf("[expressions] sql_spill_threshold_rows must not be negative")
+	}
+	cfg.SQLExpressionsSpillDir = expressions.Key("sql_spill_dir").String()
+
+	var err error
+	if cfg.SQLExpressionsEnabledOrgs, err = readOrgIDs(expressions.Key("sql_enabled_orgs").String()); err != nil {
+		return fmt.Errorf("[expressions] sql_enabled_orgs: %w", err)
+	}
+	if cfg.SQLExpressionsDisabledOrgs, err = readOrgIDs(expressions.Key("sql_disabled_orgs").String()); err != nil {
+		return fmt.Errorf("[expressions] sql_disabled_orgs: %w", err)
+	}
+	cfg.SQLExpressionsMaxJoins = expressions.Key("sql_max_joins").MustInt(0)
+	cfg.SQLExpressionsMaxSubqueryDepth = expressions.Key("sql_max_subquery_depth").MustInt(0)
+	if cfg.SQLExpressionsMaxJoins < 0 || cfg.SQLExpressionsMaxSubqueryDepth < 0 {
+		return fmt.Errorf("[expressions] sql_max_joins and sql_max_subquery_depth must not be negative")
+	}
+	cfg.SQLExpressionsTimeout = expressions.Key("sql_timeout").MustDuration(0)
+	if cfg.SQLExpressionsTimeout < 0 {
+		return fmt.Errorf("[expressions] sql_timeout must not be negative")
+	}
+
+	cfg.SQLExpressionsOrgAllowedStatements = map[int64][]string{}
+	for _, key := range cfg.Raw.Section("expressions.sql_org_allowed_statements").Keys() {
+		orgID, err := strconv.ParseInt(key.Name(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid org ID %q in [expressions.sql_org_allowed_statements]", key.Name())
+		}
+		cfg.SQLExpressionsOrgAllowedStatements[orgID] = util.SplitString(key.String())
+	}
+	return nil
+}
+
+// SQLExpressionsAllowedStatementsForOrg returns the statement types SQL expressions can run in the org.
+func (cfg *Cfg) SQLExpressionsAllowedStatementsForOrg(orgID int64) []string {
+	allowed := append([]string{}, cfg.SQLExpressionsAllowedStatements...)
+	return append(allowed, cfg.SQLExpressionsOrgAllowedStatements[orgID]...)
+}
+
+// SQLExpressionsEnabledForOrg returns whether SQL expressions can run in the org.
+func (cfg *Cfg) SQLExpressionsEnabledForOrg(orgID int64) bool {
+	if slices.Contains(cfg.SQLExpressionsDisabledOrgs, orgID) {
+		return false
+	}
+	return len(cfg.SQLExpressionsEnabledOrgs) == 0 || slices.Contains(cfg.SQLExpressionsEnabledOrgs, orgID)
+}
+
+// readOrgIDs reads a list of org IDs separated by comma or space.
+func readOrgIDs(value string) ([]int64, error) {
+	ids := []int64{}
+	for _, s := range util.SplitString(value) {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid org ID %q", s)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
 }
 
 type AnnotationCleanupSettings struct {
@@ -1113,7 +1202,27 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	dashboards := iniFile.Section("dashboards")
 	cfg.DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
 	cfg.MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
+	cfg.DashboardMaxSpecSize = dashboards.Key("max_spec_size").MustInt64(10 * 1024 * 1024)
+	cfg.DashboardSlowSearchThreshold = dashboards.Key("slow_search_threshold").MustDuration(time.Second)
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	if err := readDashboardVersionsSettings(cfg, iniFile); err != nil {
+		return err
+	}
+	if err := readDashboardRateLimitSettings(cfg, iniFile); err != nil {
+		return err
+	}
+	if err := readDashboardLibraryPanelGCSettings(cfg, iniFile); err != nil {
+		return err
+	}
+	if err := readDashboardTombstoneSettings(cfg, iniFile); err != nil {
+		return err
+	}
+	if err := readDashboardLinkValidationSettings(cfg, iniFile); err != nil {
+		return err
+	}
+	if path := iniFile.Section("dashboards.audit").Key("log_path").String(); path != "" {
+		cfg.DashboardAuditLogPath = makeAbsolute(path, cfg.HomePath)
+	}
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1253,7 +1362,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.readQuotaSettings()
 
-	cfg.readExpressionsSettings()
+	if err := cfg.readExpressionsSettings(); err != nil {
+		return err
+	}
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
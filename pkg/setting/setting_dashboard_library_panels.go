@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// DashboardLibraryPanelGCSettings configures the background job that finds the references of dashboards to library
+// panels that were deleted, and optionally replaces them with an inlined copy of the panel.
+type DashboardLibraryPanelGCSettings struct {
+	Enabled  bool
+	Interval time.Duration
+	// Inline replaces the orphaned references with inlined panels, otherwise they are only reported.
+	Inline bool
+}
+
+func readDashboardLibraryPanelGCSettings(cfg *Cfg, iniFile *ini.File) error {
+	section := iniFile.Section("dashboards.library_panels_gc")
+	s := DashboardLibraryPanelGCSettings{
+		Enabled: section.Key("enabled").MustBool(false),
+		Inline:  section.Key("inline").MustBool(false),
+	}
+	var err error
+	s.Interval, err = gtime.ParseDuration(valueAsString(section, "interval", "24h"))
+	if err != nil {
+		return fmt.Errorf("setting 'interval' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	if s.Interval <= 0 {
+		return fmt.Errorf("setting 'interval' in section [%s] must be greater than 0", section.Name())
+	}
+	cfg.DashboardLibraryPanelGC = s
+	return nil
+}
@@ -0,0 +1,33 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDashboardLibraryPanelGCSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("dashboards.library_panels_gc")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, readDashboardLibraryPanelGCSettings(cfg, f))
+	require.Equal(t, DashboardLibraryPanelGCSettings{
+		Enabled:  true,
+		Interval: 24 * time.Hour,
+	}, cfg.DashboardLibraryPanelGC)
+
+	t.Run("should fail if the interval is 0", func(t *testing.T) {
+		_, err := section.NewKey("interval", "0s")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			section.DeleteKey("interval")
+		})
+		require.Error(t, readDashboardLibraryPanelGCSettings(cfg, f))
+	})
+}
@@ -0,0 +1,113 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Modes of the validation of the links of dashboards
+const (
+	DashboardLinkValidationOff    = "off"
+	DashboardLinkValidationWarn   = "warn"
+	DashboardLinkValidationReject = "reject"
+)
+
+// DashboardLinkValidationSettings configures the checks of the dashboard links, panel links and data links of the
+// dashboards saved with the dashboards API.
+type DashboardLinkValidationSettings struct {
+	// Default is applied to every organization that does not have an override.
+	Default DashboardLinkValidation
+	// OrgOverrides holds validation settings for specific organizations, keyed by organization ID.
+	OrgOverrides map[int64]DashboardLinkValidation
+}
+
+// DashboardLinkValidation describes the links allowed in the dashboards of an organization.
+type DashboardLinkValidation struct {
+	// Mode is off, warn to save the dashboard with warnings, or reject to refuse the dashboard.
+	Mode string
+	// AllowedSchemes are the URL schemes of the links, the links without a scheme are always allowed.
+	AllowedSchemes []string
+	// AllowedHosts are the hosts the links can point to, *.example.com allows the subdomains. Empty allows any host.
+	AllowedHosts []string
+	// CheckDashboardUIDs checks that the dashboards linked with /d/<uid> exist in the organization.
+	CheckDashboardUIDs bool
+}
+
+// ForOrg returns the validation that applies to the given organization.
+func (s DashboardLinkValidationSettings) ForOrg(orgID int64) DashboardLinkValidation {
+	if v, ok := s.OrgOverrides[orgID]; ok {
+		return v
+	}
+	return s.Default
+}
+
+func readDashboardLinkValidationSettings(cfg *Cfg, iniFile *ini.File) error {
+	section := iniFile.Section("dashboards.link_validation")
+	s := DashboardLinkValidationSettings{
+		OrgOverrides: make(map[int64]DashboardLinkValidation),
+	}
+	var err error
+	s.Default, err = readDashboardLinkValidation(section, DashboardLinkValidation{
+		Mode:               DashboardLinkValidationWarn,
+		AllowedSchemes:     []string{"http", "https", "mailto"},
+		CheckDashboardUIDs: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Organization specific overrides are defined in child sections, e.g. [dashboards.link_validation.org_2].
+	for _, child := range section.ChildSections() {
+		name := strings.TrimPrefix(child.Name(), section.Name()+".")
+		idStr, ok := strings.CutPrefix(name, "org_")
+		if !ok {
+			return fmt.Errorf("section [%s] is invalid, expected the name to be in the format [%s.org_<id>]", child.Name(), section.Name())
+		}
+		orgID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || orgID <= 0 {
+			return fmt.Errorf("section [%s] is invalid, organization ID must be a positive integer", child.Name())
+		}
+		s.OrgOverrides[orgID], err = readDashboardLinkValidation(child, s.Default)
+		if err != nil {
+			return err
+		}
+	}
+	cfg.DashboardLinkValidation = s
+	return nil
+}
+
+func readDashboardLinkValidation(section *ini.Section, defaults DashboardLinkValidation) (DashboardLinkValidation, error) {
+	v := defaults
+	if mode := section.Key("mode").String(); mode != "" {
+		switch mode {
+		case DashboardLinkValidationOff, DashboardLinkValidationWarn, DashboardLinkValidationReject:
+			v.Mode = mode
+		default:
+			return v, fmt.Errorf("setting 'mode' in section [%s] is invalid, expected %s, %s or %s", section.Name(),
+				DashboardLinkValidationOff, DashboardLinkValidationWarn, DashboardLinkValidationReject)
+		}
+	}
+	if section.HasKey("allowed_schemes") {
+		v.AllowedSchemes = lowerList(section.Key("allowed_schemes").String())
+	}
+	if section.HasKey("allowed_hosts") {
+		v.AllowedHosts = lowerList(section.Key("allowed_hosts").String())
+	}
+	if section.HasKey("check_dashboard_uids") {
+		v.CheckDashboardUIDs = section.Key("check_dashboard_uids").MustBool(defaults.CheckDashboardUIDs)
+	}
+	return v, nil
+}
+
+func lowerList(s string) []string {
+	res := []string{}
+	for _, item := range util.SplitString(s) {
+		res = append(res, strings.ToLower(item))
+	}
+	return res
+}
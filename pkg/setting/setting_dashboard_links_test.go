@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDashboardLinkValidationSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("dashboards.link_validation")
+	require.NoError(t, err)
+	_, err = section.NewKey("allowed_schemes", "HTTPS, mailto")
+	require.NoError(t, err)
+
+	override, err := f.NewSection("dashboards.link_validation.org_2")
+	require.NoError(t, err)
+	_, err = override.NewKey("mode", "reject")
+	require.NoError(t, err)
+	_, err = override.NewKey("allowed_hosts", "grafana.com, *.example.com")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, readDashboardLinkValidationSettings(cfg, f))
+
+	links := cfg.DashboardLinkValidation
+	require.Equal(t, DashboardLinkValidation{
+		Mode:               DashboardLinkValidationWarn,
+		AllowedSchemes:     []string{"https", "mailto"},
+		CheckDashboardUIDs: true,
+	}, links.ForOrg(1))
+	require.Equal(t, DashboardLinkValidation{
+		Mode:               DashboardLinkValidationReject,
+		AllowedSchemes:     []string{"https", "mailto"},
+		AllowedHosts:       []string{"grafana.com", "*.example.com"},
+		CheckDashboardUIDs: true,
+	}, links.ForOrg(2))
+
+	t.Run("should fail if the mode is unknown", func(t *testing.T) {
+		_, err := override.NewKey("mode", "block")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = override.NewKey("mode", "reject")
+		})
+		require.Error(t, readDashboardLinkValidationSettings(cfg, f))
+	})
+
+	t.Run("should fail if override section name is invalid", func(t *testing.T) {
+		_, err := f.NewSection("dashboards.link_validation.main")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			f.DeleteSection("dashboards.link_validation.main")
+		})
+		require.Error(t, readDashboardLinkValidationSettings(cfg, f))
+	})
+}
@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"fmt"
+
+	"gopkg.in/ini.v1"
+)
+
+// DashboardRateLimitSettings configures the per namespace rate limits of the dashboards API.
+// Each namespace has a token bucket for searches and one for writes, refilled at the rate per second.
+type DashboardRateLimitSettings struct {
+	Enabled     bool
+	SearchRate  float64
+	SearchBurst int
+	WriteRate   float64
+	WriteBurst  int
+}
+
+func readDashboardRateLimitSettings(cfg *Cfg, iniFile *ini.File) error {
+	section := iniFile.Section("dashboards.rate_limit")
+	s := DashboardRateLimitSettings{
+		Enabled:     section.Key("enabled").MustBool(false),
+		SearchRate:  section.Key("search_rate").MustFloat64(20),
+		SearchBurst: section.Key("search_burst").MustInt(50),
+		WriteRate:   section.Key("write_rate").MustFloat64(10),
+		WriteBurst:  section.Key("write_burst").MustInt(20),
+	}
+	if s.SearchRate <= 0 || s.WriteRate <= 0 {
+		return fmt.Errorf("settings 'search_rate' and 'write_rate' in section [%s] must be greater than 0", section.Name())
+	}
+	if s.SearchBurst < 1 || s.WriteBurst < 1 {
+		return fmt.Errorf("settings 'search_burst' and 'write_burst' in section [%s] must be at least 1", section.Name())
+	}
+	cfg.DashboardRateLimit = s
+	return nil
+}
@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDashboardRateLimitSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("dashboards.rate_limit")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = section.NewKey("write_rate", "0.5")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, readDashboardRateLimitSettings(cfg, f))
+	require.Equal(t, DashboardRateLimitSettings{
+		Enabled:     true,
+		SearchRate:  20,
+		SearchBurst: 50,
+		WriteRate:   0.5,
+		WriteBurst:  20,
+	}, cfg.DashboardRateLimit)
+
+	t.Run("should fail if the burst is 0", func(t *testing.T) {
+		_, err := section.NewKey("search_burst", "0")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			section.DeleteKey("search_burst")
+		})
+		require.Error(t, readDashboardRateLimitSettings(cfg, f))
+	})
+}
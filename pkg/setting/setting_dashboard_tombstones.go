@@ -0,0 +1,21 @@
+package setting
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+func readDashboardTombstoneSettings(cfg *Cfg, iniFile *ini.File) error {
+	section := iniFile.Section("dashboards")
+	retention, err := gtime.ParseDuration(valueAsString(section, "tombstone_retention", "7d"))
+	if err != nil {
+		return fmt.Errorf("setting 'tombstone_retention' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	if retention < 0 {
+		return fmt.Errorf("setting 'tombstone_retention' in section [%s] must not be negative", section.Name())
+	}
+	cfg.DashboardTombstoneRetention = retention
+	return nil
+}
@@ -0,0 +1,30 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDashboardTombstoneSettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	require.NoError(t, readDashboardTombstoneSettings(cfg, f))
+	require.Equal(t, 7*24*time.Hour, cfg.DashboardTombstoneRetention)
+
+	section, err := f.NewSection("dashboards")
+	require.NoError(t, err)
+	key, err := section.NewKey("tombstone_retention", "12h")
+	require.NoError(t, err)
+	require.NoError(t, readDashboardTombstoneSettings(cfg, f))
+	require.Equal(t, 12*time.Hour, cfg.DashboardTombstoneRetention)
+
+	key.SetValue("0")
+	require.NoError(t, readDashboardTombstoneSettings(cfg, f))
+	require.Zero(t, cfg.DashboardTombstoneRetention)
+
+	key.SetValue("soon")
+	require.Error(t, readDashboardTombstoneSettings(cfg, f))
+}
@@ -0,0 +1,118 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+const (
+	dashboardVersionsRetentionInterval  = time.Hour
+	dashboardVersionsRetentionBatchSize = 100
+)
+
+// DashboardVersionsRetentionSettings configures the background job that deletes old dashboard versions.
+// When it is enabled, it replaces the clean up of the versions above [dashboards] versions_to_keep.
+type DashboardVersionsRetentionSettings struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+	// Default is applied to every organization that does not have an override.
+	Default DashboardVersionsRetention
+	// OrgOverrides holds retention settings for specific organizations, keyed by organization ID.
+	OrgOverrides map[int64]DashboardVersionsRetention
+}
+
+// DashboardVersionsRetention describes which versions of a dashboard are kept for an organization.
+// A version is kept when it is one of the most recent versions or when it is younger than the maximum age.
+type DashboardVersionsRetention struct {
+	// VersionsToKeep is the number of most recent versions kept for every dashboard, at least 1.
+	VersionsToKeep int
+	// MaxAge keeps the versions younger than it. Zero only keeps the most recent versions.
+	MaxAge time.Duration
+}
+
+// ForOrg returns the retention that applies to the given organization.
+func (s DashboardVersionsRetentionSettings) ForOrg(orgID int64) DashboardVersionsRetention {
+	if r, ok := s.OrgOverrides[orgID]; ok {
+		return r
+	}
+	return s.Default
+}
+
+func readDashboardVersionsSettings(cfg *Cfg, iniFile *ini.File) error {
+	retention, err := readDashboardVersionsRetentionSettings(iniFile.Section("dashboards.versions_retention"), cfg.DashboardVersionsToKeep)
+	if err != nil {
+		return err
+	}
+	cfg.DashboardVersionsRetention = retention
+	return nil
+}
+
+func readDashboardVersionsRetentionSettings(section *ini.Section, versionsToKeep int) (DashboardVersionsRetentionSettings, error) {
+	cfg := DashboardVersionsRetentionSettings{
+		Enabled:      section.Key("enabled").MustBool(false),
+		BatchSize:    section.Key("batch_size").MustInt(dashboardVersionsRetentionBatchSize),
+		OrgOverrides: make(map[int64]DashboardVersionsRetention),
+	}
+	var err error
+	cfg.Interval, err = gtime.ParseDuration(valueAsString(section, "interval", dashboardVersionsRetentionInterval.String()))
+	if err != nil {
+		return cfg, fmt.Errorf("setting 'interval' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	if cfg.Interval <= 0 {
+		return cfg, fmt.Errorf("setting 'interval' in section [%s] must be greater than 0", section.Name())
+	}
+	if cfg.BatchSize <= 0 {
+		return cfg, fmt.Errorf("setting 'batch_size' in section [%s] must be greater than 0", section.Name())
+	}
+
+	cfg.Default, err = readDashboardVersionsRetention(section, DashboardVersionsRetention{VersionsToKeep: max(versionsToKeep, 1)})
+	if err != nil {
+		return cfg, err
+	}
+
+	// Organization specific overrides are defined in child sections, e.g. [dashboards.versions_retention.org_2].
+	for _, child := range section.ChildSections() {
+		name := strings.TrimPrefix(child.Name(), section.Name()+".")
+		idStr, ok := strings.CutPrefix(name, "org_")
+		if !ok {
+			return cfg, fmt.Errorf("section [%s] is invalid, expected the name to be in the format [%s.org_<id>]", child.Name(), section.Name())
+		}
+		orgID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || orgID <= 0 {
+			return cfg, fmt.Errorf("section [%s] is invalid, organization ID must be a positive integer", child.Name())
+		}
+		cfg.OrgOverrides[orgID], err = readDashboardVersionsRetention(child, cfg.Default)
+		if err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+func readDashboardVersionsRetention(section *ini.Section, defaults DashboardVersionsRetention) (DashboardVersionsRetention, error) {
+	r := defaults
+	if section.Key("versions_to_keep").String() != "" {
+		r.VersionsToKeep = section.Key("versions_to_keep").MustInt(0)
+		if r.VersionsToKeep < 1 {
+			return r, fmt.Errorf("setting 'versions_to_keep' in section [%s] is invalid, at least 1 version must be kept", section.Name())
+		}
+	}
+	if v := section.Key("max_age").String(); v != "" {
+		maxAge, err := gtime.ParseDuration(v)
+		if err != nil {
+			return r, fmt.Errorf("setting 'max_age' in section [%s] is invalid: %w", section.Name(), err)
+		}
+		if maxAge < 0 {
+			return r, fmt.Errorf("setting 'max_age' in section [%s] is invalid, only 0 or a positive duration are allowed", section.Name())
+		}
+		r.MaxAge = maxAge
+	}
+	return r, nil
+}
@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDashboardVersionsRetentionSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("dashboards.versions_retention")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = section.NewKey("max_age", "30d")
+	require.NoError(t, err)
+
+	override, err := f.NewSection("dashboards.versions_retention.org_2")
+	require.NoError(t, err)
+	_, err = override.NewKey("versions_to_keep", "50")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	cfg.DashboardVersionsToKeep = 20
+	require.NoError(t, readDashboardVersionsSettings(cfg, f))
+
+	retention := cfg.DashboardVersionsRetention
+	require.True(t, retention.Enabled)
+	require.Equal(t, dashboardVersionsRetentionInterval, retention.Interval)
+	require.Equal(t, dashboardVersionsRetentionBatchSize, retention.BatchSize)
+	require.Equal(t, DashboardVersionsRetention{VersionsToKeep: 20, MaxAge: 30 * 24 * time.Hour}, retention.ForOrg(1))
+	require.Equal(t, DashboardVersionsRetention{VersionsToKeep: 50, MaxAge: 30 * 24 * time.Hour}, retention.ForOrg(2))
+
+	t.Run("should fail if override section name is invalid", func(t *testing.T) {
+		_, err := f.NewSection("dashboards.versions_retention.main")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			f.DeleteSection("dashboards.versions_retention.main")
+		})
+		require.Error(t, readDashboardVersionsSettings(cfg, f))
+	})
+
+	t.Run("should fail if no version is kept", func(t *testing.T) {
+		_, err := override.NewKey("versions_to_keep", "0")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = override.NewKey("versions_to_keep", "50")
+		})
+		require.Error(t, readDashboardVersionsSettings(cfg, f))
+	})
+}
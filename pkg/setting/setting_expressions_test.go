@@ -0,0 +1,51 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLExpressionsSettings(t *testing.T) {
+	cfg, err := NewCfgFromBytes([]byte(`
+[expressions]
+sql_enabled_orgs = 1, 2 3
+sql_disabled_orgs = 3
+sql_max_joins = 4
+sql_max_subquery_depth = 2
+sql_timeout = 10s
+`))
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3}, cfg.SQLExpressionsEnabledOrgs)
+	require.Equal(t, []int64{3}, cfg.SQLExpressionsDisabledOrgs)
+	require.Equal(t, 4, cfg.SQLExpressionsMaxJoins)
+	require.Equal(t, 2, cfg.SQLExpressionsMaxSubqueryDepth)
+	require.Equal(t, 10*time.Second, cfg.SQLExpressionsTimeout)
+
+	require.True(t, cfg.SQLExpressionsEnabledForOrg(1))
+	require.False(t, cfg.SQLExpressionsEnabledForOrg(3))
+	require.False(t, cfg.SQLExpressionsEnabledForOrg(4))
+
+	t.Run("all orgs are enabled by default", func(t *testing.T) {
+		cfg, err := NewCfgFromBytes([]byte(`
+[expressions]
+sql_disabled_orgs = 3
+`))
+		require.NoError(t, err)
+		require.True(t, cfg.SQLExpressionsEnabledForOrg(4))
+		require.False(t, cfg.SQLExpressionsEnabledForOrg(3))
+		require.Zero(t, cfg.SQLExpressionsTimeout)
+	})
+
+	t.Run("should fail on invalid values", func(t *testing.T) {
+		for _, ini := range []string{
+			"sql_enabled_orgs = main",
+			"sql_max_joins = -1",
+			"sql_timeout = -1s",
+		} {
+			_, err := NewCfgFromBytes([]byte("[expressions]\n" + ini))
+			require.Error(t, err, ini)
+		}
+	})
+}
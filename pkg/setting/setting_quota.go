@@ -6,6 +6,9 @@ type OrgQuota struct {
 	Dashboard  int64 `target:"dashboard"`
 	ApiKey     int64 `target:"api_key"`
 	AlertRule  int64 `target:"alert_rule"`
+
+	// FolderDashboard limits the dashboards of each folder of the org
+	FolderDashboard int64 `target:"-"`
 }
 
 type UserQuota struct {
@@ -43,6 +46,8 @@ func (cfg *Cfg) readQuotaSettings() {
 		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
 		ApiKey:     quota.Key("org_api_key").MustInt64(10),
 		AlertRule:  quota.Key("org_alert_rule").MustInt64(100),
+
+		FolderDashboard: quota.Key("folder_dashboard").MustInt64(-1),
 	}
 
 	// per User limits
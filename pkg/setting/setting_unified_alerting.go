@@ -2,6 +2,9 @@ package setting
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +15,7 @@ import (
 
 	alertingCluster "github.com/grafana/alerting/cluster"
 
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -61,11 +65,18 @@ const (
 	// with intervals that are not exactly divided by this number not to be evaluated
 	SchedulerBaseInterval = 10 * time.Second
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
-	DefaultRuleEvaluationInterval  = SchedulerBaseInterval * 6 // == 60 seconds
-	stateHistoryDefaultEnabled     = true
-	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
-	defaultRecordingRequestTimeout = 10 * time.Second
-	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	DefaultRuleEvaluationInterval   = SchedulerBaseInterval * 6 // == 60 seconds
+	stateHistoryDefaultEnabled      = true
+	lokiDefaultMaxQueryLength       = 721 * time.Hour // 30d1h, matches the default value in Loki
+	defaultRecordingRequestTimeout  = 10 * time.Second
+	lokiDefaultMaxQuerySize         = 65536 // 64kb
+	elasticsearchDefaultIndexPrefix = "grafana-state-history"
+	stateHistoryRetentionInterval   = time.Hour
+	stateHistoryRetentionBatchSize  = 500
+	stateHistoryWebhookTimeout      = 10 * time.Second
+	stateHistoryWebhookMaxAttempts  = 3
+	stateHistoryWebhookBackoff      = time.Second
+	stateHistoryWebhookQueueSize    = 1000
 )
 
 type UnifiedAlertingSettings struct {
@@ -128,6 +139,57 @@ type UnifiedAlertingSettings struct {
 	// should be stored in the database for each alert_rule in an organization including the current one.
 	// 0 value means no limit
 	RuleVersionRecordLimit int
+
+	// SLOGroups assigns the SLO group of the routes of the Alerting API, keyed by route family, e.g. history.
+	// The routes of the families that are not set are in the high-slow group.
+	SLOGroups map[string]requestmeta.SLOGroup
+
+	// RouteGates enables the routes of the Alerting API, keyed by route family, e.g. history, or by operation, e.g.
+	// RoutePurgeStateHistory. The values are enabled, disabled, or the name of the feature toggle that enables the routes.
+	RouteGates map[string]string
+}
+
+const (
+	AlertingRouteEnabled  = "enabled"
+	AlertingRouteDisabled = "disabled"
+)
+
+var (
+	alertingOperationRegex   = regexp.MustCompile(`^Route[A-Z][A-Za-z0-9]*$`)
+	alertingFeatureFlagRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+)
+
+// AlertingAPIRouteFamilies are the route families of the Alerting API that an SLO group can be assigned to.
+var AlertingAPIRouteFamilies = []string{
+	"alertmanager",
+	"configuration",
+	"history",
+	"notifications",
+	"prometheus",
+	"provisioning",
+	"ruler",
+	"testing",
+}
+
+// SLOGroup returns the SLO group of the routes of the given family of the Alerting API.
+func (u *UnifiedAlertingSettings) SLOGroup(family string) requestmeta.SLOGroup {
+	if g, ok := u.SLOGroups[family]; ok {
+		return g
+	}
+	return requestmeta.SLOGroupHighSlow
+}
+
+// RouteGate returns the gate of an operation of the given family of the Alerting API: enabled, disabled, or the name
+// of the feature toggle that enables the operation. The gate of the operation overrides the gate of its family, the
+// operations without a gate are enabled.
+func (u *UnifiedAlertingSettings) RouteGate(family, operation string) string {
+	if g, ok := u.RouteGates[operation]; ok {
+		return g
+	}
+	if g, ok := u.RouteGates[family]; ok {
+		return g
+	}
+	return AlertingRouteEnabled
 }
 
 type RecordingRuleSettings struct {
@@ -173,9 +235,110 @@ type UnifiedAlertingStateHistorySettings struct {
 	LokiBasicAuthUsername string
 	LokiMaxQueryLength    time.Duration
 	LokiMaxQuerySize      int
-	MultiPrimary          string
-	MultiSecondaries      []string
-	ExternalLabels        map[string]string
+	// LokiStructuredMetadata writes the org, the group and the UID of the rule as structured metadata of the
+	// entries rather than as labels of the streams. It requires Loki 3 or later.
+	LokiStructuredMetadata bool
+	ElasticsearchURL       string
+	// ElasticsearchAPIKey takes precedence over basic auth when it is set.
+	ElasticsearchAPIKey            string
+	ElasticsearchBasicAuthUsername string
+	ElasticsearchBasicAuthPassword string
+	ElasticsearchIndexPrefix       string
+	MultiPrimary                   string
+	MultiSecondaries               []string
+	ExternalLabels                 map[string]string
+	Retention                      UnifiedAlertingStateHistoryRetentionSettings
+	Webhooks                       UnifiedAlertingStateHistoryWebhookSettings
+	OTLP                           UnifiedAlertingStateHistoryOTLPSettings
+	Labels                         UnifiedAlertingStateHistoryLabelSettings
+}
+
+// UnifiedAlertingStateHistoryRetentionSettings configures the background job that prunes
+// and compacts state history stored in the annotations backend.
+type UnifiedAlertingStateHistoryRetentionSettings struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+	// Default is applied to every organization that does not have an override.
+	Default StateHistoryRetention
+	// OrgOverrides holds retention settings for specific organizations, keyed by organization ID.
+	OrgOverrides map[int64]StateHistoryRetention
+}
+
+// StateHistoryRetention describes how much state history is kept for an organization.
+// Zero values mean that history is kept forever.
+type StateHistoryRetention struct {
+	// MaxAge is the maximum age of a state transition.
+	MaxAge time.Duration
+	// MaxTransitionsPerRule is the maximum number of state transitions kept for a single alert rule.
+	MaxTransitionsPerRule int64
+	// DeletedRulesGracePeriod is how long the state history of a deleted alert rule is kept after its last state transition.
+	DeletedRulesGracePeriod time.Duration
+}
+
+// IsZero returns true if the retention does not limit state history.
+func (r StateHistoryRetention) IsZero() bool {
+	return r.MaxAge <= 0 && r.MaxTransitionsPerRule <= 0 && r.DeletedRulesGracePeriod <= 0
+}
+
+// ForOrg returns the retention that applies to the given organization.
+func (s UnifiedAlertingStateHistoryRetentionSettings) ForOrg(orgID int64) StateHistoryRetention {
+	if r, ok := s.OrgOverrides[orgID]; ok {
+		return r
+	}
+	return s.Default
+}
+
+// UnifiedAlertingStateHistoryWebhookSettings configures the webhooks that state transitions are posted to,
+// so external systems can consume them without a notification policy.
+type UnifiedAlertingStateHistoryWebhookSettings struct {
+	Enabled bool
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts to deliver a batch of state transitions.
+	// The delay between two attempts starts at Backoff and doubles after every attempt.
+	MaxAttempts int
+	Backoff     time.Duration
+	// QueueSize is the number of batches waiting to be delivered, batches are dropped when the queue is full.
+	QueueSize int
+	// OrgWebhooks holds the webhooks of each organization, keyed by organization ID.
+	OrgWebhooks map[int64][]StateHistoryWebhook
+}
+
+// StateHistoryWebhook is an endpoint the state transitions of an organization are posted to.
+type StateHistoryWebhook struct {
+	URL string
+	// Authorization is sent in the Authorization header when it is set.
+	Authorization string
+}
+
+// UnifiedAlertingStateHistoryOTLPSettings configures the export of state transitions as OpenTelemetry log records
+// to an OTLP/HTTP collector, so they can be analyzed alongside traces and logs.
+type UnifiedAlertingStateHistoryOTLPSettings struct {
+	Enabled bool
+	// Endpoint is the URL the logs are posted to, e.g. http://localhost:4318/v1/logs.
+	Endpoint string
+	// Headers are sent with every request, e.g. for authentication.
+	Headers map[string]string
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts to export a batch of state transitions.
+	// The delay between two attempts starts at Backoff and doubles after every attempt.
+	MaxAttempts int
+	Backoff     time.Duration
+	// QueueSize is the number of batches waiting to be exported, batches are dropped when the queue is full.
+	QueueSize int
+}
+
+// UnifiedAlertingStateHistoryLabelSettings limits the instance labels written to the state history,
+// so history can be shipped to shared stores without high cardinality or sensitive labels.
+type UnifiedAlertingStateHistoryLabelSettings struct {
+	// MaxLabels is the maximum number of labels stored per state transition. Zero means no limit.
+	MaxLabels int
+	// DropLabels are the labels that are never stored.
+	DropLabels []string
+	// HashLabels are the labels that are stored as a hash of their value.
+	HashLabels []string
+	// HashSalt is prepended to the values of HashLabels before they are hashed.
+	HashSalt string
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -416,19 +579,41 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	stateHistory := iniFile.Section("unified_alerting.state_history")
 	stateHistoryLabels := iniFile.Section("unified_alerting.state_history.external_labels")
 	uaCfgStateHistory := UnifiedAlertingStateHistorySettings{
-		Enabled:               stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
-		Backend:               stateHistory.Key("backend").MustString("annotations"),
-		LokiRemoteURL:         stateHistory.Key("loki_remote_url").MustString(""),
-		LokiReadURL:           stateHistory.Key("loki_remote_read_url").MustString(""),
-		LokiWriteURL:          stateHistory.Key("loki_remote_write_url").MustString(""),
-		LokiTenantID:          stateHistory.Key("loki_tenant_id").MustString(""),
-		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
-		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
-		LokiMaxQueryLength:    stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
-		LokiMaxQuerySize:      stateHistory.Key("loki_max_query_size").MustInt(lokiDefaultMaxQuerySize),
-		MultiPrimary:          stateHistory.Key("primary").MustString(""),
-		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
-		ExternalLabels:        stateHistoryLabels.KeysHash(),
+		Enabled:                        stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
+		Backend:                        stateHistory.Key("backend").MustString("annotations"),
+		LokiRemoteURL:                  stateHistory.Key("loki_remote_url").MustString(""),
+		LokiReadURL:                    stateHistory.Key("loki_remote_read_url").MustString(""),
+		LokiWriteURL:                   stateHistory.Key("loki_remote_write_url").MustString(""),
+		LokiTenantID:                   stateHistory.Key("loki_tenant_id").MustString(""),
+		LokiBasicAuthUsername:          stateHistory.Key("loki_basic_auth_username").MustString(""),
+		LokiBasicAuthPassword:          stateHistory.Key("loki_basic_auth_password").MustString(""),
+		LokiMaxQueryLength:             stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
+		LokiMaxQuerySize:               stateHistory.Key("loki_max_query_size").MustInt(lokiDefaultMaxQuerySize),
+		LokiStructuredMetadata:         stateHistory.Key("loki_structured_metadata").MustBool(false),
+		ElasticsearchURL:               stateHistory.Key("elasticsearch_url").MustString(""),
+		ElasticsearchAPIKey:            stateHistory.Key("elasticsearch_api_key").MustString(""),
+		ElasticsearchBasicAuthUsername: stateHistory.Key("elasticsearch_basic_auth_username").MustString(""),
+		ElasticsearchBasicAuthPassword: stateHistory.Key("elasticsearch_basic_auth_password").MustString(""),
+		ElasticsearchIndexPrefix:       stateHistory.Key("elasticsearch_index_prefix").MustString(elasticsearchDefaultIndexPrefix),
+		MultiPrimary:                   stateHistory.Key("primary").MustString(""),
+		MultiSecondaries:               splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
+		ExternalLabels:                 stateHistoryLabels.KeysHash(),
+	}
+	uaCfgStateHistory.Retention, err = readStateHistoryRetentionSettings(iniFile.Section("unified_alerting.state_history.retention"))
+	if err != nil {
+		return err
+	}
+	uaCfgStateHistory.Webhooks, err = readStateHistoryWebhookSettings(iniFile.Section("unified_alerting.state_history.webhooks"))
+	if err != nil {
+		return err
+	}
+	uaCfgStateHistory.OTLP, err = readStateHistoryOTLPSettings(iniFile.Section("unified_alerting.state_history.otlp"))
+	if err != nil {
+		return err
+	}
+	uaCfgStateHistory.Labels, err = readStateHistoryLabelSettings(iniFile.Section("unified_alerting.state_history.labels"))
+	if err != nil {
+		return err
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
@@ -472,6 +657,16 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		return fmt.Errorf("setting 'rule_version_record_limit' is invalid, only 0 or a positive integer are allowed")
 	}
 
+	uaCfg.SLOGroups, err = readAlertingSLOGroups(iniFile.Section("unified_alerting.slo_groups"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.RouteGates, err = readAlertingRouteGates(iniFile.Section("unified_alerting.routes"))
+	if err != nil {
+		return err
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -480,6 +675,220 @@ func GetAlertmanagerDefaultConfiguration() string {
 	return alertmanagerDefaultConfiguration
 }
 
+func readStateHistoryRetentionSettings(section *ini.Section) (UnifiedAlertingStateHistoryRetentionSettings, error) {
+	cfg := UnifiedAlertingStateHistoryRetentionSettings{
+		Enabled:      section.Key("enabled").MustBool(false),
+		BatchSize:    section.Key("batch_size").MustInt(stateHistoryRetentionBatchSize),
+		OrgOverrides: make(map[int64]StateHistoryRetention),
+	}
+	var err error
+	cfg.Interval, err = gtime.ParseDuration(valueAsString(section, "interval", stateHistoryRetentionInterval.String()))
+	if err != nil {
+		return cfg, fmt.Errorf("setting 'interval' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	if cfg.Interval <= 0 {
+		return cfg, fmt.Errorf("setting 'interval' in section [%s] must be greater than 0", section.Name())
+	}
+	if cfg.BatchSize <= 0 {
+		return cfg, fmt.Errorf("setting 'batch_size' in section [%s] must be greater than 0", section.Name())
+	}
+
+	cfg.Default, err = readStateHistoryRetention(section, StateHistoryRetention{})
+	if err != nil {
+		return cfg, err
+	}
+
+	// Organization specific overrides are defined in child sections, e.g. [unified_alerting.state_history.retention.org_2].
+	for _, child := range section.ChildSections() {
+		name := strings.TrimPrefix(child.Name(), section.Name()+".")
+		idStr, ok := strings.CutPrefix(name, "org_")
+		if !ok {
+			return cfg, fmt.Errorf("section [%s] is invalid, expected the name to be in the format [%s.org_<id>]", child.Name(), section.Name())
+		}
+		orgID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || orgID <= 0 {
+			return cfg, fmt.Errorf("section [%s] is invalid, organization ID must be a positive integer", child.Name())
+		}
+		cfg.OrgOverrides[orgID], err = readStateHistoryRetention(child, cfg.Default)
+		if err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+func readStateHistoryRetention(section *ini.Section, defaults StateHistoryRetention) (StateHistoryRetention, error) {
+	r := defaults
+	if v := section.Key("max_age").String(); v != "" {
+		maxAge, err := gtime.ParseDuration(v)
+		if err != nil {
+			return r, fmt.Errorf("setting 'max_age' in section [%s] is invalid: %w", section.Name(), err)
+		}
+		r.MaxAge = maxAge
+	}
+	if section.Key("max_transitions_per_rule").String() != "" {
+		r.MaxTransitionsPerRule = section.Key("max_transitions_per_rule").MustInt64(0)
+		if r.MaxTransitionsPerRule < 0 {
+			return r, fmt.Errorf("setting 'max_transitions_per_rule' in section [%s] is invalid, only 0 or a positive integer are allowed", section.Name())
+		}
+	}
+	if v := section.Key("deleted_rules_grace_period").String(); v != "" {
+		gracePeriod, err := gtime.ParseDuration(v)
+		if err != nil {
+			return r, fmt.Errorf("setting 'deleted_rules_grace_period' in section [%s] is invalid: %w", section.Name(), err)
+		}
+		r.DeletedRulesGracePeriod = gracePeriod
+	}
+	return r, nil
+}
+
+func readStateHistoryWebhookSettings(section *ini.Section) (UnifiedAlertingStateHistoryWebhookSettings, error) {
+	cfg := UnifiedAlertingStateHistoryWebhookSettings{
+		Enabled:     section.Key("enabled").MustBool(false),
+		MaxAttempts: section.Key("max_attempts").MustInt(stateHistoryWebhookMaxAttempts),
+		QueueSize:   section.Key("queue_size").MustInt(stateHistoryWebhookQueueSize),
+		OrgWebhooks: make(map[int64][]StateHistoryWebhook),
+	}
+	var err error
+	cfg.Timeout, err = gtime.ParseDuration(valueAsString(section, "timeout", stateHistoryWebhookTimeout.String()))
+	if err != nil {
+		return cfg, fmt.Errorf("setting 'timeout' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	cfg.Backoff, err = gtime.ParseDuration(valueAsString(section, "backoff", stateHistoryWebhookBackoff.String()))
+	if err != nil {
+		return cfg, fmt.Errorf("setting 'backoff' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	if cfg.Timeout <= 0 || cfg.Backoff <= 0 {
+		return cfg, fmt.Errorf("settings 'timeout' and 'backoff' in section [%s] must be greater than 0", section.Name())
+	}
+	if cfg.MaxAttempts <= 0 || cfg.QueueSize <= 0 {
+		return cfg, fmt.Errorf("settings 'max_attempts' and 'queue_size' in section [%s] must be greater than 0", section.Name())
+	}
+
+	// The webhooks of an organization are defined in child sections, e.g. [unified_alerting.state_history.webhooks.org_2].
+	for _, child := range section.ChildSections() {
+		name := strings.TrimPrefix(child.Name(), section.Name()+".")
+		idStr, ok := strings.CutPrefix(name, "org_")
+		if !ok {
+			return cfg, fmt.Errorf("section [%s] is invalid, expected the name to be in the format [%s.org_<id>]", child.Name(), section.Name())
+		}
+		orgID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || orgID <= 0 {
+			return cfg, fmt.Errorf("section [%s] is invalid, organization ID must be a positive integer", child.Name())
+		}
+		authorization := child.Key("authorization").MustString("")
+		for _, u := range splitTrim(child.Key("urls").MustString(""), ",") {
+			if u == "" {
+				continue
+			}
+			parsed, err := url.Parse(u)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return cfg, fmt.Errorf("setting 'urls' in section [%s] is invalid, %q is not an http or https URL", child.Name(), u)
+			}
+			cfg.OrgWebhooks[orgID] = append(cfg.OrgWebhooks[orgID], StateHistoryWebhook{URL: u, Authorization: authorization})
+		}
+	}
+	return cfg, nil
+}
+
+func readStateHistoryOTLPSettings(section *ini.Section) (UnifiedAlertingStateHistoryOTLPSettings, error) {
+	cfg := UnifiedAlertingStateHistoryOTLPSettings{
+		// the key is not inherited from [unified_alerting], which has an enabled key as well
+		Enabled:     slices.Contains(section.KeyStrings(), "enabled") && section.Key("enabled").MustBool(false),
+		Endpoint:    section.Key("endpoint").MustString(""),
+		Headers:     make(map[string]string),
+		MaxAttempts: section.Key("max_attempts").MustInt(stateHistoryWebhookMaxAttempts),
+		QueueSize:   section.Key("queue_size").MustInt(stateHistoryWebhookQueueSize),
+	}
+	var err error
+	cfg.Timeout, err = gtime.ParseDuration(valueAsString(section, "timeout", stateHistoryWebhookTimeout.String()))
+	if err != nil {
+		return cfg, fmt.Errorf("setting 'timeout' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	cfg.Backoff, err = gtime.ParseDuration(valueAsString(section, "backoff", stateHistoryWebhookBackoff.String()))
+	if err != nil {
+		return cfg, fmt.Errorf("setting 'backoff' in section [%s] is invalid: %w", section.Name(), err)
+	}
+	if cfg.Timeout <= 0 || cfg.Backoff <= 0 {
+		return cfg, fmt.Errorf("settings 'timeout' and 'backoff' in section [%s] must be greater than 0", section.Name())
+	}
+	if cfg.MaxAttempts <= 0 || cfg.QueueSize <= 0 {
+		return cfg, fmt.Errorf("settings 'max_attempts' and 'queue_size' in section [%s] must be greater than 0", section.Name())
+	}
+	for _, h := range splitTrim(section.Key("headers").MustString(""), ",") {
+		if h == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(h, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return cfg, fmt.Errorf("setting 'headers' in section [%s] is invalid, expected a comma separated list of <name>=<value>", section.Name())
+		}
+		cfg.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+	parsed, err := url.Parse(cfg.Endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return cfg, fmt.Errorf("setting 'endpoint' in section [%s] is invalid, %q is not an http or https URL", section.Name(), cfg.Endpoint)
+	}
+	return cfg, nil
+}
+
+func readStateHistoryLabelSettings(section *ini.Section) (UnifiedAlertingStateHistoryLabelSettings, error) {
+	cfg := UnifiedAlertingStateHistoryLabelSettings{
+		MaxLabels:  section.Key("max_labels").MustInt(0),
+		DropLabels: util.SplitString(section.Key("drop_labels").MustString("")),
+		HashLabels: util.SplitString(section.Key("hash_labels").MustString("")),
+		HashSalt:   section.Key("hash_salt").MustString(""),
+	}
+	if cfg.MaxLabels < 0 {
+		return cfg, fmt.Errorf("setting 'max_labels' in section [%s] must be 0 or greater", section.Name())
+	}
+	for _, l := range cfg.HashLabels {
+		if slices.Contains(cfg.DropLabels, l) {
+			return cfg, fmt.Errorf("label %q can not be in both 'drop_labels' and 'hash_labels' in section [%s]", l, section.Name())
+		}
+	}
+	return cfg, nil
+}
+
+func readAlertingSLOGroups(section *ini.Section) (map[string]requestmeta.SLOGroup, error) {
+	groups := make(map[string]requestmeta.SLOGroup)
+	for _, key := range section.Keys() {
+		if !slices.Contains(AlertingAPIRouteFamilies, key.Name()) {
+			return nil, fmt.Errorf("setting '%s' in section [%s] is invalid, expected one of %s", key.Name(), section.Name(), strings.Join(AlertingAPIRouteFamilies, ", "))
+		}
+		if key.Value() == "" {
+			continue
+		}
+		g, err := requestmeta.ParseSLOGroup(key.Value())
+		if err != nil {
+			return nil, fmt.Errorf("setting '%s' in section [%s] is invalid: %w", key.Name(), section.Name(), err)
+		}
+		groups[key.Name()] = g
+	}
+	return groups, nil
+}
+
+func readAlertingRouteGates(section *ini.Section) (map[string]string, error) {
+	gates := make(map[string]string)
+	for _, key := range section.Keys() {
+		if !slices.Contains(AlertingAPIRouteFamilies, key.Name()) && !alertingOperationRegex.MatchString(key.Name()) {
+			return nil, fmt.Errorf("setting '%s' in section [%s] is invalid, expected an operation of the Alerting API, e.g. RoutePurgeStateHistory, or one of %s", key.Name(), section.Name(), strings.Join(AlertingAPIRouteFamilies, ", "))
+		}
+		value := strings.TrimSpace(key.Value())
+		if value == "" {
+			continue
+		}
+		if !alertingFeatureFlagRegex.MatchString(value) {
+			return nil, fmt.Errorf("setting '%s' in section [%s] is invalid, expected %s, %s or the name of a feature toggle, got %q", key.Name(), section.Name(), AlertingRouteEnabled, AlertingRouteDisabled, value)
+		}
+		gates[key.Name()] = value
+	}
+	return gates, nil
+}
+
 func splitTrim(s string, sep string) []string {
 	spl := strings.Split(s, sep)
 	for i := range spl {
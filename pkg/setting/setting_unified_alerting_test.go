@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 )
 
 func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
@@ -350,3 +352,244 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestStateHistoryRetentionSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.state_history.retention")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = section.NewKey("interval", "30m")
+	require.NoError(t, err)
+	_, err = section.NewKey("max_age", "30d")
+	require.NoError(t, err)
+	_, err = section.NewKey("max_transitions_per_rule", "1000")
+	require.NoError(t, err)
+	_, err = section.NewKey("deleted_rules_grace_period", "1d")
+	require.NoError(t, err)
+
+	override, err := f.NewSection("unified_alerting.state_history.retention.org_2")
+	require.NoError(t, err)
+	_, err = override.NewKey("max_age", "7d")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+
+	retention := cfg.UnifiedAlerting.StateHistory.Retention
+	require.True(t, retention.Enabled)
+	require.Equal(t, 30*time.Minute, retention.Interval)
+	require.Equal(t, stateHistoryRetentionBatchSize, retention.BatchSize)
+	require.Equal(t, StateHistoryRetention{MaxAge: 30 * 24 * time.Hour, MaxTransitionsPerRule: 1000, DeletedRulesGracePeriod: 24 * time.Hour}, retention.ForOrg(1))
+	require.Equal(t, StateHistoryRetention{MaxAge: 7 * 24 * time.Hour, MaxTransitionsPerRule: 1000, DeletedRulesGracePeriod: 24 * time.Hour}, retention.ForOrg(2))
+
+	t.Run("should fail if override section name is invalid", func(t *testing.T) {
+		_, err := f.NewSection("unified_alerting.state_history.retention.main")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			f.DeleteSection("unified_alerting.state_history.retention.main")
+		})
+		require.Error(t, cfg.ReadUnifiedAlertingSettings(f))
+	})
+
+	t.Run("should fail if max transitions is negative", func(t *testing.T) {
+		_, err := override.NewKey("max_transitions_per_rule", "-1")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			override.DeleteKey("max_transitions_per_rule")
+		})
+		require.Error(t, cfg.ReadUnifiedAlertingSettings(f))
+	})
+}
+
+func TestStateHistoryWebhookSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.state_history.webhooks")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = section.NewKey("max_attempts", "5")
+	require.NoError(t, err)
+
+	org, err := f.NewSection("unified_alerting.state_history.webhooks.org_2")
+	require.NoError(t, err)
+	_, err = org.NewKey("urls", "https://a.example.com/hook, http://b.example.com")
+	require.NoError(t, err)
+	_, err = org.NewKey("authorization", "Bearer token")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+
+	webhooks := cfg.UnifiedAlerting.StateHistory.Webhooks
+	require.True(t, webhooks.Enabled)
+	require.Equal(t, 5, webhooks.MaxAttempts)
+	require.Equal(t, stateHistoryWebhookTimeout, webhooks.Timeout)
+	require.Equal(t, stateHistoryWebhookBackoff, webhooks.Backoff)
+	require.Equal(t, stateHistoryWebhookQueueSize, webhooks.QueueSize)
+	require.Equal(t, map[int64][]StateHistoryWebhook{
+		2: {
+			{URL: "https://a.example.com/hook", Authorization: "Bearer token"},
+			{URL: "http://b.example.com", Authorization: "Bearer token"},
+		},
+	}, webhooks.OrgWebhooks)
+
+	t.Run("should fail if a URL is invalid", func(t *testing.T) {
+		_, err := org.NewKey("urls", "ftp://example.com")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = org.NewKey("urls", "https://a.example.com/hook")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "is not an http or https URL")
+	})
+}
+
+func TestStateHistoryOTLPSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.state_history.otlp")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = section.NewKey("endpoint", "http://collector:4318/v1/logs")
+	require.NoError(t, err)
+	_, err = section.NewKey("headers", "Authorization=Bearer token, X-Scope-OrgID=alerts")
+	require.NoError(t, err)
+	_, err = section.NewKey("max_attempts", "5")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.Equal(t, UnifiedAlertingStateHistoryOTLPSettings{
+		Enabled:     true,
+		Endpoint:    "http://collector:4318/v1/logs",
+		Headers:     map[string]string{"Authorization": "Bearer token", "X-Scope-OrgID": "alerts"},
+		Timeout:     stateHistoryWebhookTimeout,
+		MaxAttempts: 5,
+		Backoff:     stateHistoryWebhookBackoff,
+		QueueSize:   stateHistoryWebhookQueueSize,
+	}, cfg.UnifiedAlerting.StateHistory.OTLP)
+
+	t.Run("should fail if a header is invalid", func(t *testing.T) {
+		_, err := section.NewKey("headers", "Authorization")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = section.NewKey("headers", "")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "expected a comma separated list of <name>=<value>")
+	})
+
+	t.Run("should fail if the endpoint is invalid", func(t *testing.T) {
+		_, err := section.NewKey("endpoint", "collector:4318")
+		require.NoError(t, err)
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "is not an http or https URL")
+	})
+}
+
+func TestStateHistoryLabelSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.state_history.labels")
+	require.NoError(t, err)
+	_, err = section.NewKey("max_labels", "10")
+	require.NoError(t, err)
+	_, err = section.NewKey("drop_labels", "email, phone")
+	require.NoError(t, err)
+	_, err = section.NewKey("hash_labels", "user")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.Equal(t, UnifiedAlertingStateHistoryLabelSettings{
+		MaxLabels:  10,
+		DropLabels: []string{"email", "phone"},
+		HashLabels: []string{"user"},
+	}, cfg.UnifiedAlerting.StateHistory.Labels)
+
+	t.Run("should fail if a label is dropped and hashed", func(t *testing.T) {
+		_, err := section.NewKey("hash_labels", "user,email")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = section.NewKey("hash_labels", "user")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), `label "email" can not be in both`)
+	})
+
+	t.Run("should fail if max labels is negative", func(t *testing.T) {
+		_, err := section.NewKey("max_labels", "-1")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = section.NewKey("max_labels", "10")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "must be 0 or greater")
+	})
+}
+
+func TestAlertingSLOGroupSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.slo_groups")
+	require.NoError(t, err)
+	_, err = section.NewKey("history", "high-medium")
+	require.NoError(t, err)
+	_, err = section.NewKey("ruler", "high-fast")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.Equal(t, requestmeta.SLOGroupHighMedium, cfg.UnifiedAlerting.SLOGroup("history"))
+	require.Equal(t, requestmeta.SLOGroupHighFast, cfg.UnifiedAlerting.SLOGroup("ruler"))
+	require.Equal(t, requestmeta.SLOGroupHighSlow, cfg.UnifiedAlerting.SLOGroup("provisioning"))
+
+	t.Run("should fail if the SLO group is unknown", func(t *testing.T) {
+		_, err := section.NewKey("history", "fast")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = section.NewKey("history", "high-medium")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), `unknown SLO group "fast"`)
+	})
+
+	t.Run("should fail if the route family is unknown", func(t *testing.T) {
+		_, err := section.NewKey("rules", "low")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			section.DeleteKey("rules")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "setting 'rules' in section [unified_alerting.slo_groups] is invalid")
+	})
+}
+
+func TestAlertingRouteGateSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.routes")
+	require.NoError(t, err)
+	_, err = section.NewKey("history", "alertingStateHistoryImport")
+	require.NoError(t, err)
+	_, err = section.NewKey("RoutePurgeStateHistory", "disabled")
+	require.NoError(t, err)
+	_, err = section.NewKey("RouteGetStateHistory", "enabled")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.Equal(t, AlertingRouteDisabled, cfg.UnifiedAlerting.RouteGate("history", "RoutePurgeStateHistory"))
+	require.Equal(t, AlertingRouteEnabled, cfg.UnifiedAlerting.RouteGate("history", "RouteGetStateHistory"))
+	require.Equal(t, "alertingStateHistoryImport", cfg.UnifiedAlerting.RouteGate("history", "RouteImportStateHistory"))
+	require.Equal(t, AlertingRouteEnabled, cfg.UnifiedAlerting.RouteGate("ruler", "RoutePostNameRulesConfig"))
+
+	t.Run("should fail if the gate is invalid", func(t *testing.T) {
+		_, err := section.NewKey("RoutePurgeStateHistory", "off!")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = section.NewKey("RoutePurgeStateHistory", "disabled")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), `expected enabled, disabled or the name of a feature toggle, got "off!"`)
+	})
+
+	t.Run("should fail if the key is neither a route family nor an operation", func(t *testing.T) {
+		_, err := section.NewKey("rules", "disabled")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			section.DeleteKey("rules")
+		})
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "setting 'rules' in section [unified_alerting.routes] is invalid")
+	})
+}
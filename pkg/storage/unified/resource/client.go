@@ -36,6 +36,47 @@ type resourceClient struct {
 	ResourceIndexClient
 	BlobStoreClient
 	DiagnosticsClient
+
+	// only set for in-process servers
+	readiness IndexReadinessReporter
+	admin     IndexAdmin
+}
+
+var (
+	_ IndexReadinessReporter = (*resourceClient)(nil)
+	_ IndexAdmin             = (*resourceClient)(nil)
+)
+
+// IndexReadiness implements IndexReadinessReporter, it is unknown for remote servers
+func (c *resourceClient) IndexReadiness() *IndexReadiness {
+	if c.readiness == nil {
+		return nil
+	}
+	return c.readiness.IndexReadiness()
+}
+
+// Reindex implements IndexAdmin, remote indexes are managed by their storage server
+func (c *resourceClient) Reindex(ctx context.Context, namespace string) error {
+	if c.admin == nil {
+		return ErrIndexAdminUnsupported
+	}
+	return c.admin.Reindex(ctx, namespace)
+}
+
+// IndexStats implements IndexAdmin.
+func (c *resourceClient) IndexStats(ctx context.Context) (*IndexStats, error) {
+	if c.admin == nil {
+		return nil, ErrIndexAdminUnsupported
+	}
+	return c.admin.IndexStats(ctx)
+}
+
+// RefreshDocuments implements IndexAdmin.
+func (c *resourceClient) RefreshDocuments(ctx context.Context, key *ResourceKey, names []string) error {
+	if c.admin == nil {
+		return ErrIndexAdminUnsupported
+	}
+	return c.admin.RefreshDocuments(ctx, key, names)
 }
 
 func NewLegacyResourceClient(channel *grpc.ClientConn) ResourceClient {
@@ -76,12 +117,19 @@ func NewLocalResourceClient(server ResourceServer) ResourceClient {
 	)
 
 	cc := grpchan.InterceptClientConn(channel, clientInt.UnaryClientInterceptor, clientInt.StreamClientInterceptor)
-	return &resourceClient{
+	client := &resourceClient{
 		ResourceStoreClient: NewResourceStoreClient(cc),
 		ResourceIndexClient: NewResourceIndexClient(cc),
 		BlobStoreClient:     NewBlobStoreClient(cc),
 		DiagnosticsClient:   NewDiagnosticsClient(cc),
 	}
+	if readiness, ok := server.(IndexReadinessReporter); ok {
+		client.readiness = readiness
+	}
+	if admin, ok := server.(IndexAdmin); ok {
+		client.admin = admin
+	}
+	return client
 }
 
 func NewGRPCResourceClient(tracer tracing.Tracer, conn *grpc.ClientConn) (ResourceClient, error) {
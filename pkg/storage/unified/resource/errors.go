@@ -13,6 +13,7 @@ import (
 var (
 	ErrOptimisticLockingFailed = errors.New("optimistic locking failed")
 	ErrNotImplementedYet       = errors.New("not implemented yet")
+	ErrIndexNotReady           = errors.New("search index is not ready")
 )
 
 func NewBadRequestError(msg string) *ErrorResult {
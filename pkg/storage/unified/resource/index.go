@@ -2,6 +2,7 @@ package resource
 
 import (
 	"context"
+	"fmt"
 	golog "log"
 	"path/filepath"
 	reflect "reflect"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/google/uuid"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -23,6 +25,10 @@ const tracingPrexfixIndex = "unified_storage.index."
 const specFieldPrefix = "Spec."
 const descendingPrefix = "-"
 
+// QueryTypeJSON is the query type of the search requests whose query is a bleve query encoded as JSON, e.g. built
+// from a syntax the query string of bleve can not express. The query of the other requests is a bleve query string.
+const QueryTypeJSON = "json"
+
 type Shard struct {
 	index bleve.Index
 	path  string
@@ -43,6 +49,9 @@ type Index struct {
 	s          *server
 	log        log.Logger
 	tracer     tracing.Tracer
+	readiness  *indexReadiness
+	// builtAt is when each tenant was last fully indexed, guarded by shardMutex
+	builtAt map[string]time.Time
 }
 
 func NewIndex(s *server, opts Opts, tracer tracing.Tracer) *Index {
@@ -53,6 +62,7 @@ func NewIndex(s *server, opts Opts, tracer tracing.Tracer) *Index {
 		shards:     make(map[string]*Shard),
 		log:        log.New("unifiedstorage.search.index"),
 		tracer:     tracer,
+		readiness:  newIndexReadiness(),
 	}
 }
 
@@ -146,13 +156,16 @@ func (i *Index) Init(ctx context.Context) error {
 	// Get all tenants currently in Unified Storage
 	tenants, err := i.s.backend.Namespaces(ctx)
 	if err != nil {
+		i.readiness.finish(err)
 		return err
 	}
+	i.readiness.start(tenants)
 	for _, tenant := range tenants {
 		group.Go(func() error {
 			logger.Info("initializing index for tenant", "tenant", tenant)
 			objs, err := i.InitForTenant(ctx, tenant)
 			if err != nil {
+				i.readiness.failed(tenant, err)
 				return err
 			}
 			totalObjects += objs
@@ -162,15 +175,21 @@ func (i *Index) Init(ctx context.Context) error {
 
 	err = group.Wait()
 	if err != nil {
+		i.readiness.finish(err)
 		return err
 	}
 
 	//index all remaining batches for all tenants
 	logger.Info("indexing remaining batches", "shards", len(i.shards))
-	err = i.IndexBatches(ctx, 1, i.allTenants())
+	tenantsIndexed := i.allTenants()
+	err = i.IndexBatches(ctx, 1, tenantsIndexed)
+	i.readiness.finish(err)
 	if err != nil {
 		return err
 	}
+	i.shardMutex.Lock()
+	i.markBuilt(time.Now(), tenantsIndexed...)
+	i.shardMutex.Unlock()
 
 	end := time.Now().Unix()
 	totalDocCount := getTotalDocCount(i)
@@ -211,6 +230,7 @@ func (i *Index) InitForTenant(ctx context.Context, namespace string) (int, error
 			IndexServerMetrics.IndexedKinds.WithLabelValues(rt.Kind).Add(float64(len(list.Items)))
 
 			totalObjectsFetched += len(list.Items)
+			i.readiness.progress(namespace, totalObjectsFetched)
 
 			logger.Debug("indexing batch", "kind", rt.Kind, "count", len(list.Items), "namespace", namespace)
 			//add changes to batches for shards with changes in the List
@@ -315,6 +335,17 @@ func (i *Index) Delete(ctx context.Context, uid string, key *ResourceKey) error
 	return nil
 }
 
+func searchQuery(request *SearchRequest) (query.Query, error) {
+	if request.QueryType != QueryTypeJSON {
+		return bleve.NewQueryStringQuery(request.Query), nil
+	}
+	q, err := query.ParseQuery([]byte(request.Query))
+	if err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	return q, nil
+}
+
 func (i *Index) Search(ctx context.Context, request *SearchRequest) (*IndexResults, error) {
 	ctx, span := i.tracer.Start(ctx, tracingPrexfixIndex+"Search")
 	defer span.End()
@@ -341,7 +372,10 @@ func (i *Index) Search(ctx context.Context, request *SearchRequest) (*IndexResul
 		request.Limit = 10
 	}
 
-	textQuery := bleve.NewQueryStringQuery(request.Query)
+	textQuery, err := searchQuery(request)
+	if err != nil {
+		return nil, err
+	}
 	query := bleve.NewConjunctionQuery(textQuery)
 
 	if len(request.Kind) > 0 {
@@ -403,7 +437,7 @@ func (i *Index) Search(ctx context.Context, request *SearchRequest) (*IndexResul
 		}
 	}
 
-	return &IndexResults{Values: results, Groups: groups}, nil
+	return &IndexResults{Values: results, Groups: groups, QueryCost: res.Cost, TotalHits: res.Total}, nil
 }
 
 // Count returns the total doc count
@@ -459,12 +493,10 @@ func (i *Index) createIndex() (bleve.Index, string, error) {
 	return createFileIndex(i.opts.IndexDir)
 }
 
-var mappings = createIndexMappings()
-
 // less memory intensive alternative for larger indexes with less tenants (on-prem)
 func createFileIndex(path string) (bleve.Index, string, error) {
 	indexPath := filepath.Join(path, uuid.New().String())
-	index, err := bleve.New(indexPath, mappings)
+	index, err := bleve.New(indexPath, createIndexMappings())
 	if err != nil {
 		golog.Fatalf("Failed to create index: %v", err)
 	}
@@ -473,7 +505,7 @@ func createFileIndex(path string) (bleve.Index, string, error) {
 
 // faster indexing when there are many tenants with smaller batches (cloud)
 func createInMemoryIndex() (bleve.Index, string, error) {
-	index, err := bleve.NewMemOnly(mappings)
+	index, err := bleve.NewMemOnly(createIndexMappings())
 	return index, "", err
 }
 
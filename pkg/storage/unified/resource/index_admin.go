@@ -0,0 +1,261 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+)
+
+var (
+	ErrReindexInProgress     = errors.New("a reindex is already running")
+	ErrIndexAdminUnsupported = errors.New("the search index is not managed by this process")
+)
+
+// IndexAdmin rebuilds the search index and reports its statistics
+type IndexAdmin interface {
+	// Reindex rebuilds the index of the namespace in the background, or the whole index when the namespace is empty
+	Reindex(ctx context.Context, namespace string) error
+	IndexStats(ctx context.Context) (*IndexStats, error)
+	// RefreshDocuments indexes the named resources of the namespace, group and resource of the key again, so the
+	// index fields computed from data kept outside of the resources are up to date
+	RefreshDocuments(ctx context.Context, key *ResourceKey, names []string) error
+}
+
+// IndexStats describes the content of the search index
+type IndexStats struct {
+	Documents int64 `json:"documents"`
+	// SizeBytes is only reported for file based indexes
+	SizeBytes     int64      `json:"sizeBytes"`
+	LastBuildTime *time.Time `json:"lastBuildTime,omitempty"`
+	// PendingUpdates is the number of documents waiting in batches to be written to the index
+	PendingUpdates int64                 `json:"pendingUpdates"`
+	Reindexing     bool                  `json:"reindexing"`
+	Namespaces     []NamespaceIndexStats `json:"namespaces"`
+}
+
+// NamespaceIndexStats describes the index of a single namespace
+type NamespaceIndexStats struct {
+	Namespace      string     `json:"namespace"`
+	Documents      int64      `json:"documents"`
+	PendingUpdates int64      `json:"pendingUpdates"`
+	LastBuildTime  *time.Time `json:"lastBuildTime,omitempty"`
+}
+
+// Stats returns the statistics of every shard, the size and reindex state are added by the index server
+func (i *Index) Stats() *IndexStats {
+	i.shardMutex.RLock()
+	defer i.shardMutex.RUnlock()
+
+	stats := &IndexStats{Namespaces: make([]NamespaceIndexStats, 0, len(i.shards))}
+	for tenant, shard := range i.shards {
+		ns := NamespaceIndexStats{Namespace: tenant, PendingUpdates: int64(shard.batch.Size())}
+		count, err := shard.index.DocCount()
+		if err != nil {
+			i.log.Error("failed to get doc count", "tenant", tenant, "error", err)
+		}
+		ns.Documents = int64(count)
+		if built, ok := i.builtAt[tenant]; ok {
+			ns.LastBuildTime = &built
+			if stats.LastBuildTime == nil || built.After(*stats.LastBuildTime) {
+				stats.LastBuildTime = &built
+			}
+		}
+		stats.Documents += ns.Documents
+		stats.PendingUpdates += ns.PendingUpdates
+		stats.Namespaces = append(stats.Namespaces, ns)
+	}
+	sort.Slice(stats.Namespaces, func(a, b int) bool {
+		return stats.Namespaces[a].Namespace < stats.Namespaces[b].Namespace
+	})
+	return stats
+}
+
+// ReindexTenant builds a new shard for the tenant and swaps it with the current one once it is complete,
+// so the tenant stays searchable while it is rebuilt.
+func (i *Index) ReindexTenant(ctx context.Context, tenant string) error {
+	ctx, span := i.tracer.Start(ctx, tracingPrexfixIndex+"ReindexTenant")
+	defer span.End()
+
+	start := time.Now()
+	rebuilt := NewIndex(i.s, i.opts, i.tracer)
+	objs, err := rebuilt.InitForTenant(ctx, tenant)
+	if err != nil {
+		rebuilt.close()
+		return err
+	}
+	err = rebuilt.IndexBatches(ctx, 1, []string{tenant})
+	if err != nil {
+		rebuilt.close()
+		return err
+	}
+
+	i.shardMutex.Lock()
+	old := i.shards[tenant]
+	shard, ok := rebuilt.shards[tenant]
+	if ok {
+		i.shards[tenant] = shard
+	} else {
+		// the tenant has no objects left
+		delete(i.shards, tenant)
+	}
+	i.markBuilt(time.Now(), tenant)
+	i.shardMutex.Unlock()
+
+	if old != nil {
+		i.closeShard(tenant, old)
+	}
+	i.log.Info("reindexed tenant", "tenant", tenant, "objs_fetched", objs, "seconds", time.Since(start).Seconds())
+	return nil
+}
+
+// RefreshDocuments reads the named resources and indexes them again. Resources that do not exist anymore are
+// skipped, and so are tenants that are not indexed yet, they are indexed with the current values when they are.
+func (i *Index) RefreshDocuments(ctx context.Context, key *ResourceKey, names []string) error {
+	ctx, span := i.tracer.Start(ctx, tracingPrexfixIndex+"RefreshDocuments")
+	defer span.End()
+
+	i.shardMutex.RLock()
+	shard, ok := i.shards[key.Namespace]
+	i.shardMutex.RUnlock()
+	if !ok {
+		return nil
+	}
+	for _, name := range names {
+		rsp := i.s.backend.ReadResource(ctx, &ReadRequest{Key: &ResourceKey{
+			Namespace: key.Namespace,
+			Group:     key.Group,
+			Resource:  key.Resource,
+			Name:      name,
+		}})
+		if rsp.Error != nil {
+			if rsp.Error.Code == http.StatusNotFound {
+				continue
+			}
+			return GetError(rsp.Error)
+		}
+		res, err := NewIndexedResource(rsp.Value)
+		if err != nil {
+			return err
+		}
+		if err := shard.index.Index(res.Uid, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markBuilt records when the tenants were indexed, the shard mutex must be held
+func (i *Index) markBuilt(t time.Time, tenants ...string) {
+	if i.builtAt == nil {
+		i.builtAt = make(map[string]time.Time, len(tenants))
+	}
+	for _, tenant := range tenants {
+		i.builtAt[tenant] = t
+	}
+}
+
+// close releases all shards of an index that is no longer used
+func (i *Index) close() {
+	i.shardMutex.Lock()
+	shards := i.shards
+	i.shards = make(map[string]*Shard)
+	i.shardMutex.Unlock()
+
+	for tenant, shard := range shards {
+		i.closeShard(tenant, shard)
+	}
+}
+
+func (i *Index) closeShard(tenant string, shard *Shard) {
+	if err := shard.index.Close(); err != nil {
+		i.log.Warn("failed to close index shard", "tenant", tenant, "error", err)
+	}
+	if shard.path == "" {
+		return
+	}
+	if err := os.RemoveAll(shard.path); err != nil {
+		i.log.Warn("failed to remove index shard", "tenant", tenant, "path", shard.path, "error", err)
+	}
+}
+
+// Reindex implements IndexAdmin. Searches keep using the current index until the new one is built.
+func (is *IndexServer) Reindex(ctx context.Context, namespace string) error {
+	if is.getIndex() == nil {
+		return ErrIndexNotReady
+	}
+	if !is.reindexing.CompareAndSwap(false, true) {
+		return ErrReindexInProgress
+	}
+
+	// the reindex outlives the request that triggered it
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer is.reindexing.Store(false)
+		ctx, span := is.tracer.Start(ctx, tracingPrefixIndexServer+"Reindex")
+		defer span.End()
+
+		var err error
+		if namespace != "" {
+			err = is.getIndex().ReindexTenant(ctx, namespace)
+		} else {
+			err = is.rebuild(ctx)
+		}
+		if err != nil {
+			span.RecordError(err)
+			is.log.Error("Reindex failed", "namespace", namespace, "error", err)
+		}
+	}()
+	return nil
+}
+
+// rebuild builds a new index for all tenants and replaces the current one
+func (is *IndexServer) rebuild(ctx context.Context) error {
+	start := time.Now()
+	index := NewIndex(is.s, is.indexOpts(), is.tracer)
+	if err := index.Init(ctx); err != nil {
+		index.close()
+		return err
+	}
+
+	is.indexMu.Lock()
+	old := is.index
+	is.index = index
+	is.indexMu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+	is.log.Info("Rebuilt the search index", "seconds", time.Since(start).Seconds())
+	return nil
+}
+
+// RefreshDocuments implements IndexAdmin.
+func (is *IndexServer) RefreshDocuments(ctx context.Context, key *ResourceKey, names []string) error {
+	index := is.getIndex()
+	if index == nil {
+		return ErrIndexNotReady
+	}
+	return index.RefreshDocuments(ctx, key, names)
+}
+
+// IndexStats implements IndexAdmin.
+func (is *IndexServer) IndexStats(ctx context.Context) (*IndexStats, error) {
+	index := is.getIndex()
+	if index == nil {
+		return nil, ErrIndexNotReady
+	}
+
+	stats := index.Stats()
+	stats.Reindexing = is.reindexing.Load()
+	if is.cfg.IndexPath != "" {
+		size, err := getTotalIndexSize(is.cfg.IndexPath)
+		if err != nil {
+			return nil, err
+		}
+		stats.SizeBytes = size
+	}
+	return stats, nil
+}
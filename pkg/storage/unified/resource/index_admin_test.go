@@ -0,0 +1,37 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexStats(t *testing.T) {
+	data := readTestData(t, "dashboard-resource.json")
+	list := &ListResponse{Items: []*ResourceWrapper{{Value: data}}}
+	index := newTestIndex(t, 100)
+
+	err := index.writeBatch(testContext, list)
+	require.NoError(t, err)
+
+	stats := index.Stats()
+	require.Equal(t, int64(0), stats.Documents)
+	require.Equal(t, int64(1), stats.PendingUpdates, "the batch is not full yet")
+	require.Nil(t, stats.LastBuildTime)
+	require.Equal(t, []NamespaceIndexStats{{Namespace: testTenant, PendingUpdates: 1}}, stats.Namespaces)
+
+	err = index.IndexBatches(testContext, 1, index.allTenants())
+	require.NoError(t, err)
+	built := time.Now()
+	index.markBuilt(built, testTenant)
+
+	stats = index.Stats()
+	require.Equal(t, int64(1), stats.Documents)
+	require.Equal(t, int64(0), stats.PendingUpdates)
+	require.Equal(t, &built, stats.LastBuildTime)
+	require.Equal(t, []NamespaceIndexStats{{Namespace: testTenant, Documents: 1, LastBuildTime: &built}}, stats.Namespaces)
+
+	index.close()
+	require.Empty(t, index.Stats().Namespaces)
+}
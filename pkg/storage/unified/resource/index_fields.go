@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// IndexField is a field computed from the spec of a resource when it is indexed, so searches can filter on values that
+// are not stored in the spec as they are, e.g. the number of panels of a dashboard or the types of its data sources.
+// The value is indexed with the spec fields, so the name must not shadow a field of the spec.
+type IndexField struct {
+	Field string
+	// Type is one of the types of the spec field mappings: string, string[], int, int64, float64, bool or time
+	Type string
+	// Value computes the value of the field, the field is not indexed for the resource when ok is false
+	Value func(spec map[string]any) (value any, ok bool)
+	// Usage computes the value of the field from data kept outside of the resource, like how often it is viewed.
+	// Its owner refreshes the documents with IndexAdmin.RefreshDocuments when the data changes. Either Value or
+	// Usage is set.
+	Usage func(namespace string, name string) (value any, ok bool)
+}
+
+var indexFieldTypes = []string{"string", "string[]", "int", "int64", "float64", "bool", "time"}
+
+var registeredIndexFields = struct {
+	sync.RWMutex
+	kinds map[string][]IndexField
+}{kinds: map[string][]IndexField{}}
+
+// RegisterIndexFields adds computed fields to the index of a kind, typically when the API group of the kind is registered.
+// Registering a field again replaces it. The fields are part of the mapping of the indexes created afterwards.
+func RegisterIndexFields(kind string, fields ...IndexField) error {
+	for _, f := range fields {
+		if f.Field == "" || (f.Value == nil && f.Usage == nil) {
+			return fmt.Errorf("index field of %s is missing a name or a value", kind)
+		}
+		if f.Value != nil && f.Usage != nil {
+			return fmt.Errorf("index field %s of %s has both a value and a usage", f.Field, kind)
+		}
+		if !slices.Contains(indexFieldTypes, f.Type) {
+			return fmt.Errorf("index field %s of %s has the unsupported type %q", f.Field, kind, f.Type)
+		}
+		for _, m := range specMappings[kind] {
+			if m.Field == f.Field {
+				return fmt.Errorf("index field %s shadows the spec field of %s", f.Field, kind)
+			}
+		}
+	}
+
+	registeredIndexFields.Lock()
+	defer registeredIndexFields.Unlock()
+	registered := registeredIndexFields.kinds[kind]
+	for _, f := range fields {
+		registered = slices.DeleteFunc(registered, func(r IndexField) bool { return r.Field == f.Field })
+		registered = append(registered, f)
+	}
+	registeredIndexFields.kinds[kind] = registered
+	return nil
+}
+
+// IndexFields returns the computed fields registered for a kind
+func IndexFields(kind string) []IndexField {
+	registeredIndexFields.RLock()
+	defer registeredIndexFields.RUnlock()
+	return slices.Clone(registeredIndexFields.kinds[kind])
+}
+
+// IndexFieldType returns the type of an indexed spec or computed field of a kind
+func IndexFieldType(kind string, field string) (string, bool) {
+	for _, m := range getSpecObjectMappings()[kind] {
+		if m.Field == field {
+			return m.Type, true
+		}
+	}
+	return "", false
+}
+
+// addIndexFields sets the computed fields of the kind in the spec of the named resource
+func addIndexFields(kind string, namespace string, name string, spec map[string]any) {
+	values := map[string]any{}
+	for _, f := range IndexFields(kind) {
+		var v any
+		var ok bool
+		if f.Usage != nil {
+			v, ok = f.Usage(namespace, name)
+		} else {
+			v, ok = f.Value(spec)
+		}
+		if ok {
+			values[f.Field] = v
+		}
+	}
+	for k, v := range values {
+		spec[k] = v
+	}
+}
@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterIndexFields(t *testing.T) {
+	t.Cleanup(func() {
+		registeredIndexFields.Lock()
+		delete(registeredIndexFields.kinds, "Dashboard")
+		registeredIndexFields.Unlock()
+	})
+	schemaVersion := IndexField{
+		Field: "test_schema_version",
+		Type:  "int",
+		Value: func(spec map[string]any) (any, bool) {
+			// specs are decoded with the unstructured decoder, integers are int64
+			v, ok := spec["schemaVersion"].(int64)
+			return v, ok
+		},
+	}
+
+	t.Run("validates the fields", func(t *testing.T) {
+		require.ErrorContains(t, RegisterIndexFields("Dashboard", IndexField{Field: "x", Type: "int"}), "missing a name or a value")
+		require.ErrorContains(t, RegisterIndexFields("Dashboard", IndexField{Field: "x", Type: "map", Value: schemaVersion.Value}), "unsupported type")
+		require.ErrorContains(t, RegisterIndexFields("Dashboard", IndexField{Field: "title", Type: "string", Value: schemaVersion.Value}), "shadows the spec field")
+		require.ErrorContains(t, RegisterIndexFields("Dashboard", IndexField{Field: "x", Type: "int", Value: schemaVersion.Value, Usage: func(string, string) (any, bool) { return 1, true }}), "both a value and a usage")
+	})
+
+	t.Run("registering a field again replaces it", func(t *testing.T) {
+		require.NoError(t, RegisterIndexFields("Dashboard", schemaVersion))
+		require.NoError(t, RegisterIndexFields("Dashboard", schemaVersion))
+		require.Len(t, IndexFields("Dashboard"), 1)
+
+		fieldType, ok := IndexFieldType("Dashboard", "test_schema_version")
+		require.True(t, ok)
+		require.Equal(t, "int", fieldType)
+		require.True(t, IsSpecField("-test_schema_version"))
+	})
+
+	t.Run("filters on the computed values", func(t *testing.T) {
+		require.NoError(t, RegisterIndexFields("Dashboard", schemaVersion))
+		dashboard := readTestData(t, "dashboard-resource.json")
+		folder := readTestData(t, "folder-resource.json")
+		list := &ListResponse{Items: []*ResourceWrapper{{Value: dashboard}, {Value: folder}}}
+		index := newTestIndex(t, 1)
+
+		err := index.writeBatch(testContext, list)
+		require.NoError(t, err)
+
+		assertSearchCountEquals(t, index, "Spec.test_schema_version:>=40", nil, nil, 1)
+		assertSearchCountEquals(t, index, "Spec.test_schema_version:<40", nil, nil, 0)
+
+		results, err := index.Search(testContext, &SearchRequest{Query: "*", Tenant: testTenant, Kind: []string{"dashboard"}})
+		require.NoError(t, err)
+		require.Len(t, results.Values, 1)
+		require.Equal(t, float64(40), results.Values[0].Spec["test_schema_version"])
+	})
+}
+
+func TestRefreshDocuments(t *testing.T) {
+	t.Cleanup(func() {
+		registeredIndexFields.Lock()
+		delete(registeredIndexFields.kinds, "Dashboard")
+		registeredIndexFields.Unlock()
+	})
+	views := map[string]int64{}
+	require.NoError(t, RegisterIndexFields("Dashboard", IndexField{
+		Field: "test_views",
+		Type:  "int64",
+		Usage: func(namespace string, name string) (any, bool) {
+			return views[namespace+"/"+name], true
+		},
+	}))
+
+	dashboard := readTestData(t, "dashboard-resource.json")
+	index := newTestIndex(t, 1)
+	index.s = &server{backend: &readOnlyBackend{values: map[string][]byte{"adfbg6f": dashboard}}}
+	require.NoError(t, index.writeBatch(testContext, &ListResponse{Items: []*ResourceWrapper{{Value: dashboard}}}))
+	assertSearchCountEquals(t, index, "Spec.test_views:>=10", nil, nil, 0)
+
+	views["default/adfbg6f"] = 12
+	key := &ResourceKey{Namespace: testTenant, Group: "dashboard.grafana.app", Resource: "dashboards"}
+	require.NoError(t, index.RefreshDocuments(testContext, key, []string{"adfbg6f", "deleted"}))
+	assertSearchCountEquals(t, index, "Spec.test_views:>=10", nil, nil, 1)
+	assertCountEquals(t, index, 1)
+
+	t.Run("skips the tenants that are not indexed", func(t *testing.T) {
+		other := &ResourceKey{Namespace: "org-2", Group: "dashboard.grafana.app", Resource: "dashboards"}
+		require.NoError(t, index.RefreshDocuments(testContext, other, []string{"adfbg6f"}))
+		assertCountEquals(t, index, 1)
+	})
+}
+
+// readOnlyBackend reads the resources of a map keyed by name
+type readOnlyBackend struct {
+	StorageBackend
+	values map[string][]byte
+}
+
+func (b *readOnlyBackend) ReadResource(_ context.Context, req *ReadRequest) *BackendReadResponse {
+	value, ok := b.values[req.Key.Name]
+	if !ok {
+		return &BackendReadResponse{Key: req.Key, Error: &ErrorResult{Code: http.StatusNotFound, Message: "not found"}}
+	}
+	return &BackendReadResponse{Key: req.Key, Value: value}
+}
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/blevesearch/bleve/v2"
@@ -28,6 +29,10 @@ type IndexedResource struct {
 type IndexResults struct {
 	Values []IndexedResource
 	Groups []*Group
+	// QueryCost is the number of bytes read from the index by the search
+	QueryCost uint64
+	// TotalHits is the number of documents matching the search, regardless of its limit and offset
+	TotalHits uint64
 }
 
 func (ir IndexedResource) FromSearchHit(hit *search.DocumentMatch) IndexedResource {
@@ -41,6 +46,7 @@ func (ir IndexedResource) FromSearchHit(hit *search.DocumentMatch) IndexedResour
 	ir.UpdatedAt = fieldValue("UpdatedAt", hit)
 	ir.UpdatedBy = fieldValue("UpdatedBy", hit)
 	ir.Title = fieldValue("Title", hit)
+	ir.FolderId = fieldValue("FolderId", hit)
 
 	// add indexed spec fields to search results
 	specResult := map[string]any{}
@@ -83,6 +89,7 @@ func NewIndexedResource(rawResource []byte) (*IndexedResource, error) {
 	ir.Namespace = meta.GetNamespace()
 	ir.Group = meta.GetGroupVersionKind().Group
 	ir.Kind = meta.GetGroupVersionKind().Kind
+	ir.FolderId = meta.GetFolder()
 	ir.CreatedAt = meta.GetCreationTimestamp().Time.Format("2006-01-02T15:04:05Z")
 	ir.CreatedBy = meta.GetCreatedBy()
 	updatedAt, err := meta.GetUpdatedTimestamp()
@@ -101,6 +108,7 @@ func NewIndexedResource(rawResource []byte) (*IndexedResource, error) {
 	}
 	specValues, ok := spec.(map[string]any)
 	if ok {
+		addIndexFields(ir.Kind, ir.Namespace, ir.Name, specValues)
 		ir.Spec = specValues
 	}
 
@@ -129,13 +137,14 @@ func createIndexMappingForKind(resourceKind string) *mapping.DocumentMapping {
 		"Group":     bleve.NewTextFieldMapping(),
 		"Namespace": bleve.NewTextFieldMapping(),
 		"Kind":      bleve.NewTextFieldMapping(),
-		"Name":      bleve.NewTextFieldMapping(),
+		// the names and folders are UIDs, they are matched exactly and not split in words, e.g. prod is not prod-secret
+		"Name":      bleve.NewKeywordFieldMapping(),
 		"Title":     bleve.NewTextFieldMapping(),
 		"CreatedAt": bleve.NewDateTimeFieldMapping(),
 		"CreatedBy": bleve.NewTextFieldMapping(),
 		"UpdatedAt": bleve.NewDateTimeFieldMapping(),
 		"UpdatedBy": bleve.NewTextFieldMapping(),
-		"FolderId":  bleve.NewTextFieldMapping(),
+		"FolderId":  bleve.NewKeywordFieldMapping(),
 	}
 
 	// Spec is different for all resources, so we need to generate the spec mapping based on the kind
@@ -160,10 +169,22 @@ type SpecFieldMapping struct {
 	Type  string
 }
 
-// Right now we are hardcoding which spec fields to index for each kind
-// In the future, which fields to index will be defined on the resources themselves by their owners.
+// The spec fields to index for each kind are hardcoded, the owners of the resources can add computed fields
+// with RegisterIndexFields.
 func getSpecObjectMappings() map[string][]SpecFieldMapping {
-	return specMappings
+	all := make(map[string][]SpecFieldMapping, len(specMappings))
+	for kind, mappings := range specMappings {
+		all[kind] = slices.Clone(mappings)
+	}
+
+	registeredIndexFields.RLock()
+	defer registeredIndexFields.RUnlock()
+	for kind, fields := range registeredIndexFields.kinds {
+		for _, f := range fields {
+			all[kind] = append(all[kind], SpecFieldMapping{Field: f.Field, Type: f.Type})
+		}
+	}
+	return all
 }
 
 // Generate the spec field mapping for a given kind
@@ -199,20 +220,14 @@ func createSpecObjectMapping(kind string) *mapping.DocumentMapping {
 
 func IsSpecField(field string) bool {
 	field = strings.TrimPrefix(field, "-")
-	_, ok := specFields[field]
-	return ok
-}
-
-var specFields = mapSpecFields()
-
-func mapSpecFields() map[string]bool {
-	fields := map[string]bool{}
-	for _, mappings := range specMappings {
+	for _, mappings := range getSpecObjectMappings() {
 		for _, m := range mappings {
-			fields[m.Field] = true
+			if m.Field == field {
+				return true
+			}
 		}
 	}
-	return fields
+	return false
 }
 
 var specMappings = map[string][]SpecFieldMapping{
@@ -86,7 +86,7 @@ func (s *IndexMetrics) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	// collect index docs
-	s.IndexedDocs.Set(getTotalDocCount(s.IndexServer.index))
+	s.IndexedDocs.Set(getTotalDocCount(s.IndexServer.getIndex()))
 	s.IndexedDocs.Collect(ch)
 }
 
@@ -100,6 +100,9 @@ func (s *IndexMetrics) Describe(ch chan<- *prometheus.Desc) {
 
 // getTotalDocCount returns the total number of documents in the index
 func getTotalDocCount(index *Index) float64 {
+	if index == nil {
+		return 0 // the index is still loading
+	}
 	count, _ := index.Count()
 	return float64(count)
 }
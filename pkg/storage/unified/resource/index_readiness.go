@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	IndexStatePending  = "pending"
+	IndexStateBuilding = "building"
+	IndexStateReady    = "ready"
+	IndexStateFailed   = "failed"
+	// IndexStateDisabled is reported when there is no search index in this process to warm up
+	IndexStateDisabled = "disabled"
+)
+
+// IndexReadinessReporter reports the progress of the search index warm-up
+type IndexReadinessReporter interface {
+	// IndexReadiness returns nil when the readiness is unknown, for example with a remote index
+	IndexReadiness() *IndexReadiness
+}
+
+// IndexReadiness is the progress of the search index warm-up
+type IndexReadiness struct {
+	Ready      bool                      `json:"ready"`
+	State      string                    `json:"state"`
+	StartedAt  *time.Time                `json:"startedAt,omitempty"`
+	FinishedAt *time.Time                `json:"finishedAt,omitempty"`
+	Namespaces []NamespaceIndexReadiness `json:"namespaces"`
+}
+
+// NamespaceIndexReadiness is the warm-up progress of the index of a single namespace
+type NamespaceIndexReadiness struct {
+	Namespace string `json:"namespace"`
+	State     string `json:"state"`
+	// Fetched is the number of objects read from storage so far
+	Fetched int    `json:"fetched"`
+	Error   string `json:"error,omitempty"`
+}
+
+// indexReadiness tracks the initial build of the index, it is safe for concurrent use
+type indexReadiness struct {
+	mu         sync.Mutex
+	state      string
+	startedAt  time.Time
+	finishedAt time.Time
+	namespaces map[string]*NamespaceIndexReadiness
+}
+
+func newIndexReadiness() *indexReadiness {
+	return &indexReadiness{
+		state:      IndexStatePending,
+		namespaces: map[string]*NamespaceIndexReadiness{},
+	}
+}
+
+func (r *indexReadiness) start(namespaces []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state = IndexStateBuilding
+	r.startedAt = time.Now()
+	for _, ns := range namespaces {
+		r.namespaces[ns] = &NamespaceIndexReadiness{Namespace: ns, State: IndexStatePending}
+	}
+}
+
+func (r *indexReadiness) progress(namespace string, fetched int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ns, ok := r.namespaces[namespace]; ok {
+		ns.State = IndexStateBuilding
+		ns.Fetched = fetched
+	}
+}
+
+func (r *indexReadiness) failed(namespace string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ns, ok := r.namespaces[namespace]; ok {
+		ns.State = IndexStateFailed
+		ns.Error = err.Error()
+	}
+}
+
+// finish marks the warm-up as done. Namespaces are only searchable once all batches are written, so they become ready together.
+func (r *indexReadiness) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finishedAt = time.Now()
+	r.state = IndexStateReady
+	if err != nil {
+		r.state = IndexStateFailed
+	}
+	for _, ns := range r.namespaces {
+		if ns.State == IndexStateFailed {
+			continue
+		}
+		if err != nil {
+			ns.State = IndexStateFailed
+			continue
+		}
+		ns.State = IndexStateReady
+	}
+}
+
+func (r *indexReadiness) snapshot() *IndexReadiness {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := &IndexReadiness{
+		Ready:      r.state == IndexStateReady,
+		State:      r.state,
+		Namespaces: make([]NamespaceIndexReadiness, 0, len(r.namespaces)),
+	}
+	if !r.startedAt.IsZero() {
+		startedAt := r.startedAt
+		out.StartedAt = &startedAt
+	}
+	if !r.finishedAt.IsZero() {
+		finishedAt := r.finishedAt
+		out.FinishedAt = &finishedAt
+	}
+	for _, ns := range r.namespaces {
+		out.Namespaces = append(out.Namespaces, *ns)
+	}
+	sort.Slice(out.Namespaces, func(i, j int) bool {
+		return out.Namespaces[i].Namespace < out.Namespaces[j].Namespace
+	})
+	return out
+}
@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexReadiness(t *testing.T) {
+	t.Run("ready once all namespaces are indexed", func(t *testing.T) {
+		r := newIndexReadiness()
+		require.Equal(t, &IndexReadiness{State: IndexStatePending, Namespaces: []NamespaceIndexReadiness{}}, r.snapshot())
+
+		r.start([]string{"stacks-2", "default"})
+		r.progress("default", 100)
+		r.progress("unknown", 5)
+
+		snap := r.snapshot()
+		require.False(t, snap.Ready)
+		require.Equal(t, IndexStateBuilding, snap.State)
+		require.NotNil(t, snap.StartedAt)
+		require.Nil(t, snap.FinishedAt)
+		require.Equal(t, []NamespaceIndexReadiness{
+			{Namespace: "default", State: IndexStateBuilding, Fetched: 100},
+			{Namespace: "stacks-2", State: IndexStatePending},
+		}, snap.Namespaces)
+
+		r.finish(nil)
+		snap = r.snapshot()
+		require.True(t, snap.Ready)
+		require.NotNil(t, snap.FinishedAt)
+		require.Equal(t, []NamespaceIndexReadiness{
+			{Namespace: "default", State: IndexStateReady, Fetched: 100},
+			{Namespace: "stacks-2", State: IndexStateReady},
+		}, snap.Namespaces)
+	})
+
+	t.Run("reports the namespace that failed", func(t *testing.T) {
+		r := newIndexReadiness()
+		r.start([]string{"default", "stacks-2"})
+		r.failed("stacks-2", errors.New("list failed"))
+		r.finish(errors.New("list failed"))
+
+		snap := r.snapshot()
+		require.False(t, snap.Ready)
+		require.Equal(t, IndexStateFailed, snap.State)
+		require.Equal(t, []NamespaceIndexReadiness{
+			{Namespace: "default", State: IndexStateFailed},
+			{Namespace: "stacks-2", State: IndexStateFailed, Error: "list failed"},
+		}, snap.Namespaces)
+	})
+}
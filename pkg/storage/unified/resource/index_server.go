@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
@@ -14,12 +16,16 @@ import (
 
 type IndexServer struct {
 	ResourceServer
-	s      *server
-	index  *Index
-	ws     *indexWatchServer
-	log    *slog.Logger
-	cfg    *setting.Cfg
-	tracer tracing.Tracer
+	s *server
+	// the index is built in the background, indexMu guards setting it while it is searched
+	indexMu sync.RWMutex
+	index   *Index
+	// reindexing is set while a reindex runs in the background
+	reindexing atomic.Bool
+	ws         *indexWatchServer
+	log        *slog.Logger
+	cfg        *setting.Cfg
+	tracer     tracing.Tracer
 }
 
 const tracingPrefixIndexServer = "unified_storage.index_server."
@@ -28,11 +34,16 @@ func (is *IndexServer) Search(ctx context.Context, req *SearchRequest) (*SearchR
 	ctx, span := is.tracer.Start(ctx, tracingPrefixIndexServer+"Search")
 	defer span.End()
 
-	results, err := is.index.Search(ctx, req)
+	index := is.getIndex()
+	if index == nil {
+		return nil, ErrIndexNotReady
+	}
+
+	results, err := index.Search(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	res := &SearchResponse{}
+	res := &SearchResponse{QueryCost: results.QueryCost, TotalHits: results.TotalHits}
 	for _, r := range results.Values {
 		resJsonBytes, err := json.Marshal(r)
 		if err != nil {
@@ -57,18 +68,40 @@ func (is *IndexServer) Load(ctx context.Context) error {
 	ctx, span := is.tracer.Start(ctx, tracingPrefixIndexServer+"Load")
 	defer span.End()
 
-	opts := Opts{
+	index := NewIndex(is.s, is.indexOpts(), is.tracer)
+	is.indexMu.Lock()
+	is.index = index
+	is.indexMu.Unlock()
+	err := index.Init(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (is *IndexServer) indexOpts() Opts {
+	return Opts{
 		Workers:   is.cfg.IndexWorkers,
 		BatchSize: is.cfg.IndexMaxBatchSize,
 		ListLimit: is.cfg.IndexListLimit,
 		IndexDir:  is.cfg.IndexPath,
 	}
-	is.index = NewIndex(is.s, opts, is.tracer)
-	err := is.index.Init(ctx)
-	if err != nil {
-		return err
+}
+
+// getIndex returns the index, or nil before it is loaded
+func (is *IndexServer) getIndex() *Index {
+	is.indexMu.RLock()
+	defer is.indexMu.RUnlock()
+	return is.index
+}
+
+// IndexReadiness reports the progress of the initial index build
+func (is *IndexServer) IndexReadiness() *IndexReadiness {
+	index := is.getIndex()
+	if index == nil {
+		return &IndexReadiness{State: IndexStatePending, Namespaces: []NamespaceIndexReadiness{}}
 	}
-	return nil
+	return index.readiness.snapshot()
 }
 
 // Watch resources for changes and update the index
@@ -164,7 +197,7 @@ func (f *indexWatchServer) Context() context.Context {
 }
 
 func (f *indexWatchServer) Index() *Index {
-	return f.is.index
+	return f.is.getIndex()
 }
 
 func (f *indexWatchServer) Add(we *WatchEvent) error {
@@ -110,6 +110,78 @@ func TestIndexDashboardWithTags(t *testing.T) {
 	assertSearchGroupCountEquals(t, index, "*", "tags", []string{"tag4"}, 3)
 }
 
+func TestSearchJSONQuery(t *testing.T) {
+	dashboard := readTestData(t, "dashboard-resource.json")
+	data := readTestData(t, "dashboard-tagged-resource.json")
+	data2 := readTestData(t, "dashboard-tagged-resource2.json")
+	list := &ListResponse{Items: []*ResourceWrapper{{Value: dashboard}, {Value: data}, {Value: data2}}}
+	index := newTestIndex(t, 2)
+	// the query cost is only reported by file indexes
+	index.opts.IndexDir = t.TempDir()
+
+	err := index.writeBatch(testContext, list)
+	require.NoError(t, err)
+
+	// tag1 AND NOT tag4
+	q := `{"conjuncts": [{"match": "tag1", "field": "Spec.tags"}, {"must_not": {"disjuncts": [{"match": "tag4", "field": "Spec.tags"}]}}]}`
+	req := &SearchRequest{Query: q, QueryType: QueryTypeJSON, Tenant: testTenant, Limit: 10}
+	results, err := index.Search(testContext, req)
+	require.NoError(t, err)
+	require.Len(t, results.Values, 1)
+	assert.Equal(t, "adg5xd8", results.Values[0].Name)
+	assert.Greater(t, results.QueryCost, uint64(0))
+	assert.Equal(t, uint64(1), results.TotalHits)
+
+	// the total counts the matches beyond the limit
+	results, err = index.Search(testContext, &SearchRequest{Query: "*", Tenant: testTenant, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results.Values, 1)
+	assert.Equal(t, uint64(3), results.TotalHits)
+
+	req.Query = `{"conjuncts": [`
+	_, err = index.Search(testContext, req)
+	require.ErrorContains(t, err, "invalid search query")
+}
+
+func TestSearchExactUIDs(t *testing.T) {
+	resource := func(kind, name, folder string) *ResourceWrapper {
+		return &ResourceWrapper{Value: []byte(`{
+			"kind": "` + kind + `",
+			"metadata": {
+				"uid": "uid-` + name + `",
+				"name": "` + name + `",
+				"namespace": "default",
+				"annotations": {"grafana.app/folder": "` + folder + `"}
+			},
+			"spec": {"title": "` + name + `"}
+		}`)}
+	}
+	list := &ListResponse{Items: []*ResourceWrapper{
+		resource("Dashboard", "prod-db", "team"),
+		resource("Dashboard", "prod-db-secret", "team-secret"),
+		resource("Folder", "team", ""),
+		resource("Folder", "team-secret", ""),
+	}}
+	index := newTestIndex(t, 1)
+	require.NoError(t, index.writeBatch(testContext, list))
+
+	search := func(q string) []string {
+		results, err := index.Search(testContext, &SearchRequest{Query: q, QueryType: QueryTypeJSON, Tenant: testTenant, Limit: 10})
+		require.NoError(t, err)
+		names := []string{}
+		for _, v := range results.Values {
+			names = append(names, v.Name)
+		}
+		return names
+	}
+
+	// the names and folders are not split in words, the UIDs starting with the granted one are not matched
+	require.Equal(t, []string{"prod-db"}, search(`{"term": "prod-db", "field": "Name"}`))
+	require.Equal(t, []string{"prod-db"}, search(`{"match_phrase": "prod-db", "field": "Name"}`))
+	require.Equal(t, []string{"prod-db"}, search(`{"conjuncts": [{"match_phrase": "Dashboard", "field": "Kind"}, {"term": "team", "field": "FolderId"}]}`))
+	require.Equal(t, []string{"team"}, search(`{"conjuncts": [{"match_phrase": "Folder", "field": "Kind"}, {"term": "team", "field": "Name"}]}`))
+}
+
 func TestSort(t *testing.T) {
 	dashboard := readTestData(t, "dashboard-resource.json")
 	folder := readTestData(t, "folder-resource.json")
@@ -975,6 +975,42 @@ func (s *server) Index(ctx context.Context) (*Index, error) {
 	return index.index, nil
 }
 
+// IndexReadiness implements IndexReadinessReporter.
+func (s *server) IndexReadiness() *IndexReadiness {
+	index, ok := s.index.(*IndexServer)
+	if !ok {
+		return &IndexReadiness{Ready: true, State: IndexStateDisabled, Namespaces: []NamespaceIndexReadiness{}}
+	}
+	return index.IndexReadiness()
+}
+
+// Reindex implements IndexAdmin.
+func (s *server) Reindex(ctx context.Context, namespace string) error {
+	index, ok := s.index.(*IndexServer)
+	if !ok {
+		return ErrIndexAdminUnsupported
+	}
+	return index.Reindex(ctx, namespace)
+}
+
+// IndexStats implements IndexAdmin.
+func (s *server) IndexStats(ctx context.Context) (*IndexStats, error) {
+	index, ok := s.index.(*IndexServer)
+	if !ok {
+		return nil, ErrIndexAdminUnsupported
+	}
+	return index.IndexStats(ctx)
+}
+
+// RefreshDocuments implements IndexAdmin.
+func (s *server) RefreshDocuments(ctx context.Context, key *ResourceKey, names []string) error {
+	index, ok := s.index.(*IndexServer)
+	if !ok {
+		return ErrIndexAdminUnsupported
+	}
+	return index.RefreshDocuments(ctx, key, names)
+}
+
 // IsHealthy implements ResourceServer.
 func (s *server) IsHealthy(ctx context.Context, req *HealthCheckRequest) (*HealthCheckResponse, error) {
 	if err := s.Init(ctx); err != nil {
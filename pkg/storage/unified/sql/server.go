@@ -4,12 +4,14 @@ import (
 	"context"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	infraDB "github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/authz"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -67,7 +69,8 @@ func NewResourceServer(ctx context.Context, db infraDB.DB, cfg *setting.Cfg,
 		return nil, err
 	}
 
-	// Initialize the indexer if one is configured
+	// Warm up the indexer if one is configured. The index is built in the background so startup is not blocked,
+	// the progress is reported by the search readiness endpoint.
 	if opts.Index != nil {
 		// TODO: Create a proper identity for the indexer
 		orgId := int64(1)
@@ -85,10 +88,15 @@ func NewResourceServer(ctx context.Context, db infraDB.DB, cfg *setting.Cfg,
 				},
 			},
 		})
-		_, err = rs.(resource.ResourceIndexer).Index(ctx)
-		if err != nil {
-			return nil, err
-		}
+		go func() {
+			logger := log.New("sql-resource-server")
+			start := time.Now()
+			if _, err := rs.(resource.ResourceIndexer).Index(ctx); err != nil {
+				logger.Error("Failed to warm up the search index", "error", err)
+				return
+			}
+			logger.Info("Search index warm up finished", "duration", time.Since(start))
+		}()
 	}
 
 	return rs, nil